@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/db"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbmemory"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbmysql"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbsqlite"
+	"github.com/jmjf/go-jst/internal/migrate"
+	"github.com/jmjf/go-jst/internal/region"
+	"github.com/jmjf/go-jst/internal/selftest"
+)
+
+// Backends GOJST_DB_BACKEND can name.
+const (
+	backendPostgres = "postgres"
+	backendMemory   = "memory"
+	backendMysql    = "mysql"
+	backendSqlite   = "sqlite"
+)
+
+// backendRepo is everything the server uses its repo for.
+type backendRepo interface {
+	jobStatus.FullRepo
+	migrate.CheckpointRepo
+	region.Database
+	selftest.Database
+}
+
+// coreRepo is what serveCore uses its repo for.
+type coreRepo interface {
+	jobStatus.Repo
+	region.Database
+}
+
+// backend is the opened repo. repo is set for backends that implement every port, and core for
+// backends that implement only jobStatus.Repo. pgDB is the Postgres connection pool, for
+// LISTEN/NOTIFY; it's nil for other backends. secondary is the dual write database, if
+// GOJST_DUAL_WRITE_DB_URL is set.
+type backend struct {
+	repo      backendRepo
+	core      coreRepo
+	pgDB      *sql.DB
+	secondary jobStatus.FullRepo
+	close     func() error
+}
+
+// openBackend opens the repo GOJST_DB_BACKEND names. postgres, the default, connects to
+// GOJST_DB_URL. memory keeps everything in the process, so it needs nothing else to run and
+// loses everything when it stops; use it for demos and CI, with one instance. mysql connects to
+// the MySQL or MariaDB DSN in GOJST_DB_URL and serves only the core routes (see serveCore).
+// sqlite opens the database file in GOJST_DB_URL, creates its table if it isn't there, and also
+// serves only the core routes.
+func openBackend(cfg apiConfig) (backend, error) {
+	switch cfg.server.DbBackend {
+	case backendMemory:
+		return backend{repo: dbmemory.NewRepoMemory(), close: func() error { return nil }}, nil
+	case backendPostgres:
+		repo := db.NewRepoDB(cfg.server.DbUrl, cfg.pool)
+		if err := repo.Open(); err != nil {
+			return backend{}, err
+		}
+		if !cfg.dualWriteOn {
+			return backend{repo: repo, pgDB: repo.DB, close: repo.Close}, nil
+		}
+		secondary := db.NewRepoDB(cfg.dualWrite.SecondaryDbUrl, cfg.pool)
+		if err := secondary.Open(); err != nil {
+			repo.Close()
+			return backend{}, fmt.Errorf("dual write database: %w", err)
+		}
+		return backend{repo: repo, pgDB: repo.DB, secondary: secondary, close: func() error {
+			return errors.Join(secondary.Close(), repo.Close())
+		}}, nil
+	case backendMysql:
+		repo := dbmysql.NewRepoMysql(cfg.server.DbUrl)
+		if err := repo.Open(); err != nil {
+			return backend{}, err
+		}
+		return backend{core: repo, close: repo.Close}, nil
+	case backendSqlite:
+		repo := dbsqlite.NewRepoSqlite(cfg.server.DbUrl)
+		if err := repo.Open(); err != nil {
+			return backend{}, err
+		}
+		if err := repo.Bootstrap(context.Background()); err != nil {
+			repo.Close()
+			return backend{}, err
+		}
+		return backend{core: repo, close: repo.Close}, nil
+	}
+	return backend{}, fmt.Errorf("unknown backend %q", cfg.server.DbBackend)
+}
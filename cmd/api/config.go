@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/config"
+	"github.com/jmjf/go-jst/internal/delivery"
+	"github.com/jmjf/go-jst/internal/drain"
+	"github.com/jmjf/go-jst/internal/flags"
+	"github.com/jmjf/go-jst/internal/forecast"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/chaos"
+	"github.com/jmjf/go-jst/internal/jobStatus/db"
+	"github.com/jmjf/go-jst/internal/jobStatus/dualwrite"
+	"github.com/jmjf/go-jst/internal/jobStatus/retry"
+	"github.com/jmjf/go-jst/internal/region"
+	"github.com/jmjf/go-jst/internal/webhookauth"
+)
+
+// apiConfig is every setting the server reads from the environment. See each FromEnv function
+// for its settings.
+type apiConfig struct {
+	server serverConfig
+	pool   db.PoolConfig
+	retry  retry.Config
+	drain  drain.Config
+	// migrateOnStart applies schema migrations before the server opens the repo
+	migrateOnStart bool
+
+	fault   chaos.FaultConfig
+	chaosOn bool
+
+	dualWrite   dualwrite.Config
+	dualWriteOn bool
+
+	quota       jobStatus.QuotaConfig
+	retention   jobStatus.RetentionConfig
+	flags       *flags.Flags
+	forecasters *jobStatus.Forecasters
+	integrity   jobStatus.IntegrityHasher
+	mappings    *jobStatus.FieldMappings
+	region      region.Config
+
+	proxies       common.TrustedProxies
+	proxyProtocol bool
+	apiAllow      common.IpAllowlist
+	adminAllow    common.IpAllowlist
+	adminToken    string
+	// deleteRole is the role (proxy group) a user needs to delete statuses; empty refuses every delete
+	deleteRole string
+
+	deliverer  *delivery.WebhookDeliverer
+	deliveryOn bool
+
+	selfTest   bool
+	analystSql bool
+	// envelope wraps responses in dto.EnvelopeDto for clients that don't ask for plain JSON
+	envelope bool
+
+	// slashVerifier is nil unless GOJST_WEBHOOK_SLASH_SECRET is set
+	slashVerifier webhookauth.Verifier
+
+	soakDuration time.Duration
+}
+
+// loadConfig reads every setting before anything starts, so one bad value doesn't hide the
+// others. The error lists every problem found.
+func loadConfig(getenv func(string) string) (apiConfig, error) {
+	var cfg apiConfig
+	var problems config.Problems
+	var err error
+
+	cfg.server, err = serverConfigFromEnv(getenv)
+	problems.Add(err)
+	cfg.pool, err = db.PoolConfigFromEnv(getenv)
+	problems.Add(err)
+	cfg.retry, err = retry.ConfigFromEnv(getenv)
+	problems.Add(err)
+	cfg.drain, err = drain.ConfigFromEnv(getenv)
+	problems.Add(err)
+	cfg.migrateOnStart, err = config.EnvBool(getenv, "GOJST_MIGRATE_ON_START")
+	problems.Add(err)
+	cfg.fault, cfg.chaosOn, err = chaos.FaultConfigFromEnv(getenv)
+	problems.Add(err)
+	cfg.dualWrite, cfg.dualWriteOn, err = dualwrite.ConfigFromEnv(getenv)
+	problems.Add(err)
+	if cfg.dualWriteOn && cfg.server.DbBackend != backendPostgres {
+		problems.Add(fmt.Errorf("GOJST_DUAL_WRITE_DB_URL needs GOJST_DB_BACKEND=%s", backendPostgres))
+	}
+	cfg.quota, err = jobStatus.QuotaConfigFromEnv(getenv)
+	problems.Add(err)
+	cfg.retention, err = jobStatus.RetentionConfigFromEnv(getenv)
+	problems.Add(err)
+	cfg.flags, err = flags.FromEnv(getenv)
+	problems.Add(err)
+	cfg.forecasters, err = forecast.ForecastersFromEnv(getenv)
+	problems.Add(prefixed("forecasters", err))
+	cfg.integrity, err = jobStatus.IntegrityHasherFromEnv(getenv)
+	problems.Add(prefixed("GOJST_INTEGRITY_KEY", err))
+	cfg.mappings, err = jobStatus.FieldMappingsFromEnv(getenv)
+	problems.Add(err)
+	cfg.region, err = region.ConfigFromEnv(getenv)
+	problems.Add(err)
+
+	cfg.proxies, err = common.ParseTrustedProxies(getenv("GOJST_TRUSTED_PROXIES"))
+	problems.Add(prefixed("GOJST_TRUSTED_PROXIES", err))
+	cfg.proxyProtocol, err = config.EnvBool(getenv, "GOJST_PROXY_PROTOCOL")
+	problems.Add(err)
+	cfg.apiAllow, err = common.ParseIpAllowlist(getenv("GOJST_API_ALLOW"))
+	problems.Add(prefixed("GOJST_API_ALLOW", err))
+	cfg.adminAllow, err = common.ParseIpAllowlist(config.EnvOr(getenv, "GOJST_ADMIN_ALLOW", defaultAdminAllow))
+	problems.Add(prefixed("GOJST_ADMIN_ALLOW", err))
+	cfg.adminToken = getenv("GOJST_ADMIN_TOKEN")
+	cfg.deleteRole = getenv("GOJST_DELETE_ROLE")
+
+	cfg.deliverer, cfg.deliveryOn, err = delivery.WebhookDelivererFromEnv(getenv)
+	problems.Add(prefixed("scheduled queries", err))
+
+	cfg.selfTest, err = config.EnvBool(getenv, "GOJST_SELF_TEST")
+	problems.Add(err)
+	cfg.analystSql, err = config.EnvBool(getenv, "GOJST_ANALYST_SQL")
+	problems.Add(err)
+	cfg.envelope, err = config.EnvBool(getenv, "GOJST_RESPONSE_ENVELOPE")
+	problems.Add(err)
+
+	if getenv("GOJST_WEBHOOK_SLASH_SECRET") != "" {
+		cfg.slashVerifier, err = webhookauth.VerifierFromEnv(getenv, "slash")
+		problems.Add(prefixed("slash commands", err))
+	}
+
+	if s := getenv("GOJST_SOAK_DURATION"); s != "" {
+		if cfg.soakDuration, err = time.ParseDuration(s); err != nil || cfg.soakDuration <= 0 {
+			problems.Add(fmt.Errorf("GOJST_SOAK_DURATION %q must be a duration more than 0, like 10m", s))
+		}
+	}
+
+	return cfg, problems.Err()
+}
+
+// defaultDbUrls are the dev container's databases, by backend.
+var defaultDbUrls = map[string]string{
+	backendPostgres: "postgres://postgres:postgres@db:5432/gojst",
+	backendMysql:    "gojst:gojst@tcp(db:3306)/gojst",
+	backendSqlite:   "file:gojst.db?_busy_timeout=5000",
+}
+
+// serverConfig is where the server listens and connects, and when it runs nightly work.
+type serverConfig struct {
+	DbBackend          string        `env:"GOJST_DB_BACKEND" validate:"oneof=postgres|memory|mysql|sqlite"`
+	DbUrl              string        `env:"GOJST_DB_URL"`
+	ListenAddr         string        `env:"GOJST_LISTEN_ADDR" validate:"required"`
+	GrpcAddr           string        `env:"GOJST_GRPC_ADDR"`
+	ShutdownTimeout    time.Duration `env:"GOJST_SHUTDOWN_TIMEOUT" validate:"min=1s,max=5m"`
+	RollupHour         int
+	RollupMinute       int
+	RollupLookbackDays int `env:"GOJST_ROLLUP_LOOKBACK_DAYS" validate:"min=1,max=31"`
+	RetentionHour      int
+	RetentionMinute    int
+	TaskWorkers        int `env:"GOJST_TASK_WORKERS" validate:"min=1,max=32"`
+	TaskQueueSize      int `env:"GOJST_TASK_QUEUE_SIZE" validate:"min=1,max=1000"`
+}
+
+// serverConfigFromEnv reads the server's own settings. The defaults run it in the dev container:
+//
+//	GOJST_DB_BACKEND=postgres       postgres, memory, mysql, or sqlite; see openBackend
+//	GOJST_DB_URL=postgres://postgres:postgres@db:5432/gojst
+//	                                for mysql, gojst:gojst@tcp(db:3306)/gojst; for sqlite,
+//	                                file:gojst.db?_busy_timeout=5000; memory doesn't use it
+//	GOJST_LISTEN_ADDR=:9201
+//	GOJST_GRPC_ADDR=                where the gRPC API listens, like :9202; off when empty
+//	GOJST_SHUTDOWN_TIMEOUT=10s      longest wait for open requests after a drain
+//	GOJST_ROLLUP_AT=01:30           when the nightly rollup runs, local time
+//	GOJST_ROLLUP_LOOKBACK_DAYS=3    business dates the nightly rollup recomputes, ending yesterday
+//	GOJST_RETENTION_AT=02:30        when the retention purge runs, local time; keep it after the rollup
+//	GOJST_TASK_WORKERS=2            background admin tasks run at once
+//	GOJST_TASK_QUEUE_SIZE=20        tasks waiting before more are refused with 503
+func serverConfigFromEnv(getenv func(string) string) (serverConfig, error) {
+	cfg := serverConfig{
+		DbBackend:  config.EnvOr(getenv, "GOJST_DB_BACKEND", backendPostgres),
+		ListenAddr: config.EnvOr(getenv, "GOJST_LISTEN_ADDR", ":9201"),
+		GrpcAddr:   getenv("GOJST_GRPC_ADDR"),
+	}
+	cfg.DbUrl = config.EnvOr(getenv, "GOJST_DB_URL", defaultDbUrls[cfg.DbBackend])
+	var problems config.Problems
+	if _, _, err := net.SplitHostPort(cfg.ListenAddr); err != nil {
+		problems.Add(fmt.Errorf("GOJST_LISTEN_ADDR %q must be host:port or :port", cfg.ListenAddr))
+	}
+	if _, _, err := net.SplitHostPort(cfg.GrpcAddr); cfg.GrpcAddr != "" && err != nil {
+		problems.Add(fmt.Errorf("GOJST_GRPC_ADDR %q must be host:port or :port", cfg.GrpcAddr))
+	}
+	var err error
+	cfg.ShutdownTimeout, err = config.EnvDuration(getenv, "GOJST_SHUTDOWN_TIMEOUT", 10*time.Second)
+	problems.Add(err)
+	cfg.RollupHour, cfg.RollupMinute, err = config.EnvClock(getenv, "GOJST_ROLLUP_AT", "01:30")
+	problems.Add(err)
+	cfg.RollupLookbackDays, err = config.EnvInt(getenv, "GOJST_ROLLUP_LOOKBACK_DAYS", 3)
+	problems.Add(err)
+	cfg.RetentionHour, cfg.RetentionMinute, err = config.EnvClock(getenv, "GOJST_RETENTION_AT", "02:30")
+	problems.Add(err)
+	cfg.TaskWorkers, err = config.EnvInt(getenv, "GOJST_TASK_WORKERS", 2)
+	problems.Add(err)
+	cfg.TaskQueueSize, err = config.EnvInt(getenv, "GOJST_TASK_QUEUE_SIZE", 20)
+	problems.Add(err)
+
+	problems.Add(config.Validate(cfg))
+	if err := problems.Err(); err != nil {
+		return serverConfig{}, err
+	}
+	return cfg, nil
+}
+
+// prefixed names the setting an error is about, for errors that don't name it themselves.
+func prefixed(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
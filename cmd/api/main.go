@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/jmjf/go-jst/internal/admin"
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/deprecation"
+	"github.com/jmjf/go-jst/internal/drain"
+	"github.com/jmjf/go-jst/internal/events"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/chaos"
+	"github.com/jmjf/go-jst/internal/jobStatus/db/migrations"
+	"github.com/jmjf/go-jst/internal/jobStatus/dualwrite"
+	"github.com/jmjf/go-jst/internal/jobStatus/retry"
+	"github.com/jmjf/go-jst/internal/migrate"
+	"github.com/jmjf/go-jst/internal/region"
+	"github.com/jmjf/go-jst/internal/selftest"
+	"github.com/jmjf/go-jst/internal/slashcmd"
+	"github.com/jmjf/go-jst/internal/soak"
+	"github.com/jmjf/go-jst/internal/supervisor"
+	"github.com/jmjf/go-jst/internal/systemd"
+	"github.com/jmjf/go-jst/internal/tasks"
+	"github.com/jmjf/go-jst/internal/webhookauth"
+	"github.com/jmjf/go-jst/internal/winsvc"
+	"github.com/jmjf/go-jst/public/jobStatus/client"
+)
+
+const (
+	// finished background admin tasks kept for GET /admin/tasks
+	tasksRemembered = 200
+
+	// API call counts are written to the database this often
+	meterFlushInterval = time.Minute
+
+	// a failed startup self-test runs again this often until it passes
+	selfTestRetry = 30 * time.Second
+
+	// the event instances send each other when job aliases change
+	aliasesEvent = "job-aliases"
+
+	// the Windows service name; see winsvc.Run
+	serviceName = "gojst-api"
+)
+
+// defaultAdminAllow keeps admin routes on loopback and private networks unless GOJST_ADMIN_ALLOW says otherwise.
+const defaultAdminAllow = "127.0.0.0/8,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,fc00::/7"
+
+// rollupBackfillFrom is the first business date the rollup-backfill migration recomputes.
+var rollupBackfillFrom = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// deprecatedRoutes get Deprecation and Sunset headers, and GET /admin/deprecations reports who
+// still calls them. When a new DTO version ships, add the routes that still serve the old one:
+//
+//	{Method: http.MethodPost, Path: jobStatus.JobStatusesPath, DtoVersion: "20230701",
+//		Deprecated: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+//		Sunset:     time.Date(2027, time.July, 1, 0, 0, 0, 0, time.UTC)},
+var deprecatedRoutes = []deprecation.Notice{}
+
+func main() {
+	// under Windows' service control manager, the server runs as a service; see winsvc
+	if winsvc.IsService() {
+		if err := winsvc.Run(serviceName, run); err != nil {
+			log.Fatalf("windows service failed: %v", err)
+		}
+		return
+	}
+	run()
+}
+
+// run serves until the server is stopped.
+func run() {
+	start := time.Now()
+	// ctx ends when the server should stop: after a drain, which SIGINT, SIGTERM, a Windows
+	// service stop, or POST /admin/drain starts. A second signal stops the server without waiting.
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	// every setting is read and checked before anything starts; see loadConfig
+	cfg, err := loadConfig(os.Getenv)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// schema versions are applied before the repo prepares statements against them. A passive
+	// region's database is a replica, so it's left to the active region.
+	if cfg.migrateOnStart && cfg.region.Role == region.RoleActive && cfg.server.DbBackend == backendPostgres {
+		if err := migrateSchema(cfg.server.DbUrl); err != nil {
+			log.Fatalf("schema migration failed: %v", err)
+		}
+		if cfg.dualWriteOn {
+			if err := migrateSchema(cfg.dualWrite.SecondaryDbUrl); err != nil {
+				log.Fatalf("dual write database schema migration failed: %v", err)
+			}
+		}
+	}
+	// see openBackend for GOJST_DB_BACKEND
+	be, err := openBackend(cfg)
+	if err != nil {
+		log.Fatalf("repo open failed: %v", err)
+	}
+	defer be.close()
+
+	// see drain.ConfigFromEnv for how long a drain serves and waits
+	drainer := drain.New(cfg.drain, stop)
+	go func() {
+		select {
+		case <-signals.Done():
+		case <-winsvc.StopRequested():
+		}
+		stopSignals()
+		drainer.Drain()
+	}()
+
+	// backends that implement only jobStatus.Repo get the core routes and nothing else
+	if be.repo == nil {
+		serveCore(ctx, cfg, be.core, drainer)
+		return
+	}
+	repo := be.repo
+
+	// during a storage migration, writes also go to a second database; see dualwrite.ConfigFromEnv
+	// for settings. Background work that writes uses storeRepo too, so the databases stay alike.
+	var storeRepo jobStatus.FullRepo = repo
+	var dualRepo *dualwrite.DualRepo
+	if be.secondary != nil {
+		log.Printf("DUAL WRITE: copying writes to a second database, comparing %g of reads", cfg.dualWrite.CompareRate)
+		dualRepo = dualwrite.NewDualRepo(repo, be.secondary, cfg.dualWrite, time.Now().UnixNano())
+		storeRepo = dualRepo
+	}
+
+	// staging chaos mode; see chaos.FaultConfigFromEnv for settings
+	apiRepo := storeRepo
+	if cfg.chaosOn {
+		log.Printf("CHAOS MODE: injecting repo faults %+v", cfg.fault)
+		apiRepo = chaos.NewChaosRepo(storeRepo, cfg.fault, time.Now().UnixNano())
+	}
+	// transient database errors are retried before they reach clients; see retry.ConfigFromEnv
+	// for settings. It wraps chaos mode, so chaos mode exercises it.
+	apiRepo = retry.NewRetryRepo(apiRepo, cfg.retry, time.Now().UnixNano())
+
+	// background loops run under a supervisor that restarts them if they crash
+	sup := supervisor.New(supervisor.Config{})
+
+	taskMgr := tasks.NewManager(cfg.server.TaskQueueSize, tasksRemembered)
+	drainer.WaitFor("tasks", taskMgr.Pending)
+	sup.Add("tasks", func(ctx context.Context) error {
+		taskMgr.Run(ctx, cfg.server.TaskWorkers)
+		return nil
+	})
+
+	// per-application quotas; see jobStatus.QuotaConfigFromEnv for settings
+	quotaUC := jobStatus.NewQuotaUC(apiRepo, cfg.quota)
+
+	meterUC := jobStatus.NewMeteringUC(apiRepo, apiRepo)
+	sup.Add("metering", func(ctx context.Context) error {
+		meterUC.RunFlusher(ctx, meterFlushInterval)
+		return nil
+	})
+
+	// active or passive standby region; see region.ConfigFromEnv for settings
+	rg := region.NewFromConfig(cfg.region, repo)
+	passive := func() bool { return !rg.IsActive() }
+
+	// statuses sent under legacy JobIds are stored under the canonical ones
+	aliasUC := jobStatus.NewJobAliasUC(apiRepo, jobStatus.DefaultAliasRefresh)
+
+	// instances tell each other about alias changes over LISTEN/NOTIFY, so caches don't wait
+	// for their refresh. A passive region's database is a replica, which can't LISTEN or NOTIFY,
+	// and other backends don't have it.
+	if cfg.region.Role == region.RoleActive && be.pgDB != nil {
+		bus := events.New(cfg.server.DbUrl, be.pgDB)
+		bus.Subscribe(aliasesEvent, func(events.Event) { aliasUC.Invalidate() })
+		aliasUC.PublishChanges(func() {
+			if err := bus.Publish(ctx, events.Event{Kind: aliasesEvent}); err != nil {
+				log.Printf("events: publish %s failed; other instances will reload within %s: %v", aliasesEvent, jobStatus.DefaultAliasRefresh, err)
+			}
+		})
+		sup.Add("events", func(ctx context.Context) error {
+			bus.Run(ctx)
+			return nil
+		})
+	}
+
+	// startup self-test is off unless GOJST_SELF_TEST=true; until it passes, the instance isn't ready
+	var selfTest *selftest.Suite
+	if cfg.selfTest {
+		checks := []selftest.Check{
+			selftest.DatabaseCheck(repo, rg.IsActive),
+			selftest.ClockCheck(repo, selftest.DefaultMaxClockSkew),
+		}
+		if cfg.deliveryOn {
+			checks = append(checks, selftest.Check{Name: "delivery", Run: cfg.deliverer.SelfTest})
+		}
+		selfTest = selftest.NewSuite(checks...)
+		sup.Add("self-test", func(ctx context.Context) error {
+			selfTest.RunUntilPassed(ctx, selfTestRetry)
+			return nil
+		})
+	}
+
+	mux := http.NewServeMux()
+	addProbes(mux, rg, selfTest, drainer)
+	adminRoute := newAdminRoute(cfg)
+	ports := jobStatus.FullPorts(apiRepo)
+	svc := jobStatus.Services{
+		Tasks:         taskMgr,
+		Quota:         quotaUC,
+		Meter:         meterUC,
+		Flags:         cfg.flags,
+		Aliases:       aliasUC,
+		Forecasters:   cfg.forecasters,
+		Integrity:     cfg.integrity,
+		FieldMappings: cfg.mappings,
+		AdminRoute:    adminRoute,
+	}
+	jobStatus.AddRoutes(mux, ports, svc)
+
+	mux.Handle(admin.InfoPath, adminRoute(common.MethodHandler{
+		http.MethodGet: admin.NewInfoCtrl(start, rg, selfTest, sup, dualRepo),
+	}))
+	mux.Handle("/admin/profiles", adminRoute(common.MethodHandler{
+		http.MethodGet: admin.NewProfileCtrl(),
+	}))
+	mux.Handle(jobStatus.TaskStatusPath, adminRoute(common.MethodHandler{
+		http.MethodGet: admin.NewTaskCtrl(taskMgr),
+	}))
+	mux.Handle(jobStatus.QuotaUsagePath, adminRoute(common.MethodHandler{
+		http.MethodGet: jobStatus.NewGetQuotaUsageCtrl(quotaUC),
+	}))
+	mux.Handle(jobStatus.MeteringReportPath, adminRoute(common.MethodHandler{
+		http.MethodGet: jobStatus.NewGetMeteringReportCtrl(meterUC),
+	}))
+	// online migrations run as tasks and checkpoint in the database, so use the real repo, not chaos
+	migrations := migrate.NewRunner(repo, taskMgr)
+	migrations.Register(jobStatus.NewRollupBackfill(jobStatus.NewDailyRollupUC(storeRepo), rollupBackfillFrom))
+	migrationCtrl := admin.NewMigrationCtrl(migrations)
+	mux.Handle("/admin/migrations", adminRoute(common.MethodHandler{
+		http.MethodGet:  migrationCtrl,
+		http.MethodPost: migrationCtrl,
+	}))
+	mux.Handle(jobStatus.JobRenamePath, adminRoute(common.MethodHandler{
+		http.MethodPost: jobStatus.NewJobRenameCtrl(jobStatus.NewJobRenameUC(apiRepo, aliasUC)),
+	}))
+	correctionUC := jobStatus.NewStatusCorrectionUC(apiRepo, cfg.integrity, aliasUC)
+	mux.Handle(jobStatus.StatusCorrectionsPath, adminRoute(common.MethodHandler{
+		http.MethodGet:   jobStatus.NewListStatusCorrectionsCtrl(correctionUC),
+		http.MethodPut:   jobStatus.NewReplaceJobStatusCtrl(correctionUC),
+		http.MethodPatch: jobStatus.NewPatchJobStatusCtrl(correctionUC),
+	}))
+	// deleting loses data the admin token alone shouldn't be enough for
+	mux.Handle(jobStatus.StatusDeletePath, adminRoute(common.MethodHandler{
+		http.MethodDelete: common.RequireRole(cfg.deleteRole, jobStatus.NewDeleteJobStatusCtrl(correctionUC)),
+	}))
+	mux.Handle(jobStatus.FieldMappingsPath, adminRoute(common.MethodHandler{
+		http.MethodGet: jobStatus.NewGetFieldMappingsCtrl(cfg.mappings),
+	}))
+	mux.Handle(jobStatus.IntegrityPath, adminRoute(common.MethodHandler{
+		http.MethodGet: jobStatus.NewGetIntegrityCtrl(jobStatus.NewIntegrityUC(apiRepo, apiRepo, cfg.integrity)),
+	}))
+	mux.Handle(jobStatus.SnapshotPath, adminRoute(common.MethodHandler{
+		http.MethodGet: jobStatus.NewGetSnapshotCtrl(jobStatus.NewSnapshotUC(apiRepo)),
+	}))
+	mux.Handle(jobStatus.JobAliasesPath, adminRoute(common.MethodHandler{
+		http.MethodGet:    jobStatus.NewGetJobAliasesCtrl(aliasUC),
+		http.MethodPut:    jobStatus.NewPutJobAliasCtrl(aliasUC),
+		http.MethodDelete: jobStatus.NewDeleteJobAliasCtrl(aliasUC),
+	}))
+	deprecations, err := deprecation.NewTracker(deprecatedRoutes...)
+	if err != nil {
+		log.Fatalf("deprecated routes: %v", err)
+	}
+	mux.Handle(deprecation.AdminPath, adminRoute(common.MethodHandler{
+		http.MethodGet: admin.NewDeprecationCtrl(deprecations),
+	}))
+	addDrainAndRegionRoutes(mux, adminRoute, drainer, rg)
+	flagCtrl := admin.NewFlagCtrl(cfg.flags)
+	mux.Handle("/admin/flags", adminRoute(common.MethodHandler{
+		http.MethodGet:    flagCtrl,
+		http.MethodPut:    flagCtrl,
+		http.MethodDelete: flagCtrl,
+	}))
+
+	// analyst SQL is off unless GOJST_ANALYST_SQL=true; see jobStatus.ValidateReadOnlySql for what's allowed
+	if cfg.analystSql {
+		mux.Handle(jobStatus.SqlQueryPath, adminRoute(common.MethodHandler{
+			http.MethodPost: jobStatus.NewSqlQueryCtrl(jobStatus.NewSqlQueryUC(apiRepo)),
+		}))
+	}
+
+	// slash commands are off unless GOJST_WEBHOOK_SLASH_SECRET is set; use GOJST_WEBHOOK_SLASH_SCHEME=slack
+	if cfg.slashVerifier != nil {
+		slashCtrl := slashcmd.NewCtrl(jobStatus.NewGetJobStatusesUC(apiRepo, apiRepo, apiRepo), jobStatus.NewStatusBoardUC(apiRepo, apiRepo))
+		mux.Handle(slashcmd.Path, common.MethodHandler{
+			http.MethodPost: webhookauth.Require(cfg.slashVerifier, slashCtrl),
+		})
+	}
+
+	// scheduled queries are off unless GOJST_DELIVERY_SECRET is set; deliveries are signed with it
+	if cfg.deliveryOn {
+		scheduledUC := jobStatus.NewScheduledQueryUC(apiRepo, jobStatus.NewGetJobStatusesUC(apiRepo, apiRepo, apiRepo), cfg.deliverer)
+		mux.Handle(jobStatus.ScheduledQueriesPath, adminRoute(common.MethodHandler{
+			http.MethodGet:    jobStatus.NewGetScheduledQueriesCtrl(scheduledUC),
+			http.MethodPut:    jobStatus.NewPutScheduledQueryCtrl(scheduledUC),
+			http.MethodDelete: jobStatus.NewDeleteScheduledQueryCtrl(scheduledUC),
+		}))
+		sup.Add("scheduled-queries", func(ctx context.Context) error {
+			jobStatus.RunScheduledQueries(ctx, scheduledUC, time.Minute, passive)
+			return nil
+		})
+	}
+
+	sup.Add("nightly-rollup", func(ctx context.Context) error {
+		jobStatus.RunNightlyRollup(ctx, jobStatus.NewDailyRollupUC(storeRepo), jobStatus.NightlyRollupConfig{
+			RunAtHour:    cfg.server.RollupHour,
+			RunAtMinute:  cfg.server.RollupMinute,
+			LookbackDays: cfg.server.RollupLookbackDays,
+			Paused:       passive,
+		})
+		return nil
+	})
+
+	// statuses are kept forever unless GOJST_RETENTION_DAYS or GOJST_RETENTION_OVERRIDES say
+	// otherwise; see jobStatus.RetentionConfigFromEnv for settings
+	if cfg.retention.On() {
+		retentionUC := jobStatus.NewRetentionUC(apiRepo, cfg.retention)
+		sup.Add("retention", func(ctx context.Context) error {
+			jobStatus.RunNightlyRetention(ctx, retentionUC, cfg.server.RetentionHour, cfg.server.RetentionMinute, passive)
+			return nil
+		})
+	}
+
+	// under a systemd unit with WatchdogSec, systemd restarts the server if these pings stop
+	if interval := systemd.WatchdogInterval(); interval > 0 {
+		sup.Add("systemd-watchdog", func(ctx context.Context) error {
+			systemd.RunWatchdog(ctx, interval, nil)
+			return nil
+		})
+	}
+
+	supDone := make(chan struct{})
+	go func() {
+		sup.Run(ctx)
+		close(supDone)
+	}()
+
+	// responses are wrapped in an envelope for clients that ask with Accept, or by default if
+	// GOJST_RESPONSE_ENVELOPE=true; see jobStatus.Envelope
+	handler := jobStatus.Envelope(deprecations.Wrap(mux), cfg.envelope)
+
+	// built-in soak mode: GOJST_SOAK_DURATION=10m sends traffic to this server, verifies it, and logs the report
+	if cfg.soakDuration > 0 {
+		go runSoak(ctx, cfg.server.ListenAddr, cfg.soakDuration)
+	}
+
+	// the gRPC API, on GOJST_GRPC_ADDR if it's set, serves adds and jobId queries with the same ports
+	serve(ctx, cfg, rg, drainer, handler, newGrpcServer(cfg, rg, drainer, ports, svc))
+	// let background loops stop, including the last metering flush, before the deferred repo.Close
+	<-supDone
+}
+
+func migrateSchema(pgUrl string) error {
+	sqlDB, err := sql.Open("pgx", pgUrl)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	return migrations.Migrate(context.Background(), sqlDB)
+}
+
+func runSoak(ctx context.Context, listenAddr string, d time.Duration) {
+	// give ListenAndServe a moment to start
+	time.Sleep(time.Second)
+	log.Printf("soak starting for %s", d)
+	_, port, _ := net.SplitHostPort(listenAddr)
+	report, err := soak.Run(ctx, client.New("http://"+net.JoinHostPort("localhost", port), nil), soak.Config{Duration: d, Workers: 4})
+	if err != nil {
+		log.Printf("soak verify failed: %v", err)
+	}
+	log.Print(report)
+	for _, disc := range report.Discrepancies {
+		log.Printf("soak discrepancy: %s", disc)
+	}
+}
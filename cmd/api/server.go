@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/jmjf/go-jst/internal/admin"
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/drain"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/region"
+	"github.com/jmjf/go-jst/internal/selftest"
+	"github.com/jmjf/go-jst/internal/slashcmd"
+	"github.com/jmjf/go-jst/internal/systemd"
+	"github.com/jmjf/go-jst/internal/winsvc"
+)
+
+// addProbes registers the health, readiness, startup, and liveness probes. selfTest may be nil.
+func addProbes(mux *http.ServeMux, rg *region.Region, selfTest *selftest.Suite, drainer *drain.Drainer) {
+	mux.Handle(region.HealthPath, common.MethodHandler{http.MethodGet: region.NewHealthCtrl(rg)})
+	mux.Handle(region.ReadyPath, common.MethodHandler{http.MethodGet: region.NewReadyCtrl(rg, selfTest, drainer)})
+	mux.Handle(region.StartupPath, common.MethodHandler{http.MethodGet: region.NewStartupCtrl(selfTest)})
+	mux.Handle(region.LivePath, common.MethodHandler{http.MethodGet: region.NewHealthCtrl(rg)})
+	mux.Handle(region.InstanceReadyPath, common.MethodHandler{http.MethodGet: region.NewInstanceReadyCtrl(rg, selfTest, drainer)})
+}
+
+// newAdminRoute guards admin routes: they're refused unless GOJST_ADMIN_TOKEN is set, and only
+// reachable from GOJST_ADMIN_ALLOW.
+func newAdminRoute(cfg apiConfig) func(common.MethodHandler) http.Handler {
+	return func(h common.MethodHandler) http.Handler {
+		return common.RequireAllowedIp(cfg.adminAllow, common.RequireBearerToken(cfg.adminToken, h))
+	}
+}
+
+// addDrainAndRegionRoutes registers the admin routes that drain the instance and change its region role.
+func addDrainAndRegionRoutes(mux *http.ServeMux, adminRoute func(common.MethodHandler) http.Handler, drainer *drain.Drainer, rg *region.Region) {
+	drainCtrl := admin.NewDrainCtrl(drainer)
+	mux.Handle(drain.AdminPath, adminRoute(common.MethodHandler{
+		http.MethodGet:  drainCtrl,
+		http.MethodPost: drainCtrl,
+	}))
+	regionCtrl := admin.NewRegionCtrl(rg)
+	mux.Handle(region.AdminPath, adminRoute(common.MethodHandler{
+		http.MethodGet: regionCtrl,
+		http.MethodPut: regionCtrl,
+	}))
+}
+
+// serve listens on GOJST_LISTEN_ADDR and serves handler until ctx ends, then shuts down, waiting
+// up to GOJST_SHUTDOWN_TIMEOUT for requests in flight.
+// grpcServer, if it isn't nil, is served on GOJST_GRPC_ADDR alongside; see newGrpcServer.
+func serve(ctx context.Context, cfg apiConfig, rg *region.Region, drainer *drain.Drainer, handler http.Handler, grpcServer *grpc.Server) {
+	// network policy; client addresses come from X-Forwarded-For or PROXY protocol headers, and
+	// the signed-in user from X-Forwarded-User, only when the connection is from GOJST_TRUSTED_PROXIES
+	// requests in flight are counted for drains, except probes and the drain itself
+	server := &http.Server{Addr: cfg.server.ListenAddr, Handler: drainer.Track(common.RecoverPanics(common.AssignRequestId(cfg.proxies.ResolveClientIp(cfg.proxies.ResolveUser(common.RequireAllowedIp(cfg.apiAllow, rg.RejectWritesWhenPassive(handler, region.AdminPath, drain.AdminPath, slashcmd.Path, jobStatus.SqlQueryPath)))))), drain.AdminPath, region.HealthPath, region.ReadyPath, region.StartupPath, region.LivePath, region.InstanceReadyPath)}
+	go func() {
+		<-ctx.Done()
+		if _, err := systemd.Stopping(); err != nil {
+			log.Printf("systemd notify failed: %v", err)
+		}
+		winsvc.Stopping()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.server.ShutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	ln := listen(cfg, cfg.server.ListenAddr)
+	if grpcServer != nil {
+		go serveGrpc(ctx, cfg, grpcServer)
+	}
+	if _, err := systemd.Ready(); err != nil {
+		log.Printf("systemd notify failed: %v", err)
+	}
+	winsvc.Ready()
+	if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("server failed: %v", err)
+	}
+}
+
+// listen listens on addr, reading PROXY protocol headers if GOJST_PROXY_PROTOCOL is set.
+func listen(cfg apiConfig, addr string) net.Listener {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf("listen failed: %v", err)
+	}
+	if cfg.proxyProtocol {
+		ln = common.NewProxyProtocolListener(ln, cfg.proxies)
+	}
+	log.Printf("listening on %s (PROXY protocol %v)", addr, cfg.proxyProtocol)
+	return ln
+}
+
+// newGrpcServer returns the gRPC API's server, or nil if GOJST_GRPC_ADDR is empty. Calls get the
+// HTTP middleware's treatment from interceptors: they're counted for drains, recovered from
+// panics, given request ids, checked against GOJST_API_ALLOW, and refused writes while the region
+// is passive. Quotas and metering are in the use cases, so they apply as they do for HTTP.
+func newGrpcServer(cfg apiConfig, rg *region.Region, drainer *drain.Drainer, ports jobStatus.Ports, svc jobStatus.Services) *grpc.Server {
+	if cfg.server.GrpcAddr == "" {
+		return nil
+	}
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		drainer.TrackGrpc,
+		common.GrpcRecoverPanics,
+		common.GrpcAssignRequestId,
+		common.GrpcRequireAllowedIp(cfg.apiAllow),
+		rg.RejectGrpcWritesWhenPassive(jobStatus.GrpcWriteMethods...),
+	))
+	jobStatus.RegisterGrpcService(server, ports, svc)
+	return server
+}
+
+// serveGrpc serves server on GOJST_GRPC_ADDR until ctx ends, then stops it, waiting up to
+// GOJST_SHUTDOWN_TIMEOUT for calls in flight.
+func serveGrpc(ctx context.Context, cfg apiConfig, server *grpc.Server) {
+	go func() {
+		<-ctx.Done()
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(cfg.server.ShutdownTimeout):
+			server.Stop()
+		}
+	}()
+
+	if err := server.Serve(listen(cfg, cfg.server.GrpcAddr)); err != nil {
+		log.Fatalf("gRPC server failed: %v", err)
+	}
+}
+
+// serveCore serves a backend that implements only jobStatus.Repo; see newCoreHandler.
+func serveCore(ctx context.Context, cfg apiConfig, repo coreRepo, drainer *drain.Drainer) {
+	log.Printf("the %s backend serves only adds and jobId queries; reports, boards, and admin data routes need postgres or memory", cfg.server.DbBackend)
+	rg := region.NewFromConfig(cfg.region, repo)
+	handler, grpcServer := newCoreHandler(cfg, repo, rg, drainer)
+	serve(ctx, cfg, rg, drainer, handler, grpcServer)
+}
+
+// newCoreHandler serves adds, batches, and jobId queries, with the probes, drains, and region
+// role, and the gRPC API's server if it's on. Routes that need other ports aren't mounted, and the
+// background work that needs them (rollups, retention, metering, scheduled queries, online
+// migrations, self-tests) doesn't run.
+func newCoreHandler(cfg apiConfig, repo jobStatus.Repo, rg *region.Region, drainer *drain.Drainer) (http.Handler, *grpc.Server) {
+	mux := http.NewServeMux()
+	addProbes(mux, rg, nil, drainer)
+	adminRoute := newAdminRoute(cfg)
+	ports := jobStatus.Ports{Repo: repo}
+	svc := jobStatus.Services{
+		Flags:         cfg.flags,
+		Integrity:     cfg.integrity,
+		FieldMappings: cfg.mappings,
+		AdminRoute:    adminRoute,
+	}
+	jobStatus.AddRoutes(mux, ports, svc)
+	addDrainAndRegionRoutes(mux, adminRoute, drainer, rg)
+	return jobStatus.Envelope(mux, cfg.envelope), newGrpcServer(cfg, rg, drainer, ports, svc)
+}
@@ -0,0 +1,79 @@
+// Command goslo-report sends one job status, so shell scripts and jobs not written in Go can
+// report with one line. It prints the status's RunId, for the run's later statuses, and exits 1
+// if the status wasn't stored or spooled. Flags can be written -app or --app.
+//
+//	RUN=$(go run ./cmd/goslo-report -app overdrafts -job od-calc -status START)
+//	go run ./cmd/goslo-report -app overdrafts -job od-calc -status SUCCEED -run "$RUN"
+//
+// With -spool, a status the API can't take is kept in that directory and sent by the next
+// report that can reach the API (see reporter).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jmjf/go-jst/public/jobStatus/client"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+	"github.com/jmjf/go-jst/public/jobStatus/reporter"
+)
+
+func main() {
+	host, _ := os.Hostname()
+
+	baseUrl := flag.String("url", "http://localhost:9201", "base URL of the job status API")
+	appId := flag.String("app", "", "application id")
+	jobId := flag.String("job", "", "job id")
+	status := flag.String("status", "", "START, SUCCEED, or FAIL")
+	busDt := flag.String("busdt", time.Now().Format(dto.DateFormat), "business date (YYYY-MM-DD)")
+	runId := flag.String("run", "", "run id; empty starts a new run")
+	hostId := flag.String("host", host, "host the job runs on")
+	spoolDir := flag.String("spool", "", "directory to keep statuses in while the API is unreachable")
+	timeout := flag.Duration("timeout", 10*time.Second, "how long to wait for the API")
+	flag.Parse()
+
+	jsDto := dto.JobStatusDto{
+		ApplicationId:      *appId,
+		JobId:              *jobId,
+		JobStatusCode:      *status,
+		JobStatusTimestamp: time.Now().UTC().Format(dto.TimestampFormat),
+		BusinessDate:       *busDt,
+		RunId:              *runId,
+		HostId:             *hostId,
+	}
+	c := client.New(*baseUrl, &http.Client{Timeout: *timeout})
+
+	var err error
+	if *spoolDir == "" {
+		jsDto, err = c.AddJobStatus(jsDto)
+	} else {
+		jsDto, err = spoolAndSend(c, *spoolDir, jsDto)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "report failed:", err)
+		os.Exit(1)
+	}
+	fmt.Println(jsDto.RunId)
+}
+
+// spoolAndSend sends statuses earlier reports spooled, then this one.
+func spoolAndSend(c *client.Client, spoolDir string, jsDto dto.JobStatusDto) (dto.JobStatusDto, error) {
+	rep, err := reporter.New(c, reporter.Config{SpoolDir: spoolDir})
+	if err != nil {
+		return jsDto, err
+	}
+	// a failed resend isn't this status's failure; Report spools it behind the others
+	if sent, _ := rep.Resend(); sent > 0 {
+		fmt.Fprintf(os.Stderr, "resent %d spooled statuses\n", sent)
+	}
+	if jsDto, err = rep.Report(jsDto); err != nil {
+		return jsDto, err
+	}
+	if waiting, _ := rep.Pending(); waiting > 0 {
+		fmt.Fprintf(os.Stderr, "API unreachable; %d waiting in %s\n", waiting, spoolDir)
+	}
+	return jsDto, nil
+}
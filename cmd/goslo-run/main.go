@@ -0,0 +1,184 @@
+// Command goslo-run runs a command and reports it as a job: START before it starts, then SUCCEED
+// if it exits 0 or FAIL if it doesn't, with the exit code and how long it ran as the status's
+// exitCode and durationMs metadata. Legacy scripts get statuses without changing them. It exits
+// with the command's exit code, so it can replace the command in a scheduler.
+//
+//	go run ./cmd/goslo-run -app overdrafts -job od-calc -heartbeat 1m -- ./calc.sh --date 2024-05-01
+//
+// With -heartbeat, it sends a heartbeat for the run that often while the command runs, so a
+// hung job shows as a START whose HeartbeatTs stops moving. SIGINT and SIGTERM are passed to
+// the command, so stopping goslo-run stops the command and reports FAIL. A status or heartbeat
+// that can't be sent doesn't stop the command; with -spool, statuses are kept and sent later
+// (see reporter). Heartbeats are only worth sending while they're current, so they aren't
+// spooled.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/jmjf/go-jst/public/jobStatus/client"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+	"github.com/jmjf/go-jst/public/jobStatus/reporter"
+)
+
+// exitNotStarted is the shell's exit code for a command that couldn't be run.
+const exitNotStarted = 127
+
+func main() {
+	host, _ := os.Hostname()
+
+	baseUrl := flag.String("url", "http://localhost:9201", "base URL of the job status API")
+	appId := flag.String("app", "", "application id")
+	jobId := flag.String("job", "", "job id")
+	busDt := flag.String("busdt", time.Now().Format(dto.DateFormat), "business date (YYYY-MM-DD)")
+	runId := flag.String("run", "", "run id; empty makes one")
+	hostId := flag.String("host", host, "host the job runs on")
+	spoolDir := flag.String("spool", "", "directory to keep statuses in while the API is unreachable")
+	timeout := flag.Duration("timeout", 10*time.Second, "how long to wait for the API")
+	heartbeat := flag.Duration("heartbeat", 0, "how often to send a heartbeat while the command runs; 0 sends none")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: goslo-run -app APP -job JOB [flags] -- command [args...]")
+		os.Exit(2)
+	}
+	if *heartbeat < 0 {
+		fmt.Fprintln(os.Stderr, "goslo-run: -heartbeat can't be negative")
+		os.Exit(2)
+	}
+	if *runId == "" {
+		*runId = reporter.NewRunId()
+	}
+
+	c := client.New(*baseUrl, &http.Client{Timeout: *timeout})
+	send, err := newSender(c, *spoolDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "goslo-run:", err)
+		os.Exit(1)
+	}
+	status := func(code string, metadata map[string]string) {
+		err := send(dto.JobStatusDto{
+			ApplicationId:      *appId,
+			JobId:              *jobId,
+			JobStatusCode:      code,
+			JobStatusTimestamp: time.Now().UTC().Format(dto.TimestampFormat),
+			BusinessDate:       *busDt,
+			RunId:              *runId,
+			HostId:             *hostId,
+			Metadata:           metadata,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "goslo-run: %s not reported: %v\n", code, err)
+		}
+	}
+
+	status("START", nil)
+	started := time.Now()
+	stopHeartbeats := startHeartbeats(c, *heartbeat, dto.HeartbeatDto{JobId: *jobId, BusinessDate: *busDt, RunId: *runId})
+	exitCode := runCommand(flag.Args())
+	stopHeartbeats()
+	duration := time.Since(started)
+
+	metadata := map[string]string{
+		"exitCode":   strconv.Itoa(exitCode),
+		"durationMs": strconv.FormatInt(duration.Milliseconds(), 10),
+	}
+	if exitCode == 0 {
+		status("SUCCEED", metadata)
+	} else {
+		status("FAIL", metadata)
+	}
+	fmt.Fprintf(os.Stderr, "goslo-run: %s run %s exited %d after %s\n", *jobId, *runId, exitCode, duration.Round(time.Millisecond))
+	os.Exit(exitCode)
+}
+
+// startHeartbeats sends hb every interval until the returned function is called, which waits
+// for a heartbeat in flight so it can't land after the run's last status. A zero interval sends
+// none. A heartbeat that fails is reported on stderr and the next one is tried on time.
+func startHeartbeats(c *client.Client, interval time.Duration, hb dto.HeartbeatDto) (stop func()) {
+	if interval == 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				hb.HeartbeatTimestamp = now.UTC().Format(dto.TimestampFormat)
+				if _, err := c.RecordHeartbeat(hb); err != nil {
+					fmt.Fprintln(os.Stderr, "goslo-run: heartbeat not sent:", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// runCommand runs args with goslo-run's stdin, stdout, and stderr, passing on SIGINT and SIGTERM, and
+// returns its exit code. A command killed by a signal returns 128 plus the signal, like a shell.
+func runCommand(args []string) int {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintln(os.Stderr, "goslo-run:", err)
+		return exitNotStarted
+	}
+	go func() {
+		for sig := range signals {
+			cmd.Process.Signal(sig)
+		}
+	}()
+
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		fmt.Fprintln(os.Stderr, "goslo-run:", err)
+		return 1
+	}
+	if ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+	return cmd.ProcessState.ExitCode()
+}
+
+// newSender returns a function that sends one status, through a reporter if spoolDir is set.
+func newSender(c *client.Client, spoolDir string) (func(dto.JobStatusDto) error, error) {
+	if spoolDir == "" {
+		return func(jsDto dto.JobStatusDto) error {
+			_, err := c.AddJobStatus(jsDto)
+			return err
+		}, nil
+	}
+
+	rep, err := reporter.New(c, reporter.Config{SpoolDir: spoolDir})
+	if err != nil {
+		return nil, err
+	}
+	return func(jsDto dto.JobStatusDto) error {
+		rep.Resend()
+		_, err := rep.Report(jsDto)
+		return err
+	}, nil
+}
@@ -0,0 +1,75 @@
+// Command integrity asks a job status API to verify an application's stored statuses against
+// their integrity hashes and prints the report. It exits 1 if any status doesn't match.
+//
+//	GOJST_ADMIN_TOKEN=... go run ./cmd/integrity -url http://localhost:9201 -app overdrafts -from 2024-05-01 -to 2024-05-31
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+const integrityPath = "/admin/integrity"
+
+func main() {
+	baseUrl := flag.String("url", "http://localhost:9201", "base URL of the job status API")
+	appId := flag.String("app", "", "application id to verify")
+	from := flag.String("from", "", "first business date (YYYY-MM-DD)")
+	to := flag.String("to", "", "last business date (YYYY-MM-DD); defaults to -from")
+	flag.Parse()
+	if *to == "" {
+		*to = *from
+	}
+
+	reports, err := verify(*baseUrl, os.Getenv("GOJST_ADMIN_TOKEN"), *appId, *from, *to)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "verify failed:", err)
+		os.Exit(1)
+	}
+
+	mismatched := 0
+	for _, r := range reports {
+		fmt.Printf("%s %s rows=%d verified=%d unhashed=%d mismatched=%d digest=%s\n",
+			r.ApplicationId, r.BusinessDate, r.RowCount, r.VerifiedCount, r.UnhashedCount, len(r.MismatchedStatusIds), r.Digest)
+		for _, id := range r.MismatchedStatusIds {
+			fmt.Println("   mismatch", id)
+		}
+		mismatched += len(r.MismatchedStatusIds)
+	}
+	if mismatched > 0 {
+		os.Exit(1)
+	}
+}
+
+func verify(baseUrl string, token string, appId string, from string, to string) ([]dto.IntegrityReportDto, error) {
+	q := url.Values{"appId": {appId}, "fromDt": {from}, "toDt": {to}}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(baseUrl, "/")+integrityPath+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("API returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var reports []dto.IntegrityReportDto
+	if err := json.NewDecoder(resp.Body).Decode(&reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
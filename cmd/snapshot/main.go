@@ -0,0 +1,87 @@
+// Command snapshot downloads a snapshot archive from a job status API and verifies it, or
+// verifies an archive already on disk. It exits 1 if the download fails or the archive doesn't
+// match its manifest.
+//
+//	GOJST_ADMIN_TOKEN=... go run ./cmd/snapshot -url http://localhost:9201 -out snapshot.zip
+//	go run ./cmd/snapshot -verify snapshot.zip
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+func main() {
+	baseUrl := flag.String("url", "http://localhost:9201", "base URL of the job status API")
+	out := flag.String("out", "snapshot.zip", "file to download the snapshot to")
+	verifyOnly := flag.String("verify", "", "verify this archive instead of downloading one")
+	flag.Parse()
+
+	path := *verifyOnly
+	if path == "" {
+		path = *out
+		if err := download(*baseUrl, os.Getenv("GOJST_ADMIN_TOKEN"), path); err != nil {
+			fmt.Fprintln(os.Stderr, "download failed:", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := verify(path); err != nil {
+		fmt.Fprintln(os.Stderr, "verify failed:", err)
+		os.Exit(1)
+	}
+}
+
+func download(baseUrl string, token string, path string) error {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(baseUrl, "/")+jobStatus.SnapshotPath, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("API returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func verify(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := jobStatus.VerifySnapshot(f, info.Size())
+	fmt.Printf("%s created %s format %s\n", path, manifest.CreatedTs, manifest.FormatVersion)
+	for _, file := range manifest.Files {
+		fmt.Printf("   %-24s rows=%d bytes=%d sha256=%s\n", file.Name, file.Rows, file.Bytes, file.Sha256)
+	}
+	return err
+}
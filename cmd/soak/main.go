@@ -0,0 +1,48 @@
+// Command soak sends sustained traffic to a job status API and verifies what was stored.
+// It exits 1 if there are discrepancies.
+//
+//	go run ./cmd/soak -url http://localhost:9201 -duration 10m -workers 8 -rate 200
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/soak"
+	"github.com/jmjf/go-jst/public/jobStatus/client"
+)
+
+func main() {
+	url := flag.String("url", "http://localhost:9201", "base URL of the job status API")
+	duration := flag.Duration("duration", time.Minute, "how long to send traffic")
+	workers := flag.Int("workers", 4, "concurrent senders")
+	rate := flag.Int("rate", 0, "statuses per second across all workers; 0 is unlimited")
+	jobs := flag.Int("jobs", 10, "number of job ids to spread statuses over")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := soak.Run(ctx, client.New(*url, nil), soak.Config{
+		Duration:      *duration,
+		Workers:       *workers,
+		RatePerSecond: *rate,
+		JobCount:      *jobs,
+	})
+	fmt.Println(report)
+	for _, d := range report.Discrepancies {
+		fmt.Println("  ", d)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "verify failed:", err)
+		os.Exit(1)
+	}
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,23 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/deprecation"
+)
+
+// DeprecationCtrl reports who still calls deprecated routes.
+type DeprecationCtrl struct {
+	tracker *deprecation.Tracker
+}
+
+func NewDeprecationCtrl(t *deprecation.Tracker) *DeprecationCtrl {
+	return &DeprecationCtrl{tracker: t}
+}
+
+// ServeHTTP handles GET. Counts start over when the server restarts, and each instance counts
+// its own calls.
+func (ctrl *DeprecationCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	common.WriteJson(w, http.StatusOK, ctrl.tracker.Usage())
+}
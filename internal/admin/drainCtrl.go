@@ -0,0 +1,27 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/drain"
+)
+
+// DrainCtrl shows or starts a drain, for Kubernetes preStop hooks and deploy scripts.
+type DrainCtrl struct {
+	drainer *drain.Drainer
+}
+
+func NewDrainCtrl(d *drain.Drainer) *DrainCtrl {
+	return &DrainCtrl{drainer: d}
+}
+
+// ServeHTTP handles GET to show the drain's state and POST to drain. POST answers when the drain
+// is done, just before the server shuts down, so a preStop hook holds SIGTERM until then.
+func (ctrl *DrainCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		common.WriteJson(w, http.StatusOK, ctrl.drainer.State())
+		return
+	}
+	common.WriteJson(w, http.StatusOK, ctrl.drainer.Drain())
+}
@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/flags"
+)
+
+// FlagCtrl shows and changes feature flags at runtime. Changes last until the server restarts;
+// set GOJST_FLAGS or GOJST_FLAG_OVERRIDES to keep them.
+type FlagCtrl struct {
+	flags *flags.Flags
+}
+
+func NewFlagCtrl(f *flags.Flags) *FlagCtrl {
+	return &FlagCtrl{flags: f}
+}
+
+// ServeHTTP handles GET to list flags, PUT with query parameters flag, on, and optional appId
+// to set an override, and DELETE with flag and optional appId to remove one. Without appId,
+// PUT and DELETE change the global override.
+func (ctrl *FlagCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	switch r.Method {
+	case http.MethodPut:
+		on, err := strconv.ParseBool(q.Get("on"))
+		if err != nil {
+			common.WriteError(w, r, propsError("on must be true or false"))
+			return
+		}
+		if err := ctrl.flags.Set(q.Get("flag"), q.Get("appId"), on); err != nil {
+			common.WriteError(w, r, common.NewCommonError(common.ErrcdDomainProps, err))
+			return
+		}
+	case http.MethodDelete:
+		ctrl.flags.Clear(q.Get("flag"), q.Get("appId"))
+	}
+	common.WriteJson(w, http.StatusOK, ctrl.flags.States())
+}
@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus/dualwrite"
+	"github.com/jmjf/go-jst/internal/region"
+	"github.com/jmjf/go-jst/internal/selftest"
+	"github.com/jmjf/go-jst/internal/supervisor"
+)
+
+// InfoPath is the route for debug info.
+const InfoPath = "/debug/info"
+
+// DebugInfo describes the running server, for checking what's deployed and why it isn't ready.
+type DebugInfo struct {
+	GoVersion  string                    `json:"GoVersion"`
+	Revision   string                    `json:"Revision,omitempty"`
+	StartTs    string                    `json:"StartTs"`
+	UptimeSecs int64                     `json:"UptimeSecs"`
+	Region     region.State              `json:"Region"`
+	SelfTest   selftest.Report           `json:"SelfTest"`
+	Workers    []supervisor.WorkerStatus `json:"Workers"`
+	// Panics counts panics recovered from requests and background work since the server started.
+	Panics int64 `json:"Panics"`
+	// DualWrite is only there while writes are copied to a second database.
+	DualWrite *dualwrite.Stats `json:"DualWrite,omitempty"`
+}
+
+type InfoCtrl struct {
+	start    time.Time
+	region   *region.Region
+	selfTest *selftest.Suite
+	workers  *supervisor.Supervisor
+	dualRepo *dualwrite.DualRepo
+}
+
+// NewInfoCtrl reports uptime from start. selfTest may be nil if self-tests are off, and dualRepo
+// if dual writes are.
+func NewInfoCtrl(start time.Time, rg *region.Region, selfTest *selftest.Suite, workers *supervisor.Supervisor, dualRepo *dualwrite.DualRepo) *InfoCtrl {
+	return &InfoCtrl{start: start, region: rg, selfTest: selfTest, workers: workers, dualRepo: dualRepo}
+}
+
+// ServeHTTP handles GET.
+func (ctrl *InfoCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	info := DebugInfo{
+		GoVersion:  runtime.Version(),
+		StartTs:    ctrl.start.UTC().Format(time.RFC3339),
+		UptimeSecs: int64(time.Since(ctrl.start).Seconds()),
+		Region:     ctrl.region.State(),
+		SelfTest:   ctrl.selfTest.Report(),
+		Workers:    ctrl.workers.Status(),
+		Panics:     common.PanicCount(),
+	}
+	if ctrl.dualRepo != nil {
+		stats := ctrl.dualRepo.Stats()
+		info.DualWrite = &stats
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			if s.Key == "vcs.revision" {
+				info.Revision = s.Value
+			}
+		}
+	}
+	common.WriteJson(w, http.StatusOK, info)
+}
@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/migrate"
+)
+
+// MigrationCtrl controls online migrations.
+type MigrationCtrl struct {
+	runner *migrate.Runner
+}
+
+func NewMigrationCtrl(runner *migrate.Runner) *MigrationCtrl {
+	return &MigrationCtrl{runner: runner}
+}
+
+// ServeHTTP handles GET to list migrations and their checkpoints, and POST with query
+// parameters name and action (start or pause). Start also takes optional batchSize and
+// itemsPerSecond, and returns 202 with the task that runs the migration.
+func (ctrl *MigrationCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		statuses, err := ctrl.runner.Statuses()
+		if err != nil {
+			common.WriteError(w, r, err)
+			return
+		}
+		common.WriteJson(w, http.StatusOK, statuses)
+		return
+	}
+
+	q := r.URL.Query()
+	name := q.Get("name")
+	switch q.Get("action") {
+	case "start":
+		var opts migrate.RunOptions
+		var err error
+		if s := q.Get("batchSize"); s != "" {
+			if opts.BatchSize, err = strconv.Atoi(s); err != nil {
+				common.WriteError(w, r, propsError("batchSize must be a whole number"))
+				return
+			}
+		}
+		if s := q.Get("itemsPerSecond"); s != "" {
+			if opts.ItemsPerSecond, err = strconv.ParseFloat(s, 64); err != nil {
+				common.WriteError(w, r, propsError("itemsPerSecond must be a number"))
+				return
+			}
+		}
+		task, err := ctrl.runner.Start(name, opts)
+		if err != nil {
+			common.WriteError(w, r, err)
+			return
+		}
+		common.WriteJson(w, http.StatusAccepted, task)
+	case "pause":
+		if err := ctrl.runner.Pause(name); err != nil {
+			common.WriteError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		common.WriteError(w, r, propsError("action must be start or pause"))
+	}
+}
+
+// propsError is a props CommonError for a bad query parameter.
+func propsError(msg string) error {
+	return common.NewCommonError(common.ErrcdDomainProps, errors.New(msg))
+}
@@ -0,0 +1,88 @@
+// Package admin has operational endpoints that sit behind common.RequireBearerToken.
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/pprof"
+	"strconv"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// MaxProfileSeconds bounds how long one CPU profile request can run.
+const MaxProfileSeconds = 300
+
+// snapshotProfiles are runtime/pprof profiles captured at a point in time.
+var snapshotProfiles = map[string]bool{
+	"heap":         true,
+	"allocs":       true,
+	"goroutine":    true,
+	"threadcreate": true,
+	"block":        true,
+	"mutex":        true,
+}
+
+// ProfileCtrl captures runtime profiles on demand so production can be profiled without a redeploy.
+type ProfileCtrl struct{}
+
+func NewProfileCtrl() *ProfileCtrl {
+	return &ProfileCtrl{}
+}
+
+// ServeHTTP handles GET with query parameters type (cpu or a runtime/pprof profile name) and
+// seconds (cpu only, default 30). The response is the gzipped protobuf that "go tool pprof" reads.
+func (ctrl *ProfileCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	profileType := q.Get("type")
+	if profileType == "" {
+		profileType = "cpu"
+	}
+
+	if profileType == "cpu" {
+		ctrl.serveCpu(w, r, q.Get("seconds"))
+		return
+	}
+
+	if !snapshotProfiles[profileType] {
+		common.WriteError(w, r, propsError(fmt.Sprintf("unknown profile type %q", profileType)))
+		return
+	}
+	setDownloadHeaders(w, profileType)
+	pprof.Lookup(profileType).WriteTo(w, 0)
+}
+
+func setDownloadHeaders(w http.ResponseWriter, profileType string) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.pprof"`, profileType, time.Now().UTC().Format("20060102T150405")))
+}
+
+func (ctrl *ProfileCtrl) serveCpu(w http.ResponseWriter, r *http.Request, secondsParam string) {
+	seconds := 30
+	if secondsParam != "" {
+		var err error
+		seconds, err = strconv.Atoi(secondsParam)
+		if err != nil || seconds < 1 || seconds > MaxProfileSeconds {
+			common.WriteError(w, r, propsError(fmt.Sprintf("seconds must be 1 to %d", MaxProfileSeconds)))
+			return
+		}
+	}
+
+	// the profile is written to w as it's collected, so a failed start is the last chance to send an error status
+	setDownloadHeaders(w, "cpu")
+	if err := pprof.StartCPUProfile(w); err != nil {
+		w.Header().Del("Content-Disposition")
+		w.Header().Del("Content-Type")
+		common.WriteError(w, r, common.NewCommonError(common.ErrcdConflict, fmt.Errorf("could not start CPU profile (is one already running?): %w", err)))
+		return
+	}
+
+	timer := time.NewTimer(time.Duration(seconds) * time.Second)
+	select {
+	case <-timer.C:
+	case <-r.Context().Done():
+		timer.Stop()
+	}
+	pprof.StopCPUProfile()
+}
@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/region"
+)
+
+// RegionCtrl shows and changes the region's role. Changes last until the server restarts; set
+// GOJST_REGION_ROLE to keep them.
+type RegionCtrl struct {
+	region *region.Region
+}
+
+func NewRegionCtrl(rg *region.Region) *RegionCtrl {
+	return &RegionCtrl{region: rg}
+}
+
+// ServeHTTP handles GET to show the region's state and PUT with query parameters role (active
+// or passive) and optional force to change it. Promoting fails with 409 if the database isn't
+// accepting writes yet, unless force=true.
+func (ctrl *RegionCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		common.WriteJson(w, http.StatusOK, ctrl.region.State())
+		return
+	}
+
+	q := r.URL.Query()
+	role, err := region.ParseRole(q.Get("role"))
+	if err != nil {
+		common.WriteError(w, r, common.NewCommonError(common.ErrcdDomainProps, err))
+		return
+	}
+	force := false
+	if s := q.Get("force"); s != "" {
+		if force, err = strconv.ParseBool(s); err != nil {
+			common.WriteError(w, r, propsError("force must be true or false"))
+			return
+		}
+	}
+
+	state, err := ctrl.region.SetRole(r.Context(), role, force)
+	switch {
+	case errors.Is(err, region.ErrDatabaseNotPrimary):
+		common.WriteError(w, r, common.NewCommonError(common.ErrcdConflict, err))
+		return
+	case err != nil:
+		common.WriteError(w, r, common.NewCommonError(common.ErrcdRepoConnection, fmt.Errorf("database check failed: %w", err)))
+		return
+	}
+	common.WriteJson(w, http.StatusOK, state)
+}
@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/tasks"
+)
+
+// TaskCtrl reports on background tasks, like rollups started with async=true.
+type TaskCtrl struct {
+	tasks *tasks.Manager
+}
+
+func NewTaskCtrl(tm *tasks.Manager) *TaskCtrl {
+	return &TaskCtrl{tasks: tm}
+}
+
+// ServeHTTP handles GET with query parameter id to get one task, or no id to list every task
+// the server remembers, newest first.
+func (ctrl *TaskCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	taskId := r.URL.Query().Get("id")
+	if taskId == "" {
+		list := ctrl.tasks.List()
+		sort.Slice(list, func(i, j int) bool { return list[i].CreatedTs > list[j].CreatedTs })
+		common.WriteJson(w, http.StatusOK, list)
+		return
+	}
+
+	task, ok := ctrl.tasks.Get(taskId)
+	if !ok {
+		common.WriteError(w, r, common.NewCommonError(common.ErrcdNotFound, errors.New("unknown task id (finished tasks are forgotten after a while and on restart)")))
+		return
+	}
+	common.WriteJson(w, http.StatusOK, task)
+}
@@ -0,0 +1,28 @@
+package common
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// RequireBearerToken only passes requests with "Authorization: Bearer <token>" to next.
+// If token is empty, every request is refused so admin routes are off unless configured.
+func RequireBearerToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			WriteError(w, r, NewCommonError(ErrcdForbidden, errors.New("admin API is disabled")))
+			return
+		}
+
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			WriteError(w, r, NewCommonError(ErrcdUnauthorized, errors.New("missing or wrong bearer token")))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
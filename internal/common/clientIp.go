@@ -0,0 +1,95 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies are the load balancers and proxies allowed to report the client address in
+// X-Forwarded-For or a PROXY protocol header. Connections from anywhere else are taken at
+// their connection address.
+type TrustedProxies struct {
+	prefixes []netip.Prefix
+}
+
+// ParseTrustedProxies parses the same format as ParseIpAllowlist. Empty means trust no one.
+func ParseTrustedProxies(s string) (TrustedProxies, error) {
+	prefixes, err := parsePrefixes(s)
+	if err != nil {
+		return TrustedProxies{}, err
+	}
+	return TrustedProxies{prefixes: prefixes}, nil
+}
+
+// Trusts reports whether ip is a trusted proxy.
+func (tp TrustedProxies) Trusts(ip netip.Addr) bool {
+	return containsAddr(tp.prefixes, ip)
+}
+
+// ClientIp returns the address of the client that sent r. If the connection is from a trusted
+// proxy, it walks X-Forwarded-For from the right, skipping trusted proxies, and returns the first
+// untrusted address; anything left of that could have been written by the client.
+func (tp TrustedProxies) ClientIp(r *http.Request) (netip.Addr, error) {
+	ip, err := remoteAddr(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	if !tp.Trusts(ip) {
+		return ip, nil
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// a garbled entry means we can't trust anything further left
+			return ip, nil
+		}
+		hop = hop.Unmap()
+		if !tp.Trusts(hop) {
+			return hop, nil
+		}
+		ip = hop
+	}
+	return ip, nil
+}
+
+type clientIpKey struct{}
+
+// ResolveClientIp works out the client address once per request and puts it in the request
+// context, where ClientIpOf finds it for allowlists, logs, and rate limits. Requests without
+// a usable address get 400.
+func (tp TrustedProxies) ResolveClientIp(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, err := tp.ClientIp(r)
+		if err != nil {
+			WriteError(w, r, NewCommonError(ErrcdDomainProps, fmt.Errorf("no client address: %w", err)))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIpKey{}, ip)))
+	})
+}
+
+// ClientIpOf returns the address ResolveClientIp found, or the connection address if it didn't run.
+func ClientIpOf(r *http.Request) (netip.Addr, error) {
+	if ip, ok := r.Context().Value(clientIpKey{}).(netip.Addr); ok {
+		return ip, nil
+	}
+	return remoteAddr(r.RemoteAddr)
+}
+
+func remoteAddr(addr string) (netip.Addr, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("remote address %q: %w", addr, err)
+	}
+	return ip.Unmap(), nil
+}
@@ -0,0 +1,116 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIp(t *testing.T) {
+	tp, err := ParseTrustedProxies("10.0.0.0/8, 2001:db8:1::/48, 192.168.1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		remote string
+		xff    []string
+		want   string
+	}{
+		{"direct", "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"no port", "203.0.113.7", nil, "203.0.113.7"},
+		{"untrusted peer can't spoof", "203.0.113.7:5000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"trusted peer without a header", "10.1.2.3:5000", nil, "10.1.2.3"},
+		{"one hop", "10.1.2.3:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+
+		// the client can write anything left of what our proxies appended
+		{"spoofed entry left of the client", "10.1.2.3:5000", []string{"127.0.0.1, 198.51.100.1"}, "198.51.100.1"},
+		{"spoofed trusted address", "10.1.2.3:5000", []string{"10.9.9.9, 198.51.100.1"}, "198.51.100.1"},
+
+		// multi-hop: skip every trusted proxy from the right
+		{"proxy chain", "10.1.2.3:5000", []string{"198.51.100.1, 10.0.0.9, 192.168.1.5"}, "198.51.100.1"},
+		{"chain over repeated headers", "10.1.2.3:5000", []string{"198.51.100.1", "10.0.0.9"}, "198.51.100.1"},
+		{"every hop trusted", "10.1.2.3:5000", []string{"10.0.0.9, 10.0.0.8"}, "10.0.0.9"},
+		{"garbled hop stops the walk", "10.1.2.3:5000", []string{"198.51.100.1, bogus, 10.0.0.9"}, "10.0.0.9"},
+		{"empty hop stops the walk", "10.1.2.3:5000", []string{"198.51.100.1,,10.0.0.9"}, "10.0.0.9"},
+		{"hop with a port isn't an address", "10.1.2.3:5000", []string{"198.51.100.1:4000"}, "10.1.2.3"},
+
+		// IPv6
+		{"ipv6 direct", "[2001:db8:2::1]:5000", nil, "2001:db8:2::1"},
+		{"ipv6 trusted peer", "[2001:db8:1::10]:5000", []string{"2001:db8:2::1"}, "2001:db8:2::1"},
+		{"ipv6 peer, ipv4 client", "[2001:db8:1::10]:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"ipv6 just outside the trusted /48", "[2001:db8:2::10]:5000", []string{"198.51.100.1"}, "2001:db8:2::10"},
+
+		// IPv4-mapped IPv6 is the IPv4 address, both as the peer and in the header
+		{"mapped peer", "[::ffff:203.0.113.7]:5000", nil, "203.0.113.7"},
+		{"mapped trusted peer", "[::ffff:10.1.2.3]:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"mapped hop", "10.1.2.3:5000", []string{"::ffff:198.51.100.1"}, "198.51.100.1"},
+		{"mapped trusted hop", "10.1.2.3:5000", []string{"198.51.100.1, ::ffff:10.0.0.9"}, "198.51.100.1"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = tc.remote
+		for _, v := range tc.xff {
+			r.Header.Add("X-Forwarded-For", v)
+		}
+		got, err := tp.ClientIp(r)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if want := netip.MustParseAddr(tc.want); got != want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, want)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "not-an-address:80"
+	if _, err := tp.ClientIp(r); err == nil {
+		t.Error("bad remote address: got no error")
+	}
+}
+
+func TestTrustNoOne(t *testing.T) {
+	tp, err := ParseTrustedProxies("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.1.2.3:5000"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	if got, _ := tp.ClientIp(r); got != netip.MustParseAddr("10.1.2.3") {
+		t.Errorf("got %s, want the connection address", got)
+	}
+}
+
+func TestResolveClientIp(t *testing.T) {
+	tp, _ := ParseTrustedProxies("10.0.0.0/8")
+	var got netip.Addr
+	h := tp.ResolveClientIp(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = ClientIpOf(r)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.1.2.3:5000"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if want := netip.MustParseAddr("198.51.100.1"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	rec := httptest.NewRecorder()
+	r.RemoteAddr = "@"
+	h.ServeHTTP(rec, r)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("bad remote address: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	// without ResolveClientIp, ClientIpOf falls back to the connection address
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "[::ffff:10.1.2.3]:5000"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	if ip, _ := ClientIpOf(r); ip != netip.MustParseAddr("10.1.2.3") {
+		t.Errorf("fallback: got %s, want 10.1.2.3", ip)
+	}
+}
@@ -0,0 +1,106 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error codes used in CommonError.Code. Callers should compare codes, not messages.
+const (
+	ErrcdDomainProps    = "PropsError"
+	ErrcdRepoDupeRow    = "DuplicateRowError"
+	ErrcdRepoConnection = "ConnectionExceptionError"
+	ErrcdRepoOther      = "OtherRepoError"
+	// the database rolled the work back (a serialization failure or deadlock); it can be retried
+	ErrcdRepoTransient = "TransientRepoError"
+	// a row couldn't be scanned or converted to a domain object
+	ErrcdRepoRowConversion = "RowConversionError"
+	// some rows couldn't be converted; the others were returned with the error
+	ErrcdRepoPartialResult = "PartialResultError"
+	ErrcdJsonDecode        = "JsonDecodeError"
+	// the request body is larger than the endpoint accepts
+	ErrcdBodyTooLarge = "BodyTooLargeError"
+	// the server can't take more work right now; the client should retry later
+	ErrcdBusy = "BusyError"
+	// the caller has used up a quota
+	ErrcdQuotaExceeded = "QuotaExceededError"
+	// the named thing doesn't exist
+	ErrcdNotFound = "NotFoundError"
+	// the request conflicts with the current state, like a role change the database isn't ready for
+	ErrcdConflict = "ConflictError"
+	// the caller isn't signed in
+	ErrcdUnauthorized = "UnauthorizedError"
+	// the caller is signed in but isn't allowed to do this
+	ErrcdForbidden = "ForbiddenError"
+	// code panicked; RecoverPanics and CatchPanic turn the panic into this
+	ErrcdAppPanic = "AppPanicError"
+	// an applied schema migration's file changed after it was applied
+	ErrcdSchemaDrift = "SchemaDriftError"
+)
+
+// CommonError carries an error code that upper layers can act on without knowing
+// which layer or library produced the error.
+type CommonError struct {
+	Code string
+	Err  error
+}
+
+func NewCommonError(code string, err error) *CommonError {
+	return &CommonError{Code: code, Err: err}
+}
+
+func (ce *CommonError) Error() string {
+	return fmt.Sprintf("%s: %v", ce.Code, ce.Err)
+}
+
+func (ce *CommonError) Unwrap() error {
+	return ce.Err
+}
+
+// IsRetryable is true if err's code means the same call may work if it's tried again: the
+// database was unreachable, or it rolled the work back.
+func IsRetryable(err error) bool {
+	switch ErrorCode(err) {
+	case ErrcdRepoConnection, ErrcdRepoTransient:
+		return true
+	}
+	return false
+}
+
+// ErrorCode returns the code of the first CommonError in err's chain or "" if there isn't one.
+func ErrorCode(err error) string {
+	var ce *CommonError
+	if errors.As(err, &ce) {
+		return ce.Code
+	}
+	return ""
+}
+
+// FieldError is a problem with one field of a request body, named by its JSON name. Message
+// reads after the name, like "is required".
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// FieldErrors are problems with a request body's fields. Wrap them in a CommonError with code
+// ErrcdDomainProps, so clients that take JSON errors get each field's problem.
+type FieldErrors []FieldError
+
+func (fe FieldErrors) Error() string {
+	msgs := make([]string, len(fe))
+	for i, e := range fe {
+		msgs[i] = e.Field + " " + e.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// FieldErrorsOf returns the FieldErrors in err's chain or nil if there aren't any.
+func FieldErrorsOf(err error) FieldErrors {
+	var fe FieldErrors
+	if errors.As(err, &fe) {
+		return fe
+	}
+	return nil
+}
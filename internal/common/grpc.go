@@ -0,0 +1,123 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// ErrorToGrpcCode maps CommonError codes to gRPC codes, as ErrorToHttpStatus maps them to HTTP
+// statuses. Errors without a code are Internal.
+func ErrorToGrpcCode(err error) codes.Code {
+	switch ErrorCode(err) {
+	case ErrcdDomainProps, ErrcdJsonDecode:
+		return codes.InvalidArgument
+	case ErrcdUnauthorized:
+		return codes.Unauthenticated
+	case ErrcdForbidden:
+		return codes.PermissionDenied
+	case ErrcdNotFound:
+		return codes.NotFound
+	case ErrcdRepoDupeRow, ErrcdConflict:
+		return codes.AlreadyExists
+	case ErrcdBodyTooLarge, ErrcdQuotaExceeded:
+		return codes.ResourceExhausted
+	case ErrcdRepoConnection, ErrcdRepoTransient, ErrcdBusy:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+// GrpcErrorDomain is the domain of the errdetails.ErrorInfo that GrpcError attaches.
+const GrpcErrorDomain = "go-jst"
+
+// GrpcError logs err with the call's method and request id, as ReportError does, and returns it
+// as a gRPC status. Like WriteError, an Internal error says only that it failed and an Unavailable
+// error has a generic message. Others carry an errdetails.ErrorInfo whose Reason is the
+// CommonError code, and field errors as an errdetails.BadRequest.
+func GrpcError(ctx context.Context, method string, err error) error {
+	code := ErrorToGrpcCode(err)
+	log.Printf("%s failed code %s request %s: %v", method, code, requestIdFrom(ctx), err)
+	switch code {
+	case codes.Internal:
+		return status.Error(code, code.String())
+	case codes.Unavailable:
+		return status.Error(code, unavailableMessage)
+	}
+
+	st := status.New(code, err.Error())
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: ErrorCode(err), Domain: GrpcErrorDomain}}
+	if fes := FieldErrorsOf(err); len(fes) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, fe := range fes {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: fe.Field, Description: fe.Message})
+		}
+		details = append(details, br)
+	}
+	if withDetails, detailErr := st.WithDetails(details...); detailErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// GrpcRecoverPanics is RecoverPanics for unary gRPC calls: a panic is logged and counted, and the
+// call fails with Internal.
+func GrpcRecoverPanics(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			recovered(info.FullMethod, rec)
+			resp, err = nil, status.Error(codes.Internal, codes.Internal.String())
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// GrpcAssignRequestId is AssignRequestId for unary gRPC calls. The id comes from the
+// x-request-id metadata if the client sent a usable one, and goes back in the response header.
+func GrpcAssignRequestId(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIdHeader); len(ids) > 0 {
+			id = strings.TrimSpace(ids[0])
+		}
+	}
+	if !validRequestId(id) {
+		id = newRequestId()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIdHeader, id))
+	return handler(context.WithValue(ctx, requestIdKey{}, id), req)
+}
+
+// GrpcRequireAllowedIp is RequireAllowedIp for unary gRPC calls. The client is the connection's
+// address, so gRPC behind a proxy needs PROXY protocol (see NewProxyProtocolListener);
+// X-Forwarded-For isn't read.
+func GrpcRequireAllowedIp(al IpAllowlist) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ip, err := grpcPeerIp(ctx)
+		if err != nil || !al.Allows(ip) {
+			if err == nil {
+				err = fmt.Errorf("client %s isn't allowed", ip)
+			}
+			return nil, GrpcError(ctx, info.FullMethod, NewCommonError(ErrcdForbidden, err))
+		}
+		return handler(ctx, req)
+	}
+}
+
+func grpcPeerIp(ctx context.Context) (netip.Addr, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return netip.Addr{}, fmt.Errorf("no peer address")
+	}
+	return remoteAddr(p.Addr.String())
+}
@@ -0,0 +1,132 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorToGrpcCode(t *testing.T) {
+	for err, want := range map[error]codes.Code{
+		NewCommonError(ErrcdDomainProps, errors.New("bad")):                          codes.InvalidArgument,
+		NewCommonError(ErrcdRepoDupeRow, errors.New("dupe")):                         codes.AlreadyExists,
+		NewCommonError(ErrcdQuotaExceeded, errors.New("over")):                       codes.ResourceExhausted,
+		NewCommonError(ErrcdRepoConnection, errors.New("down")):                      codes.Unavailable,
+		fmt.Errorf("wrapped: %w", NewCommonError(ErrcdNotFound, errors.New("gone"))): codes.NotFound,
+		errors.New("no code"): codes.Internal,
+	} {
+		if got := ErrorToGrpcCode(err); got != want {
+			t.Errorf("%v: got %s, want %s", err, got, want)
+		}
+	}
+}
+
+func TestGrpcErrorHidesCauses(t *testing.T) {
+	for _, tt := range []struct {
+		err     error
+		wantMsg string
+	}{
+		{errors.New("nil map in handler"), codes.Internal.String()},
+		{NewCommonError(ErrcdRepoConnection, errors.New("dial tcp 10.0.0.5:5432: refused")), unavailableMessage},
+	} {
+		st := status.Convert(GrpcError(context.Background(), "/test/Method", tt.err))
+		if st.Message() != tt.wantMsg || len(st.Details()) != 0 {
+			t.Errorf("%v: got %q with %d details, want %q and none", tt.err, st.Message(), len(st.Details()), tt.wantMsg)
+		}
+	}
+}
+
+// callUnary runs interceptor in front of a handler that returns its context, from a peer at addr
+// with incoming metadata md.
+func callUnary(interceptor grpc.UnaryServerInterceptor, addr string, md metadata.MD) (context.Context, error) {
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	if addr != "" {
+		tcpAddr, _ := net.ResolveTCPAddr("tcp", addr)
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: tcpAddr})
+	}
+	ctx = grpc.NewContextWithServerTransportStream(ctx, &headerStream{})
+	got, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test/Method"}, func(ctx context.Context, req any) (any, error) {
+		return ctx, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return got.(context.Context), nil
+}
+
+// headerStream keeps the headers grpc.SetHeader sets.
+type headerStream struct {
+	header metadata.MD
+}
+
+func (hs *headerStream) Method() string { return "/test/Method" }
+func (hs *headerStream) SetHeader(md metadata.MD) error {
+	hs.header = metadata.Join(hs.header, md)
+	return nil
+}
+func (hs *headerStream) SendHeader(md metadata.MD) error { return hs.SetHeader(md) }
+func (hs *headerStream) SetTrailer(md metadata.MD) error { return nil }
+
+func TestGrpcAssignRequestId(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		sent   string
+		wantId string
+	}{
+		{"client's id", "req-123", "req-123"},
+		{"none sent", "", ""},
+		{"id with a newline", "req\n123", ""},
+	} {
+		md := metadata.MD{}
+		if tt.sent != "" {
+			md.Set(RequestIdHeader, tt.sent)
+		}
+		ctx, err := callUnary(GrpcAssignRequestId, "", md)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		id := requestIdFrom(ctx)
+		if id == "" || (tt.wantId != "" && id != tt.wantId) || (tt.wantId == "" && id == tt.sent) {
+			t.Errorf("%s: got id %q, want %q or a new one", tt.name, id, tt.wantId)
+		}
+	}
+}
+
+func TestGrpcRequireAllowedIp(t *testing.T) {
+	al, err := ParseIpAllowlist("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		addr string
+		want codes.Code
+	}{
+		{"10.1.2.3:50000", codes.OK},
+		{"[::ffff:10.1.2.3]:50000", codes.OK},
+		{"192.168.1.5:50000", codes.PermissionDenied},
+		{"", codes.PermissionDenied},
+	} {
+		_, err := callUnary(GrpcRequireAllowedIp(al), tt.addr, nil)
+		if got := status.Code(err); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestGrpcRecoverPanics(t *testing.T) {
+	before := PanicCount()
+	_, err := GrpcRecoverPanics(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test/Method"}, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal || PanicCount() != before+1 {
+		t.Errorf("got %v and %d panics, want Internal and one more panic", err, PanicCount()-before)
+	}
+}
@@ -0,0 +1,85 @@
+package common
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// ErrorToHttpStatus maps CommonError codes to HTTP statuses. Errors without a code are 500s.
+func ErrorToHttpStatus(err error) int {
+	switch ErrorCode(err) {
+	case ErrcdDomainProps, ErrcdJsonDecode:
+		return http.StatusBadRequest
+	case ErrcdUnauthorized:
+		return http.StatusUnauthorized
+	case ErrcdForbidden:
+		return http.StatusForbidden
+	case ErrcdNotFound:
+		return http.StatusNotFound
+	case ErrcdRepoDupeRow, ErrcdConflict:
+		return http.StatusConflict
+	case ErrcdBodyTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case ErrcdQuotaExceeded:
+		return http.StatusTooManyRequests
+	case ErrcdRepoConnection, ErrcdRepoTransient, ErrcdBusy:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// unavailableMessage is what a 503 says. The cause, like a database error, is only logged.
+const unavailableMessage = "service unavailable; try again later"
+
+// ReportError logs err with r's method, path, and request id, and returns the status err maps to
+// and the code and message a client may see. A 500 has no code and a 503 has a generic message,
+// so neither says what failed; the log line does.
+func ReportError(r *http.Request, err error) (status int, code string, msg string) {
+	status = ErrorToHttpStatus(err)
+	log.Printf("%s %s failed status %d request %s: %v", r.Method, r.URL.Path, status, RequestIdOf(r), err)
+	code, msg = ErrorCode(err), err.Error()
+	switch status {
+	case http.StatusInternalServerError:
+		code, msg = "", http.StatusText(status)
+	case http.StatusServiceUnavailable:
+		msg = unavailableMessage
+	}
+	return status, code, msg
+}
+
+// WriteError reports err (see ReportError) and writes it: a dto.ErrorDto for clients whose Accept
+// names JSON, and plain text for others. Field errors are included in the ErrorDto unless the
+// status is a 500 or 503.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	status, code, msg := ReportError(r, err)
+	if !WantsJsonError(r) {
+		http.Error(w, msg, status)
+		return
+	}
+
+	errDto := dto.ErrorDto{Code: code, Message: msg, RequestId: RequestIdOf(r)}
+	if status != http.StatusInternalServerError && status != http.StatusServiceUnavailable {
+		for _, fe := range FieldErrorsOf(err) {
+			errDto.FieldErrors = append(errDto.FieldErrors, dto.FieldErrorDto{Field: fe.Field, Message: fe.Message})
+		}
+	}
+	WriteJson(w, status, errDto)
+}
+
+// WantsJsonError is true if r's Accept names application/json or the envelope, so the client
+// can read a dto.ErrorDto.
+func WantsJsonError(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			mediaType, _, _ = strings.Cut(mediaType, ";")
+			switch strings.TrimSpace(mediaType) {
+			case ContentTypeJson, dto.EnvelopeMediaType:
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+func TestErrorToHttpStatus(t *testing.T) {
+	for err, want := range map[error]int{
+		NewCommonError(ErrcdDomainProps, errors.New("bad")):                          http.StatusBadRequest,
+		NewCommonError(ErrcdRepoDupeRow, errors.New("dupe")):                         http.StatusConflict,
+		NewCommonError(ErrcdBodyTooLarge, errors.New("big")):                         http.StatusRequestEntityTooLarge,
+		NewCommonError(ErrcdRepoConnection, errors.New("down")):                      http.StatusServiceUnavailable,
+		fmt.Errorf("wrapped: %w", NewCommonError(ErrcdNotFound, errors.New("gone"))): http.StatusNotFound,
+		errors.New("no code"): http.StatusInternalServerError,
+	} {
+		if got := ErrorToHttpStatus(err); got != want {
+			t.Errorf("%v: got %d, want %d", err, got, want)
+		}
+	}
+}
+
+// writeError calls WriteError on a request with Accept accept and an assigned request id.
+func writeError(accept string, err error) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/job-statuses", nil)
+	r.Header.Set("Accept", accept)
+	AssignRequestId(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, r, err)
+	})).ServeHTTP(rec, r)
+	return rec
+}
+
+func decodeErrorDto(t *testing.T, rec *httptest.ResponseRecorder) dto.ErrorDto {
+	t.Helper()
+	var errDto dto.ErrorDto
+	if err := json.Unmarshal(rec.Body.Bytes(), &errDto); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+	return errDto
+}
+
+func TestWriteErrorGivesJsonClientsFieldErrors(t *testing.T) {
+	err := NewCommonError(ErrcdDomainProps, FieldErrors{{Field: "BusDt", Message: "is required"}})
+	rec := writeError("text/html, application/json;q=0.9", err)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want 400", rec.Code)
+	}
+	errDto := decodeErrorDto(t, rec)
+	if errDto.Code != ErrcdDomainProps || errDto.RequestId != rec.Header().Get(RequestIdHeader) || len(errDto.FieldErrors) != 1 || errDto.FieldErrors[0].Field != "BusDt" {
+		t.Errorf("got %+v, want the code, request id, and BusDt field error", errDto)
+	}
+}
+
+func TestWriteErrorKeepsCausesOutOf500sAnd503s(t *testing.T) {
+	for status, err := range map[int]error{
+		http.StatusInternalServerError: errors.New("pq: password authentication failed"),
+		http.StatusServiceUnavailable:  NewCommonError(ErrcdRepoConnection, errors.New("dial tcp 10.0.0.5:5432: refused")),
+	} {
+		rec := writeError(ContentTypeJson, err)
+		if rec.Code != status {
+			t.Errorf("%v: got status %d, want %d", err, rec.Code, status)
+		}
+		if errDto := decodeErrorDto(t, rec); strings.Contains(errDto.Message, "pq:") || strings.Contains(errDto.Message, "10.0.0.5") || errDto.FieldErrors != nil {
+			t.Errorf("%d: got %+v, want no cause", status, errDto)
+		}
+	}
+}
+
+func TestWriteErrorWritesTextForOtherClients(t *testing.T) {
+	rec := writeError("text/plain", NewCommonError(ErrcdNotFound, errors.New("no such view")))
+	if rec.Code != http.StatusNotFound || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("got %d %q, want a text 404", rec.Code, rec.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// MethodHandler routes a request to the handler for its HTTP method or responds 405.
+type MethodHandler map[string]http.Handler
+
+func (mh MethodHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h, ok := mh[r.Method]; ok {
+		h.ServeHTTP(w, r)
+		return
+	}
+
+	allowed := make([]string, 0, len(mh))
+	for method := range mh {
+		allowed = append(allowed, method)
+	}
+	sort.Strings(allowed)
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+}
+
+// WriteJson writes body as a JSON response with the given status.
+func WriteJson(w http.ResponseWriter, status int, body any) {
+	WriteJsonAs(w, "application/json", status, body)
+}
+
+// WriteJsonAs is WriteJson with a JSON media type other than application/json.
+func WriteJsonAs(w http.ResponseWriter, contentType string, status int, body any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("WriteJson encode failed: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// IpAllowlist is a list of CIDR ranges. An empty list allows every address.
+type IpAllowlist struct {
+	prefixes []netip.Prefix
+}
+
+// ParseIpAllowlist parses comma separated CIDRs or single addresses, like "10.0.0.0/8,192.168.1.5".
+func ParseIpAllowlist(s string) (IpAllowlist, error) {
+	prefixes, err := parsePrefixes(s)
+	if err != nil {
+		return IpAllowlist{}, err
+	}
+	return IpAllowlist{prefixes: prefixes}, nil
+}
+
+// Allows reports whether ip is in the list.
+func (al IpAllowlist) Allows(ip netip.Addr) bool {
+	if len(al.prefixes) == 0 {
+		return true
+	}
+	return containsAddr(al.prefixes, ip)
+}
+
+// RequireAllowedIp only passes requests from clients in al to next; others get 403.
+// The client is ClientIpOf(r), so put TrustedProxies.ResolveClientIp in front of it behind a proxy.
+func RequireAllowedIp(al IpAllowlist, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, err := ClientIpOf(r)
+		if err != nil || !al.Allows(ip) {
+			if err == nil {
+				err = fmt.Errorf("client %s isn't allowed", ip)
+			}
+			WriteError(w, r, NewCommonError(ErrcdForbidden, err))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func parsePrefixes(s string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip, err := netip.ParseAddr(item)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not an address or CIDR", item)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an address or CIDR", item)
+		}
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			// ::ffff:10.0.0.0/104 is 10.0.0.0/8, and addresses are unmapped before matching
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func containsAddr(prefixes []netip.Prefix, ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, p := range prefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestParseIpAllowlist(t *testing.T) {
+	for _, s := range []string{"10.0.0.0/8", " 10.0.0.0/8 , ,192.168.1.5,", "2001:db8::/32", "::ffff:10.0.0.1", ""} {
+		if _, err := ParseIpAllowlist(s); err != nil {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+	for _, s := range []string{"10.0.0.0/33", "10.0.0/8", "host.internal", "10.0.0.1-10.0.0.9", "2001:db8::/129", "10.0.0.0/"} {
+		if _, err := ParseIpAllowlist(s); err == nil {
+			t.Errorf("%q: got no error", s)
+		}
+	}
+}
+
+func TestIpAllowlistAllows(t *testing.T) {
+	for _, tc := range []struct {
+		list string
+		ip   string
+		want bool
+	}{
+		{"", "203.0.113.7", true},
+		{"", "2001:db8::1", true},
+
+		// CIDR edges
+		{"10.0.0.0/8", "10.0.0.0", true},
+		{"10.0.0.0/8", "10.255.255.255", true},
+		{"10.0.0.0/8", "9.255.255.255", false},
+		{"10.0.0.0/8", "11.0.0.0", false},
+		{"192.168.1.0/25", "192.168.1.127", true},
+		{"192.168.1.0/25", "192.168.1.128", false},
+		{"10.1.2.3/8", "10.200.0.1", true}, // host bits are masked off
+		{"0.0.0.0/0", "203.0.113.7", true},
+		{"0.0.0.0/0", "2001:db8::1", false},
+		{"192.168.1.5", "192.168.1.5", true},
+		{"192.168.1.5", "192.168.1.6", false},
+		{"192.168.1.5/32", "192.168.1.5", true},
+
+		// IPv6
+		{"2001:db8::/32", "2001:db8:ffff::1", true},
+		{"2001:db8::/32", "2001:db9::1", false},
+		{"2001:db8::1", "2001:db8::1", true},
+		{"2001:db8::1", "2001:db8::2", false},
+		{"::/0", "203.0.113.7", false},
+
+		// IPv4-mapped addresses are their IPv4 address, on either side
+		{"10.0.0.0/8", "::ffff:10.1.2.3", true},
+		{"10.0.0.0/8", "::ffff:11.1.2.3", false},
+		{"::ffff:192.168.1.5", "192.168.1.5", true},
+		{"::ffff:10.0.0.0/104", "10.1.2.3", true},
+		{"::ffff:10.0.0.0/104", "::ffff:11.1.2.3", false},
+
+		{"10.0.0.0/8, 2001:db8::/32", "2001:db8::1", true},
+		{"10.0.0.0/8, 2001:db8::/32", "172.16.0.1", false},
+	} {
+		al, err := ParseIpAllowlist(tc.list)
+		if err != nil {
+			t.Errorf("%q: %v", tc.list, err)
+			continue
+		}
+		if got := al.Allows(netip.MustParseAddr(tc.ip)); got != tc.want {
+			t.Errorf("%q allows %s: got %t, want %t", tc.list, tc.ip, got, tc.want)
+		}
+	}
+}
+
+func TestRequireAllowedIp(t *testing.T) {
+	al, _ := ParseIpAllowlist("10.0.0.0/8")
+	tp, _ := ParseTrustedProxies("192.168.0.0/16")
+	h := tp.ResolveClientIp(RequireAllowedIp(al, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	for _, tc := range []struct {
+		name   string
+		remote string
+		xff    string
+		want   int
+	}{
+		{"inside", "10.1.2.3:5000", "", http.StatusOK},
+		{"outside", "203.0.113.7:5000", "", http.StatusForbidden},
+		{"outside, spoofing an inside address", "203.0.113.7:5000", "10.1.2.3", http.StatusForbidden},
+		{"inside, through a trusted proxy", "192.168.1.1:5000", "10.1.2.3", http.StatusOK},
+		{"outside, through a trusted proxy", "192.168.1.1:5000", "203.0.113.7", http.StatusForbidden},
+		{"outside, spoofing behind a trusted proxy", "192.168.1.1:5000", "10.1.2.3, 203.0.113.7", http.StatusForbidden},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/admin/", nil)
+		r.RemoteAddr = tc.remote
+		if tc.xff != "" {
+			r.Header.Set("X-Forwarded-For", tc.xff)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if rec.Code != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, rec.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package common
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// JsonStream writes a response one value at a time, either as a JSON array or as
+// newline delimited JSON (NDJSON), flushing every flushEvery values so clients see
+// rows while the server is still reading them.
+//
+// Nothing is written until the first Write, so callers can still send a normal
+// error response if they fail before producing any values.
+type JsonStream struct {
+	w          http.ResponseWriter
+	enc        *json.Encoder
+	ndjson     bool
+	flushEvery int
+	count      int
+	started    bool
+}
+
+const (
+	ContentTypeJson   = "application/json"
+	ContentTypeNdjson = "application/x-ndjson"
+)
+
+func NewJsonStream(w http.ResponseWriter, ndjson bool, flushEvery int) *JsonStream {
+	if flushEvery < 1 {
+		flushEvery = 1
+	}
+	return &JsonStream{w: w, enc: json.NewEncoder(w), ndjson: ndjson, flushEvery: flushEvery}
+}
+
+// Started reports whether the response header has been sent.
+func (s *JsonStream) Started() bool {
+	return s.started
+}
+
+func (s *JsonStream) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	if s.ndjson {
+		s.w.Header().Set("Content-Type", ContentTypeNdjson)
+	} else {
+		s.w.Header().Set("Content-Type", ContentTypeJson)
+	}
+	s.w.WriteHeader(http.StatusOK)
+	if !s.ndjson {
+		s.w.Write([]byte("["))
+	}
+}
+
+// Write writes one value.
+func (s *JsonStream) Write(v any) error {
+	s.start()
+	if !s.ndjson && s.count > 0 {
+		if _, err := s.w.Write([]byte(",")); err != nil {
+			return err
+		}
+	}
+	// Encode adds a newline, which is the NDJSON delimiter and harmless in an array
+	if err := s.enc.Encode(v); err != nil {
+		return err
+	}
+
+	s.count++
+	if s.count%s.flushEvery == 0 {
+		s.flush()
+	}
+	return nil
+}
+
+// Close ends the response. An empty stream is written as an empty array or empty body.
+func (s *JsonStream) Close() error {
+	s.start()
+	if !s.ndjson {
+		if _, err := s.w.Write([]byte("]\n")); err != nil {
+			return err
+		}
+	}
+	s.flush()
+	return nil
+}
+
+// Abort ends a started response after an error. JSON arrays are left unterminated so
+// clients can't mistake a partial result for a complete one. NDJSON gets a final
+// {"Error": "..."} line for the same reason.
+func (s *JsonStream) Abort(err error) {
+	if s.ndjson {
+		s.enc.Encode(struct{ Error string }{Error: err.Error()})
+	}
+	s.flush()
+}
+
+func (s *JsonStream) flush() {
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,106 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+var panicCount atomic.Int64
+
+// PanicCount is how many panics RecoverPanics and CatchPanic have recovered since the server started.
+func PanicCount() int64 {
+	return panicCount.Load()
+}
+
+// recovered logs a recovered panic with its stack, counts it, and returns it as an ErrcdAppPanic
+// error. where says what was running, like a request's method and path.
+func recovered(where string, r any) *CommonError {
+	panicCount.Add(1)
+	log.Printf("%s panicked: %v\n%s", where, r, debug.Stack())
+	return NewCommonError(ErrcdAppPanic, fmt.Errorf("%s panicked: %v", where, r))
+}
+
+// CatchPanic calls fn and returns its error, or an ErrcdAppPanic error if it panics, so one bad
+// run of a background loop doesn't take down the server.
+func CatchPanic(where string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(where, r)
+		}
+	}()
+	return fn()
+}
+
+// Problem is an RFC 7807 problem details body.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Code   string `json:"code,omitempty"`
+}
+
+// RecoverPanics passes requests to next, and turns a panic into a 500 with an
+// application/problem+json body, so one bad request can't kill the server. The panic's value and
+// stack are logged, not sent. If the response had already started, it can't be changed, so the
+// connection is closed instead, the way net/http handles a panic.
+func RecoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pw := &panicResponseWriter{ResponseWriter: w}
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// net/http's way to abort a response; it isn't a bug
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			recovered(r.Method+" "+r.URL.Path, rec)
+			if pw.started {
+				panic(http.ErrAbortHandler)
+			}
+
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(Problem{
+				Type:   "about:blank",
+				Title:  http.StatusText(http.StatusInternalServerError),
+				Status: http.StatusInternalServerError,
+				Code:   ErrcdAppPanic,
+			})
+		}()
+		next.ServeHTTP(pw, r)
+	})
+}
+
+// panicResponseWriter notes whether the response has started. It passes Flush through for
+// streams, and Unwrap lets http.ResponseController reach the rest.
+type panicResponseWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (pw *panicResponseWriter) WriteHeader(status int) {
+	pw.started = true
+	pw.ResponseWriter.WriteHeader(status)
+}
+
+func (pw *panicResponseWriter) Write(p []byte) (int, error) {
+	pw.started = true
+	return pw.ResponseWriter.Write(p)
+}
+
+func (pw *panicResponseWriter) Flush() {
+	if f, ok := pw.ResponseWriter.(http.Flusher); ok {
+		pw.started = true
+		f.Flush()
+	}
+}
+
+func (pw *panicResponseWriter) Unwrap() http.ResponseWriter {
+	return pw.ResponseWriter
+}
@@ -0,0 +1,59 @@
+package common
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+)
+
+// sqlStateError matches pgconn.PgError (and other drivers' errors) without importing the driver.
+type sqlStateError interface {
+	SQLState() string
+}
+
+// Postgres SQLSTATE values and classes we care about.
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation      = "23505"
+	pgConnectionException  = "08"
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgAdminShutdown        = "57P01"
+	pgCannotConnectNow     = "57P03"
+)
+
+// PgErrToCommon converts an error returned by the Postgres driver into a CommonError.
+func PgErrToCommon(err error) *CommonError {
+	if err == nil {
+		return nil
+	}
+
+	var ce *CommonError
+	if errors.As(err, &ce) {
+		return ce
+	}
+
+	var pgErr sqlStateError
+	if errors.As(err, &pgErr) {
+		state := pgErr.SQLState()
+		switch {
+		case state == pgUniqueViolation:
+			return NewCommonError(ErrcdRepoDupeRow, err)
+		case strings.HasPrefix(state, pgConnectionException), state == pgAdminShutdown, state == pgCannotConnectNow:
+			// 57P01 and 57P03 are a server shutting down or still starting, like during a failover
+			return NewCommonError(ErrcdRepoConnection, err)
+		case state == pgSerializationFailure, state == pgDeadlockDetected:
+			return NewCommonError(ErrcdRepoTransient, err)
+		}
+		return NewCommonError(ErrcdRepoOther, err)
+	}
+
+	// failures before a session exists don't have a SQLSTATE
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr) {
+		return NewCommonError(ErrcdRepoConnection, err)
+	}
+
+	return NewCommonError(ErrcdRepoOther, err)
+}
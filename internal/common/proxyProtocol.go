@@ -0,0 +1,197 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProxyHeaderTimeout is how long a trusted proxy has to send its PROXY protocol header.
+const DefaultProxyHeaderTimeout = 5 * time.Second
+
+// maxProxyV1Len is the longest PROXY protocol v1 line, including CRLF.
+const maxProxyV1Len = 107
+
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// ProxyProtocolListener reads PROXY protocol (v1 or v2) headers from connections made by
+// trusted proxies, so RemoteAddr is the client, not the load balancer. Connections from
+// trusted proxies must send a header; others are passed through untouched, so a client
+// can't claim an address by sending its own header.
+type ProxyProtocolListener struct {
+	net.Listener
+	Trusted       TrustedProxies
+	HeaderTimeout time.Duration
+}
+
+func NewProxyProtocolListener(ln net.Listener, trusted TrustedProxies) *ProxyProtocolListener {
+	return &ProxyProtocolListener{Listener: ln, Trusted: trusted, HeaderTimeout: DefaultProxyHeaderTimeout}
+}
+
+// Accept doesn't read the header; that happens on the connection's first Read or RemoteAddr,
+// which http.Server does in the connection's own goroutine, so a slow proxy can't stall Accept.
+func (ln *ProxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := ln.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &proxyConn{Conn: conn, trusted: ln.Trusted, timeout: ln.HeaderTimeout}, nil
+}
+
+type proxyConn struct {
+	net.Conn
+	trusted TrustedProxies
+	timeout time.Duration
+
+	once   sync.Once
+	br     *bufio.Reader
+	remote net.Addr
+	err    error
+}
+
+func (c *proxyConn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.br.Read(b)
+}
+
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	return c.remote
+}
+
+func (c *proxyConn) readHeader() {
+	c.remote = c.Conn.RemoteAddr()
+	c.br = bufio.NewReader(c.Conn)
+
+	ip, err := remoteAddr(c.remote.String())
+	if err != nil || !c.trusted.Trusts(ip) {
+		return
+	}
+
+	if c.timeout > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+		defer c.Conn.SetReadDeadline(time.Time{})
+	}
+	src, err := readProxyHeader(c.br)
+	if err != nil {
+		c.err = fmt.Errorf("PROXY protocol header from %s: %w", c.remote, err)
+		return
+	}
+	if src != nil {
+		c.remote = src
+	}
+}
+
+// readProxyHeader returns the source address in the header, or nil for LOCAL and UNKNOWN
+// headers (health checks), which mean "use the connection address."
+func readProxyHeader(br *bufio.Reader) (net.Addr, error) {
+	first, err := br.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	switch first[0] {
+	case 'P':
+		return readProxyV1(br)
+	case '\r':
+		return readProxyV2(br)
+	}
+	return nil, errors.New("missing header")
+}
+
+// readProxyV1 reads "PROXY TCP4 <src> <dst> <srcport> <dstport>\r\n".
+func readProxyV1(br *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for len(line) < maxProxyV1Len {
+		b, err := br.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	s, ok := strings.CutSuffix(string(line), "\r\n")
+	if !ok {
+		return nil, errors.New("v1 header too long or not CRLF terminated")
+	}
+
+	fields := strings.Split(s, " ")
+	if len(fields) < 2 || fields[0] != "PROXY" {
+		return nil, fmt.Errorf("bad v1 header %q", s)
+	}
+	switch fields[1] {
+	case "UNKNOWN":
+		return nil, nil
+	case "TCP4", "TCP6":
+	default:
+		return nil, fmt.Errorf("bad v1 protocol %q", fields[1])
+	}
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("bad v1 header %q", s)
+	}
+	ip, err := netip.ParseAddr(fields[2])
+	if err != nil || ip.Is4() != (fields[1] == "TCP4") || ip.Zone() != "" {
+		return nil, fmt.Errorf("bad v1 source address %q", fields[2])
+	}
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("bad v1 source port %q", fields[4])
+	}
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(ip.Unmap(), uint16(port))), nil
+}
+
+// readProxyV2 reads the binary header: signature, version/command, family, length, addresses.
+func readProxyV2(br *bufio.Reader) (net.Addr, error) {
+	hdr := make([]byte, 16)
+	if _, err := io.ReadFull(br, hdr); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hdr[:12], proxyV2Signature) {
+		return nil, errors.New("bad v2 signature")
+	}
+	if hdr[12]>>4 != 2 {
+		return nil, fmt.Errorf("unsupported version %d", hdr[12]>>4)
+	}
+	body := make([]byte, binary.BigEndian.Uint16(hdr[14:16]))
+	if _, err := io.ReadFull(br, body); err != nil {
+		return nil, err
+	}
+
+	switch hdr[12] & 0x0f {
+	case 0x0: // LOCAL
+		return nil, nil
+	case 0x1: // PROXY
+	default:
+		return nil, fmt.Errorf("bad v2 command %d", hdr[12]&0x0f)
+	}
+
+	var addrLen int
+	switch hdr[13] >> 4 {
+	case 0x1: // AF_INET
+		addrLen = 4
+	case 0x2: // AF_INET6
+		addrLen = 16
+	default:
+		// UNSPEC or AF_UNIX: nothing useful for an IP allowlist
+		return nil, nil
+	}
+	if len(body) < 2*addrLen+4 {
+		return nil, errors.New("v2 address block too short")
+	}
+	ip, _ := netip.AddrFromSlice(body[:addrLen])
+	port := binary.BigEndian.Uint16(body[2*addrLen:])
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(ip.Unmap(), port)), nil
+}
@@ -0,0 +1,234 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+// proxyV2 builds a v2 header: version 2 with command cmd, family fam, and body.
+func proxyV2(cmd byte, fam byte, body []byte) []byte {
+	hdr := append([]byte{}, proxyV2Signature...)
+	hdr = append(hdr, 0x20|cmd, fam, 0, 0)
+	binary.BigEndian.PutUint16(hdr[14:], uint16(len(body)))
+	return append(hdr, body...)
+}
+
+// proxyV2Addrs is the address block for src:srcPort -> dst:dstPort.
+func proxyV2Addrs(src string, dst string, srcPort uint16, dstPort uint16) []byte {
+	b := append(netip.MustParseAddr(src).AsSlice(), netip.MustParseAddr(dst).AsSlice()...)
+	return binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(b, srcPort), dstPort)
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	v4 := proxyV2Addrs("198.51.100.1", "10.0.0.1", 5000, 443)
+	v6 := proxyV2Addrs("2001:db8::1", "2001:db8::2", 5000, 443)
+
+	for _, tc := range []struct {
+		name    string
+		header  string
+		want    string // source address, or empty for "use the connection address"
+		wantErr string
+	}{
+		{"v1 tcp4", "PROXY TCP4 198.51.100.1 10.0.0.1 5000 443\r\n", "198.51.100.1:5000", ""},
+		{"v1 tcp6", "PROXY TCP6 2001:db8::1 2001:db8::2 5000 443\r\n", "[2001:db8::1]:5000", ""},
+		{"v1 tcp6 mapped", "PROXY TCP6 ::ffff:198.51.100.1 ::ffff:10.0.0.1 5000 443\r\n", "198.51.100.1:5000", ""},
+		{"v1 unknown", "PROXY UNKNOWN\r\n", "", ""},
+		{"v1 unknown with addresses", "PROXY UNKNOWN ff ff 1 2\r\n", "", ""},
+		{"v1 longest", "PROXY TCP6 ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff 65535 65535\r\n", "[ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff]:65535", ""},
+
+		// malformed v1
+		{"v1 no CRLF", "PROXY TCP4 198.51.100.1 10.0.0.1 5000 443\n", "", "CRLF"},
+		{"v1 too long", "PROXY UNKNOWN " + strings.Repeat("x", maxProxyV1Len) + "\r\n", "", "too long"},
+		{"v1 truncated", "PROXY TCP4 198.51.100.1", "", "EOF"},
+		{"v1 not PROXY", "PROXZ TCP4 198.51.100.1 10.0.0.1 5000 443\r\n", "", "bad v1 header"},
+		{"v1 no protocol", "PROXY\r\n", "", "bad v1 header"},
+		{"v1 udp", "PROXY UDP4 198.51.100.1 10.0.0.1 5000 443\r\n", "", "bad v1 protocol"},
+		{"v1 missing port", "PROXY TCP4 198.51.100.1 10.0.0.1 5000\r\n", "", "bad v1 header"},
+		{"v1 double space", "PROXY TCP4  198.51.100.1 10.0.0.1 5000 443\r\n", "", "bad v1"},
+		{"v1 bad address", "PROXY TCP4 198.51.100.300 10.0.0.1 5000 443\r\n", "", "source address"},
+		{"v1 tcp4 with ipv6", "PROXY TCP4 2001:db8::1 10.0.0.1 5000 443\r\n", "", "source address"},
+		{"v1 tcp6 with ipv4", "PROXY TCP6 198.51.100.1 2001:db8::2 5000 443\r\n", "", "source address"},
+		{"v1 zone", "PROXY TCP6 fe80::1%eth0 fe80::2 5000 443\r\n", "", "source address"},
+		{"v1 port too big", "PROXY TCP4 198.51.100.1 10.0.0.1 65536 443\r\n", "", "source port"},
+		{"v1 negative port", "PROXY TCP4 198.51.100.1 10.0.0.1 -1 443\r\n", "", "source port"},
+
+		{"v2 ipv4", string(proxyV2(0x1, 0x11, v4)), "198.51.100.1:5000", ""},
+		{"v2 ipv6", string(proxyV2(0x1, 0x21, v6)), "[2001:db8::1]:5000", ""},
+		{"v2 with TLVs", string(proxyV2(0x1, 0x11, append(v4, 0x04, 0, 1, 'x'))), "198.51.100.1:5000", ""},
+		{"v2 local", string(proxyV2(0x0, 0x00, nil)), "", ""},
+		{"v2 local with addresses", string(proxyV2(0x0, 0x11, v4)), "", ""},
+		{"v2 unspec", string(proxyV2(0x1, 0x00, nil)), "", ""},
+		{"v2 unix", string(proxyV2(0x1, 0x31, make([]byte, 216))), "", ""},
+
+		// malformed and truncated v2
+		{"v2 truncated signature", string(proxyV2Signature[:8]), "", "EOF"},
+		{"v2 truncated header", string(proxyV2(0x1, 0x11, v4)[:14]), "", "EOF"},
+		{"v2 truncated body", string(proxyV2(0x1, 0x11, v4)[:20]), "", "EOF"},
+		{"v2 bad signature", "\r\n\r\n\x00\r\nQUIT!" + string(proxyV2(0x1, 0x11, v4)[12:]), "", "signature"},
+		{"v2 version 1", string(append(append([]byte{}, proxyV2Signature...), 0x11, 0x11, 0, 0)), "", "version"},
+		{"v2 bad command", string(proxyV2(0x2, 0x11, v4)), "", "command"},
+		{"v2 ipv4 block too short", string(proxyV2(0x1, 0x11, v4[:11])), "", "too short"},
+		{"v2 ipv6 block too short", string(proxyV2(0x1, 0x21, v4)), "", "too short"},
+
+		{"missing header", "GET / HTTP/1.1\r\n", "", "missing header"},
+		{"empty", "", "", "EOF"},
+	} {
+		if tc.wantErr != "" {
+			// nothing after the header, so truncated headers hit the end of the stream
+			_, err := readProxyHeader(bufio.NewReader(strings.NewReader(tc.header)))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%s: got %v, want an error containing %q", tc.name, err, tc.wantErr)
+			}
+			continue
+		}
+
+		br := bufio.NewReader(strings.NewReader(tc.header + "GET / HTTP/1.1\r\n"))
+		got, err := readProxyHeader(br)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if (got == nil && tc.want != "") || (got != nil && got.String() != tc.want) {
+			t.Errorf("%s: got %v, want %q", tc.name, got, tc.want)
+		}
+		// the request after the header is left for the server
+		if rest, _ := br.ReadString('\n'); rest != "GET / HTTP/1.1\r\n" {
+			t.Errorf("%s: left %q after the header", tc.name, rest)
+		}
+	}
+}
+
+// proxyListen sends send over one loopback connection through a ProxyProtocolListener that
+// trusts trusted, and returns the remote address and data the server side sees.
+func proxyListen(t *testing.T, trusted string, timeout time.Duration, send []byte) (remote string, data string, err error) {
+	t.Helper()
+	tp, perr := ParseTrustedProxies(trusted)
+	if perr != nil {
+		t.Fatal(perr)
+	}
+	inner, lerr := net.Listen("tcp", "127.0.0.1:0")
+	if lerr != nil {
+		t.Fatal(lerr)
+	}
+	ln := NewProxyProtocolListener(inner, tp)
+	ln.HeaderTimeout = timeout
+	defer ln.Close()
+
+	client, derr := net.Dial("tcp", ln.Addr().String())
+	if derr != nil {
+		t.Fatal(derr)
+	}
+	defer client.Close()
+	if len(send) > 0 {
+		client.Write(send)
+	}
+	client.(*net.TCPConn).CloseWrite()
+
+	conn, aerr := ln.Accept()
+	if aerr != nil {
+		t.Fatal(aerr)
+	}
+	defer conn.Close()
+	remote = conn.RemoteAddr().String()
+	b, err := io.ReadAll(conn)
+	return remote, string(b), err
+}
+
+func TestProxyProtocolListener(t *testing.T) {
+	header := "PROXY TCP4 198.51.100.1 10.0.0.1 5000 443\r\n"
+
+	remote, data, err := proxyListen(t, "127.0.0.1", time.Second, []byte(header+"GET /"))
+	if err != nil || remote != "198.51.100.1:5000" || data != "GET /" {
+		t.Errorf("trusted v1: got %s %q %v, want the header's source and the rest of the data", remote, data, err)
+	}
+
+	remote, data, err = proxyListen(t, "127.0.0.1", time.Second, append(proxyV2(0x1, 0x11, proxyV2Addrs("198.51.100.1", "10.0.0.1", 5000, 443)), "GET /"...))
+	if err != nil || remote != "198.51.100.1:5000" || data != "GET /" {
+		t.Errorf("trusted v2: got %s %q %v, want the header's source and the rest of the data", remote, data, err)
+	}
+
+	remote, data, err = proxyListen(t, "127.0.0.1", time.Second, []byte("PROXY UNKNOWN\r\nGET /"))
+	if err != nil || !strings.HasPrefix(remote, "127.0.0.1:") || data != "GET /" {
+		t.Errorf("trusted health check: got %s %q %v, want the proxy's address", remote, data, err)
+	}
+
+	// a client that isn't a trusted proxy can't claim an address; its header is just data
+	remote, data, err = proxyListen(t, "10.0.0.0/8", time.Second, []byte(header+"GET /"))
+	if err != nil || !strings.HasPrefix(remote, "127.0.0.1:") || data != header+"GET /" {
+		t.Errorf("untrusted: got %s %q %v, want the connection address and the data untouched", remote, data, err)
+	}
+
+	if _, _, err = proxyListen(t, "127.0.0.1", time.Second, []byte("GET / HTTP/1.1\r\n")); err == nil || !strings.Contains(err.Error(), "missing header") {
+		t.Errorf("trusted without a header: got %v, want missing header", err)
+	}
+	if _, _, err = proxyListen(t, "127.0.0.1", time.Second, []byte("PROXY TCP4 198.51.100.1 10.0.0.1 5000 443\n")); err == nil {
+		t.Error("trusted with a malformed header: got no error")
+	}
+	if _, _, err = proxyListen(t, "127.0.0.1", time.Second, proxyV2(0x1, 0x11, nil)[:15]); err == nil {
+		t.Error("trusted with a truncated v2 header: got no error")
+	}
+}
+
+func TestProxyProtocolHeaderTimeout(t *testing.T) {
+	tp, _ := ParseTrustedProxies("127.0.0.1")
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln := NewProxyProtocolListener(inner, tp)
+	ln.HeaderTimeout = 50 * time.Millisecond
+	defer ln.Close()
+
+	// the proxy connects and sends nothing
+	client, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Error("got no error, want a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("read took %s, want about %s", elapsed, ln.HeaderTimeout)
+	}
+}
+
+func FuzzParseProxyHeader(f *testing.F) {
+	f.Add([]byte("PROXY TCP4 198.51.100.1 10.0.0.1 5000 443\r\n"))
+	f.Add([]byte("PROXY TCP6 2001:db8::1 2001:db8::2 5000 443\r\n"))
+	f.Add([]byte("PROXY UNKNOWN\r\n"))
+	f.Add(proxyV2(0x1, 0x11, proxyV2Addrs("198.51.100.1", "10.0.0.1", 5000, 443)))
+	f.Add(proxyV2(0x1, 0x21, proxyV2Addrs("2001:db8::1", "2001:db8::2", 5000, 443)))
+	f.Add(proxyV2(0x0, 0x00, nil))
+	f.Add([]byte("GET / HTTP/1.1\r\n"))
+
+	f.Fuzz(func(t *testing.T, in []byte) {
+		r := bytes.NewReader(in)
+		src, err := readProxyHeader(bufio.NewReader(r))
+		if err != nil || src == nil {
+			return
+		}
+		tcp, ok := src.(*net.TCPAddr)
+		if !ok {
+			t.Fatalf("got %T, want *net.TCPAddr", src)
+		}
+		ip, ok := netip.AddrFromSlice(tcp.IP)
+		if !ok || !ip.IsValid() || ip.Is4In6() || ip.Zone() != "" {
+			t.Fatalf("got source %v from %q", src, in)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package common
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// RequestIdHeader carries the request id. A client or gateway can send one; otherwise the
+// server makes one. It's always in the response.
+const RequestIdHeader = "X-Request-Id"
+
+// maxRequestIdLen bounds request ids clients send, so they're safe to log.
+const maxRequestIdLen = 128
+
+type requestIdKey struct{}
+
+// AssignRequestId puts the request's id in the request context, where RequestIdOf finds it, and
+// in the response header.
+func AssignRequestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.Header.Get(RequestIdHeader))
+		if !validRequestId(id) {
+			id = newRequestId()
+		}
+		w.Header().Set(RequestIdHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIdKey{}, id)))
+	})
+}
+
+func newRequestId() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestIdOf returns the id AssignRequestId gave the request, or "" if it didn't run.
+func RequestIdOf(r *http.Request) string {
+	return requestIdFrom(r.Context())
+}
+
+func requestIdFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
+
+// validRequestId is true if a client's request id is safe to use and log.
+func validRequestId(id string) bool {
+	return id != "" && len(id) <= maxRequestIdLen && !strings.ContainsAny(id, "\r\n")
+}
+
+// ResponseMeta collects what a handler wants to tell the client besides the response body, for
+// responses that have somewhere to put it (see jobStatus.Envelope). It's safe for concurrent use.
+type ResponseMeta struct {
+	mu       sync.Mutex
+	page     any
+	warnings []string
+}
+
+type responseMetaKey struct{}
+
+// WithResponseMeta returns a context that carries meta, for AddWarning and SetPage.
+func WithResponseMeta(ctx context.Context, meta *ResponseMeta) context.Context {
+	return context.WithValue(ctx, responseMetaKey{}, meta)
+}
+
+// ResponseMetaOf returns the context's meta, or nil if the response has nowhere to put it.
+func ResponseMetaOf(ctx context.Context) *ResponseMeta {
+	meta, _ := ctx.Value(responseMetaKey{}).(*ResponseMeta)
+	return meta
+}
+
+// AddWarning tells the client about something that didn't stop the request, like a quota that's
+// nearly used up. Repeats of the same warning are dropped. Without meta in ctx, it does nothing,
+// so callers that also need the warning in a log should log it themselves.
+func AddWarning(ctx context.Context, warning string) {
+	meta := ResponseMetaOf(ctx)
+	if meta == nil {
+		return
+	}
+	meta.mu.Lock()
+	defer meta.mu.Unlock()
+	for _, w := range meta.warnings {
+		if w == warning {
+			return
+		}
+	}
+	meta.warnings = append(meta.warnings, warning)
+}
+
+// SetPage records where a page of results starts, like a *dto.PageDto.
+func SetPage(ctx context.Context, page any) {
+	meta := ResponseMetaOf(ctx)
+	if meta == nil {
+		return
+	}
+	meta.mu.Lock()
+	defer meta.mu.Unlock()
+	meta.page = page
+}
+
+func (meta *ResponseMeta) Page() any {
+	meta.mu.Lock()
+	defer meta.mu.Unlock()
+	return meta.page
+}
+
+func (meta *ResponseMeta) Warnings() []string {
+	meta.mu.Lock()
+	defer meta.mu.Unlock()
+	return append([]string(nil), meta.warnings...)
+}
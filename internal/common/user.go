@@ -0,0 +1,82 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// UserHeader is where an authenticating proxy (like oauth2-proxy) puts the signed-in user.
+const UserHeader = "X-Forwarded-User"
+
+// RolesHeader is where the proxy puts the user's groups, comma separated. They're the user's roles.
+const RolesHeader = "X-Forwarded-Groups"
+
+// MaxUserLen bounds user names so they fit the columns that store them.
+const MaxUserLen = 200
+
+type userKey struct{}
+
+type rolesKey struct{}
+
+// ResolveUser puts the user a trusted proxy reports in UserHeader into the request context, where
+// UserOf finds it, and the user's roles from RolesHeader, where RolesOf finds them. The headers
+// are ignored on connections from anywhere else, because clients could write them themselves.
+func (tp TrustedProxies) ResolveUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, err := remoteAddr(r.RemoteAddr)
+		user := strings.TrimSpace(r.Header.Get(UserHeader))
+		if err != nil || !tp.Trusts(ip) || user == "" || len(user) > MaxUserLen {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := context.WithValue(r.Context(), userKey{}, user)
+		var roles []string
+		for _, role := range strings.Split(r.Header.Get(RolesHeader), ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				roles = append(roles, role)
+			}
+		}
+		if len(roles) > 0 {
+			ctx = context.WithValue(ctx, rolesKey{}, roles)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// UserOf returns the user ResolveUser found; ok is false if there isn't one.
+func UserOf(r *http.Request) (user string, ok bool) {
+	user, ok = r.Context().Value(userKey{}).(string)
+	return user, ok
+}
+
+// RolesOf returns the user's roles ResolveUser found, if any.
+func RolesOf(r *http.Request) []string {
+	roles, _ := r.Context().Value(rolesKey{}).([]string)
+	return roles
+}
+
+// RequireRole only passes requests from a signed-in user with role to next. Requests without a
+// user are 401 and users without the role are 403. If role is empty, every request is refused,
+// so what it guards is off unless configured.
+func RequireRole(role string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if role == "" {
+			WriteError(w, r, NewCommonError(ErrcdForbidden, errors.New("no role is configured for this route")))
+			return
+		}
+		if _, ok := UserOf(r); !ok {
+			WriteError(w, r, NewCommonError(ErrcdUnauthorized, errors.New("not signed in")))
+			return
+		}
+		for _, have := range RolesOf(r) {
+			if have == role {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		WriteError(w, r, NewCommonError(ErrcdForbidden, fmt.Errorf("role %s is required", role)))
+	})
+}
@@ -0,0 +1,21 @@
+package common
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// NewUuidV7 returns a UUIDv7 (RFC 9562) string. They sort by creation time, so they index well as keys.
+func NewUuidV7() string {
+	var b [16]byte
+	// 48-bit Unix milliseconds, then random bits with the version and variant set
+	binary.BigEndian.PutUint64(b[0:8], uint64(time.Now().UnixMilli())<<16)
+	if _, err := rand.Read(b[6:]); err != nil {
+		panic(fmt.Sprintf("NewUuidV7: crypto/rand failed: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x70
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
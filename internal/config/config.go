@@ -0,0 +1,207 @@
+// Package config checks configuration structs against their validate tags and collects
+// configuration problems, so startup can report every bad setting at once instead of stopping
+// at the first.
+//
+// A field's validate tag lists rules, comma separated:
+//
+//	required      not the zero value
+//	min=N, max=N  numbers and durations (like max=1m) at least or at most N; lengths for
+//	              strings, slices, and maps
+//	url           empty, or an absolute http or https URL
+//	oneof=a|b     empty, or one of the listed strings
+//
+// Problems name the field by its env tag, if it has one, so they match what the operator set.
+// Nested structs are checked too.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Validate checks v, a struct or pointer to one, and returns every problem found, joined.
+func Validate(v any) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("config.Validate needs a struct, not %T", v)
+	}
+	var errs []error
+	validateStruct(rv, "", &errs)
+	return errors.Join(errs...)
+}
+
+func validateStruct(rv reflect.Value, prefix string, errs *[]error) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Tag.Get("env")
+		if name == "" {
+			name = prefix + field.Name
+		}
+
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Struct && fv.Type() != reflect.TypeOf(time.Time{}) {
+			validateStruct(fv, name+".", errs)
+		}
+
+		tag := field.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		for _, rule := range strings.Split(tag, ",") {
+			if err := checkRule(fv, strings.TrimSpace(rule)); err != nil {
+				*errs = append(*errs, fmt.Errorf("%s %w", name, err))
+			}
+		}
+	}
+}
+
+// checkRule returns an error that reads after the field's name, like "must be at most 5".
+func checkRule(fv reflect.Value, rule string) error {
+	key, arg, _ := strings.Cut(rule, "=")
+	switch key {
+	case "required":
+		if fv.IsZero() {
+			return errors.New("is required")
+		}
+	case "min", "max":
+		if isEmptyText(fv) {
+			return nil
+		}
+		n, limit, err := numberAndLimit(fv, arg)
+		if err != nil {
+			return err
+		}
+		if key == "min" && n < limit {
+			return fmt.Errorf("%s must be at least %s", describe(fv), arg)
+		}
+		if key == "max" && n > limit {
+			return fmt.Errorf("%s must be at most %s", describe(fv), arg)
+		}
+	case "url":
+		s := fv.String()
+		if s == "" {
+			return nil
+		}
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%q must be an absolute http or https URL", s)
+		}
+	case "oneof":
+		s := fv.String()
+		if s == "" {
+			return nil
+		}
+		for _, allowed := range strings.Split(arg, "|") {
+			if s == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q must be one of %s", s, strings.ReplaceAll(arg, "|", ", "))
+	default:
+		return fmt.Errorf("has unknown validate rule %q", rule)
+	}
+	return nil
+}
+
+// isEmptyText is true for empty strings, which min and max skip; required catches them.
+func isEmptyText(fv reflect.Value) bool {
+	return fv.Kind() == reflect.String && fv.Len() == 0
+}
+
+// numberAndLimit returns the field's value (its length for strings, slices, and maps) and the
+// rule's limit as comparable numbers.
+func numberAndLimit(fv reflect.Value, arg string) (float64, float64, error) {
+	if fv.Type() == durationType {
+		limit, err := time.ParseDuration(arg)
+		if err != nil {
+			return 0, 0, fmt.Errorf("has a bad duration limit %q", arg)
+		}
+		return float64(fv.Int()), float64(limit), nil
+	}
+
+	limit, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("has a bad limit %q", arg)
+	}
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), limit, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint()), limit, nil
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), limit, nil
+	case reflect.String, reflect.Slice, reflect.Map:
+		return float64(fv.Len()), limit, nil
+	}
+	return 0, 0, fmt.Errorf("can't have min or max (it's %s)", fv.Type())
+}
+
+// describe is what min and max say was out of range.
+func describe(fv reflect.Value) string {
+	switch {
+	case fv.Type() == durationType:
+		return time.Duration(fv.Int()).String()
+	case fv.Kind() == reflect.String, fv.Kind() == reflect.Slice, fv.Kind() == reflect.Map:
+		return fmt.Sprintf("length %d", fv.Len())
+	}
+	return fmt.Sprint(fv.Interface())
+}
+
+// Problems collects configuration errors. The zero value is ready to use.
+type Problems struct {
+	errs []error
+}
+
+// Add records err, if it isn't nil. Joined errors, like Validate's, are recorded one by one.
+func (p *Problems) Add(err error) {
+	if err == nil {
+		return
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			p.Add(e)
+		}
+		return
+	}
+	p.errs = append(p.errs, err)
+}
+
+// Err returns nil if there were no problems, or an error listing each on its own line.
+func (p *Problems) Err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	return &ProblemsError{Errs: append([]error(nil), p.errs...)}
+}
+
+type ProblemsError struct {
+	Errs []error
+}
+
+func (pe *ProblemsError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d configuration problem", len(pe.Errs))
+	if len(pe.Errs) != 1 {
+		b.WriteString("s")
+	}
+	b.WriteString(":")
+	for _, err := range pe.Errs {
+		b.WriteString("\n  - " + err.Error())
+	}
+	return b.String()
+}
+
+func (pe *ProblemsError) Unwrap() []error {
+	return pe.Errs
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// The Env functions read one setting with getenv, usually os.Getenv. An unset setting gets def.
+// A setting that doesn't parse returns an error that names it, so callers can Add the error to
+// their Problems and keep reading.
+
+// EnvOr returns the setting, or def if it isn't set.
+func EnvOr(getenv func(string) string, name string, def string) string {
+	if v := getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// EnvBool reads true or false; unset is false.
+func EnvBool(getenv func(string) string, name string) (bool, error) {
+	v := getenv(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s %q must be true or false", name, v)
+	}
+	return b, nil
+}
+
+// EnvInt reads a whole number.
+func EnvInt(getenv func(string) string, name string, def int) (int, error) {
+	v := getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def, fmt.Errorf("%s %q must be a whole number", name, v)
+	}
+	return n, nil
+}
+
+// EnvDuration reads a duration, like 10s or 5m.
+func EnvDuration(getenv func(string) string, name string, def time.Duration) (time.Duration, error) {
+	v := getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return def, fmt.Errorf("%s %q must be a duration, like 10s or 5m", name, v)
+	}
+	return d, nil
+}
+
+// EnvClock reads a time of day as HH:MM. def must be HH:MM too.
+func EnvClock(getenv func(string) string, name string, def string) (hour int, minute int, err error) {
+	v := EnvOr(getenv, name, def)
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s %q must be HH:MM, like %s", name, v, def)
+	}
+	return t.Hour(), t.Minute(), nil
+}
@@ -0,0 +1,132 @@
+// Package delivery sends scheduled query results to their destinations.
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+	"github.com/jmjf/go-jst/public/webhook"
+)
+
+// DefaultWebhookTimeout bounds one delivery, including reading the response.
+const DefaultWebhookTimeout = 30 * time.Second
+
+// WebhookDeliverer POSTs results as JSON, signed with webhook.SignRequest so receivers can
+// check them with webhook.VerifyRequest.
+type WebhookDeliverer struct {
+	client  *http.Client
+	secrets [][]byte
+}
+
+var _ jobStatus.Deliverer = (*WebhookDeliverer)(nil)
+
+// NewWebhookDeliverer signs with every secret, so receivers keep working while a secret is rotated.
+func NewWebhookDeliverer(client *http.Client, secrets ...[]byte) *WebhookDeliverer {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultWebhookTimeout}
+	}
+	return &WebhookDeliverer{client: client, secrets: secrets}
+}
+
+// WebhookDelivererFromEnv reads secrets from GOJST_DELIVERY_SECRET, comma separated, newest first.
+// ok is false if it isn't set.
+func WebhookDelivererFromEnv(getenv func(string) string) (d *WebhookDeliverer, ok bool, err error) {
+	s := getenv("GOJST_DELIVERY_SECRET")
+	if s == "" {
+		return nil, false, nil
+	}
+	var secrets [][]byte
+	for _, secret := range strings.Split(s, ",") {
+		secret = strings.TrimSpace(secret)
+		if secret == "" {
+			return nil, false, fmt.Errorf("GOJST_DELIVERY_SECRET has an empty secret")
+		}
+		secrets = append(secrets, []byte(secret))
+	}
+	return NewWebhookDeliverer(nil, secrets...), true, nil
+}
+
+// Deliver returns an error for any response that isn't 2xx. It doesn't retry.
+func (d *WebhookDeliverer) Deliver(ctx context.Context, sq jobStatus.ScheduledQuery, result dto.ScheduledQueryResultDto) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sq.DeliveryTarget, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	webhook.SignRequest(req, body, d.secrets...)
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	// drain a little so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", res.Status)
+	}
+	return nil
+}
+
+// SelfTest renders and signs a sample delivery and sends it to a null sink that checks the
+// signature with every secret, so rendering and signing problems show up at startup instead of at
+// the first scheduled run. Nothing leaves the process.
+func (d *WebhookDeliverer) SelfTest(ctx context.Context) error {
+	now := time.Now().UTC()
+	sq := jobStatus.ScheduledQuery{Name: "self-test", Team: "self-test", DeliveryKind: dto.DeliveryWebhook, DeliveryTarget: "https://self-test.invalid/"}
+	result := dto.ScheduledQueryResultDto{
+		Name:         sq.Name,
+		Team:         sq.Team,
+		BusinessDate: now.Format(dto.DateFormat),
+		RunTimestamp: now.Format(dto.TimestampFormat),
+		JobStatuses: []dto.JobStatusDto{{
+			ApplicationId:      "self-test",
+			JobId:              "self-test",
+			JobStatusCode:      string(jobStatus.JobStatus_SUCCEED),
+			JobStatusTimestamp: now.Format(dto.TimestampFormat),
+			BusinessDate:       now.Format(dto.DateFormat),
+		}},
+	}
+
+	sink := &WebhookDeliverer{client: &http.Client{Transport: nullSink{secrets: d.secrets}}, secrets: d.secrets}
+	return sink.Deliver(ctx, sq, result)
+}
+
+// nullSink answers requests itself. It's 401 if any secret doesn't verify the request's signature.
+type nullSink struct {
+	secrets [][]byte
+}
+
+func (ns nullSink) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	status := http.StatusNoContent
+	for _, secret := range ns.secrets {
+		if err := webhook.Verify(req.Header.Get(webhook.SignatureHeader), body, secret, time.Now(), webhook.DefaultTolerance); err != nil {
+			status = http.StatusUnauthorized
+		}
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
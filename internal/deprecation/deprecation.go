@@ -0,0 +1,224 @@
+// Package deprecation marks routes as deprecated. Responses from a deprecated route carry
+// Deprecation (RFC 9745), Sunset (RFC 8594), and Link headers, and calls are counted by caller
+// so a route or DTO version can be retired once the data says nobody uses it.
+package deprecation
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// AdminPath is the route for the usage report.
+const AdminPath = "/admin/deprecations"
+
+// maxCallers bounds the callers counted per route. Calls from callers past the limit are counted
+// under otherCallers.
+const (
+	maxCallers   = 1000
+	otherCallers = "(other)"
+)
+
+// Notice deprecates one route.
+type Notice struct {
+	// Method is the HTTP method, like GET. Empty means every method.
+	Method string
+	Path   string
+	// Deprecated is when the route was deprecated. It's sent as the Deprecation header.
+	Deprecated time.Time
+	// Sunset is when the route will stop working, if that's been decided. It's sent as the Sunset
+	// header.
+	Sunset time.Time
+	// Link is a page about the deprecation or the route to use instead. It's sent as a Link
+	// header with rel="deprecation".
+	Link string
+	// DtoVersion is the DTO version being retired, if that's why the route is deprecated. It's
+	// only reported, so usage can be summed by version.
+	DtoVersion string
+}
+
+func (n Notice) key() string {
+	return n.Method + " " + n.Path
+}
+
+// CallerUsage counts one caller's calls to a deprecated route since the server started.
+type CallerUsage struct {
+	Caller   string `json:"Caller"`
+	Calls    int64  `json:"Calls"`
+	LastCall string `json:"LastCall"`
+}
+
+// RouteUsage is a deprecated route and who still calls it.
+type RouteUsage struct {
+	Method     string        `json:"Method,omitempty"`
+	Path       string        `json:"Path"`
+	DtoVersion string        `json:"DtoVersion,omitempty"`
+	Deprecated string        `json:"Deprecated"`
+	Sunset     string        `json:"Sunset,omitempty"`
+	Calls      int64         `json:"Calls"`
+	Callers    []CallerUsage `json:"Callers"`
+}
+
+type route struct {
+	notice  Notice
+	headers http.Header
+	warning string
+	callers map[string]*CallerUsage
+}
+
+// Tracker adds headers to deprecated routes' responses and counts their calls. A nil *Tracker
+// deprecates nothing. It's safe for concurrent use.
+type Tracker struct {
+	routes map[string]*route
+
+	mu sync.Mutex
+}
+
+// NewTracker returns an error if a notice has no path or date, repeats a route, or has a sunset
+// before its deprecation.
+func NewTracker(notices ...Notice) (*Tracker, error) {
+	t := &Tracker{routes: map[string]*route{}}
+	for _, n := range notices {
+		switch {
+		case n.Path == "" || n.Deprecated.IsZero():
+			return nil, fmt.Errorf("deprecation notice %q needs a path and a deprecated date", name(n))
+		case !n.Sunset.IsZero() && n.Sunset.Before(n.Deprecated):
+			return nil, fmt.Errorf("deprecation notice %q has a sunset before it was deprecated", name(n))
+		case t.routes[n.key()] != nil:
+			return nil, fmt.Errorf("deprecation notice %q is repeated", name(n))
+		}
+		t.routes[n.key()] = &route{notice: n, headers: headersFor(n), warning: warningFor(n), callers: map[string]*CallerUsage{}}
+	}
+	return t, nil
+}
+
+func headersFor(n Notice) http.Header {
+	h := http.Header{}
+	h.Set("Deprecation", fmt.Sprintf("@%d", n.Deprecated.Unix()))
+	if !n.Sunset.IsZero() {
+		h.Set("Sunset", n.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if n.Link != "" {
+		h.Set("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", n.Link))
+	}
+	return h
+}
+
+func warningFor(n Notice) string {
+	warning := fmt.Sprintf("%s is deprecated", name(n))
+	if !n.Sunset.IsZero() {
+		warning += fmt.Sprintf(" and stops working on %s", n.Sunset.UTC().Format("2006-01-02"))
+	}
+	if n.Link != "" {
+		warning += "; see " + n.Link
+	}
+	return warning
+}
+
+func name(n Notice) string {
+	return strings.TrimSpace(n.key())
+}
+
+// Wrap adds the headers to responses from deprecated routes, adds a warning for enveloped
+// responses, and counts the calls. The caller is the user a trusted proxy reports, or else the
+// client address, so put Wrap inside ResolveUser and ResolveClientIp.
+func (t *Tracker) Wrap(next http.Handler) http.Handler {
+	if t == nil || len(t.routes) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rt := t.routes[r.Method+" "+r.URL.Path]
+		if rt == nil {
+			rt = t.routes[" "+r.URL.Path]
+		}
+		if rt == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		for name, values := range rt.headers {
+			w.Header()[name] = values
+		}
+		common.AddWarning(r.Context(), rt.warning)
+		t.count(rt, callerOf(r), r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func callerOf(r *http.Request) string {
+	if user, ok := common.UserOf(r); ok {
+		return user
+	}
+	if ip, err := common.ClientIpOf(r); err == nil {
+		return ip.String()
+	}
+	return "unknown"
+}
+
+// count logs a caller's first call to a route, so usage survives restarts in the logs.
+func (t *Tracker) count(rt *route, caller string, r *http.Request) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage := rt.callers[caller]
+	if usage == nil {
+		if len(rt.callers) >= maxCallers {
+			caller = otherCallers
+		}
+		if usage = rt.callers[caller]; usage == nil {
+			usage = &CallerUsage{Caller: caller}
+			rt.callers[caller] = usage
+			log.Printf("%s %s is deprecated and was called by %s", r.Method, r.URL.Path, caller)
+		}
+	}
+	usage.Calls++
+	usage.LastCall = time.Now().UTC().Format(time.RFC3339)
+}
+
+// Usage reports every deprecated route, with its callers since the server started, most calls
+// first. Routes are ordered by path and method.
+func (t *Tracker) Usage() []RouteUsage {
+	if t == nil {
+		return []RouteUsage{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := make([]RouteUsage, 0, len(t.routes))
+	for _, rt := range t.routes {
+		ru := RouteUsage{
+			Method:     rt.notice.Method,
+			Path:       rt.notice.Path,
+			DtoVersion: rt.notice.DtoVersion,
+			Deprecated: rt.notice.Deprecated.UTC().Format(time.RFC3339),
+			Callers:    make([]CallerUsage, 0, len(rt.callers)),
+		}
+		if !rt.notice.Sunset.IsZero() {
+			ru.Sunset = rt.notice.Sunset.UTC().Format(time.RFC3339)
+		}
+		for _, usage := range rt.callers {
+			ru.Calls += usage.Calls
+			ru.Callers = append(ru.Callers, *usage)
+		}
+		sort.Slice(ru.Callers, func(i, j int) bool {
+			if ru.Callers[i].Calls != ru.Callers[j].Calls {
+				return ru.Callers[i].Calls > ru.Callers[j].Calls
+			}
+			return ru.Callers[i].Caller < ru.Callers[j].Caller
+		})
+		report = append(report, ru)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Path != report[j].Path {
+			return report[i].Path < report[j].Path
+		}
+		return report[i].Method < report[j].Method
+	})
+	return report
+}
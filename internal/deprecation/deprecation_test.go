@@ -0,0 +1,213 @@
+package deprecation_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/deprecation"
+)
+
+var (
+	deprecated = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset     = time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestNewTracker(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		notices []deprecation.Notice
+		wantErr string // empty means the notices are accepted
+	}{
+		{"none", nil, ""},
+		{"one", []deprecation.Notice{{Method: "GET", Path: "/v1/x", Deprecated: deprecated, Sunset: sunset}}, ""},
+		{"same path, different methods", []deprecation.Notice{{Method: "GET", Path: "/x", Deprecated: deprecated}, {Method: "POST", Path: "/x", Deprecated: deprecated}}, ""},
+		{"same path, any method too", []deprecation.Notice{{Method: "GET", Path: "/x", Deprecated: deprecated}, {Path: "/x", Deprecated: deprecated}}, ""},
+
+		{"no path", []deprecation.Notice{{Method: "GET", Deprecated: deprecated}}, "needs a path"},
+		{"no date", []deprecation.Notice{{Path: "/x"}}, "needs a path and a deprecated date"},
+		{"sunset first", []deprecation.Notice{{Path: "/x", Deprecated: sunset, Sunset: deprecated}}, "sunset before"},
+		{"repeated", []deprecation.Notice{{Method: "GET", Path: "/x", Deprecated: deprecated}, {Method: "GET", Path: "/x", Deprecated: sunset}}, `"GET /x" is repeated`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := deprecation.NewTracker(tc.notices...)
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Errorf("got %v, want no error", err)
+			case tc.wantErr != "" && err == nil:
+				t.Errorf("got no error, want one containing %q", tc.wantErr)
+			case tc.wantErr != "" && !strings.Contains(err.Error(), tc.wantErr):
+				t.Errorf("got %v, want an error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// serve calls h with a request from remoteAddr, with meta for warnings, and returns the response.
+func serve(h http.Handler, method string, path string, remoteAddr string, header http.Header) (*httptest.ResponseRecorder, *common.ResponseMeta) {
+	meta := &common.ResponseMeta{}
+	r := httptest.NewRequest(method, path, nil)
+	r.RemoteAddr = remoteAddr
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	r = r.WithContext(common.WithResponseMeta(r.Context(), meta))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w, meta
+}
+
+func TestWrapAddsHeaders(t *testing.T) {
+	tracker, err := deprecation.NewTracker(
+		deprecation.Notice{Method: "GET", Path: "/v1/statuses", Deprecated: deprecated, Sunset: sunset, Link: "https://docs.example.com/v2"},
+		deprecation.Notice{Path: "/v1/rollups", Deprecated: deprecated},
+	)
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	h := tracker.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for _, tc := range []struct {
+		name        string
+		method      string
+		path        string
+		wantHeaders map[string]string
+		wantWarning string
+	}{
+		{
+			"with sunset and link", "GET", "/v1/statuses",
+			map[string]string{"Deprecation": "@1704067200", "Sunset": "Mon, 01 Jul 2024 00:00:00 GMT", "Link": `<https://docs.example.com/v2>; rel="deprecation"`},
+			"GET /v1/statuses is deprecated and stops working on 2024-07-01; see https://docs.example.com/v2",
+		},
+		{
+			"any method", "DELETE", "/v1/rollups",
+			map[string]string{"Deprecation": "@1704067200", "Sunset": "", "Link": ""},
+			"/v1/rollups is deprecated",
+		},
+		{"other method", "POST", "/v1/statuses", map[string]string{"Deprecation": "", "Sunset": "", "Link": ""}, ""},
+		{"other path", "GET", "/v2/statuses", map[string]string{"Deprecation": "", "Sunset": "", "Link": ""}, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w, meta := serve(h, tc.method, tc.path, "192.0.2.1:1234", nil)
+			if w.Code != http.StatusNoContent {
+				t.Errorf("got status %d, want %d", w.Code, http.StatusNoContent)
+			}
+			for name, want := range tc.wantHeaders {
+				if got := w.Header().Get(name); got != want {
+					t.Errorf("%s: got %q, want %q", name, got, want)
+				}
+			}
+			var wantWarnings []string
+			if tc.wantWarning != "" {
+				wantWarnings = []string{tc.wantWarning}
+			}
+			if got := meta.Warnings(); !reflect.DeepEqual(got, wantWarnings) {
+				t.Errorf("warnings: got %q, want %q", got, wantWarnings)
+			}
+		})
+	}
+}
+
+func TestWrapWithoutNotices(t *testing.T) {
+	next := http.NotFoundHandler()
+	var nilTracker *deprecation.Tracker
+	if got := nilTracker.Wrap(next); reflect.ValueOf(got).Pointer() != reflect.ValueOf(next).Pointer() {
+		t.Errorf("nil Tracker: Wrap didn't return next")
+	}
+	if got := nilTracker.Usage(); got == nil || len(got) != 0 {
+		t.Errorf("nil Tracker: got usage %v, want empty", got)
+	}
+
+	empty, _ := deprecation.NewTracker()
+	if got := empty.Wrap(next); reflect.ValueOf(got).Pointer() != reflect.ValueOf(next).Pointer() {
+		t.Errorf("no notices: Wrap didn't return next")
+	}
+}
+
+func TestUsageByCaller(t *testing.T) {
+	tracker, _ := deprecation.NewTracker(
+		deprecation.Notice{Method: "GET", Path: "/v1/statuses", Deprecated: deprecated, Sunset: sunset, DtoVersion: "v1"},
+		deprecation.Notice{Method: "POST", Path: "/v1/statuses", Deprecated: deprecated},
+		deprecation.Notice{Path: "/v1/rollups", Deprecated: deprecated},
+	)
+	proxies, err := common.ParseTrustedProxies("10.0.0.1")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+	// Wrap goes inside ResolveUser and ResolveClientIp, as the server does it
+	h := proxies.ResolveClientIp(proxies.ResolveUser(tracker.Wrap(http.NotFoundHandler())))
+
+	viaProxy := func(user string) http.Header {
+		return http.Header{common.UserHeader: {user}, "X-Forwarded-For": {"198.51.100.7"}}
+	}
+	for i := 0; i < 3; i++ {
+		serve(h, "GET", "/v1/statuses", "10.0.0.1:1234", viaProxy("alice"))
+	}
+	serve(h, "GET", "/v1/statuses", "10.0.0.1:1234", viaProxy("bob"))
+	// no user, so it's counted by the client address the proxy reports
+	serve(h, "GET", "/v1/statuses", "10.0.0.1:1234", http.Header{"X-Forwarded-For": {"198.51.100.7"}})
+	// user headers from an untrusted address are ignored
+	serve(h, "GET", "/v1/statuses", "192.0.2.1:1234", viaProxy("mallory"))
+	serve(h, "POST", "/v1/statuses", "192.0.2.1:1234", nil)
+
+	usage := tracker.Usage()
+	if len(usage) != 3 {
+		t.Fatalf("got %d routes, want 3: %+v", len(usage), usage)
+	}
+
+	var order []string
+	for _, ru := range usage {
+		order = append(order, strings.TrimSpace(ru.Method+" "+ru.Path))
+	}
+	if want := []string{"/v1/rollups", "GET /v1/statuses", "POST /v1/statuses"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("got routes %v, want %v", order, want)
+	}
+
+	if usage[0].Calls != 0 || len(usage[0].Callers) != 0 || usage[0].Callers == nil {
+		t.Errorf("uncalled route: got %+v, want no calls and an empty caller list", usage[0])
+	}
+
+	get := usage[1]
+	if get.Calls != 6 || get.DtoVersion != "v1" || get.Deprecated != "2024-01-01T00:00:00Z" || get.Sunset != "2024-07-01T00:00:00Z" {
+		t.Errorf("GET: got %+v, want 6 calls of DTO v1 with its dates", get)
+	}
+	var callers []string
+	for _, c := range get.Callers {
+		callers = append(callers, fmt.Sprintf("%s=%d", c.Caller, c.Calls))
+		if _, err := time.Parse(time.RFC3339, c.LastCall); err != nil {
+			t.Errorf("%s: LastCall %q isn't RFC 3339", c.Caller, c.LastCall)
+		}
+	}
+	if want := []string{"alice=3", "192.0.2.1=1", "198.51.100.7=1", "bob=1"}; !reflect.DeepEqual(callers, want) {
+		t.Errorf("GET callers: got %v, want %v", callers, want)
+	}
+
+	if post := usage[2]; post.Calls != 1 || post.Sunset != "" {
+		t.Errorf("POST: got %+v, want 1 call and no sunset", post)
+	}
+}
+
+func TestUsageBoundsCallers(t *testing.T) {
+	tracker, _ := deprecation.NewTracker(deprecation.Notice{Path: "/v1/statuses", Deprecated: deprecated})
+	h := tracker.Wrap(http.NotFoundHandler())
+
+	const callers = 1005
+	for i := 0; i < callers; i++ {
+		serve(h, "GET", "/v1/statuses", fmt.Sprintf("10.1.%d.%d:1234", i/256, i%256), nil)
+	}
+
+	ru := tracker.Usage()[0]
+	if ru.Calls != callers {
+		t.Errorf("got %d calls, want %d", ru.Calls, callers)
+	}
+	if len(ru.Callers) != 1001 || ru.Callers[0].Caller != "(other)" || ru.Callers[0].Calls != 5 {
+		t.Errorf("got %d callers, first %+v, want 1000 callers and (other) with 5 calls", len(ru.Callers), ru.Callers[0])
+	}
+}
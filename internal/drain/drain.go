@@ -0,0 +1,195 @@
+// Package drain takes an instance out of service before it stops, so rolling deploys don't drop
+// requests. Draining fails readiness, keeps serving while load balancers notice, waits for
+// requests and background work in flight to finish, and then stops the server.
+package drain
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/jmjf/go-jst/internal/config"
+)
+
+// AdminPath is the admin route that shows or starts a drain.
+const AdminPath = "/admin/drain"
+
+// pollInterval is how often a drain checks whether work in flight has finished.
+const pollInterval = 100 * time.Millisecond
+
+type Config struct {
+	// Delay is how long to keep serving after readiness fails, so load balancers and Kubernetes
+	// endpoints stop sending new requests first (default 5s). It should be longer than the
+	// readiness probe's period.
+	Delay time.Duration `env:"GOJST_DRAIN_DELAY" validate:"min=0s,max=5m"`
+	// Timeout is the longest to wait after Delay for work in flight (default 10s). Whatever is
+	// still running then is cut off by shutdown.
+	Timeout time.Duration `env:"GOJST_DRAIN_TIMEOUT" validate:"min=0s,max=10m"`
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.Delay <= 0 {
+		cfg.Delay = 5 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return cfg
+}
+
+// ConfigFromEnv reads drain settings. The defaults, with the default 10s GOJST_SHUTDOWN_TIMEOUT, fit in
+// Kubernetes' default 30s termination grace period.
+//
+//	GOJST_DRAIN_DELAY=5s      keep serving this long after readiness fails
+//	GOJST_DRAIN_TIMEOUT=10s   then wait up to this long for work in flight
+func ConfigFromEnv(getenv func(string) string) (Config, error) {
+	var cfg Config
+	var problems config.Problems
+	var err error
+	cfg.Delay, err = config.EnvDuration(getenv, "GOJST_DRAIN_DELAY", 0)
+	problems.Add(err)
+	cfg.Timeout, err = config.EnvDuration(getenv, "GOJST_DRAIN_TIMEOUT", 0)
+	problems.Add(err)
+
+	problems.Add(config.Validate(cfg))
+	if err := problems.Err(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// State is what a drain is doing. Pending counts work in flight by what WaitFor named it.
+type State struct {
+	Draining bool           `json:"Draining"`
+	Drained  bool           `json:"Drained"`
+	InFlight int64          `json:"InFlight"`
+	Pending  map[string]int `json:"Pending,omitempty"`
+	// StartedTimestamp is when the drain started; empty if it hasn't.
+	StartedTimestamp string `json:"StartedTimestamp,omitempty"`
+}
+
+// Drainer counts requests in flight, fails readiness once draining starts, and calls stop when
+// the drain is done. It's safe for concurrent use.
+type Drainer struct {
+	cfg  Config
+	stop func()
+
+	inFlight atomic.Int64
+	draining atomic.Bool
+
+	mu      sync.Mutex
+	pending map[string]func() int
+	started time.Time
+	drained bool
+
+	once sync.Once
+	done chan struct{}
+}
+
+// New returns a Drainer that calls stop, which should shut the server down, when a drain is done.
+func New(cfg Config, stop func()) *Drainer {
+	return &Drainer{cfg: cfg.withDefaults(), stop: stop, pending: map[string]func() int{}, done: make(chan struct{})}
+}
+
+// WaitFor makes drains also wait until pending returns 0, like queued background tasks.
+func (d *Drainer) WaitFor(name string, pending func() int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pending[name] = pending
+}
+
+// Track counts requests to next as in flight until they return. Requests to exempt paths aren't
+// counted, like probes and the drain request itself.
+func (d *Drainer) Track(next http.Handler, exemptPaths ...string) http.Handler {
+	exempt := map[string]bool{}
+	for _, p := range exemptPaths {
+		exempt[p] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if exempt[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		d.inFlight.Add(1)
+		defer d.inFlight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TrackGrpc is Track for unary gRPC calls. Every call is counted.
+func (d *Drainer) TrackGrpc(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	d.inFlight.Add(1)
+	defer d.inFlight.Add(-1)
+	return handler(ctx, req)
+}
+
+// Ready makes the Drainer a region.ReadyGate: it isn't ready once draining starts.
+func (d *Drainer) Ready() (bool, string) {
+	if d.draining.Load() {
+		return false, "draining"
+	}
+	return true, ""
+}
+
+// Drain fails readiness, waits Delay, then waits up to Timeout for requests and pending work to
+// finish, and calls stop. It returns when the drain is done. Calls after the first wait for the
+// first one's drain instead of starting another.
+func (d *Drainer) Drain() State {
+	d.once.Do(func() {
+		started := time.Now()
+		d.mu.Lock()
+		d.started = started
+		d.mu.Unlock()
+		d.draining.Store(true)
+		log.Printf("draining: not ready; serving %s more before waiting for work in flight", d.cfg.Delay)
+
+		time.Sleep(d.cfg.Delay)
+		deadline := time.Now().Add(d.cfg.Timeout)
+		for !d.idle() && time.Now().Before(deadline) {
+			time.Sleep(pollInterval)
+		}
+
+		state := d.State()
+		d.mu.Lock()
+		d.drained = true
+		d.mu.Unlock()
+		if state.InFlight > 0 || len(state.Pending) > 0 {
+			log.Printf("drain timed out after %s with %d requests and %v still in flight", d.cfg.Timeout, state.InFlight, state.Pending)
+		} else {
+			log.Printf("drained in %s", time.Since(started).Round(time.Millisecond))
+		}
+		d.stop()
+		close(d.done)
+	})
+	<-d.done
+	return d.State()
+}
+
+// State returns what the drain is doing. Pending lists only work that's still in flight.
+func (d *Drainer) State() State {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	state := State{Draining: d.draining.Load(), Drained: d.drained, InFlight: d.inFlight.Load()}
+	if !d.started.IsZero() {
+		state.StartedTimestamp = d.started.UTC().Format(time.RFC3339Nano)
+	}
+	for name, pending := range d.pending {
+		if n := pending(); n > 0 {
+			if state.Pending == nil {
+				state.Pending = map[string]int{}
+			}
+			state.Pending[name] = n
+		}
+	}
+	return state
+}
+
+func (d *Drainer) idle() bool {
+	state := d.State()
+	return state.InFlight == 0 && len(state.Pending) == 0
+}
@@ -0,0 +1,76 @@
+package drain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/testsupport"
+)
+
+func TestDrainWaitsForWorkInFlight(t *testing.T) {
+	defer testsupport.CheckGoroutines(t, testsupport.LeakOptions{})()
+	var stopped atomic.Bool
+	d := New(Config{Delay: time.Millisecond, Timeout: 5 * time.Second}, func() { stopped.Store(true) })
+	var queued atomic.Int64
+	queued.Store(1)
+	d.WaitFor("tasks", func() int { return int(queued.Load()) })
+
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	h := d.Track(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/readyz" {
+			return
+		}
+		close(entered)
+		<-release
+	}), "/readyz")
+	go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/job-statuses", nil))
+	<-entered
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	states := make(chan State)
+	go func() { states <- d.Drain() }()
+	time.Sleep(50 * time.Millisecond)
+	if ready, _ := d.Ready(); ready {
+		t.Error("ready while draining")
+	}
+	if state := d.State(); !state.Draining || state.Drained || state.InFlight != 1 || state.Pending["tasks"] != 1 {
+		t.Errorf("mid-drain state: got %+v, want one request and one task in flight", state)
+	}
+
+	close(release)
+	queued.Store(0)
+	state := <-states
+	if !state.Drained || state.InFlight != 0 || len(state.Pending) != 0 {
+		t.Errorf("final state: got %+v, want drained with nothing in flight", state)
+	}
+	if !stopped.Load() {
+		t.Error("stop wasn't called")
+	}
+}
+
+func TestDrainStopsAfterTimeout(t *testing.T) {
+	stops := 0
+	d := New(Config{Delay: time.Millisecond, Timeout: 10 * time.Millisecond}, func() { stops++ })
+	d.WaitFor("stuck", func() int { return 1 })
+
+	if state := d.Drain(); !state.Drained || state.Pending["stuck"] != 1 {
+		t.Errorf("got %+v, want drained with the stuck work still pending", state)
+	}
+	d.Drain()
+	if stops != 1 {
+		t.Errorf("got %d stops, want 1 for two drains", stops)
+	}
+}
+
+func TestConfigFromEnvRejectsBadDurations(t *testing.T) {
+	getenv := func(name string) string {
+		return map[string]string{"GOJST_DRAIN_DELAY": "soon", "GOJST_DRAIN_TIMEOUT": "1h"}[name]
+	}
+	if _, err := ConfigFromEnv(getenv); err == nil {
+		t.Error("got no error for a bad delay and a timeout over the maximum")
+	}
+}
@@ -0,0 +1,10 @@
+package drain
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jmjf/go-jst/internal/testsupport"
+)
+
+func TestMain(m *testing.M) { os.Exit(testsupport.VerifyTestMain(m)) }
@@ -0,0 +1,148 @@
+// Package events tells other instances about changes over Postgres LISTEN/NOTIFY, so replicas
+// share news without more infrastructure. Events are small and carry no data an instance
+// can't reload: they say what changed, and each subscriber reloads it.
+//
+// NOTIFY doesn't keep events for listeners that aren't connected. A Bus that reconnects calls
+// every subscriber with Missed set, so it reloads whatever it caches instead of trusting it.
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Channel is the NOTIFY channel every instance listens on.
+const Channel = "gojst_events"
+
+// Reconnect backoff bounds. The wait doubles after each failed connection.
+const (
+	minReconnectWait = time.Second
+	maxReconnectWait = 30 * time.Second
+)
+
+// Event says something changed. Kind names what, like "job-aliases", and Key optionally
+// narrows it. Missed is only set on events a Bus makes after reconnecting.
+type Event struct {
+	Kind   string `json:"Kind"`
+	Key    string `json:"Key,omitempty"`
+	Missed bool   `json:"-"`
+}
+
+// Bus publishes events and delivers them to subscribers. It's safe for concurrent use.
+type Bus struct {
+	connStr string
+	db      *sql.DB
+
+	mu       sync.Mutex
+	handlers map[string][]func(Event)
+}
+
+// New returns a Bus that publishes through db and listens on its own connection to connStr,
+// since a listening connection can't be shared with a pool.
+func New(connStr string, db *sql.DB) *Bus {
+	return &Bus{connStr: connStr, db: db, handlers: map[string][]func(Event){}}
+}
+
+// Publish sends ev to every listening instance, this one included. Postgres delivers it when
+// the current transaction commits; through a pool, that's right away.
+func (b *Bus) Publish(ctx context.Context, ev Event) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = b.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", Channel, string(payload))
+	return err
+}
+
+// Subscribe calls fn with each event of kind. Handlers run on the listening goroutine, so they
+// should be quick, like dropping a cache.
+func (b *Bus) Subscribe(kind string, fn func(Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[kind] = append(b.handlers[kind], fn)
+}
+
+// Run blocks, listening and delivering events until ctx is done. When the connection fails, it
+// reconnects with backoff and sends each kind's subscribers a Missed event.
+func (b *Bus) Run(ctx context.Context) {
+	wait := minReconnectWait
+	for first := true; ; first = false {
+		listened, err := b.listen(ctx, !first)
+		if ctx.Err() != nil {
+			return
+		}
+		if listened {
+			wait = minReconnectWait
+		}
+		log.Printf("events: listen failed, reconnecting in %s: %v", wait, err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if wait *= 2; wait > maxReconnectWait {
+			wait = maxReconnectWait
+		}
+	}
+}
+
+// listen connects, listens, and delivers events until the connection fails or ctx is done.
+// listened is true if it got as far as LISTEN. After a reconnect, subscribers get Missed events
+// once the new LISTEN is in place, so nothing published after it is lost.
+func (b *Bus) listen(ctx context.Context, reconnect bool) (listened bool, err error) {
+	conn, err := pgx.Connect(ctx, b.connStr)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return false, err
+	}
+	if reconnect {
+		log.Printf("events: reconnected; subscribers will reload")
+		b.deliverMissed()
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		var ev Event
+		if err := json.Unmarshal([]byte(n.Payload), &ev); err != nil {
+			log.Printf("events: ignoring bad payload %q: %v", n.Payload, err)
+			continue
+		}
+		b.deliver(ev)
+	}
+}
+
+func (b *Bus) deliver(ev Event) {
+	b.mu.Lock()
+	handlers := b.handlers[ev.Kind]
+	b.mu.Unlock()
+	for _, fn := range handlers {
+		fn(ev)
+	}
+}
+
+func (b *Bus) deliverMissed() {
+	b.mu.Lock()
+	kinds := make([]string, 0, len(b.handlers))
+	for kind := range b.handlers {
+		kinds = append(kinds, kind)
+	}
+	b.mu.Unlock()
+	for _, kind := range kinds {
+		b.deliver(Event{Kind: kind, Missed: true})
+	}
+}
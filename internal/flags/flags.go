@@ -0,0 +1,164 @@
+// Package flags turns behaviors on and off per application so big changes can be rolled out
+// gradually. Every flag is declared in defaults below with its default value.
+package flags
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Flag names.
+const (
+	// GenerateRunId lets clients leave RunId out of a POST and get a generated one.
+	GenerateRunId = "generate-run-id"
+)
+
+var defaults = map[string]bool{
+	GenerateRunId: true,
+}
+
+// Flags answers "is this flag on for this application?" An application override beats a
+// global override, which beats the default. A nil *Flags uses defaults only.
+type Flags struct {
+	mu        sync.RWMutex
+	global    map[string]bool
+	overrides map[string]map[string]bool // flag -> ApplicationId -> on
+}
+
+func New() *Flags {
+	return &Flags{global: map[string]bool{}, overrides: map[string]map[string]bool{}}
+}
+
+// FromEnv reads overrides from environment variables:
+//
+//	GOJST_FLAGS           global overrides, like "generate-run-id=false"
+//	GOJST_FLAG_OVERRIDES  per application overrides, like "generate-run-id:overdrafts=true"
+//
+// A flag with no "=value" is turned on.
+func FromEnv(getenv func(string) string) (*Flags, error) {
+	f := New()
+	for _, item := range splitList(getenv("GOJST_FLAGS")) {
+		flag, on, err := parseItem(item)
+		if err != nil {
+			return nil, fmt.Errorf("GOJST_FLAGS: %w", err)
+		}
+		if err := f.Set(flag, "", on); err != nil {
+			return nil, fmt.Errorf("GOJST_FLAGS: %w", err)
+		}
+	}
+	for _, item := range splitList(getenv("GOJST_FLAG_OVERRIDES")) {
+		flagApp, on, err := parseItem(item)
+		if err != nil {
+			return nil, fmt.Errorf("GOJST_FLAG_OVERRIDES: %w", err)
+		}
+		flag, appId, ok := strings.Cut(flagApp, ":")
+		if !ok || appId == "" {
+			return nil, fmt.Errorf("GOJST_FLAG_OVERRIDES item %q must be flag:appId=value", item)
+		}
+		if err := f.Set(flag, appId, on); err != nil {
+			return nil, fmt.Errorf("GOJST_FLAG_OVERRIDES: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// Enabled reports whether flag is on for applicationId. Unknown flags are off.
+func (f *Flags) Enabled(flag string, applicationId string) bool {
+	if f != nil {
+		f.mu.RLock()
+		defer f.mu.RUnlock()
+		if on, ok := f.overrides[flag][applicationId]; ok {
+			return on
+		}
+		if on, ok := f.global[flag]; ok {
+			return on
+		}
+	}
+	return defaults[flag]
+}
+
+// Set overrides flag for applicationId, or for everyone if applicationId is empty.
+func (f *Flags) Set(flag string, applicationId string, on bool) error {
+	if _, ok := defaults[flag]; !ok {
+		return fmt.Errorf("unknown flag %q", flag)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if applicationId == "" {
+		f.global[flag] = on
+		return nil
+	}
+	if f.overrides[flag] == nil {
+		f.overrides[flag] = map[string]bool{}
+	}
+	f.overrides[flag][applicationId] = on
+	return nil
+}
+
+// Clear removes the override Set made, so the flag falls back to the next level.
+func (f *Flags) Clear(flag string, applicationId string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if applicationId == "" {
+		delete(f.global, flag)
+		return
+	}
+	delete(f.overrides[flag], applicationId)
+}
+
+// State describes one flag for the admin API.
+type State struct {
+	Name      string          `json:"Name"`
+	Default   bool            `json:"Default"`
+	Global    *bool           `json:"Global,omitempty"`
+	Overrides map[string]bool `json:"Overrides,omitempty"`
+}
+
+// States returns every flag's default and overrides, sorted by name.
+func (f *Flags) States() []State {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	states := make([]State, 0, len(defaults))
+	for name, def := range defaults {
+		st := State{Name: name, Default: def}
+		if on, ok := f.global[name]; ok {
+			st.Global = &on
+		}
+		if len(f.overrides[name]) > 0 {
+			st.Overrides = make(map[string]bool, len(f.overrides[name]))
+			for appId, on := range f.overrides[name] {
+				st.Overrides[appId] = on
+			}
+		}
+		states = append(states, st)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+func parseItem(item string) (string, bool, error) {
+	name, value, hasValue := strings.Cut(item, "=")
+	if !hasValue {
+		return name, true, nil
+	}
+	on, err := strconv.ParseBool(value)
+	if err != nil {
+		return "", false, fmt.Errorf("item %q: value must be true or false", item)
+	}
+	return name, on, nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
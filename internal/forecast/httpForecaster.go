@@ -0,0 +1,106 @@
+// Package forecast has Forecasters that call forecasting models outside this service.
+package forecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// maxResponseBytes bounds how much of a model's response is read.
+const maxResponseBytes = 1 << 20
+
+// HttpForecaster POSTs a dto.ForecastRequestDto to a model service and reads a
+// dto.ForecastResponseDto back.
+type HttpForecaster struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+var _ jobStatus.Forecaster = (*HttpForecaster)(nil)
+
+// NewHttpForecaster sends requests to url with token as a bearer token, if it isn't empty.
+// Requests end when the caller's context does, so client needn't have a timeout.
+func NewHttpForecaster(client *http.Client, url string, token string) *HttpForecaster {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &HttpForecaster{client: client, url: url, token: token}
+}
+
+// Forecast returns an error for any response that isn't 2xx or doesn't pass
+// jobStatus.ForecastResponseToDomain. It doesn't retry.
+func (hf *HttpForecaster) Forecast(ctx context.Context, in jobStatus.ForecastInput) (jobStatus.Forecast, error) {
+	body, err := json.Marshal(jobStatus.ForecastInputToDto(in))
+	if err != nil {
+		return jobStatus.Forecast{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hf.url, bytes.NewReader(body))
+	if err != nil {
+		return jobStatus.Forecast{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if hf.token != "" {
+		req.Header.Set("Authorization", "Bearer "+hf.token)
+	}
+
+	res, err := hf.client.Do(req)
+	if err != nil {
+		return jobStatus.Forecast{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+		return jobStatus.Forecast{}, fmt.Errorf("forecasting model returned %s", res.Status)
+	}
+
+	var resDto dto.ForecastResponseDto
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxResponseBytes)).Decode(&resDto); err != nil {
+		return jobStatus.Forecast{}, fmt.Errorf("forecasting model response: %w", err)
+	}
+	f, err := jobStatus.ForecastResponseToDomain(resDto)
+	if err != nil {
+		return jobStatus.Forecast{}, fmt.Errorf("forecasting model response: %w", err)
+	}
+	if in.Deadline.IsZero() {
+		f.MissProbability = 0
+	}
+	return f, nil
+}
+
+// ForecastersFromEnv uses an HttpForecaster at GOJST_FORECAST_URL for the applications in
+// GOJST_FORECAST_APPS (comma separated, or * for all), with GOJST_FORECAST_TOKEN as its bearer
+// token. Other applications use the built-in forecaster. fs is nil, so every application uses the
+// built-in one, if GOJST_FORECAST_URL isn't set.
+func ForecastersFromEnv(getenv func(string) string) (fs *jobStatus.Forecasters, err error) {
+	url := getenv("GOJST_FORECAST_URL")
+	if url == "" {
+		return nil, nil
+	}
+	apps := getenv("GOJST_FORECAST_APPS")
+	if apps == "" {
+		return nil, fmt.Errorf("GOJST_FORECAST_APPS is required with GOJST_FORECAST_URL")
+	}
+
+	hf := NewHttpForecaster(nil, url, getenv("GOJST_FORECAST_TOKEN"))
+	if strings.TrimSpace(apps) == "*" {
+		return &jobStatus.Forecasters{Default: hf}, nil
+	}
+	fs = &jobStatus.Forecasters{ByApplication: map[string]jobStatus.Forecaster{}}
+	for _, app := range strings.Split(apps, ",") {
+		app = strings.TrimSpace(app)
+		if app == "" {
+			return nil, fmt.Errorf("GOJST_FORECAST_APPS has an empty application")
+		}
+		fs.ByApplication[app] = hf
+	}
+	return fs, nil
+}
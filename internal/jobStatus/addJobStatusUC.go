@@ -0,0 +1,167 @@
+package jobStatus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/flags"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+type AddJobStatusUC struct {
+	repo    Repo
+	quota   *QuotaUC
+	meter   *MeteringUC
+	flags   *flags.Flags
+	aliases *JobAliasUC
+	hasher  IntegrityHasher
+}
+
+// NewAddJobStatusUC returns the use case. It uses svc.Quota, svc.Meter, svc.Flags, svc.Aliases,
+// and svc.Integrity.
+func NewAddJobStatusUC(repo Repo, svc Services) *AddJobStatusUC {
+	return &AddJobStatusUC{repo: repo, quota: svc.Quota, meter: svc.Meter, flags: svc.Flags, aliases: svc.Aliases, hasher: svc.Integrity}
+}
+
+// MaxBatchStatuses bounds one batch, so a single transaction doesn't hold locks for long.
+// Loaders with more statuses send several batches.
+const MaxBatchStatuses = 5000
+
+// Add validates the DTO, stores it, and returns the stored job status and whether it was added.
+// Adding a status that's already stored returns the stored one, so a client can retry an add
+// whose response it didn't get. The status has to be the same report (see SameReport) with the
+// same RunId; a different status with the same key is a CommonError coded ErrcdRepoDupeRow.
+func (uc *AddJobStatusUC) Add(ctx context.Context, jsDto dto.JobStatusDto) (dto.JobStatusDto, bool, error) {
+	// rejected calls still cost something, so every call with an application is metered
+	if uc.meter != nil {
+		uc.meter.CountCall(jsDto.ApplicationId, MeterAddJobStatus)
+	}
+
+	js, err := uc.prepare(ctx, jsDto)
+	if err != nil {
+		return dto.JobStatusDto{}, false, err
+	}
+
+	if uc.quota != nil {
+		if err := uc.quota.Reserve(ctx, js.ApplicationId, js.BusinessDate); err != nil {
+			return dto.JobStatusDto{}, false, err
+		}
+	}
+
+	stored, added, err := uc.repo.AddIdempotent(ctx, js)
+	if err != nil {
+		uc.release([]JobStatus{js})
+		return dto.JobStatusDto{}, false, err
+	}
+	if !added {
+		uc.release([]JobStatus{js})
+		if !stored.SameReport(js) {
+			return dto.JobStatusDto{}, false, common.NewCommonError(common.ErrcdRepoDupeRow,
+				fmt.Errorf("a different %s status for job %s on %s run %s is already stored", js.JobStatusCode, js.JobId, js.BusinessDate.Format("2006-01-02"), js.RunId))
+		}
+	}
+
+	return domainToDto(stored), added, nil
+}
+
+// AddBatch validates every DTO, then stores them all in one transaction and returns the stored
+// statuses in the same order. If any status is invalid, over quota, or can't be stored, nothing
+// is stored, and the error says which status (counting from 0). Invalid fields are reported for
+// every status, up to maxBatchFieldErrors.
+func (uc *AddJobStatusUC) AddBatch(ctx context.Context, jsDtos []dto.JobStatusDto) ([]dto.JobStatusDto, error) {
+	if uc.meter != nil {
+		metered := map[string]bool{}
+		for _, jsDto := range jsDtos {
+			if !metered[jsDto.ApplicationId] {
+				metered[jsDto.ApplicationId] = true
+				uc.meter.CountCall(jsDto.ApplicationId, MeterAddJobStatusBatch)
+			}
+		}
+	}
+
+	switch {
+	case len(jsDtos) == 0:
+		return nil, propsError("batch has no statuses")
+	case len(jsDtos) > MaxBatchStatuses:
+		return nil, propsError(fmt.Sprintf("batch has %d statuses; the limit is %d", len(jsDtos), MaxBatchStatuses))
+	}
+
+	// every status's field problems are reported at once, named like "[3].JobSt"
+	jss := make([]JobStatus, len(jsDtos))
+	var problems common.FieldErrors
+	for i, jsDto := range jsDtos {
+		js, err := uc.prepare(ctx, jsDto)
+		fieldErrs := common.FieldErrorsOf(err)
+		switch {
+		case err != nil && fieldErrs == nil:
+			return nil, fmt.Errorf("status %d: %w", i, err)
+		case len(problems) >= maxBatchFieldErrors:
+		case err != nil:
+			for _, fe := range fieldErrs {
+				problems = append(problems, common.FieldError{Field: fmt.Sprintf("[%d].%s", i, fe.Field), Message: fe.Message})
+			}
+		}
+		jss[i] = js
+	}
+	if len(problems) > 0 {
+		if len(problems) > maxBatchFieldErrors {
+			problems = problems[:maxBatchFieldErrors]
+		}
+		return nil, common.NewCommonError(common.ErrcdDomainProps, problems)
+	}
+
+	if uc.quota != nil {
+		for i, js := range jss {
+			if err := uc.quota.Reserve(ctx, js.ApplicationId, js.BusinessDate); err != nil {
+				uc.release(jss[:i])
+				return nil, fmt.Errorf("status %d: %w", i, err)
+			}
+		}
+	}
+
+	if err := uc.repo.AddBatch(ctx, jss); err != nil {
+		uc.release(jss)
+		return nil, err
+	}
+
+	result := make([]dto.JobStatusDto, len(jss))
+	for i, js := range jss {
+		result[i] = domainToDto(js)
+	}
+	return result, nil
+}
+
+// prepare turns a DTO into the status to store.
+func (uc *AddJobStatusUC) prepare(ctx context.Context, jsDto dto.JobStatusDto) (JobStatus, error) {
+	js, err := dtoToDomain(jsDto, uc.flags.Enabled(flags.GenerateRunId, jsDto.ApplicationId))
+	if err != nil {
+		return JobStatus{}, err
+	}
+
+	// statuses sent under a legacy JobId are stored under the canonical one
+	if uc.aliases != nil {
+		canonical, err := uc.aliases.Resolve(ctx, js.JobId)
+		if err != nil {
+			return JobStatus{}, err
+		}
+		if canonical != js.JobId {
+			js.ReportedJobId, js.JobId = js.JobId, canonical
+		}
+	}
+
+	if uc.hasher != nil {
+		js.IntegrityHash = uc.hasher.HashStatus(js)
+	}
+	return js, nil
+}
+
+// release gives back the quota reserved for statuses that weren't stored.
+func (uc *AddJobStatusUC) release(jss []JobStatus) {
+	if uc.quota == nil {
+		return
+	}
+	for _, js := range jss {
+		uc.quota.Release(js.ApplicationId, js.BusinessDate)
+	}
+}
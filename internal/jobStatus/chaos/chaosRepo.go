@@ -0,0 +1,445 @@
+// Package chaos wraps repos with injected latency and errors so retry and failure
+// handling can be exercised in tests and in a staging "chaos mode."
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/config"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// ErrInjectedFault is wrapped in every CommonError the chaos repo returns.
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultConfig says how often and how a ChaosRepo misbehaves.
+type FaultConfig struct {
+	// ErrorRate is the probability (0 to 1) that a call fails instead of reaching the wrapped repo.
+	ErrorRate float64 `env:"GOJST_CHAOS_ERROR_RATE" validate:"min=0,max=1"`
+	// ErrorCodes are CommonError codes to choose from at random for injected errors.
+	// Empty means common.ErrcdRepoConnection.
+	ErrorCodes []string `env:"GOJST_CHAOS_ERROR_CODES"`
+	// Latency is added before every affected call, plus a random amount up to LatencyJitter.
+	Latency       time.Duration `env:"GOJST_CHAOS_LATENCY" validate:"min=0s"`
+	LatencyJitter time.Duration `env:"GOJST_CHAOS_LATENCY_JITTER" validate:"min=0s"`
+	// Methods limits faults to the named repo methods (like "Add"). Empty means all methods.
+	Methods []string `env:"GOJST_CHAOS_METHODS"`
+}
+
+type ChaosRepo struct {
+	repo    jobStatus.FullRepo
+	cfg     FaultConfig
+	methods map[string]bool
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+var _ jobStatus.FullRepo = (*ChaosRepo)(nil)
+
+// NewChaosRepo wraps repo. Use a fixed seed in tests so the same calls fail every run.
+func NewChaosRepo(repo jobStatus.FullRepo, cfg FaultConfig, seed int64) *ChaosRepo {
+	if len(cfg.ErrorCodes) == 0 {
+		cfg.ErrorCodes = []string{common.ErrcdRepoConnection}
+	}
+
+	methods := map[string]bool{}
+	for _, m := range cfg.Methods {
+		methods[m] = true
+	}
+
+	return &ChaosRepo{repo: repo, cfg: cfg, methods: methods, rand: rand.New(rand.NewSource(seed))}
+}
+
+// inject waits out the configured latency, unless ctx is done first, and decides whether method fails.
+func (cr *ChaosRepo) inject(ctx context.Context, method string) error {
+	if len(cr.methods) > 0 && !cr.methods[method] {
+		return nil
+	}
+
+	cr.mu.Lock()
+	delay := cr.cfg.Latency
+	if cr.cfg.LatencyJitter > 0 {
+		delay += time.Duration(cr.rand.Int63n(int64(cr.cfg.LatencyJitter)))
+	}
+	fail := cr.rand.Float64() < cr.cfg.ErrorRate
+	code := cr.cfg.ErrorCodes[cr.rand.Intn(len(cr.cfg.ErrorCodes))]
+	cr.mu.Unlock()
+
+	if delay > 0 {
+		// a caller that gives up doesn't wait out the latency, as it wouldn't wait for a slow database
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return common.NewCommonError(common.ErrcdRepoOther, ctx.Err())
+		case <-timer.C:
+		}
+	}
+	if fail {
+		return common.NewCommonError(code, fmt.Errorf("%s: %w", method, ErrInjectedFault))
+	}
+	return nil
+}
+
+func (cr *ChaosRepo) Add(ctx context.Context, js jobStatus.JobStatus) error {
+	if err := cr.inject(ctx, "Add"); err != nil {
+		return err
+	}
+	return cr.repo.Add(ctx, js)
+}
+
+func (cr *ChaosRepo) AddIdempotent(ctx context.Context, js jobStatus.JobStatus) (jobStatus.JobStatus, bool, error) {
+	if err := cr.inject(ctx, "AddIdempotent"); err != nil {
+		return jobStatus.JobStatus{}, false, err
+	}
+	return cr.repo.AddIdempotent(ctx, js)
+}
+
+func (cr *ChaosRepo) AddBatch(ctx context.Context, jss []jobStatus.JobStatus) error {
+	if err := cr.inject(ctx, "AddBatch"); err != nil {
+		return err
+	}
+	return cr.repo.AddBatch(ctx, jss)
+}
+
+func (cr *ChaosRepo) GetByJobId(ctx context.Context, jobId jobStatus.JobIdType, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	if err := cr.inject(ctx, "GetByJobId"); err != nil {
+		return nil, err
+	}
+	return cr.repo.GetByJobId(ctx, jobId, opts)
+}
+
+func (cr *ChaosRepo) GetByJobIdBusinessDate(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	if err := cr.inject(ctx, "GetByJobIdBusinessDate"); err != nil {
+		return nil, err
+	}
+	return cr.repo.GetByJobIdBusinessDate(ctx, jobId, businessDate, opts)
+}
+
+func (cr *ChaosRepo) ForEachByJobId(ctx context.Context, jobId jobStatus.JobIdType, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	if err := cr.inject(ctx, "ForEachByJobId"); err != nil {
+		return err
+	}
+	return cr.repo.ForEachByJobId(ctx, jobId, opts, fn)
+}
+
+func (cr *ChaosRepo) ForEachByJobIdBusinessDate(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	if err := cr.inject(ctx, "ForEachByJobIdBusinessDate"); err != nil {
+		return err
+	}
+	return cr.repo.ForEachByJobIdBusinessDate(ctx, jobId, businessDate, opts, fn)
+}
+
+func (cr *ChaosRepo) GetByFilters(ctx context.Context, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	if err := cr.inject(ctx, "GetByFilters"); err != nil {
+		return nil, err
+	}
+	return cr.repo.GetByFilters(ctx, opts)
+}
+
+func (cr *ChaosRepo) ForEachByFilters(ctx context.Context, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	if err := cr.inject(ctx, "ForEachByFilters"); err != nil {
+		return err
+	}
+	return cr.repo.ForEachByFilters(ctx, opts, fn)
+}
+
+func (cr *ChaosRepo) RollupDaily(ctx context.Context, fromDate time.Time, toDate time.Time) (int64, error) {
+	if err := cr.inject(ctx, "RollupDaily"); err != nil {
+		return 0, err
+	}
+	return cr.repo.RollupDaily(ctx, fromDate, toDate)
+}
+
+func (cr *ChaosRepo) GetDailyRollups(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time) ([]jobStatus.DailyRollup, error) {
+	if err := cr.inject(ctx, "GetDailyRollups"); err != nil {
+		return nil, err
+	}
+	return cr.repo.GetDailyRollups(ctx, applicationId, fromDate, toDate)
+}
+
+func (cr *ChaosRepo) GetJobReliability(ctx context.Context, applicationId string, jobId jobStatus.JobIdType, fromDate time.Time, toDate time.Time) ([]jobStatus.JobReliability, error) {
+	if err := cr.inject(ctx, "GetJobReliability"); err != nil {
+		return nil, err
+	}
+	return cr.repo.GetJobReliability(ctx, applicationId, jobId, fromDate, toDate)
+}
+
+func (cr *ChaosRepo) GetDurationBaselines(ctx context.Context, applicationId string, jobId jobStatus.JobIdType, fromDate time.Time, toDate time.Time) ([]jobStatus.DurationBaseline, error) {
+	if err := cr.inject(ctx, "GetDurationBaselines"); err != nil {
+		return nil, err
+	}
+	return cr.repo.GetDurationBaselines(ctx, applicationId, jobId, fromDate, toDate)
+}
+
+func (cr *ChaosRepo) PutRunCost(ctx context.Context, rc jobStatus.RunCost) error {
+	if err := cr.inject(ctx, "PutRunCost"); err != nil {
+		return err
+	}
+	return cr.repo.PutRunCost(ctx, rc)
+}
+
+func (cr *ChaosRepo) GetJobCosts(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time) ([]jobStatus.JobCost, error) {
+	if err := cr.inject(ctx, "GetJobCosts"); err != nil {
+		return nil, err
+	}
+	return cr.repo.GetJobCosts(ctx, applicationId, fromDate, toDate)
+}
+
+func (cr *ChaosRepo) AddRunComment(ctx context.Context, rc jobStatus.RunComment) error {
+	if err := cr.inject(ctx, "AddRunComment"); err != nil {
+		return err
+	}
+	return cr.repo.AddRunComment(ctx, rc)
+}
+
+func (cr *ChaosRepo) ListRunComments(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, runId jobStatus.RunIdType) ([]jobStatus.RunComment, error) {
+	if err := cr.inject(ctx, "ListRunComments"); err != nil {
+		return nil, err
+	}
+	return cr.repo.ListRunComments(ctx, jobId, businessDate, runId)
+}
+
+func (cr *ChaosRepo) CountByApplicationBusinessDate(ctx context.Context, applicationId string, businessDate time.Time) (int64, error) {
+	if err := cr.inject(ctx, "CountByApplicationBusinessDate"); err != nil {
+		return 0, err
+	}
+	return cr.repo.CountByApplicationBusinessDate(ctx, applicationId, businessDate)
+}
+
+func (cr *ChaosRepo) AddApiCalls(ctx context.Context, counts []jobStatus.ApiCallCount) error {
+	if err := cr.inject(ctx, "AddApiCalls"); err != nil {
+		return err
+	}
+	return cr.repo.AddApiCalls(ctx, counts)
+}
+
+func (cr *ChaosRepo) GetApiCalls(ctx context.Context, month time.Time) ([]jobStatus.ApiCallCount, error) {
+	if err := cr.inject(ctx, "GetApiCalls"); err != nil {
+		return nil, err
+	}
+	return cr.repo.GetApiCalls(ctx, month)
+}
+
+func (cr *ChaosRepo) PutSavedView(ctx context.Context, view jobStatus.SavedView) error {
+	if err := cr.inject(ctx, "PutSavedView"); err != nil {
+		return err
+	}
+	return cr.repo.PutSavedView(ctx, view)
+}
+
+func (cr *ChaosRepo) GetSavedView(ctx context.Context, name string) (jobStatus.SavedView, bool, error) {
+	if err := cr.inject(ctx, "GetSavedView"); err != nil {
+		return jobStatus.SavedView{}, false, err
+	}
+	return cr.repo.GetSavedView(ctx, name)
+}
+
+func (cr *ChaosRepo) ListSavedViews(ctx context.Context) ([]jobStatus.SavedView, error) {
+	if err := cr.inject(ctx, "ListSavedViews"); err != nil {
+		return nil, err
+	}
+	return cr.repo.ListSavedViews(ctx)
+}
+
+func (cr *ChaosRepo) DeleteSavedView(ctx context.Context, name string) (bool, error) {
+	if err := cr.inject(ctx, "DeleteSavedView"); err != nil {
+		return false, err
+	}
+	return cr.repo.DeleteSavedView(ctx, name)
+}
+
+func (cr *ChaosRepo) PutScheduledQuery(ctx context.Context, sq jobStatus.ScheduledQuery) error {
+	if err := cr.inject(ctx, "PutScheduledQuery"); err != nil {
+		return err
+	}
+	return cr.repo.PutScheduledQuery(ctx, sq)
+}
+
+func (cr *ChaosRepo) GetScheduledQuery(ctx context.Context, name string) (jobStatus.ScheduledQuery, bool, error) {
+	if err := cr.inject(ctx, "GetScheduledQuery"); err != nil {
+		return jobStatus.ScheduledQuery{}, false, err
+	}
+	return cr.repo.GetScheduledQuery(ctx, name)
+}
+
+func (cr *ChaosRepo) ListScheduledQueries(ctx context.Context) ([]jobStatus.ScheduledQuery, error) {
+	if err := cr.inject(ctx, "ListScheduledQueries"); err != nil {
+		return nil, err
+	}
+	return cr.repo.ListScheduledQueries(ctx)
+}
+
+func (cr *ChaosRepo) DeleteScheduledQuery(ctx context.Context, name string) (bool, error) {
+	if err := cr.inject(ctx, "DeleteScheduledQuery"); err != nil {
+		return false, err
+	}
+	return cr.repo.DeleteScheduledQuery(ctx, name)
+}
+
+func (cr *ChaosRepo) ClaimScheduledRun(ctx context.Context, name string, runDate time.Time) (bool, error) {
+	if err := cr.inject(ctx, "ClaimScheduledRun"); err != nil {
+		return false, err
+	}
+	return cr.repo.ClaimScheduledRun(ctx, name, runDate)
+}
+
+func (cr *ChaosRepo) ReleaseScheduledRun(ctx context.Context, name string, runDate time.Time, previous time.Time) (bool, error) {
+	if err := cr.inject(ctx, "ReleaseScheduledRun"); err != nil {
+		return false, err
+	}
+	return cr.repo.ReleaseScheduledRun(ctx, name, runDate, previous)
+}
+
+func (cr *ChaosRepo) GetLatestByJobId(ctx context.Context, jobId jobStatus.JobIdType, fromDate time.Time, toDate time.Time, asOf time.Time) ([]jobStatus.JobStatus, error) {
+	if err := cr.inject(ctx, "GetLatestByJobId"); err != nil {
+		return nil, err
+	}
+	return cr.repo.GetLatestByJobId(ctx, jobId, fromDate, toDate, asOf)
+}
+
+func (cr *ChaosRepo) GetLatestByJobIds(ctx context.Context, jobIds []jobStatus.JobIdType, businessDate time.Time, asOf time.Time) ([]jobStatus.JobStatus, error) {
+	if err := cr.inject(ctx, "GetLatestByJobIds"); err != nil {
+		return nil, err
+	}
+	return cr.repo.GetLatestByJobIds(ctx, jobIds, businessDate, asOf)
+}
+
+func (cr *ChaosRepo) RenameJob(ctx context.Context, from jobStatus.JobIdType, to jobStatus.JobIdType, at time.Time) (jobStatus.JobRenameResult, error) {
+	if err := cr.inject(ctx, "RenameJob"); err != nil {
+		return jobStatus.JobRenameResult{}, err
+	}
+	return cr.repo.RenameJob(ctx, from, to, at)
+}
+
+func (cr *ChaosRepo) Update(ctx context.Context, c jobStatus.StatusCorrection, correct func(before jobStatus.JobStatus) (jobStatus.JobStatus, error)) (jobStatus.StatusCorrection, error) {
+	if err := cr.inject(ctx, "Update"); err != nil {
+		return c, err
+	}
+	return cr.repo.Update(ctx, c, correct)
+}
+
+func (cr *ChaosRepo) DeleteByKey(ctx context.Context, c jobStatus.StatusCorrection, key jobStatus.StatusKey) (jobStatus.StatusCorrection, error) {
+	if err := cr.inject(ctx, "DeleteByKey"); err != nil {
+		return c, err
+	}
+	return cr.repo.DeleteByKey(ctx, c, key)
+}
+
+func (cr *ChaosRepo) ListStatusCorrections(ctx context.Context, statusId jobStatus.StatusIdType) ([]jobStatus.StatusCorrection, error) {
+	if err := cr.inject(ctx, "ListStatusCorrections"); err != nil {
+		return nil, err
+	}
+	return cr.repo.ListStatusCorrections(ctx, statusId)
+}
+
+func (cr *ChaosRepo) RecordHeartbeat(ctx context.Context, key jobStatus.StatusKey, at time.Time) (jobStatus.JobStatus, error) {
+	if err := cr.inject(ctx, "RecordHeartbeat"); err != nil {
+		return jobStatus.JobStatus{}, err
+	}
+	return cr.repo.RecordHeartbeat(ctx, key, at)
+}
+
+func (cr *ChaosRepo) PutJobAlias(ctx context.Context, alias jobStatus.JobAlias) error {
+	if err := cr.inject(ctx, "PutJobAlias"); err != nil {
+		return err
+	}
+	return cr.repo.PutJobAlias(ctx, alias)
+}
+
+func (cr *ChaosRepo) ListJobAliases(ctx context.Context) ([]jobStatus.JobAlias, error) {
+	if err := cr.inject(ctx, "ListJobAliases"); err != nil {
+		return nil, err
+	}
+	return cr.repo.ListJobAliases(ctx)
+}
+
+func (cr *ChaosRepo) DeleteJobAlias(ctx context.Context, aliasJobId jobStatus.JobIdType) (bool, error) {
+	if err := cr.inject(ctx, "DeleteJobAlias"); err != nil {
+		return false, err
+	}
+	return cr.repo.DeleteJobAlias(ctx, aliasJobId)
+}
+
+func (cr *ChaosRepo) QueryReadOnly(ctx context.Context, query string, maxRows int, timeout time.Duration) (jobStatus.SqlResult, error) {
+	if err := cr.inject(ctx, "QueryReadOnly"); err != nil {
+		return jobStatus.SqlResult{}, err
+	}
+	return cr.repo.QueryReadOnly(ctx, query, maxRows, timeout)
+}
+
+func (cr *ChaosRepo) ForEachWithIntegrityHash(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time, fn func(jobStatus.JobStatus) error) error {
+	if err := cr.inject(ctx, "ForEachWithIntegrityHash"); err != nil {
+		return err
+	}
+	return cr.repo.ForEachWithIntegrityHash(ctx, applicationId, fromDate, toDate, fn)
+}
+
+// Snapshot injects once, before the snapshot starts; reads inside it aren't faulted.
+func (cr *ChaosRepo) Snapshot(ctx context.Context, fn func(jobStatus.SnapshotReader) error) error {
+	if err := cr.inject(ctx, "Snapshot"); err != nil {
+		return err
+	}
+	return cr.repo.Snapshot(ctx, fn)
+}
+
+func (cr *ChaosRepo) DeleteBefore(ctx context.Context, cutoff time.Time, cutoffs map[string]time.Time, limit int) (int64, error) {
+	if err := cr.inject(ctx, "DeleteBefore"); err != nil {
+		return 0, err
+	}
+	return cr.repo.DeleteBefore(ctx, cutoff, cutoffs, limit)
+}
+
+// FaultConfigFromEnv reads chaos mode settings. ok is false if GOJST_CHAOS_ERROR_RATE and
+// GOJST_CHAOS_LATENCY are both unset, meaning chaos mode is off.
+//
+//	GOJST_CHAOS_ERROR_RATE=0.05                 probability a call fails
+//	GOJST_CHAOS_ERROR_CODES=ConnectionExceptionError,OtherRepoError
+//	GOJST_CHAOS_LATENCY=200ms                   added to every call
+//	GOJST_CHAOS_LATENCY_JITTER=300ms            plus up to this much more
+//	GOJST_CHAOS_METHODS=Add,GetByJobId          only these methods
+func FaultConfigFromEnv(getenv func(string) string) (cfg FaultConfig, ok bool, err error) {
+	rate := getenv("GOJST_CHAOS_ERROR_RATE")
+	latency := getenv("GOJST_CHAOS_LATENCY")
+	if rate == "" && latency == "" {
+		return FaultConfig{}, false, nil
+	}
+
+	var problems config.Problems
+	if rate != "" {
+		if cfg.ErrorRate, err = strconv.ParseFloat(rate, 64); err != nil {
+			problems.Add(fmt.Errorf("GOJST_CHAOS_ERROR_RATE %q must be a number from 0 to 1", rate))
+		}
+	}
+	cfg.Latency, err = config.EnvDuration(getenv, "GOJST_CHAOS_LATENCY", 0)
+	problems.Add(err)
+	cfg.LatencyJitter, err = config.EnvDuration(getenv, "GOJST_CHAOS_LATENCY_JITTER", 0)
+	problems.Add(err)
+	cfg.ErrorCodes = splitList(getenv("GOJST_CHAOS_ERROR_CODES"))
+	cfg.Methods = splitList(getenv("GOJST_CHAOS_METHODS"))
+
+	problems.Add(config.Validate(cfg))
+	if err := problems.Err(); err != nil {
+		return FaultConfig{}, false, err
+	}
+	return cfg, true, nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,971 @@
+package jobStatus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/chaos"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbmemory"
+	"github.com/jmjf/go-jst/internal/testsupport"
+	"github.com/jmjf/go-jst/public/jobStatus/client"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// The contract tests check that the Go client in public/jobStatus/client and the HTTP API agree.
+// Each check runs the client against an in-process server backed by dbmemory.RepoMemory. Checks
+// are grouped by DTO version so old suites keep running until that version is retired.
+
+// contractEnv is what a check gets: a client pointed at a fresh server and the repo behind it.
+type contractEnv struct {
+	Client *client.Client
+	Repo   *dbmemory.RepoMemory
+	serve  func(repo jobStatus.FullRepo)
+}
+
+// Fail makes the repo's method fail with a CommonError coded code for the rest of the check, by
+// serving from a chaos repo that always faults that method.
+func (env contractEnv) Fail(method string, code string) {
+	env.serve(chaos.NewChaosRepo(env.Repo, chaos.FaultConfig{ErrorRate: 1, ErrorCodes: []string{code}, Methods: []string{method}}, 1))
+}
+
+// Stored is how many statuses the repo has.
+func (env contractEnv) Stored() (int, error) {
+	jss, err := env.Repo.GetByFilters(context.Background(), jobStatus.QueryOptions{})
+	return len(jss), err
+}
+
+type contractCheck struct {
+	Name string
+	Run  func(env contractEnv) error
+}
+
+// contractSuites maps DTO versions to their checks.
+var contractSuites = map[string][]contractCheck{
+	dto.Version: checks20230701,
+}
+
+func TestContract(t *testing.T) {
+	for version, checks := range contractSuites {
+		for _, check := range checks {
+			check := check
+			t.Run(version+"/"+check.Name, func(t *testing.T) {
+				defer testsupport.CheckGoroutines(t, testsupport.LeakOptions{})()
+				if err := runCheck(check); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+// runCheck runs check against its own server.
+func runCheck(check contractCheck) error {
+	repo := dbmemory.NewRepoMemory()
+	var mu sync.Mutex
+	var handler http.Handler
+	serve := func(repo jobStatus.FullRepo) {
+		mux := http.NewServeMux()
+		jobStatus.AddRoutes(mux, jobStatus.FullPorts(repo), jobStatus.Services{
+			AdminRoute: func(h common.MethodHandler) http.Handler { return h },
+		})
+		mu.Lock()
+		defer mu.Unlock()
+		// request ids are assigned in front of the routes, as in cmd/api
+		handler = common.AssignRequestId(mux)
+	}
+	serve(repo)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		h := handler
+		mu.Unlock()
+		h.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	return check.Run(contractEnv{Client: client.New(server.URL, server.Client()), Repo: repo, serve: serve})
+}
+
+func expectStatus(err error, status int) error {
+	var apiErr *client.ApiError
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("expected ApiError with status %d, got %v", status, err)
+	}
+	if apiErr.StatusCode != status {
+		return fmt.Errorf("expected status %d, got %d (%s)", status, apiErr.StatusCode, apiErr.Message)
+	}
+	return nil
+}
+
+// expectErrorDto checks that err came from a dto.ErrorDto with status and code.
+func expectErrorDto(err error, status int, code string) (*client.ApiError, error) {
+	if err := expectStatus(err, status); err != nil {
+		return nil, err
+	}
+	var apiErr *client.ApiError
+	errors.As(err, &apiErr)
+	if apiErr.Code != code {
+		return nil, fmt.Errorf("expected code %q, got %q (%s)", code, apiErr.Code, apiErr.Message)
+	}
+	if apiErr.RequestId == "" {
+		return nil, errors.New("expected a RequestId")
+	}
+	return apiErr, nil
+}
+
+func expectEqual(what string, got any, want any) error {
+	if !reflect.DeepEqual(got, want) {
+		return fmt.Errorf("%s: got %+v, want %+v", what, got, want)
+	}
+	return nil
+}
+
+var sampleDto = dto.JobStatusDto{
+	ApplicationId:      "overdrafts",
+	JobId:              "od-calc",
+	JobStatusCode:      "SUCCEED",
+	JobStatusTimestamp: "2023-06-16T00:18:33.324Z",
+	BusinessDate:       "2023-06-15",
+	RunId:              "1",
+	HostId:             "batch01",
+}
+
+var checks20230701 = []contractCheck{
+	{"AddJobStatus round trips all fields", func(env contractEnv) error {
+		got, err := env.Client.AddJobStatus(sampleDto)
+		if err != nil {
+			return err
+		}
+		if len(got.StatusId) != 36 {
+			return fmt.Errorf("expected a UUID StatusId, got %q", got.StatusId)
+		}
+		if _, err := time.Parse(time.RFC3339Nano, got.ReceivedTimestamp); err != nil {
+			return fmt.Errorf("expected a ReceivedTimestamp, got %q", got.ReceivedTimestamp)
+		}
+		got.StatusId, got.ReceivedTimestamp = "", ""
+		return expectEqual("added", got, sampleDto)
+	}},
+	{"AddJobStatus links come back in queries", func(env contractEnv) error {
+		linked := sampleDto
+		linked.Links = []dto.LinkDto{{Kind: "log", Url: "https://logs.example.com/od-calc/1"}, {Kind: "ticket", Url: "https://tickets.example.com/OPS-42"}}
+		if _, err := env.Client.AddJobStatus(linked); err != nil {
+			return err
+		}
+		got, err := env.Client.GetByJobId(sampleDto.JobId, client.QueryOptions{Fields: []string{"Links"}})
+		if err != nil {
+			return err
+		}
+		return expectEqual("links", got, []dto.JobStatusDto{{Links: linked.Links}})
+	}},
+	{"AddJobStatus with a link that isn't http is 400", func(env contractEnv) error {
+		bad := sampleDto
+		bad.Links = []dto.LinkDto{{Kind: "log", Url: "file:///var/log/od-calc.log"}}
+		_, err := env.Client.AddJobStatus(bad)
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+	{"AddJobStatus with bad props is 400", func(env contractEnv) error {
+		bad := sampleDto
+		bad.JobStatusCode = "DONE"
+		_, err := env.Client.AddJobStatus(bad)
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+	{"AddJobStatus errors come back as an ErrorDto", func(env contractEnv) error {
+		bad := sampleDto
+		bad.JobStatusCode = "DONE"
+		_, err := env.Client.AddJobStatus(bad)
+		_, err = expectErrorDto(err, http.StatusBadRequest, common.ErrcdDomainProps)
+		return err
+	}},
+	{"AddJobStatus with a bad BusDt names the field", func(env contractEnv) error {
+		bad := sampleDto
+		bad.BusinessDate = "06/15/2023"
+		_, err := env.Client.AddJobStatus(bad)
+		apiErr, err := expectErrorDto(err, http.StatusBadRequest, common.ErrcdDomainProps)
+		if err != nil {
+			return err
+		}
+		if len(apiErr.FieldErrors) != 1 || apiErr.FieldErrors[0].Field != "BusDt" {
+			return fmt.Errorf("expected one field error for BusDt, got %+v", apiErr.FieldErrors)
+		}
+		return nil
+	}},
+	{"AddJobStatus reports every bad field at once", func(env contractEnv) error {
+		bad := sampleDto
+		bad.JobStatusCode = "DONE"
+		bad.HostId = ""
+		bad.JobStatusTimestamp = time.Now().Add(time.Hour).UTC().Format(dto.TimestampFormat)
+		_, err := env.Client.AddJobStatus(bad)
+		apiErr, err := expectErrorDto(err, http.StatusBadRequest, common.ErrcdDomainProps)
+		if err != nil {
+			return err
+		}
+		var fields []string
+		for _, fe := range apiErr.FieldErrors {
+			fields = append(fields, fe.Field)
+		}
+		return expectEqual("fields", fields, []string{"JobSt", "JobStTs", "HostId"})
+	}},
+	{"AddJobStatusBatch names bad fields by status", func(env contractEnv) error {
+		bad := sampleDto
+		bad.RunId = "2"
+		bad.BusinessDate = ""
+		_, err := env.Client.AddJobStatusBatch([]dto.JobStatusDto{sampleDto, bad})
+		apiErr, err := expectErrorDto(err, http.StatusBadRequest, common.ErrcdDomainProps)
+		if err != nil {
+			return err
+		}
+		if len(apiErr.FieldErrors) != 1 || apiErr.FieldErrors[0].Field != "[1].BusDt" {
+			return fmt.Errorf("expected one field error for [1].BusDt, got %+v", apiErr.FieldErrors)
+		}
+		return nil
+	}},
+	{"AddJobStatus without RunId gets a generated one", func(env contractEnv) error {
+		noRun := sampleDto
+		noRun.RunId = ""
+		got, err := env.Client.AddJobStatus(noRun)
+		if err != nil {
+			return err
+		}
+		if len(got.RunId) != 36 {
+			return fmt.Errorf("expected a UUID RunId, got %q", got.RunId)
+		}
+		return nil
+	}},
+	{"AddJobStatus with bad HostId characters is 400", func(env contractEnv) error {
+		bad := sampleDto
+		bad.HostId = "batch 01"
+		_, err := env.Client.AddJobStatus(bad)
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+	{"AddJobStatus duplicate is 409", func(env contractEnv) error {
+		env.Fail("AddIdempotent", common.ErrcdRepoDupeRow)
+		_, err := env.Client.AddJobStatus(sampleDto)
+		return expectStatus(err, http.StatusConflict)
+	}},
+	{"AddJobStatus of a different status with the same key is 409", func(env contractEnv) error {
+		if _, err := env.Client.AddJobStatus(sampleDto); err != nil {
+			return err
+		}
+		other := sampleDto
+		other.HostId = "batch02"
+		_, err := env.Client.AddJobStatus(other)
+		return expectStatus(err, http.StatusConflict)
+	}},
+	{"AddJobStatus retried returns the stored status", func(env contractEnv) error {
+		first, err := env.Client.AddJobStatus(sampleDto)
+		if err != nil {
+			return err
+		}
+		again, err := env.Client.AddJobStatus(sampleDto)
+		if err != nil {
+			return err
+		}
+		if err := expectEqual("StatusId", again.StatusId, first.StatusId); err != nil {
+			return err
+		}
+		n, err := env.Stored()
+		if err != nil {
+			return err
+		}
+		return expectEqual("stored statuses", n, 1)
+	}},
+	{"AddJobStatus keeps Metadata", func(env contractEnv) error {
+		withMeta := sampleDto
+		withMeta.Metadata = map[string]string{"exitCode": "0", "durationMs": "1500"}
+		if _, err := env.Client.AddJobStatus(withMeta); err != nil {
+			return err
+		}
+		got, err := env.Client.GetByJobId(sampleDto.JobId, client.QueryOptions{Fields: []string{"Metadata"}})
+		if err != nil {
+			return err
+		}
+		if err := expectEqual("statuses", got, []dto.JobStatusDto{{Metadata: withMeta.Metadata}}); err != nil {
+			return err
+		}
+
+		// metadata is part of the report, so a retry with different metadata isn't the same status
+		withMeta.Metadata = map[string]string{"exitCode": "1"}
+		_, err = env.Client.AddJobStatus(withMeta)
+		return expectStatus(err, http.StatusConflict)
+	}},
+	{"AddJobStatus with bad Metadata is 400 naming each problem", func(env contractEnv) error {
+		bad := sampleDto
+		bad.Metadata = map[string]string{"exit code": "0", "log": strings.Repeat("x", jobStatus.MaxMetadataValueLen+1)}
+		_, err := env.Client.AddJobStatus(bad)
+		apiErr, err := expectErrorDto(err, http.StatusBadRequest, common.ErrcdDomainProps)
+		if err != nil {
+			return err
+		}
+		var fields []string
+		for _, fe := range apiErr.FieldErrors {
+			fields = append(fields, fe.Field)
+		}
+		return expectEqual("field errors", fields, []string{"Metadata", "Metadata.log"})
+	}},
+	{"RecordHeartbeat sets HeartbeatTs on the run's START and never moves it back", func(env contractEnv) error {
+		start := sampleDto
+		start.JobStatusCode = "START"
+		if _, err := env.Client.AddJobStatus(start); err != nil {
+			return err
+		}
+		hb := dto.HeartbeatDto{JobId: start.JobId, BusinessDate: start.BusinessDate, RunId: start.RunId, HeartbeatTimestamp: "2023-06-16T00:20:00Z"}
+		got, err := env.Client.RecordHeartbeat(hb)
+		if err != nil {
+			return err
+		}
+		if err := expectEqual("HeartbeatTs", got.HeartbeatTimestamp, hb.HeartbeatTimestamp); err != nil {
+			return err
+		}
+		hb.HeartbeatTimestamp = "2023-06-16T00:19:00Z"
+		if got, err = env.Client.RecordHeartbeat(hb); err != nil {
+			return err
+		}
+		if err := expectEqual("HeartbeatTs after an older heartbeat", got.HeartbeatTimestamp, "2023-06-16T00:20:00Z"); err != nil {
+			return err
+		}
+		queried, err := env.Client.GetByJobId(start.JobId, client.QueryOptions{Fields: []string{"JobSt", "HeartbeatTs"}})
+		if err != nil {
+			return err
+		}
+		return expectEqual("queried", queried, []dto.JobStatusDto{{JobStatusCode: "START", HeartbeatTimestamp: "2023-06-16T00:20:00Z"}})
+	}},
+	{"RecordHeartbeat without a START is 404", func(env contractEnv) error {
+		if _, err := env.Client.AddJobStatus(sampleDto); err != nil {
+			return err
+		}
+		_, err := env.Client.RecordHeartbeat(dto.HeartbeatDto{JobId: sampleDto.JobId, BusinessDate: sampleDto.BusinessDate, RunId: sampleDto.RunId})
+		_, err = expectErrorDto(err, http.StatusNotFound, common.ErrcdNotFound)
+		return err
+	}},
+	{"RecordHeartbeat with bad fields is 400 naming each", func(env contractEnv) error {
+		_, err := env.Client.RecordHeartbeat(dto.HeartbeatDto{JobId: "od calc", BusinessDate: "June 15", HeartbeatTimestamp: "noon"})
+		apiErr, err := expectErrorDto(err, http.StatusBadRequest, common.ErrcdDomainProps)
+		if err != nil {
+			return err
+		}
+		var fields []string
+		for _, fe := range apiErr.FieldErrors {
+			fields = append(fields, fe.Field)
+		}
+		return expectEqual("field errors", fields, []string{"JobId", "BusDt", "RunId", "HeartbeatTs"})
+	}},
+	{"repo connection failure is 503", func(env contractEnv) error {
+		env.Fail("GetByJobId", common.ErrcdRepoConnection)
+		_, err := env.Client.GetByJobId(sampleDto.JobId, client.QueryOptions{})
+		return expectStatus(err, http.StatusServiceUnavailable)
+	}},
+	{"GetByJobId returns added statuses", func(env contractEnv) error {
+		added, err := env.Client.AddJobStatus(sampleDto)
+		if err != nil {
+			return err
+		}
+		got, err := env.Client.GetByJobId(sampleDto.JobId, client.QueryOptions{})
+		if err != nil {
+			return err
+		}
+		return expectEqual("statuses", got, []dto.JobStatusDto{added})
+	}},
+	{"GetByJobId with no matches is an empty list", func(env contractEnv) error {
+		got, err := env.Client.GetByJobId("no-such-job", client.QueryOptions{})
+		if err != nil {
+			return err
+		}
+		return expectEqual("statuses", got, []dto.JobStatusDto{})
+	}},
+	{"GetByJobIdBusinessDate filters by date", func(env contractEnv) error {
+		added, err := env.Client.AddJobStatus(sampleDto)
+		if err != nil {
+			return err
+		}
+		got, err := env.Client.GetByJobIdBusinessDate(sampleDto.JobId, "2023-06-14", client.QueryOptions{})
+		if err != nil {
+			return err
+		}
+		if err := expectEqual("other date", got, []dto.JobStatusDto{}); err != nil {
+			return err
+		}
+		got, err = env.Client.GetByJobIdBusinessDate(sampleDto.JobId, sampleDto.BusinessDate, client.QueryOptions{})
+		if err != nil {
+			return err
+		}
+		return expectEqual("same date", got, []dto.JobStatusDto{added})
+	}},
+	{"fields returns only requested fields", func(env contractEnv) error {
+		if _, err := env.Client.AddJobStatus(sampleDto); err != nil {
+			return err
+		}
+		got, err := env.Client.GetByJobId(sampleDto.JobId, client.QueryOptions{Fields: []string{"JobSt", "BusDt"}})
+		if err != nil {
+			return err
+		}
+		want := dto.JobStatusDto{JobStatusCode: sampleDto.JobStatusCode, BusinessDate: sampleDto.BusinessDate}
+		return expectEqual("sparse", got, []dto.JobStatusDto{want})
+	}},
+	{"unknown field is 400", func(env contractEnv) error {
+		_, err := env.Client.GetByJobId(sampleDto.JobId, client.QueryOptions{Fields: []string{"Nope"}})
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+	{"sort orders results", func(env contractEnv) error {
+		for _, busDt := range []string{"2023-06-14", "2023-06-16", "2023-06-15"} {
+			jsDto := sampleDto
+			jsDto.BusinessDate = busDt
+			if _, err := env.Client.AddJobStatus(jsDto); err != nil {
+				return err
+			}
+		}
+		got, err := env.Client.GetByJobId(sampleDto.JobId, client.QueryOptions{Sort: []string{"BusDt:desc", "JobStTs"}})
+		if err != nil {
+			return err
+		}
+		busDts := make([]string, len(got))
+		for i, jsDto := range got {
+			busDts[i] = jsDto.BusinessDate
+		}
+		return expectEqual("business dates", busDts, []string{"2023-06-16", "2023-06-15", "2023-06-14"})
+	}},
+	{"unsortable field is 400", func(env contractEnv) error {
+		_, err := env.Client.GetByJobId(sampleDto.JobId, client.QueryOptions{Sort: []string{"HostId"}})
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+	{"StreamByJobId matches GetByJobId", func(env contractEnv) error {
+		second := sampleDto
+		second.RunId = "2"
+		var added []dto.JobStatusDto
+		for _, d := range []dto.JobStatusDto{sampleDto, second} {
+			a, err := env.Client.AddJobStatus(d)
+			if err != nil {
+				return err
+			}
+			added = append(added, a)
+		}
+		var got []dto.JobStatusDto
+		err := env.Client.StreamByJobId(sampleDto.JobId, client.QueryOptions{}, func(d dto.JobStatusDto) error {
+			got = append(got, d)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return expectEqual("streamed", got, added)
+	}},
+	{"StreamByJobIdBusinessDate reports server errors", func(env contractEnv) error {
+		env.Fail("ForEachByJobIdBusinessDate", common.ErrcdRepoConnection)
+		err := env.Client.StreamByJobIdBusinessDate(sampleDto.JobId, sampleDto.BusinessDate, client.QueryOptions{}, func(dto.JobStatusDto) error { return nil })
+		return expectStatus(err, http.StatusServiceUnavailable)
+	}},
+	{"RunDailyRollup returns rows written", func(env contractEnv) error {
+		other := sampleDto
+		other.BusinessDate = "2023-06-16"
+		for _, jsDto := range []dto.JobStatusDto{sampleDto, other} {
+			if _, err := env.Client.AddJobStatus(jsDto); err != nil {
+				return err
+			}
+		}
+		got, err := env.Client.RunDailyRollup("2023-06-01", "2023-06-30")
+		if err != nil {
+			return err
+		}
+		return expectEqual("rollup result", got, dto.RollupResultDto{FromDate: "2023-06-01", ToDate: "2023-06-30", RowsWritten: 2})
+	}},
+	{"RunDailyRollup with reversed dates is 400", func(env contractEnv) error {
+		_, err := env.Client.RunDailyRollup("2023-06-30", "2023-06-01")
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+	{"GetDailyRollups returns rollup fields", func(env contractEnv) error {
+		// run 1 succeeds after 1 second and run 2 fails after 2
+		for _, st := range []struct{ code, runId, ts string }{
+			{"START", "1", "01:00:00"}, {"SUCCEED", "1", "01:00:01"}, {"START", "2", "02:00:00"}, {"FAIL", "2", "02:00:02"},
+		} {
+			jsDto := sampleDto
+			jsDto.JobStatusCode = st.code
+			jsDto.RunId = st.runId
+			jsDto.JobStatusTimestamp = "2023-06-16T" + st.ts + "Z"
+			if _, err := env.Client.AddJobStatus(jsDto); err != nil {
+				return err
+			}
+		}
+		if _, err := env.Client.RunDailyRollup("2023-06-01", "2023-06-30"); err != nil {
+			return err
+		}
+		got, err := env.Client.GetDailyRollups("overdrafts", "2023-06-01", "2023-06-30")
+		if err != nil {
+			return err
+		}
+		if len(got) != 1 || got[0].RolledUpTimestamp == "" {
+			return fmt.Errorf("expected one rollup with a RolledUpTimestamp, got %+v", got)
+		}
+		want := dto.DailyRollupDto{
+			ApplicationId:     "overdrafts",
+			BusinessDate:      "2023-06-15",
+			StartCount:        2,
+			SucceedCount:      1,
+			FailCount:         1,
+			RunCount:          2,
+			CompletedRunCount: 2,
+			TotalDurationMs:   3000,
+			MinDurationMs:     1000,
+			MaxDurationMs:     2000,
+			AvgDurationMs:     1500,
+			RolledUpTimestamp: got[0].RolledUpTimestamp,
+		}
+		return expectEqual("rollups", got, []dto.DailyRollupDto{want})
+	}},
+	{"GetJobReliability returns MTTR and MTBF", func(env contractEnv) error {
+		// two failures (the first is two FAILs), recovered after 60 and 15 minutes, starting 4 hours apart
+		for i, st := range []struct{ code, ts string }{
+			{"FAIL", "01:00"}, {"FAIL", "01:30"}, {"SUCCEED", "02:00"}, {"SUCCEED", "03:00"}, {"FAIL", "05:00"}, {"SUCCEED", "05:15"},
+		} {
+			jsDto := sampleDto
+			jsDto.JobStatusCode = st.code
+			jsDto.JobStatusTimestamp = "2023-06-16T" + st.ts + ":00Z"
+			jsDto.RunId = fmt.Sprint(i)
+			if _, err := env.Client.AddJobStatus(jsDto); err != nil {
+				return err
+			}
+		}
+		got, err := env.Client.GetJobReliability("overdrafts", "", "2023-06-01", "2023-06-30")
+		if err != nil {
+			return err
+		}
+		want := dto.JobReliabilityDto{
+			ApplicationId: "overdrafts",
+			JobId:         "od-calc",
+			FromDate:      "2023-06-01",
+			ToDate:        "2023-06-30",
+			TerminalCount: 6,
+			FailureCount:  2,
+			RecoveryCount: 2,
+			MttrMs:        (75 * time.Minute / 2).Milliseconds(),
+			MtbfMs:        (4 * time.Hour).Milliseconds(),
+		}
+		return expectEqual("reliability", got, []dto.JobReliabilityDto{want})
+	}},
+	{"GetFlakiestJobs ranks alternating jobs first", func(env contractEnv) error {
+		// od-flaky alternates every run, od-calc changes twice in 6 runs, od-new has too few runs
+		for jobId, codes := range map[string][]string{
+			"od-flaky": {"FAIL", "SUCCEED", "FAIL", "SUCCEED", "FAIL", "SUCCEED"},
+			"od-calc":  {"SUCCEED", "SUCCEED", "SUCCEED", "FAIL", "SUCCEED", "SUCCEED"},
+			"od-new":   {"FAIL", "SUCCEED"},
+		} {
+			for i, code := range codes {
+				jsDto := sampleDto
+				jsDto.JobId = jobId
+				jsDto.JobStatusCode = code
+				jsDto.JobStatusTimestamp = fmt.Sprintf("2023-06-16T0%d:00:00Z", i)
+				jsDto.RunId = fmt.Sprint(i)
+				if _, err := env.Client.AddJobStatus(jsDto); err != nil {
+					return err
+				}
+			}
+		}
+		got, err := env.Client.GetFlakiestJobs("overdrafts", "2023-06-01", "2023-06-30", 0, 0)
+		if err != nil {
+			return err
+		}
+		want := []dto.JobFlakinessDto{
+			{Rank: 1, ApplicationId: "overdrafts", JobId: "od-flaky", FromDate: "2023-06-01", ToDate: "2023-06-30", TerminalCount: 6, TransitionCount: 5, FailureCount: 3, Flakiness: 1},
+			{Rank: 2, ApplicationId: "overdrafts", JobId: "od-calc", FromDate: "2023-06-01", ToDate: "2023-06-30", TerminalCount: 6, TransitionCount: 2, FailureCount: 1, Flakiness: 0.4},
+		}
+		return expectEqual("flakiest jobs", got, want)
+	}},
+	{"GetDurationBaselines picks the most specific season with enough runs", func(env contractEnv) error {
+		// 2023-06-01 is the first business day, 2023-06-30 the last; 2023-07-03 is July's first
+		for busDt, minutes := range map[string]int{"2023-06-01": 60, "2023-06-02": 10, "2023-06-05": 12, "2023-06-06": 11, "2023-06-30": 40} {
+			start := sampleDto
+			start.JobStatusCode = "START"
+			start.BusinessDate = busDt
+			start.JobStatusTimestamp = busDt + "T01:00:00Z"
+			end := start
+			end.JobStatusCode = "SUCCEED"
+			end.JobStatusTimestamp = fmt.Sprintf("%sT%02d:%02d:00Z", busDt, 1+minutes/60, minutes%60)
+			for _, jsDto := range []dto.JobStatusDto{start, end} {
+				if _, err := env.Client.AddJobStatus(jsDto); err != nil {
+					return err
+				}
+			}
+		}
+		got, err := env.Client.GetDurationBaselines("overdrafts", "", "2023-06-01", "2023-06-30", "2023-07-03", 1)
+		if err != nil {
+			return err
+		}
+		want := dto.DurationBaselineDto{ApplicationId: "overdrafts", JobId: "od-calc", Season: "FirstBusinessDay", RunCount: 1, MedianMs: 3600000, P90Ms: 3600000}
+		if err := expectEqual("first business day baseline", got, []dto.DurationBaselineDto{want}); err != nil {
+			return err
+		}
+		// Mon and FirstBusinessDay have one run each, so minRuns 2 falls back to All
+		got, err = env.Client.GetDurationBaselines("overdrafts", "", "2023-06-01", "2023-06-30", "2023-07-03", 2)
+		if err != nil {
+			return err
+		}
+		want = dto.DurationBaselineDto{ApplicationId: "overdrafts", JobId: "od-calc", Season: "All", RunCount: 5, MedianMs: 12 * 60000, P90Ms: 52 * 60000}
+		return expectEqual("fallback baseline", got, []dto.DurationBaselineDto{want})
+	}},
+	{"GetJobForecast forecasts the run in progress from baselines", func(env contractEnv) error {
+		// five hour-long runs last week, then one that started ten minutes ago
+		now := time.Now().UTC()
+		today := now.Format(dto.DateFormat)
+		for days := 3; days <= 7; days++ {
+			busDt := now.AddDate(0, 0, -days).Format(dto.DateFormat)
+			start := sampleDto
+			start.JobStatusCode = "START"
+			start.BusinessDate = busDt
+			start.JobStatusTimestamp = busDt + "T01:00:00Z"
+			end := start
+			end.JobStatusCode = "SUCCEED"
+			end.JobStatusTimestamp = busDt + "T02:00:00Z"
+			for _, jsDto := range []dto.JobStatusDto{start, end} {
+				if _, err := env.Client.AddJobStatus(jsDto); err != nil {
+					return err
+				}
+			}
+		}
+		started := now.Add(-10 * time.Minute)
+		running := sampleDto
+		running.JobStatusCode = "START"
+		running.BusinessDate = today
+		running.JobStatusTimestamp = started.Format(dto.TimestampFormat)
+		running.RunId = "2"
+		if _, err := env.Client.AddJobStatus(running); err != nil {
+			return err
+		}
+
+		got, err := env.Client.GetJobForecast("overdrafts", "od-calc", today, now.Add(-time.Minute).Format(dto.TimestampFormat), "")
+		if err != nil {
+			return err
+		}
+		if got.Model != "baseline" || got.RunId != "2" || got.MissProbability == nil || *got.MissProbability != 1 {
+			return fmt.Errorf("got %+v, want baseline forecast for RunId 2 with MissProbability 1 for a passed deadline", got)
+		}
+		end, err := time.Parse(time.RFC3339Nano, got.ExpectedEndTimestamp)
+		if err != nil {
+			return err
+		}
+		if end.Before(started.Add(time.Hour)) || end.After(started.Add(62*time.Minute)) {
+			return fmt.Errorf("ExpectedEndTs %s, want about an hour after %s", got.ExpectedEndTimestamp, running.JobStatusTimestamp)
+		}
+
+		// once the run ends there's nothing to forecast
+		running.JobStatusCode = "SUCCEED"
+		running.JobStatusTimestamp = now.Format(dto.TimestampFormat)
+		if _, err := env.Client.AddJobStatus(running); err != nil {
+			return err
+		}
+		_, err = env.Client.GetJobForecast("overdrafts", "od-calc", today, "", "")
+		return expectStatus(err, http.StatusNotFound)
+	}},
+	{"GetJobCosts totals run costs and failed runs", func(env contractEnv) error {
+		failed := sampleDto
+		failed.JobStatusCode = "FAIL"
+		failed.RunId = "2"
+		for _, jsDto := range []dto.JobStatusDto{sampleDto, failed} {
+			if _, err := env.Client.AddJobStatus(jsDto); err != nil {
+				return err
+			}
+		}
+		// run 3 has no status yet, so its cost isn't counted
+		for runId, cost := range map[string]float64{"1": 1.5, "2": 2.25, "3": 10} {
+			rcDto := dto.RunCostDto{ApplicationId: "overdrafts", JobId: "od-calc", BusinessDate: sampleDto.BusinessDate, RunId: runId, ComputeHours: cost * 2, CostUsd: cost}
+			if _, err := env.Client.PutRunCost(rcDto); err != nil {
+				return err
+			}
+		}
+		got, err := env.Client.GetJobCosts("overdrafts", "2023-06-01", "2023-06-30")
+		if err != nil {
+			return err
+		}
+		want := dto.JobCostDto{ApplicationId: "overdrafts", JobId: "od-calc", FromDate: "2023-06-01", ToDate: "2023-06-30",
+			RunCount: 2, ComputeHours: 7.5, CostUsd: 3.75, FailedRunCount: 1, FailedCostUsd: 2.25}
+		return expectEqual("job costs", got, []dto.JobCostDto{want})
+	}},
+	{"PutRunCost with a negative cost is 400", func(env contractEnv) error {
+		_, err := env.Client.PutRunCost(dto.RunCostDto{ApplicationId: "overdrafts", JobId: "od-calc", BusinessDate: sampleDto.BusinessDate, RunId: "1", CostUsd: -1})
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+	{"AddRunComment without a signed-in user is 401", func(env contractEnv) error {
+		_, err := env.Client.AddRunComment(dto.RunCommentDto{JobId: "od-calc", BusinessDate: sampleDto.BusinessDate, RunId: "1", Body: "rerun after fix"})
+		if err := expectStatus(err, http.StatusUnauthorized); err != nil {
+			return err
+		}
+		comments, err := env.Repo.ListRunComments(context.Background(), "od-calc", time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC), "")
+		if err != nil {
+			return err
+		}
+		return expectEqual("stored comments", len(comments), 0)
+	}},
+	{"ListRunComments returns a run's comments oldest first", func(env contractEnv) error {
+		busDt, _ := time.Parse(dto.DateFormat, sampleDto.BusinessDate)
+		var want []dto.RunCommentDto
+		for i, runId := range []string{"1", "2", "1"} {
+			rc := jobStatus.RunComment{CommentId: jobStatus.CommentIdType(fmt.Sprintf("c%d", i)), JobId: "od-calc", BusinessDate: busDt, RunId: jobStatus.RunIdType(runId),
+				Author: "ops@example.com", Body: fmt.Sprintf("note %d", i), CreatedTs: time.Date(2023, 6, 16, 9, i, 0, 0, time.UTC)}
+			if err := env.Repo.AddRunComment(context.Background(), rc); err != nil {
+				return err
+			}
+			if runId == "1" {
+				want = append(want, dto.RunCommentDto{CommentId: string(rc.CommentId), JobId: "od-calc", BusinessDate: sampleDto.BusinessDate, RunId: "1",
+					Author: rc.Author, Body: rc.Body, CreatedTimestamp: rc.CreatedTs.Format(dto.TimestampFormat)})
+			}
+		}
+		got, err := env.Client.ListRunComments("od-calc", sampleDto.BusinessDate, "1")
+		if err != nil {
+			return err
+		}
+		return expectEqual("run comments", got, want)
+	}},
+	{"GetJobBadge shows the job's latest state", func(env contractEnv) error {
+		failed := sampleDto
+		failed.JobStatusCode = "FAIL"
+		failed.RunId = "2"
+		failed.JobStatusTimestamp = "2023-06-16T01:02:03.000Z"
+		for _, jsDto := range []dto.JobStatusDto{sampleDto, failed} {
+			if _, err := env.Client.AddJobStatus(jsDto); err != nil {
+				return err
+			}
+		}
+		for jobId, want := range map[string]string{sampleDto.JobId: ">failed<", "od-post": ">not started<"} {
+			svg, err := env.Client.GetJobBadge(jobId, sampleDto.BusinessDate)
+			if err != nil {
+				return err
+			}
+			if !strings.Contains(string(svg), want) || !strings.Contains(string(svg), ">"+jobId+"<") {
+				return fmt.Errorf("badge for %s: expected %s, got %s", jobId, want, svg)
+			}
+		}
+		return nil
+	}},
+	{"GetLatestByJobId returns the last status of each business date", func(env contractEnv) error {
+		failed := sampleDto
+		failed.JobStatusCode = "FAIL"
+		failed.RunId = "2"
+		failed.JobStatusTimestamp = "2023-06-16T01:02:03.000Z"
+		nextDay := sampleDto
+		nextDay.BusinessDate = "2023-06-16"
+		nextDay.JobStatusTimestamp = "2023-06-17T00:10:00.000Z"
+		for _, jsDto := range []dto.JobStatusDto{sampleDto, failed, nextDay} {
+			if _, err := env.Client.AddJobStatus(jsDto); err != nil {
+				return err
+			}
+		}
+		got, err := env.Client.GetLatestByJobId(sampleDto.JobId, "2023-06-14", "2023-06-16")
+		if err != nil {
+			return err
+		}
+		codes := make([]string, len(got))
+		for i, jsDto := range got {
+			codes[i] = jsDto.BusinessDate + " " + jsDto.JobStatusCode
+		}
+		return expectEqual("latest", codes, []string{"2023-06-16 SUCCEED", "2023-06-15 FAIL"})
+	}},
+	{"GetLatestByJobId without a jobId is 400", func(env contractEnv) error {
+		_, err := env.Client.GetLatestByJobId("", "2023-06-15", "")
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+	{"AddJobStatusBatch stores every status in order", func(env contractEnv) error {
+		batch := make([]dto.JobStatusDto, 3)
+		for i := range batch {
+			batch[i] = sampleDto
+			batch[i].RunId = strconv.Itoa(i + 1)
+		}
+		got, err := env.Client.AddJobStatusBatch(batch)
+		if err != nil {
+			return err
+		}
+		runIds := make([]string, len(got))
+		for i, jsDto := range got {
+			runIds[i] = jsDto.RunId
+		}
+		if err := expectEqual("returned RunIds", runIds, []string{"1", "2", "3"}); err != nil {
+			return err
+		}
+		n, err := env.Stored()
+		if err != nil {
+			return err
+		}
+		return expectEqual("stored statuses", n, 3)
+	}},
+	{"AddJobStatusBatch with an invalid status stores nothing", func(env contractEnv) error {
+		bad := sampleDto
+		bad.JobStatusCode = "DONE"
+		_, err := env.Client.AddJobStatusBatch([]dto.JobStatusDto{sampleDto, bad})
+		if err := expectStatus(err, http.StatusBadRequest); err != nil {
+			return err
+		}
+		n, err := env.Stored()
+		if err != nil {
+			return err
+		}
+		return expectEqual("stored statuses", n, 0)
+	}},
+	{"AddJobStatusBatch duplicate is 409", func(env contractEnv) error {
+		env.Fail("AddBatch", common.ErrcdRepoDupeRow)
+		_, err := env.Client.AddJobStatusBatch([]dto.JobStatusDto{sampleDto})
+		return expectStatus(err, http.StatusConflict)
+	}},
+	{"GetPageByJobId pages in sort order", func(env contractEnv) error {
+		batch := make([]dto.JobStatusDto, 5)
+		for i := range batch {
+			batch[i] = sampleDto
+			batch[i].RunId = strconv.Itoa(i + 1)
+			batch[i].JobStatusTimestamp = fmt.Sprintf("2023-06-16T00:0%d:00.000Z", 5-i)
+		}
+		if _, err := env.Client.AddJobStatusBatch(batch); err != nil {
+			return err
+		}
+		var runIds []string
+		offset := 0
+		for pages := 0; pages < 5; pages++ {
+			page, err := env.Client.GetPageByJobId(sampleDto.JobId, client.QueryOptions{Sort: []string{"JobStTs"}}, 2, offset)
+			if err != nil {
+				return err
+			}
+			for _, jsDto := range page.JobStatuses {
+				runIds = append(runIds, jsDto.RunId)
+			}
+			if page.Page.NextOffset == nil {
+				break
+			}
+			offset = *page.Page.NextOffset
+		}
+		return expectEqual("RunIds across pages", runIds, []string{"5", "4", "3", "2", "1"})
+	}},
+	{"GetPageByJobId with a limit over the maximum is 400", func(env contractEnv) error {
+		_, err := env.Client.GetPageByJobId(sampleDto.JobId, client.QueryOptions{}, jobStatus.MaxPageLimit+1, 0)
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+	{"GetFlakiestJobs without an appId is 400", func(env contractEnv) error {
+		_, err := env.Client.GetFlakiestJobs("", "2023-06-01", "2023-06-30", 0, 0)
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+	{"GetByView returns the view's jobs with its fields", func(env contractEnv) error {
+		other := sampleDto
+		other.JobId = "od-post"
+		for _, jsDto := range []dto.JobStatusDto{sampleDto, other} {
+			if _, err := env.Client.AddJobStatus(jsDto); err != nil {
+				return err
+			}
+		}
+		view := dto.SavedViewDto{Name: "overdrafts", Team: "ops", JobIds: []string{"od-post", "od-calc"}, Fields: "JobId,JobSt"}
+		if _, err := env.Client.PutSavedView(view); err != nil {
+			return err
+		}
+		got, err := env.Client.GetByView("overdrafts", "", client.QueryOptions{})
+		if err != nil {
+			return err
+		}
+		return expectEqual("view statuses", got, []dto.JobStatusDto{
+			{JobId: "od-post", JobStatusCode: "SUCCEED"},
+			{JobId: "od-calc", JobStatusCode: "SUCCEED"},
+		})
+	}},
+	{"PutSavedView over another team's view is 409", func(env contractEnv) error {
+		if _, err := env.Client.PutSavedView(dto.SavedViewDto{Name: "overdrafts", Team: "ops"}); err != nil {
+			return err
+		}
+		_, err := env.Client.PutSavedView(dto.SavedViewDto{Name: "overdrafts", Team: "dev"})
+		return expectStatus(err, http.StatusConflict)
+	}},
+	{"GetStatusBoard shows each job's latest state", func(env contractEnv) error {
+		started := sampleDto
+		started.JobStatusCode = "START"
+		started.JobStatusTimestamp = "2023-06-16T00:10:00Z"
+		for _, jsDto := range []dto.JobStatusDto{started, sampleDto} {
+			if _, err := env.Client.AddJobStatus(jsDto); err != nil {
+				return err
+			}
+		}
+		view := dto.SavedViewDto{Name: "overdrafts", Team: "ops", JobIds: []string{"od-calc", "od-post"}}
+		if _, err := env.Client.PutSavedView(view); err != nil {
+			return err
+		}
+		got, err := env.Client.GetStatusBoard("overdrafts", sampleDto.BusinessDate)
+		if err != nil {
+			return err
+		}
+		if err := expectEqual("counts", got.Counts, dto.StatusBoardCountsDto{Succeeded: 1, NotStarted: 1}); err != nil {
+			return err
+		}
+		return expectEqual("jobs", got.Jobs, []dto.StatusBoardJobDto{
+			{JobId: "od-calc", State: dto.BoardStateSucceeded, ApplicationId: sampleDto.ApplicationId, JobStatusTimestamp: sampleDto.JobStatusTimestamp, RunId: sampleDto.RunId, HostId: sampleDto.HostId},
+			{JobId: "od-post", State: dto.BoardStateNotStarted},
+		})
+	}},
+	{"GetStatusBoardAsOf before the statuses arrived shows NOT_STARTED", func(env contractEnv) error {
+		if _, err := env.Client.AddJobStatus(sampleDto); err != nil {
+			return err
+		}
+		view := dto.SavedViewDto{Name: "overdrafts", Team: "ops", JobIds: []string{"od-calc"}}
+		if _, err := env.Client.PutSavedView(view); err != nil {
+			return err
+		}
+		got, err := env.Client.GetStatusBoardAsOf("overdrafts", sampleDto.BusinessDate, "2023-06-15T00:00:00Z")
+		if err != nil {
+			return err
+		}
+		return expectEqual("counts", got.Counts, dto.StatusBoardCountsDto{NotStarted: 1})
+	}},
+	{"GetByJobId with a bad asOf is 400", func(env contractEnv) error {
+		_, err := env.Client.GetByJobId(sampleDto.JobId, client.QueryOptions{AsOf: "7am"})
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+	{"GetByFilters matches JobId prefix and status codes", func(env contractEnv) error {
+		other := sampleDto
+		other.JobId = "od-post"
+		other.JobStatusCode = "START"
+		for _, jsDto := range []dto.JobStatusDto{sampleDto, other} {
+			if _, err := env.Client.AddJobStatus(jsDto); err != nil {
+				return err
+			}
+		}
+		got, err := env.Client.GetByFilters(client.QueryOptions{
+			Fields:  []string{"JobId", "JobSt"},
+			Filters: map[string]string{"JobId[prefix]": "od-", "JobSt[in]": "START,FAIL"},
+		})
+		if err != nil {
+			return err
+		}
+		return expectEqual("statuses", got, []dto.JobStatusDto{{JobId: "od-post", JobStatusCode: "START"}})
+	}},
+	{"GetByFilters without a JobId or BusDt filter is 400", func(env contractEnv) error {
+		_, err := env.Client.GetByFilters(client.QueryOptions{Filters: map[string]string{"HostId[eq]": sampleDto.HostId}})
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+	{"GetByJobId with an unknown filter operator is 400", func(env contractEnv) error {
+		_, err := env.Client.GetByJobId(sampleDto.JobId, client.QueryOptions{Filters: map[string]string{"JobStTs[prefix]": "2023"}})
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+	{"GetSavedView unknown name is 404", func(env contractEnv) error {
+		_, err := env.Client.GetSavedView("nope")
+		return expectStatus(err, http.StatusNotFound)
+	}},
+	{"OpenAPI JobId maxLength is what AddJobStatus accepts", func(env contractEnv) error {
+		doc, err := jobStatus.OpenApiDocument()
+		if err != nil {
+			return err
+		}
+		schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+		jobIdSchema := schemas["JobStatusDto"].(map[string]any)["properties"].(map[string]any)["JobId"].(map[string]any)
+		maxLen, ok := jobIdSchema["maxLength"].(int)
+		if !ok {
+			return fmt.Errorf("expected JobId to have a maxLength, got %v", jobIdSchema)
+		}
+		longest := sampleDto
+		longest.JobId = strings.Repeat("j", maxLen)
+		if _, err := env.Client.AddJobStatus(longest); err != nil {
+			return fmt.Errorf("JobId of maxLength %d: %w", maxLen, err)
+		}
+		tooLong := sampleDto
+		tooLong.JobId = strings.Repeat("j", maxLen+1)
+		_, err = env.Client.AddJobStatus(tooLong)
+		return expectStatus(err, http.StatusBadRequest)
+	}},
+}
@@ -0,0 +1,132 @@
+package jobStatus
+
+import (
+	"sort"
+	"time"
+)
+
+// DailyRollup summarizes one application's job statuses for one business date so trend
+// queries don't need to scan raw status rows. A run's duration is the time from its
+// START to its SUCCEED or FAIL; runs missing either end don't contribute to durations.
+// Costs total the RunCosts of the date's runs; FailedCostUsd is the part for runs that failed.
+type DailyRollup struct {
+	ApplicationId     string
+	BusinessDate      time.Time
+	StartCount        int64
+	SucceedCount      int64
+	FailCount         int64
+	RunCount          int64
+	CompletedRunCount int64
+	TotalDuration     time.Duration
+	MinDuration       time.Duration
+	MaxDuration       time.Duration
+	ComputeHours      float64
+	CostUsd           float64
+	FailedCostUsd     float64
+	RolledUpTimestamp time.Time
+}
+
+// AvgDuration is the mean duration of completed runs.
+func (dr DailyRollup) AvgDuration() time.Duration {
+	if dr.CompletedRunCount == 0 {
+		return 0
+	}
+	return dr.TotalDuration / time.Duration(dr.CompletedRunCount)
+}
+
+// ComputeDailyRollups computes rollups by application and business date, ordered by both, from
+// statuses and run costs for a range of business dates. Each run's cost counts toward the
+// application of its statuses. Durations are whole milliseconds, like repoDB's. Repos that can't
+// use SQL aggregate filters use it; repoDB computes the same thing in SQL.
+func ComputeDailyRollups(jss []JobStatus, costs []RunCost, at time.Time) []DailyRollup {
+	type dayKey struct {
+		applicationId string
+		businessDate  time.Time
+	}
+	type runKey struct {
+		jobId        JobIdType
+		businessDate time.Time
+		runId        RunIdType
+	}
+	type run struct {
+		day            dayKey
+		start, end     time.Time
+		endCode        JobStatusCodeType
+		started, ended bool
+	}
+
+	days := map[dayKey]*DailyRollup{}
+	runs := map[runKey]*run{}
+	for _, js := range jss {
+		day := dayKey{js.ApplicationId, TruncateToDate(js.BusinessDate)}
+		dr := days[day]
+		if dr == nil {
+			dr = &DailyRollup{ApplicationId: day.applicationId, BusinessDate: day.businessDate, RolledUpTimestamp: at}
+			days[day] = dr
+		}
+		key := runKey{js.JobId, day.businessDate, js.RunId}
+		r := runs[key]
+		if r == nil {
+			r = &run{day: day}
+			runs[key] = r
+		}
+
+		switch js.JobStatusCode {
+		case JobStatus_START:
+			dr.StartCount++
+			if !r.started || js.JobStatusTimestamp.Before(r.start) {
+				r.start, r.started = js.JobStatusTimestamp, true
+			}
+		case JobStatus_SUCCEED, JobStatus_FAIL:
+			if js.JobStatusCode == JobStatus_SUCCEED {
+				dr.SucceedCount++
+			} else {
+				dr.FailCount++
+			}
+			if !r.ended || !js.JobStatusTimestamp.Before(r.end) {
+				r.end, r.endCode, r.ended = js.JobStatusTimestamp, js.JobStatusCode, true
+			}
+		}
+	}
+
+	for _, r := range runs {
+		dr := days[r.day]
+		dr.RunCount++
+		if !r.started || !r.ended {
+			continue
+		}
+		d := r.end.Sub(r.start).Truncate(time.Millisecond)
+		if dr.CompletedRunCount == 0 || d < dr.MinDuration {
+			dr.MinDuration = d
+		}
+		if d > dr.MaxDuration {
+			dr.MaxDuration = d
+		}
+		dr.CompletedRunCount++
+		dr.TotalDuration += d
+	}
+	for _, rc := range costs {
+		r := runs[runKey{rc.JobId, TruncateToDate(rc.BusinessDate), rc.RunId}]
+		if r == nil {
+			continue
+		}
+		dr := days[r.day]
+		dr.ComputeHours += rc.ComputeHours
+		dr.CostUsd += rc.CostUsd
+		if r.endCode == JobStatus_FAIL {
+			dr.FailedCostUsd += rc.CostUsd
+		}
+	}
+
+	result := make([]DailyRollup, 0, len(days))
+	for _, dr := range days {
+		result = append(result, *dr)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ApplicationId != result[j].ApplicationId {
+			return result[i].ApplicationId < result[j].ApplicationId
+		}
+		return result[i].BusinessDate.Before(result[j].BusinessDate)
+	})
+	return result
+}
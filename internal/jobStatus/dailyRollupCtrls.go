@@ -0,0 +1,73 @@
+package jobStatus
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/tasks"
+)
+
+// TaskStatusPath is where clients poll tasks started with async=true. The admin package serves it.
+const TaskStatusPath = "/admin/tasks"
+
+type RunDailyRollupCtrl struct {
+	uc    *DailyRollupUC
+	tasks *tasks.Manager
+}
+
+// NewRunDailyRollupCtrl returns the controller. If tm is nil, async rollups are refused.
+func NewRunDailyRollupCtrl(uc *DailyRollupUC, tm *tasks.Manager) *RunDailyRollupCtrl {
+	return &RunDailyRollupCtrl{uc: uc, tasks: tm}
+}
+
+// ServeHTTP handles POST with query parameters fromDt and toDt to run the rollup on demand.
+// With async=true, it queues the rollup and returns 202 with the task; Location points to its status.
+func (ctrl *RunDailyRollupCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	if q.Get("async") == "true" {
+		if ctrl.tasks == nil {
+			writeError(w, r, common.NewCommonError(common.ErrcdDomainProps, errors.New("async rollups are not available")))
+			return
+		}
+		task, err := ctrl.uc.StartRollup(ctrl.tasks, q.Get("fromDt"), q.Get("toDt"))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Location", TaskStatusPath+"?id="+url.QueryEscape(task.TaskId))
+		common.WriteJson(w, http.StatusAccepted, task)
+		return
+	}
+
+	result, err := ctrl.uc.Rollup(r.Context(), q.Get("fromDt"), q.Get("toDt"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
+
+type GetDailyRollupsCtrl struct {
+	uc *DailyRollupUC
+}
+
+func NewGetDailyRollupsCtrl(uc *DailyRollupUC) *GetDailyRollupsCtrl {
+	return &GetDailyRollupsCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameters fromDt, toDt, and optional appId.
+func (ctrl *GetDailyRollupsCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	result, err := ctrl.uc.Get(r.Context(), q.Get("appId"), q.Get("fromDt"), q.Get("toDt"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
@@ -0,0 +1,70 @@
+package jobStatus
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// NightlyRollupConfig controls when the nightly rollup runs and how far back it looks.
+// Late statuses can change earlier dates, so the rollup recomputes LookbackDays dates ending yesterday.
+type NightlyRollupConfig struct {
+	RunAtHour    int
+	RunAtMinute  int
+	LookbackDays int
+	Location     *time.Location
+	// Paused, if it's set, skips runs while it returns true, like while the region is passive.
+	Paused func() bool
+}
+
+// RunNightlyRollup blocks, running the rollup once a day at the configured time until ctx is done.
+func RunNightlyRollup(ctx context.Context, uc *DailyRollupUC, cfg NightlyRollupConfig) {
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+	if cfg.LookbackDays < 1 {
+		cfg.LookbackDays = 1
+	}
+
+	for {
+		now := time.Now().In(cfg.Location)
+		next := nextRunTime(now, cfg.RunAtHour, cfg.RunAtMinute)
+		timer := time.NewTimer(next.Sub(now))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if cfg.Paused != nil && cfg.Paused() {
+			log.Printf("nightly rollup skipped; paused")
+			continue
+		}
+
+		to := TruncateToDate(time.Now().In(cfg.Location)).AddDate(0, 0, -1)
+		from := to.AddDate(0, 0, 1-cfg.LookbackDays)
+		var n int64
+		err := common.CatchPanic("nightly rollup", func() (err error) {
+			n, err = uc.RollupDates(ctx, from, to)
+			return err
+		})
+		if err != nil {
+			log.Printf("nightly rollup %s to %s failed: %v", from.Format("2006-01-02"), to.Format("2006-01-02"), err)
+			continue
+		}
+		log.Printf("nightly rollup %s to %s wrote %d rows", from.Format("2006-01-02"), to.Format("2006-01-02"), n)
+	}
+}
+
+// nextRunTime returns the first hour:minute strictly after now, in now's location.
+func nextRunTime(now time.Time, hour int, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,124 @@
+package jobStatus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/tasks"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+	taskDto "github.com/jmjf/go-jst/public/tasks/dto"
+)
+
+// MaxRollupDays bounds a single on-demand rollup so one request can't recompute the whole table.
+const MaxRollupDays = 93
+
+// MaxAsyncRollupDays bounds a background rollup. It runs one day at a time, so it can cover more.
+const MaxAsyncRollupDays = 3660
+
+// RollupTaskKind is the tasks.Manager kind for background rollups.
+const RollupTaskKind = "daily-rollup"
+
+type DailyRollupUC struct {
+	repo RollupRepo
+}
+
+func NewDailyRollupUC(repo RollupRepo) *DailyRollupUC {
+	return &DailyRollupUC{repo: repo}
+}
+
+// Rollup recomputes rollups for business dates fromDate through toDate (DTO date strings).
+func (uc *DailyRollupUC) Rollup(ctx context.Context, fromDate string, toDate string) (dto.RollupResultDto, error) {
+	from, to, err := parseDateRange(fromDate, toDate, MaxRollupDays)
+	if err != nil {
+		return dto.RollupResultDto{}, err
+	}
+
+	n, err := uc.RollupDates(ctx, from, to)
+	if err != nil {
+		return dto.RollupResultDto{}, err
+	}
+
+	return dto.RollupResultDto{
+		FromDate:    from.Format(dto.DateFormat),
+		ToDate:      to.Format(dto.DateFormat),
+		RowsWritten: n,
+	}, nil
+}
+
+// StartRollup queues a rollup for fromDate through toDate on tm and returns the queued task.
+// The task rolls up one business date at a time, reporting progress in days, and its result
+// is a RollupResultDto.
+func (uc *DailyRollupUC) StartRollup(tm *tasks.Manager, fromDate string, toDate string) (taskDto.TaskDto, error) {
+	from, to, err := parseDateRange(fromDate, toDate, MaxAsyncRollupDays)
+	if err != nil {
+		return taskDto.TaskDto{}, err
+	}
+
+	return tm.Submit(RollupTaskKind, func(ctx context.Context, progress func(int, int)) (any, error) {
+		days := int(to.Sub(from)/(24*time.Hour)) + 1
+		var total int64
+		for i := 0; i < days; i++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			day := from.AddDate(0, 0, i)
+			n, err := uc.RollupDates(ctx, day, day)
+			if err != nil {
+				return nil, fmt.Errorf("rollup %s: %w", day.Format(dto.DateFormat), err)
+			}
+			total += n
+			progress(i+1, days)
+		}
+		return dto.RollupResultDto{
+			FromDate:    from.Format(dto.DateFormat),
+			ToDate:      to.Format(dto.DateFormat),
+			RowsWritten: total,
+		}, nil
+	})
+}
+
+// RollupDates is Rollup for callers that already have dates, like the nightly scheduler.
+func (uc *DailyRollupUC) RollupDates(ctx context.Context, from time.Time, to time.Time) (int64, error) {
+	return uc.repo.RollupDaily(ctx, TruncateToDate(from), TruncateToDate(to))
+}
+
+// Get returns rollups for an application (or all applications if applicationId is empty).
+func (uc *DailyRollupUC) Get(ctx context.Context, applicationId string, fromDate string, toDate string) ([]dto.DailyRollupDto, error) {
+	from, to, err := parseDateRange(fromDate, toDate, MaxRollupDays)
+	if err != nil {
+		return nil, err
+	}
+
+	drs, err := uc.repo.GetDailyRollups(ctx, applicationId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]dto.DailyRollupDto, len(drs))
+	for i, dr := range drs {
+		dtos[i] = dailyRollupToDto(dr)
+	}
+	return dtos, nil
+}
+
+func parseDateRange(fromDate string, toDate string, maxDays int) (time.Time, time.Time, error) {
+	from, err := parseDateProp("FromDate", fromDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseDateProp("ToDate", toDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, common.NewCommonError(common.ErrcdDomainProps, errors.New("ToDate is before FromDate"))
+	}
+	if to.Sub(from) > time.Duration(maxDays)*24*time.Hour {
+		return time.Time{}, time.Time{}, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("date range is longer than %d days", maxDays))
+	}
+	return from, to, nil
+}
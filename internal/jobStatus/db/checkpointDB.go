@@ -0,0 +1,39 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/migrate"
+)
+
+const getCheckpointSql = `SELECT "Name", "Cursor", "ItemsDone", "Done", "UpdatedTimestamp" FROM "MigrationCheckpoint" WHERE "Name" = $1`
+
+// GetCheckpoint implements migrate.CheckpointRepo.
+func (repo *repoDB) GetCheckpoint(name string) (migrate.Checkpoint, bool, error) {
+	var cp migrate.Checkpoint
+	err := repo.DB.QueryRow(getCheckpointSql, name).Scan(&cp.Name, &cp.Cursor, &cp.ItemsDone, &cp.Done, &cp.UpdatedTs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return migrate.Checkpoint{}, false, nil
+	}
+	if err != nil {
+		return migrate.Checkpoint{}, false, common.PgErrToCommon(err)
+	}
+	return cp, true, nil
+}
+
+const saveCheckpointSql = `INSERT INTO "MigrationCheckpoint" ("Name", "Cursor", "ItemsDone", "Done", "UpdatedTimestamp")
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT ("Name") DO UPDATE SET
+		"Cursor" = EXCLUDED."Cursor",
+		"ItemsDone" = EXCLUDED."ItemsDone",
+		"Done" = EXCLUDED."Done",
+		"UpdatedTimestamp" = EXCLUDED."UpdatedTimestamp"`
+
+func (repo *repoDB) SaveCheckpoint(cp migrate.Checkpoint) error {
+	if _, err := repo.DB.Exec(saveCheckpointSql, cp.Name, cp.Cursor, cp.ItemsDone, cp.Done, cp.UpdatedTs); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbcommon"
+)
+
+const updateJobStatusSql = `UPDATE "JobStatus" SET
+		"ApplicationId" = $2, "JobStatusCode" = $3, "JobStatusTimestamp" = $4, "BusinessDate" = $5,
+		"RunId" = $6, "HostId" = $7, "Links" = $8, "Metadata" = $9, "IntegrityHash" = $10
+	WHERE "StatusId" = $1`
+
+const insertCorrectionSql = `INSERT INTO "JobStatusCorrection" ("CorrectionId", "StatusId", "CorrectedBy", "Reason", "CorrectedTimestamp", "Before", "After")
+	VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+const selectCorrectionsSql = `SELECT "CorrectionId", "StatusId", "CorrectedBy", "Reason", "CorrectedTimestamp", "Before", "After"
+	FROM "JobStatusCorrection"
+	WHERE "StatusId" = $1
+	ORDER BY "CorrectionId"`
+
+// statusSnapshotDb is a status in the "Before" and "After" jsonb columns of "JobStatusCorrection";
+// a deletion's "After" is NULL. StatusId has its own column, and IntegrityHash isn't kept; the
+// audit row is the record.
+type statusSnapshotDb struct {
+	ApplicationId      string
+	JobId              string
+	JobStatusCode      string
+	JobStatusTimestamp time.Time
+	BusinessDate       string
+	RunId              string             `json:",omitempty"`
+	HostId             string             `json:",omitempty"`
+	ReportedJobId      string             `json:",omitempty"`
+	ReceivedTimestamp  *time.Time         `json:",omitempty"`
+	Links              []dbcommon.LinkDb  `json:",omitempty"`
+	Metadata           jobStatus.Metadata `json:",omitempty"`
+}
+
+// Update selects the status FOR UPDATE, so concurrent corrections of one status take turns and
+// each one's Before is the previous one's After.
+func (repo *repoDB) Update(ctx context.Context, c jobStatus.StatusCorrection, correct func(before jobStatus.JobStatus) (jobStatus.JobStatus, error)) (jobStatus.StatusCorrection, error) {
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return c, common.PgErrToCommon(err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+allColumns()+` FROM "JobStatus" WHERE "StatusId" = $1 FOR UPDATE`, string(c.StatusId))
+	if err != nil {
+		return c, common.PgErrToCommon(err)
+	}
+	found, err := dialect.RowsToDomain(rows, jobStatus.AllFields, false)
+	rows.Close()
+	if err != nil {
+		return c, err
+	}
+	if len(found) == 0 {
+		return c, common.NewCommonError(common.ErrcdNotFound, fmt.Errorf("status %s does not exist", c.StatusId))
+	}
+
+	c.Before = found[0]
+	if c.After, err = correct(c.Before); err != nil {
+		return c, err
+	}
+	after := c.After
+	links, err := dialect.LinksToDb(after.Links)
+	if err != nil {
+		return c, common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	metadata, err := dialect.MetadataToDb(after.Metadata)
+	if err != nil {
+		return c, common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	if _, err := tx.ExecContext(ctx, updateJobStatusSql,
+		string(after.StatusId), after.ApplicationId, string(after.JobStatusCode), after.JobStatusTimestamp, after.BusinessDate, dbcommon.NullIfEmpty(string(after.RunId)), dbcommon.NullIfEmpty(string(after.HostId)), links, metadata, after.IntegrityHash); err != nil {
+		return c, common.PgErrToCommon(err)
+	}
+
+	if err := insertCorrection(ctx, tx, c); err != nil {
+		return c, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c, common.PgErrToCommon(err)
+	}
+	return c, nil
+}
+
+// DeleteByKey deletes with RETURNING, so the audit row has exactly what was deleted.
+func (repo *repoDB) DeleteByKey(ctx context.Context, c jobStatus.StatusCorrection, key jobStatus.StatusKey) (jobStatus.StatusCorrection, error) {
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return c, common.PgErrToCommon(err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `DELETE FROM "JobStatus"
+		WHERE "JobId" = $1 AND "JobStatusCode" = $2 AND "BusinessDate" = $3 AND "RunId" = $4
+		RETURNING `+allColumns(),
+		string(key.JobId), string(key.JobStatusCode), key.BusinessDate, string(key.RunId))
+	if err != nil {
+		return c, common.PgErrToCommon(err)
+	}
+	found, err := dialect.RowsToDomain(rows, jobStatus.AllFields, false)
+	rows.Close()
+	if err != nil {
+		return c, err
+	}
+	if len(found) == 0 {
+		return c, common.NewCommonError(common.ErrcdNotFound, fmt.Errorf("no %s status for job %s run %s on %s", key.JobStatusCode, key.JobId, key.RunId, key.BusinessDate.Format("2006-01-02")))
+	}
+
+	c.StatusId = found[0].StatusId
+	c.Before = found[0]
+	if err := insertCorrection(ctx, tx, c); err != nil {
+		return c, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c, common.PgErrToCommon(err)
+	}
+	return c, nil
+}
+
+// ListStatusCorrections uses "JobStatusCorrection_StatusId". CorrectionIds are UUIDv7s, so
+// ordering by them is oldest first.
+func (repo *repoDB) ListStatusCorrections(ctx context.Context, statusId jobStatus.StatusIdType) ([]jobStatus.StatusCorrection, error) {
+	rows, err := repo.DB.QueryContext(ctx, selectCorrectionsSql, string(statusId))
+	if err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	var result []jobStatus.StatusCorrection
+	for rows.Next() {
+		var c jobStatus.StatusCorrection
+		var beforeJson, afterJson []byte
+		if err := rows.Scan(&c.CorrectionId, &c.StatusId, &c.CorrectedBy, &c.Reason, &c.CorrectedTs, &beforeJson, &afterJson); err != nil {
+			return nil, common.NewCommonError(common.ErrcdRepoRowConversion, err)
+		}
+		if c.Before, err = snapshotDbToStatus(c.StatusId, beforeJson); err != nil {
+			return nil, err
+		}
+		if afterJson != nil {
+			if c.After, err = snapshotDbToStatus(c.StatusId, afterJson); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	return result, nil
+}
+
+// insertCorrection stores a deletion's "After" as NULL.
+func insertCorrection(ctx context.Context, tx *sql.Tx, c jobStatus.StatusCorrection) error {
+	beforeJson, err := json.Marshal(statusToSnapshotDb(c.Before))
+	if err != nil {
+		return common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	var afterJson any
+	if !c.Deleted() {
+		if afterJson, err = json.Marshal(statusToSnapshotDb(c.After)); err != nil {
+			return common.NewCommonError(common.ErrcdRepoOther, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, insertCorrectionSql, string(c.CorrectionId), string(c.StatusId), c.CorrectedBy, c.Reason, c.CorrectedTs, beforeJson, afterJson); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+// allColumns is every "JobStatus" column in jobStatus.AllFields, for reading whole statuses.
+func allColumns() string {
+	cols, _ := dialect.Columns(jobStatus.AllFields)
+	return strings.Join(cols, ", ")
+}
+
+func statusToSnapshotDb(js jobStatus.JobStatus) statusSnapshotDb {
+	s := statusSnapshotDb{
+		ApplicationId:      js.ApplicationId,
+		JobId:              string(js.JobId),
+		JobStatusCode:      string(js.JobStatusCode),
+		JobStatusTimestamp: js.JobStatusTimestamp,
+		BusinessDate:       js.BusinessDate.Format("2006-01-02"),
+		RunId:              string(js.RunId),
+		HostId:             string(js.HostId),
+		ReportedJobId:      string(js.ReportedJobId),
+	}
+	if !js.ReceivedTimestamp.IsZero() {
+		s.ReceivedTimestamp = &js.ReceivedTimestamp
+	}
+	for _, l := range js.Links {
+		s.Links = append(s.Links, dbcommon.LinkDb{Kind: string(l.Kind), Url: l.Url})
+	}
+	s.Metadata = js.Metadata.Clone()
+	return s
+}
+
+func snapshotDbToStatus(statusId jobStatus.StatusIdType, b []byte) (jobStatus.JobStatus, error) {
+	var s statusSnapshotDb
+	if err := json.Unmarshal(b, &s); err != nil {
+		return jobStatus.JobStatus{}, common.NewCommonError(common.ErrcdRepoRowConversion, err)
+	}
+	businessDate, err := time.Parse("2006-01-02", s.BusinessDate)
+	if err != nil {
+		return jobStatus.JobStatus{}, common.NewCommonError(common.ErrcdRepoRowConversion, fmt.Errorf("BusinessDate: %w", err))
+	}
+	js := jobStatus.JobStatus{
+		StatusId:           statusId,
+		ApplicationId:      s.ApplicationId,
+		JobId:              jobStatus.JobIdType(s.JobId),
+		JobStatusCode:      jobStatus.JobStatusCodeType(s.JobStatusCode),
+		JobStatusTimestamp: s.JobStatusTimestamp,
+		BusinessDate:       businessDate,
+		RunId:              jobStatus.RunIdType(s.RunId),
+		HostId:             jobStatus.HostIdType(s.HostId),
+		ReportedJobId:      jobStatus.JobIdType(s.ReportedJobId),
+	}
+	if s.ReceivedTimestamp != nil {
+		js.ReceivedTimestamp = *s.ReceivedTimestamp
+	}
+	for _, l := range s.Links {
+		js.Links = append(js.Links, jobStatus.Link{Kind: jobStatus.LinkKind(l.Kind), Url: l.Url})
+	}
+	js.Metadata = s.Metadata
+	return js, nil
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// rollupDailySql recomputes "JobStatusDailyRollup" for a business date range from raw statuses.
+// A run is identified by (JobId, BusinessDate, RunId); its duration runs from its first START to its
+// last SUCCEED or FAIL. Costs come from "RunCost" for runs with a status; a run failed if its last
+// SUCCEED or FAIL is a FAIL. Dates in the range with no statuses keep any rollup rows they already have.
+const rollupDailySql = `WITH "StatusCounts" AS (
+	SELECT "ApplicationId", "BusinessDate",
+		COUNT(*) FILTER (WHERE "JobStatusCode" = 'START') AS "StartCount",
+		COUNT(*) FILTER (WHERE "JobStatusCode" = 'SUCCEED') AS "SucceedCount",
+		COUNT(*) FILTER (WHERE "JobStatusCode" = 'FAIL') AS "FailCount"
+	FROM "JobStatus"
+	WHERE "BusinessDate" BETWEEN $1 AND $2
+	GROUP BY "ApplicationId", "BusinessDate"
+), "Runs" AS (
+	SELECT "ApplicationId", "JobId", "BusinessDate", "RunId",
+		MIN("JobStatusTimestamp") FILTER (WHERE "JobStatusCode" = 'START') AS "StartTs",
+		MAX("JobStatusTimestamp") FILTER (WHERE "JobStatusCode" IN ('SUCCEED', 'FAIL')) AS "EndTs",
+		(ARRAY_AGG("JobStatusCode" ORDER BY "JobStatusTimestamp" DESC) FILTER (WHERE "JobStatusCode" IN ('SUCCEED', 'FAIL')))[1] AS "EndCode"
+	FROM "JobStatus"
+	WHERE "BusinessDate" BETWEEN $1 AND $2
+	GROUP BY "ApplicationId", "JobId", "BusinessDate", "RunId"
+), "RunDurations" AS (
+	SELECT "ApplicationId", "BusinessDate",
+		COUNT(*) AS "RunCount",
+		COUNT("EndTs" - "StartTs") AS "CompletedRunCount",
+		COALESCE(SUM(EXTRACT(EPOCH FROM "EndTs" - "StartTs") * 1000), 0)::bigint AS "TotalDurationMs",
+		COALESCE(MIN(EXTRACT(EPOCH FROM "EndTs" - "StartTs") * 1000), 0)::bigint AS "MinDurationMs",
+		COALESCE(MAX(EXTRACT(EPOCH FROM "EndTs" - "StartTs") * 1000), 0)::bigint AS "MaxDurationMs"
+	FROM "Runs"
+	GROUP BY "ApplicationId", "BusinessDate"
+), "RunCosts" AS (
+	SELECT r."ApplicationId", r."BusinessDate",
+		SUM(rc."ComputeHours") AS "ComputeHours",
+		SUM(rc."CostUsd")::double precision AS "CostUsd",
+		COALESCE(SUM(rc."CostUsd") FILTER (WHERE r."EndCode" = 'FAIL'), 0)::double precision AS "FailedCostUsd"
+	FROM "Runs" r
+		JOIN "RunCost" rc ON rc."JobId" = r."JobId" AND rc."BusinessDate" = r."BusinessDate" AND rc."RunId" = r."RunId"
+	GROUP BY r."ApplicationId", r."BusinessDate"
+)
+INSERT INTO "JobStatusDailyRollup" ("ApplicationId", "BusinessDate", "StartCount", "SucceedCount", "FailCount",
+	"RunCount", "CompletedRunCount", "TotalDurationMs", "MinDurationMs", "MaxDurationMs",
+	"ComputeHours", "CostUsd", "FailedCostUsd", "RolledUpTimestamp")
+SELECT sc."ApplicationId", sc."BusinessDate", sc."StartCount", sc."SucceedCount", sc."FailCount",
+	rd."RunCount", rd."CompletedRunCount", rd."TotalDurationMs", rd."MinDurationMs", rd."MaxDurationMs",
+	COALESCE(c."ComputeHours", 0), COALESCE(c."CostUsd", 0), COALESCE(c."FailedCostUsd", 0), now()
+FROM "StatusCounts" sc
+	JOIN "RunDurations" rd ON rd."ApplicationId" = sc."ApplicationId" AND rd."BusinessDate" = sc."BusinessDate"
+	LEFT JOIN "RunCosts" c ON c."ApplicationId" = sc."ApplicationId" AND c."BusinessDate" = sc."BusinessDate"
+ON CONFLICT ("ApplicationId", "BusinessDate") DO UPDATE SET
+	"StartCount" = EXCLUDED."StartCount",
+	"SucceedCount" = EXCLUDED."SucceedCount",
+	"FailCount" = EXCLUDED."FailCount",
+	"RunCount" = EXCLUDED."RunCount",
+	"CompletedRunCount" = EXCLUDED."CompletedRunCount",
+	"TotalDurationMs" = EXCLUDED."TotalDurationMs",
+	"MinDurationMs" = EXCLUDED."MinDurationMs",
+	"MaxDurationMs" = EXCLUDED."MaxDurationMs",
+	"ComputeHours" = EXCLUDED."ComputeHours",
+	"CostUsd" = EXCLUDED."CostUsd",
+	"FailedCostUsd" = EXCLUDED."FailedCostUsd",
+	"RolledUpTimestamp" = EXCLUDED."RolledUpTimestamp"`
+
+func (repo *repoDB) RollupDaily(ctx context.Context, fromDate time.Time, toDate time.Time) (int64, error) {
+	result, err := repo.DB.ExecContext(ctx, rollupDailySql, fromDate, toDate)
+	if err != nil {
+		return 0, common.PgErrToCommon(err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, common.PgErrToCommon(err)
+	}
+	return n, nil
+}
+
+const selectDailyRollupSql = `SELECT "ApplicationId", "BusinessDate", "StartCount", "SucceedCount", "FailCount",
+	"RunCount", "CompletedRunCount", "TotalDurationMs", "MinDurationMs", "MaxDurationMs",
+	"ComputeHours", "CostUsd", "FailedCostUsd", "RolledUpTimestamp"
+	FROM "JobStatusDailyRollup"
+	WHERE "BusinessDate" BETWEEN $1 AND $2 AND ($3 = '' OR "ApplicationId" = $3)
+	ORDER BY "ApplicationId", "BusinessDate"`
+
+func (repo *repoDB) GetDailyRollups(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time) ([]jobStatus.DailyRollup, error) {
+	rows, err := repo.DB.QueryContext(ctx, selectDailyRollupSql, fromDate, toDate, applicationId)
+	if err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	var result []jobStatus.DailyRollup
+	for rows.Next() {
+		var dr jobStatus.DailyRollup
+		var totalMs, minMs, maxMs int64
+		err := rows.Scan(&dr.ApplicationId, &dr.BusinessDate, &dr.StartCount, &dr.SucceedCount, &dr.FailCount,
+			&dr.RunCount, &dr.CompletedRunCount, &totalMs, &minMs, &maxMs,
+			&dr.ComputeHours, &dr.CostUsd, &dr.FailedCostUsd, &dr.RolledUpTimestamp)
+		if err != nil {
+			return nil, common.PgErrToCommon(err)
+		}
+		dr.BusinessDate = jobStatus.TruncateToDate(dr.BusinessDate)
+		dr.TotalDuration = time.Duration(totalMs) * time.Millisecond
+		dr.MinDuration = time.Duration(minMs) * time.Millisecond
+		dr.MaxDuration = time.Duration(maxMs) * time.Millisecond
+		result = append(result, dr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,21 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbcommon"
+)
+
+// dialect is Postgres's: double-quoted names and numbered $n parameters. ne is IS DISTINCT
+// FROM, and prefix is LIKE with backslash, Postgres's default escape. pgx sends and scans dates,
+// times, and jsonb as they are.
+var dialect = dbcommon.Dialect{
+	QuoteIdent:  func(name string) string { return `"` + name + `"` },
+	Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+	NotEqual:    func(col string, param string) string { return col + " IS DISTINCT FROM " + param },
+	Prefix: func(col string, prefix string, param func(any) string) string {
+		return col + " LIKE " + param(dbcommon.EscapeLike(prefix)+"%")
+	},
+	ErrToCommon: common.PgErrToCommon,
+}
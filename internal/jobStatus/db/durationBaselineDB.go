@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// durationBaselineSql is jobStatus.ComputeDurationBaselines in SQL. Each successful run is
+// counted once per season it's in (see jobStatus.SeasonsOf). to_char's Dy is English without
+// the TM prefix, so it matches Go's weekday abbreviations.
+const durationBaselineSql = `WITH "Runs" AS (
+	SELECT "JobId", "BusinessDate",
+		MIN("JobStatusTimestamp") FILTER (WHERE "JobStatusCode" = 'START') AS "StartTs",
+		MAX("JobStatusTimestamp") FILTER (WHERE "JobStatusCode" = 'SUCCEED') AS "EndTs"
+	FROM "JobStatus"
+	WHERE "BusinessDate" BETWEEN $1 AND $2 AND "ApplicationId" = $3 AND ($4 = '' OR "JobId" = $4)
+	GROUP BY "JobId", "BusinessDate", "RunId"
+), "Completed" AS (
+	SELECT "JobId", "BusinessDate", EXTRACT(ISODOW FROM "BusinessDate") AS "Dow",
+		EXTRACT(EPOCH FROM "EndTs" - "StartTs") * 1000 AS "DurationMs"
+	FROM "Runs"
+	WHERE "EndTs" > "StartTs"
+), "Seasonal" AS (
+	SELECT "JobId", 'All' AS "Season", "DurationMs" FROM "Completed"
+	UNION ALL
+	SELECT "JobId", to_char("BusinessDate", 'Dy'), "DurationMs" FROM "Completed"
+	UNION ALL
+	SELECT "JobId", 'FirstBusinessDay', "DurationMs" FROM "Completed"
+	WHERE "Dow" <= 5 AND (EXTRACT(DAY FROM "BusinessDate") = 1 OR ("Dow" = 1 AND EXTRACT(DAY FROM "BusinessDate") <= 3))
+	UNION ALL
+	SELECT "JobId", 'LastBusinessDay', "DurationMs" FROM "Completed"
+	WHERE "Dow" <= 5 AND (EXTRACT(MONTH FROM "BusinessDate" + 1) <> EXTRACT(MONTH FROM "BusinessDate")
+		OR ("Dow" = 5 AND EXTRACT(MONTH FROM "BusinessDate" + 3) <> EXTRACT(MONTH FROM "BusinessDate")))
+)
+SELECT "JobId", "Season", COUNT(*),
+	percentile_cont(0.5) WITHIN GROUP (ORDER BY "DurationMs")::bigint,
+	percentile_cont(0.9) WITHIN GROUP (ORDER BY "DurationMs")::bigint
+FROM "Seasonal"
+GROUP BY "JobId", "Season"
+ORDER BY "JobId", "Season"`
+
+func (repo *repoDB) GetDurationBaselines(ctx context.Context, applicationId string, jobId jobStatus.JobIdType, fromDate time.Time, toDate time.Time) ([]jobStatus.DurationBaseline, error) {
+	rows, err := repo.DB.QueryContext(ctx, durationBaselineSql, fromDate, toDate, applicationId, string(jobId))
+	if err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	var result []jobStatus.DurationBaseline
+	for rows.Next() {
+		b := jobStatus.DurationBaseline{ApplicationId: applicationId}
+		var medianMs, p90Ms int64
+		if err := rows.Scan(&b.JobId, &b.Season, &b.RunCount, &medianMs, &p90Ms); err != nil {
+			return nil, common.PgErrToCommon(err)
+		}
+		b.Median = time.Duration(medianMs) * time.Millisecond
+		b.P90 = time.Duration(p90Ms) * time.Millisecond
+		result = append(result, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	return result, nil
+}
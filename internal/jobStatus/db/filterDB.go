@@ -0,0 +1,24 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// GetByFilters needs at least one filter; it doesn't scan the whole table.
+func (repo *repoDB) GetByFilters(ctx context.Context, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	if len(opts.Filters) == 0 {
+		return nil, common.NewCommonError(common.ErrcdDomainProps, errors.New("no filters"))
+	}
+	return repo.selectDB(ctx, opts, "")
+}
+
+func (repo *repoDB) ForEachByFilters(ctx context.Context, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	if len(opts.Filters) == 0 {
+		return common.NewCommonError(common.ErrcdDomainProps, errors.New("no filters"))
+	}
+	return repo.forEachDB(ctx, opts, fn, "")
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// recordHeartbeatSql keeps the later heartbeat, so heartbeats that arrive out of order or are
+// retried don't move it back.
+var recordHeartbeatSql = `UPDATE "JobStatus"
+	SET "HeartbeatTimestamp" = GREATEST("HeartbeatTimestamp", $5)
+	WHERE "JobId" = $1 AND "JobStatusCode" = $2 AND "BusinessDate" = $3 AND "RunId" = $4
+	RETURNING ` + allColumns()
+
+func (repo *repoDB) RecordHeartbeat(ctx context.Context, key jobStatus.StatusKey, at time.Time) (jobStatus.JobStatus, error) {
+	rows, err := repo.DB.QueryContext(ctx, recordHeartbeatSql,
+		string(key.JobId), string(jobStatus.JobStatus_START), key.BusinessDate, string(key.RunId), at)
+	if err != nil {
+		return jobStatus.JobStatus{}, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+	found, err := dialect.RowsToDomain(rows, jobStatus.AllFields, false)
+	if err != nil {
+		return jobStatus.JobStatus{}, err
+	}
+	if len(found) == 0 {
+		return jobStatus.JobStatus{}, common.NewCommonError(common.ErrcdNotFound, fmt.Errorf("no START status for job %s run %s on %s", key.JobId, key.RunId, key.BusinessDate.Format("2006-01-02")))
+	}
+	return found[0], nil
+}
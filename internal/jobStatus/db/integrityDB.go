@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbcommon"
+)
+
+func (repo *repoDB) ForEachWithIntegrityHash(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time, fn func(jobStatus.JobStatus) error) error {
+	cols, _ := dialect.Columns(jobStatus.AllFields)
+	query := `SELECT ` + strings.Join(cols, ", ") + `, "IntegrityHash" FROM "JobStatus"
+		WHERE "ApplicationId" = $1 AND "BusinessDate" BETWEEN $2 AND $3
+		ORDER BY "BusinessDate", "StatusId"`
+
+	rows, err := repo.DB.QueryContext(ctx, query, applicationId, fromDate, toDate)
+	if err != nil {
+		return common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row dbcommon.Row
+		var hash []byte
+		if err := rows.Scan(append(dialect.ScanTargets(&row, jobStatus.AllFields), &hash)...); err != nil {
+			return common.NewCommonError(common.ErrcdRepoRowConversion, err)
+		}
+		js, err := dbcommon.RowToDomain(row, jobStatus.AllFields)
+		if err != nil {
+			return err
+		}
+		js.IntegrityHash = hash
+		if err := fn(js); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
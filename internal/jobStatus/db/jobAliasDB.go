@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+func (repo *repoDB) PutJobAlias(ctx context.Context, alias jobStatus.JobAlias) error {
+	if _, err := repo.DB.ExecContext(ctx, addAliasSql, string(alias.AliasJobId), string(alias.CanonicalJobId), alias.CreatedTs); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+const listJobAliasesSql = `SELECT "AliasJobId", "CanonicalJobId", "CreatedTimestamp" FROM "JobAlias" ORDER BY "AliasJobId"`
+
+func (repo *repoDB) ListJobAliases(ctx context.Context) ([]jobStatus.JobAlias, error) {
+	return listJobAliases(ctx, repo.DB)
+}
+
+func listJobAliases(ctx context.Context, q querier) ([]jobStatus.JobAlias, error) {
+	rows, err := q.QueryContext(ctx, listJobAliasesSql)
+	if err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	var result []jobStatus.JobAlias
+	for rows.Next() {
+		var alias jobStatus.JobAlias
+		var aliasId, canonicalId string
+		if err := rows.Scan(&aliasId, &canonicalId, &alias.CreatedTs); err != nil {
+			return nil, common.NewCommonError(common.ErrcdRepoRowConversion, err)
+		}
+		alias.AliasJobId = jobStatus.JobIdType(aliasId)
+		alias.CanonicalJobId = jobStatus.JobIdType(canonicalId)
+		result = append(result, alias)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	return result, nil
+}
+
+func (repo *repoDB) DeleteJobAlias(ctx context.Context, aliasJobId jobStatus.JobIdType) (bool, error) {
+	res, err := repo.DB.ExecContext(ctx, `DELETE FROM "JobAlias" WHERE "AliasJobId" = $1`, string(aliasJobId))
+	if err != nil {
+		return false, common.PgErrToCommon(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, common.PgErrToCommon(err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+const isJobAliasSql = `SELECT EXISTS (SELECT 1 FROM "JobAlias" WHERE "AliasJobId" = $1)`
+
+// countRenameCollisionsSql counts statuses under $1 whose natural key already exists under $2.
+const countRenameCollisionsSql = `SELECT COUNT(*) FROM "JobStatus" o
+	JOIN "JobStatus" n ON n."JobId" = $2
+		AND n."JobStatusCode" = o."JobStatusCode"
+		AND n."BusinessDate" = o."BusinessDate"
+		AND n."RunId" = o."RunId"
+	WHERE o."JobId" = $1`
+
+const moveStatusesSql = `UPDATE "JobStatus" SET "JobId" = $2 WHERE "JobId" = $1`
+
+const repointAliasesSql = `UPDATE "JobAlias" SET "CanonicalJobId" = $2 WHERE "CanonicalJobId" = $1`
+
+const addAliasSql = `INSERT INTO "JobAlias" ("AliasJobId", "CanonicalJobId", "CreatedTimestamp")
+	VALUES ($1, $2, $3)
+	ON CONFLICT ("AliasJobId") DO UPDATE SET
+		"CanonicalJobId" = EXCLUDED."CanonicalJobId",
+		"CreatedTimestamp" = EXCLUDED."CreatedTimestamp"`
+
+// RenameJob runs in one transaction. Saved view JobIds are in jsonb, so views are locked,
+// rewritten in Go, and updated inside the same transaction.
+func (repo *repoDB) RenameJob(ctx context.Context, from jobStatus.JobIdType, to jobStatus.JobIdType, at time.Time) (jobStatus.JobRenameResult, error) {
+	var result jobStatus.JobRenameResult
+
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return result, common.PgErrToCommon(err)
+	}
+	defer tx.Rollback()
+
+	var toIsAlias bool
+	if err := tx.QueryRowContext(ctx, isJobAliasSql, string(to)).Scan(&toIsAlias); err != nil {
+		return result, common.PgErrToCommon(err)
+	}
+	if toIsAlias {
+		return result, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("%q is an alias; rename to its canonical JobId", to))
+	}
+
+	var collisions int64
+	if err := tx.QueryRowContext(ctx, countRenameCollisionsSql, string(from), string(to)).Scan(&collisions); err != nil {
+		return result, common.PgErrToCommon(err)
+	}
+	if collisions > 0 {
+		return result, common.NewCommonError(common.ErrcdRepoDupeRow, fmt.Errorf("%d status(es) for %q duplicate statuses already under %q", collisions, from, to))
+	}
+
+	if result.StatusesMoved, err = execCount(ctx, tx, moveStatusesSql, string(from), string(to)); err != nil {
+		return result, err
+	}
+	if result.AliasesUpdated, err = execCount(ctx, tx, repointAliasesSql, string(from), string(to)); err != nil {
+		return result, err
+	}
+	if _, err := tx.ExecContext(ctx, addAliasSql, string(from), string(to), at); err != nil {
+		return result, common.PgErrToCommon(err)
+	}
+	if result.ViewsUpdated, err = renameInSavedViews(ctx, tx, from, to); err != nil {
+		return result, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, common.PgErrToCommon(err)
+	}
+	return result, nil
+}
+
+func execCount(ctx context.Context, tx *sql.Tx, query string, args ...any) (int64, error) {
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, common.PgErrToCommon(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, common.PgErrToCommon(err)
+	}
+	return n, nil
+}
+
+func renameInSavedViews(ctx context.Context, tx *sql.Tx, from jobStatus.JobIdType, to jobStatus.JobIdType) (int64, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT "Name", "Filters" FROM "SavedView" FOR UPDATE`)
+	if err != nil {
+		return 0, common.PgErrToCommon(err)
+	}
+	changed := map[string][]byte{}
+	for rows.Next() {
+		var name string
+		var filtersJson []byte
+		if err := rows.Scan(&name, &filtersJson); err != nil {
+			rows.Close()
+			return 0, common.NewCommonError(common.ErrcdRepoRowConversion, err)
+		}
+		var filters savedViewFilters
+		if err := json.Unmarshal(filtersJson, &filters); err != nil {
+			rows.Close()
+			return 0, common.NewCommonError(common.ErrcdRepoRowConversion, err)
+		}
+		renamed, ok := jobStatus.RenameJobIds(filters.JobIds, from, to)
+		if !ok {
+			continue
+		}
+		filters.JobIds = renamed
+		if changed[name], err = json.Marshal(filters); err != nil {
+			rows.Close()
+			return 0, common.NewCommonError(common.ErrcdRepoOther, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, common.PgErrToCommon(err)
+	}
+	rows.Close()
+
+	for name, filtersJson := range changed {
+		if _, err := tx.ExecContext(ctx, `UPDATE "SavedView" SET "Filters" = $2 WHERE "Name" = $1`, name, filtersJson); err != nil {
+			return 0, common.PgErrToCommon(err)
+		}
+	}
+	return int64(len(changed)), nil
+}
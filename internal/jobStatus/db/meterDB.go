@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// "ApiCallMeter" holds one row per application, month, and endpoint; flushes add to CallCount.
+const addApiCallsSql = `INSERT INTO "ApiCallMeter" ("ApplicationId", "Month", "Endpoint", "CallCount")
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT ("ApplicationId", "Month", "Endpoint") DO UPDATE SET
+		"CallCount" = "ApiCallMeter"."CallCount" + EXCLUDED."CallCount"`
+
+// AddApiCalls adds all counts in one transaction so a failed flush doesn't add some of them twice.
+func (repo *repoDB) AddApiCalls(ctx context.Context, counts []jobStatus.ApiCallCount) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return common.PgErrToCommon(err)
+	}
+	defer tx.Rollback()
+
+	for _, c := range counts {
+		if _, err := tx.ExecContext(ctx, addApiCallsSql, c.ApplicationId, c.Month, c.Endpoint, c.Count); err != nil {
+			return common.PgErrToCommon(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+const getApiCallsSql = `SELECT "ApplicationId", "Month", "Endpoint", "CallCount" FROM "ApiCallMeter" WHERE "Month" = $1`
+
+func (repo *repoDB) GetApiCalls(ctx context.Context, month time.Time) ([]jobStatus.ApiCallCount, error) {
+	rows, err := repo.DB.QueryContext(ctx, getApiCallsSql, month)
+	if err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	var result []jobStatus.ApiCallCount
+	for rows.Next() {
+		var c jobStatus.ApiCallCount
+		if err := rows.Scan(&c.ApplicationId, &c.Month, &c.Endpoint, &c.Count); err != nil {
+			return nil, common.NewCommonError(common.ErrcdRepoRowConversion, err)
+		}
+		result = append(result, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,368 @@
+// Package migrations creates and changes the Postgres schema the db package uses. Each version is
+// an embedded sql/NNNN_name.up.sql file, with an optional NNNN_name.down.sql that undoes it.
+// Applied versions are recorded in the SchemaMigration table with a checksum of their up file.
+// An applied version's file must not change; Migrate and Rollback refuse to run if one has (see
+// common.ErrcdSchemaDrift). Change the schema with a new version instead.
+//
+// Versions 1 and 2 are the schema as it stood when migrations were introduced. Their statements
+// are safe to repeat, so a database created by hand adopts them without changes.
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockKey is the Postgres advisory lock held while migrating, so servers starting together don't
+// apply the same version twice.
+const lockKey = 7_105_118_001
+
+const createVersionTableSql = `
+	CREATE TABLE IF NOT EXISTS "public"."SchemaMigration" (
+		"Version" integer NOT NULL,
+		"Name" character varying(200) NOT NULL,
+		"AppliedTimestamp" timestamptz NOT NULL,
+		CONSTRAINT "SchemaMigration_pk" PRIMARY KEY ("Version")
+	)`
+
+// the checksum column was added after the table; it's null for versions applied before then
+// until Migrate records them
+const addChecksumColumnSql = `ALTER TABLE "public"."SchemaMigration" ADD COLUMN IF NOT EXISTS "Checksum" character varying(64) NULL`
+
+// Migration is one schema version.
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+// Reversible is true if the version has a down file.
+func (m Migration) Reversible() bool {
+	return m.down != ""
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// Checksum is the hex SHA-256 of the up file.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.up))
+	return hex.EncodeToString(sum[:])
+}
+
+// Status is a version and when it was applied. Applied is zero for versions not applied yet.
+// AppliedChecksum is the checksum recorded when it was applied; it's empty if it wasn't applied
+// or was applied before checksums were recorded.
+type Status struct {
+	Migration
+	Applied         time.Time
+	AppliedChecksum string
+}
+
+// Drifted is true if the version's up file changed after it was applied.
+func (s Status) Drifted() bool {
+	return s.AppliedChecksum != "" && s.AppliedChecksum != s.Checksum()
+}
+
+// appliedVersion is when a version was applied and its checksum then.
+type appliedVersion struct {
+	at       time.Time
+	checksum string
+}
+
+// All returns the embedded versions in order.
+func All() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		base, direction, ok := strings.Cut(strings.TrimSuffix(e.Name(), ".sql"), ".")
+		num, name, hasName := strings.Cut(base, "_")
+		version, err := strconv.Atoi(num)
+		if !ok || !hasName || err != nil || version <= 0 || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("migration file %q isn't named like 0001_name.up.sql", e.Name())
+		}
+		body, err := files.ReadFile("sql/" + e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration version %d has two names, %q and %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.up = string(body)
+		} else {
+			m.down = string(body)
+		}
+	}
+
+	all := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("migration %s has no up file", m)
+		}
+		all = append(all, *m)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	return all, nil
+}
+
+// Latest returns the highest embedded version.
+func Latest() (int, error) {
+	all, err := All()
+	if err != nil || len(all) == 0 {
+		return 0, err
+	}
+	return all[len(all)-1].Version, nil
+}
+
+// Migrate applies every version not applied yet.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	latest, err := Latest()
+	if err != nil {
+		return err
+	}
+	return MigrateTo(ctx, db, latest)
+}
+
+// MigrateTo applies versions up to and including to that aren't applied yet, oldest first. Each
+// version runs in its own transaction with its SchemaMigration row, so a failed version leaves
+// the database at the version before it.
+func MigrateTo(ctx context.Context, db *sql.DB, to int) error {
+	all, err := All()
+	if err != nil {
+		return err
+	}
+	return withLock(ctx, db, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := checkDrift(all, applied); err != nil {
+			return err
+		}
+		if err := recordChecksums(ctx, conn, all, applied); err != nil {
+			return err
+		}
+		for _, m := range all {
+			if m.Version > to || !applied[m.Version].at.IsZero() {
+				continue
+			}
+			if err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, m.up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx,
+					`INSERT INTO "public"."SchemaMigration" ("Version", "Name", "AppliedTimestamp", "Checksum") VALUES ($1, $2, $3, $4)`,
+					m.Version, m.Name, time.Now().UTC(), m.Checksum())
+				return err
+			}); err != nil {
+				return fmt.Errorf("migration %s up: %w", m, err)
+			}
+			log.Printf("migration %s applied", m)
+		}
+		return nil
+	})
+}
+
+// RollbackFloor returns the lowest version Rollback can roll back to: the newest version with no
+// down file, or 0 if every version has one.
+func RollbackFloor() (int, error) {
+	all, err := All()
+	if err != nil {
+		return 0, err
+	}
+	return rollbackFloor(all).Version, nil
+}
+
+// rollbackFloor is the newest version in all with no down file, or a zero Migration.
+func rollbackFloor(all []Migration) Migration {
+	for i := len(all) - 1; i >= 0; i-- {
+		if !all[i].Reversible() {
+			return all[i]
+		}
+	}
+	return Migration{}
+}
+
+// Rollback undoes applied versions after to, newest first, each in its own transaction. It
+// changes nothing, and doesn't connect, if to is below RollbackFloor.
+func Rollback(ctx context.Context, db *sql.DB, to int) error {
+	all, err := All()
+	if err != nil {
+		return err
+	}
+	if floor := rollbackFloor(all); to < floor.Version {
+		return fmt.Errorf("can't roll back to %d: migration %s has no down file, so %d is the lowest version to roll back to", to, floor, floor.Version)
+	}
+	return withLock(ctx, db, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := checkDrift(all, applied); err != nil {
+			return err
+		}
+
+		var undo []Migration
+		for i := len(all) - 1; i >= 0; i-- {
+			m := all[i]
+			if m.Version <= to || applied[m.Version].at.IsZero() {
+				continue
+			}
+			undo = append(undo, m)
+		}
+
+		for _, m := range undo {
+			if err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, m.down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `DELETE FROM "public"."SchemaMigration" WHERE "Version" = $1`, m.Version)
+				return err
+			}); err != nil {
+				return fmt.Errorf("migration %s down: %w", m, err)
+			}
+			log.Printf("migration %s rolled back", m)
+		}
+		return nil
+	})
+}
+
+// Statuses reports every embedded version and whether it's applied.
+func Statuses(ctx context.Context, db *sql.DB) ([]Status, error) {
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, len(all))
+	for i, m := range all {
+		statuses[i] = Status{Migration: m, Applied: applied[m.Version].at, AppliedChecksum: applied[m.Version].checksum}
+	}
+	return statuses, nil
+}
+
+// withLock runs fn on one connection while holding the migration lock.
+func withLock(ctx context.Context, db *sql.DB, fn func(conn *sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+
+	return fn(conn)
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// appliedVersions creates SchemaMigration if it's missing and returns when each version in it
+// was applied, with its checksum.
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]appliedVersion, error) {
+	if _, err := conn.ExecContext(ctx, createVersionTableSql); err != nil {
+		return nil, fmt.Errorf("migration table: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, addChecksumColumnSql); err != nil {
+		return nil, fmt.Errorf("migration table: %w", err)
+	}
+	rows, err := conn.QueryContext(ctx, `SELECT "Version", "AppliedTimestamp", "Checksum" FROM "public"."SchemaMigration"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := map[int]appliedVersion{}
+	for rows.Next() {
+		var version int
+		var ts time.Time
+		var checksum sql.NullString
+		if err := rows.Scan(&version, &ts, &checksum); err != nil {
+			return nil, err
+		}
+		versions[version] = appliedVersion{at: ts, checksum: checksum.String}
+	}
+	return versions, rows.Err()
+}
+
+// checkDrift returns a CommonError coded ErrcdSchemaDrift, naming every applied version whose up
+// file changed after it was applied. Applied versions without a checksum, and versions newer
+// than this build, can't be checked.
+func checkDrift(all []Migration, versions map[int]appliedVersion) error {
+	var drifted []string
+	for _, m := range all {
+		if recorded := versions[m.Version].checksum; recorded != "" && recorded != m.Checksum() {
+			drifted = append(drifted, fmt.Sprintf("%s (applied %.12s, file %.12s)", m, recorded, m.Checksum()))
+		}
+	}
+	if len(drifted) == 0 {
+		return nil
+	}
+	return common.NewCommonError(common.ErrcdSchemaDrift,
+		fmt.Errorf("applied migrations changed since they were applied: %s; restore the files and change the schema with a new version", strings.Join(drifted, ", ")))
+}
+
+// recordChecksums records the checksums of versions applied before checksums were, trusting
+// that their files haven't changed since.
+func recordChecksums(ctx context.Context, conn *sql.Conn, all []Migration, versions map[int]appliedVersion) error {
+	for _, m := range all {
+		v, ok := versions[m.Version]
+		if !ok || v.checksum != "" {
+			continue
+		}
+		if _, err := conn.ExecContext(ctx, `UPDATE "public"."SchemaMigration" SET "Checksum" = $1 WHERE "Version" = $2 AND "Checksum" IS NULL`,
+			m.Checksum(), m.Version); err != nil {
+			return fmt.Errorf("migration %s checksum: %w", m, err)
+		}
+		log.Printf("migration %s checksum recorded", m)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	all, err := All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range all {
+		if m.Version != i+1 {
+			t.Errorf("%s: got version %d, want %d; versions must be numbered without gaps", m, m.Version, i+1)
+		}
+	}
+}
+
+func TestRollbackFloor(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		versions []Migration
+		want     int
+	}{
+		{"none", nil, 0},
+		{"all reversible", []Migration{{Version: 1, down: "x"}, {Version: 2, down: "x"}}, 0},
+		{"first isn't", []Migration{{Version: 1}, {Version: 2, down: "x"}}, 1},
+		{"newest that isn't", []Migration{{Version: 1}, {Version: 2}, {Version: 3, down: "x"}}, 2},
+	} {
+		if got := rollbackFloor(tt.versions).Version; got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	floor, err := RollbackFloor()
+	if err != nil || floor != 1 {
+		t.Errorf("embedded: got %d, %v, want 1 because 0001 has no down file", floor, err)
+	}
+}
+
+func TestRollbackBelowFloor(t *testing.T) {
+	// a nil db shows Rollback refuses before it connects
+	err := Rollback(context.Background(), nil, 0)
+	if err == nil || !strings.Contains(err.Error(), "0001_job_status has no down file") {
+		t.Errorf("got %v, want a refusal naming 0001_job_status", err)
+	}
+}
@@ -0,0 +1,26 @@
+-- JobStatus as it stands when migrations were introduced. Databases created by hand before then
+-- have some of this already, so every statement is safe to repeat.
+
+CREATE TABLE IF NOT EXISTS "public"."JobStatus" (
+    "StatusId" uuid NOT NULL,
+    "ApplicationId" character varying(200) NOT NULL,
+    "JobId" character varying(200) NOT NULL,
+    "JobStatusCode" character varying(10) NOT NULL,
+    "JobStatusTimestamp" timestamptz NOT NULL,
+    "BusinessDate" date NOT NULL,
+    "RunId" character varying(50) NOT NULL,
+    "HostId" character varying(150) NOT NULL,
+    CONSTRAINT "JobStatus_pk" PRIMARY KEY ("JobId", "JobStatusCode", "BusinessDate", "RunId")
+);
+
+-- columns added after the table was first created by hand
+ALTER TABLE "public"."JobStatus" ADD COLUMN IF NOT EXISTS "StatusId" uuid NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE "public"."JobStatus" ALTER COLUMN "StatusId" DROP DEFAULT;
+ALTER TABLE "public"."JobStatus" ADD COLUMN IF NOT EXISTS "ReportedJobId" character varying(200) NULL;
+ALTER TABLE "public"."JobStatus" ADD COLUMN IF NOT EXISTS "IntegrityHash" bytea NULL;
+ALTER TABLE "public"."JobStatus" ADD COLUMN IF NOT EXISTS "ReceivedTimestamp" timestamptz NULL;
+ALTER TABLE "public"."JobStatus" ADD COLUMN IF NOT EXISTS "Links" jsonb NULL;
+
+CREATE INDEX IF NOT EXISTS "JobStatus_BusinessDate_ApplicationId" ON "public"."JobStatus" ("BusinessDate", "ApplicationId");
+CREATE UNIQUE INDEX IF NOT EXISTS "JobStatus_StatusId" ON "public"."JobStatus" ("StatusId");
+CREATE INDEX IF NOT EXISTS "JobStatus_JobId_pattern" ON "public"."JobStatus" ("JobId" varchar_pattern_ops);
@@ -0,0 +1,12 @@
+-- Drops the feature tables and everything in them. JobStatus is kept. The rollup can be
+-- recomputed from JobStatus; comments, costs, views, schedules, meters, and aliases can't.
+
+DROP TABLE IF EXISTS "public"."JobAlias";
+DROP TABLE IF EXISTS "public"."MigrationCheckpoint";
+DROP TABLE IF EXISTS "public"."ApiCallMeter";
+DROP TABLE IF EXISTS "public"."SelfTestProbe";
+DROP TABLE IF EXISTS "public"."ScheduledQuery";
+DROP TABLE IF EXISTS "public"."SavedView";
+DROP TABLE IF EXISTS "public"."RunComment";
+DROP TABLE IF EXISTS "public"."RunCost";
+DROP TABLE IF EXISTS "public"."JobStatusDailyRollup";
@@ -0,0 +1,103 @@
+-- Tables features added alongside JobStatus, as they stand when migrations were introduced.
+-- Databases created by hand before then have some of these already, so every statement is safe
+-- to repeat.
+
+CREATE TABLE IF NOT EXISTS "public"."JobStatusDailyRollup" (
+    "ApplicationId" character varying(200) NOT NULL,
+    "BusinessDate" date NOT NULL,
+    "StartCount" bigint NOT NULL,
+    "SucceedCount" bigint NOT NULL,
+    "FailCount" bigint NOT NULL,
+    "RunCount" bigint NOT NULL,
+    "CompletedRunCount" bigint NOT NULL,
+    "TotalDurationMs" bigint NOT NULL,
+    "MinDurationMs" bigint NOT NULL,
+    "MaxDurationMs" bigint NOT NULL,
+    "RolledUpTimestamp" timestamptz NOT NULL,
+    CONSTRAINT "JobStatusDailyRollup_pk" PRIMARY KEY ("ApplicationId", "BusinessDate")
+);
+
+ALTER TABLE "public"."JobStatusDailyRollup"
+    ADD COLUMN IF NOT EXISTS "ComputeHours" double precision NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "CostUsd" double precision NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "FailedCostUsd" double precision NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "public"."RunCost" (
+    "ApplicationId" character varying(200) NOT NULL,
+    "JobId" character varying(200) NOT NULL,
+    "BusinessDate" date NOT NULL,
+    "RunId" character varying(200) NOT NULL,
+    "ComputeHours" double precision NOT NULL,
+    "CostUsd" numeric(14, 4) NOT NULL,
+    "UpdatedTimestamp" timestamptz NOT NULL,
+    CONSTRAINT "RunCost_pk" PRIMARY KEY ("JobId", "BusinessDate", "RunId")
+);
+
+CREATE TABLE IF NOT EXISTS "public"."RunComment" (
+    "CommentId" uuid NOT NULL,
+    "JobId" character varying(200) NOT NULL,
+    "BusinessDate" date NOT NULL,
+    "RunId" character varying(200) NOT NULL,
+    "Author" character varying(200) NOT NULL,
+    "Body" text NOT NULL,
+    "CreatedTimestamp" timestamptz NOT NULL,
+    CONSTRAINT "RunComment_pk" PRIMARY KEY ("CommentId")
+);
+
+CREATE INDEX IF NOT EXISTS "RunComment_JobId_BusinessDate_RunId" ON "public"."RunComment" USING btree ("JobId", "BusinessDate", "RunId");
+
+CREATE TABLE IF NOT EXISTS "public"."SavedView" (
+    "Name" character varying(100) NOT NULL,
+    "Team" character varying(100) NOT NULL,
+    "Shared" boolean NOT NULL,
+    "Filters" jsonb NOT NULL,
+    "Fields" character varying(500) NOT NULL,
+    "Sort" character varying(500) NOT NULL,
+    "UpdatedTimestamp" timestamptz NOT NULL,
+    CONSTRAINT "SavedView_pk" PRIMARY KEY ("Name")
+);
+
+CREATE TABLE IF NOT EXISTS "public"."ScheduledQuery" (
+    "Name" character varying(100) NOT NULL,
+    "Team" character varying(100) NOT NULL,
+    "View" character varying(100) NULL,
+    "Filters" jsonb NOT NULL,
+    "BusinessDateOffset" integer NOT NULL,
+    "RunAtHour" integer NOT NULL,
+    "RunAtMinute" integer NOT NULL,
+    "DeliveryKind" character varying(20) NOT NULL,
+    "DeliveryTarget" character varying(2000) NOT NULL,
+    "LastRunDate" date NULL,
+    "UpdatedTimestamp" timestamptz NOT NULL,
+    CONSTRAINT "ScheduledQuery_pk" PRIMARY KEY ("Name")
+);
+
+CREATE TABLE IF NOT EXISTS "public"."SelfTestProbe" (
+    "ProbeId" character varying(32) NOT NULL,
+    "CreatedTs" timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT "SelfTestProbe_pk" PRIMARY KEY ("ProbeId")
+);
+
+CREATE TABLE IF NOT EXISTS "public"."ApiCallMeter" (
+    "ApplicationId" character varying(200) NOT NULL,
+    "Month" date NOT NULL,
+    "Endpoint" character varying(100) NOT NULL,
+    "CallCount" bigint NOT NULL,
+    CONSTRAINT "ApiCallMeter_pk" PRIMARY KEY ("ApplicationId", "Month", "Endpoint")
+);
+
+CREATE TABLE IF NOT EXISTS "public"."MigrationCheckpoint" (
+    "Name" character varying(100) NOT NULL,
+    "Cursor" character varying(500) NOT NULL,
+    "ItemsDone" bigint NOT NULL,
+    "Done" boolean NOT NULL,
+    "UpdatedTimestamp" timestamptz NOT NULL,
+    CONSTRAINT "MigrationCheckpoint_pk" PRIMARY KEY ("Name")
+);
+
+CREATE TABLE IF NOT EXISTS "public"."JobAlias" (
+    "AliasJobId" character varying(200) NOT NULL,
+    "CanonicalJobId" character varying(200) NOT NULL,
+    "CreatedTimestamp" timestamptz NOT NULL,
+    CONSTRAINT "JobAlias_pk" PRIMARY KEY ("AliasJobId")
+);
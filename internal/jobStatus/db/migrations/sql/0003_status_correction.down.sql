@@ -0,0 +1,4 @@
+-- Drops the correction audit trail. The corrected statuses stay corrected; only the record of
+-- what they were before is lost.
+
+DROP TABLE IF EXISTS "public"."JobStatusCorrection";
@@ -0,0 +1,15 @@
+-- The audit trail for operators' corrections to stored statuses. Before and After hold the whole
+-- status, so a row says exactly what changed even after later corrections.
+
+CREATE TABLE IF NOT EXISTS "public"."JobStatusCorrection" (
+    "CorrectionId" uuid NOT NULL,
+    "StatusId" uuid NOT NULL,
+    "CorrectedBy" character varying(200) NOT NULL,
+    "Reason" character varying(1000) NOT NULL,
+    "CorrectedTimestamp" timestamptz NOT NULL,
+    "Before" jsonb NOT NULL,
+    "After" jsonb NOT NULL,
+    CONSTRAINT "JobStatusCorrection_pk" PRIMARY KEY ("CorrectionId")
+);
+
+CREATE INDEX IF NOT EXISTS "JobStatusCorrection_StatusId" ON "public"."JobStatusCorrection" USING btree ("StatusId", "CorrectionId");
@@ -0,0 +1,5 @@
+-- "After" can't be NOT NULL while deletions are recorded, so rolling back drops their audit
+-- rows. The deleted statuses stay deleted.
+
+DELETE FROM "public"."JobStatusCorrection" WHERE "After" IS NULL;
+ALTER TABLE "public"."JobStatusCorrection" ALTER COLUMN "After" SET NOT NULL;
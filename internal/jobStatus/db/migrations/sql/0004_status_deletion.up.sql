@@ -0,0 +1,3 @@
+-- Deletions go in the correction audit trail too, with no "After".
+
+ALTER TABLE "public"."JobStatusCorrection" ALTER COLUMN "After" DROP NOT NULL;
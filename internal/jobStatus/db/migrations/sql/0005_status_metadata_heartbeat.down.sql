@@ -0,0 +1,5 @@
+-- Rolling back drops every status's metadata and heartbeat. Statuses hashed with metadata no
+-- longer verify after that.
+
+ALTER TABLE "public"."JobStatus" DROP COLUMN "HeartbeatTimestamp";
+ALTER TABLE "public"."JobStatus" DROP COLUMN "Metadata";
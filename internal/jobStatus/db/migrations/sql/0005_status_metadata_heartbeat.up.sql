@@ -0,0 +1,5 @@
+-- Metadata is what a job attaches to a status, like goslo-run's exit code and duration.
+-- HeartbeatTimestamp is set on a run's START when the run sends a heartbeat.
+
+ALTER TABLE "public"."JobStatus" ADD COLUMN "Metadata" jsonb NULL;
+ALTER TABLE "public"."JobStatus" ADD COLUMN "HeartbeatTimestamp" timestamptz NULL;
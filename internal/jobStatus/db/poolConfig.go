@@ -0,0 +1,65 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/config"
+)
+
+// PoolConfig bounds repoDB's connection pool. Zero fields keep database/sql's defaults: no limit
+// on open connections, 2 idle connections, and connections that are never closed for age.
+type PoolConfig struct {
+	MaxOpenConns    int           `env:"GOJST_DB_MAX_OPEN_CONNS" validate:"min=0"`
+	MaxIdleConns    int           `env:"GOJST_DB_MAX_IDLE_CONNS" validate:"min=0"`
+	ConnMaxLifetime time.Duration `env:"GOJST_DB_CONN_MAX_LIFETIME" validate:"min=0s"`
+	ConnMaxIdleTime time.Duration `env:"GOJST_DB_CONN_MAX_IDLE_TIME" validate:"min=0s"`
+}
+
+// PoolConfigFromEnv reads pool settings:
+//
+//	GOJST_DB_MAX_OPEN_CONNS      most connections open at once, in use or idle
+//	GOJST_DB_MAX_IDLE_CONNS      most idle connections kept (at most GOJST_DB_MAX_OPEN_CONNS)
+//	GOJST_DB_CONN_MAX_LIFETIME   close connections this old, like 30m
+//	GOJST_DB_CONN_MAX_IDLE_TIME  close connections idle this long, like 5m
+//
+// With none set, the pool is database/sql's default. Every problem is returned, not just the first.
+func PoolConfigFromEnv(getenv func(string) string) (PoolConfig, error) {
+	var cfg PoolConfig
+	var problems config.Problems
+	var err error
+	cfg.MaxOpenConns, err = config.EnvInt(getenv, "GOJST_DB_MAX_OPEN_CONNS", 0)
+	problems.Add(err)
+	cfg.MaxIdleConns, err = config.EnvInt(getenv, "GOJST_DB_MAX_IDLE_CONNS", 0)
+	problems.Add(err)
+	cfg.ConnMaxLifetime, err = config.EnvDuration(getenv, "GOJST_DB_CONN_MAX_LIFETIME", 0)
+	problems.Add(err)
+	cfg.ConnMaxIdleTime, err = config.EnvDuration(getenv, "GOJST_DB_CONN_MAX_IDLE_TIME", 0)
+	problems.Add(err)
+
+	problems.Add(config.Validate(cfg))
+	if cfg.MaxOpenConns > 0 && cfg.MaxIdleConns > cfg.MaxOpenConns {
+		problems.Add(fmt.Errorf("GOJST_DB_MAX_IDLE_CONNS %d is more than GOJST_DB_MAX_OPEN_CONNS %d", cfg.MaxIdleConns, cfg.MaxOpenConns))
+	}
+	if err := problems.Err(); err != nil {
+		return PoolConfig{}, err
+	}
+	return cfg, nil
+}
+
+// apply sets the pool limits on db. Zero fields aren't set.
+func (cfg PoolConfig) apply(db *sql.DB) {
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+}
@@ -0,0 +1,24 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// Ping checks that the database answers, for readiness checks.
+func (repo *repoDB) Ping(ctx context.Context) error {
+	if err := repo.DB.PingContext(ctx); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+// IsPrimary is false while the database is a streaming replica (in recovery).
+func (repo *repoDB) IsPrimary(ctx context.Context) (bool, error) {
+	var inRecovery bool
+	if err := repo.DB.QueryRowContext(ctx, `SELECT pg_is_in_recovery()`).Scan(&inRecovery); err != nil {
+		return false, common.PgErrToCommon(err)
+	}
+	return !inRecovery, nil
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// jobReliabilitySql is jobStatus.ComputeJobReliability in SQL. "Changes" keeps the terminal
+// statuses whose code differs from the one before, so it alternates between failure starts
+// (FAIL) and recoveries (SUCCEED), except that it can start with a SUCCEED that isn't one. Its
+// first row per job isn't a change, so the job's transitions are its rows less one.
+const jobReliabilitySql = `WITH "Terminal" AS (
+	SELECT "ApplicationId", "JobId", "JobStatusCode", "JobStatusTimestamp",
+		LAG("JobStatusCode") OVER "Job" AS "PrevCode"
+	FROM "JobStatus"
+	WHERE "BusinessDate" BETWEEN $1 AND $2 AND "JobStatusCode" IN ('SUCCEED', 'FAIL')
+		AND ($3 = '' OR "ApplicationId" = $3) AND ($4 = '' OR "JobId" = $4)
+	WINDOW "Job" AS (PARTITION BY "ApplicationId", "JobId" ORDER BY "JobStatusTimestamp")
+), "Changes" AS (
+	SELECT "ApplicationId", "JobId", "JobStatusCode", "JobStatusTimestamp",
+		"JobStatusTimestamp" - LAG("JobStatusTimestamp") OVER "Job" AS "SincePrevChange"
+	FROM "Terminal"
+	WHERE "PrevCode" IS DISTINCT FROM "JobStatusCode"
+	WINDOW "Job" AS (PARTITION BY "ApplicationId", "JobId" ORDER BY "JobStatusTimestamp")
+), "FailureStarts" AS (
+	SELECT "ApplicationId", "JobId",
+		"JobStatusTimestamp" - LAG("JobStatusTimestamp") OVER (PARTITION BY "ApplicationId", "JobId" ORDER BY "JobStatusTimestamp") AS "SincePrevFailure"
+	FROM "Changes"
+	WHERE "JobStatusCode" = 'FAIL'
+), "JobCounts" AS (
+	SELECT "ApplicationId", "JobId", COUNT(*) AS "TerminalCount"
+	FROM "Terminal"
+	GROUP BY "ApplicationId", "JobId"
+), "RecoveryCounts" AS (
+	SELECT "ApplicationId", "JobId",
+		COUNT(*) - 1 AS "TransitionCount",
+		COUNT("SincePrevChange") FILTER (WHERE "JobStatusCode" = 'SUCCEED') AS "RecoveryCount",
+		COALESCE(SUM(EXTRACT(EPOCH FROM "SincePrevChange") * 1000) FILTER (WHERE "JobStatusCode" = 'SUCCEED'), 0)::bigint AS "TotalRecoveryMs"
+	FROM "Changes"
+	GROUP BY "ApplicationId", "JobId"
+), "FailureCounts" AS (
+	SELECT "ApplicationId", "JobId",
+		COUNT(*) AS "FailureCount",
+		COUNT("SincePrevFailure") AS "FailureIntervalCount",
+		COALESCE(SUM(EXTRACT(EPOCH FROM "SincePrevFailure") * 1000), 0)::bigint AS "TotalBetweenFailuresMs"
+	FROM "FailureStarts"
+	GROUP BY "ApplicationId", "JobId"
+)
+SELECT jc."ApplicationId", jc."JobId", jc."TerminalCount", rc."TransitionCount",
+	COALESCE(fc."FailureCount", 0), rc."RecoveryCount", rc."TotalRecoveryMs",
+	COALESCE(fc."FailureIntervalCount", 0), COALESCE(fc."TotalBetweenFailuresMs", 0)
+FROM "JobCounts" jc
+	JOIN "RecoveryCounts" rc ON rc."ApplicationId" = jc."ApplicationId" AND rc."JobId" = jc."JobId"
+	LEFT JOIN "FailureCounts" fc ON fc."ApplicationId" = jc."ApplicationId" AND fc."JobId" = jc."JobId"
+ORDER BY jc."ApplicationId", jc."JobId"`
+
+func (repo *repoDB) GetJobReliability(ctx context.Context, applicationId string, jobId jobStatus.JobIdType, fromDate time.Time, toDate time.Time) ([]jobStatus.JobReliability, error) {
+	rows, err := repo.DB.QueryContext(ctx, jobReliabilitySql, fromDate, toDate, applicationId, string(jobId))
+	if err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	var result []jobStatus.JobReliability
+	for rows.Next() {
+		jr := jobStatus.JobReliability{FromDate: fromDate, ToDate: toDate}
+		var recoveryMs, betweenMs int64
+		err := rows.Scan(&jr.ApplicationId, &jr.JobId, &jr.TerminalCount, &jr.TransitionCount, &jr.FailureCount, &jr.RecoveryCount, &recoveryMs,
+			&jr.FailureIntervalCount, &betweenMs)
+		if err != nil {
+			return nil, common.PgErrToCommon(err)
+		}
+		jr.TotalRecovery = time.Duration(recoveryMs) * time.Millisecond
+		jr.TotalBetweenFailures = time.Duration(betweenMs) * time.Millisecond
+		result = append(result, jr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,300 @@
+// Package db implements jobStatus.Repo for Postgres using database/sql.
+// The caller must register the pgx driver (import _ "github.com/jackc/pgx/v5/stdlib").
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbcommon"
+)
+
+const driverName = "pgx"
+
+type repoDB struct {
+	DB      *sql.DB
+	connStr string
+	pool    PoolConfig
+	// insertStmt is prepared at Open. database/sql prepares it again on each connection it's
+	// used on, once, so adds skip parsing and planning.
+	insertStmt *sql.Stmt
+}
+
+// NewRepoDB returns a repo for connStr. Pass a zero PoolConfig for database/sql's defaults.
+func NewRepoDB(connStr string, pool PoolConfig) *repoDB {
+	return &repoDB{connStr: connStr, pool: pool}
+}
+
+// Open opens the database with the repo's pool limits and confirms it's reachable.
+func (repo *repoDB) Open() error {
+	db, err := sql.Open(driverName, repo.connStr)
+	if err != nil {
+		return common.PgErrToCommon(err)
+	}
+	repo.pool.apply(db)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return common.PgErrToCommon(err)
+	}
+
+	insertStmt, err := db.Prepare(insertJobStatusSql)
+	if err != nil {
+		db.Close()
+		return common.PgErrToCommon(err)
+	}
+
+	repo.DB = db
+	repo.insertStmt = insertStmt
+	return nil
+}
+
+func (repo *repoDB) Close() error {
+	if repo.DB == nil {
+		return nil
+	}
+	if repo.insertStmt != nil {
+		repo.insertStmt.Close()
+	}
+	return repo.DB.Close()
+}
+
+const insertJobStatusSql = `INSERT INTO "JobStatus" ("StatusId", "ApplicationId", "JobId", "JobStatusCode", "JobStatusTimestamp", "BusinessDate", "RunId", "HostId", "ReportedJobId", "ReceivedTimestamp", "Links", "Metadata", "IntegrityHash")
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+// insertArgs are js's values for insertJobStatusSql. HeartbeatTimestamp isn't inserted; a run
+// only has heartbeats after its START is stored.
+func insertArgs(js jobStatus.JobStatus) ([]any, error) {
+	links, err := dialect.LinksToDb(js.Links)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := dialect.MetadataToDb(js.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	return []any{string(js.StatusId), js.ApplicationId, string(js.JobId), string(js.JobStatusCode), js.JobStatusTimestamp, js.BusinessDate, dbcommon.NullIfEmpty(string(js.RunId)), dbcommon.NullIfEmpty(string(js.HostId)), dbcommon.NullIfEmpty(string(js.ReportedJobId)), nullIfZero(js.ReceivedTimestamp), links, metadata, js.IntegrityHash}, nil
+}
+
+func (repo *repoDB) Add(ctx context.Context, js jobStatus.JobStatus) error {
+	args, err := insertArgs(js)
+	if err != nil {
+		return common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	_, err = repo.insertStmt.ExecContext(ctx, args...)
+	if err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+const insertJobStatusIdempotentSql = insertJobStatusSql + `
+	ON CONFLICT ("JobId", "JobStatusCode", "BusinessDate", "RunId") DO NOTHING`
+
+// AddIdempotent inserts with ON CONFLICT DO NOTHING on "JobStatus_pk", then reads back the row
+// that was already there if nothing was inserted. A StatusId conflict is still an error.
+func (repo *repoDB) AddIdempotent(ctx context.Context, js jobStatus.JobStatus) (jobStatus.JobStatus, bool, error) {
+	args, err := insertArgs(js)
+	if err != nil {
+		return jobStatus.JobStatus{}, false, common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	res, err := repo.DB.ExecContext(ctx, insertJobStatusIdempotentSql, args...)
+	if err != nil {
+		return jobStatus.JobStatus{}, false, common.PgErrToCommon(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return jobStatus.JobStatus{}, false, common.PgErrToCommon(err)
+	} else if n == 1 {
+		return js, true, nil
+	}
+
+	existing, err := repo.selectDB(ctx, jobStatus.QueryOptions{}, `"JobId" = $1 AND "JobStatusCode" = $2 AND "BusinessDate" = $3 AND "RunId" = $4`,
+		string(js.JobId), string(js.JobStatusCode), js.BusinessDate, string(js.RunId))
+	if err != nil {
+		return jobStatus.JobStatus{}, false, err
+	}
+	if len(existing) == 0 {
+		// deleted between the insert and the select, so trying again will insert it
+		return jobStatus.JobStatus{}, false, common.NewCommonError(common.ErrcdRepoTransient, fmt.Errorf("status %s for job %s run %s conflicted and then was gone", js.JobStatusCode, js.JobId, js.RunId))
+	}
+	return existing[0], false, nil
+}
+
+// AddBatch runs the prepared insert for each status inside one transaction.
+func (repo *repoDB) AddBatch(ctx context.Context, jss []jobStatus.JobStatus) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return common.PgErrToCommon(err)
+	}
+	defer tx.Rollback()
+
+	stmt := tx.StmtContext(ctx, repo.insertStmt)
+	defer stmt.Close()
+
+	for i, js := range jss {
+		args, err := insertArgs(js)
+		if err != nil {
+			return common.NewCommonError(common.ErrcdRepoOther, fmt.Errorf("status %d: %w", i, err))
+		}
+		_, err = stmt.ExecContext(ctx, args...)
+		if err != nil {
+			return common.PgErrToCommon(fmt.Errorf("status %d: %w", i, err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+func nullIfZero(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func (repo *repoDB) GetByJobId(ctx context.Context, jobId jobStatus.JobIdType, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	return repo.selectDB(ctx, opts, `"JobId" = $1`, string(jobId))
+}
+
+func (repo *repoDB) GetByJobIdBusinessDate(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	return repo.selectDB(ctx, opts, `"JobId" = $1 AND "BusinessDate" = $2`, string(jobId), businessDate)
+}
+
+const countByApplicationBusinessDateSql = `SELECT COUNT(*) FROM "JobStatus" WHERE "BusinessDate" = $1 AND "ApplicationId" = $2`
+
+// CountByApplicationBusinessDate uses the "JobStatus_BusinessDate_ApplicationId" index.
+func (repo *repoDB) CountByApplicationBusinessDate(ctx context.Context, applicationId string, businessDate time.Time) (int64, error) {
+	var n int64
+	if err := repo.DB.QueryRowContext(ctx, countByApplicationBusinessDateSql, businessDate, applicationId).Scan(&n); err != nil {
+		return 0, common.PgErrToCommon(err)
+	}
+	return n, nil
+}
+
+// GetLatestByJobIds uses DISTINCT ON to pick each job's newest status in one query. "JobStatus_pk"
+// leads with "JobId", so each job is an index range scan.
+func (repo *repoDB) GetLatestByJobIds(ctx context.Context, jobIds []jobStatus.JobIdType, businessDate time.Time, asOf time.Time) ([]jobStatus.JobStatus, error) {
+	if len(jobIds) == 0 {
+		return nil, nil
+	}
+
+	cols, _ := dialect.Columns(jobStatus.AllFields)
+	args := []any{businessDate}
+	placeholders := make([]string, len(jobIds))
+	for i, jobId := range jobIds {
+		args = append(args, string(jobId))
+		placeholders[i] = fmt.Sprintf("$%d", i+2)
+	}
+	where, args := dialect.AsOfWhere(`"BusinessDate" = $1 AND "JobId" IN (`+strings.Join(placeholders, ", ")+`)`, args, asOf)
+	query := `SELECT DISTINCT ON ("JobId") ` + strings.Join(cols, ", ") + ` FROM "JobStatus"
+		WHERE ` + where + `
+		ORDER BY "JobId", "JobStatusTimestamp" DESC`
+
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	return dialect.RowsToDomain(rows, jobStatus.AllFields, false)
+}
+
+// GetLatestByJobId ranks each business date's statuses with ROW_NUMBER and keeps the first. It
+// reads one job's range of "JobStatus_pk".
+func (repo *repoDB) GetLatestByJobId(ctx context.Context, jobId jobStatus.JobIdType, fromDate time.Time, toDate time.Time, asOf time.Time) ([]jobStatus.JobStatus, error) {
+	cols, _ := dialect.Columns(jobStatus.AllFields)
+	where, args := dialect.AsOfWhere(`"JobId" = $1 AND "BusinessDate" BETWEEN $2 AND $3`, []any{string(jobId), fromDate, toDate}, asOf)
+	query := `SELECT ` + strings.Join(cols, ", ") + ` FROM (
+			SELECT ` + strings.Join(cols, ", ") + `,
+				ROW_NUMBER() OVER (PARTITION BY "JobId", "BusinessDate" ORDER BY "JobStatusTimestamp" DESC) AS "Rank"
+			FROM "JobStatus"
+			WHERE ` + where + `
+		) ranked
+		WHERE "Rank" = 1
+		ORDER BY "BusinessDate" DESC`
+
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	return dialect.RowsToDomain(rows, jobStatus.AllFields, false)
+}
+
+func (repo *repoDB) selectDB(ctx context.Context, opts jobStatus.QueryOptions, where string, args ...any) ([]jobStatus.JobStatus, error) {
+	where, args, err := dialect.OptionsWhere(where, args, opts)
+	if err != nil {
+		return nil, err
+	}
+	fields := opts.SelectedFields()
+	query, err := dialect.BuildSelect(fields, where, opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+	query, args = dialect.PageQuery(query, args, opts)
+
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	return dialect.RowsToDomain(rows, fields, opts.AllowPartial)
+}
+
+func (repo *repoDB) ForEachByJobId(ctx context.Context, jobId jobStatus.JobIdType, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	return repo.forEachDB(ctx, opts, fn, `"JobId" = $1`, string(jobId))
+}
+
+func (repo *repoDB) ForEachByJobIdBusinessDate(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	return repo.forEachDB(ctx, opts, fn, `"JobId" = $1 AND "BusinessDate" = $2`, string(jobId), businessDate)
+}
+
+// forEachDB is selectDB without collecting results. Errors from fn are returned as is.
+// With opts.AllowPartial, rows that can't be read are skipped and reported in a partial result error at the end.
+func (repo *repoDB) forEachDB(ctx context.Context, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error, where string, args ...any) error {
+	where, args, err := dialect.OptionsWhere(where, args, opts)
+	if err != nil {
+		return err
+	}
+	fields := opts.SelectedFields()
+	query, err := dialect.BuildSelect(fields, where, opts.Sort)
+	if err != nil {
+		return err
+	}
+	query, args = dialect.PageQuery(query, args, opts)
+
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	var rowErrs []jobStatus.RowError
+	for row := 0; rows.Next(); row++ {
+		js, err := dialect.ScanRow(rows, fields)
+		if err != nil {
+			if !opts.AllowPartial {
+				return err
+			}
+			rowErrs = append(rowErrs, jobStatus.RowError{Row: row, Err: err})
+			continue
+		}
+		if err := fn(js); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	if len(rowErrs) > 0 {
+		return jobStatus.NewPartialResultError(rowErrs)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/db/migrations"
+	"github.com/jmjf/go-jst/internal/testsupport"
+)
+
+func TestMain(m *testing.M) { os.Exit(testsupport.VerifyTestMain(m)) }
+
+// openTestRepo opens a repo on GOJST_TEST_PG_URL, migrated to the latest schema, or skips if it
+// isn't set. Cleanup checks that no connections are still in use, then closes the repo.
+func openTestRepo(tb testing.TB) *repoDB {
+	tb.Helper()
+	pgUrl := os.Getenv("GOJST_TEST_PG_URL")
+	if pgUrl == "" {
+		tb.Skip("GOJST_TEST_PG_URL isn't set")
+	}
+
+	sqlDB, err := sql.Open(driverName, pgUrl)
+	if err != nil {
+		tb.Fatalf("open: %v", err)
+	}
+	defer sqlDB.Close()
+	if err := migrations.Migrate(context.Background(), sqlDB); err != nil {
+		tb.Fatalf("migrate: %v", err)
+	}
+
+	repo := NewRepoDB(pgUrl, PoolConfig{})
+	if err := repo.Open(); err != nil {
+		tb.Fatalf("Open: %v", err)
+	}
+	tb.Cleanup(func() { repo.Close() })
+	tb.Cleanup(testsupport.CheckDB(tb, repo.DB))
+	return repo
+}
+
+// testJobId is a JobId no other run of the tests has used, so tests don't see each other's rows.
+func testJobId() jobStatus.JobIdType {
+	return jobStatus.JobIdType("test-" + strconv.FormatInt(time.Now().UnixNano(), 36))
+}
+
+func newTestStatus(tb testing.TB, jobId jobStatus.JobIdType, runId int) jobStatus.JobStatus {
+	tb.Helper()
+	busDt := time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)
+	js, err := jobStatus.NewJobStatus("gojst-tests", jobId, "SUCCEED", busDt.Add(time.Duration(25*60+runId)*time.Minute), busDt, jobStatus.RunIdType(strconv.Itoa(runId)), "batch01")
+	if err != nil {
+		tb.Fatalf("NewJobStatus: %v", err)
+	}
+	return js
+}
+
+func TestForEachReturnsItsConnectionWhenFnFails(t *testing.T) {
+	ctx := context.Background()
+	repo := openTestRepo(t)
+	jobId := testJobId()
+	for runId := 1; runId <= 3; runId++ {
+		if err := repo.Add(ctx, newTestStatus(t, jobId, runId)); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	stop := errors.New("stop")
+	seen := 0
+	err := repo.ForEachByJobId(ctx, jobId, jobStatus.QueryOptions{}, func(jobStatus.JobStatus) error {
+		seen++
+		return stop
+	})
+	if !errors.Is(err, stop) || seen != 1 {
+		t.Errorf("got %v after %d statuses, want fn's error after 1", err, seen)
+	}
+}
+
+func TestAddDuplicateIsDupeRow(t *testing.T) {
+	ctx := context.Background()
+	repo := openTestRepo(t)
+	js := newTestStatus(t, testJobId(), 1)
+	if err := repo.Add(ctx, js); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := repo.Add(ctx, js); common.ErrorCode(err) != common.ErrcdRepoDupeRow {
+		t.Errorf("second Add: got %v, want %s", err, common.ErrcdRepoDupeRow)
+	}
+}
+
+func TestBuildOrderByRefusesUnsortableFields(t *testing.T) {
+	if _, err := dialect.OrderBy([]jobStatus.SortField{{Field: jobStatus.FieldHostId}}); common.ErrorCode(err) != common.ErrcdDomainProps {
+		t.Errorf("got %v, want %s", err, common.ErrcdDomainProps)
+	}
+	orderBy, err := dialect.OrderBy([]jobStatus.SortField{{Field: jobStatus.FieldJobStatusTimestamp, Descending: true}})
+	if err != nil || orderBy == "" {
+		t.Errorf("got %q, %v; want an ORDER BY", orderBy, err)
+	}
+}
+
+// benchStatuses returns n statuses for one new JobId, made before the timer starts.
+func benchStatuses(b *testing.B, n int) []jobStatus.JobStatus {
+	b.Helper()
+	jobId := testJobId()
+	jss := make([]jobStatus.JobStatus, n)
+	for i := range jss {
+		jss[i] = newTestStatus(b, jobId, i)
+	}
+	return jss
+}
+
+// benchParallel calls add once for each of b.N statuses from GOMAXPROCS goroutines, like
+// concurrent POSTs sharing the pool.
+func benchParallel(b *testing.B, add func(context.Context, jobStatus.JobStatus) error) {
+	jss := benchStatuses(b, b.N)
+	var next atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		ctx := context.Background()
+		for pb.Next() {
+			if err := add(ctx, jss[next.Add(1)-1]); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+// addUnprepared is Add as it was before the insert was prepared at Open, for comparison.
+func (repo *repoDB) addUnprepared(ctx context.Context, js jobStatus.JobStatus) error {
+	args, err := insertArgs(js)
+	if err != nil {
+		return common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	_, err = repo.DB.ExecContext(ctx, insertJobStatusSql, args...)
+	if err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+func BenchmarkAddPrepared(b *testing.B) {
+	benchParallel(b, openTestRepo(b).Add)
+}
+
+func BenchmarkAddUnprepared(b *testing.B) {
+	benchParallel(b, openTestRepo(b).addUnprepared)
+}
+
+// BenchmarkAddBatch reports time per status for batches of 100.
+func BenchmarkAddBatch(b *testing.B) {
+	const batchSize = 100
+	repo := openTestRepo(b)
+	jss := benchStatuses(b, b.N)
+	ctx := context.Background()
+	b.ResetTimer()
+	for from := 0; from < len(jss); from += batchSize {
+		to := from + batchSize
+		if to > len(jss) {
+			to = len(jss)
+		}
+		if err := repo.AddBatch(ctx, jss[from:to]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// DeleteBefore picks rows by ctid in a LIMITed subquery, because Postgres DELETE has no LIMIT.
+// Each application in cutoffs gets a WHEN in a CASE, so one statement covers every window.
+func (repo *repoDB) DeleteBefore(ctx context.Context, cutoff time.Time, cutoffs map[string]time.Time, limit int) (int64, error) {
+	args := []any{cutoff}
+	var whens []string
+	for applicationId, appCutoff := range cutoffs {
+		args = append(args, applicationId, appCutoff)
+		whens = append(whens, fmt.Sprintf(`WHEN $%d THEN $%d::date`, len(args)-1, len(args)))
+	}
+	appCutoff := `$1::date`
+	if len(whens) > 0 {
+		appCutoff = `CASE "ApplicationId" ` + strings.Join(whens, " ") + ` ELSE $1::date END`
+	}
+	args = append(args, limit)
+	query := fmt.Sprintf(`DELETE FROM "JobStatus" WHERE ctid IN (
+		SELECT ctid FROM "JobStatus" WHERE "BusinessDate" < %s LIMIT $%d)`, appCutoff, len(args))
+
+	res, err := repo.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, common.PgErrToCommon(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, common.PgErrToCommon(err)
+	}
+	return n, nil
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+const insertRunCommentSql = `INSERT INTO "RunComment" ("CommentId", "JobId", "BusinessDate", "RunId", "Author", "Body", "CreatedTimestamp")
+	VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+func (repo *repoDB) AddRunComment(ctx context.Context, rc jobStatus.RunComment) error {
+	_, err := repo.DB.ExecContext(ctx, insertRunCommentSql, string(rc.CommentId), string(rc.JobId), rc.BusinessDate, string(rc.RunId), rc.Author, rc.Body, rc.CreatedTs)
+	if err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+// selectRunCommentsSql uses "RunComment_JobId_BusinessDate_RunId". CommentIds are UUIDv7s, so
+// they break ties between comments created in the same microsecond in creation order.
+const selectRunCommentsSql = `SELECT "CommentId", "JobId", "BusinessDate", "RunId", "Author", "Body", "CreatedTimestamp"
+	FROM "RunComment"
+	WHERE "JobId" = $1 AND "BusinessDate" = $2 AND ($3 = '' OR "RunId" = $3)
+	ORDER BY "CreatedTimestamp", "CommentId"`
+
+func (repo *repoDB) ListRunComments(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, runId jobStatus.RunIdType) ([]jobStatus.RunComment, error) {
+	rows, err := repo.DB.QueryContext(ctx, selectRunCommentsSql, string(jobId), businessDate, string(runId))
+	if err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	var result []jobStatus.RunComment
+	for rows.Next() {
+		var rc jobStatus.RunComment
+		if err := rows.Scan(&rc.CommentId, &rc.JobId, &rc.BusinessDate, &rc.RunId, &rc.Author, &rc.Body, &rc.CreatedTs); err != nil {
+			return nil, common.PgErrToCommon(err)
+		}
+		rc.BusinessDate = jobStatus.TruncateToDate(rc.BusinessDate)
+		result = append(result, rc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	return result, nil
+}
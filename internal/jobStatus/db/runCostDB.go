@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+const putRunCostSql = `INSERT INTO "RunCost" ("ApplicationId", "JobId", "BusinessDate", "RunId", "ComputeHours", "CostUsd", "UpdatedTimestamp")
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT ("JobId", "BusinessDate", "RunId") DO UPDATE SET
+		"ApplicationId" = EXCLUDED."ApplicationId",
+		"ComputeHours" = EXCLUDED."ComputeHours",
+		"CostUsd" = EXCLUDED."CostUsd",
+		"UpdatedTimestamp" = EXCLUDED."UpdatedTimestamp"`
+
+func (repo *repoDB) PutRunCost(ctx context.Context, rc jobStatus.RunCost) error {
+	_, err := repo.DB.ExecContext(ctx, putRunCostSql, rc.ApplicationId, string(rc.JobId), rc.BusinessDate, string(rc.RunId), rc.ComputeHours, rc.CostUsd, rc.UpdatedTs)
+	if err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+// jobCostSql is jobStatus.ComputeJobCosts in SQL. "Runs" has one row per run with a status and
+// its last SUCCEED or FAIL code, if it has one, so costs for runs that haven't reported drop out
+// of the join.
+const jobCostSql = `WITH "Runs" AS (
+	SELECT "JobId", "BusinessDate", "RunId",
+		(ARRAY_AGG("JobStatusCode" ORDER BY "JobStatusTimestamp" DESC) FILTER (WHERE "JobStatusCode" IN ('SUCCEED', 'FAIL')))[1] AS "EndCode"
+	FROM "JobStatus"
+	WHERE "BusinessDate" BETWEEN $1 AND $2 AND "ApplicationId" = $3
+	GROUP BY "JobId", "BusinessDate", "RunId"
+)
+SELECT rc."JobId", COUNT(*), SUM(rc."ComputeHours"), SUM(rc."CostUsd")::double precision,
+	COUNT(*) FILTER (WHERE r."EndCode" = 'FAIL'),
+	COALESCE(SUM(rc."CostUsd") FILTER (WHERE r."EndCode" = 'FAIL'), 0)::double precision
+FROM "RunCost" rc
+	JOIN "Runs" r ON r."JobId" = rc."JobId" AND r."BusinessDate" = rc."BusinessDate" AND r."RunId" = rc."RunId"
+WHERE rc."BusinessDate" BETWEEN $1 AND $2 AND rc."ApplicationId" = $3
+GROUP BY rc."JobId"
+ORDER BY rc."JobId"`
+
+func (repo *repoDB) GetJobCosts(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time) ([]jobStatus.JobCost, error) {
+	rows, err := repo.DB.QueryContext(ctx, jobCostSql, fromDate, toDate, applicationId)
+	if err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	var result []jobStatus.JobCost
+	for rows.Next() {
+		jc := jobStatus.JobCost{ApplicationId: applicationId, FromDate: fromDate, ToDate: toDate}
+		err := rows.Scan(&jc.JobId, &jc.RunCount, &jc.ComputeHours, &jc.CostUsd, &jc.FailedRunCount, &jc.FailedCostUsd)
+		if err != nil {
+			return nil, common.PgErrToCommon(err)
+		}
+		result = append(result, jc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// savedViewFilters is the "Filters" jsonb column. Lists go in jsonb so database/sql doesn't
+// have to handle Postgres arrays.
+type savedViewFilters struct {
+	ApplicationIds []string
+	JobIds         []jobStatus.JobIdType
+}
+
+const putSavedViewSql = `INSERT INTO "SavedView" ("Name", "Team", "Shared", "Filters", "Fields", "Sort", "UpdatedTimestamp")
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT ("Name") DO UPDATE SET
+		"Team" = EXCLUDED."Team",
+		"Shared" = EXCLUDED."Shared",
+		"Filters" = EXCLUDED."Filters",
+		"Fields" = EXCLUDED."Fields",
+		"Sort" = EXCLUDED."Sort",
+		"UpdatedTimestamp" = EXCLUDED."UpdatedTimestamp"`
+
+func (repo *repoDB) PutSavedView(ctx context.Context, sv jobStatus.SavedView) error {
+	filters, err := json.Marshal(savedViewFilters{ApplicationIds: sv.ApplicationIds, JobIds: sv.JobIds})
+	if err != nil {
+		return common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	if _, err := repo.DB.ExecContext(ctx, putSavedViewSql, sv.Name, sv.Team, sv.Shared, filters, sv.Fields, sv.Sort, sv.UpdatedTs); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+const selectSavedViewSql = `SELECT "Name", "Team", "Shared", "Filters", "Fields", "Sort", "UpdatedTimestamp" FROM "SavedView"`
+
+func (repo *repoDB) GetSavedView(ctx context.Context, name string) (jobStatus.SavedView, bool, error) {
+	sv, err := scanSavedView(repo.DB.QueryRowContext(ctx, selectSavedViewSql+` WHERE "Name" = $1`, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return jobStatus.SavedView{}, false, nil
+	}
+	if err != nil {
+		return jobStatus.SavedView{}, false, err
+	}
+	return sv, true, nil
+}
+
+func (repo *repoDB) ListSavedViews(ctx context.Context) ([]jobStatus.SavedView, error) {
+	return listSavedViews(ctx, repo.DB)
+}
+
+func listSavedViews(ctx context.Context, q querier) ([]jobStatus.SavedView, error) {
+	rows, err := q.QueryContext(ctx, selectSavedViewSql+` ORDER BY "Name"`)
+	if err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	var result []jobStatus.SavedView
+	for rows.Next() {
+		sv, err := scanSavedView(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, sv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	return result, nil
+}
+
+func (repo *repoDB) DeleteSavedView(ctx context.Context, name string) (bool, error) {
+	res, err := repo.DB.ExecContext(ctx, `DELETE FROM "SavedView" WHERE "Name" = $1`, name)
+	if err != nil {
+		return false, common.PgErrToCommon(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, common.PgErrToCommon(err)
+	}
+	return n > 0, nil
+}
+
+// scanSavedView returns sql.ErrNoRows unchanged so GetSavedView can tell "not found" from failure.
+func scanSavedView(row interface{ Scan(...any) error }) (jobStatus.SavedView, error) {
+	var sv jobStatus.SavedView
+	var filtersJson []byte
+	err := row.Scan(&sv.Name, &sv.Team, &sv.Shared, &filtersJson, &sv.Fields, &sv.Sort, &sv.UpdatedTs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return jobStatus.SavedView{}, err
+	}
+	if err != nil {
+		return jobStatus.SavedView{}, common.NewCommonError(common.ErrcdRepoRowConversion, err)
+	}
+
+	var filters savedViewFilters
+	if err := json.Unmarshal(filtersJson, &filters); err != nil {
+		return jobStatus.SavedView{}, common.NewCommonError(common.ErrcdRepoRowConversion, err)
+	}
+	sv.ApplicationIds = filters.ApplicationIds
+	sv.JobIds = filters.JobIds
+	return sv, nil
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbcommon"
+)
+
+// "LastRunDate" isn't in the update so replacing a query doesn't make it run again today.
+const putScheduledQuerySql = `INSERT INTO "ScheduledQuery" ("Name", "Team", "View", "Filters", "BusinessDateOffset", "RunAtHour", "RunAtMinute", "DeliveryKind", "DeliveryTarget", "UpdatedTimestamp")
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	ON CONFLICT ("Name") DO UPDATE SET
+		"Team" = EXCLUDED."Team",
+		"View" = EXCLUDED."View",
+		"Filters" = EXCLUDED."Filters",
+		"BusinessDateOffset" = EXCLUDED."BusinessDateOffset",
+		"RunAtHour" = EXCLUDED."RunAtHour",
+		"RunAtMinute" = EXCLUDED."RunAtMinute",
+		"DeliveryKind" = EXCLUDED."DeliveryKind",
+		"DeliveryTarget" = EXCLUDED."DeliveryTarget",
+		"UpdatedTimestamp" = EXCLUDED."UpdatedTimestamp"`
+
+func (repo *repoDB) PutScheduledQuery(ctx context.Context, sq jobStatus.ScheduledQuery) error {
+	filters, err := json.Marshal(sq.Filters)
+	if err != nil {
+		return common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	_, err = repo.DB.ExecContext(ctx, putScheduledQuerySql, sq.Name, sq.Team, dbcommon.NullIfEmpty(sq.View), filters, sq.BusinessDateOffset,
+		sq.RunAtHour, sq.RunAtMinute, sq.DeliveryKind, sq.DeliveryTarget, sq.UpdatedTs)
+	if err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+const selectScheduledQuerySql = `SELECT "Name", "Team", "View", "Filters", "BusinessDateOffset", "RunAtHour", "RunAtMinute", "DeliveryKind", "DeliveryTarget", "LastRunDate", "UpdatedTimestamp" FROM "ScheduledQuery"`
+
+func (repo *repoDB) GetScheduledQuery(ctx context.Context, name string) (jobStatus.ScheduledQuery, bool, error) {
+	sq, err := scanScheduledQuery(repo.DB.QueryRowContext(ctx, selectScheduledQuerySql+` WHERE "Name" = $1`, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return jobStatus.ScheduledQuery{}, false, nil
+	}
+	if err != nil {
+		return jobStatus.ScheduledQuery{}, false, err
+	}
+	return sq, true, nil
+}
+
+func (repo *repoDB) ListScheduledQueries(ctx context.Context) ([]jobStatus.ScheduledQuery, error) {
+	return listScheduledQueries(ctx, repo.DB)
+}
+
+func listScheduledQueries(ctx context.Context, q querier) ([]jobStatus.ScheduledQuery, error) {
+	rows, err := q.QueryContext(ctx, selectScheduledQuerySql+` ORDER BY "Name"`)
+	if err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	var result []jobStatus.ScheduledQuery
+	for rows.Next() {
+		sq, err := scanScheduledQuery(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, sq)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, common.PgErrToCommon(err)
+	}
+	return result, nil
+}
+
+func (repo *repoDB) DeleteScheduledQuery(ctx context.Context, name string) (bool, error) {
+	res, err := repo.DB.ExecContext(ctx, `DELETE FROM "ScheduledQuery" WHERE "Name" = $1`, name)
+	if err != nil {
+		return false, common.PgErrToCommon(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, common.PgErrToCommon(err)
+	}
+	return n > 0, nil
+}
+
+const claimScheduledRunSql = `UPDATE "ScheduledQuery" SET "LastRunDate" = $2
+	WHERE "Name" = $1 AND ("LastRunDate" IS NULL OR "LastRunDate" < $2)`
+
+// ClaimScheduledRun relies on the UPDATE's row lock: when instances race, the second one
+// rechecks the WHERE after the first commits and updates nothing.
+func (repo *repoDB) ClaimScheduledRun(ctx context.Context, name string, runDate time.Time) (bool, error) {
+	res, err := repo.DB.ExecContext(ctx, claimScheduledRunSql, name, runDate)
+	if err != nil {
+		return false, common.PgErrToCommon(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, common.PgErrToCommon(err)
+	}
+	return n == 1, nil
+}
+
+const releaseScheduledRunSql = `UPDATE "ScheduledQuery" SET "LastRunDate" = $3
+	WHERE "Name" = $1 AND "LastRunDate" = $2`
+
+func (repo *repoDB) ReleaseScheduledRun(ctx context.Context, name string, runDate time.Time, previous time.Time) (bool, error) {
+	var prev sql.NullTime
+	if !previous.IsZero() {
+		prev = sql.NullTime{Time: previous, Valid: true}
+	}
+	res, err := repo.DB.ExecContext(ctx, releaseScheduledRunSql, name, runDate, prev)
+	if err != nil {
+		return false, common.PgErrToCommon(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, common.PgErrToCommon(err)
+	}
+	return n == 1, nil
+}
+
+// scanScheduledQuery returns sql.ErrNoRows unchanged so GetScheduledQuery can tell "not found" from failure.
+func scanScheduledQuery(row interface{ Scan(...any) error }) (jobStatus.ScheduledQuery, error) {
+	var sq jobStatus.ScheduledQuery
+	var view sql.NullString
+	var filtersJson []byte
+	var lastRun sql.NullTime
+	err := row.Scan(&sq.Name, &sq.Team, &view, &filtersJson, &sq.BusinessDateOffset, &sq.RunAtHour, &sq.RunAtMinute,
+		&sq.DeliveryKind, &sq.DeliveryTarget, &lastRun, &sq.UpdatedTs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return jobStatus.ScheduledQuery{}, err
+	}
+	if err != nil {
+		return jobStatus.ScheduledQuery{}, common.NewCommonError(common.ErrcdRepoRowConversion, err)
+	}
+
+	if err := json.Unmarshal(filtersJson, &sq.Filters); err != nil {
+		return jobStatus.ScheduledQuery{}, common.NewCommonError(common.ErrcdRepoRowConversion, err)
+	}
+	sq.View = view.String
+	if lastRun.Valid {
+		sq.LastRunDate = jobStatus.TruncateToDate(lastRun.Time)
+	}
+	return sq, nil
+}
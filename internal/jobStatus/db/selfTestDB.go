@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// ProbeWrite inserts a row into "SelfTestProbe", reads it back, and deletes it, in one transaction
+// that's committed, so it proves the user can write and the database accepts commits.
+func (repo *repoDB) ProbeWrite(ctx context.Context) error {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	probeId := hex.EncodeToString(b)
+
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return common.PgErrToCommon(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO "SelfTestProbe" ("ProbeId") VALUES ($1)`, probeId); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	var readId string
+	if err := tx.QueryRowContext(ctx, `SELECT "ProbeId" FROM "SelfTestProbe" WHERE "ProbeId" = $1`, probeId).Scan(&readId); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	if readId != probeId {
+		return common.NewCommonError(common.ErrcdRepoOther, fmt.Errorf("probe read back %q, wrote %q", readId, probeId))
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM "SelfTestProbe" WHERE "ProbeId" = $1`, probeId); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+// Now is the database's clock, for clock skew checks.
+func (repo *repoDB) Now(ctx context.Context) (time.Time, error) {
+	var now time.Time
+	if err := repo.DB.QueryRowContext(ctx, `SELECT clock_timestamp()`).Scan(&now); err != nil {
+		return time.Time{}, common.PgErrToCommon(err)
+	}
+	return now, nil
+}
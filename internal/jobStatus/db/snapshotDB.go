@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbcommon"
+)
+
+// querier is what *sql.DB and *sql.Tx have in common, so list queries can run in a snapshot.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Snapshot runs fn in a REPEATABLE READ, READ ONLY transaction. Every query in it sees the data
+// as of its first query, and it doesn't block writers.
+func (repo *repoDB) Snapshot(ctx context.Context, fn func(jobStatus.SnapshotReader) error) error {
+	tx, err := repo.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return common.PgErrToCommon(err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&snapshotReaderDB{ctx: ctx, tx: tx}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+type snapshotReaderDB struct {
+	ctx context.Context
+	tx  *sql.Tx
+}
+
+func (sr *snapshotReaderDB) ForEachJobStatus(fn func(jobStatus.JobStatus) error) error {
+	cols, _ := dialect.Columns(jobStatus.AllFields)
+	query := `SELECT ` + strings.Join(cols, ", ") + `, "IntegrityHash" FROM "JobStatus" ORDER BY "BusinessDate", "StatusId"`
+
+	rows, err := sr.tx.QueryContext(sr.ctx, query)
+	if err != nil {
+		return common.PgErrToCommon(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row dbcommon.Row
+		var hash []byte
+		if err := rows.Scan(append(dialect.ScanTargets(&row, jobStatus.AllFields), &hash)...); err != nil {
+			return common.NewCommonError(common.ErrcdRepoRowConversion, err)
+		}
+		js, err := dbcommon.RowToDomain(row, jobStatus.AllFields)
+		if err != nil {
+			return err
+		}
+		js.IntegrityHash = hash
+		if err := fn(js); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return common.PgErrToCommon(err)
+	}
+	return nil
+}
+
+func (sr *snapshotReaderDB) ListSavedViews() ([]jobStatus.SavedView, error) {
+	return listSavedViews(sr.ctx, sr.tx)
+}
+
+func (sr *snapshotReaderDB) ListScheduledQueries() ([]jobStatus.ScheduledQuery, error) {
+	return listScheduledQueries(sr.ctx, sr.tx)
+}
+
+func (sr *snapshotReaderDB) ListJobAliases() ([]jobStatus.JobAlias, error) {
+	return listJobAliases(sr.ctx, sr.tx)
+}
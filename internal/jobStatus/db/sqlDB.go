@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// QueryReadOnly wraps query as a subquery so the LIMIT can't be left off. The read-only
+// transaction and statement_timeout hold even if ValidateReadOnlySql misses something; the
+// context timeout is a backstop for time spent outside the statement, and ctx ends the query
+// when the client goes away.
+func (repo *repoDB) QueryReadOnly(ctx context.Context, query string, maxRows int, timeout time.Duration) (jobStatus.SqlResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout+time.Second)
+	defer cancel()
+
+	tx, err := repo.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return jobStatus.SqlResult{}, common.PgErrToCommon(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('statement_timeout', $1, true)`, fmt.Sprintf("%dms", timeout.Milliseconds())); err != nil {
+		return jobStatus.SqlResult{}, common.PgErrToCommon(err)
+	}
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT * FROM (%s) AS "q" LIMIT %d`, query, maxRows+1))
+	if err != nil {
+		return jobStatus.SqlResult{}, sqlQueryErr(err)
+	}
+	defer rows.Close()
+
+	result := jobStatus.SqlResult{Rows: [][]any{}}
+	if result.Columns, err = rows.Columns(); err != nil {
+		return jobStatus.SqlResult{}, sqlQueryErr(err)
+	}
+	for rows.Next() {
+		if len(result.Rows) == maxRows {
+			result.Truncated = true
+			break
+		}
+		values := make([]any, len(result.Columns))
+		ptrs := make([]any, len(values))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return jobStatus.SqlResult{}, common.NewCommonError(common.ErrcdRepoRowConversion, err)
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return jobStatus.SqlResult{}, sqlQueryErr(err)
+	}
+	return result, nil
+}
+
+// sqlQueryErr makes errors the analyst can fix props errors, so they get a 400 with the
+// database's message instead of a 500.
+func sqlQueryErr(err error) error {
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		state := stateErr.SQLState()
+		switch {
+		case state == "57014":
+			return common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("query took longer than its timeout: %w", err))
+		case state == "25006", strings.HasPrefix(state, "42"), strings.HasPrefix(state, "22"):
+			return common.NewCommonError(common.ErrcdDomainProps, err)
+		}
+	}
+	return common.PgErrToCommon(err)
+}
@@ -0,0 +1,91 @@
+// Package dbcommon builds and reads the "JobStatus" queries the SQL repos (db, dbmysql, and
+// dbsqlite) share. What differs between databases (identifier quoting, parameter markers,
+// null-safe comparison, prefix matching, and how dates and times are stored) is a Dialect each
+// repo package defines.
+package dbcommon
+
+import (
+	"fmt"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// Dialect is one database's SQL. Every func field must be set except Arg.
+type Dialect struct {
+	// QuoteIdent quotes a table or column name.
+	QuoteIdent func(name string) string
+	// Placeholder is the parameter marker for the nth argument, counting from 1.
+	Placeholder func(n int) string
+	// NotEqual is a null-safe col <> param, so a NULL RunId or HostId matches ne as it does
+	// in jobStatus.Filter.Matches.
+	NotEqual func(col string, param string) string
+	// Prefix is a condition that col starts with prefix, matched literally. param adds an
+	// argument and returns its placeholder.
+	Prefix func(col string, prefix string, param func(v any) string) string
+	// Arg converts a date or time compared with field to the form it's stored in. Nil sends
+	// values as they are.
+	Arg func(field jobStatus.FieldName, v any) any
+	// ErrToCommon maps the driver's errors.
+	ErrToCommon func(err error) *common.CommonError
+
+	// JsonAsText sends Links and Metadata as strings, for drivers that would send []byte as binary.
+	JsonAsText bool
+	// TimestampLayout and DateLayout parse timestamps and dates that are stored as text. They
+	// can be empty if the driver scans them as time.Time.
+	TimestampLayout string
+	DateLayout      string
+}
+
+// columnNames is the whitelist of "JobStatus" columns, unquoted.
+var columnNames = map[jobStatus.FieldName]string{
+	jobStatus.FieldStatusId:           "StatusId",
+	jobStatus.FieldApplicationId:      "ApplicationId",
+	jobStatus.FieldJobId:              "JobId",
+	jobStatus.FieldJobStatusCode:      "JobStatusCode",
+	jobStatus.FieldJobStatusTimestamp: "JobStatusTimestamp",
+	jobStatus.FieldBusinessDate:       "BusinessDate",
+	jobStatus.FieldRunId:              "RunId",
+	jobStatus.FieldHostId:             "HostId",
+	jobStatus.FieldReportedJobId:      "ReportedJobId",
+	jobStatus.FieldReceivedTimestamp:  "ReceivedTimestamp",
+	jobStatus.FieldLinks:              "Links",
+	jobStatus.FieldMetadata:           "Metadata",
+	jobStatus.FieldHeartbeatTimestamp: "HeartbeatTimestamp",
+}
+
+// Column returns field's quoted column. ok is false if field isn't a "JobStatus" column.
+func (d Dialect) Column(field jobStatus.FieldName) (col string, ok bool) {
+	name, ok := columnNames[field]
+	if !ok {
+		return "", false
+	}
+	return d.QuoteIdent(name), true
+}
+
+// Columns returns the quoted columns for fields, in order.
+func (d Dialect) Columns(fields []jobStatus.FieldName) ([]string, error) {
+	cols := make([]string, len(fields))
+	for i, field := range fields {
+		col, ok := d.Column(field)
+		if !ok {
+			return nil, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("unknown field %q", field))
+		}
+		cols[i] = col
+	}
+	return cols, nil
+}
+
+// Table is the quoted "JobStatus" table.
+func (d Dialect) Table() string {
+	return d.QuoteIdent("JobStatus")
+}
+
+// param appends v, converted for field, to args and returns its placeholder.
+func (d Dialect) param(args *[]any, field jobStatus.FieldName, v any) string {
+	if d.Arg != nil {
+		v = d.Arg(field, v)
+	}
+	*args = append(*args, v)
+	return d.Placeholder(len(*args))
+}
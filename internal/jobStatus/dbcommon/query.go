@@ -0,0 +1,143 @@
+package dbcommon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// filterSqlOps are the comparisons filters compile to, other than ne, in, and prefix, which
+// the Dialect decides.
+var filterSqlOps = map[jobStatus.FilterOp]string{
+	jobStatus.FilterEq:  "=",
+	jobStatus.FilterGt:  ">",
+	jobStatus.FilterGte: ">=",
+	jobStatus.FilterLt:  "<",
+	jobStatus.FilterLte: "<=",
+}
+
+// BuildSelect builds the SELECT for the requested fields and sort. Column names come only from
+// the column whitelist.
+func (d Dialect) BuildSelect(fields []jobStatus.FieldName, where string, sort []jobStatus.SortField) (string, error) {
+	cols, err := d.Columns(fields)
+	if err != nil {
+		return "", err
+	}
+	orderBy, err := d.OrderBy(sort)
+	if err != nil {
+		return "", err
+	}
+	return "SELECT " + strings.Join(cols, ", ") + " FROM " + d.Table() + " WHERE " + where + orderBy, nil
+}
+
+// OrderBy returns an ORDER BY clause (with a leading space), or "" if sort is empty.
+func (d Dialect) OrderBy(sort []jobStatus.SortField) (string, error) {
+	if len(sort) == 0 {
+		return "", nil
+	}
+
+	terms := make([]string, len(sort))
+	for i, sf := range sort {
+		col, ok := d.Column(sf.Field)
+		if !ok || !jobStatus.SortableFields[sf.Field] {
+			return "", common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("cannot sort on %q", sf.Field))
+		}
+		if sf.Descending {
+			col += " DESC"
+		}
+		terms[i] = col
+	}
+	return " ORDER BY " + strings.Join(terms, ", "), nil
+}
+
+// PageQuery adds "StatusId" to the end of query's ORDER BY, so rows that tie on opts.Sort stay in
+// the same order from page to page, then LIMIT and OFFSET as the next parameters. Queries that
+// aren't paged are returned as is.
+func (d Dialect) PageQuery(query string, args []any, opts jobStatus.QueryOptions) (string, []any) {
+	if opts.Limit <= 0 {
+		return query, args
+	}
+	statusId, _ := d.Column(jobStatus.FieldStatusId)
+	if len(opts.Sort) == 0 {
+		query += " ORDER BY " + statusId
+	} else {
+		query += ", " + statusId
+	}
+	args = append(args, opts.Limit, opts.Offset)
+	return query + " LIMIT " + d.Placeholder(len(args)-1) + " OFFSET " + d.Placeholder(len(args)), args
+}
+
+// AndWhere appends cond to a WHERE condition that may be empty.
+func AndWhere(where string, cond string) string {
+	if len(where) == 0 {
+		return cond
+	}
+	return where + " AND " + cond
+}
+
+// AsOfWhere adds asOf to where as the next parameter unless it's zero. Rows without a
+// "ReceivedTimestamp" count as received at their "JobStatusTimestamp", as in JobStatus.KnownAt.
+func (d Dialect) AsOfWhere(where string, args []any, asOf time.Time) (string, []any) {
+	if asOf.IsZero() {
+		return where, args
+	}
+	col, _ := d.filterColumn(jobStatus.FieldReceivedTimestamp)
+	return AndWhere(where, col+" <= "+d.param(&args, jobStatus.FieldReceivedTimestamp, asOf)), args
+}
+
+// OptionsWhere adds the conditions in opts (AsOf and Filters) to where.
+func (d Dialect) OptionsWhere(where string, args []any, opts jobStatus.QueryOptions) (string, []any, error) {
+	where, args = d.AsOfWhere(where, args, opts.AsOf)
+	return d.FilterWhere(where, args, opts.Filters)
+}
+
+// FilterWhere ANDs a condition for each filter onto where, with values as parameters after args.
+// Columns come only from the column whitelist and operators only from filterSqlOps and the
+// Dialect, so nothing a client sends becomes SQL text.
+func (d Dialect) FilterWhere(where string, args []any, filters []jobStatus.Filter) (string, []any, error) {
+	for _, f := range filters {
+		col, ok := d.filterColumn(f.Field)
+		if !ok || !jobStatus.IsFilterOpAllowed(f.Field, f.Op) || len(f.Values) == 0 {
+			return "", nil, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("can't filter %s %s", f.Field, f.Op))
+		}
+
+		var cond string
+		switch f.Op {
+		case jobStatus.FilterIn:
+			placeholders := make([]string, len(f.Values))
+			for i, v := range f.Values {
+				placeholders[i] = d.param(&args, f.Field, v)
+			}
+			cond = col + " IN (" + strings.Join(placeholders, ", ") + ")"
+		case jobStatus.FilterPrefix:
+			prefix, _ := f.Values[0].(string)
+			cond = d.Prefix(col, prefix, func(v any) string {
+				args = append(args, v)
+				return d.Placeholder(len(args))
+			})
+		case jobStatus.FilterNe:
+			cond = d.NotEqual(col, d.param(&args, f.Field, f.Values[0]))
+		default:
+			cond = col + " " + filterSqlOps[f.Op] + " " + d.param(&args, f.Field, f.Values[0])
+		}
+		where = AndWhere(where, cond)
+	}
+	return where, args, nil
+}
+
+// filterColumn is the column or expression a filter compares. A NULL "ReceivedTimestamp" is
+// compared as the "JobStatusTimestamp", as in AsOfWhere.
+func (d Dialect) filterColumn(field jobStatus.FieldName) (string, bool) {
+	if field == jobStatus.FieldReceivedTimestamp {
+		return "COALESCE(" + d.QuoteIdent("ReceivedTimestamp") + ", " + d.QuoteIdent("JobStatusTimestamp") + ")", true
+	}
+	return d.Column(field)
+}
+
+// EscapeLike escapes LIKE wildcards with backslashes so a prefix matches literally.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,140 @@
+package dbcommon
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// testDialect numbers parameters like Postgres and formats dates as text like MySQL and SQLite,
+// so one test shows both hooks at work.
+var testDialect = Dialect{
+	QuoteIdent:  func(name string) string { return "[" + name + "]" },
+	Placeholder: func(n int) string { return fmt.Sprintf(":%d", n) },
+	NotEqual:    func(col string, param string) string { return col + " IS NOT " + param },
+	Prefix: func(col string, prefix string, param func(any) string) string {
+		return col + " LIKE " + param(EscapeLike(prefix)+"%")
+	},
+	Arg: func(field jobStatus.FieldName, v any) any {
+		if t, ok := v.(time.Time); ok {
+			return t.Format("2006-01-02")
+		}
+		return v
+	},
+	ErrToCommon: func(err error) *common.CommonError { return common.NewCommonError(common.ErrcdRepoOther, err) },
+}
+
+func TestFilterWhere(t *testing.T) {
+	busDt := time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name      string
+		filter    jobStatus.Filter
+		wantWhere string
+		wantArgs  []any
+	}{
+		{"eq", jobStatus.Filter{Field: jobStatus.FieldHostId, Op: jobStatus.FilterEq, Values: []any{"h1"}}, "[JobId] = :1 AND [HostId] = :2", []any{"od-calc", "h1"}},
+		{"ne", jobStatus.Filter{Field: jobStatus.FieldRunId, Op: jobStatus.FilterNe, Values: []any{"r1"}}, "[JobId] = :1 AND [RunId] IS NOT :2", []any{"od-calc", "r1"}},
+		{"in", jobStatus.Filter{Field: jobStatus.FieldJobStatusCode, Op: jobStatus.FilterIn, Values: []any{"FAIL", "SUCC"}}, "[JobId] = :1 AND [JobStatusCode] IN (:2, :3)", []any{"od-calc", "FAIL", "SUCC"}},
+		{"prefix escapes wildcards", jobStatus.Filter{Field: jobStatus.FieldRunId, Op: jobStatus.FilterPrefix, Values: []any{`10_%\`}}, "[JobId] = :1 AND [RunId] LIKE :2", []any{"od-calc", `10\_\%\\%`}},
+		{"date goes through Arg", jobStatus.Filter{Field: jobStatus.FieldBusinessDate, Op: jobStatus.FilterGte, Values: []any{busDt}}, "[JobId] = :1 AND [BusinessDate] >= :2", []any{"od-calc", "2023-06-15"}},
+		{"received falls back to status time", jobStatus.Filter{Field: jobStatus.FieldReceivedTimestamp, Op: jobStatus.FilterLt, Values: []any{busDt}}, "[JobId] = :1 AND COALESCE([ReceivedTimestamp], [JobStatusTimestamp]) < :2", []any{"od-calc", "2023-06-15"}},
+	} {
+		where, args, err := testDialect.FilterWhere("[JobId] = :1", []any{"od-calc"}, []jobStatus.Filter{tt.filter})
+		if err != nil {
+			t.Errorf("%s: got %v, want no error", tt.name, err)
+			continue
+		}
+		if where != tt.wantWhere {
+			t.Errorf("%s: got %q, want %q", tt.name, where, tt.wantWhere)
+		}
+		if !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("%s: got args %#v, want %#v", tt.name, args, tt.wantArgs)
+		}
+	}
+}
+
+func TestFilterWhereRefusesBadFilters(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		filter jobStatus.Filter
+	}{
+		{"unknown field", jobStatus.Filter{Field: "Password", Op: jobStatus.FilterEq, Values: []any{"x"}}},
+		{"field that can't be filtered", jobStatus.Filter{Field: jobStatus.FieldLinks, Op: jobStatus.FilterEq, Values: []any{"x"}}},
+		{"op the field doesn't allow", jobStatus.Filter{Field: jobStatus.FieldJobStatusTimestamp, Op: jobStatus.FilterIn, Values: []any{time.Now()}}},
+		{"op that isn't SQL", jobStatus.Filter{Field: jobStatus.FieldJobId, Op: "; DROP TABLE", Values: []any{"x"}}},
+		{"no values", jobStatus.Filter{Field: jobStatus.FieldJobId, Op: jobStatus.FilterEq}},
+	} {
+		if _, _, err := testDialect.FilterWhere("", nil, []jobStatus.Filter{tt.filter}); common.ErrorCode(err) != common.ErrcdDomainProps {
+			t.Errorf("%s: got %v, want %s", tt.name, err, common.ErrcdDomainProps)
+		}
+	}
+}
+
+func TestOptionsWhereStartsWithoutACondition(t *testing.T) {
+	asOf := time.Date(2023, 6, 16, 12, 0, 0, 0, time.UTC)
+	opts := jobStatus.QueryOptions{
+		AsOf:    asOf,
+		Filters: []jobStatus.Filter{{Field: jobStatus.FieldJobId, Op: jobStatus.FilterEq, Values: []any{"od-calc"}}},
+	}
+
+	where, args, err := testDialect.OptionsWhere("", nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "COALESCE([ReceivedTimestamp], [JobStatusTimestamp]) <= :1 AND [JobId] = :2"; where != want {
+		t.Errorf("got %q, want %q", where, want)
+	}
+	if want := []any{"2023-06-16", "od-calc"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("got args %#v, want %#v", args, want)
+	}
+}
+
+func TestBuildSelectAndPageQuery(t *testing.T) {
+	fields := []jobStatus.FieldName{jobStatus.FieldStatusId, jobStatus.FieldJobId}
+
+	for _, tt := range []struct {
+		name      string
+		opts      jobStatus.QueryOptions
+		wantQuery string
+		wantArgs  []any
+	}{
+		{"not paged", jobStatus.QueryOptions{}, "SELECT [StatusId], [JobId] FROM [JobStatus] WHERE [JobId] = :1", []any{"od-calc"}},
+		{"paged without a sort", jobStatus.QueryOptions{Limit: 10}, "SELECT [StatusId], [JobId] FROM [JobStatus] WHERE [JobId] = :1 ORDER BY [StatusId] LIMIT :2 OFFSET :3", []any{"od-calc", 10, 0}},
+		{
+			"paged with a sort",
+			jobStatus.QueryOptions{Limit: 10, Offset: 20, Sort: []jobStatus.SortField{{Field: jobStatus.FieldBusinessDate, Descending: true}, {Field: jobStatus.FieldJobId}}},
+			"SELECT [StatusId], [JobId] FROM [JobStatus] WHERE [JobId] = :1 ORDER BY [BusinessDate] DESC, [JobId], [StatusId] LIMIT :2 OFFSET :3",
+			[]any{"od-calc", 10, 20},
+		},
+	} {
+		query, err := testDialect.BuildSelect(fields, "[JobId] = :1", tt.opts.Sort)
+		if err != nil {
+			t.Errorf("%s: got %v, want no error", tt.name, err)
+			continue
+		}
+		query, args := testDialect.PageQuery(query, []any{"od-calc"}, tt.opts)
+		if query != tt.wantQuery {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, query, tt.wantQuery)
+		}
+		if !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("%s: got args %#v, want %#v", tt.name, args, tt.wantArgs)
+		}
+	}
+}
+
+func TestBuildSelectRefusesUnknownFieldsAndSorts(t *testing.T) {
+	if _, err := testDialect.BuildSelect([]jobStatus.FieldName{"Password"}, "1 = 1", nil); common.ErrorCode(err) != common.ErrcdDomainProps {
+		t.Errorf("unknown field: got %v, want %s", err, common.ErrcdDomainProps)
+	}
+	if _, err := testDialect.BuildSelect([]jobStatus.FieldName{jobStatus.FieldStatusId}, "1 = 1", []jobStatus.SortField{{Field: jobStatus.FieldHostId}}); common.ErrorCode(err) != common.ErrcdDomainProps {
+		t.Errorf("unsortable field: got %v, want %s", err, common.ErrcdDomainProps)
+	}
+	if _, err := testDialect.OrderBy([]jobStatus.SortField{{Field: "1; DROP TABLE"}}); common.ErrorCode(err) != common.ErrcdDomainProps {
+		t.Errorf("unknown sort: got %v, want %s", err, common.ErrcdDomainProps)
+	}
+}
@@ -0,0 +1,238 @@
+package dbcommon
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// NullIfEmpty stores "not reported" as NULL so it reads back the same way.
+func NullIfEmpty(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: len(s) > 0}
+}
+
+// LinkDb is one element of the "Links" JSON column.
+type LinkDb struct {
+	Kind string
+	Url  string
+}
+
+// LinksToDb stores no links as NULL, so most rows don't carry an empty array.
+func (d Dialect) LinksToDb(links []jobStatus.Link) (any, error) {
+	if len(links) == 0 {
+		return nil, nil
+	}
+	linkDbs := make([]LinkDb, len(links))
+	for i, l := range links {
+		linkDbs[i] = LinkDb{Kind: string(l.Kind), Url: l.Url}
+	}
+	b, err := json.Marshal(linkDbs)
+	if err != nil {
+		return nil, err
+	}
+	if d.JsonAsText {
+		return string(b), nil
+	}
+	return b, nil
+}
+
+// MetadataToDb stores no metadata as NULL, like LinksToDb. It's a JSON object.
+func (d Dialect) MetadataToDb(m jobStatus.Metadata) (any, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	if d.JsonAsText {
+		return string(b), nil
+	}
+	return b, nil
+}
+
+// Row mirrors a "JobStatus" row. RunId and HostId are nullable so older rows and columns
+// relaxed to NULL scan instead of failing; NULL becomes "not reported" in the domain.
+// ReportedJobId is NULL unless an alias mapped the status to another JobId. ReceivedTimestamp
+// is NULL for rows stored before it was recorded. Links and Metadata are JSON, NULL if there
+// aren't any. HeartbeatTimestamp is NULL unless the run's START got a heartbeat.
+type Row struct {
+	StatusId           string
+	ApplicationId      string
+	JobId              string
+	JobStatusCode      string
+	JobStatusTimestamp time.Time
+	BusinessDate       time.Time
+	RunId              sql.NullString
+	HostId             sql.NullString
+	ReportedJobId      sql.NullString
+	ReceivedTimestamp  sql.NullTime
+	Links              []byte
+	Metadata           []byte
+	HeartbeatTimestamp sql.NullTime
+}
+
+// timeScanner scans a time.Time, or text in layout, into a sql.NullTime.
+type timeScanner struct {
+	dst    *sql.NullTime
+	layout string
+}
+
+func (ts timeScanner) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*ts.dst = sql.NullTime{}
+		return nil
+	case time.Time:
+		*ts.dst = sql.NullTime{Time: v, Valid: true}
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("can't scan %T as a time", src)
+	}
+	if ts.layout == "" {
+		return fmt.Errorf("can't scan text %q as a time", s)
+	}
+	t, err := time.Parse(ts.layout, s)
+	if err != nil {
+		return err
+	}
+	*ts.dst = sql.NullTime{Time: t, Valid: true}
+	return nil
+}
+
+// requiredTime scans a NOT NULL time column into *dst.
+type requiredTime struct {
+	dst    *time.Time
+	layout string
+}
+
+func (rt requiredTime) Scan(src any) error {
+	if src == nil {
+		return fmt.Errorf("NULL isn't a time")
+	}
+	var nt sql.NullTime
+	if err := (timeScanner{dst: &nt, layout: rt.layout}).Scan(src); err != nil {
+		return err
+	}
+	*rt.dst = nt.Time
+	return nil
+}
+
+// ScanTargets returns pointers into row in the order of fields.
+func (d Dialect) ScanTargets(row *Row, fields []jobStatus.FieldName) []any {
+	targets := make([]any, len(fields))
+	for i, field := range fields {
+		switch field {
+		case jobStatus.FieldStatusId:
+			targets[i] = &row.StatusId
+		case jobStatus.FieldApplicationId:
+			targets[i] = &row.ApplicationId
+		case jobStatus.FieldJobId:
+			targets[i] = &row.JobId
+		case jobStatus.FieldJobStatusCode:
+			targets[i] = &row.JobStatusCode
+		case jobStatus.FieldJobStatusTimestamp:
+			targets[i] = requiredTime{dst: &row.JobStatusTimestamp, layout: d.TimestampLayout}
+		case jobStatus.FieldBusinessDate:
+			targets[i] = requiredTime{dst: &row.BusinessDate, layout: d.DateLayout}
+		case jobStatus.FieldRunId:
+			targets[i] = &row.RunId
+		case jobStatus.FieldHostId:
+			targets[i] = &row.HostId
+		case jobStatus.FieldReportedJobId:
+			targets[i] = &row.ReportedJobId
+		case jobStatus.FieldReceivedTimestamp:
+			targets[i] = timeScanner{dst: &row.ReceivedTimestamp, layout: d.TimestampLayout}
+		case jobStatus.FieldLinks:
+			targets[i] = &row.Links
+		case jobStatus.FieldMetadata:
+			targets[i] = &row.Metadata
+		case jobStatus.FieldHeartbeatTimestamp:
+			targets[i] = timeScanner{dst: &row.HeartbeatTimestamp, layout: d.TimestampLayout}
+		}
+	}
+	return targets
+}
+
+// RowsToDomain reads every row. Without allowPartial, the first bad row fails the whole query.
+// With it, bad rows are left out and the good ones come back with a partial result error.
+// An error from rows.Err (the connection or query failed part way) always fails the query.
+func (d Dialect) RowsToDomain(rows *sql.Rows, fields []jobStatus.FieldName, allowPartial bool) ([]jobStatus.JobStatus, error) {
+	var result []jobStatus.JobStatus
+	var rowErrs []jobStatus.RowError
+	for row := 0; rows.Next(); row++ {
+		js, err := d.ScanRow(rows, fields)
+		if err != nil {
+			if !allowPartial {
+				return nil, err
+			}
+			rowErrs = append(rowErrs, jobStatus.RowError{Row: row, Err: err})
+			continue
+		}
+		result = append(result, js)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, d.ErrToCommon(err)
+	}
+	if len(rowErrs) > 0 {
+		return result, jobStatus.NewPartialResultError(rowErrs)
+	}
+	return result, nil
+}
+
+// ScanRow reads the current row. Scan and conversion failures are RowConversionErrors.
+func (d Dialect) ScanRow(rows *sql.Rows, fields []jobStatus.FieldName) (jobStatus.JobStatus, error) {
+	var row Row
+	if err := rows.Scan(d.ScanTargets(&row, fields)...); err != nil {
+		return jobStatus.JobStatus{}, common.NewCommonError(common.ErrcdRepoRowConversion, err)
+	}
+	return RowToDomain(row, fields)
+}
+
+// RowToDomain doesn't revalidate through NewJobStatus, but it rejects status codes the domain
+// doesn't know so they don't leak out to clients. Fields that weren't selected are left at
+// their zero values.
+func RowToDomain(row Row, fields []jobStatus.FieldName) (jobStatus.JobStatus, error) {
+	js := jobStatus.JobStatus{
+		StatusId:           jobStatus.StatusIdType(row.StatusId),
+		ApplicationId:      row.ApplicationId,
+		JobId:              jobStatus.JobIdType(row.JobId),
+		JobStatusCode:      jobStatus.JobStatusCodeType(row.JobStatusCode),
+		JobStatusTimestamp: row.JobStatusTimestamp,
+		BusinessDate:       jobStatus.TruncateToDate(row.BusinessDate),
+		RunId:              jobStatus.RunIdType(row.RunId.String),
+		HostId:             jobStatus.HostIdType(row.HostId.String),
+		ReportedJobId:      jobStatus.JobIdType(row.ReportedJobId.String),
+		ReceivedTimestamp:  row.ReceivedTimestamp.Time,
+		HeartbeatTimestamp: row.HeartbeatTimestamp.Time,
+	}
+	for _, field := range fields {
+		if field == jobStatus.FieldJobStatusCode && !js.JobStatusCode.IsValid() {
+			return jobStatus.JobStatus{}, common.NewCommonError(common.ErrcdRepoRowConversion, fmt.Errorf("unknown JobStatusCode %q", row.JobStatusCode))
+		}
+	}
+	if len(row.Links) > 0 {
+		var linkDbs []LinkDb
+		if err := json.Unmarshal(row.Links, &linkDbs); err != nil {
+			return jobStatus.JobStatus{}, common.NewCommonError(common.ErrcdRepoRowConversion, fmt.Errorf("Links: %w", err))
+		}
+		for _, l := range linkDbs {
+			js.Links = append(js.Links, jobStatus.Link{Kind: jobStatus.LinkKind(l.Kind), Url: l.Url})
+		}
+	}
+	if len(row.Metadata) > 0 {
+		if err := json.Unmarshal(row.Metadata, &js.Metadata); err != nil {
+			return jobStatus.JobStatus{}, common.NewCommonError(common.ErrcdRepoRowConversion, fmt.Errorf("Metadata: %w", err))
+		}
+	}
+	return js, nil
+}
@@ -0,0 +1,120 @@
+package dbcommon
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+func TestTimeScanner(t *testing.T) {
+	ts := time.Date(2023, 6, 15, 7, 30, 0, 0, time.UTC)
+	layout := "2006-01-02 15:04:05"
+
+	for _, tt := range []struct {
+		name    string
+		layout  string
+		src     any
+		want    sql.NullTime
+		wantErr bool
+	}{
+		{"NULL", layout, nil, sql.NullTime{}, false},
+		{"time", "", ts, sql.NullTime{Time: ts, Valid: true}, false},
+		{"text", layout, "2023-06-15 07:30:00", sql.NullTime{Time: ts, Valid: true}, false},
+		{"bytes", layout, []byte("2023-06-15 07:30:00"), sql.NullTime{Time: ts, Valid: true}, false},
+		{"text without a layout", "", "2023-06-15 07:30:00", sql.NullTime{}, true},
+		{"text in another layout", layout, "15/06/2023", sql.NullTime{}, true},
+		{"number", layout, int64(1686814200), sql.NullTime{}, true},
+	} {
+		var got sql.NullTime
+		err := timeScanner{dst: &got, layout: tt.layout}.Scan(tt.src)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, want error %t", tt.name, err, tt.wantErr)
+		}
+		if !got.Time.Equal(tt.want.Time) || got.Valid != tt.want.Valid {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	var required time.Time
+	if err := (requiredTime{dst: &required, layout: layout}).Scan(nil); err == nil {
+		t.Error("requiredTime: got no error for NULL")
+	}
+}
+
+func TestLinksToDb(t *testing.T) {
+	links := []jobStatus.Link{{Kind: "log", Url: "https://logs.example.com/1"}}
+	want := `[{"Kind":"log","Url":"https://logs.example.com/1"}]`
+
+	if got, err := testDialect.LinksToDb(nil); got != nil || err != nil {
+		t.Errorf("no links: got %v, %v, want nil", got, err)
+	}
+	if got, err := testDialect.LinksToDb(links); err != nil || !reflect.DeepEqual(got, []byte(want)) {
+		t.Errorf("bytes: got %#v, %v, want %s", got, err, want)
+	}
+	text := testDialect
+	text.JsonAsText = true
+	if got, err := text.LinksToDb(links); err != nil || got != want {
+		t.Errorf("text: got %#v, %v, want %s", got, err, want)
+	}
+}
+
+func TestMetadataToDb(t *testing.T) {
+	if got, err := testDialect.MetadataToDb(jobStatus.Metadata{}); got != nil || err != nil {
+		t.Errorf("no metadata: got %v, %v, want nil", got, err)
+	}
+	text := testDialect
+	text.JsonAsText = true
+	want := `{"durationMs":"1500","exitCode":"0"}`
+	if got, err := text.MetadataToDb(jobStatus.Metadata{"exitCode": "0", "durationMs": "1500"}); err != nil || got != want {
+		t.Errorf("text: got %#v, %v, want %s", got, err, want)
+	}
+}
+
+func TestRowToDomain(t *testing.T) {
+	row := Row{
+		StatusId:           "s1",
+		JobId:              "od-calc",
+		JobStatusCode:      string(jobStatus.JobStatus_SUCCEED),
+		JobStatusTimestamp: time.Date(2023, 6, 15, 7, 30, 0, 0, time.UTC),
+		BusinessDate:       time.Date(2023, 6, 15, 4, 0, 0, 0, time.UTC),
+		HostId:             sql.NullString{String: "h1", Valid: true},
+		Links:              []byte(`[{"Kind":"log","Url":"https://logs.example.com/1"}]`),
+		Metadata:           []byte(`{"exitCode":"0"}`),
+	}
+
+	js, err := RowToDomain(row, jobStatus.AllFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC); !js.BusinessDate.Equal(want) {
+		t.Errorf("BusinessDate: got %s, want %s", js.BusinessDate, want)
+	}
+	if js.HostId != "h1" || js.RunId != "" {
+		t.Errorf("got HostId %q and RunId %q, want h1 and not reported", js.HostId, js.RunId)
+	}
+	if want := []jobStatus.Link{{Kind: "log", Url: "https://logs.example.com/1"}}; !reflect.DeepEqual(js.Links, want) {
+		t.Errorf("Links: got %v, want %v", js.Links, want)
+	}
+	if want := (jobStatus.Metadata{"exitCode": "0"}); !js.Metadata.Equal(want) {
+		t.Errorf("Metadata: got %v, want %v", js.Metadata, want)
+	}
+
+	for _, tt := range []struct {
+		name string
+		edit func(*Row)
+	}{
+		{"unknown status code", func(r *Row) { r.JobStatusCode = "MAYBE" }},
+		{"bad Links", func(r *Row) { r.Links = []byte(`{`) }},
+		{"bad Metadata", func(r *Row) { r.Metadata = []byte(`["exitCode"]`) }},
+	} {
+		bad := row
+		tt.edit(&bad)
+		if _, err := RowToDomain(bad, jobStatus.AllFields); common.ErrorCode(err) != common.ErrcdRepoRowConversion {
+			t.Errorf("%s: got %v, want %s", tt.name, err, common.ErrcdRepoRowConversion)
+		}
+	}
+}
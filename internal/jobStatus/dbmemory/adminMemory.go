@@ -0,0 +1,243 @@
+package dbmemory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// RenameJob checks everything before changing anything, so a failed rename changes nothing.
+func (repo *RepoMemory) RenameJob(ctx context.Context, from jobStatus.JobIdType, to jobStatus.JobIdType, at time.Time) (jobStatus.JobRenameResult, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var result jobStatus.JobRenameResult
+	if _, ok := repo.aliases[to]; ok {
+		return result, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("%q is an alias; rename to its canonical JobId", to))
+	}
+	var collisions int64
+	for _, pos := range repo.byJobId[from] {
+		key := keyOf(repo.rows[pos])
+		key.jobId = to
+		if _, taken := repo.keys[key]; taken {
+			collisions++
+		}
+	}
+	if collisions > 0 {
+		return result, common.NewCommonError(common.ErrcdRepoDupeRow, fmt.Errorf("%d status(es) for %q duplicate statuses already under %q", collisions, from, to))
+	}
+
+	for _, pos := range repo.byJobId[from] {
+		repo.rows[pos].JobId = to
+		result.StatusesMoved++
+	}
+	repo.reindex()
+	for id, alias := range repo.aliases {
+		if alias.CanonicalJobId == from {
+			alias.CanonicalJobId = to
+			repo.aliases[id] = alias
+			result.AliasesUpdated++
+		}
+	}
+	repo.aliases[from] = jobStatus.JobAlias{AliasJobId: from, CanonicalJobId: to, CreatedTs: at}
+	for name, view := range repo.views {
+		if renamed, ok := jobStatus.RenameJobIds(view.JobIds, from, to); ok {
+			view.JobIds = renamed
+			repo.views[name] = view
+			result.ViewsUpdated++
+		}
+	}
+	return result, nil
+}
+
+// Update checks the corrected status's natural key against the others before changing anything,
+// so a failed correction changes nothing.
+func (repo *RepoMemory) Update(ctx context.Context, c jobStatus.StatusCorrection, correct func(before jobStatus.JobStatus) (jobStatus.JobStatus, error)) (jobStatus.StatusCorrection, error) {
+	if err := ctx.Err(); err != nil {
+		return c, common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	at := -1
+	for i, js := range repo.rows {
+		if js.StatusId == c.StatusId {
+			at = i
+			break
+		}
+	}
+	if at < 0 {
+		return c, common.NewCommonError(common.ErrcdNotFound, fmt.Errorf("status %s does not exist", c.StatusId))
+	}
+
+	c.Before = stored(repo.rows[at])
+	after, err := correct(c.Before)
+	if err != nil {
+		return c, err
+	}
+	after = stored(after)
+	if key := keyOf(after); key != keyOf(c.Before) {
+		if _, taken := repo.keys[key]; taken {
+			return c, common.NewCommonError(common.ErrcdRepoDupeRow, fmt.Errorf("status %s would duplicate another status for job %s run %s", c.StatusId, after.JobId, after.RunId))
+		}
+	}
+	c.After = after
+	repo.rows[at] = after
+	repo.reindex()
+	repo.corrections = append(repo.corrections, c)
+	return c, nil
+}
+
+func (repo *RepoMemory) DeleteByKey(ctx context.Context, c jobStatus.StatusCorrection, key jobStatus.StatusKey) (jobStatus.StatusCorrection, error) {
+	if err := ctx.Err(); err != nil {
+		return c, common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	want := rowKey{jobId: key.JobId, jobStatusCode: key.JobStatusCode, businessDate: key.BusinessDate.Format(dateFormat), runId: key.RunId}
+	for _, pos := range repo.byJobIdDate[jobDateKey{jobId: want.jobId, businessDate: want.businessDate}] {
+		if keyOf(repo.rows[pos]) == want {
+			c.StatusId = repo.rows[pos].StatusId
+			c.Before = stored(repo.rows[pos])
+			repo.rows = append(repo.rows[:pos], repo.rows[pos+1:]...)
+			repo.reindex()
+			repo.corrections = append(repo.corrections, c)
+			return c, nil
+		}
+	}
+	return c, common.NewCommonError(common.ErrcdNotFound, fmt.Errorf("no %s status for job %s run %s", key.JobStatusCode, key.JobId, key.RunId))
+}
+
+func (repo *RepoMemory) ListStatusCorrections(ctx context.Context, statusId jobStatus.StatusIdType) ([]jobStatus.StatusCorrection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var result []jobStatus.StatusCorrection
+	for _, c := range repo.corrections {
+		if c.StatusId == statusId {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// QueryReadOnly fails: analyst SQL is Postgres SQL, and there's no database to run it.
+func (repo *RepoMemory) QueryReadOnly(ctx context.Context, query string, maxRows int, timeout time.Duration) (jobStatus.SqlResult, error) {
+	return jobStatus.SqlResult{}, common.NewCommonError(common.ErrcdDomainProps, errors.New("analyst SQL needs the postgres backend"))
+}
+
+func (repo *RepoMemory) ForEachWithIntegrityHash(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time, fn func(jobStatus.JobStatus) error) error {
+	repo.mu.RLock()
+	matches := repo.match(func(js jobStatus.JobStatus) bool {
+		return js.ApplicationId == applicationId && inDates(js.BusinessDate, fromDate, toDate)
+	})
+	repo.mu.RUnlock()
+
+	sortByDateAndId(matches)
+	return forEach(ctx, matches, fn)
+}
+
+// Snapshot copies everything under one lock and calls fn with a reader over the copies, so
+// writes during fn aren't seen.
+func (repo *RepoMemory) Snapshot(ctx context.Context, fn func(jobStatus.SnapshotReader) error) error {
+	repo.mu.RLock()
+	snap := &snapshotMemory{
+		ctx:      ctx,
+		statuses: repo.match(func(jobStatus.JobStatus) bool { return true }),
+		views:    repo.listSavedViews(),
+		queries:  repo.listScheduledQueries(),
+		aliases:  repo.listJobAliases(),
+	}
+	repo.mu.RUnlock()
+
+	sortByDateAndId(snap.statuses)
+	return fn(snap)
+}
+
+type snapshotMemory struct {
+	ctx      context.Context
+	statuses []jobStatus.JobStatus
+	views    []jobStatus.SavedView
+	queries  []jobStatus.ScheduledQuery
+	aliases  []jobStatus.JobAlias
+}
+
+func (s *snapshotMemory) ForEachJobStatus(fn func(jobStatus.JobStatus) error) error {
+	return forEach(s.ctx, s.statuses, fn)
+}
+
+func (s *snapshotMemory) ListSavedViews() ([]jobStatus.SavedView, error) { return s.views, nil }
+
+func (s *snapshotMemory) ListScheduledQueries() ([]jobStatus.ScheduledQuery, error) {
+	return s.queries, nil
+}
+
+func (s *snapshotMemory) ListJobAliases() ([]jobStatus.JobAlias, error) { return s.aliases, nil }
+
+func sortByDateAndId(jss []jobStatus.JobStatus) {
+	sort.SliceStable(jss, func(i, j int) bool {
+		if !jss[i].BusinessDate.Equal(jss[j].BusinessDate) {
+			return jss[i].BusinessDate.Before(jss[j].BusinessDate)
+		}
+		return jss[i].StatusId < jss[j].StatusId
+	})
+}
+
+// DeleteBefore deletes the oldest statuses added first.
+func (repo *RepoMemory) DeleteBefore(ctx context.Context, cutoff time.Time, cutoffs map[string]time.Time, limit int) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var deleted int64
+	kept := repo.rows[:0]
+	for _, js := range repo.rows {
+		appCutoff, ok := cutoffs[js.ApplicationId]
+		if !ok {
+			appCutoff = cutoff
+		}
+		if deleted < int64(limit) && js.BusinessDate.Format(dateFormat) < appCutoff.Format(dateFormat) {
+			deleted++
+			continue
+		}
+		kept = append(kept, js)
+	}
+	repo.rows = kept
+	repo.reindex()
+	return deleted, nil
+}
+
+// Ping always succeeds; the repo is in the process.
+func (repo *RepoMemory) Ping(ctx context.Context) error {
+	return nil
+}
+
+// IsPrimary is always true; there are no replicas.
+func (repo *RepoMemory) IsPrimary(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// ProbeWrite always succeeds; there's nothing to write to but memory.
+func (repo *RepoMemory) ProbeWrite(ctx context.Context) error {
+	return nil
+}
+
+// Now is the process's clock, so clock skew checks always pass.
+func (repo *RepoMemory) Now(ctx context.Context) (time.Time, error) {
+	return time.Now(), nil
+}
@@ -0,0 +1,200 @@
+package dbmemory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/migrate"
+)
+
+func (repo *RepoMemory) AddRunComment(ctx context.Context, rc jobStatus.RunComment) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.runComments = append(repo.runComments, rc)
+	return nil
+}
+
+// ListRunComments orders comments by CreatedTs and CommentId, like repoDB.
+func (repo *RepoMemory) ListRunComments(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, runId jobStatus.RunIdType) ([]jobStatus.RunComment, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var result []jobStatus.RunComment
+	for _, rc := range repo.runComments {
+		if rc.JobId == jobId && inDates(rc.BusinessDate, businessDate, businessDate) && (runId == "" || rc.RunId == runId) {
+			result = append(result, rc)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].CreatedTs.Equal(result[j].CreatedTs) {
+			return result[i].CreatedTs.Before(result[j].CreatedTs)
+		}
+		return result[i].CommentId < result[j].CommentId
+	})
+	return result, nil
+}
+
+func (repo *RepoMemory) PutSavedView(ctx context.Context, view jobStatus.SavedView) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.views[view.Name] = view
+	return nil
+}
+
+func (repo *RepoMemory) GetSavedView(ctx context.Context, name string) (jobStatus.SavedView, bool, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	view, ok := repo.views[name]
+	return view, ok, nil
+}
+
+func (repo *RepoMemory) ListSavedViews(ctx context.Context) ([]jobStatus.SavedView, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.listSavedViews(), nil
+}
+
+// listSavedViews returns every view, ordered by name. Callers hold repo.mu.
+func (repo *RepoMemory) listSavedViews() []jobStatus.SavedView {
+	result := make([]jobStatus.SavedView, 0, len(repo.views))
+	for _, view := range repo.views {
+		result = append(result, view)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func (repo *RepoMemory) DeleteSavedView(ctx context.Context, name string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	_, ok := repo.views[name]
+	delete(repo.views, name)
+	return ok, nil
+}
+
+// PutScheduledQuery keeps the stored LastRunDate, like the database does.
+func (repo *RepoMemory) PutScheduledQuery(ctx context.Context, sq jobStatus.ScheduledQuery) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	sq.LastRunDate = repo.queries[sq.Name].LastRunDate
+	repo.queries[sq.Name] = sq
+	return nil
+}
+
+func (repo *RepoMemory) GetScheduledQuery(ctx context.Context, name string) (jobStatus.ScheduledQuery, bool, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	sq, ok := repo.queries[name]
+	return sq, ok, nil
+}
+
+func (repo *RepoMemory) ListScheduledQueries(ctx context.Context) ([]jobStatus.ScheduledQuery, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.listScheduledQueries(), nil
+}
+
+// listScheduledQueries returns every query, ordered by name. Callers hold repo.mu.
+func (repo *RepoMemory) listScheduledQueries() []jobStatus.ScheduledQuery {
+	result := make([]jobStatus.ScheduledQuery, 0, len(repo.queries))
+	for _, sq := range repo.queries {
+		result = append(result, sq)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func (repo *RepoMemory) DeleteScheduledQuery(ctx context.Context, name string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	_, ok := repo.queries[name]
+	delete(repo.queries, name)
+	return ok, nil
+}
+
+func (repo *RepoMemory) ClaimScheduledRun(ctx context.Context, name string, runDate time.Time) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	sq, ok := repo.queries[name]
+	if !ok || !sq.LastRunDate.Before(runDate) {
+		return false, nil
+	}
+	sq.LastRunDate = runDate
+	repo.queries[name] = sq
+	return true, nil
+}
+
+func (repo *RepoMemory) ReleaseScheduledRun(ctx context.Context, name string, runDate time.Time, previous time.Time) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	sq, ok := repo.queries[name]
+	if !ok || !sq.LastRunDate.Equal(runDate) {
+		return false, nil
+	}
+	sq.LastRunDate = previous
+	repo.queries[name] = sq
+	return true, nil
+}
+
+func (repo *RepoMemory) PutJobAlias(ctx context.Context, alias jobStatus.JobAlias) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.aliases[alias.AliasJobId] = alias
+	return nil
+}
+
+func (repo *RepoMemory) ListJobAliases(ctx context.Context) ([]jobStatus.JobAlias, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.listJobAliases(), nil
+}
+
+// listJobAliases returns every alias, ordered by AliasJobId. Callers hold repo.mu.
+func (repo *RepoMemory) listJobAliases() []jobStatus.JobAlias {
+	result := make([]jobStatus.JobAlias, 0, len(repo.aliases))
+	for _, alias := range repo.aliases {
+		result = append(result, alias)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].AliasJobId < result[j].AliasJobId })
+	return result
+}
+
+func (repo *RepoMemory) DeleteJobAlias(ctx context.Context, aliasJobId jobStatus.JobIdType) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	_, ok := repo.aliases[aliasJobId]
+	delete(repo.aliases, aliasJobId)
+	return ok, nil
+}
+
+func (repo *RepoMemory) GetCheckpoint(name string) (migrate.Checkpoint, bool, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	cp, ok := repo.checkpoints[name]
+	return cp, ok, nil
+}
+
+func (repo *RepoMemory) SaveCheckpoint(cp migrate.Checkpoint) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.checkpoints[cp.Name] = cp
+	return nil
+}
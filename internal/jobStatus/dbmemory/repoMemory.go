@@ -0,0 +1,391 @@
+// Package dbmemory implements every jobStatus repo port in memory, for tests and for ephemeral
+// deployments like demos and CI environments (GOJST_DB_BACKEND=memory). Everything is lost when
+// the process exits.
+//
+// It enforces the same uniqueness as the "JobStatus" table (StatusId, and JobId, JobStatusCode,
+// BusinessDate, and RunId), stores timestamps at microsecond precision like Postgres, and applies
+// QueryOptions AsOf, Filters, Sort, Limit, and Offset. Results have every field set; callers
+// already drop fields that weren't selected. Reports like rollups and reliability are computed
+// with the same jobStatus.Compute functions other repos that can't use SQL use.
+//
+// To make calls fail in tests, wrap it with chaos.NewChaosRepo.
+package dbmemory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/migrate"
+	"github.com/jmjf/go-jst/internal/region"
+	"github.com/jmjf/go-jst/internal/selftest"
+)
+
+const dateFormat = "2006-01-02"
+
+// rowKey is the "JobStatus" primary key.
+type rowKey struct {
+	jobId         jobStatus.JobIdType
+	jobStatusCode jobStatus.JobStatusCodeType
+	businessDate  string
+	runId         jobStatus.RunIdType
+}
+
+type jobDateKey struct {
+	jobId        jobStatus.JobIdType
+	businessDate string
+}
+
+// runKey identifies a run, like the "RunCost" primary key.
+type runKey struct {
+	jobId        jobStatus.JobIdType
+	businessDate string
+	runId        jobStatus.RunIdType
+}
+
+type rollupKey struct {
+	applicationId string
+	businessDate  string
+}
+
+type meterKey struct {
+	applicationId string
+	month         time.Time
+	endpoint      string
+}
+
+// RepoMemory keeps statuses in a slice in the order they were added. The indexes hold positions
+// in it, also in the order added; changes that move or remove statuses rebuild them. The other
+// tables are maps keyed like their primary keys.
+type RepoMemory struct {
+	mu          sync.RWMutex
+	rows        []jobStatus.JobStatus
+	statusIds   map[jobStatus.StatusIdType]struct{}
+	keys        map[rowKey]struct{}
+	byJobId     map[jobStatus.JobIdType][]int
+	byJobIdDate map[jobDateKey][]int
+
+	rollups     map[rollupKey]jobStatus.DailyRollup
+	runCosts    map[runKey]jobStatus.RunCost
+	runComments []jobStatus.RunComment
+	apiCalls    map[meterKey]int64
+	views       map[string]jobStatus.SavedView
+	queries     map[string]jobStatus.ScheduledQuery
+	aliases     map[jobStatus.JobIdType]jobStatus.JobAlias
+	corrections []jobStatus.StatusCorrection
+	checkpoints map[string]migrate.Checkpoint
+}
+
+var (
+	_ jobStatus.FullRepo     = (*RepoMemory)(nil)
+	_ migrate.CheckpointRepo = (*RepoMemory)(nil)
+	_ region.Database        = (*RepoMemory)(nil)
+	_ selftest.Database      = (*RepoMemory)(nil)
+)
+
+// NewRepoMemory returns an empty repo. It's safe for concurrent use.
+func NewRepoMemory() *RepoMemory {
+	repo := &RepoMemory{
+		rollups:     map[rollupKey]jobStatus.DailyRollup{},
+		runCosts:    map[runKey]jobStatus.RunCost{},
+		apiCalls:    map[meterKey]int64{},
+		views:       map[string]jobStatus.SavedView{},
+		queries:     map[string]jobStatus.ScheduledQuery{},
+		aliases:     map[jobStatus.JobIdType]jobStatus.JobAlias{},
+		checkpoints: map[string]migrate.Checkpoint{},
+	}
+	repo.reindex()
+	return repo
+}
+
+func (repo *RepoMemory) Add(ctx context.Context, js jobStatus.JobStatus) error {
+	if err := ctx.Err(); err != nil {
+		return common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	js = stored(js)
+	if err := repo.checkUnique(js, nil); err != nil {
+		return common.NewCommonError(common.ErrcdRepoDupeRow, err)
+	}
+	repo.insert(js)
+	return nil
+}
+
+func (repo *RepoMemory) AddIdempotent(ctx context.Context, js jobStatus.JobStatus) (jobStatus.JobStatus, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return jobStatus.JobStatus{}, false, common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	js = stored(js)
+	key := keyOf(js)
+	if _, taken := repo.keys[key]; taken {
+		for _, pos := range repo.byJobIdDate[jobDateKey{jobId: js.JobId, businessDate: key.businessDate}] {
+			if keyOf(repo.rows[pos]) == key {
+				return stored(repo.rows[pos]), false, nil
+			}
+		}
+	}
+	if err := repo.checkUnique(js, nil); err != nil {
+		return jobStatus.JobStatus{}, false, common.NewCommonError(common.ErrcdRepoDupeRow, err)
+	}
+	repo.insert(js)
+	return js, true, nil
+}
+
+// AddBatch checks every status before inserting any, so a duplicate adds none of them.
+func (repo *RepoMemory) AddBatch(ctx context.Context, jss []jobStatus.JobStatus) error {
+	if err := ctx.Err(); err != nil {
+		return common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	batch := make([]jobStatus.JobStatus, len(jss))
+	inBatch := map[any]struct{}{}
+	for i, js := range jss {
+		batch[i] = stored(js)
+		if err := repo.checkUnique(batch[i], inBatch); err != nil {
+			return common.NewCommonError(common.ErrcdRepoDupeRow, fmt.Errorf("status %d: %w", i, err))
+		}
+	}
+	for _, js := range batch {
+		repo.insert(js)
+	}
+	return nil
+}
+
+// checkUnique returns an error if js's StatusId or primary key is stored, or is in inBatch.
+// If inBatch isn't nil, js's keys are added to it. Callers hold repo.mu.
+func (repo *RepoMemory) checkUnique(js jobStatus.JobStatus, inBatch map[any]struct{}) error {
+	key := keyOf(js)
+	_, idTaken := repo.statusIds[js.StatusId]
+	_, keyTaken := repo.keys[key]
+	if inBatch != nil {
+		_, idInBatch := inBatch[js.StatusId]
+		_, keyInBatch := inBatch[key]
+		idTaken = idTaken || idInBatch
+		keyTaken = keyTaken || keyInBatch
+		inBatch[js.StatusId] = struct{}{}
+		inBatch[key] = struct{}{}
+	}
+
+	switch {
+	case idTaken:
+		return fmt.Errorf("StatusId %s already exists", js.StatusId)
+	case keyTaken:
+		return fmt.Errorf("status %s for job %s on %s run %s already exists", js.JobStatusCode, js.JobId, key.businessDate, js.RunId)
+	}
+	return nil
+}
+
+// insert appends js and indexes it. Callers hold repo.mu and have checked uniqueness.
+func (repo *RepoMemory) insert(js jobStatus.JobStatus) {
+	pos := len(repo.rows)
+	repo.rows = append(repo.rows, js)
+
+	key := keyOf(js)
+	repo.statusIds[js.StatusId] = struct{}{}
+	repo.keys[key] = struct{}{}
+	repo.byJobId[js.JobId] = append(repo.byJobId[js.JobId], pos)
+	dateKey := jobDateKey{jobId: js.JobId, businessDate: key.businessDate}
+	repo.byJobIdDate[dateKey] = append(repo.byJobIdDate[dateKey], pos)
+}
+
+// reindex rebuilds the indexes from repo.rows after statuses are changed or removed. Callers
+// hold repo.mu.
+func (repo *RepoMemory) reindex() {
+	rows := repo.rows
+	repo.rows = nil
+	repo.statusIds = map[jobStatus.StatusIdType]struct{}{}
+	repo.keys = map[rowKey]struct{}{}
+	repo.byJobId = map[jobStatus.JobIdType][]int{}
+	repo.byJobIdDate = map[jobDateKey][]int{}
+	for _, js := range rows {
+		repo.insert(js)
+	}
+}
+
+func keyOf(js jobStatus.JobStatus) rowKey {
+	return rowKey{jobId: js.JobId, jobStatusCode: js.JobStatusCode, businessDate: js.BusinessDate.Format(dateFormat), runId: js.RunId}
+}
+
+// inDates is true if t's date is fromDate through toDate (inclusive).
+func inDates(t time.Time, fromDate time.Time, toDate time.Time) bool {
+	d := t.Format(dateFormat)
+	return d >= fromDate.Format(dateFormat) && d <= toDate.Format(dateFormat)
+}
+
+// stored copies js the way the database would store it, so later changes to the caller's slices
+// don't change the repo, and timestamps read back as they would from Postgres.
+func stored(js jobStatus.JobStatus) jobStatus.JobStatus {
+	js.JobStatusTimestamp = js.JobStatusTimestamp.Truncate(time.Microsecond)
+	js.ReceivedTimestamp = js.ReceivedTimestamp.Truncate(time.Microsecond)
+	js.HeartbeatTimestamp = js.HeartbeatTimestamp.Truncate(time.Microsecond)
+	if js.Links != nil {
+		js.Links = append([]jobStatus.Link(nil), js.Links...)
+	}
+	js.Metadata = js.Metadata.Clone()
+	if js.IntegrityHash != nil {
+		js.IntegrityHash = append([]byte(nil), js.IntegrityHash...)
+	}
+	return js
+}
+
+func (repo *RepoMemory) RecordHeartbeat(ctx context.Context, key jobStatus.StatusKey, at time.Time) (jobStatus.JobStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return jobStatus.JobStatus{}, common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key.JobStatusCode = jobStatus.JobStatus_START
+	want := rowKey{jobId: key.JobId, jobStatusCode: key.JobStatusCode, businessDate: key.BusinessDate.Format(dateFormat), runId: key.RunId}
+	for _, pos := range repo.byJobIdDate[jobDateKey{jobId: want.jobId, businessDate: want.businessDate}] {
+		if keyOf(repo.rows[pos]) == want {
+			if at = at.Truncate(time.Microsecond); at.After(repo.rows[pos].HeartbeatTimestamp) {
+				repo.rows[pos].HeartbeatTimestamp = at
+			}
+			return stored(repo.rows[pos]), nil
+		}
+	}
+	return jobStatus.JobStatus{}, common.NewCommonError(common.ErrcdNotFound, fmt.Errorf("no START status for job %s run %s on %s", key.JobId, key.RunId, want.businessDate))
+}
+
+func (repo *RepoMemory) GetByJobId(ctx context.Context, jobId jobStatus.JobIdType, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	return repo.query(ctx, opts, func() []int { return repo.byJobId[jobId] })
+}
+
+func (repo *RepoMemory) GetByJobIdBusinessDate(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	key := jobDateKey{jobId: jobId, businessDate: businessDate.Format(dateFormat)}
+	return repo.query(ctx, opts, func() []int { return repo.byJobIdDate[key] })
+}
+
+// GetByFilters scans every status; there are no indexes on the fields filters use.
+func (repo *RepoMemory) GetByFilters(ctx context.Context, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	return repo.query(ctx, opts, nil)
+}
+
+// ForEach methods copy their results before calling fn, so fn doesn't hold the lock and can
+// take as long as it needs.
+
+func (repo *RepoMemory) ForEachByJobId(ctx context.Context, jobId jobStatus.JobIdType, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	jss, err := repo.GetByJobId(ctx, jobId, opts)
+	if err != nil {
+		return err
+	}
+	return forEach(ctx, jss, fn)
+}
+
+func (repo *RepoMemory) ForEachByJobIdBusinessDate(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	jss, err := repo.GetByJobIdBusinessDate(ctx, jobId, businessDate, opts)
+	if err != nil {
+		return err
+	}
+	return forEach(ctx, jss, fn)
+}
+
+func (repo *RepoMemory) ForEachByFilters(ctx context.Context, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	jss, err := repo.GetByFilters(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return forEach(ctx, jss, fn)
+}
+
+func forEach(ctx context.Context, jss []jobStatus.JobStatus, fn func(jobStatus.JobStatus) error) error {
+	for _, js := range jss {
+		if err := ctx.Err(); err != nil {
+			return common.NewCommonError(common.ErrcdRepoOther, err)
+		}
+		if err := fn(js); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// query returns copies of the statuses at the positions candidates returns that match opts,
+// sorted and paged like the database repos. A nil candidates means every status.
+func (repo *RepoMemory) query(ctx context.Context, opts jobStatus.QueryOptions, candidates func() []int) ([]jobStatus.JobStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	if err := checkOptions(opts); err != nil {
+		return nil, err
+	}
+
+	repo.mu.RLock()
+	var result []jobStatus.JobStatus
+	keep := func(js jobStatus.JobStatus) {
+		if js.KnownAt(opts.AsOf) && jobStatus.MatchesAll(js, opts.Filters) {
+			result = append(result, stored(js))
+		}
+	}
+	if candidates == nil {
+		for _, js := range repo.rows {
+			keep(js)
+		}
+	} else {
+		for _, pos := range candidates() {
+			keep(repo.rows[pos])
+		}
+	}
+	repo.mu.RUnlock()
+
+	if opts.Limit <= 0 {
+		jobStatus.SortJobStatuses(result, opts.Sort)
+		return result, nil
+	}
+
+	// pages are in Sort order and then StatusId order, like the database repos'
+	sort.SliceStable(result, func(i, j int) bool { return result[i].StatusId < result[j].StatusId })
+	jobStatus.SortJobStatuses(result, opts.Sort)
+	if opts.Offset >= len(result) {
+		return nil, nil
+	}
+	result = result[opts.Offset:]
+	if len(result) > opts.Limit {
+		result = result[:opts.Limit]
+	}
+	return result, nil
+}
+
+// match returns copies of the statuses keep is true for, in the order they were added. Callers
+// hold repo.mu.
+func (repo *RepoMemory) match(keep func(jobStatus.JobStatus) bool) []jobStatus.JobStatus {
+	var result []jobStatus.JobStatus
+	for _, js := range repo.rows {
+		if keep(js) {
+			result = append(result, stored(js))
+		}
+	}
+	return result
+}
+
+// checkOptions refuses sorts and filters the database repos would, so code tried against this
+// repo doesn't fail later against a real one.
+func checkOptions(opts jobStatus.QueryOptions) error {
+	for _, sf := range opts.Sort {
+		if !jobStatus.SortableFields[sf.Field] {
+			return common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("cannot sort on %q", sf.Field))
+		}
+	}
+	for _, f := range opts.Filters {
+		if !jobStatus.IsFilterOpAllowed(f.Field, f.Op) || len(f.Values) == 0 {
+			return common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("can't filter %s %s", f.Field, f.Op))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package dbmemory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+var busDt = time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)
+
+func newStatus(t *testing.T, code string, runId string, ts time.Time) jobStatus.JobStatus {
+	t.Helper()
+	js, err := jobStatus.NewJobStatus("overdrafts", "od-calc", code, ts, busDt, jobStatus.RunIdType(runId), "batch01")
+	if err != nil {
+		t.Fatalf("NewJobStatus: %v", err)
+	}
+	return js
+}
+
+func at(hour int, minute int, second int) time.Time {
+	return time.Date(2023, 6, 16, hour, minute, second, 0, time.UTC)
+}
+
+func TestAddRefusesDuplicates(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepoMemory()
+	js := newStatus(t, "SUCCEED", "1", at(1, 0, 0))
+	if err := repo.Add(ctx, js); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	sameId := js
+	sameId.RunId = "2"
+	sameKey := newStatus(t, "SUCCEED", "1", at(2, 0, 0))
+	for name, dupe := range map[string]jobStatus.JobStatus{"StatusId": sameId, "key": sameKey} {
+		if err := repo.Add(ctx, dupe); common.ErrorCode(err) != common.ErrcdRepoDupeRow {
+			t.Errorf("Add with the same %s: got %v, want %s", name, err, common.ErrcdRepoDupeRow)
+		}
+	}
+}
+
+func TestAddIdempotentReturnsStoredStatus(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepoMemory()
+	first := newStatus(t, "SUCCEED", "1", at(1, 0, 0))
+	if _, added, err := repo.AddIdempotent(ctx, first); err != nil || !added {
+		t.Fatalf("first AddIdempotent: added %v, err %v", added, err)
+	}
+
+	got, added, err := repo.AddIdempotent(ctx, newStatus(t, "SUCCEED", "1", at(1, 0, 0)))
+	if err != nil || added {
+		t.Fatalf("second AddIdempotent: added %v, err %v", added, err)
+	}
+	if got.StatusId != first.StatusId {
+		t.Errorf("got StatusId %s, want the first add's %s", got.StatusId, first.StatusId)
+	}
+}
+
+func TestAddBatchAddsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepoMemory()
+	one := newStatus(t, "START", "1", at(1, 0, 0))
+	err := repo.AddBatch(ctx, []jobStatus.JobStatus{one, newStatus(t, "SUCCEED", "1", at(1, 0, 1)), one})
+	if common.ErrorCode(err) != common.ErrcdRepoDupeRow {
+		t.Fatalf("AddBatch with a repeat: got %v, want %s", err, common.ErrcdRepoDupeRow)
+	}
+	jss, err := repo.GetByJobId(ctx, "od-calc", jobStatus.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetByJobId: %v", err)
+	}
+	if len(jss) != 0 {
+		t.Errorf("got %d statuses after a failed batch, want 0", len(jss))
+	}
+}
+
+func TestQuerySortsAndPages(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepoMemory()
+	for i, runId := range []string{"1", "2", "3", "4"} {
+		if err := repo.Add(ctx, newStatus(t, "SUCCEED", runId, at(1, i, 0))); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	opts := jobStatus.QueryOptions{Sort: []jobStatus.SortField{{Field: jobStatus.FieldJobStatusTimestamp, Descending: true}}, Limit: 2, Offset: 1}
+	jss, err := repo.GetByJobIdBusinessDate(ctx, "od-calc", busDt, opts)
+	if err != nil {
+		t.Fatalf("GetByJobIdBusinessDate: %v", err)
+	}
+	if len(jss) != 2 || jss[0].RunId != "3" || jss[1].RunId != "2" {
+		t.Errorf("got %v, want runs 3 and 2", runIds(jss))
+	}
+
+	if _, err := repo.GetByJobId(ctx, "od-calc", jobStatus.QueryOptions{Sort: []jobStatus.SortField{{Field: jobStatus.FieldHostId}}}); common.ErrorCode(err) != common.ErrcdDomainProps {
+		t.Errorf("sort on HostId: got %v, want %s like repoDB", err, common.ErrcdDomainProps)
+	}
+}
+
+func TestRollupDailyComputesFromStatusesAndCosts(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepoMemory()
+	for _, js := range []jobStatus.JobStatus{
+		newStatus(t, "START", "1", at(1, 0, 0)),
+		newStatus(t, "SUCCEED", "1", at(1, 0, 1)),
+		newStatus(t, "START", "2", at(2, 0, 0)),
+		newStatus(t, "FAIL", "2", at(2, 0, 2)),
+		newStatus(t, "START", "3", at(3, 0, 0)),
+	} {
+		if err := repo.Add(ctx, js); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	if err := repo.PutRunCost(context.Background(), jobStatus.RunCost{ApplicationId: "overdrafts", JobId: "od-calc", BusinessDate: busDt, RunId: "2", ComputeHours: 1, CostUsd: 4}); err != nil {
+		t.Fatalf("PutRunCost: %v", err)
+	}
+
+	n, err := repo.RollupDaily(context.Background(), busDt, busDt)
+	if err != nil || n != 1 {
+		t.Fatalf("RollupDaily: got %d, %v; want 1 row", n, err)
+	}
+	drs, err := repo.GetDailyRollups(context.Background(), "overdrafts", busDt, busDt)
+	if err != nil || len(drs) != 1 {
+		t.Fatalf("GetDailyRollups: got %v, %v; want 1 rollup", drs, err)
+	}
+	dr := drs[0]
+	dr.RolledUpTimestamp = time.Time{}
+	want := jobStatus.DailyRollup{
+		ApplicationId: "overdrafts", BusinessDate: busDt,
+		StartCount: 3, SucceedCount: 1, FailCount: 1, RunCount: 3, CompletedRunCount: 2,
+		TotalDuration: 3 * time.Second, MinDuration: time.Second, MaxDuration: 2 * time.Second,
+		ComputeHours: 1, CostUsd: 4, FailedCostUsd: 4,
+	}
+	if dr != want {
+		t.Errorf("got %+v, want %+v", dr, want)
+	}
+}
+
+func TestCorrectionsKeepIndexesCurrent(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepoMemory()
+	first := newStatus(t, "START", "1", at(1, 0, 0))
+	second := newStatus(t, "SUCCEED", "1", at(1, 0, 1))
+	if err := repo.AddBatch(ctx, []jobStatus.JobStatus{first, second}); err != nil {
+		t.Fatalf("AddBatch: %v", err)
+	}
+
+	c, _ := jobStatus.NewStatusCorrection(second.StatusId, "ops@example.com", "wrong code")
+	_, err := repo.Update(ctx, c, func(before jobStatus.JobStatus) (jobStatus.JobStatus, error) {
+		before.JobStatusCode = jobStatus.JobStatus_START
+		return before, nil
+	})
+	if common.ErrorCode(err) != common.ErrcdRepoDupeRow {
+		t.Errorf("Update to a stored key: got %v, want %s", err, common.ErrcdRepoDupeRow)
+	}
+
+	d, _ := jobStatus.NewStatusDeletion("ops@example.com", "sent twice")
+	if _, err := repo.DeleteByKey(ctx, d, jobStatus.StatusKey{JobId: "od-calc", JobStatusCode: jobStatus.JobStatus_START, BusinessDate: busDt, RunId: "1"}); err != nil {
+		t.Fatalf("DeleteByKey: %v", err)
+	}
+	if _, err := repo.RenameJob(context.Background(), "od-calc", "od-calc-v2", at(5, 0, 0)); err != nil {
+		t.Fatalf("RenameJob: %v", err)
+	}
+
+	jss, err := repo.GetByJobIdBusinessDate(ctx, "od-calc-v2", busDt, jobStatus.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetByJobIdBusinessDate: %v", err)
+	}
+	if len(jss) != 1 || jss[0].StatusId != second.StatusId {
+		t.Errorf("got %v under the new JobId, want only the SUCCEED", runIds(jss))
+	}
+	if err := repo.Add(ctx, newStatus(t, "START", "1", at(6, 0, 0))); err != nil {
+		t.Errorf("Add of the deleted key under the old JobId: %v", err)
+	}
+}
+
+func TestDeleteBeforeHonorsCutoffsAndLimit(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepoMemory()
+	for i, runId := range []string{"1", "2", "3"} {
+		js := newStatus(t, "SUCCEED", runId, at(1, i, 0))
+		js.BusinessDate = busDt.AddDate(0, 0, -i)
+		if err := repo.Add(ctx, js); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	n, err := repo.DeleteBefore(ctx, busDt, nil, 1)
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteBefore with limit 1: got %d, %v; want 1", n, err)
+	}
+	n, err = repo.DeleteBefore(ctx, busDt, map[string]time.Time{"overdrafts": {}}, 10)
+	if err != nil || n != 0 {
+		t.Errorf("DeleteBefore with a zero application cutoff: got %d, %v; want 0", n, err)
+	}
+	n, err = repo.DeleteBefore(ctx, busDt, nil, 10)
+	if err != nil || n != 1 {
+		t.Errorf("DeleteBefore: got %d, %v; want the 1 left before the cutoff", n, err)
+	}
+}
+
+func runIds(jss []jobStatus.JobStatus) []jobStatus.RunIdType {
+	ids := make([]jobStatus.RunIdType, len(jss))
+	for i, js := range jss {
+		ids[i] = js.RunId
+	}
+	return ids
+}
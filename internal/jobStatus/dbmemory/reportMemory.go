@@ -0,0 +1,213 @@
+package dbmemory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// RollupDaily recomputes rollups with jobStatus.ComputeDailyRollups. Like repoDB, dates with no
+// statuses keep the rollups they have.
+func (repo *RepoMemory) RollupDaily(ctx context.Context, fromDate time.Time, toDate time.Time) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	jss := repo.match(func(js jobStatus.JobStatus) bool { return inDates(js.BusinessDate, fromDate, toDate) })
+	var costs []jobStatus.RunCost
+	for _, rc := range repo.runCosts {
+		if inDates(rc.BusinessDate, fromDate, toDate) {
+			costs = append(costs, rc)
+		}
+	}
+
+	drs := jobStatus.ComputeDailyRollups(jss, costs, time.Now())
+	for _, dr := range drs {
+		repo.rollups[rollupKey{applicationId: dr.ApplicationId, businessDate: dr.BusinessDate.Format(dateFormat)}] = dr
+	}
+	return int64(len(drs)), nil
+}
+
+// GetDailyRollups orders rollups by ApplicationId and BusinessDate, like repoDB.
+func (repo *RepoMemory) GetDailyRollups(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time) ([]jobStatus.DailyRollup, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var result []jobStatus.DailyRollup
+	for _, dr := range repo.rollups {
+		if (applicationId == "" || dr.ApplicationId == applicationId) && inDates(dr.BusinessDate, fromDate, toDate) {
+			result = append(result, dr)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ApplicationId != result[j].ApplicationId {
+			return result[i].ApplicationId < result[j].ApplicationId
+		}
+		return result[i].BusinessDate.Before(result[j].BusinessDate)
+	})
+	return result, nil
+}
+
+func (repo *RepoMemory) GetJobReliability(ctx context.Context, applicationId string, jobId jobStatus.JobIdType, fromDate time.Time, toDate time.Time) ([]jobStatus.JobReliability, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	type jobKey struct {
+		applicationId string
+		jobId         jobStatus.JobIdType
+	}
+	byJob := map[jobKey][]jobStatus.JobStatus{}
+	for _, js := range repo.rows {
+		if (applicationId == "" || js.ApplicationId == applicationId) && (jobId == "" || js.JobId == jobId) && inDates(js.BusinessDate, fromDate, toDate) {
+			key := jobKey{js.ApplicationId, js.JobId}
+			byJob[key] = append(byJob[key], js)
+		}
+	}
+
+	var result []jobStatus.JobReliability
+	for key, jss := range byJob {
+		jr := jobStatus.ComputeJobReliability(key.applicationId, key.jobId, fromDate, toDate, jss)
+		if jr.TerminalCount > 0 {
+			result = append(result, jr)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ApplicationId != result[j].ApplicationId {
+			return result[i].ApplicationId < result[j].ApplicationId
+		}
+		return result[i].JobId < result[j].JobId
+	})
+	return result, nil
+}
+
+func (repo *RepoMemory) GetDurationBaselines(ctx context.Context, applicationId string, jobId jobStatus.JobIdType, fromDate time.Time, toDate time.Time) ([]jobStatus.DurationBaseline, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	byJob := map[jobStatus.JobIdType][]jobStatus.JobStatus{}
+	for _, js := range repo.rows {
+		if js.ApplicationId == applicationId && (jobId == "" || js.JobId == jobId) && inDates(js.BusinessDate, fromDate, toDate) {
+			byJob[js.JobId] = append(byJob[js.JobId], js)
+		}
+	}
+	jobIds := make([]jobStatus.JobIdType, 0, len(byJob))
+	for id := range byJob {
+		jobIds = append(jobIds, id)
+	}
+	sort.Slice(jobIds, func(i, j int) bool { return jobIds[i] < jobIds[j] })
+
+	var result []jobStatus.DurationBaseline
+	for _, id := range jobIds {
+		result = append(result, jobStatus.ComputeDurationBaselines(applicationId, id, byJob[id])...)
+	}
+	return result, nil
+}
+
+func (repo *RepoMemory) PutRunCost(ctx context.Context, rc jobStatus.RunCost) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.runCosts[runKey{jobId: rc.JobId, businessDate: rc.BusinessDate.Format(dateFormat), runId: rc.RunId}] = rc
+	return nil
+}
+
+func (repo *RepoMemory) GetJobCosts(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time) ([]jobStatus.JobCost, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var costs []jobStatus.RunCost
+	for _, rc := range repo.runCosts {
+		if inDates(rc.BusinessDate, fromDate, toDate) {
+			costs = append(costs, rc)
+		}
+	}
+	jss := repo.match(func(js jobStatus.JobStatus) bool { return inDates(js.BusinessDate, fromDate, toDate) })
+	return jobStatus.ComputeJobCosts(applicationId, fromDate, toDate, costs, jss), nil
+}
+
+func (repo *RepoMemory) CountByApplicationBusinessDate(ctx context.Context, applicationId string, businessDate time.Time) (int64, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var n int64
+	for _, js := range repo.rows {
+		if js.ApplicationId == applicationId && inDates(js.BusinessDate, businessDate, businessDate) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (repo *RepoMemory) AddApiCalls(ctx context.Context, counts []jobStatus.ApiCallCount) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, c := range counts {
+		repo.apiCalls[meterKey{applicationId: c.ApplicationId, month: c.Month, endpoint: c.Endpoint}] += c.Count
+	}
+	return nil
+}
+
+// GetApiCalls orders counts by ApplicationId and Endpoint.
+func (repo *RepoMemory) GetApiCalls(ctx context.Context, month time.Time) ([]jobStatus.ApiCallCount, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var result []jobStatus.ApiCallCount
+	for key, n := range repo.apiCalls {
+		if key.month.Equal(month) {
+			result = append(result, jobStatus.ApiCallCount{ApplicationId: key.applicationId, Month: key.month, Endpoint: key.endpoint, Count: n})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ApplicationId != result[j].ApplicationId {
+			return result[i].ApplicationId < result[j].ApplicationId
+		}
+		return result[i].Endpoint < result[j].Endpoint
+	})
+	return result, nil
+}
+
+func (repo *RepoMemory) GetLatestByJobIds(ctx context.Context, jobIds []jobStatus.JobIdType, businessDate time.Time, asOf time.Time) ([]jobStatus.JobStatus, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var result []jobStatus.JobStatus
+	for _, jobId := range jobIds {
+		latest := -1
+		for _, pos := range repo.byJobIdDate[jobDateKey{jobId: jobId, businessDate: businessDate.Format(dateFormat)}] {
+			js := repo.rows[pos]
+			if js.KnownAt(asOf) && (latest < 0 || js.JobStatusTimestamp.After(repo.rows[latest].JobStatusTimestamp)) {
+				latest = pos
+			}
+		}
+		if latest >= 0 {
+			result = append(result, stored(repo.rows[latest]))
+		}
+	}
+	return result, nil
+}
+
+func (repo *RepoMemory) GetLatestByJobId(ctx context.Context, jobId jobStatus.JobIdType, fromDate time.Time, toDate time.Time, asOf time.Time) ([]jobStatus.JobStatus, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	latest := map[string]jobStatus.JobStatus{}
+	for _, pos := range repo.byJobId[jobId] {
+		js := repo.rows[pos]
+		if !inDates(js.BusinessDate, fromDate, toDate) || !js.KnownAt(asOf) {
+			continue
+		}
+		date := js.BusinessDate.Format(dateFormat)
+		if cur, ok := latest[date]; !ok || js.JobStatusTimestamp.After(cur.JobStatusTimestamp) {
+			latest[date] = js
+		}
+	}
+	result := make([]jobStatus.JobStatus, 0, len(latest))
+	for _, js := range latest {
+		result = append(result, stored(js))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].BusinessDate.After(result[j].BusinessDate) })
+	return result, nil
+}
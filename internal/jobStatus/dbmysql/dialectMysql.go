@@ -0,0 +1,34 @@
+package dbmysql
+
+import (
+	"time"
+
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbcommon"
+)
+
+// dialect is MySQL's: backquoted names and ? parameters. ne is NOT <=>, MySQL's null-safe
+// equality negated. Dates are sent as DATE strings and timestamps in UTC, to match what's stored.
+var dialect = dbcommon.Dialect{
+	QuoteIdent:  func(name string) string { return "`" + name + "`" },
+	Placeholder: func(n int) string { return "?" },
+	NotEqual:    func(col string, param string) string { return "NOT (" + col + " <=> " + param + ")" },
+	Prefix: func(col string, prefix string, param func(any) string) string {
+		return col + " LIKE " + param(dbcommon.EscapeLike(prefix)+"%") + ` ESCAPE '\\'`
+	},
+	Arg:         filterArg,
+	ErrToCommon: mysqlErrToCommon,
+	// a string, so the driver sends JSON text rather than binary
+	JsonAsText: true,
+}
+
+func filterArg(field jobStatus.FieldName, v any) any {
+	t, ok := v.(time.Time)
+	if !ok {
+		return v
+	}
+	if field == jobStatus.FieldBusinessDate {
+		return t.Format("2006-01-02")
+	}
+	return t.UTC()
+}
@@ -0,0 +1,55 @@
+package dbmysql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// MySQL server error numbers we care about.
+// See https://mariadb.com/kb/en/mariadb-error-codes/
+const (
+	mysqlDupEntry          = 1062
+	mysqlTooManyConns      = 1040
+	mysqlServerShutdown    = 1053
+	mysqlConnCountExceeded = 1203
+	mysqlLockWaitTimeout   = 1205
+	mysqlDeadlock          = 1213
+)
+
+// mysqlErrToCommon converts an error returned by the MySQL driver into a CommonError.
+func mysqlErrToCommon(err error) *common.CommonError {
+	if err == nil {
+		return nil
+	}
+
+	var ce *common.CommonError
+	if errors.As(err, &ce) {
+		return ce
+	}
+
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		switch myErr.Number {
+		case mysqlDupEntry:
+			return common.NewCommonError(common.ErrcdRepoDupeRow, err)
+		case mysqlTooManyConns, mysqlServerShutdown, mysqlConnCountExceeded:
+			return common.NewCommonError(common.ErrcdRepoConnection, err)
+		case mysqlLockWaitTimeout, mysqlDeadlock:
+			return common.NewCommonError(common.ErrcdRepoTransient, err)
+		}
+		return common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+
+	// the driver's ErrInvalidConn and failures before a session exists don't have a number
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.As(err, &netErr) {
+		return common.NewCommonError(common.ErrcdRepoConnection, err)
+	}
+
+	return common.NewCommonError(common.ErrcdRepoOther, err)
+}
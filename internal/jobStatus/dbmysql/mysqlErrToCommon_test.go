@@ -0,0 +1,41 @@
+package dbmysql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+func TestMysqlErrToCommon(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"duplicate entry", &mysql.MySQLError{Number: mysqlDupEntry}, common.ErrcdRepoDupeRow},
+		{"wrapped duplicate entry", fmt.Errorf("status 3: %w", &mysql.MySQLError{Number: mysqlDupEntry}), common.ErrcdRepoDupeRow},
+		{"too many connections", &mysql.MySQLError{Number: mysqlTooManyConns}, common.ErrcdRepoConnection},
+		{"lock wait timeout", &mysql.MySQLError{Number: mysqlLockWaitTimeout, Message: "Lock wait timeout exceeded; try restarting transaction"}, common.ErrcdRepoTransient},
+		{"deadlock", &mysql.MySQLError{Number: mysqlDeadlock, Message: "Deadlock found when trying to get lock; try restarting transaction"}, common.ErrcdRepoTransient},
+		{"wrapped deadlock", fmt.Errorf("status 3: %w", &mysql.MySQLError{Number: mysqlDeadlock}), common.ErrcdRepoTransient},
+		{"other server error", &mysql.MySQLError{Number: 1146, Message: "Table 'gojst.JobStatus' doesn't exist"}, common.ErrcdRepoOther},
+		{"invalid connection", mysql.ErrInvalidConn, common.ErrcdRepoConnection},
+		{"bad connection", driver.ErrBadConn, common.ErrcdRepoConnection},
+		{"refused", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, common.ErrcdRepoConnection},
+		{"already a CommonError", common.NewCommonError(common.ErrcdDomainProps, errors.New("bad")), common.ErrcdDomainProps},
+		{"anything else", errors.New("oops"), common.ErrcdRepoOther},
+	} {
+		if got := mysqlErrToCommon(tc.err); got.Code != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got.Code, tc.want)
+		}
+	}
+	if mysqlErrToCommon(nil) != nil {
+		t.Error("nil: got a CommonError, want nil")
+	}
+}
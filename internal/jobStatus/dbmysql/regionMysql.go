@@ -0,0 +1,20 @@
+package dbmysql
+
+import "context"
+
+// Ping checks that the database answers, for readiness checks.
+func (repo *repoMysql) Ping(ctx context.Context) error {
+	if err := repo.DB.PingContext(ctx); err != nil {
+		return mysqlErrToCommon(err)
+	}
+	return nil
+}
+
+// IsPrimary is false while the server is read only, which is how MySQL and MariaDB replicas run.
+func (repo *repoMysql) IsPrimary(ctx context.Context) (bool, error) {
+	var readOnly bool
+	if err := repo.DB.QueryRowContext(ctx, "SELECT @@global.read_only").Scan(&readOnly); err != nil {
+		return false, mysqlErrToCommon(err)
+	}
+	return !readOnly, nil
+}
@@ -0,0 +1,182 @@
+// Package dbmysql implements jobStatus.Repo for MySQL and MariaDB using database/sql and
+// github.com/go-sql-driver/mysql.
+package dbmysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbcommon"
+)
+
+type repoMysql struct {
+	DB  *sql.DB
+	dsn string
+}
+
+var _ jobStatus.Repo = (*repoMysql)(nil)
+
+func NewRepoMysql(dsn string) *repoMysql {
+	return &repoMysql{dsn: dsn}
+}
+
+// Open opens the database and confirms it's reachable. It sets parseTime and loc=UTC on the
+// DSN, so DATETIME and DATE columns scan as UTC time.Time whatever the DSN says.
+func (repo *repoMysql) Open() error {
+	cfg, err := mysql.ParseDSN(repo.dsn)
+	if err != nil {
+		return common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	cfg.ParseTime = true
+	cfg.Loc = time.UTC
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	db := sql.OpenDB(connector)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return mysqlErrToCommon(err)
+	}
+
+	repo.DB = db
+	return nil
+}
+
+func (repo *repoMysql) Close() error {
+	if repo.DB == nil {
+		return nil
+	}
+	return repo.DB.Close()
+}
+
+const insertJobStatusSql = "INSERT INTO `JobStatus` (`StatusId`, `ApplicationId`, `JobId`, `JobStatusCode`, `JobStatusTimestamp`, `BusinessDate`, `RunId`, `HostId`, `ReportedJobId`, `ReceivedTimestamp`, `Links`, `Metadata`, `IntegrityHash`)" +
+	" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+func (repo *repoMysql) Add(ctx context.Context, js jobStatus.JobStatus) error {
+	args, err := insertArgs(js)
+	if err != nil {
+		return common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	if _, err := repo.DB.ExecContext(ctx, insertJobStatusSql, args...); err != nil {
+		return mysqlErrToCommon(err)
+	}
+	return nil
+}
+
+// insertJobStatusIdempotentSql's update changes nothing, so a duplicate affects no rows. (INSERT
+// IGNORE would also turn other errors into warnings.)
+const insertJobStatusIdempotentSql = insertJobStatusSql + " ON DUPLICATE KEY UPDATE `JobId` = `JobId`"
+
+// AddIdempotent reads back the row that was already there if nothing was inserted. A duplicate
+// StatusId also affects no rows, so if there's no row with the key, it's a duplicate row.
+func (repo *repoMysql) AddIdempotent(ctx context.Context, js jobStatus.JobStatus) (jobStatus.JobStatus, bool, error) {
+	args, err := insertArgs(js)
+	if err != nil {
+		return jobStatus.JobStatus{}, false, common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	res, err := repo.DB.ExecContext(ctx, insertJobStatusIdempotentSql, args...)
+	if err != nil {
+		return jobStatus.JobStatus{}, false, mysqlErrToCommon(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return jobStatus.JobStatus{}, false, mysqlErrToCommon(err)
+	} else if n == 1 {
+		return js, true, nil
+	}
+
+	existing, err := repo.selectMysql(ctx, jobStatus.QueryOptions{}, "`JobId` = ? AND `JobStatusCode` = ? AND `BusinessDate` = ? AND `RunId` = ?",
+		string(js.JobId), string(js.JobStatusCode), js.BusinessDate.Format("2006-01-02"), string(js.RunId))
+	if err != nil {
+		return jobStatus.JobStatus{}, false, err
+	}
+	if len(existing) == 0 {
+		return jobStatus.JobStatus{}, false, common.NewCommonError(common.ErrcdRepoDupeRow, fmt.Errorf("StatusId %s already exists", js.StatusId))
+	}
+	return existing[0], false, nil
+}
+
+// AddBatch prepares the insert once and runs it for each status inside one transaction.
+func (repo *repoMysql) AddBatch(ctx context.Context, jss []jobStatus.JobStatus) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return mysqlErrToCommon(err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, insertJobStatusSql)
+	if err != nil {
+		return mysqlErrToCommon(err)
+	}
+	defer stmt.Close()
+
+	for i, js := range jss {
+		args, err := insertArgs(js)
+		if err != nil {
+			return common.NewCommonError(common.ErrcdRepoOther, fmt.Errorf("status %d: %w", i, err))
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return mysqlErrToCommon(fmt.Errorf("status %d: %w", i, err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return mysqlErrToCommon(err)
+	}
+	return nil
+}
+
+// insertArgs returns the insert's parameters. Timestamps are stored in UTC because DATETIME
+// doesn't keep a time zone.
+func insertArgs(js jobStatus.JobStatus) ([]any, error) {
+	links, err := dialect.LinksToDb(js.Links)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := dialect.MetadataToDb(js.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	var received sql.NullTime
+	if !js.ReceivedTimestamp.IsZero() {
+		received = sql.NullTime{Time: js.ReceivedTimestamp.UTC(), Valid: true}
+	}
+	return []any{string(js.StatusId), js.ApplicationId, string(js.JobId), string(js.JobStatusCode), js.JobStatusTimestamp.UTC(), js.BusinessDate.Format("2006-01-02"),
+		dbcommon.NullIfEmpty(string(js.RunId)), dbcommon.NullIfEmpty(string(js.HostId)), dbcommon.NullIfEmpty(string(js.ReportedJobId)), received, links, metadata, js.IntegrityHash}, nil
+}
+
+func (repo *repoMysql) GetByJobId(ctx context.Context, jobId jobStatus.JobIdType, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	return repo.selectMysql(ctx, opts, "`JobId` = ?", string(jobId))
+}
+
+func (repo *repoMysql) GetByJobIdBusinessDate(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	return repo.selectMysql(ctx, opts, "`JobId` = ? AND `BusinessDate` = ?", string(jobId), businessDate.Format("2006-01-02"))
+}
+
+func (repo *repoMysql) selectMysql(ctx context.Context, opts jobStatus.QueryOptions, where string, args ...any) ([]jobStatus.JobStatus, error) {
+	where, args, err := dialect.OptionsWhere(where, args, opts)
+	if err != nil {
+		return nil, err
+	}
+	fields := opts.SelectedFields()
+	query, err := dialect.BuildSelect(fields, where, opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+	query, args = dialect.PageQuery(query, args, opts)
+
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, mysqlErrToCommon(err)
+	}
+	defer rows.Close()
+
+	return dialect.RowsToDomain(rows, fields, opts.AllowPartial)
+}
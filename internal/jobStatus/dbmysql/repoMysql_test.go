@@ -0,0 +1,59 @@
+package dbmysql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+func TestOpenUnreachableIsConnectionError(t *testing.T) {
+	repo := NewRepoMysql("gojst:gojst@tcp(127.0.0.1:1)/gojst?timeout=1s")
+	if err := repo.Open(); common.ErrorCode(err) != common.ErrcdRepoConnection {
+		t.Errorf("got %v, want %s", err, common.ErrcdRepoConnection)
+	}
+	if err := NewRepoMysql("not a dsn").Open(); err == nil {
+		t.Error("got no error for a bad DSN")
+	}
+}
+
+func TestSelectBuildsParameterizedSql(t *testing.T) {
+	busDt := time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)
+	opts := jobStatus.QueryOptions{
+		Fields:  []jobStatus.FieldName{jobStatus.FieldStatusId, jobStatus.FieldRunId},
+		Sort:    []jobStatus.SortField{{Field: jobStatus.FieldJobStatusTimestamp, Descending: true}},
+		Filters: []jobStatus.Filter{{Field: jobStatus.FieldRunId, Op: jobStatus.FilterPrefix, Values: []any{"10_"}}, {Field: jobStatus.FieldBusinessDate, Op: jobStatus.FilterGte, Values: []any{busDt}}},
+		Limit:   10,
+		Offset:  20,
+	}
+
+	where, args, err := dialect.OptionsWhere("`JobId` = ?", []any{"od-calc"}, opts)
+	if err != nil {
+		t.Fatalf("optionsWhere: %v", err)
+	}
+	query, err := dialect.BuildSelect(opts.SelectedFields(), where, opts.Sort)
+	if err != nil {
+		t.Fatalf("buildSelect: %v", err)
+	}
+	query, args = dialect.PageQuery(query, args, opts)
+
+	wantQuery := "SELECT `StatusId`, `RunId` FROM `JobStatus` WHERE `JobId` = ? AND `RunId` LIKE ? ESCAPE '\\\\' AND `BusinessDate` >= ?" +
+		" ORDER BY `JobStatusTimestamp` DESC, `StatusId` LIMIT ? OFFSET ?"
+	if query != wantQuery {
+		t.Errorf("query:\n got %s\nwant %s", query, wantQuery)
+	}
+	if wantArgs := []any{"od-calc", `10\_%`, "2023-06-15", 10, 20}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args: got %#v, want %#v", args, wantArgs)
+	}
+}
+
+func TestSelectRefusesUnknownFieldsAndSorts(t *testing.T) {
+	if _, err := dialect.BuildSelect([]jobStatus.FieldName{"Password"}, "1 = 1", nil); common.ErrorCode(err) != common.ErrcdDomainProps {
+		t.Errorf("unknown field: got %v, want %s", err, common.ErrcdDomainProps)
+	}
+	if _, err := dialect.BuildSelect([]jobStatus.FieldName{jobStatus.FieldStatusId}, "1 = 1", []jobStatus.SortField{{Field: jobStatus.FieldHostId}}); common.ErrorCode(err) != common.ErrcdDomainProps {
+		t.Errorf("unsortable field: got %v, want %s", err, common.ErrcdDomainProps)
+	}
+}
@@ -0,0 +1,37 @@
+package dbsqlite
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbcommon"
+)
+
+// dialect is SQLite's: double-quoted names and ? parameters. ne is IS NOT, SQLite's null-safe
+// comparison. SQLite's LIKE ignores ASCII case, so prefix compares a substring instead. Dates and
+// timestamps are text in dateFormat and timestampFormat.
+var dialect = dbcommon.Dialect{
+	QuoteIdent:  func(name string) string { return `"` + name + `"` },
+	Placeholder: func(n int) string { return "?" },
+	NotEqual:    func(col string, param string) string { return col + " IS NOT " + param },
+	Prefix: func(col string, prefix string, param func(any) string) string {
+		return "substr(" + col + ", 1, " + param(utf8.RuneCountInString(prefix)) + ") = " + param(prefix)
+	},
+	Arg:             filterArg,
+	ErrToCommon:     sqliteErrToCommon,
+	JsonAsText:      true,
+	TimestampLayout: timestampFormat,
+	DateLayout:      dateFormat,
+}
+
+func filterArg(field jobStatus.FieldName, v any) any {
+	t, ok := v.(time.Time)
+	if !ok {
+		return v
+	}
+	if field == jobStatus.FieldBusinessDate {
+		return t.Format(dateFormat)
+	}
+	return formatTimestamp(t)
+}
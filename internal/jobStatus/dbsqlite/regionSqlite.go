@@ -0,0 +1,16 @@
+package dbsqlite
+
+import "context"
+
+// Ping checks that the database answers, for readiness checks.
+func (repo *repoSqlite) Ping(ctx context.Context) error {
+	if err := repo.DB.PingContext(ctx); err != nil {
+		return sqliteErrToCommon(err)
+	}
+	return nil
+}
+
+// IsPrimary is always true. A SQLite database is a local file with no replicas.
+func (repo *repoSqlite) IsPrimary(ctx context.Context) (bool, error) {
+	return true, nil
+}
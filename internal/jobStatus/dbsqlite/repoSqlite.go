@@ -0,0 +1,245 @@
+// Package dbsqlite implements jobStatus.Repo for SQLite using database/sql, for demos, local
+// development, and edge agents that can't run Postgres. It uses github.com/mattn/go-sqlite3, so
+// building it needs cgo.
+//
+// Timestamps and dates are stored as fixed-width UTC text, so they compare and sort correctly as
+// strings and read back the same whichever driver is used.
+package dbsqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbcommon"
+)
+
+// driverName is the name github.com/mattn/go-sqlite3 registers.
+const driverName = "sqlite3"
+
+// Stored formats. timestampFormat keeps microseconds, like Postgres.
+const (
+	timestampFormat = "2006-01-02T15:04:05.000000Z"
+	dateFormat      = "2006-01-02"
+)
+
+type repoSqlite struct {
+	DB  *sql.DB
+	dsn string
+}
+
+var _ jobStatus.Repo = (*repoSqlite)(nil)
+
+// NewRepoSqlite returns a repo for the database file in dsn, like "file:gojst.db?_busy_timeout=5000".
+// Use "file::memory:" for a database that goes away when the repo is closed.
+func NewRepoSqlite(dsn string) *repoSqlite {
+	return &repoSqlite{dsn: dsn}
+}
+
+// Open opens the database and confirms it's reachable. SQLite has one writer at a time, and each
+// connection to an in-memory database is a different database, so the pool has one connection.
+func (repo *repoSqlite) Open() error {
+	db, err := sql.Open(driverName, repo.dsn)
+	if err != nil {
+		return sqliteErrToCommon(err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return sqliteErrToCommon(err)
+	}
+
+	repo.DB = db
+	return nil
+}
+
+func (repo *repoSqlite) Close() error {
+	if repo.DB == nil {
+		return nil
+	}
+	return repo.DB.Close()
+}
+
+// bootstrapSql creates the schema if it doesn't exist. Columns match the Postgres table.
+var bootstrapSql = []string{
+	`CREATE TABLE IF NOT EXISTS "JobStatus" (
+		"StatusId" TEXT NOT NULL,
+		"ApplicationId" TEXT NOT NULL,
+		"JobId" TEXT NOT NULL,
+		"JobStatusCode" TEXT NOT NULL,
+		"JobStatusTimestamp" TEXT NOT NULL,
+		"BusinessDate" TEXT NOT NULL,
+		"RunId" TEXT NOT NULL,
+		"HostId" TEXT NOT NULL,
+		"ReportedJobId" TEXT NULL,
+		"ReceivedTimestamp" TEXT NULL,
+		"Links" TEXT NULL,
+		"Metadata" TEXT NULL,
+		"HeartbeatTimestamp" TEXT NULL,
+		"IntegrityHash" BLOB NULL,
+		CONSTRAINT "JobStatus_pk" PRIMARY KEY ("JobId", "JobStatusCode", "BusinessDate", "RunId")
+	)`,
+	`CREATE INDEX IF NOT EXISTS "JobStatus_BusinessDate_ApplicationId" ON "JobStatus" ("BusinessDate", "ApplicationId")`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS "JobStatus_StatusId" ON "JobStatus" ("StatusId")`,
+}
+
+// addedColumns are "JobStatus" columns added after files were first bootstrapped, with their
+// types. SQLite has no ADD COLUMN IF NOT EXISTS, so Bootstrap checks for each one.
+var addedColumns = [][2]string{
+	{"Metadata", "TEXT NULL"},
+	{"HeartbeatTimestamp", "TEXT NULL"},
+}
+
+// Bootstrap creates the schema if it isn't there yet, and adds columns that files bootstrapped
+// by older versions don't have. It's safe to call on every start.
+func (repo *repoSqlite) Bootstrap(ctx context.Context) error {
+	for _, stmt := range bootstrapSql {
+		if _, err := repo.DB.ExecContext(ctx, stmt); err != nil {
+			return sqliteErrToCommon(err)
+		}
+	}
+	for _, col := range addedColumns {
+		var n int
+		if err := repo.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM pragma_table_info('JobStatus') WHERE "name" = ?`, col[0]).Scan(&n); err != nil {
+			return sqliteErrToCommon(err)
+		}
+		if n > 0 {
+			continue
+		}
+		if _, err := repo.DB.ExecContext(ctx, `ALTER TABLE "JobStatus" ADD COLUMN "`+col[0]+`" `+col[1]); err != nil {
+			return sqliteErrToCommon(err)
+		}
+	}
+	return nil
+}
+
+const insertJobStatusSql = `INSERT INTO "JobStatus" ("StatusId", "ApplicationId", "JobId", "JobStatusCode", "JobStatusTimestamp", "BusinessDate", "RunId", "HostId", "ReportedJobId", "ReceivedTimestamp", "Links", "Metadata", "IntegrityHash")
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+func (repo *repoSqlite) Add(ctx context.Context, js jobStatus.JobStatus) error {
+	args, err := insertArgs(js)
+	if err != nil {
+		return common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	if _, err := repo.DB.ExecContext(ctx, insertJobStatusSql, args...); err != nil {
+		return sqliteErrToCommon(err)
+	}
+	return nil
+}
+
+const insertJobStatusIdempotentSql = insertJobStatusSql + `
+	ON CONFLICT ("JobId", "JobStatusCode", "BusinessDate", "RunId") DO NOTHING`
+
+// AddIdempotent reads back the row that was already there if nothing was inserted. A StatusId
+// conflict is still an error.
+func (repo *repoSqlite) AddIdempotent(ctx context.Context, js jobStatus.JobStatus) (jobStatus.JobStatus, bool, error) {
+	args, err := insertArgs(js)
+	if err != nil {
+		return jobStatus.JobStatus{}, false, common.NewCommonError(common.ErrcdRepoOther, err)
+	}
+	res, err := repo.DB.ExecContext(ctx, insertJobStatusIdempotentSql, args...)
+	if err != nil {
+		return jobStatus.JobStatus{}, false, sqliteErrToCommon(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return jobStatus.JobStatus{}, false, sqliteErrToCommon(err)
+	} else if n == 1 {
+		return js, true, nil
+	}
+
+	existing, err := repo.selectSqlite(ctx, jobStatus.QueryOptions{}, `"JobId" = ? AND "JobStatusCode" = ? AND "BusinessDate" = ? AND "RunId" = ?`,
+		string(js.JobId), string(js.JobStatusCode), js.BusinessDate.Format(dateFormat), string(js.RunId))
+	if err != nil {
+		return jobStatus.JobStatus{}, false, err
+	}
+	if len(existing) == 0 {
+		return jobStatus.JobStatus{}, false, common.NewCommonError(common.ErrcdRepoTransient, fmt.Errorf("status %s for job %s run %s conflicted and then was gone", js.JobStatusCode, js.JobId, js.RunId))
+	}
+	return existing[0], false, nil
+}
+
+// AddBatch prepares the insert once and runs it for each status inside one transaction.
+func (repo *repoSqlite) AddBatch(ctx context.Context, jss []jobStatus.JobStatus) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return sqliteErrToCommon(err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, insertJobStatusSql)
+	if err != nil {
+		return sqliteErrToCommon(err)
+	}
+	defer stmt.Close()
+
+	for i, js := range jss {
+		args, err := insertArgs(js)
+		if err != nil {
+			return common.NewCommonError(common.ErrcdRepoOther, fmt.Errorf("status %d: %w", i, err))
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return sqliteErrToCommon(fmt.Errorf("status %d: %w", i, err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return sqliteErrToCommon(err)
+	}
+	return nil
+}
+
+func insertArgs(js jobStatus.JobStatus) ([]any, error) {
+	links, err := dialect.LinksToDb(js.Links)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := dialect.MetadataToDb(js.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	var received sql.NullString
+	if !js.ReceivedTimestamp.IsZero() {
+		received = sql.NullString{String: formatTimestamp(js.ReceivedTimestamp), Valid: true}
+	}
+	return []any{string(js.StatusId), js.ApplicationId, string(js.JobId), string(js.JobStatusCode), formatTimestamp(js.JobStatusTimestamp), js.BusinessDate.Format(dateFormat),
+		string(js.RunId), dbcommon.NullIfEmpty(string(js.HostId)), dbcommon.NullIfEmpty(string(js.ReportedJobId)), received, links, metadata, js.IntegrityHash}, nil
+}
+
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Truncate(time.Microsecond).Format(timestampFormat)
+}
+
+func (repo *repoSqlite) GetByJobId(ctx context.Context, jobId jobStatus.JobIdType, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	return repo.selectSqlite(ctx, opts, `"JobId" = ?`, string(jobId))
+}
+
+func (repo *repoSqlite) GetByJobIdBusinessDate(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	return repo.selectSqlite(ctx, opts, `"JobId" = ? AND "BusinessDate" = ?`, string(jobId), businessDate.Format(dateFormat))
+}
+
+func (repo *repoSqlite) selectSqlite(ctx context.Context, opts jobStatus.QueryOptions, where string, args ...any) ([]jobStatus.JobStatus, error) {
+	where, args, err := dialect.OptionsWhere(where, args, opts)
+	if err != nil {
+		return nil, err
+	}
+	fields := opts.SelectedFields()
+	query, err := dialect.BuildSelect(fields, where, opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+	query, args = dialect.PageQuery(query, args, opts)
+
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, sqliteErrToCommon(err)
+	}
+	defer rows.Close()
+
+	return dialect.RowsToDomain(rows, fields, opts.AllowPartial)
+}
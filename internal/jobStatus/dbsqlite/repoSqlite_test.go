@@ -0,0 +1,152 @@
+package dbsqlite
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/testsupport"
+)
+
+// openTestRepo opens an in-memory database with the schema. Cleanup checks that no connections
+// are still in use, then closes the repo.
+func openTestRepo(t *testing.T) *repoSqlite {
+	t.Helper()
+	repo := NewRepoSqlite("file::memory:")
+	if err := repo.Open(); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	t.Cleanup(testsupport.CheckDB(t, repo.DB))
+	if err := repo.Bootstrap(context.Background()); err != nil {
+		t.Fatalf("Bootstrap: %v", err)
+	}
+	return repo
+}
+
+func newTestStatus(t *testing.T, jobId jobStatus.JobIdType, runId int) jobStatus.JobStatus {
+	t.Helper()
+	busDt := time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)
+	js, err := jobStatus.NewJobStatus("gojst-tests", jobId, "SUCCEED", busDt.Add(time.Duration(25*60+runId)*time.Minute), busDt, jobStatus.RunIdType(strconv.Itoa(runId)), "batch01")
+	if err != nil {
+		t.Fatalf("NewJobStatus: %v", err)
+	}
+	return js
+}
+
+func TestAddAndGetRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := openTestRepo(t)
+	js := newTestStatus(t, "od-calc", 1)
+	js.Links = []jobStatus.Link{{Kind: "log", Url: "https://logs.example.com/od-calc/1"}}
+	js.Metadata = jobStatus.Metadata{"exitCode": "0", "durationMs": "1500"}
+	if err := repo.Add(ctx, js); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	got, err := repo.GetByJobIdBusinessDate(ctx, js.JobId, js.BusinessDate, jobStatus.QueryOptions{})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("got %v, %v; want the status", got, err)
+	}
+	if g := got[0]; g.StatusId != js.StatusId || !g.JobStatusTimestamp.Equal(js.JobStatusTimestamp) || !g.BusinessDate.Equal(js.BusinessDate) || g.HostId != js.HostId || len(g.Links) != 1 || g.Links[0] != js.Links[0] ||
+		!g.Metadata.Equal(js.Metadata) {
+		t.Errorf("got %+v, want %+v", g, js)
+	}
+}
+
+func TestBootstrapAddsNewColumns(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepoSqlite("file:" + filepath.Join(t.TempDir(), "gojst.db"))
+	if err := repo.Open(); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	// the table as files bootstrapped before addedColumns have it
+	if _, err := repo.DB.ExecContext(ctx, `CREATE TABLE "JobStatus" (
+		"StatusId" TEXT NOT NULL, "ApplicationId" TEXT NOT NULL, "JobId" TEXT NOT NULL, "JobStatusCode" TEXT NOT NULL,
+		"JobStatusTimestamp" TEXT NOT NULL, "BusinessDate" TEXT NOT NULL, "RunId" TEXT NOT NULL, "HostId" TEXT NOT NULL,
+		"ReportedJobId" TEXT NULL, "ReceivedTimestamp" TEXT NULL, "Links" TEXT NULL, "IntegrityHash" BLOB NULL,
+		CONSTRAINT "JobStatus_pk" PRIMARY KEY ("JobId", "JobStatusCode", "BusinessDate", "RunId"))`); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := repo.Bootstrap(ctx); err != nil {
+			t.Fatalf("Bootstrap %d: %v", i+1, err)
+		}
+	}
+	js := newTestStatus(t, "od-calc", 1)
+	js.Metadata = jobStatus.Metadata{"exitCode": "3"}
+	if err := repo.Add(ctx, js); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	got, err := repo.GetByJobId(ctx, js.JobId, jobStatus.QueryOptions{})
+	if err != nil || len(got) != 1 || got[0].Metadata["exitCode"] != "3" {
+		t.Errorf("got %v, %v; want the status with its metadata", got, err)
+	}
+}
+
+func TestAddDuplicateIsDupeRow(t *testing.T) {
+	ctx := context.Background()
+	repo := openTestRepo(t)
+	js := newTestStatus(t, "od-calc", 1)
+	if err := repo.Add(ctx, js); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := repo.Add(ctx, js); common.ErrorCode(err) != common.ErrcdRepoDupeRow {
+		t.Errorf("second Add: got %v, want %s", err, common.ErrcdRepoDupeRow)
+	}
+
+	again := newTestStatus(t, "od-calc", 1)
+	stored, inserted, err := repo.AddIdempotent(ctx, again)
+	if err != nil || inserted || stored.StatusId != js.StatusId {
+		t.Errorf("AddIdempotent: got %s, %v, %v; want the stored %s", stored.StatusId, inserted, err, js.StatusId)
+	}
+}
+
+func TestAddBatchRollsBackOnFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := openTestRepo(t)
+	first := newTestStatus(t, "od-calc", 1)
+	if err := repo.AddBatch(ctx, []jobStatus.JobStatus{first, newTestStatus(t, "od-calc", 2), first}); common.ErrorCode(err) != common.ErrcdRepoDupeRow {
+		t.Fatalf("AddBatch with a duplicate: got %v, want %s", err, common.ErrcdRepoDupeRow)
+	}
+	if got, err := repo.GetByJobId(ctx, "od-calc", jobStatus.QueryOptions{}); err != nil || len(got) != 0 {
+		t.Errorf("got %d statuses, %v; want none after the rollback", len(got), err)
+	}
+}
+
+func TestGetByJobIdAppliesFiltersSortAndPaging(t *testing.T) {
+	ctx := context.Background()
+	repo := openTestRepo(t)
+	for _, runId := range []int{1, 10, 11, 12, 2} {
+		if err := repo.Add(ctx, newTestStatus(t, "od-calc", runId)); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	got, err := repo.GetByJobId(ctx, "od-calc", jobStatus.QueryOptions{
+		Fields:  []jobStatus.FieldName{jobStatus.FieldStatusId, jobStatus.FieldRunId, jobStatus.FieldJobStatusTimestamp},
+		Filters: []jobStatus.Filter{{Field: jobStatus.FieldRunId, Op: jobStatus.FilterPrefix, Values: []any{"1"}}},
+		Sort:    []jobStatus.SortField{{Field: jobStatus.FieldJobStatusTimestamp, Descending: true}},
+		Limit:   2,
+		Offset:  1,
+	})
+	if err != nil {
+		t.Fatalf("GetByJobId: %v", err)
+	}
+	if len(got) != 2 || got[0].RunId != "11" || got[1].RunId != "10" {
+		t.Errorf("got %+v, want runs 11 and 10", got)
+	}
+}
+
+func TestOpenUnopenableIsConnectionError(t *testing.T) {
+	repo := NewRepoSqlite("file:" + filepath.Join(t.TempDir(), "missing", "gojst.db"))
+	if err := repo.Open(); common.ErrorCode(err) != common.ErrcdRepoConnection {
+		t.Errorf("got %v, want %s", err, common.ErrcdRepoConnection)
+	}
+}
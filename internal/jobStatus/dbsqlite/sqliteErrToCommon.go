@@ -0,0 +1,33 @@
+package dbsqlite
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// sqliteErrToCommon converts an error returned by the SQLite driver into a CommonError.
+func sqliteErrToCommon(err error) *common.CommonError {
+	if err == nil {
+		return nil
+	}
+
+	var ce *common.CommonError
+	if errors.As(err, &ce) {
+		return ce
+	}
+
+	var liteErr sqlite3.Error
+	if errors.As(err, &liteErr) {
+		switch {
+		case liteErr.ExtendedCode == sqlite3.ErrConstraintUnique, liteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey:
+			return common.NewCommonError(common.ErrcdRepoDupeRow, err)
+		case liteErr.Code == sqlite3.ErrBusy, liteErr.Code == sqlite3.ErrLocked, liteErr.Code == sqlite3.ErrCantOpen:
+			// busy means another writer held the database longer than _busy_timeout; retrying can work
+			return common.NewCommonError(common.ErrcdRepoConnection, err)
+		}
+	}
+	return common.NewCommonError(common.ErrcdRepoOther, err)
+}
@@ -0,0 +1,34 @@
+package dbsqlite
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+func TestSqliteErrToCommon(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"unique", sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}, common.ErrcdRepoDupeRow},
+		{"wrapped primary key", fmt.Errorf("status 3: %w", sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintPrimaryKey}), common.ErrcdRepoDupeRow},
+		{"not null", sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintNotNull}, common.ErrcdRepoOther},
+		{"busy", sqlite3.Error{Code: sqlite3.ErrBusy}, common.ErrcdRepoConnection},
+		{"can't open", sqlite3.Error{Code: sqlite3.ErrCantOpen}, common.ErrcdRepoConnection},
+		{"already a CommonError", common.NewCommonError(common.ErrcdDomainProps, errors.New("bad")), common.ErrcdDomainProps},
+		{"anything else", errors.New("oops"), common.ErrcdRepoOther},
+	} {
+		if got := sqliteErrToCommon(tc.err); got.Code != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got.Code, tc.want)
+		}
+	}
+	if sqliteErrToCommon(nil) != nil {
+		t.Error("nil: got a CommonError, want nil")
+	}
+}
@@ -0,0 +1,347 @@
+package jobStatus
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// dtoToDomain validates jsDto, reporting every field's problems at once (see
+// validateJobStatusDto). If generateRunId is true, a missing RunId is generated instead of rejected.
+func dtoToDomain(jsDto dto.JobStatusDto, generateRunId bool) (JobStatus, error) {
+	if problems := validateJobStatusDto(jsDto, generateRunId, time.Now()); len(problems) > 0 {
+		return JobStatus{}, common.NewCommonError(common.ErrcdDomainProps, problems)
+	}
+	// validated above, so these can't fail
+	jobStatusTimestamp, _ := time.Parse(time.RFC3339Nano, jsDto.JobStatusTimestamp)
+	businessDate, _ := ParseDate(jsDto.BusinessDate)
+
+	// clients that don't track runs can leave RunId out and get a generated one back
+	runId := RunIdType(jsDto.RunId)
+	if len(runId) == 0 && generateRunId {
+		runId = GenerateRunId()
+	}
+
+	js, err := NewJobStatus(jsDto.ApplicationId, JobIdType(jsDto.JobId), jsDto.JobStatusCode, jobStatusTimestamp, businessDate, runId, HostIdType(jsDto.HostId))
+	if err != nil {
+		return JobStatus{}, err
+	}
+	js.Links = linksDtoToDomain(jsDto.Links)
+	if err := validateLinks(js.Links); err != nil {
+		return JobStatus{}, err
+	}
+	js.Metadata = Metadata(jsDto.Metadata).Clone()
+	if err := validateMetadata(js.Metadata); err != nil {
+		return JobStatus{}, err
+	}
+	return js, nil
+}
+
+func linksDtoToDomain(linkDtos []dto.LinkDto) []Link {
+	if len(linkDtos) == 0 {
+		return nil
+	}
+	links := make([]Link, len(linkDtos))
+	for i, l := range linkDtos {
+		links[i] = Link{Kind: LinkKind(l.Kind), Url: l.Url}
+	}
+	return links
+}
+
+func linksToDto(links []Link) []dto.LinkDto {
+	if len(links) == 0 {
+		return nil
+	}
+	linkDtos := make([]dto.LinkDto, len(links))
+	for i, l := range links {
+		linkDtos[i] = dto.LinkDto{Kind: string(l.Kind), Url: l.Url}
+	}
+	return linkDtos
+}
+
+func domainToDto(js JobStatus) dto.JobStatusDto {
+	return domainToDtoFields(js, AllFields)
+}
+
+// domainToDtoFields sets only the listed fields so unrequested fields are omitted from JSON.
+func domainToDtoFields(js JobStatus, fields []FieldName) dto.JobStatusDto {
+	var jsDto dto.JobStatusDto
+	for _, field := range fields {
+		switch field {
+		case FieldStatusId:
+			jsDto.StatusId = string(js.StatusId)
+		case FieldApplicationId:
+			jsDto.ApplicationId = js.ApplicationId
+		case FieldJobId:
+			jsDto.JobId = string(js.JobId)
+		case FieldJobStatusCode:
+			jsDto.JobStatusCode = string(js.JobStatusCode)
+		case FieldJobStatusTimestamp:
+			jsDto.JobStatusTimestamp = js.JobStatusTimestamp.Format(time.RFC3339Nano)
+		case FieldBusinessDate:
+			jsDto.BusinessDate = js.BusinessDate.Format(dto.DateFormat)
+		case FieldRunId:
+			jsDto.RunId = string(js.RunId)
+		case FieldHostId:
+			jsDto.HostId = string(js.HostId)
+		case FieldReportedJobId:
+			jsDto.ReportedJobId = string(js.ReportedJobId)
+		case FieldReceivedTimestamp:
+			if !js.ReceivedTimestamp.IsZero() {
+				jsDto.ReceivedTimestamp = js.ReceivedTimestamp.Format(time.RFC3339Nano)
+			}
+		case FieldLinks:
+			jsDto.Links = linksToDto(js.Links)
+		case FieldMetadata:
+			jsDto.Metadata = js.Metadata.Clone()
+		case FieldHeartbeatTimestamp:
+			if !js.HeartbeatTimestamp.IsZero() {
+				jsDto.HeartbeatTimestamp = js.HeartbeatTimestamp.Format(time.RFC3339Nano)
+			}
+		}
+	}
+	return jsDto
+}
+
+func domainsToDtos(jss []JobStatus, fields []FieldName) []dto.JobStatusDto {
+	dtos := make([]dto.JobStatusDto, len(jss))
+	for i, js := range jss {
+		dtos[i] = domainToDtoFields(js, fields)
+	}
+	return dtos
+}
+
+// dtoFieldNames maps DTO JSON names, which clients use in the fields parameter, to domain fields.
+var dtoFieldNames = map[string]FieldName{
+	"StatusId":      FieldStatusId,
+	"AppId":         FieldApplicationId,
+	"JobId":         FieldJobId,
+	"JobSt":         FieldJobStatusCode,
+	"JobStTs":       FieldJobStatusTimestamp,
+	"BusDt":         FieldBusinessDate,
+	"RunId":         FieldRunId,
+	"HostId":        FieldHostId,
+	"ReportedJobId": FieldReportedJobId,
+	"RecvTs":        FieldReceivedTimestamp,
+	"Links":         FieldLinks,
+	"Metadata":      FieldMetadata,
+	"HeartbeatTs":   FieldHeartbeatTimestamp,
+}
+
+// parseFields parses a comma separated list of DTO field names. An empty string means all fields.
+func parseFields(s string) ([]FieldName, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return nil, nil
+	}
+
+	var fields []FieldName
+	seen := map[FieldName]bool{}
+	for _, name := range strings.Split(s, ",") {
+		field, ok := dtoFieldNames[strings.TrimSpace(name)]
+		if !ok {
+			return nil, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("fields: unknown field %q", name))
+		}
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// parseSort parses a comma separated list of DTO field names, each optionally followed by
+// :asc or :desc, like "BusDt:desc,JobStTs". An empty string means no sort.
+func parseSort(s string) ([]SortField, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return nil, nil
+	}
+
+	var sortFields []SortField
+	seen := map[FieldName]bool{}
+	for _, term := range strings.Split(s, ",") {
+		name, direction, _ := strings.Cut(strings.TrimSpace(term), ":")
+		field, ok := dtoFieldNames[name]
+		if !ok || !SortableFields[field] {
+			return nil, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("sort: cannot sort on %q", name))
+		}
+		if seen[field] {
+			return nil, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("sort: %q appears more than once", name))
+		}
+		seen[field] = true
+
+		var descending bool
+		switch strings.ToLower(direction) {
+		case "", "asc":
+		case "desc":
+			descending = true
+		default:
+			return nil, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("sort: direction %q must be asc or desc", direction))
+		}
+		sortFields = append(sortFields, SortField{Field: field, Descending: descending})
+	}
+	return sortFields, nil
+}
+
+// parseFilters parses filter parameters, keyed like "JobStTs[gte]" or "JobSt[in]" (a DTO
+// field name and a FilterOp), into Filters. in takes a comma separated list. Filters come
+// back in key order so queries are repeatable.
+func parseFilters(params map[string]string) ([]Filter, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	if len(params) > MaxFilters {
+		return nil, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("filters: at most %d are allowed", MaxFilters))
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	filters := make([]Filter, 0, len(keys))
+	for _, key := range keys {
+		name, op, ok := strings.Cut(strings.TrimSuffix(key, "]"), "[")
+		field, known := dtoFieldNames[name]
+		if !ok || !known || !IsFilterOpAllowed(field, FilterOp(op)) {
+			return nil, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("filters: can't filter %q", key))
+		}
+
+		texts := []string{params[key]}
+		if FilterOp(op) == FilterIn {
+			texts = strings.Split(params[key], ",")
+		}
+		if len(texts) > MaxFilterValues {
+			return nil, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("filters: %s has more than %d values", key, MaxFilterValues))
+		}
+		values := make([]any, len(texts))
+		for i, text := range texts {
+			v, err := parseFilterValue(field, strings.TrimSpace(text))
+			if err != nil {
+				return nil, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("filters: %s: %w", key, err))
+			}
+			values[i] = v
+		}
+		filters = append(filters, Filter{Field: field, Op: FilterOp(op), Values: values})
+	}
+	return filters, nil
+}
+
+// parseFilterValue converts one filter value to the type Filter.Values holds for field.
+func parseFilterValue(field FieldName, s string) (any, error) {
+	if len(s) == 0 {
+		return nil, errors.New("values can't be empty")
+	}
+	switch field {
+	case FieldJobStatusTimestamp, FieldReceivedTimestamp:
+		return time.Parse(time.RFC3339Nano, s)
+	case FieldBusinessDate:
+		return ParseDate(s)
+	case FieldJobStatusCode:
+		if !JobStatusCodeType(s).IsValid() {
+			return nil, fmt.Errorf("%q is not a JobStatusCode", s)
+		}
+	}
+	return s, nil
+}
+
+func dailyRollupToDto(dr DailyRollup) dto.DailyRollupDto {
+	return dto.DailyRollupDto{
+		ApplicationId:     dr.ApplicationId,
+		BusinessDate:      dr.BusinessDate.Format(dto.DateFormat),
+		StartCount:        dr.StartCount,
+		SucceedCount:      dr.SucceedCount,
+		FailCount:         dr.FailCount,
+		RunCount:          dr.RunCount,
+		CompletedRunCount: dr.CompletedRunCount,
+		TotalDurationMs:   dr.TotalDuration.Milliseconds(),
+		MinDurationMs:     dr.MinDuration.Milliseconds(),
+		MaxDurationMs:     dr.MaxDuration.Milliseconds(),
+		AvgDurationMs:     dr.AvgDuration().Milliseconds(),
+		ComputeHours:      dr.ComputeHours,
+		CostUsd:           dr.CostUsd,
+		FailedCostUsd:     dr.FailedCostUsd,
+		RolledUpTimestamp: dr.RolledUpTimestamp.Format(time.RFC3339Nano),
+	}
+}
+
+func durationBaselineToDto(b DurationBaseline) dto.DurationBaselineDto {
+	return dto.DurationBaselineDto{
+		ApplicationId: b.ApplicationId,
+		JobId:         string(b.JobId),
+		Season:        string(b.Season),
+		RunCount:      b.RunCount,
+		MedianMs:      b.Median.Milliseconds(),
+		P90Ms:         b.P90.Milliseconds(),
+	}
+}
+
+// ForecastInputToDto is the request body Forecasters outside this service get.
+func ForecastInputToDto(in ForecastInput) dto.ForecastRequestDto {
+	req := dto.ForecastRequestDto{
+		ApplicationId:    in.ApplicationId,
+		JobId:            string(in.JobId),
+		BusinessDate:     in.BusinessDate.Format(dto.DateFormat),
+		RunId:            string(in.RunId),
+		StartedTimestamp: in.StartedAt.Format(dto.TimestampFormat),
+		AsOfTimestamp:    in.AsOf.Format(dto.TimestampFormat),
+		Baselines:        make([]dto.DurationBaselineDto, len(in.Baselines)),
+	}
+	if !in.Deadline.IsZero() {
+		req.DeadlineTimestamp = in.Deadline.Format(dto.TimestampFormat)
+	}
+	for i, b := range in.Baselines {
+		req.Baselines[i] = durationBaselineToDto(b)
+	}
+	return req
+}
+
+// ForecastResponseToDomain checks a response from a Forecaster outside this service.
+func ForecastResponseToDomain(res dto.ForecastResponseDto) (Forecast, error) {
+	end, err := time.Parse(time.RFC3339Nano, res.ExpectedEndTimestamp)
+	if err != nil {
+		return Forecast{}, fmt.Errorf("ExpectedEndTs: %w", err)
+	}
+	if res.MissProbability < 0 || res.MissProbability > 1 {
+		return Forecast{}, fmt.Errorf("MissProbability %v is not from 0 to 1", res.MissProbability)
+	}
+	if res.Model == "" {
+		return Forecast{}, errors.New("Model is required")
+	}
+	return Forecast{Model: res.Model, ExpectedEnd: end, MissProbability: res.MissProbability}, nil
+}
+
+// ParseDate parses a DTO date string into a date-only time.Time.
+func ParseDate(s string) (time.Time, error) {
+	d, err := time.Parse(dto.DateFormat, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return TruncateToDate(d), nil
+}
+
+// parseAsOf parses an as-of timestamp. An empty string means now and returns the zero time.
+func parseAsOf(s string) (time.Time, error) {
+	if len(s) == 0 {
+		return time.Time{}, nil
+	}
+	asOf, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("asOf: %w", err))
+	}
+	return asOf, nil
+}
+
+// parseDateProp parses a date and returns a props CommonError naming the field on failure.
+func parseDateProp(name string, s string) (time.Time, error) {
+	d, err := ParseDate(s)
+	if err != nil {
+		return time.Time{}, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("%s: %w", name, err))
+	}
+	return d, nil
+}
@@ -0,0 +1,73 @@
+package jobStatus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// MaxFutureSkew is how far after the server's clock a JobStTs can be. Batch hosts' clocks drift,
+// so a little is allowed; more means the host's clock or the job's timestamp is wrong.
+const MaxFutureSkew = 5 * time.Minute
+
+// maxBatchFieldErrors bounds the field errors a batch reports, so a batch of bad statuses
+// doesn't get a response bigger than the request.
+const maxBatchFieldErrors = 100
+
+// validateJobStatusDto checks every field of jsDto against the rules NewJobStatus and the
+// database apply, and returns every problem, named by the field's JSON name. It's nil if there
+// aren't any. If generateRunId is true, RunId can be empty. JobStTs can't be more than
+// MaxFutureSkew after now.
+func validateJobStatusDto(jsDto dto.JobStatusDto, generateRunId bool, now time.Time) common.FieldErrors {
+	var problems common.FieldErrors
+	add := func(field string, problem string) {
+		if problem != "" {
+			problems = append(problems, common.FieldError{Field: field, Message: problem})
+		}
+	}
+
+	switch {
+	case len(jsDto.ApplicationId) == 0:
+		add("AppId", "is required")
+	case len(jsDto.ApplicationId) > MaxApplicationIdLen:
+		add("AppId", fmt.Sprintf("is longer than %d characters", MaxApplicationIdLen))
+	}
+	add("JobId", idProblem(jsDto.JobId, MaxJobIdLen))
+	if !JobStatusCodeType(jsDto.JobStatusCode).IsValid() {
+		add("JobSt", fmt.Sprintf("%q is not START, SUCCEED, or FAIL", jsDto.JobStatusCode))
+	}
+
+	switch ts, err := time.Parse(time.RFC3339Nano, jsDto.JobStatusTimestamp); {
+	case len(jsDto.JobStatusTimestamp) == 0:
+		add("JobStTs", "is required")
+	case err != nil:
+		add("JobStTs", fmt.Sprintf("%q is not an RFC 3339 timestamp, like 2023-06-16T00:18:33Z", jsDto.JobStatusTimestamp))
+	case ts.After(now.Add(MaxFutureSkew)):
+		add("JobStTs", fmt.Sprintf("%q is more than %s after the server's clock", jsDto.JobStatusTimestamp, MaxFutureSkew))
+	}
+
+	switch _, err := ParseDate(jsDto.BusinessDate); {
+	case len(jsDto.BusinessDate) == 0:
+		add("BusDt", "is required")
+	case err != nil:
+		add("BusDt", fmt.Sprintf("%q is not a date, like 2023-06-15", jsDto.BusinessDate))
+	}
+
+	if len(jsDto.RunId) > 0 || !generateRunId {
+		add("RunId", idProblem(jsDto.RunId, MaxRunIdLen))
+	}
+	add("HostId", idProblem(jsDto.HostId, MaxHostIdLen))
+
+	if len(jsDto.Links) > MaxLinksPerStatus {
+		add("Links", fmt.Sprintf("has %d links; the limit is %d", len(jsDto.Links), MaxLinksPerStatus))
+	}
+	for i, l := range linksDtoToDomain(jsDto.Links) {
+		for _, problem := range l.problems() {
+			add(fmt.Sprintf("Links[%d].%s", i, problem.Field), problem.Message)
+		}
+	}
+	problems = append(problems, Metadata(jsDto.Metadata).problems()...)
+	return problems
+}
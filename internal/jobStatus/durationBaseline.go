@@ -0,0 +1,148 @@
+package jobStatus
+
+import (
+	"sort"
+	"time"
+)
+
+// MaxBaselineDays bounds a baseline window. Month-end seasons get one run a month, so the
+// window is longer than for reliability, and a baseline query needs an application.
+const MaxBaselineDays = 366
+
+// DefaultBaselineMinRuns is how many runs a season needs before ExpectedDuration uses it.
+const DefaultBaselineMinRuns = 5
+
+// Season is a group of business dates whose runs are expected to take about as long:
+// a weekday ("Mon" to "Sun"), the first or last business day of the month, or all dates.
+type Season string
+
+const (
+	SeasonAll              Season = "All"
+	SeasonFirstBusinessDay Season = "FirstBusinessDay"
+	SeasonLastBusinessDay  Season = "LastBusinessDay"
+)
+
+// DurationBaseline is the duration distribution of a job's successful runs in one season. A run
+// is (JobId, BusinessDate, RunId), from its first START to its last SUCCEED, like rollups; runs
+// that failed or are missing an end aren't in it, because failures are often quick.
+type DurationBaseline struct {
+	ApplicationId string
+	JobId         JobIdType
+	Season        Season
+	RunCount      int64
+	Median        time.Duration
+	P90           time.Duration
+}
+
+// SeasonsOf returns the seasons a business date is in, most specific first: its month position
+// (if it's the first or last business day), its weekday, and SeasonAll. Business days are
+// Monday to Friday; holidays aren't known.
+func SeasonsOf(businessDate time.Time) []Season {
+	var seasons []Season
+	if isFirstBusinessDay(businessDate) {
+		seasons = append(seasons, SeasonFirstBusinessDay)
+	}
+	if isLastBusinessDay(businessDate) {
+		seasons = append(seasons, SeasonLastBusinessDay)
+	}
+	return append(seasons, weekdaySeason(businessDate), SeasonAll)
+}
+
+// ExpectedDuration picks the baseline for a business date from one job's baselines: the most
+// specific season with at least minRuns runs. ok is false if no season has enough.
+func ExpectedDuration(baselines []DurationBaseline, businessDate time.Time, minRuns int64) (baseline DurationBaseline, ok bool) {
+	bySeason := map[Season]DurationBaseline{}
+	for _, b := range baselines {
+		bySeason[b.Season] = b
+	}
+	for _, season := range SeasonsOf(businessDate) {
+		if b, found := bySeason[season]; found && b.RunCount >= minRuns {
+			return b, true
+		}
+	}
+	return DurationBaseline{}, false
+}
+
+// ComputeDurationBaselines computes baselines for one job from its statuses, in any order. Repos
+// that can't use percentile_cont use it; repoDB computes the same thing in SQL.
+func ComputeDurationBaselines(applicationId string, jobId JobIdType, jss []JobStatus) []DurationBaseline {
+	type runKey struct {
+		businessDate time.Time
+		runId        RunIdType
+	}
+	type runEnds struct {
+		start, end time.Time
+	}
+	runs := map[runKey]runEnds{}
+	for _, js := range jss {
+		key := runKey{TruncateToDate(js.BusinessDate), js.RunId}
+		ends := runs[key]
+		switch js.JobStatusCode {
+		case JobStatus_START:
+			if ends.start.IsZero() || js.JobStatusTimestamp.Before(ends.start) {
+				ends.start = js.JobStatusTimestamp
+			}
+		case JobStatus_SUCCEED:
+			if js.JobStatusTimestamp.After(ends.end) {
+				ends.end = js.JobStatusTimestamp
+			}
+		}
+		runs[key] = ends
+	}
+
+	durations := map[Season][]time.Duration{}
+	for key, ends := range runs {
+		if ends.start.IsZero() || !ends.end.After(ends.start) {
+			continue
+		}
+		for _, season := range SeasonsOf(key.businessDate) {
+			durations[season] = append(durations[season], ends.end.Sub(ends.start))
+		}
+	}
+
+	var result []DurationBaseline
+	for season, ds := range durations {
+		sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+		result = append(result, DurationBaseline{
+			ApplicationId: applicationId,
+			JobId:         jobId,
+			Season:        season,
+			RunCount:      int64(len(ds)),
+			Median:        percentileCont(ds, 0.5),
+			P90:           percentileCont(ds, 0.9),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Season < result[j].Season })
+	return result
+}
+
+// percentileCont interpolates between the closest values, like Postgres percentile_cont.
+// sorted must be in ascending order and not empty.
+func percentileCont(sorted []time.Duration, p float64) time.Duration {
+	pos := p * float64(len(sorted)-1)
+	lower := int(pos)
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+	frac := pos - float64(lower)
+	return sorted[lower] + time.Duration(frac*float64(sorted[lower+1]-sorted[lower]))
+}
+
+func weekdaySeason(d time.Time) Season {
+	return Season(d.Weekday().String()[:3])
+}
+
+func isBusinessDay(d time.Time) bool {
+	return d.Weekday() != time.Saturday && d.Weekday() != time.Sunday
+}
+
+// isFirstBusinessDay is true for the 1st if it's a weekday, or the Monday after a weekend 1st.
+func isFirstBusinessDay(d time.Time) bool {
+	return isBusinessDay(d) && (d.Day() == 1 || (d.Weekday() == time.Monday && d.Day() <= 3))
+}
+
+// isLastBusinessDay is true for the month's last day if it's a weekday, or the Friday before a weekend end.
+func isLastBusinessDay(d time.Time) bool {
+	return isBusinessDay(d) && (d.AddDate(0, 0, 1).Month() != d.Month() ||
+		(d.Weekday() == time.Friday && d.AddDate(0, 0, 3).Month() != d.Month()))
+}
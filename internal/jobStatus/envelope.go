@@ -0,0 +1,130 @@
+package jobStatus
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// Envelope wraps successful JSON responses in a dto.EnvelopeDto for clients that ask for one
+// with Accept: dto.EnvelopeMediaType. If byDefault is true, clients get one unless their Accept
+// names application/json or JSON:API.
+//
+// Errors, responses that aren't JSON (CSV, SVG, zip), and streams pass through unwrapped. A
+// handler that flushes is streaming, so what it has written goes out as is.
+func Envelope(next http.Handler, byDefault bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !wantsEnvelope(r, byDefault) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		meta := &common.ResponseMeta{}
+		ew := &envelopeWriter{w: w, r: r, meta: meta}
+		next.ServeHTTP(ew, r.WithContext(common.WithResponseMeta(r.Context(), meta)))
+		ew.finish()
+	})
+}
+
+func wantsEnvelope(r *http.Request, byDefault bool) bool {
+	named := false
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			mediaType, _, _ = strings.Cut(mediaType, ";")
+			switch strings.TrimSpace(mediaType) {
+			case dto.EnvelopeMediaType:
+				return true
+			case common.ContentTypeJson, dto.JsonApiMediaType:
+				named = true
+			}
+		}
+	}
+	return byDefault && !named
+}
+
+// envelopeWriter holds a successful JSON response until the handler returns, then writes it in
+// an envelope. Anything else goes straight to w.
+type envelopeWriter struct {
+	w    http.ResponseWriter
+	r    *http.Request
+	meta *common.ResponseMeta
+
+	status      int
+	buf         bytes.Buffer
+	buffering   bool
+	wroteHeader bool
+}
+
+func (ew *envelopeWriter) Header() http.Header {
+	return ew.w.Header()
+}
+
+func (ew *envelopeWriter) WriteHeader(status int) {
+	if ew.wroteHeader {
+		return
+	}
+	ew.wroteHeader = true
+	ew.status = status
+	mediaType, _, _ := strings.Cut(ew.w.Header().Get("Content-Type"), ";")
+	ew.buffering = status >= 200 && status < 300 && strings.TrimSpace(mediaType) == common.ContentTypeJson
+	if !ew.buffering {
+		ew.w.WriteHeader(status)
+	}
+}
+
+func (ew *envelopeWriter) Write(b []byte) (int, error) {
+	if !ew.wroteHeader {
+		ew.WriteHeader(http.StatusOK)
+	}
+	if ew.buffering {
+		return ew.buf.Write(b)
+	}
+	return ew.w.Write(b)
+}
+
+// Flush sends what's held unwrapped and stops holding, because the handler is streaming.
+func (ew *envelopeWriter) Flush() {
+	if ew.buffering {
+		ew.buffering = false
+		ew.w.WriteHeader(ew.status)
+		ew.w.Write(ew.buf.Bytes())
+		ew.buf.Reset()
+	}
+	if f, ok := ew.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (ew *envelopeWriter) finish() {
+	if !ew.buffering {
+		return
+	}
+
+	env := dto.EnvelopeDto{
+		Data: json.RawMessage(bytes.TrimSpace(ew.buf.Bytes())),
+		Meta: dto.EnvelopeMetaDto{
+			RequestId:  common.RequestIdOf(ew.r),
+			ServerTime: time.Now().UTC().Format(dto.TimestampFormat),
+			Warnings:   ew.meta.Warnings(),
+		},
+	}
+	if page, ok := ew.meta.Page().(*dto.PageDto); ok {
+		env.Meta.Page = page
+	}
+	if len(env.Data) == 0 {
+		env.Data = json.RawMessage("null")
+	}
+
+	ew.w.Header().Del("Content-Length")
+	ew.w.Header().Set("Content-Type", dto.EnvelopeMediaType)
+	ew.w.WriteHeader(ew.status)
+	if err := json.NewEncoder(ew.w).Encode(env); err != nil {
+		log.Printf("%s %s envelope encode failed: %v", ew.r.Method, ew.r.URL.Path, err)
+	}
+}
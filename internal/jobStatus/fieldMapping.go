@@ -0,0 +1,223 @@
+package jobStatus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// FieldMappings renames JSON fields legacy clients send, like job_name, to JobStatusDto's names,
+// like JobId, per application, so a client's quirks don't need a new DTO version. A request
+// uses its application's mappings when it names the application in the appId query parameter;
+// legacy clients are configured with a URL, not a body format. Each mapping's uses are counted,
+// so a mapping can be dropped once its client sends the real names. A nil *FieldMappings maps
+// nothing. It's safe for concurrent use.
+type FieldMappings struct {
+	byApp map[string]map[string]string // ApplicationId -> legacy name -> DTO name
+
+	mu   sync.Mutex
+	uses map[string]map[string]*dto.FieldMappingDto // ApplicationId -> legacy name -> usage
+}
+
+// ParseFieldMappings parses items like "overdrafts:job_name=JobId", separated by commas. A
+// field can only be mapped to a JobStatusDto field that new statuses are read from.
+func ParseFieldMappings(s string) (*FieldMappings, error) {
+	fm := &FieldMappings{byApp: map[string]map[string]string{}, uses: map[string]map[string]*dto.FieldMappingDto{}}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		appFrom, to, ok := strings.Cut(item, "=")
+		appId, from, hasApp := strings.Cut(appFrom, ":")
+		appId, from, to = strings.TrimSpace(appId), strings.TrimSpace(from), strings.TrimSpace(to)
+		switch {
+		case !ok || !hasApp || appId == "" || from == "" || to == "":
+			return nil, fmt.Errorf("field mapping %q must be appId:legacyField=DtoField", item)
+		case !isInputField(to):
+			return nil, fmt.Errorf("field mapping %q: %q isn't a field new statuses are read from", item, to)
+		case isInputField(from):
+			return nil, fmt.Errorf("field mapping %q: %q is already a DTO field", item, from)
+		}
+		if fm.byApp[appId] == nil {
+			fm.byApp[appId] = map[string]string{}
+			fm.uses[appId] = map[string]*dto.FieldMappingDto{}
+		}
+		if _, dupe := fm.byApp[appId][from]; dupe {
+			return nil, fmt.Errorf("field mapping %q: %s's %q is already mapped", item, appId, from)
+		}
+		fm.byApp[appId][from] = to
+		fm.uses[appId][from] = &dto.FieldMappingDto{AppId: appId, From: from, To: to}
+	}
+	return fm, nil
+}
+
+// FieldMappingsFromEnv reads GOJST_FIELD_MAPPINGS (see ParseFieldMappings). It's empty by default.
+func FieldMappingsFromEnv(getenv func(string) string) (*FieldMappings, error) {
+	fm, err := ParseFieldMappings(getenv("GOJST_FIELD_MAPPINGS"))
+	if err != nil {
+		return nil, fmt.Errorf("GOJST_FIELD_MAPPINGS: %w", err)
+	}
+	return fm, nil
+}
+
+// isInputField is true for the JobStatusDto fields a new status is read from.
+func isInputField(name string) bool {
+	for _, field := range requiredOnInput {
+		if field == name {
+			return true
+		}
+	}
+	return name == "RunId" || name == "Links"
+}
+
+// MaxAddBodyBytes bounds the body of a status or batch add. It leaves room for a batch of
+// MaxBatchStatuses statuses with a few links each.
+const MaxAddBodyBytes = 8 << 20
+
+// decode reads a status, or a batch of them, from r's body into v. If r's appId parameter names
+// an application with mappings, its legacy fields are renamed first, and statuses without an
+// AppId get appId. Statuses with a different AppId are a props error, since the mappings are
+// the wrong ones for them. Errors reading the JSON are JsonDecode CommonErrors, and a body over
+// MaxAddBodyBytes is a BodyTooLarge CommonError.
+func (fm *FieldMappings) decode(w http.ResponseWriter, r *http.Request, v any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxAddBodyBytes)
+	appId := r.URL.Query().Get("appId")
+	var mapping map[string]string
+	if fm != nil {
+		mapping = fm.byApp[appId]
+	}
+	if mapping == nil {
+		if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+			return bodyError(err)
+		}
+		return nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return bodyError(err)
+	}
+	var objs []map[string]json.RawMessage
+	batch := bytes.HasPrefix(bytes.TrimSpace(body), []byte("["))
+	if batch {
+		err = json.Unmarshal(body, &objs)
+	} else {
+		objs = make([]map[string]json.RawMessage, 1)
+		err = json.Unmarshal(body, &objs[0])
+	}
+	if err != nil {
+		return common.NewCommonError(common.ErrcdJsonDecode, err)
+	}
+	for i, obj := range objs {
+		if obj == nil {
+			return common.NewCommonError(common.ErrcdJsonDecode, fmt.Errorf("status %d is null", i))
+		}
+	}
+
+	appIdJson, _ := json.Marshal(appId)
+	used := map[string]bool{}
+	var problems common.FieldErrors
+	for i, obj := range objs {
+		prefix := ""
+		if batch {
+			prefix = fmt.Sprintf("[%d].", i)
+		}
+		for from, to := range mapping {
+			val, ok := obj[from]
+			if !ok {
+				continue
+			}
+			if _, both := obj[to]; both {
+				problems = append(problems, common.FieldError{Field: prefix + to, Message: fmt.Sprintf("is also sent as %s, which %s maps to it", from, appId)})
+				continue
+			}
+			obj[to] = val
+			delete(obj, from)
+			used[from] = true
+		}
+		sent, ok := obj["AppId"]
+		var sentAppId string
+		switch {
+		case !ok:
+			obj["AppId"] = appIdJson
+		case json.Unmarshal(sent, &sentAppId) != nil || sentAppId != appId:
+			problems = append(problems, common.FieldError{Field: prefix + "AppId", Message: fmt.Sprintf("%s doesn't match the appId parameter %q", sent, appId)})
+		}
+	}
+	if len(problems) > 0 {
+		return common.NewCommonError(common.ErrcdDomainProps, problems)
+	}
+	fm.count(appId, used)
+
+	if batch {
+		body, err = json.Marshal(objs)
+	} else {
+		body, err = json.Marshal(objs[0])
+	}
+	if err != nil {
+		return common.NewCommonError(common.ErrcdJsonDecode, err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return common.NewCommonError(common.ErrcdJsonDecode, err)
+	}
+	return nil
+}
+
+// bodyError codes an error reading a request body: BodyTooLarge if the body was over the
+// limit, JsonDecode otherwise.
+func bodyError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return common.NewCommonError(common.ErrcdBodyTooLarge, fmt.Errorf("body is over %d bytes", tooLarge.Limit))
+	}
+	return common.NewCommonError(common.ErrcdJsonDecode, err)
+}
+
+// count adds a use to each mapping a request used, once per request, however many statuses
+// it had.
+func (fm *FieldMappings) count(appId string, used map[string]bool) {
+	if len(used) == 0 {
+		return
+	}
+	now := time.Now().UTC().Format(dto.TimestampFormat)
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	for from := range used {
+		u := fm.uses[appId][from]
+		u.Uses++
+		u.LastUsed = now
+	}
+}
+
+// Usage returns every mapping and its uses since the server started, ordered by AppId and From.
+func (fm *FieldMappings) Usage() []dto.FieldMappingDto {
+	if fm == nil {
+		return []dto.FieldMappingDto{}
+	}
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	usage := []dto.FieldMappingDto{}
+	for _, byFrom := range fm.uses {
+		for _, u := range byFrom {
+			usage = append(usage, *u)
+		}
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].AppId != usage[j].AppId {
+			return usage[i].AppId < usage[j].AppId
+		}
+		return usage[i].From < usage[j].From
+	})
+	return usage
+}
@@ -0,0 +1,23 @@
+package jobStatus
+
+import (
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// FieldMappingsPath is the admin route that lists field mappings and how much they're used.
+const FieldMappingsPath = "/admin/field-mappings"
+
+type GetFieldMappingsCtrl struct {
+	fm *FieldMappings
+}
+
+func NewGetFieldMappingsCtrl(fm *FieldMappings) *GetFieldMappingsCtrl {
+	return &GetFieldMappingsCtrl{fm: fm}
+}
+
+// ServeHTTP handles GET and lists every mapping with its uses.
+func (ctrl *GetFieldMappingsCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	common.WriteJson(w, http.StatusOK, ctrl.fm.Usage())
+}
@@ -0,0 +1,155 @@
+package jobStatus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// BaselineModel is the Model name of forecasts from BaselineForecaster.
+const BaselineModel = "baseline"
+
+// ForecastInput is a run in progress and the history to forecast it from.
+type ForecastInput struct {
+	ApplicationId string
+	JobId         JobIdType
+	BusinessDate  time.Time
+	RunId         RunIdType
+	StartedAt     time.Time
+	AsOf          time.Time
+	// Deadline is when the run needs to end. Zero means there isn't one.
+	Deadline time.Time
+	// Baselines are the job's duration baselines, every season, for the year before BusinessDate.
+	Baselines []DurationBaseline
+}
+
+// Forecast predicts when a run will end. MissProbability is the chance it ends after the
+// input's Deadline, from 0 to 1; it's 0 without a Deadline.
+type Forecast struct {
+	Model           string
+	ExpectedEnd     time.Time
+	MissProbability float64
+}
+
+// Forecaster predicts when runs in progress will end. Implementations may call other services,
+// so they should return when ctx is done.
+type Forecaster interface {
+	Forecast(ctx context.Context, in ForecastInput) (Forecast, error)
+}
+
+// Forecasters picks a Forecaster for each application. A nil *Forecasters, or a nil Default,
+// uses BaselineForecaster.
+type Forecasters struct {
+	Default       Forecaster
+	ByApplication map[string]Forecaster
+}
+
+// For returns the application's Forecaster, or the default.
+func (fs *Forecasters) For(applicationId string) Forecaster {
+	if fs == nil {
+		return BaselineForecaster{}
+	}
+	if f, ok := fs.ByApplication[applicationId]; ok {
+		return f
+	}
+	if fs.Default == nil {
+		return BaselineForecaster{}
+	}
+	return fs.Default
+}
+
+// BaselineForecaster is the built-in forecaster. It uses the duration baseline ExpectedDuration
+// picks for the business date, with at least MinRuns runs (zero means DefaultBaselineMinRuns).
+//
+// It treats the chance a run is still going after d as 1 at the start, 0.5 at the median, and 0.1
+// at P90, linear in between, then falling by a factor of e every (P90 - median) after that. A run
+// that has been going for a while can only end later, so both the expected end and the chance of
+// a miss are for runs that have lasted as long as this one: the expected end is the median of
+// those runs, and the miss probability is the share of them still going at the deadline.
+type BaselineForecaster struct {
+	MinRuns int64
+}
+
+var _ Forecaster = BaselineForecaster{}
+
+func (bf BaselineForecaster) Forecast(ctx context.Context, in ForecastInput) (Forecast, error) {
+	minRuns := bf.MinRuns
+	if minRuns == 0 {
+		minRuns = DefaultBaselineMinRuns
+	}
+	b, ok := ExpectedDuration(in.Baselines, in.BusinessDate, minRuns)
+	if !ok {
+		return Forecast{}, common.NewCommonError(common.ErrcdNotFound,
+			fmt.Errorf("%s has fewer than %d successful runs in every season to forecast from", in.JobId, minRuns))
+	}
+
+	curve := newSurvivalCurve(b)
+	elapsed := in.AsOf.Sub(in.StartedAt)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	f := Forecast{Model: BaselineModel}
+	if elapsed > curve.median+curve.spread {
+		// the tail is exponential, so how much longer doesn't depend on how long it's been; working
+		// from the deadline's distance avoids dividing tiny survival values for very late runs
+		f.ExpectedEnd = in.AsOf.Add(time.Duration(math.Ln2 * float64(curve.spread)))
+		if !in.Deadline.IsZero() {
+			f.MissProbability = math.Min(1, math.Exp(-float64(in.Deadline.Sub(in.AsOf))/float64(curve.spread)))
+		}
+		return f, nil
+	}
+	stillGoing := curve.survival(elapsed)
+	f.ExpectedEnd = in.StartedAt.Add(curve.duration(stillGoing / 2))
+	switch {
+	case in.Deadline.IsZero():
+	case !in.Deadline.After(in.AsOf):
+		f.MissProbability = 1
+	default:
+		f.MissProbability = curve.survival(in.Deadline.Sub(in.StartedAt)) / stillGoing
+	}
+	return f, nil
+}
+
+// survivalCurve is the chance a run is still going after a duration, as BaselineForecaster
+// describes.
+type survivalCurve struct {
+	median, spread time.Duration
+}
+
+func newSurvivalCurve(b DurationBaseline) survivalCurve {
+	spread := b.P90 - b.Median
+	if spread <= 0 {
+		// every run took about as long, so allow a little either way
+		spread = b.Median / 10
+	}
+	if spread <= 0 {
+		spread = time.Second
+	}
+	return survivalCurve{median: b.Median, spread: spread}
+}
+
+func (c survivalCurve) survival(d time.Duration) float64 {
+	switch {
+	case d <= 0:
+		return 1
+	case d <= c.median:
+		return 1 - 0.5*float64(d)/float64(c.median)
+	case d <= c.median+c.spread:
+		return 0.5 - 0.4*float64(d-c.median)/float64(c.spread)
+	}
+	return 0.1 * math.Exp(-float64(d-c.median-c.spread)/float64(c.spread))
+}
+
+// duration is the inverse of survival: how long until only share s of runs are still going.
+func (c survivalCurve) duration(s float64) time.Duration {
+	switch {
+	case s >= 0.5:
+		return time.Duration(2 * (1 - s) * float64(c.median))
+	case s >= 0.1:
+		return c.median + time.Duration((0.5-s)/0.4*float64(c.spread))
+	}
+	return c.median + c.spread + time.Duration(math.Log(0.1/s)*float64(c.spread))
+}
@@ -0,0 +1,128 @@
+package jobStatus
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// DefaultForecastTimeout bounds one forecast, so a slow external model can't hold a request.
+const DefaultForecastTimeout = 5 * time.Second
+
+type ForecastUC struct {
+	repo            Repo
+	reliabilityRepo ReliabilityRepo
+	forecasters     *Forecasters
+}
+
+// NewForecastUC returns the use case. A nil forecasters uses BaselineForecaster for every application.
+func NewForecastUC(repo Repo, reliabilityRepo ReliabilityRepo, forecasters *Forecasters) *ForecastUC {
+	return &ForecastUC{repo: repo, reliabilityRepo: reliabilityRepo, forecasters: forecasters}
+}
+
+// Forecast predicts when the job's latest run on businessDate will end, from the year of business
+// dates before it. deadline is an optional RFC 3339 timestamp. asOf works like QueryParams.AsOf
+// and is also the time the forecast is made. If the application's Forecaster fails, it's logged
+// and BaselineForecaster is used instead, so the Model in the result says which one answered.
+func (uc *ForecastUC) Forecast(ctx context.Context, applicationId string, jobId string, businessDate string, deadline string, asOf string) (dto.JobForecastDto, error) {
+	if len(applicationId) == 0 {
+		return dto.JobForecastDto{}, propsError("AppId is required")
+	}
+	id, err := NewJobId(jobId)
+	if err != nil {
+		return dto.JobForecastDto{}, err
+	}
+	busDt, err := parseDateProp("BusinessDate", businessDate)
+	if err != nil {
+		return dto.JobForecastDto{}, err
+	}
+	var deadlineTs time.Time
+	if len(deadline) > 0 {
+		if deadlineTs, err = time.Parse(time.RFC3339Nano, deadline); err != nil {
+			return dto.JobForecastDto{}, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("deadline: %w", err))
+		}
+	}
+	asOfTs, err := parseAsOf(asOf)
+	if err != nil {
+		return dto.JobForecastDto{}, err
+	}
+
+	jss, err := uc.repo.GetByJobIdBusinessDate(ctx, id, busDt, QueryOptions{AsOf: asOfTs})
+	if err != nil {
+		return dto.JobForecastDto{}, err
+	}
+	start, running := latestRunStart(applicationId, jss)
+	if !running {
+		return dto.JobForecastDto{}, common.NewCommonError(common.ErrcdNotFound,
+			fmt.Errorf("%s has no run in progress on %s", id, busDt.Format(dto.DateFormat)))
+	}
+
+	baselines, err := uc.reliabilityRepo.GetDurationBaselines(ctx, applicationId, id, busDt.AddDate(0, 0, -MaxBaselineDays), busDt.AddDate(0, 0, -1))
+	if err != nil {
+		return dto.JobForecastDto{}, err
+	}
+
+	in := ForecastInput{
+		ApplicationId: applicationId,
+		JobId:         id,
+		BusinessDate:  busDt,
+		RunId:         start.RunId,
+		StartedAt:     start.JobStatusTimestamp,
+		AsOf:          asOfTs,
+		Deadline:      deadlineTs,
+		Baselines:     baselines,
+	}
+	if in.AsOf.IsZero() {
+		in.AsOf = time.Now().UTC()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultForecastTimeout)
+	defer cancel()
+	forecaster := uc.forecasters.For(applicationId)
+	f, err := forecaster.Forecast(ctx, in)
+	if _, builtIn := forecaster.(BaselineForecaster); err != nil && !builtIn {
+		log.Printf("forecaster for %s failed, using %s: %v", applicationId, BaselineModel, err)
+		f, err = BaselineForecaster{}.Forecast(ctx, in)
+	}
+	if err != nil {
+		return dto.JobForecastDto{}, err
+	}
+
+	result := dto.JobForecastDto{
+		ApplicationId:        applicationId,
+		JobId:                string(id),
+		BusinessDate:         busDt.Format(dto.DateFormat),
+		RunId:                string(in.RunId),
+		StartedTimestamp:     in.StartedAt.Format(dto.TimestampFormat),
+		AsOfTimestamp:        in.AsOf.Format(dto.TimestampFormat),
+		Model:                f.Model,
+		ExpectedEndTimestamp: f.ExpectedEnd.Format(dto.TimestampFormat),
+	}
+	if !deadlineTs.IsZero() {
+		result.DeadlineTimestamp = deadlineTs.Format(dto.TimestampFormat)
+		result.MissProbability = &f.MissProbability
+	}
+	return result, nil
+}
+
+// latestRunStart returns the application's latest START, if its run hasn't ended.
+func latestRunStart(applicationId string, jss []JobStatus) (start JobStatus, running bool) {
+	for _, js := range jss {
+		if js.ApplicationId == applicationId && js.JobStatusCode == JobStatus_START && js.JobStatusTimestamp.After(start.JobStatusTimestamp) {
+			start = js
+		}
+	}
+	if start.JobStatusTimestamp.IsZero() {
+		return JobStatus{}, false
+	}
+	for _, js := range jss {
+		if js.RunId == start.RunId && (js.JobStatusCode == JobStatus_SUCCEED || js.JobStatusCode == JobStatus_FAIL) {
+			return JobStatus{}, false
+		}
+	}
+	return start, true
+}
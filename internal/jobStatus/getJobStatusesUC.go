@@ -0,0 +1,256 @@
+package jobStatus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+type GetJobStatusesUC struct {
+	repo    Repo
+	views   SavedViewRepo
+	filters FilterRepo
+}
+
+// NewGetJobStatusesUC returns the query use case. If views is nil, queries by view are refused;
+// if filters is nil, queries by filters alone are.
+func NewGetJobStatusesUC(repo Repo, views SavedViewRepo, filters FilterRepo) *GetJobStatusesUC {
+	return &GetJobStatusesUC{repo: repo, views: views, filters: filters}
+}
+
+// QueryParams are optional query parameters as clients send them.
+type QueryParams struct {
+	// Fields is a comma separated list of DTO field names to return; empty returns all fields.
+	Fields string
+	// Sort is described in parseSort.
+	Sort string
+	// AllowPartial returns the rows that can be read even if some can't. The use case then
+	// returns those rows along with an error for which IsPartialResult is true.
+	AllowPartial bool
+	// Filters are described in parseFilters. They narrow any query and can stand in for a
+	// JobId; see GetByFilters.
+	Filters map[string]string
+	// AsOf is an RFC 3339 timestamp. If it's set, results are what the server had received
+	// by then, so a query can answer "what did we know at 7am?" Empty means now.
+	AsOf string
+	// Limit (1 to MaxPageLimit) returns one page of results, starting after Offset (0 to
+	// MaxPageOffset). Empty Limit returns everything, and Offset needs a Limit.
+	Limit  string
+	Offset string
+}
+
+// MaxPageLimit bounds a page. MaxPageOffset bounds how far a query can skip, because the database
+// still reads every skipped row; page further with a JobStTs filter instead.
+const (
+	MaxPageLimit  = 1000
+	MaxPageOffset = 100000
+)
+
+// GetByJobId returns all statuses for a job.
+func (uc *GetJobStatusesUC) GetByJobId(ctx context.Context, jobId string, params QueryParams) ([]dto.JobStatusDto, error) {
+	id, err := NewJobId(jobId)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := parseQueryOptions(params)
+	if err != nil {
+		return nil, err
+	}
+
+	jss, err := uc.repo.GetByJobId(ctx, id, opts)
+	if err != nil && !IsPartialResult(err) {
+		return nil, err
+	}
+	return domainsToDtos(jss, opts.SelectedFields()), err
+}
+
+// GetByJobIdBusinessDate returns all statuses for a job on one business date.
+func (uc *GetJobStatusesUC) GetByJobIdBusinessDate(ctx context.Context, jobId string, businessDate string, params QueryParams) ([]dto.JobStatusDto, error) {
+	id, err := NewJobId(jobId)
+	if err != nil {
+		return nil, err
+	}
+	busDt, err := parseDateProp("BusinessDate", businessDate)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := parseQueryOptions(params)
+	if err != nil {
+		return nil, err
+	}
+
+	jss, err := uc.repo.GetByJobIdBusinessDate(ctx, id, busDt, opts)
+	if err != nil && !IsPartialResult(err) {
+		return nil, err
+	}
+	return domainsToDtos(jss, opts.SelectedFields()), err
+}
+
+// GetByFilters returns statuses matching params.Filters, for queries without a JobId. The
+// filters have to narrow on JobId or BusDt (other than ne) so the query can use an index.
+func (uc *GetJobStatusesUC) GetByFilters(ctx context.Context, params QueryParams) ([]dto.JobStatusDto, error) {
+	if uc.filters == nil {
+		return nil, common.NewCommonError(common.ErrcdDomainProps, errors.New("queries by filters are not available"))
+	}
+	opts, err := parseFilterQueryOptions(params)
+	if err != nil {
+		return nil, err
+	}
+
+	jss, err := uc.filters.GetByFilters(ctx, opts)
+	if err != nil && !IsPartialResult(err) {
+		return nil, err
+	}
+	return domainsToDtos(jss, opts.SelectedFields()), err
+}
+
+// GetByView returns statuses for every job in the named view, optionally on one business date
+// (empty businessDate means all dates), keeping only the view's applications if it lists any.
+// params.Fields and params.Sort override the view's. Each job is a separate query, so the
+// combined result is sorted here.
+func (uc *GetJobStatusesUC) GetByView(ctx context.Context, viewName string, businessDate string, params QueryParams) ([]dto.JobStatusDto, error) {
+	if uc.views == nil {
+		return nil, common.NewCommonError(common.ErrcdDomainProps, errors.New("saved views are not available"))
+	}
+	view, err := getSavedView(ctx, uc.views, viewName)
+	if err != nil {
+		return nil, err
+	}
+	if len(view.JobIds) == 0 {
+		return nil, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("view %q has no JobIds to query", viewName))
+	}
+	if len(params.Fields) == 0 {
+		params.Fields = view.Fields
+	}
+	if len(params.Sort) == 0 {
+		params.Sort = view.Sort
+	}
+	opts, err := parseQueryOptions(params)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Limit > 0 {
+		return nil, propsError("view results can't be paged")
+	}
+
+	// the query needs the filter and sort fields even if the caller didn't ask for them
+	queryOpts := opts
+	if len(opts.Fields) > 0 {
+		queryOpts.Fields = append([]FieldName{}, opts.Fields...)
+		if len(view.ApplicationIds) > 0 {
+			queryOpts.Fields = addField(queryOpts.Fields, FieldApplicationId)
+		}
+		for _, sf := range opts.Sort {
+			queryOpts.Fields = addField(queryOpts.Fields, sf.Field)
+		}
+	}
+
+	var busDt time.Time
+	if len(businessDate) > 0 {
+		if busDt, err = parseDateProp("BusinessDate", businessDate); err != nil {
+			return nil, err
+		}
+	}
+
+	var jss []JobStatus
+	var rowErrors []RowError
+	// row numbers count across the whole view, as if it were one query
+	rowsRead := 0
+	for _, jobId := range view.JobIds {
+		var found []JobStatus
+		if len(businessDate) > 0 {
+			found, err = uc.repo.GetByJobIdBusinessDate(ctx, jobId, busDt, queryOpts)
+		} else {
+			found, err = uc.repo.GetByJobId(ctx, jobId, queryOpts)
+		}
+		if err != nil && !IsPartialResult(err) {
+			return nil, err
+		}
+		for _, re := range RowErrorsOf(err) {
+			re.Row += rowsRead
+			rowErrors = append(rowErrors, re)
+		}
+		rowsRead += len(found) + len(RowErrorsOf(err))
+		jss = append(jss, filterApplications(found, view.ApplicationIds)...)
+	}
+
+	SortJobStatuses(jss, opts.Sort)
+	result := domainsToDtos(jss, opts.SelectedFields())
+	if len(rowErrors) > 0 {
+		return result, NewPartialResultError(rowErrors)
+	}
+	return result, nil
+}
+
+func addField(fields []FieldName, field FieldName) []FieldName {
+	for _, f := range fields {
+		if f == field {
+			return fields
+		}
+	}
+	return append(fields, field)
+}
+
+func filterApplications(jss []JobStatus, applicationIds []string) []JobStatus {
+	if len(applicationIds) == 0 {
+		return jss
+	}
+	keep := map[string]bool{}
+	for _, appId := range applicationIds {
+		keep[appId] = true
+	}
+	var result []JobStatus
+	for _, js := range jss {
+		if keep[js.ApplicationId] {
+			result = append(result, js)
+		}
+	}
+	return result
+}
+
+func parseQueryOptions(params QueryParams) (QueryOptions, error) {
+	fieldNames, err := parseFields(params.Fields)
+	if err != nil {
+		return QueryOptions{}, err
+	}
+	sortFields, err := parseSort(params.Sort)
+	if err != nil {
+		return QueryOptions{}, err
+	}
+	filters, err := parseFilters(params.Filters)
+	if err != nil {
+		return QueryOptions{}, err
+	}
+	asOf, err := parseAsOf(params.AsOf)
+	if err != nil {
+		return QueryOptions{}, err
+	}
+	limit, err := parseIntParam("limit", params.Limit, 0, 1, MaxPageLimit)
+	if err != nil {
+		return QueryOptions{}, err
+	}
+	offset, err := parseIntParam("offset", params.Offset, 0, 0, MaxPageOffset)
+	if err != nil {
+		return QueryOptions{}, err
+	}
+	if offset > 0 && limit == 0 {
+		return QueryOptions{}, propsError("offset needs a limit")
+	}
+	return QueryOptions{Fields: fieldNames, Sort: sortFields, AllowPartial: params.AllowPartial, Filters: filters, AsOf: asOf, Limit: limit, Offset: offset}, nil
+}
+
+// parseFilterQueryOptions is parseQueryOptions for queries by filters alone.
+func parseFilterQueryOptions(params QueryParams) (QueryOptions, error) {
+	opts, err := parseQueryOptions(params)
+	if err != nil {
+		return QueryOptions{}, err
+	}
+	if !hasIndexedFilter(opts.Filters) {
+		return QueryOptions{}, common.NewCommonError(common.ErrcdDomainProps, errors.New("a query without a jobId needs a JobId or BusDt filter"))
+	}
+	return opts, nil
+}
@@ -0,0 +1,134 @@
+package jobStatus
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+	pb "github.com/jmjf/go-jst/public/jobStatus/grpc"
+)
+
+// GrpcWriteMethods are the gRPC service's writes, for interceptors that treat writes
+// differently, like region.RejectGrpcWritesWhenPassive.
+var GrpcWriteMethods = []string{pb.JobStatusService_AddJobStatus_FullMethodName}
+
+// GrpcServer serves pb.JobStatusService with the use cases the HTTP controllers call, so both
+// APIs validate, store, and query the same way. Field mappings don't apply; they're chosen by
+// an HTTP query parameter.
+type GrpcServer struct {
+	pb.UnimplementedJobStatusServiceServer
+	addUC *AddJobStatusUC
+	getUC *GetJobStatusesUC
+}
+
+func NewGrpcServer(addUC *AddJobStatusUC, getUC *GetJobStatusesUC) *GrpcServer {
+	return &GrpcServer{addUC: addUC, getUC: getUC}
+}
+
+// RegisterGrpcService registers the job status gRPC service on s. It needs only ports.Repo, and
+// uses svc as AddRoutes does, so quotas and metering count gRPC adds too.
+func RegisterGrpcService(s *grpc.Server, ports Ports, svc Services) {
+	pb.RegisterJobStatusServiceServer(s, NewGrpcServer(NewAddJobStatusUC(ports.Repo, svc), NewGetJobStatusesUC(ports.Repo, nil, nil)))
+}
+
+// AddJobStatus is AddJobStatusCtrl. Added is false if the same status was already stored.
+func (srv *GrpcServer) AddJobStatus(ctx context.Context, req *pb.AddJobStatusRequest) (*pb.AddJobStatusResponse, error) {
+	result, added, err := srv.addUC.Add(ctx, pbToDto(req.GetJobStatus()))
+	if err != nil {
+		return nil, common.GrpcError(ctx, pb.JobStatusService_AddJobStatus_FullMethodName, err)
+	}
+	return &pb.AddJobStatusResponse{JobStatus: dtoToPb(result), Added: added}, nil
+}
+
+// GetByJobId is GetJobStatusesCtrl with a jobId.
+func (srv *GrpcServer) GetByJobId(ctx context.Context, req *pb.GetByJobIdRequest) (*pb.JobStatuses, error) {
+	params := pbQueryParams(req.GetOptions())
+	result, err := srv.getUC.GetByJobId(ctx, req.GetJobId(), params)
+	if err != nil {
+		return nil, common.GrpcError(ctx, pb.JobStatusService_GetByJobId_FullMethodName, err)
+	}
+	return dtosToPb(result, params), nil
+}
+
+// GetByJobIdBusinessDate is GetJobStatusesCtrl with a jobId and busDt.
+func (srv *GrpcServer) GetByJobIdBusinessDate(ctx context.Context, req *pb.GetByJobIdBusinessDateRequest) (*pb.JobStatuses, error) {
+	params := pbQueryParams(req.GetOptions())
+	result, err := srv.getUC.GetByJobIdBusinessDate(ctx, req.GetJobId(), req.GetBusinessDate(), params)
+	if err != nil {
+		return nil, common.GrpcError(ctx, pb.JobStatusService_GetByJobIdBusinessDate_FullMethodName, err)
+	}
+	return dtosToPb(result, params), nil
+}
+
+// pbQueryParams are the query parameters opts stands for. A zero limit or offset wasn't sent,
+// so the use case checks the rest as it does for HTTP.
+func pbQueryParams(opts *pb.QueryOptions) QueryParams {
+	params := QueryParams{
+		Fields: strings.Join(opts.GetFields(), ","),
+		Sort:   strings.Join(opts.GetSort(), ","),
+		AsOf:   opts.GetAsOf(),
+	}
+	if opts.GetLimit() != 0 {
+		params.Limit = strconv.Itoa(int(opts.GetLimit()))
+	}
+	if opts.GetOffset() != 0 {
+		params.Offset = strconv.Itoa(int(opts.GetOffset()))
+	}
+	return params
+}
+
+func dtosToPb(jsDtos []dto.JobStatusDto, params QueryParams) *pb.JobStatuses {
+	result := &pb.JobStatuses{JobStatuses: make([]*pb.JobStatus, len(jsDtos))}
+	for i, jsDto := range jsDtos {
+		result.JobStatuses[i] = dtoToPb(jsDto)
+	}
+	if len(params.Limit) > 0 {
+		if next := pageOf(params, len(jsDtos)).NextOffset; next != nil {
+			n := int32(*next)
+			result.NextOffset = &n
+		}
+	}
+	return result
+}
+
+func pbToDto(js *pb.JobStatus) dto.JobStatusDto {
+	jsDto := dto.JobStatusDto{
+		ApplicationId:      js.GetApplicationId(),
+		JobId:              js.GetJobId(),
+		JobStatusCode:      js.GetJobStatusCode(),
+		JobStatusTimestamp: js.GetJobStatusTimestamp(),
+		BusinessDate:       js.GetBusinessDate(),
+		RunId:              js.GetRunId(),
+		HostId:             js.GetHostId(),
+		Metadata:           js.GetMetadata(),
+	}
+	for _, l := range js.GetLinks() {
+		jsDto.Links = append(jsDto.Links, dto.LinkDto{Kind: l.GetKind(), Url: l.GetUrl()})
+	}
+	return jsDto
+}
+
+func dtoToPb(jsDto dto.JobStatusDto) *pb.JobStatus {
+	js := &pb.JobStatus{
+		StatusId:           jsDto.StatusId,
+		ApplicationId:      jsDto.ApplicationId,
+		JobId:              jsDto.JobId,
+		JobStatusCode:      jsDto.JobStatusCode,
+		JobStatusTimestamp: jsDto.JobStatusTimestamp,
+		BusinessDate:       jsDto.BusinessDate,
+		RunId:              jsDto.RunId,
+		HostId:             jsDto.HostId,
+		ReportedJobId:      jsDto.ReportedJobId,
+		ReceivedTimestamp:  jsDto.ReceivedTimestamp,
+		Metadata:           jsDto.Metadata,
+		HeartbeatTimestamp: jsDto.HeartbeatTimestamp,
+	}
+	for _, l := range jsDto.Links {
+		js.Links = append(js.Links, &pb.Link{Kind: l.Kind, Url: l.Url})
+	}
+	return js
+}
@@ -0,0 +1,215 @@
+package jobStatus_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbmemory"
+	"github.com/jmjf/go-jst/public/jobStatus/client"
+	pb "github.com/jmjf/go-jst/public/jobStatus/grpc"
+)
+
+// grpcEnv serves the gRPC API and the HTTP API from one repo, so a test can compare them.
+type grpcEnv struct {
+	grpc pb.JobStatusServiceClient
+	http *client.Client
+}
+
+func newGrpcEnv(t *testing.T) grpcEnv {
+	t.Helper()
+	repo := dbmemory.NewRepoMemory()
+	ports := jobStatus.FullPorts(repo)
+
+	ln := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	jobStatus.RegisterGrpcService(server, ports, jobStatus.Services{})
+	go server.Serve(ln)
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	mux := http.NewServeMux()
+	jobStatus.AddRoutes(mux, ports, jobStatus.Services{})
+	httpServer := httptest.NewServer(mux)
+	t.Cleanup(httpServer.Close)
+
+	return grpcEnv{grpc: pb.NewJobStatusServiceClient(conn), http: client.New(httpServer.URL, httpServer.Client())}
+}
+
+func samplePb() *pb.JobStatus {
+	return &pb.JobStatus{
+		ApplicationId:      sampleDto.ApplicationId,
+		JobId:              sampleDto.JobId,
+		JobStatusCode:      sampleDto.JobStatusCode,
+		JobStatusTimestamp: sampleDto.JobStatusTimestamp,
+		BusinessDate:       sampleDto.BusinessDate,
+		RunId:              sampleDto.RunId,
+		HostId:             sampleDto.HostId,
+		Links:              []*pb.Link{{Kind: "log", Url: "https://logs.example.com/od-calc/1"}},
+		Metadata:           map[string]string{"exitCode": "0"},
+	}
+}
+
+func TestGrpcAddJobStatusMatchesHttp(t *testing.T) {
+	env := newGrpcEnv(t)
+	ctx := context.Background()
+
+	res, err := env.grpc.AddJobStatus(ctx, &pb.AddJobStatusRequest{JobStatus: samplePb()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.GetAdded() || res.GetJobStatus().GetStatusId() == "" || res.GetJobStatus().GetReceivedTimestamp() == "" {
+		t.Errorf("got %v, want an added status with a StatusId and RecvTs", res)
+	}
+
+	retry, err := env.grpc.AddJobStatus(ctx, &pb.AddJobStatusRequest{JobStatus: samplePb()})
+	if err != nil || retry.GetAdded() || retry.GetJobStatus().GetStatusId() != res.GetJobStatus().GetStatusId() {
+		t.Errorf("retry: got %v, %v, want the stored status, not added", retry, err)
+	}
+
+	got, err := env.http.GetByJobId(sampleDto.JobId, client.QueryOptions{})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("HTTP: got %v, %v, want the status added over gRPC", got, err)
+	}
+	want := res.GetJobStatus()
+	if got[0].StatusId != want.GetStatusId() || got[0].JobStatusTimestamp != want.GetJobStatusTimestamp() || got[0].ReceivedTimestamp != want.GetReceivedTimestamp() ||
+		len(got[0].Links) != 1 || got[0].Links[0].Url != want.GetLinks()[0].GetUrl() || got[0].Metadata["exitCode"] != want.GetMetadata()["exitCode"] {
+		t.Errorf("HTTP: got %+v, want %v", got[0], want)
+	}
+}
+
+func TestGrpcErrorCodes(t *testing.T) {
+	env := newGrpcEnv(t)
+	ctx := context.Background()
+
+	invalid := samplePb()
+	invalid.BusinessDate = "June 15"
+	_, err := env.grpc.AddJobStatus(ctx, &pb.AddJobStatusRequest{JobStatus: invalid})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("invalid: got %v, want %s", err, codes.InvalidArgument)
+	}
+	var reason string
+	var fields []string
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			reason = d.GetReason()
+		case *errdetails.BadRequest:
+			for _, fv := range d.GetFieldViolations() {
+				fields = append(fields, fv.GetField())
+			}
+		}
+	}
+	if reason != common.ErrcdDomainProps || len(fields) == 0 {
+		t.Errorf("invalid: got reason %q and field violations %v, want %s and the bad field", reason, fields, common.ErrcdDomainProps)
+	}
+
+	if _, err := env.grpc.AddJobStatus(ctx, &pb.AddJobStatusRequest{JobStatus: samplePb()}); err != nil {
+		t.Fatal(err)
+	}
+	different := samplePb()
+	different.HostId = "batch02"
+	if _, err := env.grpc.AddJobStatus(ctx, &pb.AddJobStatusRequest{JobStatus: different}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("different status with the same key: got %v, want %s", err, codes.AlreadyExists)
+	}
+
+	if _, err := env.grpc.GetByJobId(ctx, &pb.GetByJobIdRequest{JobId: sampleDto.JobId, Options: &pb.QueryOptions{Offset: 5}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("offset without a limit: got %v, want %s", err, codes.InvalidArgument)
+	}
+}
+
+func TestGrpcQueries(t *testing.T) {
+	env := newGrpcEnv(t)
+	ctx := context.Background()
+	for i, runId := range []string{"1", "2", "3"} {
+		js := samplePb()
+		js.RunId = runId
+		js.JobStatusTimestamp = fmt.Sprintf("2023-06-16T0%d:18:33.324Z", i)
+		if _, err := env.grpc.AddJobStatus(ctx, &pb.AddJobStatusRequest{JobStatus: js}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	next := samplePb()
+	next.BusinessDate = "2023-06-16"
+	if _, err := env.grpc.AddJobStatus(ctx, &pb.AddJobStatusRequest{JobStatus: next}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name       string
+		call       func() (*pb.JobStatuses, error)
+		wantRunIds []string
+		wantNext   *int32
+	}{
+		{
+			"by job id",
+			func() (*pb.JobStatuses, error) {
+				return env.grpc.GetByJobId(ctx, &pb.GetByJobIdRequest{JobId: sampleDto.JobId, Options: &pb.QueryOptions{Sort: []string{"BusDt:desc", "JobStTs"}}})
+			},
+			[]string{"1", "1", "2", "3"}, nil,
+		},
+		{
+			"by business date",
+			func() (*pb.JobStatuses, error) {
+				return env.grpc.GetByJobIdBusinessDate(ctx, &pb.GetByJobIdBusinessDateRequest{JobId: sampleDto.JobId, BusinessDate: "2023-06-15", Options: &pb.QueryOptions{Sort: []string{"JobStTs:desc"}}})
+			},
+			[]string{"3", "2", "1"}, nil,
+		},
+		{
+			"full page",
+			func() (*pb.JobStatuses, error) {
+				return env.grpc.GetByJobIdBusinessDate(ctx, &pb.GetByJobIdBusinessDateRequest{JobId: sampleDto.JobId, BusinessDate: "2023-06-15", Options: &pb.QueryOptions{Sort: []string{"JobStTs"}, Limit: 2}})
+			},
+			[]string{"1", "2"}, int32Ptr(2),
+		},
+		{
+			"last page",
+			func() (*pb.JobStatuses, error) {
+				return env.grpc.GetByJobIdBusinessDate(ctx, &pb.GetByJobIdBusinessDateRequest{JobId: sampleDto.JobId, BusinessDate: "2023-06-15", Options: &pb.QueryOptions{Sort: []string{"JobStTs"}, Limit: 2, Offset: 2}})
+			},
+			[]string{"3"}, nil,
+		},
+	} {
+		res, err := tt.call()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var runIds []string
+		for _, js := range res.GetJobStatuses() {
+			runIds = append(runIds, js.GetRunId())
+		}
+		if err := expectEqual(tt.name, runIds, tt.wantRunIds); err != nil {
+			t.Error(err)
+		}
+		if (res.NextOffset == nil) != (tt.wantNext == nil) || (res.NextOffset != nil && *res.NextOffset != *tt.wantNext) {
+			t.Errorf("%s: got next offset %v, want %v", tt.name, res.NextOffset, tt.wantNext)
+		}
+	}
+
+	res, err := env.grpc.GetByJobId(ctx, &pb.GetByJobIdRequest{JobId: sampleDto.JobId, Options: &pb.QueryOptions{Fields: []string{"RunId"}}})
+	if err != nil || len(res.GetJobStatuses()) == 0 || res.GetJobStatuses()[0].GetJobId() != "" || res.GetJobStatuses()[0].GetRunId() == "" {
+		t.Errorf("fields: got %v, %v, want only RunIds", res, err)
+	}
+}
+
+func int32Ptr(n int32) *int32 { return &n }
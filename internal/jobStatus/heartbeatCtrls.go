@@ -0,0 +1,38 @@
+package jobStatus
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// JobHeartbeatsPath is the route path for heartbeats from running jobs.
+const JobHeartbeatsPath = "/job-heartbeats"
+
+type RecordHeartbeatCtrl struct {
+	uc *HeartbeatUC
+}
+
+func NewRecordHeartbeatCtrl(uc *HeartbeatUC) *RecordHeartbeatCtrl {
+	return &RecordHeartbeatCtrl{uc: uc}
+}
+
+// ServeHTTP handles POST of a HeartbeatDto and responds with the run's START status. It's 404 if
+// the run hasn't posted a START.
+func (ctrl *RecordHeartbeatCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var hbDto dto.HeartbeatDto
+	if err := json.NewDecoder(r.Body).Decode(&hbDto); err != nil {
+		writeError(w, r, common.NewCommonError(common.ErrcdJsonDecode, err))
+		return
+	}
+
+	result, err := ctrl.uc.Record(r.Context(), hbDto)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
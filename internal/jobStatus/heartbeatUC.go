@@ -0,0 +1,69 @@
+package jobStatus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+type HeartbeatUC struct {
+	repo    HeartbeatRepo
+	aliases *JobAliasUC
+	now     func() time.Time
+}
+
+// NewHeartbeatUC returns the use case. It uses svc.Aliases, so a job that reports under a legacy
+// JobId finds the START it posted under that JobId.
+func NewHeartbeatUC(repo HeartbeatRepo, svc Services) *HeartbeatUC {
+	return &HeartbeatUC{repo: repo, aliases: svc.Aliases, now: time.Now}
+}
+
+// Record sets the run's START status's HeartbeatTs to hbDto's, or now if it's empty, and returns
+// the START status. Like JobStTs, HeartbeatTs can't be more than MaxFutureSkew after now.
+func (uc *HeartbeatUC) Record(ctx context.Context, hbDto dto.HeartbeatDto) (dto.JobStatusDto, error) {
+	var problems common.FieldErrors
+	add := func(field string, problem string) {
+		if problem != "" {
+			problems = append(problems, common.FieldError{Field: field, Message: problem})
+		}
+	}
+	now := uc.now().UTC()
+	add("JobId", idProblem(hbDto.JobId, MaxJobIdLen))
+	businessDate, err := ParseDate(hbDto.BusinessDate)
+	switch {
+	case len(hbDto.BusinessDate) == 0:
+		add("BusDt", "is required")
+	case err != nil:
+		add("BusDt", fmt.Sprintf("%q is not a date, like 2023-06-15", hbDto.BusinessDate))
+	}
+	add("RunId", idProblem(hbDto.RunId, MaxRunIdLen))
+	at := now
+	if len(hbDto.HeartbeatTimestamp) > 0 {
+		switch ts, err := time.Parse(time.RFC3339Nano, hbDto.HeartbeatTimestamp); {
+		case err != nil:
+			add("HeartbeatTs", fmt.Sprintf("%q is not an RFC 3339 timestamp, like 2023-06-16T00:18:33Z", hbDto.HeartbeatTimestamp))
+		case ts.After(now.Add(MaxFutureSkew)):
+			add("HeartbeatTs", fmt.Sprintf("%q is more than %s after the server's clock", hbDto.HeartbeatTimestamp, MaxFutureSkew))
+		default:
+			at = ts
+		}
+	}
+	if len(problems) > 0 {
+		return dto.JobStatusDto{}, common.NewCommonError(common.ErrcdDomainProps, problems)
+	}
+
+	key := StatusKey{JobId: JobIdType(hbDto.JobId), JobStatusCode: JobStatus_START, BusinessDate: businessDate, RunId: RunIdType(hbDto.RunId)}
+	if uc.aliases != nil {
+		if key.JobId, err = uc.aliases.Resolve(ctx, key.JobId); err != nil {
+			return dto.JobStatusDto{}, err
+		}
+	}
+	js, err := uc.repo.RecordHeartbeat(ctx, key, at.UTC().Truncate(time.Microsecond))
+	if err != nil {
+		return dto.JobStatusDto{}, err
+	}
+	return domainToDto(js), nil
+}
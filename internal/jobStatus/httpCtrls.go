@@ -0,0 +1,284 @@
+package jobStatus
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+type AddJobStatusCtrl struct {
+	uc       *AddJobStatusUC
+	mappings *FieldMappings
+}
+
+// NewAddJobStatusCtrl returns the controller. mappings may be nil.
+func NewAddJobStatusCtrl(uc *AddJobStatusUC, mappings *FieldMappings) *AddJobStatusCtrl {
+	return &AddJobStatusCtrl{uc: uc, mappings: mappings}
+}
+
+// ServeHTTP handles POST of a single JobStatusDto. It responds 201 if the status was added and
+// 200 if the same status was already stored, which is what a retry gets. With an appId query
+// parameter, the application's field mappings apply (see FieldMappings).
+func (ctrl *AddJobStatusCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var jsDto dto.JobStatusDto
+	if err := ctrl.mappings.decode(w, r, &jsDto); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	result, added, err := ctrl.uc.Add(r.Context(), jsDto)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+	}
+	common.WriteJson(w, status, result)
+}
+
+type AddJobStatusBatchCtrl struct {
+	uc       *AddJobStatusUC
+	mappings *FieldMappings
+}
+
+// NewAddJobStatusBatchCtrl returns the controller. mappings may be nil.
+func NewAddJobStatusBatchCtrl(uc *AddJobStatusUC, mappings *FieldMappings) *AddJobStatusBatchCtrl {
+	return &AddJobStatusBatchCtrl{uc: uc, mappings: mappings}
+}
+
+// ServeHTTP handles POST of a JSON array of JobStatusDtos, stored all or nothing. Field mappings
+// apply as they do for AddJobStatusCtrl.
+func (ctrl *AddJobStatusBatchCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var jsDtos []dto.JobStatusDto
+	if err := ctrl.mappings.decode(w, r, &jsDtos); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	result, err := ctrl.uc.AddBatch(r.Context(), jsDtos)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusCreated, result)
+}
+
+type GetJobStatusesCtrl struct {
+	uc       *GetJobStatusesUC
+	streamUC *StreamJobStatusesUC
+}
+
+// NewGetJobStatusesCtrl returns a controller for job status queries. If streamUC is nil,
+// requests for streamed responses get 400.
+func NewGetJobStatusesCtrl(uc *GetJobStatusesUC, streamUC *StreamJobStatusesUC) *GetJobStatusesCtrl {
+	return &GetJobStatusesCtrl{uc: uc, streamUC: streamUC}
+}
+
+// streamFlushEvery is how many rows a streamed response writes between flushes.
+const streamFlushEvery = 500
+
+// ServeHTTP handles GET with query parameters jobId and optional busDt, fields, sort, partial, asOf, and stream.
+// stream=ndjson or stream=array writes rows as they're read instead of building the whole result first.
+// partial=true returns rows that can be read even if some can't; see dto.PartialJobStatusesDto.
+// asOf=<RFC 3339 timestamp> returns only statuses the server had received by then.
+// view=<name> replaces jobId with a saved view's jobs and applications; fields and sort override the view's.
+// <DTO field>[<op>]=<value> filters results, like JobSt[in]=START,FAIL; see parseFilters. Filters can replace
+// jobId if they narrow on JobId or BusDt, like JobId[prefix]=billing-.
+// limit=<n> and optional offset=<n> return one page in a dto.JobStatusPageDto; see QueryParams.Limit.
+// Accept: application/vnd.api+json returns a JSON:API document (see dto.JsonApiJobStatusesDto).
+func (ctrl *GetJobStatusesCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	jsonApi := wantsJsonApi(r)
+	if q.Has("stream") {
+		switch {
+		case q.Has("view"):
+			writeError(w, r, common.NewCommonError(common.ErrcdDomainProps, errors.New("view can't be streamed")))
+		case q.Has("limit"):
+			writeError(w, r, common.NewCommonError(common.ErrcdDomainProps, errors.New("streams can't be paged")))
+		case jsonApi:
+			writeError(w, r, common.NewCommonError(common.ErrcdDomainProps, errors.New("JSON:API responses can't be streamed")))
+		default:
+			ctrl.serveStream(w, r, q.Get("stream"))
+		}
+		return
+	}
+
+	jobId := q.Get("jobId")
+	params := queryParams(q)
+	// JSON:API resources need an id even if the client didn't ask for it
+	if jsonApi && len(params.Fields) > 0 {
+		params.Fields += ",StatusId"
+	}
+
+	var result []dto.JobStatusDto
+	var err error
+	switch {
+	case q.Has("view"):
+		result, err = ctrl.uc.GetByView(r.Context(), q.Get("view"), q.Get("busDt"), params)
+	case !q.Has("jobId") && len(params.Filters) > 0:
+		result, err = ctrl.uc.GetByFilters(r.Context(), params)
+	case q.Has("busDt"):
+		result, err = ctrl.uc.GetByJobIdBusinessDate(r.Context(), jobId, q.Get("busDt"), params)
+	default:
+		result, err = ctrl.uc.GetByJobId(r.Context(), jobId, params)
+	}
+	if err != nil && !(params.AllowPartial && IsPartialResult(err)) {
+		writeError(w, r, err)
+		return
+	}
+
+	if params.AllowPartial && err != nil {
+		log.Printf("%s %s partial result: %v", r.Method, r.URL.Path, err)
+	}
+	var page *dto.PageDto
+	if len(params.Limit) > 0 {
+		page = pageOf(params, len(result)+len(RowErrorsOf(err)))
+		common.SetPage(r.Context(), page)
+	}
+	if jsonApi {
+		writeJsonApiStatuses(w, r, result, page, err)
+		return
+	}
+	if page != nil {
+		common.WriteJson(w, http.StatusOK, dto.JobStatusPageDto{JobStatuses: result, Page: *page, RowErrors: rowErrorsToDto(err)})
+		return
+	}
+	if params.AllowPartial {
+		common.WriteJson(w, http.StatusOK, dto.PartialJobStatusesDto{JobStatuses: result, RowErrors: rowErrorsToDto(err)})
+		return
+	}
+	common.WriteJson(w, http.StatusOK, result)
+}
+
+// RowErrorCountTrailer is the HTTP trailer a streamed partial result uses to report how many rows it skipped.
+const RowErrorCountTrailer = "X-Row-Error-Count"
+
+func (ctrl *GetJobStatusesCtrl) serveStream(w http.ResponseWriter, r *http.Request, mode string) {
+	if ctrl.streamUC == nil {
+		writeError(w, r, common.NewCommonError(common.ErrcdDomainProps, errors.New("streaming is not available")))
+		return
+	}
+	if mode != "ndjson" && mode != "array" {
+		writeError(w, r, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("stream %q must be ndjson or array", mode)))
+		return
+	}
+
+	q := r.URL.Query()
+	jobId := q.Get("jobId")
+	params := queryParams(q)
+
+	// skipped rows are only known at the end, so partial streams report them in a trailer
+	if params.AllowPartial {
+		w.Header().Set("Trailer", RowErrorCountTrailer)
+	}
+
+	stream := common.NewJsonStream(w, mode == "ndjson", streamFlushEvery)
+	write := func(jsDto dto.JobStatusDto) error {
+		return stream.Write(jsDto)
+	}
+
+	var err error
+	switch {
+	case !q.Has("jobId") && len(params.Filters) > 0:
+		err = ctrl.streamUC.StreamByFilters(r.Context(), params, write)
+	case q.Has("busDt"):
+		err = ctrl.streamUC.StreamByJobIdBusinessDate(r.Context(), jobId, q.Get("busDt"), params, write)
+	default:
+		err = ctrl.streamUC.StreamByJobId(r.Context(), jobId, params, write)
+	}
+	if err != nil && !(params.AllowPartial && IsPartialResult(err)) {
+		if !stream.Started() {
+			writeError(w, r, err)
+			return
+		}
+		log.Printf("%s %s stream aborted: %v", r.Method, r.URL.Path, err)
+		stream.Abort(err)
+		return
+	}
+
+	if err != nil {
+		log.Printf("%s %s partial stream: %v", r.Method, r.URL.Path, err)
+	}
+	stream.Close()
+	if params.AllowPartial {
+		w.Header().Set(RowErrorCountTrailer, strconv.Itoa(len(RowErrorsOf(err))))
+	}
+}
+
+func queryParams(q url.Values) QueryParams {
+	return QueryParams{
+		Fields:       q.Get("fields"),
+		Sort:         q.Get("sort"),
+		AllowPartial: q.Get("partial") == "true",
+		Filters:      filterParams(q),
+		AsOf:         q.Get("asOf"),
+		Limit:        q.Get("limit"),
+		Offset:       q.Get("offset"),
+	}
+}
+
+// pageOf describes the page a query returned, given how many rows it read. The use case already
+// checked limit and offset.
+func pageOf(params QueryParams, rowsRead int) *dto.PageDto {
+	limit, _ := strconv.Atoi(params.Limit)
+	offset, _ := strconv.Atoi(params.Offset)
+	page := &dto.PageDto{Limit: limit, Offset: offset}
+	if rowsRead >= limit {
+		next := offset + limit
+		page.NextOffset = &next
+	}
+	return page
+}
+
+// filterParams picks out parameters shaped like filters ("name[op]"). If one is repeated, the
+// first value is used. In a query by filters, busDt is short for BusDt[eq].
+func filterParams(q url.Values) map[string]string {
+	var filters map[string]string
+	for key, values := range q {
+		if !strings.Contains(key, "[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		if filters == nil {
+			filters = map[string]string{}
+		}
+		filters[key] = values[0]
+	}
+	if _, ok := filters["BusDt[eq]"]; len(filters) > 0 && !ok && !q.Has("jobId") && q.Has("busDt") {
+		filters["BusDt[eq]"] = q.Get("busDt")
+	}
+	return filters
+}
+
+func rowErrorsToDto(err error) []dto.RowErrorDto {
+	rowErrs := RowErrorsOf(err)
+	dtos := make([]dto.RowErrorDto, len(rowErrs))
+	for i, re := range rowErrs {
+		dtos[i] = dto.RowErrorDto{Row: re.Row, Error: re.Err.Error()}
+	}
+	return dtos
+}
+
+// errNotSignedIn is why requests that need a signed-in user (see common.UserOf) are 401.
+var errNotSignedIn = errors.New("not signed in")
+
+// writeError writes err as common.WriteError does, or as a JSON:API error document for JSON:API
+// clients.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if !wantsJsonApi(r) {
+		common.WriteError(w, r, err)
+		return
+	}
+	status, code, msg := common.ReportError(r, err)
+	writeJsonApiError(w, status, code, msg)
+}
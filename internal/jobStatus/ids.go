@@ -0,0 +1,132 @@
+package jobStatus
+
+import (
+	"fmt"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// ID length limits match the "JobStatus" column sizes.
+const (
+	MaxApplicationIdLen = 200
+	MaxJobIdLen         = 200
+	MaxRunIdLen         = 50
+	MaxHostIdLen        = 150
+)
+
+type JobIdType string
+
+// RunIdType identifies one run of a job. Clients that don't have one can omit it and get a UUIDv7.
+type RunIdType string
+
+type HostIdType string
+
+// StatusIdType is the server-generated UUIDv7 for one stored status. Unlike the natural key
+// (JobId, JobStatusCode, BusinessDate, RunId), it never changes, so it's safe to hold onto
+// for corrections and audits.
+type StatusIdType string
+
+func GenerateStatusId() StatusIdType {
+	return StatusIdType(common.NewUuidV7())
+}
+
+// Validate requires a UUID in its usual text form, like 0188bdb4-3b1a-7c2e-9f4d-2a6b1c8e5d30,
+// which is what the server generates. Older rows have UUIDv4s, which also pass.
+func (id StatusIdType) Validate() error {
+	s := string(id)
+	if len(s) == 0 {
+		return propsError("StatusId is required")
+	}
+	if len(s) != 36 {
+		return propsError(fmt.Sprintf("StatusId %q is not a UUID", s))
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case i == 8 || i == 13 || i == 18 || i == 23:
+			if c != '-' {
+				return propsError(fmt.Sprintf("StatusId %q is not a UUID", s))
+			}
+		case !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'):
+			return propsError(fmt.Sprintf("StatusId %q is not a UUID", s))
+		}
+	}
+	return nil
+}
+
+// NewJobId returns a validated JobIdType or a CommonError with code ErrcdDomainProps.
+func NewJobId(s string) (JobIdType, error) {
+	if err := JobIdType(s).Validate(); err != nil {
+		return "", err
+	}
+	return JobIdType(s), nil
+}
+
+func (id JobIdType) Validate() error {
+	return validateId("JobId", string(id), MaxJobIdLen)
+}
+
+// NewRunId returns a validated RunIdType or a CommonError with code ErrcdDomainProps.
+func NewRunId(s string) (RunIdType, error) {
+	if err := RunIdType(s).Validate(); err != nil {
+		return "", err
+	}
+	return RunIdType(s), nil
+}
+
+func (id RunIdType) Validate() error {
+	return validateId("RunId", string(id), MaxRunIdLen)
+}
+
+// GenerateRunId returns a UUIDv7, so generated RunIds sort by creation time.
+func GenerateRunId() RunIdType {
+	return RunIdType(common.NewUuidV7())
+}
+
+// NewHostId returns a validated HostIdType or a CommonError with code ErrcdDomainProps.
+func NewHostId(s string) (HostIdType, error) {
+	if err := HostIdType(s).Validate(); err != nil {
+		return "", err
+	}
+	return HostIdType(s), nil
+}
+
+func (id HostIdType) Validate() error {
+	return validateId("HostId", string(id), MaxHostIdLen)
+}
+
+// validateId requires 1 to maxLen characters from isIdChar. IDs end up in URLs, logs, and
+// file names, so spaces, quotes, and control characters aren't allowed.
+func validateId(name string, s string, maxLen int) error {
+	if problem := idProblem(s, maxLen); problem != "" {
+		return propsError(name + " " + problem)
+	}
+	return nil
+}
+
+// idProblem says what validateId would reject s for, like "is required", or "" if nothing.
+func idProblem(s string, maxLen int) string {
+	switch {
+	case len(s) == 0:
+		return "is required"
+	case len(s) > maxLen:
+		return fmt.Sprintf("is longer than %d characters", maxLen)
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIdChar(s[i]) {
+			return fmt.Sprintf("%q has invalid character %q; use letters, digits, and . _ - : / @", s, s[i])
+		}
+	}
+	return ""
+}
+
+func isIdChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	switch c {
+	case '.', '_', '-', ':', '/', '@':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package jobStatus
+
+import (
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// IntegrityPath is the admin route for integrity checks.
+const IntegrityPath = "/admin/integrity"
+
+type GetIntegrityCtrl struct {
+	uc *IntegrityUC
+}
+
+func NewGetIntegrityCtrl(uc *IntegrityUC) *GetIntegrityCtrl {
+	return &GetIntegrityCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameters appId, fromDt, and toDt.
+func (ctrl *GetIntegrityCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	result, err := ctrl.uc.Verify(r.Context(), q.Get("appId"), q.Get("fromDt"), q.Get("toDt"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
@@ -0,0 +1,114 @@
+package jobStatus
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// MaxIntegrityDays bounds one integrity check, which reads every status in the range.
+const MaxIntegrityDays = 31
+
+type IntegrityUC struct {
+	repo      IntegrityRepo
+	aliasRepo JobAliasRepo
+	hasher    IntegrityHasher
+}
+
+// NewIntegrityUC returns the use case. hasher must be the one used at ingestion. aliasRepo may be
+// nil if jobs are never renamed.
+func NewIntegrityUC(repo IntegrityRepo, aliasRepo JobAliasRepo, hasher IntegrityHasher) *IntegrityUC {
+	return &IntegrityUC{repo: repo, aliasRepo: aliasRepo, hasher: hasher}
+}
+
+// Verify recomputes the hash of each of an application's statuses on business dates fromDate
+// through toDate (inclusive) and compares it to the stored one. It returns a report for each
+// business date with statuses, in date order.
+func (uc *IntegrityUC) Verify(ctx context.Context, applicationId string, fromDate string, toDate string) ([]dto.IntegrityReportDto, error) {
+	if len(applicationId) == 0 {
+		return nil, propsError("ApplicationId is required")
+	}
+	from, to, err := parseDateRange(fromDate, toDate, MaxIntegrityDays)
+	if err != nil {
+		return nil, err
+	}
+
+	formerJobIds, err := uc.formerJobIds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []dto.IntegrityReportDto
+	var report *dto.IntegrityReportDto
+	var digest hash.Hash
+	finish := func() {
+		if report != nil {
+			report.Digest = hex.EncodeToString(digest.Sum(nil))
+			reports = append(reports, *report)
+		}
+	}
+
+	err = uc.repo.ForEachWithIntegrityHash(ctx, applicationId, from, to, func(js JobStatus) error {
+		busDt := js.BusinessDate.Format(dto.DateFormat)
+		if report == nil || report.BusinessDate != busDt {
+			finish()
+			report = &dto.IntegrityReportDto{ApplicationId: applicationId, BusinessDate: busDt}
+			digest = sha256.New()
+		}
+		report.RowCount++
+
+		if len(js.IntegrityHash) == 0 {
+			report.UnhashedCount++
+			digest.Write(uc.hasher.HashStatus(js))
+			return nil
+		}
+		if uc.matches(js, formerJobIds[js.JobId]) {
+			report.VerifiedCount++
+		} else {
+			report.MismatchedStatusIds = append(report.MismatchedStatusIds, string(js.StatusId))
+		}
+		// the stored hash, so a tampered row also changes the digest
+		digest.Write(js.IntegrityHash)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	finish()
+	return reports, nil
+}
+
+// matches checks js against its stored hash. Renames rewrite JobId after the hash is stored, so
+// if js doesn't match as is, it's tried under each JobId that was renamed to its current one.
+func (uc *IntegrityUC) matches(js JobStatus, formerJobIds []JobIdType) bool {
+	if hmac.Equal(uc.hasher.HashStatus(js), js.IntegrityHash) {
+		return true
+	}
+	for _, jobId := range formerJobIds {
+		js.JobId = jobId
+		if hmac.Equal(uc.hasher.HashStatus(js), js.IntegrityHash) {
+			return true
+		}
+	}
+	return false
+}
+
+// formerJobIds maps each canonical JobId to its aliases.
+func (uc *IntegrityUC) formerJobIds(ctx context.Context) (map[JobIdType][]JobIdType, error) {
+	result := map[JobIdType][]JobIdType{}
+	if uc.aliasRepo == nil {
+		return result, nil
+	}
+	aliases, err := uc.aliasRepo.ListJobAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range aliases {
+		result[a.CanonicalJobId] = append(result[a.CanonicalJobId], a.AliasJobId)
+	}
+	return result, nil
+}
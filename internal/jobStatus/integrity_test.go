@@ -0,0 +1,271 @@
+package jobStatus_test
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbmemory"
+)
+
+func integrityStatus() jobStatus.JobStatus {
+	return jobStatus.JobStatus{
+		StatusId:           "s1",
+		ApplicationId:      "overdrafts",
+		JobId:              "nightly-load",
+		JobStatusCode:      jobStatus.JobStatus_SUCCEED,
+		JobStatusTimestamp: time.Date(2023, 6, 15, 22, 30, 0, 123456000, time.UTC),
+		BusinessDate:       time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC),
+		RunId:              "run1",
+		HostId:             "host1",
+		ReceivedTimestamp:  time.Date(2023, 6, 15, 22, 30, 1, 0, time.UTC),
+		Links:              []jobStatus.Link{{Kind: jobStatus.LinkLog, Url: "https://logs.example.com/run1"}},
+		Metadata:           jobStatus.Metadata{"exitCode": "0"},
+	}
+}
+
+func TestHashStatusCoversEveryField(t *testing.T) {
+	hasher := jobStatus.Sha256Hasher{}
+	base := hasher.HashStatus(integrityStatus())
+
+	for _, tc := range []struct {
+		name   string
+		change func(*jobStatus.JobStatus)
+	}{
+		{"StatusId", func(js *jobStatus.JobStatus) { js.StatusId = "s2" }},
+		{"ApplicationId", func(js *jobStatus.JobStatus) { js.ApplicationId = "loans" }},
+		{"JobId", func(js *jobStatus.JobStatus) { js.JobId = "nightly-loads" }},
+		{"JobStatusCode", func(js *jobStatus.JobStatus) { js.JobStatusCode = jobStatus.JobStatus_FAIL }},
+		{"JobStatusTimestamp", func(js *jobStatus.JobStatus) { js.JobStatusTimestamp = js.JobStatusTimestamp.Add(time.Microsecond) }},
+		{"BusinessDate", func(js *jobStatus.JobStatus) { js.BusinessDate = js.BusinessDate.AddDate(0, 0, 1) }},
+		{"RunId", func(js *jobStatus.JobStatus) { js.RunId = "run2" }},
+		{"HostId", func(js *jobStatus.JobStatus) { js.HostId = "" }},
+		{"ReportedJobId", func(js *jobStatus.JobStatus) { js.ReportedJobId = "old-load" }},
+		{"ReceivedTimestamp", func(js *jobStatus.JobStatus) { js.ReceivedTimestamp = time.Time{} }},
+		{"Links", func(js *jobStatus.JobStatus) { js.Links = nil }},
+		{"Link Url", func(js *jobStatus.JobStatus) { js.Links[0].Url = "https://logs.example.com/run2" }},
+		{"Metadata", func(js *jobStatus.JobStatus) { js.Metadata = nil }},
+		{"Metadata value", func(js *jobStatus.JobStatus) { js.Metadata["exitCode"] = "1" }},
+		// length prefixes keep fields from running together
+		{"field boundary", func(js *jobStatus.JobStatus) { js.RunId, js.HostId = "run1h", "ost1" }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js := integrityStatus()
+			tc.change(&js)
+			if bytes.Equal(hasher.HashStatus(js), base) {
+				t.Errorf("changing %s didn't change the hash", tc.name)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		name   string
+		change func(*jobStatus.JobStatus)
+	}{
+		{"other time zone", func(js *jobStatus.JobStatus) {
+			js.JobStatusTimestamp = js.JobStatusTimestamp.In(time.FixedZone("EST", -5*3600))
+		}},
+		{"nanoseconds", func(js *jobStatus.JobStatus) { js.JobStatusTimestamp = js.JobStatusTimestamp.Add(999) }},
+		{"heartbeat", func(js *jobStatus.JobStatus) { js.HeartbeatTimestamp = time.Now() }},
+		{"stored hash", func(js *jobStatus.JobStatus) { js.IntegrityHash = []byte("x") }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js := integrityStatus()
+			tc.change(&js)
+			if !bytes.Equal(hasher.HashStatus(js), base) {
+				t.Errorf("%s changed the hash", tc.name)
+			}
+		})
+	}
+
+	// rows hashed before Metadata was added have none, and must still verify
+	js := integrityStatus()
+	js.Metadata = nil
+	withEmpty := integrityStatus()
+	withEmpty.Metadata = jobStatus.Metadata{}
+	if !bytes.Equal(hasher.HashStatus(js), hasher.HashStatus(withEmpty)) {
+		t.Errorf("nil and empty Metadata hash differently")
+	}
+}
+
+func TestHmacSha256Hasher(t *testing.T) {
+	if _, err := jobStatus.NewHmacSha256Hasher([]byte(strings.Repeat("k", jobStatus.MinIntegrityKeyLen-1))); common.ErrorCode(err) != common.ErrcdDomainProps {
+		t.Errorf("short key: got %v, want a %s error", err, common.ErrcdDomainProps)
+	}
+
+	key1, err := jobStatus.NewHmacSha256Hasher([]byte(strings.Repeat("1", jobStatus.MinIntegrityKeyLen)))
+	if err != nil {
+		t.Fatalf("NewHmacSha256Hasher: %v", err)
+	}
+	key2, _ := jobStatus.NewHmacSha256Hasher([]byte(strings.Repeat("2", jobStatus.MinIntegrityKeyLen)))
+	js := integrityStatus()
+
+	if !bytes.Equal(key1.HashStatus(js), key1.HashStatus(js)) {
+		t.Errorf("same key: hashes differ")
+	}
+	if bytes.Equal(key1.HashStatus(js), key2.HashStatus(js)) {
+		t.Errorf("different keys: hashes match")
+	}
+	if bytes.Equal(key1.HashStatus(js), jobStatus.Sha256Hasher{}.HashStatus(js)) {
+		t.Errorf("HMAC hash matches the plain hash")
+	}
+}
+
+func TestIntegrityHasherFromEnv(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		key      string
+		wantType any
+		wantErr  bool
+	}{
+		{"no key", "", jobStatus.Sha256Hasher{}, false},
+		{"key", strings.Repeat("k", jobStatus.MinIntegrityKeyLen), jobStatus.HmacSha256Hasher{}, false},
+		{"short key", "secret", nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			hasher, err := jobStatus.IntegrityHasherFromEnv(func(name string) string {
+				if name == "GOJST_INTEGRITY_KEY" {
+					return tc.key
+				}
+				return ""
+			})
+			switch {
+			case tc.wantErr && err == nil:
+				t.Errorf("got no error, want one")
+			case !tc.wantErr && err != nil:
+				t.Errorf("got %v, want no error", err)
+			case !tc.wantErr && reflect.TypeOf(hasher) != reflect.TypeOf(tc.wantType):
+				t.Errorf("got %T, want %T", hasher, tc.wantType)
+			}
+		})
+	}
+}
+
+// addHashed stores js with the hash hasher gives it, then applies tamper (if any) to the stored
+// copy, as if someone edited the row.
+func addHashed(t *testing.T, repo *dbmemory.RepoMemory, hasher jobStatus.IntegrityHasher, js jobStatus.JobStatus, tamper func(*jobStatus.JobStatus)) {
+	t.Helper()
+	js.IntegrityHash = hasher.HashStatus(js)
+	if tamper != nil {
+		tamper(&js)
+	}
+	if err := repo.Add(context.Background(), js); err != nil {
+		t.Fatalf("Add %s: %v", js.StatusId, err)
+	}
+}
+
+func TestIntegrityVerify(t *testing.T) {
+	ctx := context.Background()
+	hasher := jobStatus.Sha256Hasher{}
+	day1 := integrityStatus()
+	day2 := integrityStatus()
+	day2.StatusId, day2.BusinessDate = "s2", day2.BusinessDate.AddDate(0, 0, 1)
+
+	build := func(tamper func(*jobStatus.JobStatus)) *dbmemory.RepoMemory {
+		repo := dbmemory.NewRepoMemory()
+		addHashed(t, repo, hasher, day1, nil)
+		addHashed(t, repo, hasher, day2, tamper)
+
+		unhashed := integrityStatus()
+		unhashed.StatusId, unhashed.JobId = "s3", "weekly-load"
+		if err := repo.Add(ctx, unhashed); err != nil {
+			t.Fatalf("Add s3: %v", err)
+		}
+
+		// renamed after it was stored, so it's hashed under its old JobId
+		renamed := integrityStatus()
+		renamed.StatusId, renamed.JobId = "s4", "old-load"
+		addHashed(t, repo, hasher, renamed, func(js *jobStatus.JobStatus) { js.JobId = "new-load" })
+		return repo
+	}
+
+	clean := build(nil)
+	if err := clean.PutJobAlias(ctx, jobStatus.JobAlias{AliasJobId: "old-load", CanonicalJobId: "new-load"}); err != nil {
+		t.Fatalf("PutJobAlias: %v", err)
+	}
+	reports, err := jobStatus.NewIntegrityUC(clean, clean, hasher).Verify(ctx, "overdrafts", "2023-06-15", "2023-06-16")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("got %d reports, want 2: %+v", len(reports), reports)
+	}
+	got := []any{reports[0].BusinessDate, reports[0].RowCount, reports[0].VerifiedCount, reports[0].UnhashedCount, len(reports[0].MismatchedStatusIds)}
+	if want := []any{"2023-06-15", 3, 2, 1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("day 1: got date, rows, verified, unhashed, mismatched %v, want %v", got, want)
+	}
+	got = []any{reports[1].BusinessDate, reports[1].RowCount, reports[1].VerifiedCount, len(reports[1].MismatchedStatusIds)}
+	if want := []any{"2023-06-16", 1, 1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("day 2: got date, rows, verified, mismatched %v, want %v", got, want)
+	}
+
+	// without the alias, the renamed status doesn't match
+	reports, err = jobStatus.NewIntegrityUC(clean, nil, hasher).Verify(ctx, "overdrafts", "2023-06-15", "2023-06-15")
+	if err != nil {
+		t.Fatalf("Verify without aliases: %v", err)
+	}
+	if len(reports) != 1 || !reflect.DeepEqual(reports[0].MismatchedStatusIds, []string{"s4"}) {
+		t.Errorf("without aliases: got %+v, want s4 mismatched", reports)
+	}
+
+	// an edited row is reported; the digest is of stored hashes, so it doesn't change
+	cleanReports, _ := jobStatus.NewIntegrityUC(clean, nil, hasher).Verify(ctx, "overdrafts", "2023-06-15", "2023-06-16")
+	edited := build(func(js *jobStatus.JobStatus) { js.JobStatusCode = jobStatus.JobStatus_FAIL })
+	reports, err = jobStatus.NewIntegrityUC(edited, nil, hasher).Verify(ctx, "overdrafts", "2023-06-15", "2023-06-16")
+	if err != nil {
+		t.Fatalf("Verify edited: %v", err)
+	}
+	if !reflect.DeepEqual(reports[1].MismatchedStatusIds, []string{"s2"}) {
+		t.Errorf("edited: got mismatched %v, want [s2]", reports[1].MismatchedStatusIds)
+	}
+	if reports[1].Digest != cleanReports[1].Digest {
+		t.Errorf("edited: got digest %s, want %s", reports[1].Digest, cleanReports[1].Digest)
+	}
+
+	// a row edited and rehashed verifies, but changes its date's digest and not the other's
+	rehashed := build(func(js *jobStatus.JobStatus) {
+		js.JobStatusCode = jobStatus.JobStatus_FAIL
+		js.IntegrityHash = hasher.HashStatus(*js)
+	})
+	reports, err = jobStatus.NewIntegrityUC(rehashed, nil, hasher).Verify(ctx, "overdrafts", "2023-06-15", "2023-06-16")
+	if err != nil {
+		t.Fatalf("Verify rehashed: %v", err)
+	}
+	if len(reports[1].MismatchedStatusIds) != 0 {
+		t.Errorf("rehashed: got mismatched %v, want none", reports[1].MismatchedStatusIds)
+	}
+	if reports[0].Digest != cleanReports[0].Digest {
+		t.Errorf("rehashed: untouched date's digest changed")
+	}
+	if reports[1].Digest == cleanReports[1].Digest {
+		t.Errorf("rehashed: digest didn't change")
+	}
+}
+
+func TestIntegrityVerifyProps(t *testing.T) {
+	repo := dbmemory.NewRepoMemory()
+	uc := jobStatus.NewIntegrityUC(repo, repo, jobStatus.Sha256Hasher{})
+	for _, tc := range []struct {
+		name          string
+		applicationId string
+		fromDate      string
+		toDate        string
+	}{
+		{"no application", "", "2023-06-15", "2023-06-15"},
+		{"bad date", "overdrafts", "06/15/2023", "2023-06-15"},
+		{"backward range", "overdrafts", "2023-06-15", "2023-06-14"},
+		{"range too long", "overdrafts", "2023-06-01", "2023-07-15"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := uc.Verify(context.Background(), tc.applicationId, tc.fromDate, tc.toDate)
+			if common.ErrorCode(err) != common.ErrcdDomainProps {
+				t.Errorf("got %v, want a %s error", err, common.ErrcdDomainProps)
+			}
+		})
+	}
+}
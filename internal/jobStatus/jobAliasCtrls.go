@@ -0,0 +1,67 @@
+package jobStatus
+
+import (
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// JobAliasesPath is the admin route for job aliases.
+const JobAliasesPath = "/admin/job-aliases"
+
+type GetJobAliasesCtrl struct {
+	uc *JobAliasUC
+}
+
+func NewGetJobAliasesCtrl(uc *JobAliasUC) *GetJobAliasesCtrl {
+	return &GetJobAliasesCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET and lists every alias.
+func (ctrl *GetJobAliasesCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result, err := ctrl.uc.List(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
+
+type PutJobAliasCtrl struct {
+	uc *JobAliasUC
+}
+
+func NewPutJobAliasCtrl(uc *JobAliasUC) *PutJobAliasCtrl {
+	return &PutJobAliasCtrl{uc: uc}
+}
+
+// ServeHTTP handles PUT with query parameters alias and canonical.
+func (ctrl *PutJobAliasCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	result, err := ctrl.uc.Put(r.Context(), q.Get("alias"), q.Get("canonical"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
+
+type DeleteJobAliasCtrl struct {
+	uc *JobAliasUC
+}
+
+func NewDeleteJobAliasCtrl(uc *JobAliasUC) *DeleteJobAliasCtrl {
+	return &DeleteJobAliasCtrl{uc: uc}
+}
+
+// ServeHTTP handles DELETE with query parameter alias.
+func (ctrl *DeleteJobAliasCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := ctrl.uc.Delete(r.Context(), r.URL.Query().Get("alias")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,170 @@
+package jobStatus
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// DefaultAliasRefresh is how long JobAliasUC uses its cached aliases before reloading them,
+// which is how long other instances take to see an alias change if they don't hear about it
+// (see PublishChanges).
+const DefaultAliasRefresh = time.Minute
+
+// JobAliasUC manages aliases and maps reported JobIds to canonical ones at ingestion. Aliases
+// are read on every add, so they're cached and reloaded every refresh; changes made through
+// this UC update the cache right away.
+type JobAliasUC struct {
+	repo    JobAliasRepo
+	refresh time.Duration
+	publish func()
+
+	mu       sync.Mutex
+	aliases  map[JobIdType]JobAlias
+	loadedAt time.Time
+}
+
+func NewJobAliasUC(repo JobAliasRepo, refresh time.Duration) *JobAliasUC {
+	return &JobAliasUC{repo: repo, refresh: refresh}
+}
+
+// Resolve returns the canonical JobId for jobId, which is jobId itself if it isn't an alias.
+// If aliases can't be reloaded, the last loaded ones are used; if they were never loaded,
+// the error is returned so statuses aren't stored under a legacy JobId.
+func (uc *JobAliasUC) Resolve(ctx context.Context, jobId JobIdType) (JobIdType, error) {
+	aliases, err := uc.current(ctx)
+	if err != nil {
+		return "", err
+	}
+	if alias, ok := aliases[jobId]; ok {
+		return alias.CanonicalJobId, nil
+	}
+	return jobId, nil
+}
+
+// List returns every alias.
+func (uc *JobAliasUC) List(ctx context.Context) ([]dto.JobAliasDto, error) {
+	aliases, err := uc.repo.ListJobAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dto.JobAliasDto, len(aliases))
+	for i, alias := range aliases {
+		result[i] = jobAliasToDto(alias)
+	}
+	return result, nil
+}
+
+// Put makes alias an alias of canonical. Aliases don't chain: canonical can't be an alias,
+// and alias can't be the canonical JobId of other aliases. Put doesn't move statuses already
+// stored under alias; use JobRenameUC for that.
+func (uc *JobAliasUC) Put(ctx context.Context, alias string, canonical string) (dto.JobAliasDto, error) {
+	aliasId, err := NewJobId(alias)
+	if err != nil {
+		return dto.JobAliasDto{}, err
+	}
+	canonicalId, err := NewJobId(canonical)
+	if err != nil {
+		return dto.JobAliasDto{}, err
+	}
+	if aliasId == canonicalId {
+		return dto.JobAliasDto{}, propsError("a job can't be an alias of itself")
+	}
+
+	aliases, err := uc.repo.ListJobAliases(ctx)
+	if err != nil {
+		return dto.JobAliasDto{}, err
+	}
+	for _, a := range aliases {
+		if a.AliasJobId == canonicalId {
+			return dto.JobAliasDto{}, propsError(fmt.Sprintf("%q is an alias of %q; use that instead", canonical, a.CanonicalJobId))
+		}
+		if a.CanonicalJobId == aliasId {
+			return dto.JobAliasDto{}, propsError(fmt.Sprintf("%q has aliases of its own, like %q; rename it instead", alias, a.AliasJobId))
+		}
+	}
+
+	ja := JobAlias{AliasJobId: aliasId, CanonicalJobId: canonicalId, CreatedTs: time.Now().UTC()}
+	if err := uc.repo.PutJobAlias(ctx, ja); err != nil {
+		return dto.JobAliasDto{}, err
+	}
+	uc.changed()
+	return jobAliasToDto(ja), nil
+}
+
+// Delete removes an alias. Later statuses sent with that JobId are stored under it again.
+func (uc *JobAliasUC) Delete(ctx context.Context, alias string) error {
+	found, err := uc.repo.DeleteJobAlias(ctx, JobIdType(alias))
+	if err != nil {
+		return err
+	}
+	if !found {
+		return common.NewCommonError(common.ErrcdNotFound, fmt.Errorf("alias %q does not exist", alias))
+	}
+	uc.changed()
+	return nil
+}
+
+// PublishChanges makes the UC call publish after it changes aliases, so other instances can
+// Invalidate their caches instead of waiting up to refresh.
+func (uc *JobAliasUC) PublishChanges(publish func()) {
+	uc.publish = publish
+}
+
+// Invalidate makes the next Resolve reload aliases. Call it when another instance changed them.
+func (uc *JobAliasUC) Invalidate() {
+	uc.mu.Lock()
+	uc.loadedAt = time.Time{}
+	uc.mu.Unlock()
+}
+
+// changed invalidates this instance's aliases and tells the others. JobRenameUC calls it,
+// because renames add aliases.
+func (uc *JobAliasUC) changed() {
+	uc.Invalidate()
+	if uc.publish != nil {
+		uc.publish()
+	}
+}
+
+func (uc *JobAliasUC) current(ctx context.Context) (map[JobIdType]JobAlias, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if time.Since(uc.loadedAt) < uc.refresh {
+		return uc.aliases, nil
+	}
+
+	list, err := uc.repo.ListJobAliases(ctx)
+	if err != nil {
+		// a caller that gave up isn't a repo that's down, so the next caller tries again
+		if uc.aliases == nil || ctx.Err() != nil {
+			return nil, err
+		}
+		log.Printf("job aliases: reload failed, using aliases from %s: %v", uc.loadedAt.Format(time.RFC3339), err)
+		// don't retry on every add while the repo is down
+		uc.loadedAt = time.Now()
+		return uc.aliases, nil
+	}
+
+	aliases := make(map[JobIdType]JobAlias, len(list))
+	for _, alias := range list {
+		aliases[alias.AliasJobId] = alias
+	}
+	uc.aliases = aliases
+	uc.loadedAt = time.Now()
+	return aliases, nil
+}
+
+func jobAliasToDto(alias JobAlias) dto.JobAliasDto {
+	return dto.JobAliasDto{
+		AliasJobId:       string(alias.AliasJobId),
+		CanonicalJobId:   string(alias.CanonicalJobId),
+		CreatedTimestamp: alias.CreatedTs.Format(time.RFC3339Nano),
+	}
+}
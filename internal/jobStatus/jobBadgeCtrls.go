@@ -0,0 +1,85 @@
+package jobStatus
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// JobBadgePath is the route for embeddable job status badges.
+const JobBadgePath = "/job-badge"
+
+// BadgeMaxAgeSeconds is how long caches (and image proxies like GitHub's) may keep a badge.
+// Statuses change a few times a day, so a minute is fresh enough and keeps page views off the DB.
+const BadgeMaxAgeSeconds = 60
+
+// badgeColors are shields.io's colors for each board state.
+var badgeColors = map[string]string{
+	dto.BoardStateNotStarted: "#9f9f9f",
+	dto.BoardStateRunning:    "#007ec6",
+	dto.BoardStateSucceeded:  "#4c1",
+	dto.BoardStateFailed:     "#e05d44",
+}
+
+type GetJobBadgeCtrl struct {
+	uc *JobBadgeUC
+}
+
+func NewGetJobBadgeCtrl(uc *JobBadgeUC) *GetJobBadgeCtrl {
+	return &GetJobBadgeCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameters jobId and optional busDt. The response is an SVG
+// labeled with the JobId and showing its state, with an ETag so unchanged badges are 304.
+func (ctrl *GetJobBadgeCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	state, err := ctrl.uc.State(r.Context(), q.Get("jobId"), q.Get("busDt"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	svg := renderBadge(q.Get("jobId"), strings.ToLower(strings.ReplaceAll(state, "_", " ")), badgeColors[state])
+	sum := sha256.Sum256(svg)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", BadgeMaxAgeSeconds))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(svg)
+}
+
+// badgeCharWidth approximates Verdana 11px, which is what badges are drawn in, so text widths
+// don't need font metrics.
+const badgeCharWidth = 7
+
+// renderBadge draws a flat shields.io-style badge: label on grey, message on color.
+func renderBadge(label string, message string, color string) []byte {
+	labelWidth := utf8.RuneCountInString(label)*badgeCharWidth + 10
+	messageWidth := utf8.RuneCountInString(message)*badgeCharWidth + 10
+	width := labelWidth + messageWidth
+
+	var l, m strings.Builder
+	xml.EscapeText(&l, []byte(label))
+	xml.EscapeText(&m, []byte(message))
+
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">`+
+		`<title>%[4]s: %[5]s</title>`+
+		`<rect width="%[2]d" height="20" fill="#555"/>`+
+		`<rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[7]d" y="14">%[4]s</text><text x="%[8]d" y="14">%[5]s</text></g></svg>`,
+		width, labelWidth, messageWidth, l.String(), m.String(), color, labelWidth/2, labelWidth+messageWidth/2))
+}
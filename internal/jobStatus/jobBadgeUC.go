@@ -0,0 +1,41 @@
+package jobStatus
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// JobBadgeUC answers the one question a badge shows: what state is this job in today?
+type JobBadgeUC struct {
+	board BoardRepo
+}
+
+func NewJobBadgeUC(board BoardRepo) *JobBadgeUC {
+	return &JobBadgeUC{board: board}
+}
+
+// State returns the job's board state (a dto.BoardState) on businessDate, from its latest status
+// in any application. An empty businessDate means today, UTC.
+func (uc *JobBadgeUC) State(ctx context.Context, jobId string, businessDate string) (string, error) {
+	id, err := NewJobId(jobId)
+	if err != nil {
+		return "", err
+	}
+	busDt := TruncateToDate(time.Now().UTC())
+	if len(businessDate) > 0 {
+		if busDt, err = parseDateProp("BusinessDate", businessDate); err != nil {
+			return "", err
+		}
+	}
+
+	latest, err := uc.board.GetLatestByJobIds(ctx, []JobIdType{id}, busDt, time.Time{})
+	if err != nil {
+		return "", err
+	}
+	if len(latest) == 0 {
+		return dto.BoardStateNotStarted, nil
+	}
+	return boardState(latest[0].JobStatusCode), nil
+}
@@ -0,0 +1,31 @@
+package jobStatus
+
+import (
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// JobRenamePath is the admin route for renaming and merging jobs.
+const JobRenamePath = "/admin/job-renames"
+
+type JobRenameCtrl struct {
+	uc *JobRenameUC
+}
+
+func NewJobRenameCtrl(uc *JobRenameUC) *JobRenameCtrl {
+	return &JobRenameCtrl{uc: uc}
+}
+
+// ServeHTTP handles POST with query parameters from and to.
+func (ctrl *JobRenameCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	result, err := ctrl.uc.Rename(r.Context(), q.Get("from"), q.Get("to"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
@@ -0,0 +1,95 @@
+package jobStatus
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// JobAlias says statuses reported as AliasJobId belong to CanonicalJobId. Renames create them.
+type JobAlias struct {
+	AliasJobId     JobIdType
+	CanonicalJobId JobIdType
+	CreatedTs      time.Time
+}
+
+// JobRenameResult counts what RenameJob changed.
+type JobRenameResult struct {
+	StatusesMoved  int64
+	ViewsUpdated   int64
+	AliasesUpdated int64
+}
+
+type JobRenameUC struct {
+	repo    JobRenameRepo
+	aliases *JobAliasUC
+}
+
+// NewJobRenameUC returns the rename use case. If aliases isn't nil, its cache is reset after
+// each rename so the new alias applies to the next status.
+func NewJobRenameUC(repo JobRenameRepo, aliases *JobAliasUC) *JobRenameUC {
+	return &JobRenameUC{repo: repo, aliases: aliases}
+}
+
+// Rename moves from's history to to. If to already has statuses, the histories are merged.
+// Afterward, from is an alias of to.
+func (uc *JobRenameUC) Rename(ctx context.Context, from string, to string) (dto.JobRenameResultDto, error) {
+	fromId, err := NewJobId(from)
+	if err != nil {
+		return dto.JobRenameResultDto{}, err
+	}
+	toId, err := NewJobId(to)
+	if err != nil {
+		return dto.JobRenameResultDto{}, err
+	}
+	if fromId == toId {
+		return dto.JobRenameResultDto{}, propsError("can't rename a job to itself")
+	}
+
+	result, err := uc.repo.RenameJob(ctx, fromId, toId, time.Now().UTC())
+	if err != nil {
+		return dto.JobRenameResultDto{}, err
+	}
+	if uc.aliases != nil {
+		uc.aliases.changed()
+	}
+	return dto.JobRenameResultDto{
+		FromJobId:      from,
+		ToJobId:        to,
+		StatusesMoved:  result.StatusesMoved,
+		ViewsUpdated:   result.ViewsUpdated,
+		AliasesUpdated: result.AliasesUpdated,
+	}, nil
+}
+
+// RenameJobIds returns jobIds with from replaced by to, without duplicating to. changed is
+// false if from isn't in jobIds. Repos use it to update saved views.
+func RenameJobIds(jobIds []JobIdType, from JobIdType, to JobIdType) (renamed []JobIdType, changed bool) {
+	hasTo := false
+	for _, jobId := range jobIds {
+		if jobId == from {
+			changed = true
+		}
+		if jobId == to {
+			hasTo = true
+		}
+	}
+	if !changed {
+		return jobIds, false
+	}
+
+	renamed = make([]JobIdType, 0, len(jobIds))
+	for _, jobId := range jobIds {
+		switch {
+		case jobId == from && hasTo:
+			continue
+		case jobId == from:
+			renamed = append(renamed, to)
+			hasTo = true
+		default:
+			renamed = append(renamed, jobId)
+		}
+	}
+	return renamed, true
+}
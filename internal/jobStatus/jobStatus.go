@@ -0,0 +1,163 @@
+package jobStatus
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+type JobStatusCodeType string
+
+const (
+	JobStatus_START   JobStatusCodeType = "START"
+	JobStatus_SUCCEED JobStatusCodeType = "SUCCEED"
+	JobStatus_FAIL    JobStatusCodeType = "FAIL"
+)
+
+var validJobStatusCodes = map[JobStatusCodeType]bool{
+	JobStatus_START:   true,
+	JobStatus_SUCCEED: true,
+	JobStatus_FAIL:    true,
+}
+
+func (code JobStatusCodeType) IsValid() bool {
+	return validJobStatusCodes[code]
+}
+
+// JobStatus is one status report for one run of a job.
+// NewJobStatus requires every field, but rows stored before RunId and HostId were required
+// may not have them. An empty RunId or HostId means "not reported"; see HasRunId and HasHostId.
+type JobStatus struct {
+	StatusId           StatusIdType
+	ApplicationId      string
+	JobId              JobIdType
+	JobStatusCode      JobStatusCodeType
+	JobStatusTimestamp time.Time
+	BusinessDate       time.Time
+	RunId              RunIdType
+	HostId             HostIdType
+	// ReportedJobId is the JobId the job sent when a JobAlias mapped it to JobId; empty otherwise.
+	ReportedJobId JobIdType
+	// ReceivedTimestamp is when the server accepted the status. It's zero for rows stored
+	// before it was recorded; see KnownAt.
+	ReceivedTimestamp time.Time
+	// Links are URLs the job attached, like its log. They're optional.
+	Links []Link
+	// Metadata are name/value pairs the job attached, like its exit code. They're optional.
+	Metadata Metadata
+	// HeartbeatTimestamp is when the run last sent a heartbeat, on its START status only. It's
+	// zero if the run doesn't send heartbeats. Only HeartbeatRepo sets it, and it isn't part
+	// of the report, so it isn't compared or hashed.
+	HeartbeatTimestamp time.Time
+	// IntegrityHash is the IntegrityHasher's hash of the other fields, set at ingestion. It's
+	// empty for rows stored before hashing. Only IntegrityRepo reads it back.
+	IntegrityHash []byte
+}
+
+// StatusKey is a status's natural key. The database allows one status per key.
+type StatusKey struct {
+	JobId         JobIdType
+	JobStatusCode JobStatusCodeType
+	BusinessDate  time.Time
+	RunId         RunIdType
+}
+
+// NewJobStatus validates its arguments and returns a JobStatus with a new StatusId and a
+// ReceivedTimestamp of now or a CommonError with code ErrcdDomainProps.
+// IDs are checked with their Validate methods, so callers can convert strings directly.
+func NewJobStatus(applicationId string, jobId JobIdType, jobStatusCode string, jobStatusTimestamp time.Time, businessDate time.Time, runId RunIdType, hostId HostIdType) (JobStatus, error) {
+	if len(applicationId) == 0 {
+		return JobStatus{}, propsError("ApplicationId is required")
+	}
+	if err := jobId.Validate(); err != nil {
+		return JobStatus{}, err
+	}
+
+	switch {
+	case !JobStatusCodeType(jobStatusCode).IsValid():
+		return JobStatus{}, propsError(fmt.Sprintf("JobStatusCode %q is not valid", jobStatusCode))
+	case jobStatusTimestamp.IsZero():
+		return JobStatus{}, propsError("JobStatusTimestamp is required")
+	case businessDate.IsZero():
+		return JobStatus{}, propsError("BusinessDate is required")
+	}
+	if err := runId.Validate(); err != nil {
+		return JobStatus{}, err
+	}
+	if err := hostId.Validate(); err != nil {
+		return JobStatus{}, err
+	}
+
+	return JobStatus{
+		StatusId:           GenerateStatusId(),
+		ApplicationId:      applicationId,
+		JobId:              jobId,
+		JobStatusCode:      JobStatusCodeType(jobStatusCode),
+		JobStatusTimestamp: jobStatusTimestamp,
+		BusinessDate:       TruncateToDate(businessDate),
+		RunId:              runId,
+		HostId:             hostId,
+		// Postgres keeps microseconds, so truncate to return what queries will read back
+		ReceivedTimestamp: time.Now().UTC().Truncate(time.Microsecond),
+	}, nil
+}
+
+func (js JobStatus) Key() StatusKey {
+	return StatusKey{JobId: js.JobId, JobStatusCode: js.JobStatusCode, BusinessDate: js.BusinessDate, RunId: js.RunId}
+}
+
+// HasRunId is false if the reporting job didn't send a RunId.
+func (js JobStatus) HasRunId() bool {
+	return len(js.RunId) > 0
+}
+
+// HasHostId is false if the reporting job didn't send a HostId.
+func (js JobStatus) HasHostId() bool {
+	return len(js.HostId) > 0
+}
+
+// KnownAt is true if the server had received js by asOf. A zero asOf means now. Rows without a
+// ReceivedTimestamp are taken to have arrived at their JobStatusTimestamp.
+func (js JobStatus) KnownAt(asOf time.Time) bool {
+	return asOf.IsZero() || !js.receivedOrReported().After(asOf)
+}
+
+// SameReport is true if other reports what js does: the same run's status, at the same time,
+// from the same host, with the same links and metadata. StatusId, ReceivedTimestamp,
+// HeartbeatTimestamp, and IntegrityHash are set by the server, so they aren't compared. Timestamps are compared to the microsecond, which
+// is what the databases keep.
+func (js JobStatus) SameReport(other JobStatus) bool {
+	if js.ApplicationId != other.ApplicationId || js.JobId != other.JobId || js.JobStatusCode != other.JobStatusCode ||
+		!js.BusinessDate.Equal(other.BusinessDate) || js.RunId != other.RunId || js.HostId != other.HostId ||
+		js.ReportedJobId != other.ReportedJobId ||
+		!js.JobStatusTimestamp.Truncate(time.Microsecond).Equal(other.JobStatusTimestamp.Truncate(time.Microsecond)) ||
+		len(js.Links) != len(other.Links) || !js.Metadata.Equal(other.Metadata) {
+		return false
+	}
+	for i := range js.Links {
+		if js.Links[i] != other.Links[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// receivedOrReported is ReceivedTimestamp, or JobStatusTimestamp for rows stored without one.
+func (js JobStatus) receivedOrReported() time.Time {
+	if js.ReceivedTimestamp.IsZero() {
+		return js.JobStatusTimestamp
+	}
+	return js.ReceivedTimestamp
+}
+
+// TruncateToDate returns midnight UTC on t's calendar date so dates compare equal regardless of source.
+func TruncateToDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func propsError(msg string) error {
+	return common.NewCommonError(common.ErrcdDomainProps, errors.New(msg))
+}
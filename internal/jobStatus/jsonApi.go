@@ -0,0 +1,76 @@
+package jobStatus
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// wantsJsonApi is true if the request's Accept header lists dto.JsonApiMediaType. Media type
+// parameters are ignored.
+func wantsJsonApi(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			mediaType, _, _ = strings.Cut(mediaType, ";")
+			if strings.TrimSpace(mediaType) == dto.JsonApiMediaType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeJsonApiStatuses writes query results as a JSON:API document. Row errors from a partial
+// result go in meta. If page isn't nil, links has first, prev, and next. Links are relative so
+// they work behind a gateway that rewrites the host.
+func writeJsonApiStatuses(w http.ResponseWriter, r *http.Request, jss []dto.JobStatusDto, page *dto.PageDto, partialErr error) {
+	doc := dto.JsonApiJobStatusesDto{
+		Data:  make([]dto.JsonApiJobStatusDto, len(jss)),
+		Links: dto.JsonApiLinksDto{Self: r.URL.RequestURI()},
+	}
+	if page != nil {
+		doc.Links.First = pageLink(r, 0)
+		if page.Offset > 0 {
+			prev := page.Offset - page.Limit
+			if prev < 0 {
+				prev = 0
+			}
+			doc.Links.Prev = pageLink(r, prev)
+		}
+		if page.NextOffset != nil {
+			doc.Links.Next = pageLink(r, *page.NextOffset)
+		}
+	}
+	for i, jsDto := range jss {
+		resource := dto.JsonApiJobStatusDto{Type: dto.JsonApiJobStatusType, Id: jsDto.StatusId, Attributes: jsDto}
+		resource.Attributes.StatusId = ""
+		if len(jsDto.JobId) > 0 {
+			resource.Relationships = map[string]dto.JsonApiRelationshipDto{
+				"job": {Links: dto.JsonApiLinksDto{Related: JobStatusesPath + "?" + url.Values{"jobId": {jsDto.JobId}}.Encode()}},
+			}
+		}
+		doc.Data[i] = resource
+	}
+	if rowErrs := rowErrorsToDto(partialErr); len(rowErrs) > 0 {
+		doc.Meta = &dto.JsonApiMetaDto{RowErrors: rowErrs}
+	}
+	common.WriteJsonAs(w, dto.JsonApiMediaType, http.StatusOK, doc)
+}
+
+// pageLink is the request's URL with offset replaced.
+func pageLink(r *http.Request, offset int) string {
+	q := r.URL.Query()
+	q.Set("offset", strconv.Itoa(offset))
+	return r.URL.Path + "?" + q.Encode()
+}
+
+// writeJsonApiError writes the error document writeError uses for JSON:API clients.
+func writeJsonApiError(w http.ResponseWriter, status int, code string, detail string) {
+	common.WriteJsonAs(w, dto.JsonApiMediaType, status, dto.JsonApiErrorsDto{
+		Errors: []dto.JsonApiErrorDto{{Status: strconv.Itoa(status), Code: code, Detail: detail}},
+	})
+}
@@ -0,0 +1,36 @@
+package jobStatus
+
+import (
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// LatestJobStatusesPath is the route for a job's current state on each business date.
+const LatestJobStatusesPath = "/job-statuses/latest"
+
+type GetLatestJobStatusesCtrl struct {
+	uc *LatestJobStatusUC
+}
+
+func NewGetLatestJobStatusesCtrl(uc *LatestJobStatusUC) *GetLatestJobStatusesCtrl {
+	return &GetLatestJobStatusesCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameters jobId and optional fromDt, toDt, and asOf. busDt
+// is accepted for one date, like on /job-statuses.
+func (ctrl *GetLatestJobStatusesCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	fromDt, toDt := q.Get("fromDt"), q.Get("toDt")
+	if busDt := q.Get("busDt"); busDt != "" {
+		fromDt, toDt = busDt, busDt
+	}
+	result, err := ctrl.uc.GetLatestByJobId(r.Context(), q.Get("jobId"), fromDt, toDt, q.Get("asOf"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
@@ -0,0 +1,62 @@
+package jobStatus
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// MaxLatestDays bounds the business dates one request for a job's latest statuses covers.
+const MaxLatestDays = 93
+
+// LatestJobStatusUC returns a job's current state for dashboards, which want where each run
+// ended up and not how it got there.
+type LatestJobStatusUC struct {
+	board BoardRepo
+}
+
+func NewLatestJobStatusUC(board BoardRepo) *LatestJobStatusUC {
+	return &LatestJobStatusUC{board: board}
+}
+
+// GetLatestByJobId returns the job's most recent status for each business date from fromDate
+// through toDate, newest date first. Dates the job has no status for are left out. An empty
+// fromDate and toDate mean today, UTC; an empty toDate alone means fromDate.
+// If asOf (RFC 3339) is set, the result is what it was then, from the statuses received by that
+// time, and empty dates mean asOf's date.
+func (uc *LatestJobStatusUC) GetLatestByJobId(ctx context.Context, jobId string, fromDate string, toDate string, asOfTs string) ([]dto.JobStatusDto, error) {
+	id, err := NewJobId(jobId)
+	if err != nil {
+		return nil, err
+	}
+	asOf, err := parseAsOf(asOfTs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fromDate) == 0 && len(toDate) == 0 {
+		now := time.Now().UTC()
+		if !asOf.IsZero() {
+			now = asOf.UTC()
+		}
+		fromDate = TruncateToDate(now).Format(dto.DateFormat)
+	}
+	if len(toDate) == 0 {
+		toDate = fromDate
+	}
+	from, to, err := parseDateRange(fromDate, toDate, MaxLatestDays)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := uc.board.GetLatestByJobId(ctx, id, from, to, asOf)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]dto.JobStatusDto, len(latest))
+	for i, js := range latest {
+		dtos[i] = domainToDto(js)
+	}
+	return dtos, nil
+}
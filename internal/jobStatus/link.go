@@ -0,0 +1,73 @@
+package jobStatus
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// Limits on links, so a status can't carry an unbounded payload.
+const (
+	MaxLinksPerStatus = 10
+	MaxLinkUrlLen     = 2000
+)
+
+// LinkKind says what a link points to.
+type LinkKind string
+
+const (
+	LinkLog      LinkKind = "log"
+	LinkArtifact LinkKind = "artifact"
+	LinkTicket   LinkKind = "ticket"
+)
+
+var validLinkKinds = map[LinkKind]bool{
+	LinkLog:      true,
+	LinkArtifact: true,
+	LinkTicket:   true,
+}
+
+// Link is a URL a job attaches to a status, like where its log is, so on-call can go straight
+// from the status to it. A run's links are the links on all of its statuses.
+type Link struct {
+	Kind LinkKind
+	Url  string
+}
+
+// Validate returns a props CommonError unless Kind is known and Url is an absolute http or https URL.
+func (l Link) Validate() error {
+	if problems := l.problems(); len(problems) > 0 {
+		return propsError("link " + problems[0].Field + " " + problems[0].Message)
+	}
+	return nil
+}
+
+// problems lists what Validate would reject l for, by field.
+func (l Link) problems() common.FieldErrors {
+	var problems common.FieldErrors
+	if !validLinkKinds[l.Kind] {
+		problems = append(problems, common.FieldError{Field: "Kind", Message: fmt.Sprintf("%q is not log, artifact, or ticket", l.Kind)})
+	}
+	if len(l.Url) > MaxLinkUrlLen {
+		return append(problems, common.FieldError{Field: "Url", Message: fmt.Sprintf("is longer than %d characters", MaxLinkUrlLen)})
+	}
+	u, err := url.Parse(l.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, common.FieldError{Field: "Url", Message: fmt.Sprintf("%q is not an absolute http or https URL", l.Url)})
+	}
+	return problems
+}
+
+// validateLinks checks each link and the number of links.
+func validateLinks(links []Link) error {
+	if len(links) > MaxLinksPerStatus {
+		return propsError(fmt.Sprintf("a status can have at most %d links", MaxLinksPerStatus))
+	}
+	for _, l := range links {
+		if err := l.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package jobStatus
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// Limits on metadata, so a status can't carry an unbounded payload.
+const (
+	MaxMetadataEntries  = 20
+	MaxMetadataKeyLen   = 64
+	MaxMetadataValueLen = 500
+)
+
+// Metadata are name/value pairs a job attaches to a status, like its exit code and how long it
+// took; goslo-run sets exitCode and durationMs on SUCCEED and FAIL. Keys follow the ID rules, so
+// they're safe in URLs and logs; values are any text. They're optional.
+type Metadata map[string]string
+
+// Keys returns m's keys in order, so anything that writes m out (hashes, logs) is repeatable.
+func (m Metadata) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Equal is true if m and other have the same pairs. nil and empty are equal.
+func (m Metadata) Equal(other Metadata) bool {
+	if len(m) != len(other) {
+		return false
+	}
+	for k, v := range m {
+		if ov, ok := other[k]; !ok || ov != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a copy of m, or nil if m is empty, so repos don't share maps with callers.
+func (m Metadata) Clone() Metadata {
+	if len(m) == 0 {
+		return nil
+	}
+	c := make(Metadata, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// problems lists what validateMetadata would reject m for, by field, in key order.
+func (m Metadata) problems() common.FieldErrors {
+	var problems common.FieldErrors
+	if len(m) > MaxMetadataEntries {
+		problems = append(problems, common.FieldError{Field: "Metadata", Message: fmt.Sprintf("has %d entries; the limit is %d", len(m), MaxMetadataEntries)})
+	}
+	for _, k := range m.Keys() {
+		if problem := idProblem(k, MaxMetadataKeyLen); problem != "" {
+			problems = append(problems, common.FieldError{Field: "Metadata", Message: "key " + problem})
+			continue
+		}
+		switch v := m[k]; {
+		case len(v) > MaxMetadataValueLen:
+			problems = append(problems, common.FieldError{Field: "Metadata." + k, Message: fmt.Sprintf("is longer than %d characters", MaxMetadataValueLen)})
+		case !utf8.ValidString(v):
+			problems = append(problems, common.FieldError{Field: "Metadata." + k, Message: "is not UTF-8"})
+		}
+	}
+	return problems
+}
+
+// validateMetadata checks the number of entries and each key and value.
+func validateMetadata(m Metadata) error {
+	if problems := m.problems(); len(problems) > 0 {
+		return propsError(problems[0].Field + " " + problems[0].Message)
+	}
+	return nil
+}
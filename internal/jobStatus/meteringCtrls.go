@@ -0,0 +1,56 @@
+package jobStatus
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// MeteringReportPath is the admin route for the monthly metering report.
+const MeteringReportPath = "/admin/metering"
+
+type GetMeteringReportCtrl struct {
+	uc *MeteringUC
+}
+
+func NewGetMeteringReportCtrl(uc *MeteringUC) *GetMeteringReportCtrl {
+	return &GetMeteringReportCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameters month (2006-01) and optional format (json or csv).
+// CSV has one row per application with total API calls, for spreadsheets and chargeback tools.
+func (ctrl *GetMeteringReportCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		writeError(w, r, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("format %q must be json or csv", format)))
+		return
+	}
+
+	report, err := ctrl.uc.MonthlyReport(r.Context(), q.Get("month"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if format != "csv" {
+		common.WriteJson(w, http.StatusOK, report)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="metering-%s.csv"`, report.Month))
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"AppId", "Month", "ApiCallCt", "RowsStored"})
+	for _, usage := range report.Applications {
+		cw.Write([]string{usage.ApplicationId, report.Month, strconv.FormatInt(usage.ApiCallCount, 10), strconv.FormatInt(usage.RowsStored, 10)})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		log.Printf("%s %s write failed: %v", r.Method, r.URL.Path, err)
+	}
+}
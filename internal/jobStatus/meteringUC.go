@@ -0,0 +1,162 @@
+package jobStatus
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// Metered endpoint names. They're stored, so don't rename them.
+const (
+	MeterAddJobStatus      = "POST /job-statuses"
+	MeterAddJobStatusBatch = "POST /job-status-batches"
+)
+
+const meterDefaultFlushInterval = time.Minute
+
+// ApiCallCount is how many times an application called an endpoint in a month.
+type ApiCallCount struct {
+	ApplicationId string
+	Month         time.Time // first day of the month, UTC
+	Endpoint      string
+	Count         int64
+}
+
+type meterKey struct {
+	applicationId string
+	month         time.Time
+	endpoint      string
+}
+
+// MeteringUC counts API calls per application and builds the monthly metering report.
+// Counts are kept in memory and added to the repo by Flush, so a crash loses at most one
+// flush interval of counts.
+type MeteringUC struct {
+	repo       MeterRepo
+	rollupRepo RollupRepo
+	mu         sync.Mutex
+	pending    map[meterKey]int64
+}
+
+func NewMeteringUC(repo MeterRepo, rollupRepo RollupRepo) *MeteringUC {
+	return &MeteringUC{repo: repo, rollupRepo: rollupRepo, pending: map[meterKey]int64{}}
+}
+
+// CountCall counts one call. Calls without an application aren't metered.
+func (uc *MeteringUC) CountCall(applicationId string, endpoint string) {
+	if len(applicationId) == 0 {
+		return
+	}
+	key := meterKey{applicationId: applicationId, month: monthOf(time.Now()), endpoint: endpoint}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.pending[key]++
+}
+
+// Flush adds pending counts to the repo. If that fails, the counts stay pending for the next Flush.
+func (uc *MeteringUC) Flush(ctx context.Context) error {
+	uc.mu.Lock()
+	pending := uc.pending
+	uc.pending = map[meterKey]int64{}
+	uc.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	counts := make([]ApiCallCount, 0, len(pending))
+	for key, n := range pending {
+		counts = append(counts, ApiCallCount{ApplicationId: key.applicationId, Month: key.month, Endpoint: key.endpoint, Count: n})
+	}
+	if err := uc.repo.AddApiCalls(ctx, counts); err != nil {
+		uc.mu.Lock()
+		for key, n := range pending {
+			uc.pending[key] += n
+		}
+		uc.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// RunFlusher blocks, flushing every interval (a minute if 0) until ctx is done, then flushes once more.
+func (uc *MeteringUC) RunFlusher(ctx context.Context, interval time.Duration) {
+	if interval == 0 {
+		interval = meterDefaultFlushInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// ctx is done, so the last flush gets one of its own
+			if err := uc.Flush(context.Background()); err != nil {
+				log.Printf("final metering flush failed: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := common.CatchPanic("metering flush", func() error { return uc.Flush(ctx) }); err != nil {
+				log.Printf("metering flush failed: %v", err)
+			}
+		}
+	}
+}
+
+// MonthlyReport returns usage for month ("2006-01"). It flushes first so the report includes recent calls.
+func (uc *MeteringUC) MonthlyReport(ctx context.Context, month string) (dto.MeteringReportDto, error) {
+	start, err := time.Parse(dto.MonthFormat, month)
+	if err != nil {
+		return dto.MeteringReportDto{}, common.NewCommonError(common.ErrcdDomainProps, err)
+	}
+	end := start.AddDate(0, 1, -1)
+
+	if err := uc.Flush(ctx); err != nil {
+		return dto.MeteringReportDto{}, err
+	}
+	calls, err := uc.repo.GetApiCalls(ctx, start)
+	if err != nil {
+		return dto.MeteringReportDto{}, err
+	}
+	rollups, err := uc.rollupRepo.GetDailyRollups(ctx, "", start, end)
+	if err != nil {
+		return dto.MeteringReportDto{}, err
+	}
+
+	byApp := map[string]*dto.ApplicationUsageDto{}
+	usageFor := func(applicationId string) *dto.ApplicationUsageDto {
+		usage, ok := byApp[applicationId]
+		if !ok {
+			usage = &dto.ApplicationUsageDto{ApplicationId: applicationId, ApiCalls: map[string]int64{}}
+			byApp[applicationId] = usage
+		}
+		return usage
+	}
+	for _, call := range calls {
+		usage := usageFor(call.ApplicationId)
+		usage.ApiCalls[call.Endpoint] += call.Count
+		usage.ApiCallCount += call.Count
+	}
+	for _, dr := range rollups {
+		usageFor(dr.ApplicationId).RowsStored += dr.StartCount + dr.SucceedCount + dr.FailCount
+	}
+
+	report := dto.MeteringReportDto{Month: start.Format(dto.MonthFormat), Applications: make([]dto.ApplicationUsageDto, 0, len(byApp))}
+	for _, usage := range byApp {
+		report.Applications = append(report.Applications, *usage)
+	}
+	sort.Slice(report.Applications, func(i, j int) bool {
+		return report.Applications[i].ApplicationId < report.Applications[j].ApplicationId
+	})
+	return report, nil
+}
+
+func monthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,425 @@
+package jobStatus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// OpenApiPath serves the OpenAPI 3 document for the job status API.
+const OpenApiPath = "/openapi.json"
+
+// openApiVersion is the OpenAPI version the document follows.
+const openApiVersion = "3.0.3"
+
+// OpenApiCtrl serves the OpenAPI document for the routes that take and return dto.Version DTOs.
+// The document is built once, when the controller is made.
+type OpenApiCtrl struct {
+	doc []byte
+}
+
+// NewOpenApiCtrl builds the OpenAPI document. It panics if the document can't be built, which
+// means a schema rule names a DTO field that no longer exists; mux.Handle panics on bad routes
+// the same way, so the server won't start with a document that has drifted from the DTOs.
+func NewOpenApiCtrl() *OpenApiCtrl {
+	doc, err := OpenApiDocument()
+	if err != nil {
+		panic(err)
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	return &OpenApiCtrl{doc: b}
+}
+
+func (ctrl *OpenApiCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", common.ContentTypeJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ctrl.doc)
+}
+
+// openApiSchema is a JSON Schema object as OpenAPI 3.0 uses it.
+type openApiSchema = map[string]any
+
+// OpenApiDocument returns the OpenAPI document for the job status routes. Schemas are reflected
+// from the dto structs, so field names and optional fields come from their json tags. Constraints
+// come from the domain's own rules, like ID lengths and status codes, so the document says what
+// the server checks.
+func OpenApiDocument() (map[string]any, error) {
+	s := newOpenApiSchemas(openApiRules())
+
+	jobStatus := s.ref(reflect.TypeOf(dto.JobStatusDto{}))
+	newJobStatus := openApiSchema{"allOf": []any{jobStatus, openApiSchema{"required": requiredOnInput}}}
+	jobStatuses := openApiSchema{"type": "array", "items": jobStatus}
+
+	paths := map[string]any{
+		JobStatusesPath: map[string]any{
+			"post": openApiOperation("addJobStatus",
+				"Adds one status. A retry of a stored status gets it back with 200 instead of 201.",
+				addJobStatusParams(), newJobStatus,
+				map[string]any{
+					"201": s.response("The status was added.", jobStatus, nil),
+					"200": s.response("The same status was already stored.", jobStatus, nil),
+				},
+				s.errorResponses(http.StatusBadRequest, http.StatusConflict, http.StatusTooManyRequests, http.StatusServiceUnavailable)),
+			"get": openApiOperation("getJobStatuses",
+				"Queries statuses by jobId, a saved view, or filters. Filters are query parameters shaped like "+
+					"<DTO field>[<op>]=<value>, like JobSt[in]=START,FAIL; they can replace jobId if they narrow on JobId or BusDt. "+
+					"With limit, the response is one page. With partial=true, rows that can't be read are listed instead of failing the query. "+
+					"With stream, rows are written as they're read.",
+				getJobStatusesParams(),
+				nil,
+				map[string]any{
+					"200": s.queryResponse(jobStatuses),
+				},
+				s.errorResponses(http.StatusBadRequest, http.StatusNotFound, http.StatusServiceUnavailable)),
+		},
+		JobStatusBatchesPath: map[string]any{
+			"post": openApiOperation("addJobStatusBatch",
+				"Adds statuses all or nothing.",
+				addJobStatusParams(), openApiSchema{"type": "array", "items": newJobStatus, "minItems": 1, "maxItems": MaxBatchStatuses},
+				map[string]any{
+					"201": s.response("The statuses were added.", jobStatuses, nil),
+				},
+				s.errorResponses(http.StatusBadRequest, http.StatusConflict, http.StatusTooManyRequests, http.StatusServiceUnavailable)),
+		},
+		JobHeartbeatsPath: map[string]any{
+			"post": openApiOperation("recordHeartbeat",
+				"Says a run is still going. Sets HeartbeatTs on the run's START status, unless it has a later one, and returns that status.",
+				nil, s.ref(reflect.TypeOf(dto.HeartbeatDto{})),
+				map[string]any{
+					"200": s.response("The run's START status.", jobStatus, nil),
+				},
+				s.errorResponses(http.StatusBadRequest, http.StatusNotFound, http.StatusServiceUnavailable)),
+		},
+	}
+
+	if names := s.unusedRules(); len(names) > 0 {
+		return nil, fmt.Errorf("OpenAPI rules name fields the DTOs don't have: %s", strings.Join(names, ", "))
+	}
+	return map[string]any{
+		"openapi": openApiVersion,
+		"info": map[string]any{
+			"title":   "Job status API",
+			"version": dto.Version,
+			"description": "Errors are an ErrorDto if the request's Accept names application/json, a JSON:API error document if it asks for " +
+				dto.JsonApiMediaType + ", and plain text otherwise. " +
+				"A 500 from a server fault is an RFC 7807 problem. Successful JSON responses are wrapped in an envelope " +
+				"if Accept asks for " + dto.EnvelopeMediaType + " or the server wraps them by default.",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": s.components},
+	}, nil
+}
+
+// requiredOnInput are the JobStatusDto fields a new status must have. RunId is generated if
+// it's missing, and the rest are set by the server.
+var requiredOnInput = []string{"AppId", "JobId", "JobSt", "JobStTs", "BusDt", "HostId"}
+
+// openApiRules adds constraints to reflected schemas, by component name and JSON field name.
+// Each rule must name a field its DTO has, or OpenApiDocument fails.
+func openApiRules() map[string]map[string]openApiSchema {
+	id := func(maxLen int) openApiSchema {
+		return openApiSchema{"minLength": 1, "maxLength": maxLen, "pattern": idPattern()}
+	}
+
+	return map[string]map[string]openApiSchema{
+		"JobStatusDto": {
+			"StatusId":      {"format": "uuid", "readOnly": true},
+			"AppId":         {"minLength": 1, "maxLength": MaxApplicationIdLen},
+			"JobId":         id(MaxJobIdLen),
+			"JobSt":         {"enum": jobStatusCodeNames()},
+			"JobStTs":       {"format": "date-time"},
+			"BusDt":         {"format": "date"},
+			"RunId":         id(MaxRunIdLen),
+			"HostId":        id(MaxHostIdLen),
+			"ReportedJobId": {"readOnly": true},
+			"RecvTs":        {"format": "date-time", "readOnly": true},
+			"Metadata": {
+				"maxProperties":        MaxMetadataEntries,
+				"additionalProperties": openApiSchema{"type": "string", "maxLength": MaxMetadataValueLen},
+			},
+			"HeartbeatTs": {"format": "date-time", "readOnly": true},
+		},
+		"HeartbeatDto": {
+			"JobId":       id(MaxJobIdLen),
+			"BusDt":       {"format": "date"},
+			"RunId":       id(MaxRunIdLen),
+			"HeartbeatTs": {"format": "date-time"},
+		},
+		"LinkDto": {
+			"Kind": {"enum": linkKindNames()},
+			"Url":  {"format": "uri", "pattern": "^https?://"},
+		},
+	}
+}
+
+// idPattern is a regular expression for the characters isIdChar allows.
+func idPattern() string {
+	var class strings.Builder
+	for c := byte(0); c < 128; c++ {
+		switch {
+		case !isIdChar(c):
+		case isAlnum(c):
+			// letters and digits are runs, so write each run as a range
+			end := c
+			for isAlnum(end + 1) {
+				end++
+			}
+			fmt.Fprintf(&class, "%c-%c", c, end)
+			c = end
+		case c == '-':
+			class.WriteString(`\-`)
+		default:
+			class.WriteByte(c)
+		}
+	}
+	return "^[" + class.String() + "]+$"
+}
+
+func isAlnum(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// sortedStrings sorts and returns keys, for enums built from the domain's maps.
+func sortedStrings(keys []string) []string {
+	sort.Strings(keys)
+	return keys
+}
+
+func jobStatusCodeNames() []string {
+	var names []string
+	for code := range validJobStatusCodes {
+		names = append(names, string(code))
+	}
+	return sortedStrings(names)
+}
+
+func linkKindNames() []string {
+	var names []string
+	for kind := range validLinkKinds {
+		names = append(names, string(kind))
+	}
+	return sortedStrings(names)
+}
+
+func dtoFieldNameList() []string {
+	var names []string
+	for name := range dtoFieldNames {
+		names = append(names, name)
+	}
+	return sortedStrings(names)
+}
+
+func getJobStatusesParams() []any {
+	param := func(name string, description string, schema openApiSchema) map[string]any {
+		return map[string]any{"name": name, "in": "query", "description": description, "schema": schema}
+	}
+	str := openApiSchema{"type": "string"}
+	return []any{
+		param("jobId", "JobId to query; required unless view or filters are set", str),
+		param("busDt", "only statuses for this business date", openApiSchema{"type": "string", "format": "date"}),
+		param("view", "a saved view's name, which replaces jobId", str),
+		param("fields", "comma separated DTO fields to return: "+strings.Join(dtoFieldNameList(), ", "), str),
+		param("sort", "comma separated DTO fields to sort by, each optionally followed by :asc or :desc", str),
+		param("partial", "return the rows that can be read even if some can't", openApiSchema{"type": "boolean"}),
+		param("asOf", "only statuses the server had received by this time", openApiSchema{"type": "string", "format": "date-time"}),
+		param("limit", "return one page of at most this many statuses", openApiSchema{"type": "integer", "minimum": 1}),
+		param("offset", "with limit, how many statuses to skip", openApiSchema{"type": "integer", "minimum": 0}),
+		param("stream", "write rows as they're read, as NDJSON or one JSON array; can't be used with view, limit, or JSON:API",
+			openApiSchema{"type": "string", "enum": []string{"ndjson", "array"}}),
+	}
+}
+
+func addJobStatusParams() []any {
+	return []any{map[string]any{"name": "appId", "in": "query",
+		"description": "the sending application, whose configured field mappings rename legacy fields and which is the AppId of statuses without one",
+		"schema":      openApiSchema{"type": "string"}}}
+}
+
+func openApiOperation(id string, description string, params []any, body openApiSchema, responses map[string]any, errors map[string]any) map[string]any {
+	op := map[string]any{"operationId": id, "description": description}
+	if params != nil {
+		op["parameters"] = params
+	}
+	if body != nil {
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{common.ContentTypeJson: map[string]any{"schema": body}},
+		}
+	}
+	for status, response := range errors {
+		responses[status] = response
+	}
+	op["responses"] = responses
+	return op
+}
+
+// openApiSchemas reflects dto types into component schemas.
+type openApiSchemas struct {
+	components map[string]any
+	rules      map[string]map[string]openApiSchema
+	// unused are rules no reflected field has used yet, by component name
+	unused map[string]map[string]bool
+}
+
+func newOpenApiSchemas(rules map[string]map[string]openApiSchema) *openApiSchemas {
+	unused := map[string]map[string]bool{}
+	for component, fields := range rules {
+		unused[component] = map[string]bool{}
+		for field := range fields {
+			unused[component][field] = true
+		}
+	}
+	return &openApiSchemas{components: map[string]any{}, rules: rules, unused: unused}
+}
+
+// response describes a JSON response with body, which can also come wrapped in an envelope.
+// jsonApi, if it isn't nil, is the body for JSON:API requests.
+func (s *openApiSchemas) response(description string, body openApiSchema, jsonApi openApiSchema) map[string]any {
+	content := map[string]any{
+		common.ContentTypeJson: map[string]any{"schema": body},
+		dto.EnvelopeMediaType: map[string]any{"schema": openApiSchema{"allOf": []any{
+			s.ref(reflect.TypeOf(dto.EnvelopeDto{})),
+			openApiSchema{"properties": map[string]any{"Data": body}},
+		}}},
+	}
+	if jsonApi != nil {
+		content[dto.JsonApiMediaType] = map[string]any{"schema": jsonApi}
+	}
+	return map[string]any{"description": description, "content": content}
+}
+
+// queryResponse describes every shape a job status query can return.
+func (s *openApiSchemas) queryResponse(jobStatuses openApiSchema) map[string]any {
+	body := openApiSchema{"oneOf": []any{
+		jobStatuses,
+		s.ref(reflect.TypeOf(dto.JobStatusPageDto{})),
+		s.ref(reflect.TypeOf(dto.PartialJobStatusesDto{})),
+	}}
+	response := s.response("Statuses: an array; a JobStatusPageDto with limit; a PartialJobStatusesDto with partial=true.",
+		body, s.ref(reflect.TypeOf(dto.JsonApiJobStatusesDto{})))
+	response["content"].(map[string]any)[common.ContentTypeNdjson] = map[string]any{"schema": s.ref(reflect.TypeOf(dto.JobStatusDto{}))}
+	return response
+}
+
+// errorResponses describes the errors an operation can return, plus 500, which any can.
+func (s *openApiSchemas) errorResponses(statuses ...int) map[string]any {
+	text := map[string]any{"schema": openApiSchema{"type": "string"}}
+	errDto := map[string]any{"schema": s.ref(reflect.TypeOf(dto.ErrorDto{}))}
+	jsonApi := map[string]any{"schema": s.ref(reflect.TypeOf(dto.JsonApiErrorsDto{}))}
+
+	responses := map[string]any{}
+	for _, status := range statuses {
+		responses[fmt.Sprint(status)] = map[string]any{
+			"description": http.StatusText(status),
+			"content":     map[string]any{"text/plain": text, common.ContentTypeJson: errDto, dto.JsonApiMediaType: jsonApi},
+		}
+	}
+	responses["500"] = map[string]any{
+		"description": "A server fault. The body doesn't describe it; the server's log does.",
+		"content": map[string]any{
+			"text/plain":               text,
+			common.ContentTypeJson:     errDto,
+			dto.JsonApiMediaType:       jsonApi,
+			"application/problem+json": map[string]any{"schema": s.ref(reflect.TypeOf(common.Problem{}))},
+		},
+	}
+	return responses
+}
+
+// ref returns a reference to t's component schema, reflecting it the first time.
+func (s *openApiSchemas) ref(t reflect.Type) openApiSchema {
+	name := t.Name()
+	if _, ok := s.components[name]; !ok {
+		// placeholder first, so a type that contains itself doesn't recurse forever
+		s.components[name] = openApiSchema{}
+		s.components[name] = s.object(t)
+	}
+	return openApiSchema{"$ref": "#/components/schemas/" + name}
+}
+
+// object reflects a struct's exported fields by their json names. Fields without omitempty are
+// required, since they're always in the JSON.
+func (s *openApiSchemas) object(t reflect.Type) openApiSchema {
+	rules := s.rules[t.Name()]
+	properties := map[string]any{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		schema := s.of(f.Type)
+		for k, v := range rules[name] {
+			schema[k] = v
+		}
+		delete(s.unused[t.Name()], name)
+		properties[name] = schema
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	if len(s.unused[t.Name()]) == 0 {
+		delete(s.unused, t.Name())
+	}
+
+	schema := openApiSchema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// of returns the schema for a field's type. Structs become references; a new map is returned
+// each time, so rules can add to it.
+func (s *openApiSchemas) of(t reflect.Type) openApiSchema {
+	if t == rawMessageType {
+		return openApiSchema{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.of(t.Elem())
+	case reflect.Struct:
+		return s.ref(t)
+	case reflect.Slice, reflect.Array:
+		return openApiSchema{"type": "array", "items": s.of(t.Elem())}
+	case reflect.Map:
+		return openApiSchema{"type": "object", "additionalProperties": s.of(t.Elem())}
+	case reflect.String:
+		return openApiSchema{"type": "string"}
+	case reflect.Bool:
+		return openApiSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return openApiSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return openApiSchema{"type": "number"}
+	}
+	return openApiSchema{}
+}
+
+// unusedRules lists rules that name missing fields as Component.Field.
+func (s *openApiSchemas) unusedRules() []string {
+	var names []string
+	for component, fields := range s.unused {
+		for field := range fields {
+			names = append(names, component+"."+field)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,47 @@
+package jobStatus
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// RowError describes one result row the repo couldn't convert. Row counts from 0 in result order.
+type RowError struct {
+	Row int
+	Err error
+}
+
+// PartialResultError lists the rows left out of a partial result.
+type PartialResultError struct {
+	RowErrors []RowError
+}
+
+func (pre *PartialResultError) Error() string {
+	msgs := make([]string, 0, len(pre.RowErrors))
+	for _, re := range pre.RowErrors {
+		msgs = append(msgs, fmt.Sprintf("row %d: %v", re.Row, re.Err))
+	}
+	return fmt.Sprintf("%d row(s) could not be read: %s", len(pre.RowErrors), strings.Join(msgs, "; "))
+}
+
+// NewPartialResultError returns the CommonError repos return with partial results.
+func NewPartialResultError(rowErrors []RowError) error {
+	return common.NewCommonError(common.ErrcdRepoPartialResult, &PartialResultError{RowErrors: rowErrors})
+}
+
+// IsPartialResult reports whether err means "these results are usable but incomplete."
+func IsPartialResult(err error) bool {
+	return common.ErrorCode(err) == common.ErrcdRepoPartialResult
+}
+
+// RowErrorsOf returns the row errors in a partial result error, or nil.
+func RowErrorsOf(err error) []RowError {
+	var pre *PartialResultError
+	if errors.As(err, &pre) {
+		return pre.RowErrors
+	}
+	return nil
+}
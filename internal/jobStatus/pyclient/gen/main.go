@@ -0,0 +1,29 @@
+// Command gen writes the Python client for jobStatus.OpenApiDocument to the file named by its
+// argument. go generate runs it from the pyclient package.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/pyclient"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: gen OUTPUT.py")
+		os.Exit(2)
+	}
+	doc, err := jobStatus.OpenApiDocument()
+	if err == nil {
+		var client []byte
+		if client, err = pyclient.Generate(doc); err == nil {
+			err = os.WriteFile(os.Args[1], client, 0o644)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gen failed:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,591 @@
+// Package pyclient generates the Python client in public/jobStatus/python from the job status
+// API's OpenAPI document, so the client changes when the DTOs and routes do. The client uses
+// only Python's standard library, so it can be copied onto batch hosts like cmd/goslo-report.
+//
+// Run go generate in this package after changing the document; a test fails while the checked
+// in client is out of date.
+package pyclient
+
+//go:generate go run ./gen ../../../public/jobStatus/python/goslo_client.py
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// document is the part of an OpenAPI 3.0 document the generator reads.
+type document struct {
+	Info struct {
+		Title   string
+		Version string
+	}
+	Paths      map[string]map[string]operation
+	Components struct {
+		Schemas map[string]*schema
+	}
+}
+
+type operation struct {
+	OperationId string
+	Description string
+	Parameters  []parameter
+	RequestBody *struct {
+		Content map[string]mediaType
+	}
+	Responses map[string]struct {
+		Content map[string]mediaType
+	}
+}
+
+type parameter struct {
+	Name        string
+	In          string
+	Description string
+	Schema      *schema
+}
+
+type mediaType struct {
+	Schema *schema
+}
+
+type schema struct {
+	Ref                  string `json:"$ref"`
+	Type                 string
+	Items                *schema
+	Properties           map[string]*schema
+	AdditionalProperties *schema
+	Required             []string
+	AllOf                []*schema
+	OneOf                []*schema
+	Enum                 []any
+	ReadOnly             bool
+}
+
+// jsonMediaType is the only content the client asks for and sends.
+const jsonMediaType = "application/json"
+
+const refPrefix = "#/components/schemas/"
+
+// Generate returns the Python client for doc, an OpenAPI document like
+// jobStatus.OpenApiDocument returns. It has a dataclass for each component schema the JSON
+// requests and responses use, and a Client method for each operation.
+func Generate(openApiDoc map[string]any) ([]byte, error) {
+	b, err := json.Marshal(openApiDoc)
+	if err != nil {
+		return nil, err
+	}
+	var doc document
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("reading the OpenAPI document: %w", err)
+	}
+
+	g := &generator{doc: doc, used: map[string]bool{}}
+	ops := g.operations()
+	for _, op := range ops {
+		if err := g.markUsed(op.op); err != nil {
+			return nil, fmt.Errorf("%s: %w", op.op.OperationId, err)
+		}
+	}
+	if !g.used["ErrorDto"] {
+		return nil, fmt.Errorf("no operation returns an ErrorDto, which ApiError needs")
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, header, doc.Info.Title, doc.Info.Version, doc.Info.Version)
+	for _, name := range sortedKeys(g.used) {
+		g.writeClass(&out, name)
+	}
+	out.WriteString(clientHeader)
+	for _, op := range ops {
+		if err := g.writeMethod(&out, op.path, op.method, op.op); err != nil {
+			return nil, fmt.Errorf("%s: %w", op.op.OperationId, err)
+		}
+	}
+	out.WriteString(runtime)
+	return out.Bytes(), nil
+}
+
+type generator struct {
+	doc  document
+	used map[string]bool
+}
+
+type pathOperation struct {
+	path   string
+	method string
+	op     operation
+}
+
+// operations are the document's operations, by path and method so the output is stable.
+func (g *generator) operations() []pathOperation {
+	var ops []pathOperation
+	for _, path := range sortedKeys(g.doc.Paths) {
+		for _, method := range sortedKeys(g.doc.Paths[path]) {
+			ops = append(ops, pathOperation{path: path, method: strings.ToUpper(method), op: g.doc.Paths[path][method]})
+		}
+	}
+	return ops
+}
+
+// markUsed marks the components op's JSON bodies use, and the components they use.
+func (g *generator) markUsed(op operation) error {
+	var schemas []*schema
+	for _, p := range op.Parameters {
+		schemas = append(schemas, p.Schema)
+	}
+	if op.RequestBody != nil {
+		schemas = append(schemas, op.RequestBody.Content[jsonMediaType].Schema)
+	}
+	for _, response := range op.Responses {
+		schemas = append(schemas, response.Content[jsonMediaType].Schema)
+	}
+	for _, s := range schemas {
+		if err := g.markSchema(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) markSchema(s *schema) error {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		name, err := g.refName(s.Ref)
+		if err != nil || g.used[name] {
+			return err
+		}
+		g.used[name] = true
+		s = g.doc.Components.Schemas[name]
+	}
+	children := append(append([]*schema{s.Items, s.AdditionalProperties}, s.AllOf...), s.OneOf...)
+	for _, name := range sortedKeys(s.Properties) {
+		children = append(children, s.Properties[name])
+	}
+	for _, child := range children {
+		if err := g.markSchema(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) refName(ref string) (string, error) {
+	name := strings.TrimPrefix(ref, refPrefix)
+	if name == ref || g.doc.Components.Schemas[name] == nil {
+		return "", fmt.Errorf("%s isn't a component schema", ref)
+	}
+	return name, nil
+}
+
+func (g *generator) writeClass(out *bytes.Buffer, name string) {
+	s := g.doc.Components.Schemas[name]
+	fmt.Fprintf(out, "\n\n@dataclasses.dataclass\nclass %s:\n", name)
+	doc := fmt.Sprintf("The %s schema.", name)
+	if len(s.Required) > 0 {
+		doc += " The API always sends " + strings.Join(s.Required, ", ") + "."
+	}
+	writeDocstring(out, "    ", doc, nil)
+	out.WriteString("\n")
+
+	fields := sortedKeys(s.Properties)
+	for _, field := range fields {
+		fs := s.Properties[field]
+		line := fmt.Sprintf("    %s: Optional[%s] = None", field, g.pyType(fs))
+		var notes []string
+		if fs.ReadOnly {
+			notes = append(notes, "set by the server")
+		}
+		if len(fs.Enum) > 0 {
+			values := make([]string, len(fs.Enum))
+			for i, v := range fs.Enum {
+				values[i] = fmt.Sprint(v)
+			}
+			notes = append(notes, "one of "+strings.Join(values, ", "))
+		}
+		if len(notes) > 0 {
+			line += "  # " + strings.Join(notes, "; ")
+		}
+		out.WriteString(line + "\n")
+	}
+
+	fmt.Fprintf(out, "\n    @classmethod\n    def from_dict(cls, d: Dict[str, Any]) -> %s:\n        return cls(\n", name)
+	for _, field := range fields {
+		fmt.Fprintf(out, "            %s=%s,\n", field, g.decode(s.Properties[field], fmt.Sprintf("d.get(%q)", field)))
+	}
+	out.WriteString("        )\n\n    def to_dict(self) -> Dict[str, Any]:\n        return _plain(self)\n")
+}
+
+func (g *generator) writeMethod(out *bytes.Buffer, path string, method string, op operation) error {
+	type arg struct {
+		name, pyName, pyType, description string
+	}
+	var args []arg
+	if op.RequestBody != nil {
+		body := op.RequestBody.Content[jsonMediaType].Schema
+		if body == nil {
+			return fmt.Errorf("the request body isn't %s", jsonMediaType)
+		}
+		args = append(args, arg{pyName: "body", pyType: g.pyType(body)})
+	}
+	var queryArgs []arg
+	for _, p := range op.Parameters {
+		if p.In != "query" {
+			return fmt.Errorf("parameter %s is in %s; only query parameters are supported", p.Name, p.In)
+		}
+		queryArgs = append(queryArgs, arg{name: p.Name, pyName: snakeCase(p.Name), pyType: "Optional[" + g.pyType(p.Schema) + "]", description: p.Description})
+	}
+
+	result, resultSchema, err := g.result(op)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\n    def %s(\n        self,\n", snakeCase(op.OperationId))
+	for _, a := range args {
+		fmt.Fprintf(out, "        %s: %s,\n", a.pyName, a.pyType)
+	}
+	for _, a := range queryArgs {
+		fmt.Fprintf(out, "        %s: %s = None,\n", a.pyName, a.pyType)
+	}
+	fmt.Fprintf(out, "        query: Optional[Dict[str, str]] = None,\n    ) -> %s:\n", result)
+
+	var argDocs []string
+	for _, a := range queryArgs {
+		argDocs = append(argDocs, fmt.Sprintf("%s (%s): %s", a.pyName, a.name, a.description))
+	}
+	argDocs = append(argDocs, "query: more query parameters, sent as they are")
+	writeDocstring(out, "        ", fmt.Sprintf("%s %s. %s", method, path, op.Description), argDocs)
+
+	if len(queryArgs) == 0 {
+		out.WriteString("        params: Dict[str, Any] = {}\n")
+	} else {
+		out.WriteString("        params = {\n")
+		for _, a := range queryArgs {
+			fmt.Fprintf(out, "            %q: %s,\n", a.name, a.pyName)
+		}
+		out.WriteString("        }\n")
+	}
+	bodyArg := "None"
+	if op.RequestBody != nil {
+		bodyArg = "_plain(body)"
+	}
+	fmt.Fprintf(out, "        data = self._request(%q, %q, params, query, %s)\n", method, path, bodyArg)
+
+	switch {
+	case resultSchema == nil:
+		out.WriteString("        return None\n")
+	case len(resultSchema.OneOf) > 0:
+		// one shape per line, since these get long
+		out.WriteString("        return _one_of(data, [\n")
+		for _, one := range resultSchema.OneOf {
+			fmt.Fprintf(out, "            (%s, lambda v: %s),\n", g.shape(one), g.decode(one, "v"))
+		}
+		out.WriteString("        ])\n")
+	default:
+		fmt.Fprintf(out, "        return %s\n", g.decode(resultSchema, "data"))
+	}
+	return nil
+}
+
+// result is the Python type and schema of op's successful JSON response. Every 2xx response
+// must have the same type.
+func (g *generator) result(op operation) (string, *schema, error) {
+	var pyType string
+	var result *schema
+	for _, status := range sortedKeys(op.Responses) {
+		if !strings.HasPrefix(status, "2") {
+			continue
+		}
+		s := op.Responses[status].Content[jsonMediaType].Schema
+		if s == nil {
+			return "", nil, fmt.Errorf("response %s isn't %s", status, jsonMediaType)
+		}
+		t := g.pyType(s)
+		if pyType != "" && t != pyType {
+			return "", nil, fmt.Errorf("2xx responses have different types, %s and %s", pyType, t)
+		}
+		pyType, result = t, s
+	}
+	if pyType == "" {
+		return "None", nil, nil
+	}
+	return pyType, result, nil
+}
+
+// pyType is the Python type annotation for s.
+func (g *generator) pyType(s *schema) string {
+	switch {
+	case s == nil:
+		return "Any"
+	case s.Ref != "":
+		return strings.TrimPrefix(s.Ref, refPrefix)
+	case len(s.AllOf) > 0:
+		// allOf here narrows a component, like JobStatusDto with more required fields
+		return g.pyType(s.AllOf[0])
+	case len(s.OneOf) > 0:
+		types := make([]string, len(s.OneOf))
+		for i, one := range s.OneOf {
+			types[i] = g.pyType(one)
+		}
+		return "Union[" + strings.Join(types, ", ") + "]"
+	case s.Type == "array":
+		return "List[" + g.pyType(s.Items) + "]"
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		return "Dict[str, " + g.pyType(s.AdditionalProperties) + "]"
+	}
+	switch s.Type {
+	case "string":
+		return "str"
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	}
+	return "Any"
+}
+
+// decode is the Python expression that turns expr, decoded JSON, into s's pyType. It's expr if
+// nothing needs converting.
+func (g *generator) decode(s *schema, expr string) string {
+	switch {
+	case s == nil:
+		return expr
+	case s.Ref != "":
+		return fmt.Sprintf("_obj(%s, %s)", expr, g.pyType(s))
+	case len(s.AllOf) > 0:
+		return g.decode(s.AllOf[0], expr)
+	case len(s.OneOf) > 0:
+		shapes := make([]string, len(s.OneOf))
+		for i, one := range s.OneOf {
+			shapes[i] = fmt.Sprintf("(%s, lambda v: %s)", g.shape(one), g.decode(one, "v"))
+		}
+		return fmt.Sprintf("_one_of(%s, [%s])", expr, strings.Join(shapes, ", "))
+	case s.Type == "array":
+		if item := g.decode(s.Items, "v"); item != "v" {
+			return fmt.Sprintf("_list(%s, lambda v: %s)", expr, item)
+		}
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		if value := g.decode(s.AdditionalProperties, "v"); value != "v" {
+			return fmt.Sprintf("_map(%s, lambda v: %s)", expr, value)
+		}
+	}
+	return expr
+}
+
+// shape is what _one_of checks to pick s: list for arrays, or the keys an object always has.
+func (g *generator) shape(s *schema) string {
+	if s.Ref != "" {
+		s = g.doc.Components.Schemas[strings.TrimPrefix(s.Ref, refPrefix)]
+	}
+	if s.Type == "array" {
+		return "list"
+	}
+	keys := make([]string, len(s.Required))
+	for i, key := range s.Required {
+		keys[i] = fmt.Sprintf("%q", key)
+	}
+	if len(keys) == 1 {
+		return "(" + keys[0] + ",)"
+	}
+	return "(" + strings.Join(keys, ", ") + ")"
+}
+
+// writeDocstring writes summary, and args after a blank line, as a docstring at indent, wrapped
+// at 100 columns. Each arg's later lines are indented under it.
+func writeDocstring(out *bytes.Buffer, indent string, summary string, args []string) {
+	out.WriteString(indent + `"""` + wrap(summary, indent, 100-len(indent)))
+	if len(args) == 0 {
+		out.WriteString(`"""` + "\n")
+		return
+	}
+	out.WriteString("\n")
+	for _, arg := range args {
+		out.WriteString("\n" + indent + wrap(arg, indent+"    ", 100-len(indent)-4))
+	}
+	out.WriteString("\n" + indent + `"""` + "\n")
+}
+
+func wrap(text string, indent string, width int) string {
+	var b strings.Builder
+	lineLen := 0
+	for _, word := range strings.Fields(text) {
+		if lineLen > 0 && lineLen+1+len(word) > width {
+			b.WriteString("\n" + indent)
+			lineLen = 0
+		} else if lineLen > 0 {
+			b.WriteString(" ")
+			lineLen++
+		}
+		b.WriteString(word)
+		lineLen += len(word)
+	}
+	return b.String()
+}
+
+// snakeCase turns an OpenAPI name like addJobStatus or busDt into add_job_status or bus_dt.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+const header = `# Code generated by go generate ./internal/jobStatus/pyclient. DO NOT EDIT.
+"""Python client for the %s, API version %s.
+
+It uses only the standard library, so it can be copied onto batch hosts. Field names are the
+API's JSON names, like AppId and JobStTs. Fields left as None aren't sent. An error response
+raises ApiError.
+
+    from goslo_client import Client, JobStatusDto
+
+    c = Client("http://localhost:9201")
+    js = c.add_job_status(JobStatusDto(AppId="overdrafts", JobId="od-calc", JobSt="START",
+                                       JobStTs="2024-05-01T02:00:00Z", BusDt="2024-05-01",
+                                       HostId="batch01"))
+    runs = c.get_job_statuses(job_id="od-calc", bus_dt="2024-05-01")
+"""
+
+from __future__ import annotations
+
+import dataclasses
+import json
+import urllib.error
+import urllib.parse
+import urllib.request
+from typing import Any, Callable, Dict, List, Optional, Union
+
+__version__ = "%s"
+`
+
+const clientHeader = `
+
+class ApiError(Exception):
+    """A response that isn't 2xx. error is the ErrorDto the API sent, or one with only a Message
+    if it answered in plain text; act on error.Code rather than the message."""
+
+    def __init__(self, status: int, error: ErrorDto):
+        super().__init__(f"job status API returned {status}: {error.Message}")
+        self.status = status
+        self.error = error
+
+
+class Client:
+    """A client for the API at base_url, like "http://localhost:9201". headers are sent with every
+    request, for a bearer token, say. timeout is in seconds."""
+
+    def __init__(self, base_url: str, timeout: float = 10.0, headers: Optional[Dict[str, str]] = None):
+        self.base_url = base_url.rstrip("/")
+        self.timeout = timeout
+        self.headers = dict(headers or {})
+`
+
+const runtime = `
+    def _request(self, method: str, path: str, params: Dict[str, Any], query: Optional[Dict[str, str]],
+                 body: Any) -> Any:
+        q = {name: _query_value(value) for name, value in params.items() if value is not None}
+        q.update(query or {})
+        url = self.base_url + path + ("?" + urllib.parse.urlencode(q) if q else "")
+        data = None if body is None else json.dumps(body).encode()
+        headers = dict(self.headers, Accept="application/json")
+        if data is not None:
+            headers["Content-Type"] = "application/json"
+
+        # urllib won't resend a POST on a 307 or 308, which is how a passive region sends writes
+        # to the active one, so follow those here
+        for _ in range(3):
+            req = urllib.request.Request(url, data=data, method=method, headers=headers)
+            try:
+                with urllib.request.urlopen(req, timeout=self.timeout) as resp:
+                    return _read(resp.status, resp.headers.get("Content-Type", ""), resp.read())
+            except urllib.error.HTTPError as err:
+                location = err.headers.get("Location")
+                if err.code in (307, 308) and location:
+                    url = urllib.parse.urljoin(url, location)
+                    continue
+                raise _api_error(err.code, err.headers.get("Content-Type", ""), err.read()) from None
+        raise ApiError(307, ErrorDto(Message="too many redirects"))
+
+
+def _read(status: int, content_type: str, body: bytes) -> Any:
+    if content_type.startswith("application/x-ndjson"):
+        rows = [json.loads(line) for line in body.splitlines() if line.strip()]
+        # a stream that fails after it started ends with an {"Error": "..."} line
+        if rows and isinstance(rows[-1], dict) and "Error" in rows[-1]:
+            raise ApiError(status, ErrorDto(Message=rows[-1]["Error"]))
+        return rows
+    return json.loads(body) if body else None
+
+
+def _api_error(status: int, content_type: str, body: bytes) -> ApiError:
+    text = body.decode(errors="replace").strip()
+    if content_type.startswith("application/json"):
+        try:
+            return ApiError(status, ErrorDto.from_dict(json.loads(text)))
+        except (ValueError, AttributeError):
+            pass
+    return ApiError(status, ErrorDto(Message=text))
+
+
+def _query_value(value: Any) -> str:
+    if isinstance(value, bool):
+        return "true" if value else "false"
+    return str(value)
+
+
+def _plain(value: Any) -> Any:
+    if dataclasses.is_dataclass(value):
+        return {f.name: _plain(getattr(value, f.name)) for f in dataclasses.fields(value)
+                if getattr(value, f.name) is not None}
+    if isinstance(value, list):
+        return [_plain(v) for v in value]
+    if isinstance(value, dict):
+        return {k: _plain(v) for k, v in value.items()}
+    return value
+
+
+def _obj(value: Any, cls: Any) -> Any:
+    return None if value is None else cls.from_dict(value)
+
+
+def _list(value: Any, decode: Callable[[Any], Any]) -> Any:
+    return None if value is None else [decode(v) for v in value]
+
+
+def _map(value: Any, decode: Callable[[Any], Any]) -> Any:
+    return None if value is None else {k: decode(v) for k, v in value.items()}
+
+
+def _one_of(value: Any, shapes: List[Any]) -> Any:
+    # picks the first shape value has: list for a list, or keys a dict has all of
+    for shape, decode in shapes:
+        if shape is list and isinstance(value, list):
+            return decode(value)
+        if isinstance(shape, tuple) and isinstance(value, dict) and all(k in value for k in shape):
+            return decode(value)
+    return value
+`
@@ -0,0 +1,72 @@
+package pyclient_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbmemory"
+	"github.com/jmjf/go-jst/internal/jobStatus/pyclient"
+)
+
+var clientPath = filepath.Join("..", "..", "..", "public", "jobStatus", "python", "goslo_client.py")
+
+func TestClientIsCurrent(t *testing.T) {
+	doc, err := jobStatus.OpenApiDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := pyclient.Generate(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(clientPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s is out of date; run go generate ./internal/jobStatus/pyclient", clientPath)
+	}
+}
+
+func TestGenerateRejectsUnsupportedDocuments(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		doc  map[string]any
+	}{
+		{"no ErrorDto", map[string]any{"paths": map[string]any{}}},
+		{"missing component", map[string]any{"paths": map[string]any{"/x": map[string]any{"get": map[string]any{
+			"operationId": "getX",
+			"responses": map[string]any{"200": map[string]any{"content": map[string]any{
+				"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Missing"}}}}},
+		}}}}},
+	} {
+		if _, err := pyclient.Generate(tt.doc); err == nil {
+			t.Errorf("%s: got no error, want one", tt.name)
+		}
+	}
+}
+
+// TestPythonClient runs testdata/check_client.py against a server on a memory repo. It needs
+// python3 on the PATH, and is skipped without it.
+func TestPythonClient(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 isn't on the PATH")
+	}
+	mux := http.NewServeMux()
+	jobStatus.AddRoutes(mux, jobStatus.FullPorts(dbmemory.NewRepoMemory()), jobStatus.Services{})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cmd := exec.Command(python, filepath.Join("testdata", "check_client.py"), server.URL)
+	cmd.Env = append(os.Environ(), "PYTHONPATH="+filepath.Dir(clientPath), "PYTHONDONTWRITEBYTECODE=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("check_client.py: %v\n%s", err, out)
+	}
+}
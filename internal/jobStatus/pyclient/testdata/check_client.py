@@ -0,0 +1,51 @@
+"""Checks goslo_client against the server at sys.argv[1]. pyclient_test.go runs it."""
+
+import sys
+
+from goslo_client import ApiError, Client, HeartbeatDto, JobStatusDto, JobStatusPageDto, LinkDto
+
+c = Client(sys.argv[1])
+
+
+def status(**fields):
+    base = dict(AppId="overdrafts", JobId="od-calc", JobSt="START", JobStTs="2024-05-01T02:00:00Z",
+                BusDt="2024-05-01", RunId="1", HostId="batch01")
+    base.update(fields)
+    return JobStatusDto(**base)
+
+
+added = c.add_job_status(status(Links=[LinkDto(Kind="log", Url="https://logs.example.com/1")]))
+assert added.StatusId and added.RecvTs, added
+assert added.Links == [LinkDto(Kind="log", Url="https://logs.example.com/1")], added.Links
+
+retry = c.add_job_status(status(Links=[LinkDto(Kind="log", Url="https://logs.example.com/1")]))
+assert retry.StatusId == added.StatusId, (retry, added)
+
+batch = c.add_job_status_batch([status(RunId="2", JobStTs="2024-05-01T03:00:00Z"),
+                                status(RunId="3", JobStTs="2024-05-01T04:00:00Z", JobSt="FAIL",
+                                       Metadata={"exitCode": "2"})])
+assert [js.RunId for js in batch] == ["2", "3"], batch
+
+rows = c.get_job_statuses(job_id="od-calc", sort="JobStTs")
+assert [js.RunId for js in rows] == ["1", "2", "3"], rows
+
+page = c.get_job_statuses(job_id="od-calc", sort="JobStTs", limit=2)
+assert isinstance(page, JobStatusPageDto), page
+assert [js.RunId for js in page.JobStatuses] == ["1", "2"] and page.Page.NextOffset == 2, page
+
+failed = c.get_job_statuses(job_id="od-calc", query={"JobSt[in]": "FAIL"})
+assert [js.RunId for js in failed] == ["3"], failed
+assert failed[0].Metadata == {"exitCode": "2"}, failed[0]
+
+beat = c.record_heartbeat(HeartbeatDto(JobId="od-calc", BusDt="2024-05-01", RunId="1", HeartbeatTs="2024-05-01T02:05:00Z"))
+assert beat.StatusId == added.StatusId and beat.HeartbeatTs == "2024-05-01T02:05:00Z", beat
+
+streamed = c.get_job_statuses(job_id="od-calc", stream="ndjson")
+assert len(streamed) == 3 and all(isinstance(js, JobStatusDto) for js in streamed), streamed
+
+try:
+    c.add_job_status(status(BusDt="May 1", RunId="4"))
+    raise AssertionError("an invalid status was added")
+except ApiError as err:
+    assert err.status == 400 and err.error.Code == "PropsError", err
+    assert [fe.Field for fe in err.error.FieldErrors] == ["BusDt"], err.error
@@ -0,0 +1,169 @@
+package jobStatus
+
+import (
+	"strings"
+	"time"
+)
+
+// FilterOp compares a field to a filter's values.
+type FilterOp string
+
+const (
+	FilterEq     FilterOp = "eq"
+	FilterNe     FilterOp = "ne"
+	FilterGt     FilterOp = "gt"
+	FilterGte    FilterOp = "gte"
+	FilterLt     FilterOp = "lt"
+	FilterLte    FilterOp = "lte"
+	FilterIn     FilterOp = "in"
+	FilterPrefix FilterOp = "prefix"
+)
+
+// Filter limits keep ad-hoc queries to something the database can plan quickly.
+const (
+	MaxFilters      = 20
+	MaxFilterValues = 50
+)
+
+// Filter is one condition on a field. Values has one value except for FilterIn. Values are
+// strings for ID and code fields and time.Time for timestamp and date fields.
+type Filter struct {
+	Field  FieldName
+	Op     FilterOp
+	Values []any
+}
+
+var (
+	stringFilterOps = []FilterOp{FilterEq, FilterNe, FilterIn, FilterPrefix}
+	timeFilterOps   = []FilterOp{FilterEq, FilterGt, FilterGte, FilterLt, FilterLte}
+)
+
+// FilterOps lists the operators each filterable field allows. Timestamps don't allow ne or in
+// because equality on a timestamp is rarely what anyone means.
+var FilterOps = map[FieldName][]FilterOp{
+	FieldApplicationId:      stringFilterOps,
+	FieldJobId:              stringFilterOps,
+	FieldJobStatusCode:      {FilterEq, FilterNe, FilterIn},
+	FieldJobStatusTimestamp: timeFilterOps,
+	FieldBusinessDate:       {FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte, FilterIn},
+	FieldRunId:              stringFilterOps,
+	FieldHostId:             stringFilterOps,
+	FieldReportedJobId:      stringFilterOps,
+	FieldReceivedTimestamp:  timeFilterOps,
+}
+
+// IsFilterOpAllowed is true if FilterOps allows op on field.
+func IsFilterOpAllowed(field FieldName, op FilterOp) bool {
+	for _, allowed := range FilterOps[field] {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches is true if js passes the filter. Repos that don't filter in a query language use it,
+// and it's the reference for the ones that do.
+func (f Filter) Matches(js JobStatus) bool {
+	switch v := filterValue(js, f.Field).(type) {
+	case string:
+		return matchString(v, f.Op, f.Values)
+	case time.Time:
+		return matchTime(v, f.Op, f.Values)
+	}
+	return false
+}
+
+// MatchesAll is true if js passes every filter.
+func MatchesAll(js JobStatus, filters []Filter) bool {
+	for _, f := range filters {
+		if !f.Matches(js) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterValue returns the value filters compare. A missing ReceivedTimestamp is treated as in KnownAt.
+func filterValue(js JobStatus, field FieldName) any {
+	switch field {
+	case FieldApplicationId:
+		return js.ApplicationId
+	case FieldJobId:
+		return string(js.JobId)
+	case FieldJobStatusCode:
+		return string(js.JobStatusCode)
+	case FieldJobStatusTimestamp:
+		return js.JobStatusTimestamp
+	case FieldBusinessDate:
+		return js.BusinessDate
+	case FieldRunId:
+		return string(js.RunId)
+	case FieldHostId:
+		return string(js.HostId)
+	case FieldReportedJobId:
+		return string(js.ReportedJobId)
+	case FieldReceivedTimestamp:
+		return js.receivedOrReported()
+	}
+	return nil
+}
+
+func matchString(v string, op FilterOp, values []any) bool {
+	switch op {
+	case FilterEq, FilterIn:
+		for _, want := range values {
+			if v == want {
+				return true
+			}
+		}
+		return false
+	case FilterNe:
+		return v != values[0]
+	case FilterPrefix:
+		prefix, _ := values[0].(string)
+		return strings.HasPrefix(v, prefix)
+	}
+	return false
+}
+
+func matchTime(v time.Time, op FilterOp, values []any) bool {
+	switch op {
+	case FilterEq, FilterIn:
+		for _, want := range values {
+			if t, ok := want.(time.Time); ok && v.Equal(t) {
+				return true
+			}
+		}
+		return false
+	}
+
+	t, ok := values[0].(time.Time)
+	if !ok {
+		return false
+	}
+	switch op {
+	case FilterNe:
+		return !v.Equal(t)
+	case FilterGt:
+		return v.After(t)
+	case FilterGte:
+		return !v.Before(t)
+	case FilterLt:
+		return v.Before(t)
+	case FilterLte:
+		return !v.After(t)
+	}
+	return false
+}
+
+// hasIndexedFilter is true if filters narrow on JobId or BusinessDate, which lead the
+// "JobStatus" indexes. Queries by filters alone need one so they can't scan the whole table.
+func hasIndexedFilter(filters []Filter) bool {
+	for _, f := range filters {
+		if (f.Field == FieldJobId || f.Field == FieldBusinessDate) && f.Op != FilterNe {
+			return true
+		}
+	}
+	return false
+}
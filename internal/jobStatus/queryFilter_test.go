@@ -0,0 +1,159 @@
+package jobStatus
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+func TestParseFilters(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	d1 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	d2 := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	tooMany := map[string]string{}
+	for i := 0; i <= MaxFilters; i++ {
+		tooMany[fmt.Sprintf("JobId[eq%d]", i)] = "x"
+	}
+
+	for _, tc := range []struct {
+		name    string
+		params  map[string]string
+		want    []Filter
+		wantErr string // empty means the filters parse
+	}{
+		{"none", nil, nil, ""},
+		{"string eq", map[string]string{"JobId[eq]": "job1"}, []Filter{{FieldJobId, FilterEq, []any{"job1"}}}, ""},
+		{"timestamp", map[string]string{"JobStTs[gte]": "2024-03-01T12:30:00Z"}, []Filter{{FieldJobStatusTimestamp, FilterGte, []any{ts}}}, ""},
+		{"date in", map[string]string{"BusDt[in]": "2024-03-01, 2024-03-02"}, []Filter{{FieldBusinessDate, FilterIn, []any{d1, d2}}}, ""},
+		{"status code", map[string]string{"JobSt[ne]": "FAIL"}, []Filter{{FieldJobStatusCode, FilterNe, []any{"FAIL"}}}, ""},
+		{"comma outside in", map[string]string{"HostId[prefix]": "a,b"}, []Filter{{FieldHostId, FilterPrefix, []any{"a,b"}}}, ""},
+		{
+			"sorted by key",
+			map[string]string{"RunId[eq]": "r1", "AppId[eq]": "app1", "JobId[prefix]": "nightly"},
+			[]Filter{{FieldApplicationId, FilterEq, []any{"app1"}}, {FieldJobId, FilterPrefix, []any{"nightly"}}, {FieldRunId, FilterEq, []any{"r1"}}},
+			"",
+		},
+
+		{"unknown field", map[string]string{"Nope[eq]": "x"}, nil, `can't filter "Nope[eq]"`},
+		{"no op", map[string]string{"JobId": "x"}, nil, `can't filter "JobId"`},
+		{"op not allowed", map[string]string{"JobStTs[ne]": "2024-03-01T12:30:00Z"}, nil, `can't filter "JobStTs[ne]"`},
+		{"field not filterable", map[string]string{"Links[eq]": "x"}, nil, `can't filter "Links[eq]"`},
+		{"empty value", map[string]string{"JobId[eq]": ""}, nil, "can't be empty"},
+		{"empty in value", map[string]string{"JobId[in]": "a,,b"}, nil, "can't be empty"},
+		{"bad timestamp", map[string]string{"RecvTs[lt]": "yesterday"}, nil, "RecvTs[lt]"},
+		{"bad date", map[string]string{"BusDt[eq]": "03/01/2024"}, nil, "BusDt[eq]"},
+		{"bad status code", map[string]string{"JobSt[eq]": "DONE"}, nil, "not a JobStatusCode"},
+		{"too many filters", tooMany, nil, "at most"},
+		{"too many values", map[string]string{"JobId[in]": strings.Repeat("x,", MaxFilterValues) + "x"}, nil, "more than"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseFilters(tc.params)
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Errorf("got %v, want no error", err)
+			case tc.wantErr != "" && err == nil:
+				t.Errorf("got no error, want one containing %q", tc.wantErr)
+			case tc.wantErr != "" && !strings.Contains(err.Error(), tc.wantErr):
+				t.Errorf("got %v, want an error containing %q", err, tc.wantErr)
+			case err != nil && common.ErrorCode(err) != common.ErrcdDomainProps:
+				t.Errorf("got code %s, want %s", common.ErrorCode(err), common.ErrcdDomainProps)
+			case err == nil && !reflect.DeepEqual(got, tc.want):
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	reported := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := reported.Add(-time.Minute)
+	after := reported.Add(time.Minute)
+	js := JobStatus{
+		ApplicationId:      "app1",
+		JobId:              "nightly-load",
+		JobStatusCode:      JobStatus_SUCCEED,
+		JobStatusTimestamp: reported,
+		BusinessDate:       time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		RunId:              "run1",
+	}
+	received := js
+	received.ReceivedTimestamp = after
+
+	for _, tc := range []struct {
+		name   string
+		js     JobStatus
+		filter Filter
+		want   bool
+	}{
+		{"eq", js, Filter{FieldJobId, FilterEq, []any{"nightly-load"}}, true},
+		{"eq other", js, Filter{FieldJobId, FilterEq, []any{"nightly"}}, false},
+		{"ne", js, Filter{FieldApplicationId, FilterNe, []any{"app2"}}, true},
+		{"ne same", js, Filter{FieldApplicationId, FilterNe, []any{"app1"}}, false},
+		{"in", js, Filter{FieldJobStatusCode, FilterIn, []any{"FAIL", "SUCCEED"}}, true},
+		{"in none", js, Filter{FieldJobStatusCode, FilterIn, []any{"FAIL", "START"}}, false},
+		{"prefix", js, Filter{FieldJobId, FilterPrefix, []any{"nightly"}}, true},
+		{"prefix other", js, Filter{FieldJobId, FilterPrefix, []any{"load"}}, false},
+		{"empty host", js, Filter{FieldHostId, FilterEq, []any{""}}, true},
+
+		{"time eq", js, Filter{FieldJobStatusTimestamp, FilterEq, []any{reported}}, true},
+		{"time gt", js, Filter{FieldJobStatusTimestamp, FilterGt, []any{before}}, true},
+		{"time gt same", js, Filter{FieldJobStatusTimestamp, FilterGt, []any{reported}}, false},
+		{"time gte same", js, Filter{FieldJobStatusTimestamp, FilterGte, []any{reported}}, true},
+		{"time lt", js, Filter{FieldJobStatusTimestamp, FilterLt, []any{after}}, true},
+		{"time lte same", js, Filter{FieldJobStatusTimestamp, FilterLte, []any{reported}}, true},
+		{"time lte before", js, Filter{FieldJobStatusTimestamp, FilterLte, []any{before}}, false},
+		{"time in another zone", js, Filter{FieldJobStatusTimestamp, FilterEq, []any{reported.In(time.FixedZone("x", 3600))}}, true},
+		{"date ne", js, Filter{FieldBusinessDate, FilterNe, []any{js.BusinessDate}}, false},
+		{"time value not a time", js, Filter{FieldJobStatusTimestamp, FilterGt, []any{"2024-01-01"}}, false},
+
+		// a missing ReceivedTimestamp falls back to JobStatusTimestamp
+		{"received missing", js, Filter{FieldReceivedTimestamp, FilterEq, []any{reported}}, true},
+		{"received set", received, Filter{FieldReceivedTimestamp, FilterEq, []any{reported}}, false},
+		{"received set gt", received, Filter{FieldReceivedTimestamp, FilterGt, []any{reported}}, true},
+
+		{"not filterable", js, Filter{FieldLinks, FilterEq, []any{""}}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.Matches(tc.js); got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	all := []Filter{{FieldJobId, FilterPrefix, []any{"nightly"}}, {FieldJobStatusCode, FilterEq, []any{"SUCCEED"}}}
+	if !MatchesAll(js, all) {
+		t.Errorf("MatchesAll: got false, want true")
+	}
+	if MatchesAll(js, append(all, Filter{FieldRunId, FilterEq, []any{"run2"}})) {
+		t.Errorf("MatchesAll with a failing filter: got true, want false")
+	}
+	if !MatchesAll(js, nil) {
+		t.Errorf("MatchesAll with no filters: got false, want true")
+	}
+}
+
+func TestHasIndexedFilter(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		filters []Filter
+		want    bool
+	}{
+		{"none", nil, false},
+		{"job id", []Filter{{FieldJobId, FilterPrefix, []any{"x"}}}, true},
+		{"business date", []Filter{{FieldBusinessDate, FilterGte, []any{time.Time{}}}}, true},
+		{"job id ne", []Filter{{FieldJobId, FilterNe, []any{"x"}}}, false},
+		{"other fields", []Filter{{FieldApplicationId, FilterEq, []any{"x"}}, {FieldJobStatusCode, FilterEq, []any{"FAIL"}}}, false},
+		{"among others", []Filter{{FieldApplicationId, FilterEq, []any{"x"}}, {FieldBusinessDate, FilterIn, []any{time.Time{}}}}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := hasIndexedFilter(tc.filters); got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package jobStatus
+
+import "time"
+
+// FieldName names a JobStatus field for sparse field selection.
+type FieldName string
+
+const (
+	FieldStatusId           FieldName = "StatusId"
+	FieldApplicationId      FieldName = "ApplicationId"
+	FieldJobId              FieldName = "JobId"
+	FieldJobStatusCode      FieldName = "JobStatusCode"
+	FieldJobStatusTimestamp FieldName = "JobStatusTimestamp"
+	FieldBusinessDate       FieldName = "BusinessDate"
+	FieldRunId              FieldName = "RunId"
+	FieldHostId             FieldName = "HostId"
+	FieldReportedJobId      FieldName = "ReportedJobId"
+	FieldReceivedTimestamp  FieldName = "ReceivedTimestamp"
+	FieldLinks              FieldName = "Links"
+	FieldMetadata           FieldName = "Metadata"
+	FieldHeartbeatTimestamp FieldName = "HeartbeatTimestamp"
+)
+
+// AllFields lists every JobStatus field in DTO order.
+var AllFields = []FieldName{
+	FieldStatusId,
+	FieldApplicationId,
+	FieldJobId,
+	FieldJobStatusCode,
+	FieldJobStatusTimestamp,
+	FieldBusinessDate,
+	FieldRunId,
+	FieldHostId,
+	FieldReportedJobId,
+	FieldReceivedTimestamp,
+	FieldLinks,
+	FieldMetadata,
+	FieldHeartbeatTimestamp,
+}
+
+// SortableFields are the fields queries may sort on.
+var SortableFields = map[FieldName]bool{
+	FieldJobStatusTimestamp: true,
+	FieldBusinessDate:       true,
+	FieldJobStatusCode:      true,
+	FieldJobId:              true,
+}
+
+// SortField is one ORDER BY term.
+type SortField struct {
+	Field      FieldName
+	Descending bool
+}
+
+// QueryOptions shapes the results of Repo queries. The zero value returns all fields in no particular order.
+type QueryOptions struct {
+	// Fields limits the fields populated in results. Empty means all fields.
+	Fields []FieldName
+	// Sort orders results by each SortField in turn. Only SortableFields are allowed.
+	Sort []SortField
+	// AllowPartial returns rows that convert even if others don't. The rows come back with a
+	// CommonError coded ErrcdRepoPartialResult wrapping a *PartialResultError.
+	AllowPartial bool
+	// Filters are ANDed with the query's own conditions. Only FilterOps are allowed.
+	Filters []Filter
+	// AsOf limits results to statuses the server had received by then (see JobStatus.KnownAt).
+	// Zero means now.
+	AsOf time.Time
+	// Limit, if it's more than 0, returns at most that many statuses after skipping Offset. Pages
+	// need a stable order, so paged queries sort by Sort and then StatusId.
+	Limit  int
+	Offset int
+}
+
+// SelectedFields returns the fields a query should populate.
+func (opts QueryOptions) SelectedFields() []FieldName {
+	if len(opts.Fields) == 0 {
+		return AllFields
+	}
+	return opts.Fields
+}
@@ -0,0 +1,31 @@
+package jobStatus
+
+import (
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// QuotaUsagePath is the admin route for quota usage.
+const QuotaUsagePath = "/admin/quotas"
+
+type GetQuotaUsageCtrl struct {
+	uc *QuotaUC
+}
+
+func NewGetQuotaUsageCtrl(uc *QuotaUC) *GetQuotaUsageCtrl {
+	return &GetQuotaUsageCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameters appId and busDt.
+func (ctrl *GetQuotaUsageCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	result, err := ctrl.uc.Usage(r.Context(), q.Get("appId"), q.Get("busDt"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
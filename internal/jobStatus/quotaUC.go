@@ -0,0 +1,220 @@
+package jobStatus
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/config"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// QuotaConfig limits how many statuses each application can add per business date. 0 means no limit.
+type QuotaConfig struct {
+	StatusesPerDay int64            `env:"GOJST_QUOTA_STATUSES_PER_DAY" validate:"min=0"`     // for applications not in Overrides
+	Overrides      map[string]int64 `env:"GOJST_QUOTA_OVERRIDES"`                             // by ApplicationId
+	WarnPercent    int64            `env:"GOJST_QUOTA_WARN_PERCENT" validate:"min=0,max=100"` // log a warning when usage reaches this; 0 means 80
+}
+
+func (cfg QuotaConfig) limitFor(applicationId string) int64 {
+	if limit, ok := cfg.Overrides[applicationId]; ok {
+		return limit
+	}
+	return cfg.StatusesPerDay
+}
+
+// QuotaConfigFromEnv reads quota settings:
+//
+//	GOJST_QUOTA_STATUSES_PER_DAY  default limit per application per business date
+//	GOJST_QUOTA_OVERRIDES         per application limits, like "overdrafts=50000,payments=0"
+//	GOJST_QUOTA_WARN_PERCENT      warning threshold (default 80)
+//
+// With none set, every application is unlimited. Every problem is returned, not just the first.
+func QuotaConfigFromEnv(getenv func(string) string) (QuotaConfig, error) {
+	var cfg QuotaConfig
+	var problems config.Problems
+	if s := getenv("GOJST_QUOTA_STATUSES_PER_DAY"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			problems.Add(fmt.Errorf("GOJST_QUOTA_STATUSES_PER_DAY %q must be a whole number", s))
+		}
+		cfg.StatusesPerDay = n
+	}
+	if s := getenv("GOJST_QUOTA_OVERRIDES"); s != "" {
+		cfg.Overrides = map[string]int64{}
+		for _, item := range strings.Split(s, ",") {
+			appId, limit, ok := strings.Cut(strings.TrimSpace(item), "=")
+			n, err := strconv.ParseInt(limit, 10, 64)
+			if !ok || appId == "" || err != nil || n < 0 {
+				problems.Add(fmt.Errorf("GOJST_QUOTA_OVERRIDES item %q must be appId=limit", item))
+				continue
+			}
+			cfg.Overrides[appId] = n
+		}
+	}
+	if s := getenv("GOJST_QUOTA_WARN_PERCENT"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			problems.Add(fmt.Errorf("GOJST_QUOTA_WARN_PERCENT %q must be a whole number from 1 to 100", s))
+		}
+		cfg.WarnPercent = n
+	}
+
+	problems.Add(config.Validate(cfg))
+	if err := problems.Err(); err != nil {
+		return QuotaConfig{}, err
+	}
+	return cfg, nil
+}
+
+// quotaRetainDays is how far behind the newest business date counts are kept in memory.
+// Older dates are counted from the repo again if a late status arrives.
+const quotaRetainDays = 7
+
+type quotaKey struct {
+	applicationId string
+	businessDate  string
+}
+
+type quotaCount struct {
+	n      int64
+	warned bool
+}
+
+// QuotaUC enforces QuotaConfig when statuses are added and reports usage. Counts start from
+// the repo and are kept in memory after that, so with several API instances each one only
+// sees its own adds until it re-reads the repo; the limit is approximate in that case.
+type QuotaUC struct {
+	repo   QuotaRepo
+	cfg    QuotaConfig
+	mu     sync.Mutex
+	counts map[quotaKey]*quotaCount
+	newest time.Time
+}
+
+func NewQuotaUC(repo QuotaRepo, cfg QuotaConfig) *QuotaUC {
+	if cfg.WarnPercent == 0 {
+		cfg.WarnPercent = 80
+	}
+	return &QuotaUC{repo: repo, cfg: cfg, counts: map[quotaKey]*quotaCount{}}
+}
+
+// Reserve counts one status against the application's quota for businessDate. If the quota
+// is used up, it returns a CommonError with code ErrcdQuotaExceeded and counts nothing.
+// Over the warning threshold, it adds a warning to ctx's response (see common.AddWarning).
+// Call Release if the status isn't stored after all.
+func (uc *QuotaUC) Reserve(ctx context.Context, applicationId string, businessDate time.Time) error {
+	limit := uc.cfg.limitFor(applicationId)
+	if limit == 0 {
+		return nil
+	}
+
+	key := quotaKey{applicationId: applicationId, businessDate: businessDate.Format(dto.DateFormat)}
+	return uc.withCount(ctx, key, businessDate, func(count *quotaCount) error {
+		if count.n >= limit {
+			return common.NewCommonError(common.ErrcdQuotaExceeded,
+				fmt.Errorf("application %q has used its quota of %d statuses for %s", applicationId, limit, key.businessDate))
+		}
+		count.n++
+		if count.n*100 >= limit*uc.cfg.WarnPercent {
+			common.AddWarning(ctx, fmt.Sprintf("application %q has reached %d%% of its quota of %d statuses for %s", applicationId, uc.cfg.WarnPercent, limit, key.businessDate))
+			if !count.warned {
+				count.warned = true
+				log.Printf("QUOTA WARNING: application %q has used %d of %d statuses for %s", applicationId, count.n, limit, key.businessDate)
+			}
+		}
+		return nil
+	})
+}
+
+// Release undoes a Reserve.
+func (uc *QuotaUC) Release(applicationId string, businessDate time.Time) {
+	if uc.cfg.limitFor(applicationId) == 0 {
+		return
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if count, ok := uc.counts[quotaKey{applicationId: applicationId, businessDate: businessDate.Format(dto.DateFormat)}]; ok && count.n > 0 {
+		count.n--
+	}
+}
+
+// Usage returns an application's count and limit for a business date (DTO date string).
+func (uc *QuotaUC) Usage(ctx context.Context, applicationId string, businessDate string) (dto.QuotaUsageDto, error) {
+	if len(applicationId) == 0 {
+		return dto.QuotaUsageDto{}, propsError("ApplicationId is required")
+	}
+	busDt, err := parseDateProp("BusinessDate", businessDate)
+	if err != nil {
+		return dto.QuotaUsageDto{}, err
+	}
+
+	key := quotaKey{applicationId: applicationId, businessDate: busDt.Format(dto.DateFormat)}
+	limit := uc.cfg.limitFor(applicationId)
+	var n int64
+	if limit == 0 {
+		// unlimited applications aren't tracked, so ask the repo
+		if n, err = uc.repo.CountByApplicationBusinessDate(ctx, applicationId, busDt); err != nil {
+			return dto.QuotaUsageDto{}, err
+		}
+	} else if err := uc.withCount(ctx, key, busDt, func(count *quotaCount) error {
+		n = count.n
+		return nil
+	}); err != nil {
+		return dto.QuotaUsageDto{}, err
+	}
+
+	return dto.QuotaUsageDto{
+		ApplicationId: applicationId,
+		BusinessDate:  key.businessDate,
+		StatusCount:   n,
+		StatusLimit:   limit,
+	}, nil
+}
+
+// withCount calls fn with key's count, holding uc.mu. A count that isn't in memory is read from
+// the repo first, without the lock so one slow count doesn't block other applications. fn runs in
+// the same lock hold that finds or adds the count, so a prune can't drop it in between.
+func (uc *QuotaUC) withCount(ctx context.Context, key quotaKey, businessDate time.Time, fn func(count *quotaCount) error) error {
+	uc.mu.Lock()
+	if count, ok := uc.counts[key]; ok {
+		defer uc.mu.Unlock()
+		return fn(count)
+	}
+	uc.mu.Unlock()
+
+	n, err := uc.repo.CountByApplicationBusinessDate(ctx, key.applicationId, businessDate)
+	if err != nil {
+		return err
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	count, ok := uc.counts[key]
+	if !ok {
+		count = &quotaCount{n: n}
+		uc.counts[key] = count
+	}
+	if businessDate.After(uc.newest) {
+		uc.newest = businessDate
+		uc.prune()
+	}
+	return fn(count)
+}
+
+// prune drops counts for business dates more than quotaRetainDays before the newest. Callers hold uc.mu.
+func (uc *QuotaUC) prune() {
+	cutoff := uc.newest.AddDate(0, 0, -quotaRetainDays).Format(dto.DateFormat)
+	for key := range uc.counts {
+		// DateFormat strings sort in date order
+		if key.businessDate < cutoff {
+			delete(uc.counts, key)
+		}
+	}
+}
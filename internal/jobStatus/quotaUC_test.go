@@ -0,0 +1,91 @@
+package jobStatus_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// countRepo answers every count with n after pause. With a pause, concurrent Reserves all miss
+// the in-memory count and race to load it.
+type countRepo struct {
+	n     int64
+	pause time.Duration
+}
+
+func (repo countRepo) CountByApplicationBusinessDate(ctx context.Context, applicationId string, businessDate time.Time) (int64, error) {
+	time.Sleep(repo.pause)
+	return repo.n, nil
+}
+
+func TestQuotaReserveCountsConcurrentAddsOnce(t *testing.T) {
+	uc := jobStatus.NewQuotaUC(countRepo{n: 10, pause: time.Millisecond}, jobStatus.QuotaConfig{StatusesPerDay: 50})
+	busDt := time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved := 0
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := uc.Reserve(context.Background(), "overdrafts", busDt)
+			if err != nil && common.ErrorCode(err) != common.ErrcdQuotaExceeded {
+				t.Errorf("Reserve: %v", err)
+				return
+			}
+			if err == nil {
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved != 40 {
+		t.Errorf("got %d reservations, want 40 (limit 50, 10 stored)", reserved)
+	}
+	usage, err := uc.Usage(context.Background(), "overdrafts", busDt.Format(dto.DateFormat))
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if usage.StatusCount != 50 {
+		t.Errorf("Usage: got %d, want 50", usage.StatusCount)
+	}
+}
+
+func TestQuotaReserveAndUsageSurvivePrunes(t *testing.T) {
+	uc := jobStatus.NewQuotaUC(countRepo{}, jobStatus.QuotaConfig{StatusesPerDay: 100000})
+	oldDt := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// reserving later dates prunes oldDt's count while other calls are loading or using it
+	var wg sync.WaitGroup
+	for i := 0; i < 1000; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if err := uc.Reserve(context.Background(), "overdrafts", oldDt); err != nil {
+				t.Errorf("Reserve old date: %v", err)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			if err := uc.Reserve(context.Background(), "overdrafts", oldDt.AddDate(0, 0, 8+i)); err != nil {
+				t.Errorf("Reserve new date: %v", err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := uc.Usage(context.Background(), "overdrafts", oldDt.Format(dto.DateFormat)); err != nil {
+				t.Errorf("Usage: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
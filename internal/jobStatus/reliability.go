@@ -0,0 +1,106 @@
+package jobStatus
+
+import (
+	"sort"
+	"time"
+)
+
+// MaxReliabilityDays bounds a reliability window. Reliability is computed from raw statuses, not
+// rollups, so the window is limited like an on-demand rollup.
+const MaxReliabilityDays = 93
+
+// Flakiness ranking defaults. A job needs a few runs before alternating means anything: two runs,
+// one FAIL and one SUCCEED, would otherwise be as flaky as possible.
+const (
+	DefaultFlakinessMinRuns = 5
+	DefaultFlakinessLimit   = 20
+	MaxFlakinessLimit       = 200
+)
+
+// JobReliability is one job's failure and recovery history over a window of business dates,
+// from its SUCCEED and FAIL statuses in time order (STARTs are ignored).
+//
+// A failure starts at a FAIL that isn't preceded by another FAIL, so a job that fails three
+// times in a row has one failure. It's recovered by the next SUCCEED; the recovery time is from
+// the failure's first FAIL to that SUCCEED. The time between failures is from one failure's
+// start to the next one's. Only statuses in the window count, so a failure still open at the
+// end isn't recovered, and a SUCCEED that ends a failure from before the window isn't a recovery.
+// TransitionCount is how many times the code changed from one SUCCEED or FAIL to the next.
+type JobReliability struct {
+	ApplicationId        string
+	JobId                JobIdType
+	FromDate             time.Time
+	ToDate               time.Time
+	TerminalCount        int64
+	TransitionCount      int64
+	FailureCount         int64
+	RecoveryCount        int64
+	TotalRecovery        time.Duration
+	FailureIntervalCount int64
+	TotalBetweenFailures time.Duration
+}
+
+// Mttr is the mean time to recovery, or 0 if no failure recovered.
+func (jr JobReliability) Mttr() time.Duration {
+	if jr.RecoveryCount == 0 {
+		return 0
+	}
+	return jr.TotalRecovery / time.Duration(jr.RecoveryCount)
+}
+
+// Mtbf is the mean time between failure starts, or 0 with fewer than two failures.
+func (jr JobReliability) Mtbf() time.Duration {
+	if jr.FailureIntervalCount == 0 {
+		return 0
+	}
+	return jr.TotalBetweenFailures / time.Duration(jr.FailureIntervalCount)
+}
+
+// Flakiness is the share of consecutive SUCCEED and FAIL pairs that differ, from 0 (never
+// alternates) to 1 (alternates every time). Each SUCCEED or FAIL ends a run, so it's how often a
+// run's outcome differs from the run before it. It's 0 with fewer than two.
+func (jr JobReliability) Flakiness() float64 {
+	if jr.TerminalCount < 2 {
+		return 0
+	}
+	return float64(jr.TransitionCount) / float64(jr.TerminalCount-1)
+}
+
+// ComputeJobReliability computes reliability for one job from its statuses, in any order. Repos
+// that can't use SQL window functions use it; repoDB computes the same thing in SQL.
+func ComputeJobReliability(applicationId string, jobId JobIdType, from time.Time, to time.Time, jss []JobStatus) JobReliability {
+	jr := JobReliability{ApplicationId: applicationId, JobId: jobId, FromDate: from, ToDate: to}
+
+	var terminal []JobStatus
+	for _, js := range jss {
+		if js.JobStatusCode == JobStatus_SUCCEED || js.JobStatusCode == JobStatus_FAIL {
+			terminal = append(terminal, js)
+		}
+	}
+	sort.SliceStable(terminal, func(i, j int) bool {
+		return terminal[i].JobStatusTimestamp.Before(terminal[j].JobStatusTimestamp)
+	})
+	jr.TerminalCount = int64(len(terminal))
+
+	var failStart, prevFailStart time.Time
+	var prevCode JobStatusCodeType
+	for _, js := range terminal {
+		if prevCode != "" && js.JobStatusCode != prevCode {
+			jr.TransitionCount++
+		}
+		switch {
+		case js.JobStatusCode == JobStatus_FAIL && prevCode != JobStatus_FAIL:
+			jr.FailureCount++
+			if !prevFailStart.IsZero() {
+				jr.FailureIntervalCount++
+				jr.TotalBetweenFailures += js.JobStatusTimestamp.Sub(prevFailStart)
+			}
+			failStart, prevFailStart = js.JobStatusTimestamp, js.JobStatusTimestamp
+		case js.JobStatusCode == JobStatus_SUCCEED && prevCode == JobStatus_FAIL:
+			jr.RecoveryCount++
+			jr.TotalRecovery += js.JobStatusTimestamp.Sub(failStart)
+		}
+		prevCode = js.JobStatusCode
+	}
+	return jr
+}
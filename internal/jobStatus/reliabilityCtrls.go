@@ -0,0 +1,91 @@
+package jobStatus
+
+import (
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+type GetJobReliabilityCtrl struct {
+	uc *ReliabilityUC
+}
+
+func NewGetJobReliabilityCtrl(uc *ReliabilityUC) *GetJobReliabilityCtrl {
+	return &GetJobReliabilityCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameters fromDt, toDt, and optional appId and jobId.
+func (ctrl *GetJobReliabilityCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	result, err := ctrl.uc.Get(r.Context(), q.Get("appId"), q.Get("jobId"), q.Get("fromDt"), q.Get("toDt"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
+
+type GetJobFlakinessCtrl struct {
+	uc *ReliabilityUC
+}
+
+func NewGetJobFlakinessCtrl(uc *ReliabilityUC) *GetJobFlakinessCtrl {
+	return &GetJobFlakinessCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameters appId, fromDt, toDt, and optional minRuns and limit.
+func (ctrl *GetJobFlakinessCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	result, err := ctrl.uc.Flakiest(r.Context(), q.Get("appId"), q.Get("fromDt"), q.Get("toDt"), q.Get("minRuns"), q.Get("limit"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
+
+type GetDurationBaselinesCtrl struct {
+	uc *ReliabilityUC
+}
+
+func NewGetDurationBaselinesCtrl(uc *ReliabilityUC) *GetDurationBaselinesCtrl {
+	return &GetDurationBaselinesCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameters appId, fromDt, toDt, and optional jobId, forDt, and minRuns.
+func (ctrl *GetDurationBaselinesCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	result, err := ctrl.uc.DurationBaselines(r.Context(), q.Get("appId"), q.Get("jobId"), q.Get("fromDt"), q.Get("toDt"), q.Get("forDt"), q.Get("minRuns"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
+
+type GetJobForecastCtrl struct {
+	uc *ForecastUC
+}
+
+func NewGetJobForecastCtrl(uc *ForecastUC) *GetJobForecastCtrl {
+	return &GetJobForecastCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameters appId, jobId, busDt, and optional deadline and asOf.
+func (ctrl *GetJobForecastCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	result, err := ctrl.uc.Forecast(r.Context(), q.Get("appId"), q.Get("jobId"), q.Get("busDt"), q.Get("deadline"), q.Get("asOf"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
@@ -0,0 +1,175 @@
+package jobStatus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+type ReliabilityUC struct {
+	repo ReliabilityRepo
+}
+
+func NewReliabilityUC(repo ReliabilityRepo) *ReliabilityUC {
+	return &ReliabilityUC{repo: repo}
+}
+
+// Get returns reliability for business dates fromDate through toDate (DTO date strings). An empty
+// applicationId or jobId returns all applications or jobs.
+func (uc *ReliabilityUC) Get(ctx context.Context, applicationId string, jobId string, fromDate string, toDate string) ([]dto.JobReliabilityDto, error) {
+	from, to, err := parseDateRange(fromDate, toDate, MaxReliabilityDays)
+	if err != nil {
+		return nil, err
+	}
+
+	jrs, err := uc.repo.GetJobReliability(ctx, applicationId, JobIdType(jobId), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]dto.JobReliabilityDto, len(jrs))
+	for i, jr := range jrs {
+		dtos[i] = jobReliabilityToDto(jr)
+	}
+	return dtos, nil
+}
+
+// Flakiest ranks an application's jobs by flakiness, most flaky first, leaving out jobs with fewer
+// than minRuns SUCCEEDs and FAILs and jobs that never changed. minRuns and limit are query
+// parameter strings; empty means the default.
+func (uc *ReliabilityUC) Flakiest(ctx context.Context, applicationId string, fromDate string, toDate string, minRuns string, limit string) ([]dto.JobFlakinessDto, error) {
+	if len(applicationId) == 0 {
+		return nil, propsError("AppId is required")
+	}
+	from, to, err := parseDateRange(fromDate, toDate, MaxReliabilityDays)
+	if err != nil {
+		return nil, err
+	}
+	minRunCount, err := parseIntParam("minRuns", minRuns, DefaultFlakinessMinRuns, 2, 1000000)
+	if err != nil {
+		return nil, err
+	}
+	maxJobs, err := parseIntParam("limit", limit, DefaultFlakinessLimit, 1, MaxFlakinessLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	jrs, err := uc.repo.GetJobReliability(ctx, applicationId, "", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranked []JobReliability
+	for _, jr := range jrs {
+		if jr.TerminalCount >= int64(minRunCount) && jr.TransitionCount > 0 {
+			ranked = append(ranked, jr)
+		}
+	}
+	// ties go to the job with more evidence, then by JobId so the order is stable
+	sort.Slice(ranked, func(i, j int) bool {
+		fi, fj := ranked[i].Flakiness(), ranked[j].Flakiness()
+		switch {
+		case fi != fj:
+			return fi > fj
+		case ranked[i].TerminalCount != ranked[j].TerminalCount:
+			return ranked[i].TerminalCount > ranked[j].TerminalCount
+		}
+		return ranked[i].JobId < ranked[j].JobId
+	})
+	if len(ranked) > maxJobs {
+		ranked = ranked[:maxJobs]
+	}
+
+	dtos := make([]dto.JobFlakinessDto, len(ranked))
+	for i, jr := range ranked {
+		dtos[i] = dto.JobFlakinessDto{
+			Rank:            i + 1,
+			ApplicationId:   jr.ApplicationId,
+			JobId:           string(jr.JobId),
+			FromDate:        jr.FromDate.Format(dto.DateFormat),
+			ToDate:          jr.ToDate.Format(dto.DateFormat),
+			TerminalCount:   jr.TerminalCount,
+			TransitionCount: jr.TransitionCount,
+			FailureCount:    jr.FailureCount,
+			Flakiness:       jr.Flakiness(),
+		}
+	}
+	return dtos, nil
+}
+
+// DurationBaselines returns an application's duration baselines from business dates fromDate
+// through toDate. With forDate, it returns only the baseline ExpectedDuration picks for that date,
+// one per job, leaving out jobs without a season that has minRuns runs. jobId is optional.
+func (uc *ReliabilityUC) DurationBaselines(ctx context.Context, applicationId string, jobId string, fromDate string, toDate string, forDate string, minRuns string) ([]dto.DurationBaselineDto, error) {
+	if len(applicationId) == 0 {
+		return nil, propsError("AppId is required")
+	}
+	from, to, err := parseDateRange(fromDate, toDate, MaxBaselineDays)
+	if err != nil {
+		return nil, err
+	}
+	minRunCount, err := parseIntParam("minRuns", minRuns, DefaultBaselineMinRuns, 1, 1000000)
+	if err != nil {
+		return nil, err
+	}
+
+	baselines, err := uc.repo.GetDurationBaselines(ctx, applicationId, JobIdType(jobId), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(forDate) > 0 {
+		forDt, err := parseDateProp("ForDate", forDate)
+		if err != nil {
+			return nil, err
+		}
+		// baselines are ordered by JobId, so each job's are together
+		var picked []DurationBaseline
+		for start := 0; start < len(baselines); {
+			end := start
+			for end < len(baselines) && baselines[end].JobId == baselines[start].JobId {
+				end++
+			}
+			if b, ok := ExpectedDuration(baselines[start:end], forDt, int64(minRunCount)); ok {
+				picked = append(picked, b)
+			}
+			start = end
+		}
+		baselines = picked
+	}
+
+	dtos := make([]dto.DurationBaselineDto, len(baselines))
+	for i, b := range baselines {
+		dtos[i] = durationBaselineToDto(b)
+	}
+	return dtos, nil
+}
+
+// parseIntParam parses an optional integer query parameter from low to high.
+func parseIntParam(name string, s string, def int, low int, high int) (int, error) {
+	if len(s) == 0 {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < low || n > high {
+		return 0, propsError(fmt.Sprintf("%s must be a whole number from %d to %d", name, low, high))
+	}
+	return n, nil
+}
+
+func jobReliabilityToDto(jr JobReliability) dto.JobReliabilityDto {
+	return dto.JobReliabilityDto{
+		ApplicationId: jr.ApplicationId,
+		JobId:         string(jr.JobId),
+		FromDate:      jr.FromDate.Format(dto.DateFormat),
+		ToDate:        jr.ToDate.Format(dto.DateFormat),
+		TerminalCount: jr.TerminalCount,
+		FailureCount:  jr.FailureCount,
+		RecoveryCount: jr.RecoveryCount,
+		MttrMs:        jr.Mttr().Milliseconds(),
+		MtbfMs:        jr.Mtbf().Milliseconds(),
+	}
+}
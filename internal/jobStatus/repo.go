@@ -0,0 +1,239 @@
+package jobStatus
+
+import (
+	"context"
+	"time"
+)
+
+// Repo stores and retrieves job statuses. Implementations return CommonErrors. Every port's
+// methods take a ctx first and give up when it's done, so a client that hangs up or times out
+// doesn't leave its query running.
+type Repo interface {
+	Add(ctx context.Context, jobStatus JobStatus) error
+	// AddIdempotent adds jobStatus unless a status with the same key (JobId, JobStatusCode,
+	// BusinessDate, RunId) is already stored. It returns the stored status and whether it added
+	// jobStatus, so a retried add gets what the first try stored instead of ErrcdRepoDupeRow.
+	AddIdempotent(ctx context.Context, jobStatus JobStatus) (JobStatus, bool, error)
+	// AddBatch adds every status or none of them, in one transaction.
+	AddBatch(ctx context.Context, jobStatuses []JobStatus) error
+	GetByJobId(ctx context.Context, jobId JobIdType, opts QueryOptions) ([]JobStatus, error)
+	GetByJobIdBusinessDate(ctx context.Context, jobId JobIdType, businessDate time.Time, opts QueryOptions) ([]JobStatus, error)
+}
+
+// StreamRepo reads job statuses one at a time so large results don't have to fit in memory.
+// fn is called for each status as it's scanned; if fn returns an error, iteration stops and
+// the ForEach method returns that error.
+type StreamRepo interface {
+	ForEachByJobId(ctx context.Context, jobId JobIdType, opts QueryOptions, fn func(JobStatus) error) error
+	ForEachByJobIdBusinessDate(ctx context.Context, jobId JobIdType, businessDate time.Time, opts QueryOptions, fn func(JobStatus) error) error
+}
+
+// FilterRepo runs queries defined only by QueryOptions.Filters. Callers make sure the filters
+// narrow on an indexed field.
+type FilterRepo interface {
+	GetByFilters(ctx context.Context, opts QueryOptions) ([]JobStatus, error)
+	// ForEachByFilters works like the StreamRepo methods.
+	ForEachByFilters(ctx context.Context, opts QueryOptions, fn func(JobStatus) error) error
+}
+
+// RollupRepo maintains and reads the daily rollup summary.
+type RollupRepo interface {
+	// RollupDaily recomputes rollups for business dates from fromDate through toDate (inclusive)
+	// and returns the number of rollup rows written.
+	RollupDaily(ctx context.Context, fromDate time.Time, toDate time.Time) (int64, error)
+	// GetDailyRollups returns rollups for business dates from fromDate through toDate (inclusive).
+	// An empty applicationId returns all applications.
+	GetDailyRollups(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time) ([]DailyRollup, error)
+}
+
+// ReliabilityRepo computes job reliability and duration baselines from raw statuses.
+type ReliabilityRepo interface {
+	// GetJobReliability returns reliability for each job with a SUCCEED or FAIL on business dates
+	// fromDate through toDate (inclusive), ordered by ApplicationId and JobId. An empty
+	// applicationId or jobId doesn't filter.
+	GetJobReliability(ctx context.Context, applicationId string, jobId JobIdType, fromDate time.Time, toDate time.Time) ([]JobReliability, error)
+	// GetDurationBaselines returns an application's duration baselines for business dates fromDate
+	// through toDate (inclusive), one per job and season with a successful run, ordered by JobId
+	// and Season. An empty jobId doesn't filter.
+	GetDurationBaselines(ctx context.Context, applicationId string, jobId JobIdType, fromDate time.Time, toDate time.Time) ([]DurationBaseline, error)
+}
+
+// RunCostRepo stores what runs cost and totals it by job.
+type RunCostRepo interface {
+	// PutRunCost adds the run's cost or replaces the one stored for the same JobId, BusinessDate, and RunId.
+	PutRunCost(ctx context.Context, rc RunCost) error
+	// GetJobCosts returns an application's costs for business dates fromDate through toDate
+	// (inclusive), one per job with a cost for a run that has a status, ordered by JobId.
+	GetJobCosts(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time) ([]JobCost, error)
+}
+
+// RunCommentRepo stores comments on runs.
+type RunCommentRepo interface {
+	AddRunComment(ctx context.Context, rc RunComment) error
+	// ListRunComments returns the comments on a job's runs for one business date, oldest first. An
+	// empty runId returns every run's comments.
+	ListRunComments(ctx context.Context, jobId JobIdType, businessDate time.Time, runId RunIdType) ([]RunComment, error)
+}
+
+// QuotaRepo counts stored statuses so quotas survive restarts.
+type QuotaRepo interface {
+	// CountByApplicationBusinessDate returns how many statuses an application has for one business date.
+	CountByApplicationBusinessDate(ctx context.Context, applicationId string, businessDate time.Time) (int64, error)
+}
+
+// MeterRepo stores API call counts for usage metering.
+type MeterRepo interface {
+	// AddApiCalls adds each count to the stored count for its application, month, and endpoint.
+	AddApiCalls(ctx context.Context, counts []ApiCallCount) error
+	// GetApiCalls returns stored counts for one month (the first day of the month).
+	GetApiCalls(ctx context.Context, month time.Time) ([]ApiCallCount, error)
+}
+
+// SavedViewRepo stores saved views by name.
+type SavedViewRepo interface {
+	// PutSavedView adds the view or replaces the one with the same name.
+	PutSavedView(ctx context.Context, view SavedView) error
+	// GetSavedView returns the named view; ok is false if there isn't one.
+	GetSavedView(ctx context.Context, name string) (view SavedView, ok bool, err error)
+	// ListSavedViews returns every view, ordered by name.
+	ListSavedViews(ctx context.Context) ([]SavedView, error)
+	// DeleteSavedView removes the named view; ok is false if there wasn't one.
+	DeleteSavedView(ctx context.Context, name string) (ok bool, err error)
+}
+
+// ScheduledQueryRepo stores scheduled queries.
+type ScheduledQueryRepo interface {
+	// PutScheduledQuery adds or replaces a query by name. It doesn't change LastRunDate.
+	PutScheduledQuery(ctx context.Context, sq ScheduledQuery) error
+	GetScheduledQuery(ctx context.Context, name string) (sq ScheduledQuery, found bool, err error)
+	// ListScheduledQueries returns every query, ordered by name.
+	ListScheduledQueries(ctx context.Context) ([]ScheduledQuery, error)
+	DeleteScheduledQuery(ctx context.Context, name string) (ok bool, err error)
+	// ClaimScheduledRun sets the query's LastRunDate to runDate if it's earlier, in one
+	// statement, so only one instance runs the query each day. claimed is false if another
+	// instance already did, or the query was deleted.
+	ClaimScheduledRun(ctx context.Context, name string, runDate time.Time) (claimed bool, err error)
+	// ReleaseScheduledRun sets LastRunDate back to previous (none if it's zero) if it's still
+	// runDate, so a run that failed after its claim can be claimed again.
+	ReleaseScheduledRun(ctx context.Context, name string, runDate time.Time, previous time.Time) (released bool, err error)
+}
+
+// SqlRepo runs analyst SQL. Callers validate it with ValidateReadOnlySql first.
+type SqlRepo interface {
+	// QueryReadOnly runs query in a read-only transaction that's canceled after timeout, and
+	// returns at most maxRows rows. Errors in the query itself are props errors.
+	QueryReadOnly(ctx context.Context, query string, maxRows int, timeout time.Duration) (SqlResult, error)
+}
+
+// BoardRepo reads what status boards show.
+type BoardRepo interface {
+	// GetLatestByJobIds returns the most recent status for each job on one business date, in one
+	// query, considering only statuses received by asOf (zero means now). Jobs with no status
+	// that day are left out.
+	GetLatestByJobIds(ctx context.Context, jobIds []JobIdType, businessDate time.Time, asOf time.Time) ([]JobStatus, error)
+	// GetLatestByJobId returns the job's most recent status for each business date from fromDate
+	// through toDate (inclusive), newest date first, considering only statuses received by asOf
+	// (zero means now). Dates with no status are left out.
+	GetLatestByJobId(ctx context.Context, jobId JobIdType, fromDate time.Time, toDate time.Time, asOf time.Time) ([]JobStatus, error)
+}
+
+// JobRenameRepo moves a job's history to another JobId when a scheduler renames it.
+type JobRenameRepo interface {
+	// RenameJob moves every status from `from` to `to`, points saved views and aliases that use
+	// `from` at `to`, and records `from` as an alias of `to`, all in one transaction. If a moved
+	// status would duplicate one already under `to`, nothing changes and the error is coded
+	// ErrcdRepoDupeRow. If `to` is itself an alias, the error is coded ErrcdDomainProps.
+	RenameJob(ctx context.Context, from JobIdType, to JobIdType, at time.Time) (JobRenameResult, error)
+}
+
+// JobAliasRepo stores job aliases. RenameJob also adds them.
+type JobAliasRepo interface {
+	// PutJobAlias adds the alias or replaces the one with the same AliasJobId.
+	PutJobAlias(ctx context.Context, alias JobAlias) error
+	// ListJobAliases returns every alias, ordered by AliasJobId.
+	ListJobAliases(ctx context.Context) ([]JobAlias, error)
+	// DeleteJobAlias removes an alias; ok is false if there wasn't one.
+	DeleteJobAlias(ctx context.Context, aliasJobId JobIdType) (ok bool, err error)
+}
+
+// CorrectionRepo changes and deletes stored statuses and keeps an audit trail of every change.
+type CorrectionRepo interface {
+	// Update locks the status with c.StatusId, calls correct with it, and stores what correct
+	// returns in its place, along with c with Before and After set, all in one transaction. Errors
+	// from correct are returned as is. If there's no such status, the error is coded
+	// ErrcdNotFound; if the corrected status duplicates another's natural key, ErrcdRepoDupeRow.
+	Update(ctx context.Context, c StatusCorrection, correct func(before JobStatus) (JobStatus, error)) (StatusCorrection, error)
+	// DeleteByKey deletes the status with key and stores c with StatusId and Before set, in one
+	// transaction. If there's no such status, the error is coded ErrcdNotFound.
+	DeleteByKey(ctx context.Context, c StatusCorrection, key StatusKey) (StatusCorrection, error)
+	// ListStatusCorrections returns a status's corrections, oldest first.
+	ListStatusCorrections(ctx context.Context, statusId StatusIdType) ([]StatusCorrection, error)
+}
+
+// HeartbeatRepo records that a run is still going.
+type HeartbeatRepo interface {
+	// RecordHeartbeat sets HeartbeatTimestamp on the START status with key to at, unless it
+	// already has a later one, and returns the status. If there's no such status, the error is
+	// coded ErrcdNotFound.
+	RecordHeartbeat(ctx context.Context, key StatusKey, at time.Time) (JobStatus, error)
+}
+
+// IntegrityRepo reads stored statuses with their integrity hashes so they can be verified.
+type IntegrityRepo interface {
+	// ForEachWithIntegrityHash calls fn for each of an application's statuses on business dates
+	// fromDate through toDate (inclusive), ordered by BusinessDate and StatusId, with
+	// IntegrityHash set. It works like the StreamRepo methods.
+	ForEachWithIntegrityHash(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time, fn func(JobStatus) error) error
+}
+
+// RetentionRepo deletes statuses that are past their retention.
+type RetentionRepo interface {
+	// DeleteBefore deletes up to limit statuses whose BusinessDate is before their application's
+	// cutoff and returns how many it deleted. An application's cutoff is cutoffs[ApplicationId] if
+	// it's in cutoffs, and cutoff if it isn't. A zero cutoff deletes none of that application's
+	// statuses.
+	DeleteBefore(ctx context.Context, cutoff time.Time, cutoffs map[string]time.Time, limit int) (int64, error)
+}
+
+// SnapshotRepo reads everything a DR snapshot holds.
+type SnapshotRepo interface {
+	// Snapshot calls fn with a reader whose reads all see the same committed data, however long
+	// fn takes and whatever is written meanwhile, so a snapshot isn't torn between tables.
+	Snapshot(ctx context.Context, fn func(SnapshotReader) error) error
+}
+
+// SnapshotReader reads inside a snapshot. It's only valid until the Snapshot call's fn returns,
+// and its reads use the Snapshot call's ctx.
+type SnapshotReader interface {
+	// ForEachJobStatus calls fn for every status, ordered by BusinessDate and StatusId, with
+	// IntegrityHash set. It works like the StreamRepo methods.
+	ForEachJobStatus(fn func(JobStatus) error) error
+	ListSavedViews() ([]SavedView, error)
+	ListScheduledQueries() ([]ScheduledQuery, error)
+	ListJobAliases() ([]JobAlias, error)
+}
+
+// FullRepo is every repo port. Wrappers that decorate the whole repo, like the chaos and retry
+// repos, wrap a FullRepo.
+type FullRepo interface {
+	Repo
+	StreamRepo
+	FilterRepo
+	RollupRepo
+	ReliabilityRepo
+	RunCostRepo
+	RunCommentRepo
+	QuotaRepo
+	MeterRepo
+	SavedViewRepo
+	ScheduledQueryRepo
+	BoardRepo
+	JobRenameRepo
+	JobAliasRepo
+	CorrectionRepo
+	HeartbeatRepo
+	SqlRepo
+	IntegrityRepo
+	SnapshotRepo
+	RetentionRepo
+}
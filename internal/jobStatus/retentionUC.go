@@ -0,0 +1,152 @@
+package jobStatus
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/config"
+)
+
+// MinRetentionDays is the shortest retention allowed. Quota counts and the nightly rollup read
+// back about a week, so younger statuses are still in use.
+const MinRetentionDays = 7
+
+// retentionBatchSize is how many statuses each DeleteBefore deletes, so the first purge of a big
+// table is many short transactions instead of one long one.
+const retentionBatchSize = 10_000
+
+// RetentionConfig says how many days of statuses to keep, by business date. 0 keeps them forever.
+type RetentionConfig struct {
+	Days      int            `env:"GOJST_RETENTION_DAYS"`      // for applications not in Overrides
+	Overrides map[string]int `env:"GOJST_RETENTION_OVERRIDES"` // by ApplicationId
+}
+
+// On is true if any application's statuses are ever deleted.
+func (cfg RetentionConfig) On() bool {
+	if cfg.Days > 0 {
+		return true
+	}
+	for _, days := range cfg.Overrides {
+		if days > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// RetentionConfigFromEnv reads retention settings:
+//
+//	GOJST_RETENTION_DAYS       days to keep for applications without an override
+//	GOJST_RETENTION_OVERRIDES  per application days, like "overdrafts=400,payments=0"
+//
+// Days are 0 (keep forever) or at least MinRetentionDays. With none set, nothing is deleted.
+// Every problem is returned, not just the first.
+func RetentionConfigFromEnv(getenv func(string) string) (RetentionConfig, error) {
+	var cfg RetentionConfig
+	var problems config.Problems
+	if s := getenv("GOJST_RETENTION_DAYS"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || !validRetentionDays(n) {
+			problems.Add(fmt.Errorf("GOJST_RETENTION_DAYS %q must be 0 or a whole number from %d", s, MinRetentionDays))
+		}
+		cfg.Days = n
+	}
+	if s := getenv("GOJST_RETENTION_OVERRIDES"); s != "" {
+		cfg.Overrides = map[string]int{}
+		for _, item := range strings.Split(s, ",") {
+			appId, days, ok := strings.Cut(strings.TrimSpace(item), "=")
+			n, err := strconv.Atoi(days)
+			if !ok || appId == "" || err != nil || !validRetentionDays(n) {
+				problems.Add(fmt.Errorf("GOJST_RETENTION_OVERRIDES item %q must be appId=days, with days 0 or from %d", item, MinRetentionDays))
+				continue
+			}
+			cfg.Overrides[appId] = n
+		}
+	}
+
+	if err := problems.Err(); err != nil {
+		return RetentionConfig{}, err
+	}
+	return cfg, nil
+}
+
+func validRetentionDays(n int) bool {
+	return n == 0 || n >= MinRetentionDays
+}
+
+// RetentionUC deletes statuses that are past their application's retention.
+type RetentionUC struct {
+	repo RetentionRepo
+	cfg  RetentionConfig
+}
+
+func NewRetentionUC(repo RetentionRepo, cfg RetentionConfig) *RetentionUC {
+	return &RetentionUC{repo: repo, cfg: cfg}
+}
+
+// Purge deletes statuses whose business date is more than their retention's days before now's
+// date, a batch at a time until none are left or ctx is done, and returns how many it deleted.
+func (uc *RetentionUC) Purge(ctx context.Context, now time.Time) (int64, error) {
+	today := TruncateToDate(now)
+	cutoff := retentionCutoff(today, uc.cfg.Days)
+	cutoffs := make(map[string]time.Time, len(uc.cfg.Overrides))
+	for applicationId, days := range uc.cfg.Overrides {
+		cutoffs[applicationId] = retentionCutoff(today, days)
+	}
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		n, err := uc.repo.DeleteBefore(ctx, cutoff, cutoffs, retentionBatchSize)
+		total += n
+		if err != nil || n < retentionBatchSize {
+			return total, err
+		}
+	}
+}
+
+// retentionCutoff is the first business date kept, or zero to keep every date.
+func retentionCutoff(today time.Time, days int) time.Time {
+	if days <= 0 {
+		return time.Time{}
+	}
+	return today.AddDate(0, 0, -days)
+}
+
+// RunNightlyRetention blocks, purging once a day at hour:minute local time until ctx is done. While
+// paused (if it isn't nil) returns true, runs are skipped.
+func RunNightlyRetention(ctx context.Context, uc *RetentionUC, hour int, minute int, paused func() bool) {
+	for {
+		now := time.Now()
+		timer := time.NewTimer(nextRunTime(now, hour, minute).Sub(now))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if paused != nil && paused() {
+			log.Printf("retention purge skipped; paused")
+			continue
+		}
+		var n int64
+		err := common.CatchPanic("retention purge", func() (err error) {
+			n, err = uc.Purge(ctx, time.Now())
+			return err
+		})
+		if err != nil {
+			log.Printf("retention purge failed after deleting %d statuses: %v", n, err)
+			continue
+		}
+		log.Printf("retention purge deleted %d statuses", n)
+	}
+}
@@ -0,0 +1,525 @@
+// Package retry wraps a repo so calls that fail for a moment (a dropped connection, a failover, a
+// serialization failure or deadlock) are tried again with backoff instead of failing the request.
+package retry
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/config"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+)
+
+// Config controls retries. Zero fields get the defaults.
+type Config struct {
+	// MaxAttempts is how many times a call is tried, including the first (default 3). 1 turns
+	// retries off.
+	MaxAttempts int `env:"GOJST_DB_RETRY_MAX_ATTEMPTS" validate:"min=0,max=10"`
+	// BackoffMin is the wait before the first retry, doubled for each retry after it up to
+	// BackoffMax (defaults 50ms and 1s). Each wait is jittered down by up to half.
+	BackoffMin time.Duration `env:"GOJST_DB_RETRY_BACKOFF_MIN" validate:"min=0s"`
+	BackoffMax time.Duration `env:"GOJST_DB_RETRY_BACKOFF_MAX" validate:"min=0s,max=30s"`
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.BackoffMin <= 0 {
+		cfg.BackoffMin = 50 * time.Millisecond
+	}
+	if cfg.BackoffMax <= 0 {
+		cfg.BackoffMax = time.Second
+	}
+	if cfg.BackoffMax < cfg.BackoffMin {
+		cfg.BackoffMax = cfg.BackoffMin
+	}
+	return cfg
+}
+
+// ConfigFromEnv reads retry settings. With none set, calls are tried 3 times.
+//
+//	GOJST_DB_RETRY_MAX_ATTEMPTS=3      tries per call, including the first; 1 turns retries off
+//	GOJST_DB_RETRY_BACKOFF_MIN=50ms    wait before the first retry
+//	GOJST_DB_RETRY_BACKOFF_MAX=1s      longest wait between tries
+func ConfigFromEnv(getenv func(string) string) (Config, error) {
+	var cfg Config
+	var problems config.Problems
+	var err error
+	cfg.MaxAttempts, err = config.EnvInt(getenv, "GOJST_DB_RETRY_MAX_ATTEMPTS", 0)
+	problems.Add(err)
+	cfg.BackoffMin, err = config.EnvDuration(getenv, "GOJST_DB_RETRY_BACKOFF_MIN", 0)
+	problems.Add(err)
+	cfg.BackoffMax, err = config.EnvDuration(getenv, "GOJST_DB_RETRY_BACKOFF_MAX", 0)
+	problems.Add(err)
+
+	problems.Add(config.Validate(cfg))
+	if err := problems.Err(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// RetryRepo retries calls that fail with a retryable CommonError (see common.IsRetryable).
+//
+// Calls that are safe to repeat (reads, upserts, and adds, which the primary key makes
+// idempotent) are retried for connection errors and ErrcdRepoTransient. An add whose retry finds
+// its own status already stored succeeded (see add). Calls that aren't (adding
+// comments and API call counts, deletes, claims, renames, corrections) are only retried for
+// ErrcdRepoTransient, because the database rolled that work back; after a connection error they
+// may have committed. ForEach methods and Snapshot are only retried if fn hasn't been called.
+type RetryRepo struct {
+	repo jobStatus.FullRepo
+	cfg  Config
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+var _ jobStatus.FullRepo = (*RetryRepo)(nil)
+
+func NewRetryRepo(repo jobStatus.FullRepo, cfg Config, seed int64) *RetryRepo {
+	return &RetryRepo{repo: repo, cfg: cfg.withDefaults(), rand: rand.New(rand.NewSource(seed))}
+}
+
+func isTransient(err error) bool {
+	return common.ErrorCode(err) == common.ErrcdRepoTransient
+}
+
+// do calls fn until it succeeds, fails with an error retryable rejects, runs out of attempts, or
+// ctx is done. It returns fn's last error.
+func (rr *RetryRepo) do(ctx context.Context, method string, retryable func(error) bool, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= rr.cfg.MaxAttempts || !retryable(err) {
+			return err
+		}
+
+		wait := rr.backoff(attempt)
+		log.Printf("repo %s failed, try %d of %d in %s: %v", method, attempt+1, rr.cfg.MaxAttempts, wait, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff is BackoffMin doubled for each retry after the first, up to BackoffMax, less a random
+// amount up to half, so instances that failed together don't retry together.
+func (rr *RetryRepo) backoff(attempt int) time.Duration {
+	d := rr.cfg.BackoffMin
+	for i := 1; i < attempt && d < rr.cfg.BackoffMax; i++ {
+		d *= 2
+	}
+	if d > rr.cfg.BackoffMax {
+		d = rr.cfg.BackoffMax
+	}
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	return d - time.Duration(rr.rand.Int63n(int64(d/2)+1))
+}
+
+// forEach retries run until it calls its callback; after that, a retry would repeat rows fn has
+// already seen.
+func (rr *RetryRepo) forEach(ctx context.Context, method string, fn func(jobStatus.JobStatus) error, run func(fn func(jobStatus.JobStatus) error) error) error {
+	called := false
+	tracked := func(js jobStatus.JobStatus) error {
+		called = true
+		return fn(js)
+	}
+	return rr.do(ctx, method, func(err error) bool { return !called && common.IsRetryable(err) }, func() error {
+		return run(tracked)
+	})
+}
+
+func (rr *RetryRepo) Add(ctx context.Context, js jobStatus.JobStatus) error {
+	return rr.add(ctx, "Add", js, func() error {
+		return rr.repo.Add(ctx, js)
+	})
+}
+
+// AddIdempotent is safe to retry even if a lost response hid a successful insert; the retry
+// finds the row the first try stored.
+func (rr *RetryRepo) AddIdempotent(ctx context.Context, js jobStatus.JobStatus) (result jobStatus.JobStatus, added bool, err error) {
+	err = rr.do(ctx, "AddIdempotent", common.IsRetryable, func() error {
+		result, added, err = rr.repo.AddIdempotent(ctx, js)
+		return err
+	})
+	return result, added, err
+}
+
+// AddBatch is one transaction, so a retry adds all of the batch or none of it, and if the first
+// status was stored, all of them were.
+func (rr *RetryRepo) AddBatch(ctx context.Context, jss []jobStatus.JobStatus) error {
+	if len(jss) == 0 {
+		return rr.repo.AddBatch(ctx, jss)
+	}
+	return rr.add(ctx, "AddBatch", jss[0], func() error {
+		return rr.repo.AddBatch(ctx, jss)
+	})
+}
+
+// add retries an insert. A connection error may hide a commit whose response was lost, so if a
+// retry fails with ErrcdRepoDupeRow and first is stored with its own StatusId, an earlier try
+// committed and the insert succeeded. StatusIds are new for each add, so another client's status
+// with the same natural key is still a duplicate.
+func (rr *RetryRepo) add(ctx context.Context, method string, first jobStatus.JobStatus, insert func() error) error {
+	retry := false
+	return rr.do(ctx, method, common.IsRetryable, func() error {
+		err := insert()
+		if retry && common.ErrorCode(err) == common.ErrcdRepoDupeRow && rr.isStored(ctx, first) {
+			log.Printf("repo %s retry found StatusId %s already stored; an earlier try committed", method, first.StatusId)
+			return nil
+		}
+		retry = true
+		return err
+	})
+}
+
+// isStored is true if a status with js's StatusId is stored under js's JobId and BusinessDate.
+// If it can't tell, it's false, so the duplicate is reported.
+func (rr *RetryRepo) isStored(ctx context.Context, js jobStatus.JobStatus) bool {
+	opts := jobStatus.QueryOptions{Fields: []jobStatus.FieldName{jobStatus.FieldStatusId}}
+	stored, err := rr.repo.GetByJobIdBusinessDate(ctx, js.JobId, js.BusinessDate, opts)
+	if err != nil {
+		return false
+	}
+	for _, s := range stored {
+		if s.StatusId == js.StatusId {
+			return true
+		}
+	}
+	return false
+}
+
+func (rr *RetryRepo) GetByJobId(ctx context.Context, jobId jobStatus.JobIdType, opts jobStatus.QueryOptions) (result []jobStatus.JobStatus, err error) {
+	err = rr.do(ctx, "GetByJobId", common.IsRetryable, func() error {
+		result, err = rr.repo.GetByJobId(ctx, jobId, opts)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) GetByJobIdBusinessDate(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, opts jobStatus.QueryOptions) (result []jobStatus.JobStatus, err error) {
+	err = rr.do(ctx, "GetByJobIdBusinessDate", common.IsRetryable, func() error {
+		result, err = rr.repo.GetByJobIdBusinessDate(ctx, jobId, businessDate, opts)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) ForEachByJobId(ctx context.Context, jobId jobStatus.JobIdType, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	return rr.forEach(ctx, "ForEachByJobId", fn, func(fn func(jobStatus.JobStatus) error) error {
+		return rr.repo.ForEachByJobId(ctx, jobId, opts, fn)
+	})
+}
+
+func (rr *RetryRepo) ForEachByJobIdBusinessDate(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	return rr.forEach(ctx, "ForEachByJobIdBusinessDate", fn, func(fn func(jobStatus.JobStatus) error) error {
+		return rr.repo.ForEachByJobIdBusinessDate(ctx, jobId, businessDate, opts, fn)
+	})
+}
+
+func (rr *RetryRepo) GetByFilters(ctx context.Context, opts jobStatus.QueryOptions) (result []jobStatus.JobStatus, err error) {
+	err = rr.do(ctx, "GetByFilters", common.IsRetryable, func() error {
+		result, err = rr.repo.GetByFilters(ctx, opts)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) ForEachByFilters(ctx context.Context, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	return rr.forEach(ctx, "ForEachByFilters", fn, func(fn func(jobStatus.JobStatus) error) error {
+		return rr.repo.ForEachByFilters(ctx, opts, fn)
+	})
+}
+
+// Methods without a context can't be canceled while they wait, but MaxAttempts and BackoffMax
+// bound how long they take.
+
+// RollupDaily recomputes the rollups for the dates, so it's safe to repeat.
+func (rr *RetryRepo) RollupDaily(ctx context.Context, fromDate time.Time, toDate time.Time) (n int64, err error) {
+	err = rr.do(ctx, "RollupDaily", common.IsRetryable, func() error {
+		n, err = rr.repo.RollupDaily(ctx, fromDate, toDate)
+		return err
+	})
+	return n, err
+}
+
+func (rr *RetryRepo) GetDailyRollups(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time) (result []jobStatus.DailyRollup, err error) {
+	err = rr.do(ctx, "GetDailyRollups", common.IsRetryable, func() error {
+		result, err = rr.repo.GetDailyRollups(ctx, applicationId, fromDate, toDate)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) GetJobReliability(ctx context.Context, applicationId string, jobId jobStatus.JobIdType, fromDate time.Time, toDate time.Time) (result []jobStatus.JobReliability, err error) {
+	err = rr.do(ctx, "GetJobReliability", common.IsRetryable, func() error {
+		result, err = rr.repo.GetJobReliability(ctx, applicationId, jobId, fromDate, toDate)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) GetDurationBaselines(ctx context.Context, applicationId string, jobId jobStatus.JobIdType, fromDate time.Time, toDate time.Time) (result []jobStatus.DurationBaseline, err error) {
+	err = rr.do(ctx, "GetDurationBaselines", common.IsRetryable, func() error {
+		result, err = rr.repo.GetDurationBaselines(ctx, applicationId, jobId, fromDate, toDate)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) PutRunCost(ctx context.Context, rc jobStatus.RunCost) error {
+	return rr.do(ctx, "PutRunCost", common.IsRetryable, func() error {
+		return rr.repo.PutRunCost(ctx, rc)
+	})
+}
+
+func (rr *RetryRepo) GetJobCosts(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time) (result []jobStatus.JobCost, err error) {
+	err = rr.do(ctx, "GetJobCosts", common.IsRetryable, func() error {
+		result, err = rr.repo.GetJobCosts(ctx, applicationId, fromDate, toDate)
+		return err
+	})
+	return result, err
+}
+
+// AddRunComment isn't idempotent; a repeat after a commit would add the comment twice.
+func (rr *RetryRepo) AddRunComment(ctx context.Context, rc jobStatus.RunComment) error {
+	return rr.do(ctx, "AddRunComment", isTransient, func() error {
+		return rr.repo.AddRunComment(ctx, rc)
+	})
+}
+
+func (rr *RetryRepo) ListRunComments(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, runId jobStatus.RunIdType) (result []jobStatus.RunComment, err error) {
+	err = rr.do(ctx, "ListRunComments", common.IsRetryable, func() error {
+		result, err = rr.repo.ListRunComments(ctx, jobId, businessDate, runId)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) CountByApplicationBusinessDate(ctx context.Context, applicationId string, businessDate time.Time) (n int64, err error) {
+	err = rr.do(ctx, "CountByApplicationBusinessDate", common.IsRetryable, func() error {
+		n, err = rr.repo.CountByApplicationBusinessDate(ctx, applicationId, businessDate)
+		return err
+	})
+	return n, err
+}
+
+// AddApiCalls adds to counts, so a repeat after a commit would count the calls twice.
+func (rr *RetryRepo) AddApiCalls(ctx context.Context, counts []jobStatus.ApiCallCount) error {
+	return rr.do(ctx, "AddApiCalls", isTransient, func() error {
+		return rr.repo.AddApiCalls(ctx, counts)
+	})
+}
+
+func (rr *RetryRepo) GetApiCalls(ctx context.Context, month time.Time) (result []jobStatus.ApiCallCount, err error) {
+	err = rr.do(ctx, "GetApiCalls", common.IsRetryable, func() error {
+		result, err = rr.repo.GetApiCalls(ctx, month)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) PutSavedView(ctx context.Context, view jobStatus.SavedView) error {
+	return rr.do(ctx, "PutSavedView", common.IsRetryable, func() error {
+		return rr.repo.PutSavedView(ctx, view)
+	})
+}
+
+func (rr *RetryRepo) GetSavedView(ctx context.Context, name string) (view jobStatus.SavedView, found bool, err error) {
+	err = rr.do(ctx, "GetSavedView", common.IsRetryable, func() error {
+		view, found, err = rr.repo.GetSavedView(ctx, name)
+		return err
+	})
+	return view, found, err
+}
+
+func (rr *RetryRepo) ListSavedViews(ctx context.Context) (result []jobStatus.SavedView, err error) {
+	err = rr.do(ctx, "ListSavedViews", common.IsRetryable, func() error {
+		result, err = rr.repo.ListSavedViews(ctx)
+		return err
+	})
+	return result, err
+}
+
+// Deletes aren't retried after connection errors, because a repeat after a commit would report
+// the view wasn't found.
+func (rr *RetryRepo) DeleteSavedView(ctx context.Context, name string) (found bool, err error) {
+	err = rr.do(ctx, "DeleteSavedView", isTransient, func() error {
+		found, err = rr.repo.DeleteSavedView(ctx, name)
+		return err
+	})
+	return found, err
+}
+
+func (rr *RetryRepo) PutScheduledQuery(ctx context.Context, sq jobStatus.ScheduledQuery) error {
+	return rr.do(ctx, "PutScheduledQuery", common.IsRetryable, func() error {
+		return rr.repo.PutScheduledQuery(ctx, sq)
+	})
+}
+
+func (rr *RetryRepo) GetScheduledQuery(ctx context.Context, name string) (sq jobStatus.ScheduledQuery, found bool, err error) {
+	err = rr.do(ctx, "GetScheduledQuery", common.IsRetryable, func() error {
+		sq, found, err = rr.repo.GetScheduledQuery(ctx, name)
+		return err
+	})
+	return sq, found, err
+}
+
+func (rr *RetryRepo) ListScheduledQueries(ctx context.Context) (result []jobStatus.ScheduledQuery, err error) {
+	err = rr.do(ctx, "ListScheduledQueries", common.IsRetryable, func() error {
+		result, err = rr.repo.ListScheduledQueries(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) DeleteScheduledQuery(ctx context.Context, name string) (found bool, err error) {
+	err = rr.do(ctx, "DeleteScheduledQuery", isTransient, func() error {
+		found, err = rr.repo.DeleteScheduledQuery(ctx, name)
+		return err
+	})
+	return found, err
+}
+
+// ClaimScheduledRun isn't retried after connection errors, because a repeat after a commit would
+// find the run already claimed and skip it.
+func (rr *RetryRepo) ClaimScheduledRun(ctx context.Context, name string, runDate time.Time) (claimed bool, err error) {
+	err = rr.do(ctx, "ClaimScheduledRun", isTransient, func() error {
+		claimed, err = rr.repo.ClaimScheduledRun(ctx, name, runDate)
+		return err
+	})
+	return claimed, err
+}
+
+// ReleaseScheduledRun is retried like a read: after a commit, a repeat finds LastRunDate already
+// moved back and changes nothing.
+func (rr *RetryRepo) ReleaseScheduledRun(ctx context.Context, name string, runDate time.Time, previous time.Time) (released bool, err error) {
+	err = rr.do(ctx, "ReleaseScheduledRun", common.IsRetryable, func() error {
+		released, err = rr.repo.ReleaseScheduledRun(ctx, name, runDate, previous)
+		return err
+	})
+	return released, err
+}
+
+func (rr *RetryRepo) GetLatestByJobId(ctx context.Context, jobId jobStatus.JobIdType, fromDate time.Time, toDate time.Time, asOf time.Time) (result []jobStatus.JobStatus, err error) {
+	err = rr.do(ctx, "GetLatestByJobId", common.IsRetryable, func() error {
+		result, err = rr.repo.GetLatestByJobId(ctx, jobId, fromDate, toDate, asOf)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) GetLatestByJobIds(ctx context.Context, jobIds []jobStatus.JobIdType, businessDate time.Time, asOf time.Time) (result []jobStatus.JobStatus, err error) {
+	err = rr.do(ctx, "GetLatestByJobIds", common.IsRetryable, func() error {
+		result, err = rr.repo.GetLatestByJobIds(ctx, jobIds, businessDate, asOf)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) RenameJob(ctx context.Context, from jobStatus.JobIdType, to jobStatus.JobIdType, at time.Time) (result jobStatus.JobRenameResult, err error) {
+	err = rr.do(ctx, "RenameJob", isTransient, func() error {
+		result, err = rr.repo.RenameJob(ctx, from, to, at)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) Update(ctx context.Context, c jobStatus.StatusCorrection, correct func(before jobStatus.JobStatus) (jobStatus.JobStatus, error)) (result jobStatus.StatusCorrection, err error) {
+	err = rr.do(ctx, "Update", isTransient, func() error {
+		result, err = rr.repo.Update(ctx, c, correct)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) DeleteByKey(ctx context.Context, c jobStatus.StatusCorrection, key jobStatus.StatusKey) (result jobStatus.StatusCorrection, err error) {
+	err = rr.do(ctx, "DeleteByKey", isTransient, func() error {
+		result, err = rr.repo.DeleteByKey(ctx, c, key)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) ListStatusCorrections(ctx context.Context, statusId jobStatus.StatusIdType) (result []jobStatus.StatusCorrection, err error) {
+	err = rr.do(ctx, "ListStatusCorrections", common.IsRetryable, func() error {
+		result, err = rr.repo.ListStatusCorrections(ctx, statusId)
+		return err
+	})
+	return result, err
+}
+
+// RecordHeartbeat is retried like the reads because a later heartbeat never moves
+// HeartbeatTimestamp back, so recording one twice does nothing more.
+func (rr *RetryRepo) RecordHeartbeat(ctx context.Context, key jobStatus.StatusKey, at time.Time) (result jobStatus.JobStatus, err error) {
+	err = rr.do(ctx, "RecordHeartbeat", common.IsRetryable, func() error {
+		result, err = rr.repo.RecordHeartbeat(ctx, key, at)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) PutJobAlias(ctx context.Context, alias jobStatus.JobAlias) error {
+	return rr.do(ctx, "PutJobAlias", common.IsRetryable, func() error {
+		return rr.repo.PutJobAlias(ctx, alias)
+	})
+}
+
+func (rr *RetryRepo) ListJobAliases(ctx context.Context) (result []jobStatus.JobAlias, err error) {
+	err = rr.do(ctx, "ListJobAliases", common.IsRetryable, func() error {
+		result, err = rr.repo.ListJobAliases(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) DeleteJobAlias(ctx context.Context, aliasJobId jobStatus.JobIdType) (found bool, err error) {
+	err = rr.do(ctx, "DeleteJobAlias", isTransient, func() error {
+		found, err = rr.repo.DeleteJobAlias(ctx, aliasJobId)
+		return err
+	})
+	return found, err
+}
+
+func (rr *RetryRepo) QueryReadOnly(ctx context.Context, query string, maxRows int, timeout time.Duration) (result jobStatus.SqlResult, err error) {
+	err = rr.do(ctx, "QueryReadOnly", common.IsRetryable, func() error {
+		result, err = rr.repo.QueryReadOnly(ctx, query, maxRows, timeout)
+		return err
+	})
+	return result, err
+}
+
+func (rr *RetryRepo) ForEachWithIntegrityHash(ctx context.Context, applicationId string, fromDate time.Time, toDate time.Time, fn func(jobStatus.JobStatus) error) error {
+	return rr.forEach(ctx, "ForEachWithIntegrityHash", fn, func(fn func(jobStatus.JobStatus) error) error {
+		return rr.repo.ForEachWithIntegrityHash(ctx, applicationId, fromDate, toDate, fn)
+	})
+}
+
+// Snapshot is retried only if fn hasn't been called, like the ForEach methods.
+func (rr *RetryRepo) Snapshot(ctx context.Context, fn func(jobStatus.SnapshotReader) error) error {
+	called := false
+	return rr.do(ctx, "Snapshot", func(err error) bool { return !called && common.IsRetryable(err) }, func() error {
+		return rr.repo.Snapshot(ctx, func(sr jobStatus.SnapshotReader) error {
+			called = true
+			return fn(sr)
+		})
+	})
+}
+
+// DeleteBefore only deletes what's still past its cutoff, so it's safe to repeat.
+func (rr *RetryRepo) DeleteBefore(ctx context.Context, cutoff time.Time, cutoffs map[string]time.Time, limit int) (n int64, err error) {
+	err = rr.do(ctx, "DeleteBefore", common.IsRetryable, func() error {
+		n, err = rr.repo.DeleteBefore(ctx, cutoff, cutoffs, limit)
+		return err
+	})
+	return n, err
+}
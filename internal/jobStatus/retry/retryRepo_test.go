@@ -0,0 +1,109 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbmemory"
+	"github.com/jmjf/go-jst/internal/jobStatus/retry"
+)
+
+var fastRetries = retry.Config{MaxAttempts: 3, BackoffMin: time.Millisecond, BackoffMax: time.Millisecond}
+
+// flakyRepo fails the first fails calls to Add and AddRunComment with code. If commit is set, the
+// failed Adds store the status anyway, like a commit whose response was lost.
+type flakyRepo struct {
+	*dbmemory.RepoMemory
+	code   string
+	fails  int
+	commit bool
+	calls  int
+}
+
+func (repo *flakyRepo) Add(ctx context.Context, js jobStatus.JobStatus) error {
+	repo.calls++
+	if repo.calls > repo.fails {
+		return repo.RepoMemory.Add(ctx, js)
+	}
+	if repo.commit {
+		if err := repo.RepoMemory.Add(ctx, js); err != nil {
+			return err
+		}
+	}
+	return common.NewCommonError(repo.code, errors.New("flaky"))
+}
+
+func (repo *flakyRepo) AddRunComment(ctx context.Context, rc jobStatus.RunComment) error {
+	repo.calls++
+	if repo.calls > repo.fails {
+		return repo.RepoMemory.AddRunComment(ctx, rc)
+	}
+	return common.NewCommonError(repo.code, errors.New("flaky"))
+}
+
+func newStatus(t *testing.T, hostId jobStatus.HostIdType) jobStatus.JobStatus {
+	t.Helper()
+	busDt := time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)
+	js, err := jobStatus.NewJobStatus("overdrafts", "od-calc", "SUCCEED", busDt.Add(25*time.Hour), busDt, "1", hostId)
+	if err != nil {
+		t.Fatalf("NewJobStatus: %v", err)
+	}
+	return js
+}
+
+func TestRetriesUntilSuccess(t *testing.T) {
+	flaky := &flakyRepo{RepoMemory: dbmemory.NewRepoMemory(), code: common.ErrcdRepoTransient, fails: 2}
+	rr := retry.NewRetryRepo(flaky, fastRetries, 1)
+	if err := rr.Add(context.Background(), newStatus(t, "batch01")); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if flaky.calls != 3 {
+		t.Errorf("got %d calls, want 3", flaky.calls)
+	}
+}
+
+func TestStopsAfterMaxAttempts(t *testing.T) {
+	flaky := &flakyRepo{RepoMemory: dbmemory.NewRepoMemory(), code: common.ErrcdRepoConnection, fails: 5}
+	rr := retry.NewRetryRepo(flaky, fastRetries, 1)
+	if err := rr.Add(context.Background(), newStatus(t, "batch01")); common.ErrorCode(err) != common.ErrcdRepoConnection {
+		t.Fatalf("Add: got %v, want %s", err, common.ErrcdRepoConnection)
+	}
+	if flaky.calls != 3 {
+		t.Errorf("got %d calls, want 3", flaky.calls)
+	}
+}
+
+func TestAddWhoseCommitWasLostSucceeds(t *testing.T) {
+	flaky := &flakyRepo{RepoMemory: dbmemory.NewRepoMemory(), code: common.ErrcdRepoConnection, fails: 1, commit: true}
+	rr := retry.NewRetryRepo(flaky, fastRetries, 1)
+	if err := rr.Add(context.Background(), newStatus(t, "batch01")); err != nil {
+		t.Errorf("Add: got %v, want the retry to find the stored status", err)
+	}
+}
+
+func TestAddOfAnotherClientsDuplicateFails(t *testing.T) {
+	ctx := context.Background()
+	flaky := &flakyRepo{RepoMemory: dbmemory.NewRepoMemory(), code: common.ErrcdRepoConnection, fails: 1}
+	if err := flaky.RepoMemory.Add(ctx, newStatus(t, "batch02")); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	rr := retry.NewRetryRepo(flaky, fastRetries, 1)
+	if err := rr.Add(ctx, newStatus(t, "batch01")); common.ErrorCode(err) != common.ErrcdRepoDupeRow {
+		t.Errorf("Add: got %v, want %s", err, common.ErrcdRepoDupeRow)
+	}
+}
+
+func TestNonIdempotentCallsAreNotRetriedAfterConnectionErrors(t *testing.T) {
+	for code, wantCalls := range map[string]int{common.ErrcdRepoConnection: 1, common.ErrcdRepoTransient: 2} {
+		flaky := &flakyRepo{RepoMemory: dbmemory.NewRepoMemory(), code: code, fails: 1}
+		rr := retry.NewRetryRepo(flaky, fastRetries, 1)
+		rr.AddRunComment(context.Background(), jobStatus.RunComment{CommentId: "c1", JobId: "od-calc", RunId: "1"})
+		if flaky.calls != wantCalls {
+			t.Errorf("AddRunComment failing with %s: got %d calls, want %d", code, flaky.calls, wantCalls)
+		}
+	}
+}
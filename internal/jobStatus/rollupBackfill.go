@@ -0,0 +1,57 @@
+package jobStatus
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// rollupBackfillMaxDays caps one batch so each rollup query stays small.
+const rollupBackfillMaxDays = 31
+
+// RollupBackfill is a migrate.Migration that recomputes daily rollups for every business date
+// from a start date through yesterday, a batch of dates at a time. The cursor is the last
+// date rolled up. Rollups are upserts, so repeating a batch is harmless.
+type RollupBackfill struct {
+	uc   *DailyRollupUC
+	from time.Time
+}
+
+func NewRollupBackfill(uc *DailyRollupUC, from time.Time) *RollupBackfill {
+	return &RollupBackfill{uc: uc, from: TruncateToDate(from)}
+}
+
+func (rb *RollupBackfill) Name() string {
+	return "rollup-backfill"
+}
+
+// Batch rolls up limit dates (at most rollupBackfillMaxDays) after cursor.
+func (rb *RollupBackfill) Batch(ctx context.Context, cursor string, limit int) (string, int, bool, error) {
+	start := rb.from
+	if cursor != "" {
+		last, err := time.Parse(dto.DateFormat, cursor)
+		if err != nil {
+			return cursor, 0, false, common.NewCommonError(common.ErrcdDomainProps, err)
+		}
+		start = last.AddDate(0, 0, 1)
+	}
+	yesterday := TruncateToDate(time.Now()).AddDate(0, 0, -1)
+	if start.After(yesterday) {
+		return cursor, 0, true, nil
+	}
+
+	if limit > rollupBackfillMaxDays {
+		limit = rollupBackfillMaxDays
+	}
+	end := start.AddDate(0, 0, limit-1)
+	if end.After(yesterday) {
+		end = yesterday
+	}
+	if _, err := rb.uc.RollupDates(ctx, start, end); err != nil {
+		return cursor, 0, false, err
+	}
+	days := int(end.Sub(start)/(24*time.Hour)) + 1
+	return end.Format(dto.DateFormat), days, !end.Before(yesterday), nil
+}
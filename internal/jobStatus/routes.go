@@ -0,0 +1,164 @@
+package jobStatus
+
+import (
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/flags"
+	"github.com/jmjf/go-jst/internal/tasks"
+)
+
+// Route paths for the job status API.
+const (
+	JobStatusesPath       = "/job-statuses"
+	JobStatusBatchesPath  = "/job-status-batches"
+	JobStatusRollupsPath  = "/job-status-rollups"
+	JobReliabilityPath    = "/job-reliability"
+	JobFlakinessPath      = "/job-flakiness"
+	DurationBaselinesPath = "/job-duration-baselines"
+	JobForecastPath       = "/job-forecast"
+)
+
+// Services are optional services the job status API uses. A nil field turns that service off:
+// no async rollups, no quotas, no metering, default feature flags, no job aliases, only
+// the built-in forecaster, or no field mappings. AdminRoute guards routes that change stored
+// data in bulk, like cmd/api's admin routes; if it's nil, those routes are off.
+type Services struct {
+	Tasks         *tasks.Manager
+	Quota         *QuotaUC
+	Meter         *MeteringUC
+	Flags         *flags.Flags
+	Aliases       *JobAliasUC
+	Forecasters   *Forecasters
+	Integrity     IntegrityHasher
+	FieldMappings *FieldMappings
+	AdminRoute    func(common.MethodHandler) http.Handler
+}
+
+// Ports are the repo ports the job status API reads and writes. Repo is required. A nil port
+// turns off the routes that need it: Stream, streamed queries; Filter, queries without a jobId
+// or view; Rollup, daily rollups; Views, saved views and status boards; Board, status boards,
+// job badges, and latest statuses; Reliability, job reliability, flakiness, duration
+// baselines, and forecasts; Cost, run costs; Comment, run comments; Heartbeat, heartbeats.
+type Ports struct {
+	Repo        Repo
+	Stream      StreamRepo
+	Filter      FilterRepo
+	Rollup      RollupRepo
+	Views       SavedViewRepo
+	Board       BoardRepo
+	Reliability ReliabilityRepo
+	Cost        RunCostRepo
+	Comment     RunCommentRepo
+	Heartbeat   HeartbeatRepo
+}
+
+// FullPorts returns Ports with every port served by repo.
+func FullPorts(repo FullRepo) Ports {
+	return Ports{
+		Repo:        repo,
+		Stream:      repo,
+		Filter:      repo,
+		Rollup:      repo,
+		Views:       repo,
+		Board:       repo,
+		Reliability: repo,
+		Cost:        repo,
+		Comment:     repo,
+		Heartbeat:   repo,
+	}
+}
+
+// AddRoutes registers the job status API's handlers on mux.
+func AddRoutes(mux *http.ServeMux, ports Ports, svc Services) {
+	addUC := NewAddJobStatusUC(ports.Repo, svc)
+	getUC := NewGetJobStatusesUC(ports.Repo, ports.Views, ports.Filter)
+	var streamUC *StreamJobStatusesUC
+	if ports.Stream != nil {
+		streamUC = NewStreamJobStatusesUC(ports.Stream, ports.Filter)
+	}
+
+	mux.Handle(JobStatusesPath, common.MethodHandler{
+		http.MethodPost: NewAddJobStatusCtrl(addUC, svc.FieldMappings),
+		http.MethodGet:  NewGetJobStatusesCtrl(getUC, streamUC),
+	})
+	mux.Handle(JobStatusBatchesPath, common.MethodHandler{
+		http.MethodPost: NewAddJobStatusBatchCtrl(addUC, svc.FieldMappings),
+	})
+	mux.Handle(OpenApiPath, common.MethodHandler{
+		http.MethodGet: NewOpenApiCtrl(),
+	})
+
+	if ports.Rollup != nil {
+		rollupUC := NewDailyRollupUC(ports.Rollup)
+		// reads are public and bounded by MaxRollupDays; running a rollup rewrites stored rollups,
+		// up to MaxAsyncRollupDays of them, so it's an admin route
+		rollupHandler := common.MethodHandler{
+			http.MethodGet: NewGetDailyRollupsCtrl(rollupUC),
+		}
+		if svc.AdminRoute != nil {
+			rollupHandler[http.MethodPost] = svc.AdminRoute(common.MethodHandler{
+				http.MethodPost: NewRunDailyRollupCtrl(rollupUC, svc.Tasks),
+			})
+		}
+		mux.Handle(JobStatusRollupsPath, rollupHandler)
+	}
+
+	if ports.Reliability != nil {
+		reliabilityUC := NewReliabilityUC(ports.Reliability)
+		mux.Handle(JobReliabilityPath, common.MethodHandler{
+			http.MethodGet: NewGetJobReliabilityCtrl(reliabilityUC),
+		})
+		mux.Handle(JobFlakinessPath, common.MethodHandler{
+			http.MethodGet: NewGetJobFlakinessCtrl(reliabilityUC),
+		})
+		mux.Handle(DurationBaselinesPath, common.MethodHandler{
+			http.MethodGet: NewGetDurationBaselinesCtrl(reliabilityUC),
+		})
+		mux.Handle(JobForecastPath, common.MethodHandler{
+			http.MethodGet: NewGetJobForecastCtrl(NewForecastUC(ports.Repo, ports.Reliability, svc.Forecasters)),
+		})
+	}
+	if ports.Cost != nil {
+		costUC := NewRunCostUC(ports.Cost, svc)
+		mux.Handle(RunCostsPath, common.MethodHandler{
+			http.MethodPut: NewPutRunCostCtrl(costUC),
+		})
+		mux.Handle(JobCostsPath, common.MethodHandler{
+			http.MethodGet: NewGetJobCostsCtrl(costUC),
+		})
+	}
+	if ports.Comment != nil {
+		commentUC := NewRunCommentUC(ports.Comment, svc)
+		mux.Handle(RunCommentsPath, common.MethodHandler{
+			http.MethodPost: NewAddRunCommentCtrl(commentUC),
+			http.MethodGet:  NewListRunCommentsCtrl(commentUC),
+		})
+	}
+	if ports.Heartbeat != nil {
+		mux.Handle(JobHeartbeatsPath, common.MethodHandler{
+			http.MethodPost: NewRecordHeartbeatCtrl(NewHeartbeatUC(ports.Heartbeat, svc)),
+		})
+	}
+	if ports.Views != nil {
+		viewUC := NewSavedViewUC(ports.Views)
+		mux.Handle(SavedViewsPath, common.MethodHandler{
+			http.MethodGet:    NewGetSavedViewsCtrl(viewUC),
+			http.MethodPut:    NewPutSavedViewCtrl(viewUC),
+			http.MethodDelete: NewDeleteSavedViewCtrl(viewUC),
+		})
+	}
+	if ports.Board != nil {
+		mux.Handle(JobBadgePath, common.MethodHandler{
+			http.MethodGet: NewGetJobBadgeCtrl(NewJobBadgeUC(ports.Board)),
+		})
+		mux.Handle(LatestJobStatusesPath, common.MethodHandler{
+			http.MethodGet: NewGetLatestJobStatusesCtrl(NewLatestJobStatusUC(ports.Board)),
+		})
+	}
+	if ports.Views != nil && ports.Board != nil {
+		mux.Handle(StatusBoardPath, common.MethodHandler{
+			http.MethodGet: NewGetStatusBoardCtrl(NewStatusBoardUC(ports.Views, ports.Board)),
+		})
+	}
+}
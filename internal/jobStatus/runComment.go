@@ -0,0 +1,59 @@
+package jobStatus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// MaxCommentLen bounds a comment's body. Triage notes are short; logs belong behind a link.
+const MaxCommentLen = 4000
+
+// CommentIdType is the server-generated UUIDv7 for one comment, so comments sort by creation time.
+type CommentIdType string
+
+// RunComment is a triage note on a run, so it stays with the run instead of in chat scrollback.
+// A run is (JobId, BusinessDate, RunId), like rollups. Author is the signed-in user who wrote it
+// (see common.UserOf); comments can't be edited.
+type RunComment struct {
+	CommentId    CommentIdType
+	JobId        JobIdType
+	BusinessDate time.Time
+	RunId        RunIdType
+	Author       string
+	Body         string
+	CreatedTs    time.Time
+}
+
+// NewRunComment validates its arguments and returns a comment with a new CommentId, created now,
+// or a CommonError with code ErrcdDomainProps.
+func NewRunComment(jobId JobIdType, businessDate time.Time, runId RunIdType, author string, body string) (RunComment, error) {
+	if err := jobId.Validate(); err != nil {
+		return RunComment{}, err
+	}
+	if err := runId.Validate(); err != nil {
+		return RunComment{}, err
+	}
+	body = strings.TrimSpace(body)
+	switch {
+	case businessDate.IsZero():
+		return RunComment{}, propsError("BusinessDate is required")
+	case author == "":
+		return RunComment{}, propsError("Author is required")
+	case body == "":
+		return RunComment{}, propsError("Body is required")
+	case len(body) > MaxCommentLen:
+		return RunComment{}, propsError(fmt.Sprintf("Body is longer than %d characters", MaxCommentLen))
+	}
+	return RunComment{
+		CommentId:    CommentIdType(common.NewUuidV7()),
+		JobId:        jobId,
+		BusinessDate: TruncateToDate(businessDate),
+		RunId:        runId,
+		Author:       author,
+		Body:         body,
+		CreatedTs:    time.Now().UTC().Truncate(time.Microsecond),
+	}, nil
+}
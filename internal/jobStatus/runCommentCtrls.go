@@ -0,0 +1,65 @@
+package jobStatus
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// RunCommentsPath is the route path for comments on runs.
+const RunCommentsPath = "/run-comments"
+
+type AddRunCommentCtrl struct {
+	uc *RunCommentUC
+}
+
+func NewAddRunCommentCtrl(uc *RunCommentUC) *AddRunCommentCtrl {
+	return &AddRunCommentCtrl{uc: uc}
+}
+
+// ServeHTTP handles POST of a RunCommentDto. The author is the user common.ResolveUser found,
+// so requests that didn't come through the authenticating proxy are 401.
+func (ctrl *AddRunCommentCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	author, ok := common.UserOf(r)
+	if !ok {
+		writeError(w, r, common.NewCommonError(common.ErrcdUnauthorized, errNotSignedIn))
+		return
+	}
+
+	var rcDto dto.RunCommentDto
+	if err := json.NewDecoder(r.Body).Decode(&rcDto); err != nil {
+		writeError(w, r, common.NewCommonError(common.ErrcdJsonDecode, err))
+		return
+	}
+
+	result, err := ctrl.uc.Add(r.Context(), rcDto, author)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusCreated, result)
+}
+
+type ListRunCommentsCtrl struct {
+	uc *RunCommentUC
+}
+
+func NewListRunCommentsCtrl(uc *RunCommentUC) *ListRunCommentsCtrl {
+	return &ListRunCommentsCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameters jobId, busDt, and optionally runId.
+func (ctrl *ListRunCommentsCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	result, err := ctrl.uc.List(r.Context(), q.Get("jobId"), q.Get("busDt"), q.Get("runId"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
@@ -0,0 +1,88 @@
+package jobStatus
+
+import (
+	"context"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+type RunCommentUC struct {
+	repo    RunCommentRepo
+	aliases *JobAliasUC
+}
+
+// NewRunCommentUC returns the use case. It uses svc.Aliases, so comments on a legacy JobId are
+// stored under the canonical one like statuses are.
+func NewRunCommentUC(repo RunCommentRepo, svc Services) *RunCommentUC {
+	return &RunCommentUC{repo: repo, aliases: svc.Aliases}
+}
+
+// Add stores rcDto's body as a new comment by author. The run doesn't need a status yet.
+func (uc *RunCommentUC) Add(ctx context.Context, rcDto dto.RunCommentDto, author string) (dto.RunCommentDto, error) {
+	busDt, err := parseDateProp("BusinessDate", rcDto.BusinessDate)
+	if err != nil {
+		return dto.RunCommentDto{}, err
+	}
+	jobId, err := uc.resolve(ctx, JobIdType(rcDto.JobId))
+	if err != nil {
+		return dto.RunCommentDto{}, err
+	}
+	rc, err := NewRunComment(jobId, busDt, RunIdType(rcDto.RunId), author, rcDto.Body)
+	if err != nil {
+		return dto.RunCommentDto{}, err
+	}
+
+	if err := uc.repo.AddRunComment(ctx, rc); err != nil {
+		return dto.RunCommentDto{}, err
+	}
+	return runCommentToDto(rc), nil
+}
+
+// List returns the comments on a job's runs for businessDate, oldest first. If runId is empty,
+// it returns comments on every run that day.
+func (uc *RunCommentUC) List(ctx context.Context, jobId string, businessDate string, runId string) ([]dto.RunCommentDto, error) {
+	id, err := NewJobId(jobId)
+	if err != nil {
+		return nil, err
+	}
+	if runId != "" {
+		if err := RunIdType(runId).Validate(); err != nil {
+			return nil, err
+		}
+	}
+	busDt, err := parseDateProp("BusinessDate", businessDate)
+	if err != nil {
+		return nil, err
+	}
+	if id, err = uc.resolve(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rcs, err := uc.repo.ListRunComments(ctx, id, busDt, RunIdType(runId))
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]dto.RunCommentDto, len(rcs))
+	for i, rc := range rcs {
+		dtos[i] = runCommentToDto(rc)
+	}
+	return dtos, nil
+}
+
+func (uc *RunCommentUC) resolve(ctx context.Context, jobId JobIdType) (JobIdType, error) {
+	if uc.aliases == nil {
+		return jobId, nil
+	}
+	return uc.aliases.Resolve(ctx, jobId)
+}
+
+func runCommentToDto(rc RunComment) dto.RunCommentDto {
+	return dto.RunCommentDto{
+		CommentId:        string(rc.CommentId),
+		JobId:            string(rc.JobId),
+		BusinessDate:     rc.BusinessDate.Format(dto.DateFormat),
+		RunId:            string(rc.RunId),
+		Author:           rc.Author,
+		Body:             rc.Body,
+		CreatedTimestamp: rc.CreatedTs.Format(dto.TimestampFormat),
+	}
+}
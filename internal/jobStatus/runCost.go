@@ -0,0 +1,110 @@
+package jobStatus
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// MaxJobCostDays bounds a job cost window. Job costs join raw statuses to find failed runs,
+// so the window is limited like reliability.
+const MaxJobCostDays = 93
+
+// RunCost is what one run cost, as its reporter estimates it. A run is (JobId, BusinessDate,
+// RunId), like rollups. CostUsd is in dollars; there's no currency conversion.
+type RunCost struct {
+	ApplicationId string
+	JobId         JobIdType
+	BusinessDate  time.Time
+	RunId         RunIdType
+	ComputeHours  float64
+	CostUsd       float64
+	UpdatedTs     time.Time
+}
+
+// Validate returns a props CommonError if rc is missing an ID or has a negative cost.
+func (rc RunCost) Validate() error {
+	if len(rc.ApplicationId) == 0 {
+		return propsError("ApplicationId is required")
+	}
+	if err := rc.JobId.Validate(); err != nil {
+		return err
+	}
+	if err := rc.RunId.Validate(); err != nil {
+		return err
+	}
+	if rc.BusinessDate.IsZero() {
+		return propsError("BusinessDate is required")
+	}
+	if rc.ComputeHours < 0 || math.IsInf(rc.ComputeHours, 0) || rc.CostUsd < 0 || math.IsInf(rc.CostUsd, 0) {
+		return propsError("ComputeHours and CostUsd can't be negative")
+	}
+	return nil
+}
+
+// JobCost totals one job's run costs over a window of business dates. Only runs with a status
+// count. A run failed if its last SUCCEED or FAIL is a FAIL; FailedCostUsd is what those runs
+// cost, so it's spend that produced nothing and estimates what failures cost.
+type JobCost struct {
+	ApplicationId  string
+	JobId          JobIdType
+	FromDate       time.Time
+	ToDate         time.Time
+	RunCount       int64
+	ComputeHours   float64
+	CostUsd        float64
+	FailedRunCount int64
+	FailedCostUsd  float64
+}
+
+// ComputeJobCosts totals an application's costs by job, ordered by JobId, from its costs and
+// statuses for the window. Repos that can't join use it; repoDB computes the same thing in SQL.
+func ComputeJobCosts(applicationId string, from time.Time, to time.Time, costs []RunCost, jss []JobStatus) []JobCost {
+	type runKey struct {
+		jobId        JobIdType
+		businessDate time.Time
+		runId        RunIdType
+	}
+	type runEnd struct {
+		code JobStatusCodeType
+		ts   time.Time
+	}
+	ends := map[runKey]runEnd{}
+	for _, js := range jss {
+		key := runKey{js.JobId, TruncateToDate(js.BusinessDate), js.RunId}
+		end, found := ends[key]
+		if !found {
+			ends[key] = runEnd{}
+		}
+		if (js.JobStatusCode == JobStatus_SUCCEED || js.JobStatusCode == JobStatus_FAIL) && !js.JobStatusTimestamp.Before(end.ts) {
+			ends[key] = runEnd{code: js.JobStatusCode, ts: js.JobStatusTimestamp}
+		}
+	}
+
+	byJob := map[JobIdType]*JobCost{}
+	for _, rc := range costs {
+		end, found := ends[runKey{rc.JobId, TruncateToDate(rc.BusinessDate), rc.RunId}]
+		if rc.ApplicationId != applicationId || !found {
+			continue
+		}
+		jc := byJob[rc.JobId]
+		if jc == nil {
+			jc = &JobCost{ApplicationId: applicationId, JobId: rc.JobId, FromDate: from, ToDate: to}
+			byJob[rc.JobId] = jc
+		}
+		jc.RunCount++
+		jc.ComputeHours += rc.ComputeHours
+		jc.CostUsd += rc.CostUsd
+		if end.code == JobStatus_FAIL {
+			jc.FailedRunCount++
+			jc.FailedCostUsd += rc.CostUsd
+		}
+	}
+
+	result := make([]JobCost, 0, len(byJob))
+	for _, jc := range byJob {
+		result = append(result, *jc)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].JobId < result[j].JobId })
+	return result
+}
@@ -0,0 +1,61 @@
+package jobStatus
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// Route paths for run costs.
+const (
+	RunCostsPath = "/run-costs"
+	JobCostsPath = "/job-costs"
+)
+
+type PutRunCostCtrl struct {
+	uc *RunCostUC
+}
+
+func NewPutRunCostCtrl(uc *RunCostUC) *PutRunCostCtrl {
+	return &PutRunCostCtrl{uc: uc}
+}
+
+// ServeHTTP handles PUT of a RunCostDto.
+func (ctrl *PutRunCostCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var rcDto dto.RunCostDto
+	if err := json.NewDecoder(r.Body).Decode(&rcDto); err != nil {
+		writeError(w, r, common.NewCommonError(common.ErrcdJsonDecode, err))
+		return
+	}
+
+	result, err := ctrl.uc.Put(r.Context(), rcDto)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
+
+type GetJobCostsCtrl struct {
+	uc *RunCostUC
+}
+
+func NewGetJobCostsCtrl(uc *RunCostUC) *GetJobCostsCtrl {
+	return &GetJobCostsCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameters appId, fromDt, and toDt.
+func (ctrl *GetJobCostsCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	result, err := ctrl.uc.JobCosts(r.Context(), q.Get("appId"), q.Get("fromDt"), q.Get("toDt"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
@@ -0,0 +1,91 @@
+package jobStatus
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+type RunCostUC struct {
+	repo    RunCostRepo
+	aliases *JobAliasUC
+}
+
+// NewRunCostUC returns the use case. It uses svc.Aliases, so costs sent under a legacy JobId are
+// stored under the canonical one like statuses are.
+func NewRunCostUC(repo RunCostRepo, svc Services) *RunCostUC {
+	return &RunCostUC{repo: repo, aliases: svc.Aliases}
+}
+
+// Put adds a run's cost or replaces the one already stored. The run doesn't need a status yet,
+// but its cost isn't in job costs or rollups until it has one.
+func (uc *RunCostUC) Put(ctx context.Context, rcDto dto.RunCostDto) (dto.RunCostDto, error) {
+	busDt, err := parseDateProp("BusinessDate", rcDto.BusinessDate)
+	if err != nil {
+		return dto.RunCostDto{}, err
+	}
+	rc := RunCost{
+		ApplicationId: rcDto.ApplicationId,
+		JobId:         JobIdType(rcDto.JobId),
+		BusinessDate:  busDt,
+		RunId:         RunIdType(rcDto.RunId),
+		ComputeHours:  rcDto.ComputeHours,
+		CostUsd:       rcDto.CostUsd,
+		UpdatedTs:     time.Now().UTC().Truncate(time.Microsecond),
+	}
+	if err := rc.Validate(); err != nil {
+		return dto.RunCostDto{}, err
+	}
+
+	if uc.aliases != nil {
+		if rc.JobId, err = uc.aliases.Resolve(ctx, rc.JobId); err != nil {
+			return dto.RunCostDto{}, err
+		}
+	}
+
+	if err := uc.repo.PutRunCost(ctx, rc); err != nil {
+		return dto.RunCostDto{}, err
+	}
+	return dto.RunCostDto{
+		ApplicationId:    rc.ApplicationId,
+		JobId:            string(rc.JobId),
+		BusinessDate:     rc.BusinessDate.Format(dto.DateFormat),
+		RunId:            string(rc.RunId),
+		ComputeHours:     rc.ComputeHours,
+		CostUsd:          rc.CostUsd,
+		UpdatedTimestamp: rc.UpdatedTs.Format(dto.TimestampFormat),
+	}, nil
+}
+
+// JobCosts returns an application's costs by job for business dates fromDate through toDate.
+func (uc *RunCostUC) JobCosts(ctx context.Context, applicationId string, fromDate string, toDate string) ([]dto.JobCostDto, error) {
+	if len(applicationId) == 0 {
+		return nil, propsError("AppId is required")
+	}
+	from, to, err := parseDateRange(fromDate, toDate, MaxJobCostDays)
+	if err != nil {
+		return nil, err
+	}
+
+	jcs, err := uc.repo.GetJobCosts(ctx, applicationId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]dto.JobCostDto, len(jcs))
+	for i, jc := range jcs {
+		dtos[i] = dto.JobCostDto{
+			ApplicationId:  jc.ApplicationId,
+			JobId:          string(jc.JobId),
+			FromDate:       jc.FromDate.Format(dto.DateFormat),
+			ToDate:         jc.ToDate.Format(dto.DateFormat),
+			RunCount:       jc.RunCount,
+			ComputeHours:   jc.ComputeHours,
+			CostUsd:        jc.CostUsd,
+			FailedRunCount: jc.FailedRunCount,
+			FailedCostUsd:  jc.FailedCostUsd,
+		}
+	}
+	return dtos, nil
+}
@@ -0,0 +1,122 @@
+package jobStatus
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// View limits keep names URL friendly and bound how many queries one view fans out to.
+const (
+	MaxViewNameLen        = 100
+	MaxTeamLen            = 100
+	MaxViewJobIds         = 50
+	MaxViewApplicationIds = 50
+)
+
+// SavedView is a named set of filters and display options owned by a team. Names are unique
+// across teams so clients can refer to a view by name alone (view=nightly-eu).
+type SavedView struct {
+	Name           string
+	Team           string
+	Shared         bool
+	ApplicationIds []string
+	JobIds         []JobIdType
+	Fields         string
+	Sort           string
+	UpdatedTs      time.Time
+}
+
+// Validate checks names, list sizes, and that Fields and Sort parse.
+func (sv SavedView) Validate() error {
+	if err := validateId("view Name", sv.Name, MaxViewNameLen); err != nil {
+		return err
+	}
+	if err := validateId("Team", sv.Team, MaxTeamLen); err != nil {
+		return err
+	}
+	if len(sv.JobIds) > MaxViewJobIds {
+		return propsError(fmt.Sprintf("a view can have at most %d JobIds", MaxViewJobIds))
+	}
+	if len(sv.ApplicationIds) > MaxViewApplicationIds {
+		return propsError(fmt.Sprintf("a view can have at most %d AppIds", MaxViewApplicationIds))
+	}
+	for _, jobId := range sv.JobIds {
+		if err := jobId.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, appId := range sv.ApplicationIds {
+		if len(appId) == 0 || len(appId) > 200 {
+			return propsError("view AppIds must be 1 to 200 characters")
+		}
+	}
+	if _, err := parseFields(sv.Fields); err != nil {
+		return err
+	}
+	if _, err := parseSort(sv.Sort); err != nil {
+		return err
+	}
+	return nil
+}
+
+// VisibleTo reports whether team should see the view in its list.
+func (sv SavedView) VisibleTo(team string) bool {
+	return sv.Shared || sv.Team == team
+}
+
+// SortJobStatuses orders jss the way the repo's ORDER BY would, keeping ties in their current
+// order. Views query each job separately, so their combined results are sorted here, and repos
+// that can't sort (like test fakes) use it too.
+func SortJobStatuses(jss []JobStatus, sortFields []SortField) {
+	if len(sortFields) == 0 {
+		return
+	}
+	sort.SliceStable(jss, func(i, j int) bool {
+		for _, sf := range sortFields {
+			c := compareField(jss[i], jss[j], sf.Field)
+			if c == 0 {
+				continue
+			}
+			if sf.Descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+func compareField(a, b JobStatus, field FieldName) int {
+	switch field {
+	case FieldJobStatusTimestamp:
+		return compareTime(a.JobStatusTimestamp, b.JobStatusTimestamp)
+	case FieldBusinessDate:
+		return compareTime(a.BusinessDate, b.BusinessDate)
+	case FieldJobStatusCode:
+		return compareString(string(a.JobStatusCode), string(b.JobStatusCode))
+	case FieldJobId:
+		return compareString(string(a.JobId), string(b.JobId))
+	}
+	return 0
+}
+
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+func compareString(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
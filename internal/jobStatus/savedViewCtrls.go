@@ -0,0 +1,89 @@
+package jobStatus
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// SavedViewsPath is the route for saved view CRUD.
+const SavedViewsPath = "/saved-views"
+
+type GetSavedViewsCtrl struct {
+	uc *SavedViewUC
+}
+
+func NewGetSavedViewsCtrl(uc *SavedViewUC) *GetSavedViewsCtrl {
+	return &GetSavedViewsCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameter name to get one view, or optional team to list
+// that team's views and all shared views.
+func (ctrl *GetSavedViewsCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Has("name") {
+		result, err := ctrl.uc.Get(r.Context(), q.Get("name"))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		common.WriteJson(w, http.StatusOK, result)
+		return
+	}
+
+	result, err := ctrl.uc.List(r.Context(), q.Get("team"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	common.WriteJson(w, http.StatusOK, result)
+}
+
+type PutSavedViewCtrl struct {
+	uc *SavedViewUC
+}
+
+func NewPutSavedViewCtrl(uc *SavedViewUC) *PutSavedViewCtrl {
+	return &PutSavedViewCtrl{uc: uc}
+}
+
+// ServeHTTP handles PUT of a SavedViewDto. It returns 201 for a new view and 200 for a replaced one.
+func (ctrl *PutSavedViewCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var svDto dto.SavedViewDto
+	if err := json.NewDecoder(r.Body).Decode(&svDto); err != nil {
+		writeError(w, r, common.NewCommonError(common.ErrcdJsonDecode, err))
+		return
+	}
+
+	result, created, err := ctrl.uc.Put(r.Context(), svDto)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	common.WriteJson(w, status, result)
+}
+
+type DeleteSavedViewCtrl struct {
+	uc *SavedViewUC
+}
+
+func NewDeleteSavedViewCtrl(uc *SavedViewUC) *DeleteSavedViewCtrl {
+	return &DeleteSavedViewCtrl{uc: uc}
+}
+
+// ServeHTTP handles DELETE with query parameters name and team (the owning team).
+func (ctrl *DeleteSavedViewCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if err := ctrl.uc.Delete(r.Context(), q.Get("name"), q.Get("team")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
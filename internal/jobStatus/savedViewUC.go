@@ -0,0 +1,126 @@
+package jobStatus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+type SavedViewUC struct {
+	repo SavedViewRepo
+}
+
+func NewSavedViewUC(repo SavedViewRepo) *SavedViewUC {
+	return &SavedViewUC{repo: repo}
+}
+
+// Put adds or replaces a view and returns it with its new UpdatedTs. created is true if the
+// view is new. A view can only be replaced by the team that owns it; a name another team
+// owns returns a CommonError coded ErrcdRepoDupeRow.
+func (uc *SavedViewUC) Put(ctx context.Context, svDto dto.SavedViewDto) (result dto.SavedViewDto, created bool, err error) {
+	sv := savedViewDtoToDomain(svDto)
+	if err := sv.Validate(); err != nil {
+		return dto.SavedViewDto{}, false, err
+	}
+
+	current, found, err := uc.repo.GetSavedView(ctx, sv.Name)
+	if err != nil {
+		return dto.SavedViewDto{}, false, err
+	}
+	if found && current.Team != sv.Team {
+		return dto.SavedViewDto{}, false, common.NewCommonError(common.ErrcdRepoDupeRow, fmt.Errorf("view %q belongs to team %q", sv.Name, current.Team))
+	}
+
+	sv.UpdatedTs = time.Now().UTC()
+	if err := uc.repo.PutSavedView(ctx, sv); err != nil {
+		return dto.SavedViewDto{}, false, err
+	}
+	return savedViewToDto(sv), !found, nil
+}
+
+// Get returns the named view or a CommonError coded ErrcdNotFound.
+func (uc *SavedViewUC) Get(ctx context.Context, name string) (dto.SavedViewDto, error) {
+	sv, err := getSavedView(ctx, uc.repo, name)
+	if err != nil {
+		return dto.SavedViewDto{}, err
+	}
+	return savedViewToDto(sv), nil
+}
+
+// List returns the views team can see: its own and every shared view. An empty team lists
+// only shared views.
+func (uc *SavedViewUC) List(ctx context.Context, team string) ([]dto.SavedViewDto, error) {
+	svs, err := uc.repo.ListSavedViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := []dto.SavedViewDto{}
+	for _, sv := range svs {
+		if sv.VisibleTo(team) {
+			result = append(result, savedViewToDto(sv))
+		}
+	}
+	return result, nil
+}
+
+// Delete removes a view owned by team.
+func (uc *SavedViewUC) Delete(ctx context.Context, name string, team string) error {
+	sv, err := getSavedView(ctx, uc.repo, name)
+	if err != nil {
+		return err
+	}
+	if sv.Team != team {
+		return common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("view %q belongs to team %q", name, sv.Team))
+	}
+	if _, err := uc.repo.DeleteSavedView(ctx, name); err != nil {
+		return err
+	}
+	return nil
+}
+
+func getSavedView(ctx context.Context, repo SavedViewRepo, name string) (SavedView, error) {
+	sv, found, err := repo.GetSavedView(ctx, name)
+	if err != nil {
+		return SavedView{}, err
+	}
+	if !found {
+		return SavedView{}, common.NewCommonError(common.ErrcdNotFound, fmt.Errorf("view %q does not exist", name))
+	}
+	return sv, nil
+}
+
+func savedViewDtoToDomain(svDto dto.SavedViewDto) SavedView {
+	jobIds := make([]JobIdType, len(svDto.JobIds))
+	for i, jobId := range svDto.JobIds {
+		jobIds[i] = JobIdType(jobId)
+	}
+	return SavedView{
+		Name:           svDto.Name,
+		Team:           svDto.Team,
+		Shared:         svDto.Shared,
+		ApplicationIds: svDto.ApplicationIds,
+		JobIds:         jobIds,
+		Fields:         svDto.Fields,
+		Sort:           svDto.Sort,
+	}
+}
+
+func savedViewToDto(sv SavedView) dto.SavedViewDto {
+	jobIds := make([]string, len(sv.JobIds))
+	for i, jobId := range sv.JobIds {
+		jobIds[i] = string(jobId)
+	}
+	return dto.SavedViewDto{
+		Name:             sv.Name,
+		Team:             sv.Team,
+		Shared:           sv.Shared,
+		ApplicationIds:   sv.ApplicationIds,
+		JobIds:           jobIds,
+		Fields:           sv.Fields,
+		Sort:             sv.Sort,
+		UpdatedTimestamp: sv.UpdatedTs.Format(time.RFC3339Nano),
+	}
+}
@@ -0,0 +1,89 @@
+package jobStatus
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// Scheduled query limits. BusinessDateOffset reaches back at most a month.
+const (
+	MaxScheduledQueryNameLen  = 100
+	MaxDeliveryTargetLen      = 2000
+	MinBusinessDateOffset     = -31
+	scheduledQueryRunAtFormat = "15:04"
+)
+
+// ScheduledQuery is a query run once a day at RunAtHour:RunAtMinute UTC with its results sent
+// to DeliveryTarget. View is a saved view's name; if it's empty, the query is by Filters alone,
+// with the business date added as a BusDt filter so it's always indexed. Filters are in query
+// parameter form (see parseFilters). LastRunDate is the last run date claimed, zero if none.
+type ScheduledQuery struct {
+	Name               string
+	Team               string
+	View               string
+	Filters            map[string]string
+	BusinessDateOffset int
+	RunAtHour          int
+	RunAtMinute        int
+	DeliveryKind       string
+	DeliveryTarget     string
+	LastRunDate        time.Time
+	UpdatedTs          time.Time
+}
+
+// Validate checks names, filters, timing, and the delivery target. Only webhook delivery exists.
+func (sq ScheduledQuery) Validate() error {
+	if err := validateId("scheduled query Name", sq.Name, MaxScheduledQueryNameLen); err != nil {
+		return err
+	}
+	if err := validateId("Team", sq.Team, MaxTeamLen); err != nil {
+		return err
+	}
+	if len(sq.View) > 0 {
+		if err := validateId("View", sq.View, MaxViewNameLen); err != nil {
+			return err
+		}
+	} else if len(sq.Filters) == 0 {
+		return propsError("a scheduled query needs a View or Filters")
+	}
+	if _, ok := sq.Filters["BusDt[eq]"]; ok {
+		return propsError("scheduled query Filters can't include BusDt[eq]; use BusDtOffset")
+	}
+	if _, err := parseFilters(sq.Filters); err != nil {
+		return err
+	}
+
+	switch {
+	case sq.BusinessDateOffset < MinBusinessDateOffset || sq.BusinessDateOffset > 0:
+		return propsError(fmt.Sprintf("BusDtOffset must be from %d to 0", MinBusinessDateOffset))
+	case sq.RunAtHour < 0 || sq.RunAtHour > 23 || sq.RunAtMinute < 0 || sq.RunAtMinute > 59:
+		return propsError("RunAt must be HH:MM")
+	}
+
+	if sq.DeliveryKind != dto.DeliveryWebhook {
+		return propsError(fmt.Sprintf("DeliveryKind %q is not supported; use %s", sq.DeliveryKind, dto.DeliveryWebhook))
+	}
+	if len(sq.DeliveryTarget) > MaxDeliveryTargetLen {
+		return propsError(fmt.Sprintf("DeliveryTarget is longer than %d characters", MaxDeliveryTargetLen))
+	}
+	u, err := url.Parse(sq.DeliveryTarget)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || len(u.Host) == 0 {
+		return propsError("a webhook DeliveryTarget must be an http or https URL")
+	}
+	return nil
+}
+
+// DueAt returns when the query runs on runDate's day.
+func (sq ScheduledQuery) DueAt(runDate time.Time) time.Time {
+	y, m, d := runDate.Date()
+	return time.Date(y, m, d, sq.RunAtHour, sq.RunAtMinute, 0, 0, time.UTC)
+}
+
+// Deliverer sends a scheduled query's results to its DeliveryTarget.
+type Deliverer interface {
+	Deliver(ctx context.Context, sq ScheduledQuery, result dto.ScheduledQueryResultDto) error
+}
@@ -0,0 +1,78 @@
+package jobStatus
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// ScheduledQueriesPath is the admin route for scheduled query CRUD. It's admin only because
+// deliveries are requests from the server to any URL a caller names.
+const ScheduledQueriesPath = "/admin/scheduled-queries"
+
+type GetScheduledQueriesCtrl struct {
+	uc *ScheduledQueryUC
+}
+
+func NewGetScheduledQueriesCtrl(uc *ScheduledQueryUC) *GetScheduledQueriesCtrl {
+	return &GetScheduledQueriesCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with optional query parameter team.
+func (ctrl *GetScheduledQueriesCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result, err := ctrl.uc.List(r.Context(), r.URL.Query().Get("team"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	common.WriteJson(w, http.StatusOK, result)
+}
+
+type PutScheduledQueryCtrl struct {
+	uc *ScheduledQueryUC
+}
+
+func NewPutScheduledQueryCtrl(uc *ScheduledQueryUC) *PutScheduledQueryCtrl {
+	return &PutScheduledQueryCtrl{uc: uc}
+}
+
+// ServeHTTP handles PUT of a ScheduledQueryDto. It returns 201 for a new query and 200 for a replaced one.
+func (ctrl *PutScheduledQueryCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var sqDto dto.ScheduledQueryDto
+	if err := json.NewDecoder(r.Body).Decode(&sqDto); err != nil {
+		writeError(w, r, common.NewCommonError(common.ErrcdJsonDecode, err))
+		return
+	}
+
+	result, created, err := ctrl.uc.Put(r.Context(), sqDto)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	common.WriteJson(w, status, result)
+}
+
+type DeleteScheduledQueryCtrl struct {
+	uc *ScheduledQueryUC
+}
+
+func NewDeleteScheduledQueryCtrl(uc *ScheduledQueryUC) *DeleteScheduledQueryCtrl {
+	return &DeleteScheduledQueryCtrl{uc: uc}
+}
+
+// ServeHTTP handles DELETE with query parameters name and team (the owning team).
+func (ctrl *DeleteScheduledQueryCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if err := ctrl.uc.Delete(r.Context(), q.Get("name"), q.Get("team")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,286 @@
+package jobStatus
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// Failed scheduled runs are retried after MinScheduledRetryDelay, doubling up to
+// MaxScheduledRetryDelay, until one succeeds or the day ends.
+const (
+	MinScheduledRetryDelay = time.Minute
+	MaxScheduledRetryDelay = time.Hour
+)
+
+type ScheduledQueryUC struct {
+	repo      ScheduledQueryRepo
+	getUC     *GetJobStatusesUC
+	deliverer Deliverer
+
+	mu      sync.Mutex
+	retries map[string]scheduledRetry
+}
+
+// scheduledRetry is when a failed run may be tried again and how long to wait after that.
+type scheduledRetry struct {
+	runDate time.Time
+	next    time.Time
+	delay   time.Duration
+}
+
+// NewScheduledQueryUC returns the use case. getUC runs the queries, so it needs saved views and
+// filters turned on for queries that use them.
+func NewScheduledQueryUC(repo ScheduledQueryRepo, getUC *GetJobStatusesUC, deliverer Deliverer) *ScheduledQueryUC {
+	return &ScheduledQueryUC{repo: repo, getUC: getUC, deliverer: deliverer, retries: map[string]scheduledRetry{}}
+}
+
+// Put adds or replaces a scheduled query owned by a team, like SavedViewUC.Put. A new query
+// whose time has already passed today runs at the scheduler's next check.
+func (uc *ScheduledQueryUC) Put(ctx context.Context, sqDto dto.ScheduledQueryDto) (result dto.ScheduledQueryDto, created bool, err error) {
+	sq, err := scheduledQueryDtoToDomain(sqDto)
+	if err != nil {
+		return dto.ScheduledQueryDto{}, false, err
+	}
+	if err := sq.Validate(); err != nil {
+		return dto.ScheduledQueryDto{}, false, err
+	}
+
+	current, found, err := uc.repo.GetScheduledQuery(ctx, sq.Name)
+	if err != nil {
+		return dto.ScheduledQueryDto{}, false, err
+	}
+	if found && current.Team != sq.Team {
+		return dto.ScheduledQueryDto{}, false, common.NewCommonError(common.ErrcdRepoDupeRow, fmt.Errorf("scheduled query %q belongs to team %q", sq.Name, current.Team))
+	}
+
+	sq.LastRunDate = current.LastRunDate
+	sq.UpdatedTs = time.Now().UTC()
+	if err := uc.repo.PutScheduledQuery(ctx, sq); err != nil {
+		return dto.ScheduledQueryDto{}, false, err
+	}
+	return scheduledQueryToDto(sq), !found, nil
+}
+
+// List returns team's scheduled queries, or every team's if team is empty.
+func (uc *ScheduledQueryUC) List(ctx context.Context, team string) ([]dto.ScheduledQueryDto, error) {
+	sqs, err := uc.repo.ListScheduledQueries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := []dto.ScheduledQueryDto{}
+	for _, sq := range sqs {
+		if len(team) == 0 || sq.Team == team {
+			result = append(result, scheduledQueryToDto(sq))
+		}
+	}
+	return result, nil
+}
+
+// Delete removes a scheduled query owned by team.
+func (uc *ScheduledQueryUC) Delete(ctx context.Context, name string, team string) error {
+	sq, found, err := uc.repo.GetScheduledQuery(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return common.NewCommonError(common.ErrcdNotFound, fmt.Errorf("scheduled query %q does not exist", name))
+	}
+	if sq.Team != team {
+		return common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("scheduled query %q belongs to team %q", name, sq.Team))
+	}
+	_, err = uc.repo.DeleteScheduledQuery(ctx, name)
+	return err
+}
+
+// RunDue runs every query that's due by now and hasn't run today, and returns how many it
+// delivered. A run is claimed before it starts, so only one instance runs it. If the query or
+// delivery fails, the claim is released and the run is retried at a later call, backing off
+// from MinScheduledRetryDelay to MaxScheduledRetryDelay. LastRunDate only stays set once a
+// delivery succeeds.
+func (uc *ScheduledQueryUC) RunDue(ctx context.Context, now time.Time) (int, error) {
+	sqs, err := uc.repo.ListScheduledQueries(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now = now.UTC()
+	runDate := TruncateToDate(now)
+	delivered := 0
+	for _, sq := range sqs {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+		if now.Before(sq.DueAt(runDate)) || !sq.LastRunDate.Before(runDate) || uc.waiting(sq.Name, runDate, now) {
+			continue
+		}
+		claimed, err := uc.repo.ClaimScheduledRun(ctx, sq.Name, runDate)
+		if err != nil {
+			log.Printf("scheduled query %s: claim failed: %v", sq.Name, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		result, err := uc.run(ctx, sq, runDate, now)
+		if err != nil {
+			log.Printf("scheduled query %s: query failed: %v", sq.Name, err)
+			uc.release(sq, runDate, now)
+			continue
+		}
+		if err := uc.deliverer.Deliver(ctx, sq, result); err != nil {
+			log.Printf("scheduled query %s: delivery to %s failed: %v", sq.Name, sq.DeliveryTarget, err)
+			uc.release(sq, runDate, now)
+			continue
+		}
+		uc.mu.Lock()
+		delete(uc.retries, sq.Name)
+		uc.mu.Unlock()
+		delivered++
+	}
+	return delivered, nil
+}
+
+// waiting reports whether name's run for runDate failed and its retry delay hasn't passed.
+func (uc *ScheduledQueryUC) waiting(name string, runDate time.Time, now time.Time) bool {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	retry, ok := uc.retries[name]
+	return ok && retry.runDate.Equal(runDate) && now.Before(retry.next)
+}
+
+// release gives up sq's claim on runDate after a failure and schedules a retry. If the release
+// fails, the claim stands and the query waits for tomorrow, as if it had run. It doesn't take the
+// run's ctx, because a run that failed since the scheduler is stopping still has to release.
+func (uc *ScheduledQueryUC) release(sq ScheduledQuery, runDate time.Time, now time.Time) {
+	if _, err := uc.repo.ReleaseScheduledRun(context.Background(), sq.Name, runDate, sq.LastRunDate); err != nil {
+		log.Printf("scheduled query %s: release failed, not retrying until tomorrow: %v", sq.Name, err)
+		return
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	for name, retry := range uc.retries {
+		// yesterday's failures, including deleted queries'
+		if retry.runDate.Before(runDate) {
+			delete(uc.retries, name)
+		}
+	}
+	retry, ok := uc.retries[sq.Name]
+	if !ok {
+		retry = scheduledRetry{runDate: runDate, delay: MinScheduledRetryDelay}
+	}
+	retry.next = now.Add(retry.delay)
+	retry.delay *= 2
+	if retry.delay > MaxScheduledRetryDelay {
+		retry.delay = MaxScheduledRetryDelay
+	}
+	uc.retries[sq.Name] = retry
+	log.Printf("scheduled query %s: retrying after %s", sq.Name, retry.next.Format(time.RFC3339))
+}
+
+func (uc *ScheduledQueryUC) run(ctx context.Context, sq ScheduledQuery, runDate time.Time, now time.Time) (dto.ScheduledQueryResultDto, error) {
+	busDt := runDate.AddDate(0, 0, sq.BusinessDateOffset).Format(dto.DateFormat)
+	params := QueryParams{AllowPartial: true, Filters: map[string]string{}}
+	for key, value := range sq.Filters {
+		params.Filters[key] = value
+	}
+
+	var jss []dto.JobStatusDto
+	var err error
+	if len(sq.View) > 0 {
+		jss, err = uc.getUC.GetByView(ctx, sq.View, busDt, params)
+	} else {
+		params.Filters["BusDt[eq]"] = busDt
+		jss, err = uc.getUC.GetByFilters(ctx, params)
+	}
+	if err != nil && !IsPartialResult(err) {
+		return dto.ScheduledQueryResultDto{}, err
+	}
+
+	result := dto.ScheduledQueryResultDto{
+		Name:         sq.Name,
+		Team:         sq.Team,
+		View:         sq.View,
+		BusinessDate: busDt,
+		RunTimestamp: now.Format(time.RFC3339Nano),
+		JobStatuses:  jss,
+	}
+	if result.JobStatuses == nil {
+		result.JobStatuses = []dto.JobStatusDto{}
+	}
+	for _, re := range RowErrorsOf(err) {
+		result.RowErrors = append(result.RowErrors, dto.RowErrorDto{Row: re.Row, Error: re.Err.Error()})
+	}
+	return result, nil
+}
+
+// RunScheduledQueries blocks, running due queries every interval until ctx is done. While paused
+// (if it isn't nil) returns true, nothing runs; queries that came due run once it's false again.
+func RunScheduledQueries(ctx context.Context, uc *ScheduledQueryUC, interval time.Duration, paused func() bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if paused != nil && paused() {
+				continue
+			}
+			var n int
+			err := common.CatchPanic("scheduled queries", func() (err error) {
+				n, err = uc.RunDue(ctx, now)
+				return err
+			})
+			if err != nil {
+				log.Printf("scheduled queries: %v", err)
+			} else if n > 0 {
+				log.Printf("scheduled queries: delivered %d", n)
+			}
+		}
+	}
+}
+
+func scheduledQueryDtoToDomain(sqDto dto.ScheduledQueryDto) (ScheduledQuery, error) {
+	runAt, err := time.Parse(scheduledQueryRunAtFormat, strings.TrimSpace(sqDto.RunAt))
+	if err != nil {
+		return ScheduledQuery{}, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("RunAt must be HH:MM: %w", err))
+	}
+	return ScheduledQuery{
+		Name:               sqDto.Name,
+		Team:               sqDto.Team,
+		View:               sqDto.View,
+		Filters:            sqDto.Filters,
+		BusinessDateOffset: sqDto.BusinessDateOffset,
+		RunAtHour:          runAt.Hour(),
+		RunAtMinute:        runAt.Minute(),
+		DeliveryKind:       sqDto.DeliveryKind,
+		DeliveryTarget:     sqDto.DeliveryTarget,
+	}, nil
+}
+
+func scheduledQueryToDto(sq ScheduledQuery) dto.ScheduledQueryDto {
+	sqDto := dto.ScheduledQueryDto{
+		Name:               sq.Name,
+		Team:               sq.Team,
+		View:               sq.View,
+		Filters:            sq.Filters,
+		BusinessDateOffset: sq.BusinessDateOffset,
+		RunAt:              fmt.Sprintf("%02d:%02d", sq.RunAtHour, sq.RunAtMinute),
+		DeliveryKind:       sq.DeliveryKind,
+		DeliveryTarget:     sq.DeliveryTarget,
+		UpdatedTimestamp:   sq.UpdatedTs.Format(time.RFC3339Nano),
+	}
+	if !sq.LastRunDate.IsZero() {
+		sqDto.LastRunDate = sq.LastRunDate.Format(dto.DateFormat)
+	}
+	return sqDto
+}
@@ -0,0 +1,150 @@
+package jobStatus_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbmemory"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// flakyDeliverer fails its first failures deliveries.
+type flakyDeliverer struct {
+	failures  int
+	attempts  int
+	delivered []dto.ScheduledQueryResultDto
+}
+
+func (d *flakyDeliverer) Deliver(ctx context.Context, sq jobStatus.ScheduledQuery, result dto.ScheduledQueryResultDto) error {
+	d.attempts++
+	if d.attempts <= d.failures {
+		return errors.New("webhook returned 503 Service Unavailable")
+	}
+	d.delivered = append(d.delivered, result)
+	return nil
+}
+
+func newScheduledQueryUC(t *testing.T, d jobStatus.Deliverer) (*jobStatus.ScheduledQueryUC, *dbmemory.RepoMemory) {
+	t.Helper()
+	repo := dbmemory.NewRepoMemory()
+	uc := jobStatus.NewScheduledQueryUC(repo, jobStatus.NewGetJobStatusesUC(repo, repo, repo), d)
+	_, _, err := uc.Put(context.Background(), dto.ScheduledQueryDto{
+		Name:           "morning-failures",
+		Team:           "ops",
+		Filters:        map[string]string{"JobId[eq]": "billing-load", "JobSt[eq]": "FAIL"},
+		RunAt:          "07:00",
+		DeliveryKind:   dto.DeliveryWebhook,
+		DeliveryTarget: "https://hooks.example.com/ops",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uc, repo
+}
+
+func lastRunDt(t *testing.T, repo *dbmemory.RepoMemory) string {
+	t.Helper()
+	sq, _, err := repo.GetScheduledQuery(context.Background(), "morning-failures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sq.LastRunDate.IsZero() {
+		return ""
+	}
+	return sq.LastRunDate.Format(dto.DateFormat)
+}
+
+func TestScheduledQueryRetriesFailedDeliveries(t *testing.T) {
+	d := &flakyDeliverer{failures: 2}
+	uc, repo := newScheduledQueryUC(t, d)
+	due := time.Date(2023, 6, 15, 7, 0, 0, 0, time.UTC)
+
+	for _, step := range []struct {
+		at            time.Duration // after due
+		wantDelivered int
+		wantAttempts  int
+		wantLastRun   string
+	}{
+		{-time.Minute, 0, 0, ""},              // not due yet
+		{0, 0, 1, ""},                         // fails; released and retried after a minute
+		{30 * time.Second, 0, 1, ""},          // still backing off
+		{time.Minute, 0, 2, ""},               // fails again; the delay doubles
+		{2 * time.Minute, 0, 2, ""},           // still backing off
+		{3 * time.Minute, 1, 3, "2023-06-15"}, // delivered, and only now marked run
+		{4 * time.Minute, 0, 3, "2023-06-15"}, // done for the day
+		{24 * time.Hour, 1, 4, "2023-06-16"},  // next day runs without waiting
+		{24*time.Hour + time.Minute, 0, 4, "2023-06-16"},
+	} {
+		n, err := uc.RunDue(context.Background(), due.Add(step.at))
+		if err != nil {
+			t.Fatalf("%s: %v", step.at, err)
+		}
+		if n != step.wantDelivered || d.attempts != step.wantAttempts {
+			t.Errorf("%s: got %d delivered after %d attempts, want %d after %d", step.at, n, d.attempts, step.wantDelivered, step.wantAttempts)
+		}
+		if got := lastRunDt(t, repo); got != step.wantLastRun {
+			t.Errorf("%s: got LastRunDt %q, want %q", step.at, got, step.wantLastRun)
+		}
+	}
+}
+
+func TestScheduledQueryRetryDelayIsCapped(t *testing.T) {
+	d := &flakyDeliverer{failures: 1000}
+	uc, _ := newScheduledQueryUC(t, d)
+	now := time.Date(2023, 6, 15, 7, 0, 0, 0, time.UTC)
+
+	var waits []time.Duration
+	last := now
+	for now.Before(time.Date(2023, 6, 15, 23, 59, 0, 0, time.UTC)) {
+		before := d.attempts
+		if _, err := uc.RunDue(context.Background(), now); err != nil {
+			t.Fatal(err)
+		}
+		if d.attempts > before {
+			waits = append(waits, now.Sub(last))
+			last = now
+		}
+		now = now.Add(time.Minute)
+	}
+
+	want := []time.Duration{0, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 16 * time.Minute, 32 * time.Minute, time.Hour, time.Hour}
+	for i, w := range want {
+		if i >= len(waits) || waits[i] != w {
+			t.Fatalf("got waits %v, want them to start %v", waits, want)
+		}
+	}
+	for _, w := range waits[len(want):] {
+		if w != time.Hour {
+			t.Errorf("got a wait of %s after the cap, want %s", w, time.Hour)
+		}
+	}
+}
+
+func TestReleaseScheduledRun(t *testing.T) {
+	_, repo := newScheduledQueryUC(t, &flakyDeliverer{})
+	day1 := time.Date(2023, 6, 14, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	ctx := context.Background()
+
+	for _, step := range []struct {
+		name string
+		op   func() (bool, error)
+		want bool
+	}{
+		{"claim day 1", func() (bool, error) { return repo.ClaimScheduledRun(ctx, "morning-failures", day1) }, true},
+		{"claim day 2", func() (bool, error) { return repo.ClaimScheduledRun(ctx, "morning-failures", day2) }, true},
+		{"release another day's claim", func() (bool, error) { return repo.ReleaseScheduledRun(ctx, "morning-failures", day1, time.Time{}) }, false},
+		{"release day 2", func() (bool, error) { return repo.ReleaseScheduledRun(ctx, "morning-failures", day2, day1) }, true},
+		{"release day 2 again", func() (bool, error) { return repo.ReleaseScheduledRun(ctx, "morning-failures", day2, day1) }, false},
+		{"claim day 2 again", func() (bool, error) { return repo.ClaimScheduledRun(ctx, "morning-failures", day2) }, true},
+		{"release a deleted query", func() (bool, error) { return repo.ReleaseScheduledRun(ctx, "gone", day2, day1) }, false},
+	} {
+		got, err := step.op()
+		if err != nil || got != step.want {
+			t.Errorf("%s: got %t, %v, want %t", step.name, got, err, step.want)
+		}
+	}
+}
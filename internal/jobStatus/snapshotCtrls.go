@@ -0,0 +1,53 @@
+package jobStatus
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// SnapshotPath is the admin route for snapshot exports.
+const SnapshotPath = "/admin/snapshot"
+
+type GetSnapshotCtrl struct {
+	uc *SnapshotUC
+}
+
+func NewGetSnapshotCtrl(uc *SnapshotUC) *GetSnapshotCtrl {
+	return &GetSnapshotCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET and streams the archive. Errors once the archive has started can't
+// change the status, so they're logged and the archive is left without its zip directory.
+func (ctrl *GetSnapshotCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := "gojst-snapshot-" + time.Now().UTC().Format("20060102T150405Z") + ".zip"
+	zw := &snapshotResponseWriter{w: w, name: name}
+
+	manifest, err := ctrl.uc.Export(r.Context(), zw)
+	if err != nil {
+		if !zw.started {
+			writeError(w, r, err)
+			return
+		}
+		log.Printf("%s %s snapshot aborted: %v", r.Method, r.URL.Path, err)
+		return
+	}
+	log.Printf("%s %s snapshot %s with %d files", r.Method, r.URL.Path, name, len(manifest.Files))
+}
+
+// snapshotResponseWriter sends headers on the first write, like common.JsonStream.
+type snapshotResponseWriter struct {
+	w       http.ResponseWriter
+	name    string
+	started bool
+}
+
+func (sw *snapshotResponseWriter) Write(p []byte) (int, error) {
+	if !sw.started {
+		sw.started = true
+		sw.w.Header().Set("Content-Type", "application/zip")
+		sw.w.Header().Set("Content-Disposition", `attachment; filename="`+sw.name+`"`)
+		sw.w.WriteHeader(http.StatusOK)
+	}
+	return sw.w.Write(p)
+}
@@ -0,0 +1,241 @@
+package jobStatus
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// Snapshot archive entries. The manifest is written last because it holds the others' sums.
+const (
+	SnapshotJobStatusesFile      = "job-statuses.jsonl"
+	SnapshotSavedViewsFile       = "saved-views.jsonl"
+	SnapshotScheduledQueriesFile = "scheduled-queries.jsonl"
+	SnapshotJobAliasesFile       = "job-aliases.jsonl"
+	SnapshotManifestFile         = "manifest.json"
+)
+
+type SnapshotUC struct {
+	repo SnapshotRepo
+}
+
+func NewSnapshotUC(repo SnapshotRepo) *SnapshotUC {
+	return &SnapshotUC{repo: repo}
+}
+
+// Export writes a zip archive of every status and all configuration, read in one repo snapshot,
+// to w, and returns its manifest. The zip writer buffers, so an error at the start of the snapshot
+// returns before anything reaches w. An error after output starts leaves w holding a truncated
+// archive, which VerifySnapshot rejects.
+func (uc *SnapshotUC) Export(ctx context.Context, w io.Writer) (dto.SnapshotManifestDto, error) {
+	manifest := dto.SnapshotManifestDto{
+		FormatVersion: dto.SnapshotFormatVersion,
+		DtoVersion:    dto.Version,
+		CreatedTs:     time.Now().UTC().Format(dto.TimestampFormat),
+	}
+	zw := zip.NewWriter(w)
+
+	err := uc.repo.Snapshot(ctx, func(sr SnapshotReader) error {
+		entry, err := newSnapshotEntry(zw, SnapshotJobStatusesFile)
+		if err != nil {
+			return err
+		}
+		err = sr.ForEachJobStatus(func(js JobStatus) error {
+			return entry.write(dto.SnapshotJobStatusDto{
+				JobStatusDto:  domainToDto(js),
+				IntegrityHash: hex.EncodeToString(js.IntegrityHash),
+			})
+		})
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, entry.file())
+
+		views, err := sr.ListSavedViews()
+		if err != nil {
+			return err
+		}
+		if entry, err = newSnapshotEntry(zw, SnapshotSavedViewsFile); err != nil {
+			return err
+		}
+		for _, view := range views {
+			if err := entry.write(savedViewToDto(view)); err != nil {
+				return err
+			}
+		}
+		manifest.Files = append(manifest.Files, entry.file())
+
+		queries, err := sr.ListScheduledQueries()
+		if err != nil {
+			return err
+		}
+		if entry, err = newSnapshotEntry(zw, SnapshotScheduledQueriesFile); err != nil {
+			return err
+		}
+		for _, query := range queries {
+			if err := entry.write(scheduledQueryToDto(query)); err != nil {
+				return err
+			}
+		}
+		manifest.Files = append(manifest.Files, entry.file())
+
+		aliases, err := sr.ListJobAliases()
+		if err != nil {
+			return err
+		}
+		if entry, err = newSnapshotEntry(zw, SnapshotJobAliasesFile); err != nil {
+			return err
+		}
+		for _, alias := range aliases {
+			if err := entry.write(jobAliasToDto(alias)); err != nil {
+				return err
+			}
+		}
+		manifest.Files = append(manifest.Files, entry.file())
+		return nil
+	})
+	if err != nil {
+		return dto.SnapshotManifestDto{}, err
+	}
+
+	mw, err := zw.Create(SnapshotManifestFile)
+	if err != nil {
+		return dto.SnapshotManifestDto{}, err
+	}
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return dto.SnapshotManifestDto{}, err
+	}
+	// Close writes the zip directory; without it the archive can't be opened
+	if err := zw.Close(); err != nil {
+		return dto.SnapshotManifestDto{}, err
+	}
+	return manifest, nil
+}
+
+// snapshotEntry writes JSON lines to one archive entry, counting and hashing them on the way.
+type snapshotEntry struct {
+	name  string
+	enc   *json.Encoder
+	sum   hash.Hash
+	rows  int64
+	bytes int64
+}
+
+func newSnapshotEntry(zw *zip.Writer, name string) (*snapshotEntry, error) {
+	w, err := zw.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	entry := &snapshotEntry{name: name, sum: sha256.New()}
+	entry.enc = json.NewEncoder(io.MultiWriter(w, entry.sum, byteCounter{&entry.bytes}))
+	return entry, nil
+}
+
+func (e *snapshotEntry) write(v any) error {
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+	e.rows++
+	return nil
+}
+
+func (e *snapshotEntry) file() dto.SnapshotFileDto {
+	return dto.SnapshotFileDto{Name: e.name, Rows: e.rows, Bytes: e.bytes, Sha256: hex.EncodeToString(e.sum.Sum(nil))}
+}
+
+type byteCounter struct {
+	n *int64
+}
+
+func (c byteCounter) Write(p []byte) (int, error) {
+	*c.n += int64(len(p))
+	return len(p), nil
+}
+
+// VerifySnapshot checks that a snapshot archive is complete: it has a manifest, and every file the
+// manifest lists is there with the listed size, line count, and SHA-256. It returns the manifest
+// and every problem found, joined.
+func VerifySnapshot(r io.ReaderAt, size int64) (dto.SnapshotManifestDto, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return dto.SnapshotManifestDto{}, fmt.Errorf("not a complete snapshot archive: %w", err)
+	}
+	entries := map[string]*zip.File{}
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+
+	var manifest dto.SnapshotManifestDto
+	mf, ok := entries[SnapshotManifestFile]
+	if !ok {
+		return manifest, errors.New("snapshot has no " + SnapshotManifestFile)
+	}
+	rc, err := mf.Open()
+	if err != nil {
+		return manifest, err
+	}
+	err = json.NewDecoder(rc).Decode(&manifest)
+	rc.Close()
+	if err != nil {
+		return manifest, fmt.Errorf("reading %s: %w", SnapshotManifestFile, err)
+	}
+	if manifest.FormatVersion != dto.SnapshotFormatVersion {
+		return manifest, fmt.Errorf("snapshot format %q isn't %q", manifest.FormatVersion, dto.SnapshotFormatVersion)
+	}
+
+	var errs []error
+	for _, file := range manifest.Files {
+		f, ok := entries[file.Name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s is missing", file.Name))
+			continue
+		}
+		if err := verifySnapshotFile(f, file); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return manifest, errors.Join(errs...)
+}
+
+func verifySnapshotFile(f *zip.File, want dto.SnapshotFileDto) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	sum := sha256.New()
+	var rows, n int64
+	tr := io.TeeReader(rc, sum)
+	buf := make([]byte, 32*1024)
+	for {
+		k, err := tr.Read(buf)
+		n += int64(k)
+		rows += int64(bytes.Count(buf[:k], []byte{'\n'}))
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+	}
+
+	got := hex.EncodeToString(sum.Sum(nil))
+	if n != want.Bytes || rows != want.Rows || got != want.Sha256 {
+		return fmt.Errorf("%s has %d rows, %d bytes, sha256 %s; manifest says %d rows, %d bytes, sha256 %s",
+			f.Name, rows, n, got, want.Rows, want.Bytes, want.Sha256)
+	}
+	return nil
+}
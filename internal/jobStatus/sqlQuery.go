@@ -0,0 +1,256 @@
+package jobStatus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Analyst SQL limits. The repo also runs the query in a read-only transaction with a statement
+// timeout, so ValidateReadOnlySql isn't the only guard.
+const (
+	MaxSqlLen         = 10000
+	MaxSqlRows        = 5000
+	DefaultSqlRows    = 1000
+	MaxSqlTimeout     = 30 * time.Second
+	DefaultSqlTimeout = 10 * time.Second
+)
+
+// SqlTables are the tables analyst SQL can read: statuses and their rollups, not configuration.
+var SqlTables = map[string]bool{
+	"JobStatus":            true,
+	"JobStatusDailyRollup": true,
+}
+
+// sqlFunctions are the functions analyst SQL can call. Anything else, including pg_sleep and
+// the file and admin functions, is rejected.
+var sqlFunctions = map[string]bool{
+	"count": true, "sum": true, "min": true, "max": true, "avg": true,
+	"coalesce": true, "nullif": true, "greatest": true, "least": true,
+	"lower": true, "upper": true, "length": true, "btrim": true, "left": true, "right": true,
+	"date_trunc": true, "date_part": true, "now": true, "age": true, "to_char": true,
+	"round": true, "floor": true, "ceil": true, "abs": true,
+	"row_number": true, "rank": true, "dense_rank": true, "lag": true, "lead": true,
+	"percentile_cont": true, "percentile_disc": true, "string_agg": true, "bool_and": true, "bool_or": true,
+}
+
+// sqlBannedWords can't appear as bare words. They're either writes, DDL, or, for WITH and FOR,
+// forms that would let a query reach tables through names the allowlist can't check.
+var sqlBannedWords = map[string]bool{
+	"insert": true, "update": true, "delete": true, "merge": true, "upsert": true,
+	"create": true, "drop": true, "alter": true, "truncate": true, "grant": true, "revoke": true,
+	"copy": true, "lock": true, "into": true, "for": true, "with": true, "execute": true,
+	"call": true, "do": true, "set": true, "reset": true, "vacuum": true, "analyze": true,
+	"listen": true, "notify": true, "prepare": true, "table": true, "only": true,
+}
+
+// sqlParenWords are keywords that can be followed by "(" without being function calls.
+var sqlParenWords = map[string]bool{
+	"in": true, "exists": true, "any": true, "all": true, "some": true, "not": true, "and": true,
+	"or": true, "as": true, "cast": true, "over": true, "filter": true, "using": true, "on": true,
+	"select": true, "from": true, "join": true, "lateral": true, "values": true, "where": true,
+	"having": true, "by": true, "then": true, "else": true, "when": true, "within": true,
+	"union": true, "intersect": true, "except": true, "is": true, "like": true, "ilike": true,
+	"between": true, "distinct": true,
+}
+
+// sqlFromEndWords end a FROM list, so a comma after them isn't another table.
+var sqlFromEndWords = map[string]bool{
+	"where": true, "group": true, "having": true, "order": true, "limit": true, "offset": true,
+	"window": true, "union": true, "intersect": true, "except": true,
+}
+
+// SqlResult is one analyst query's result. Row values are what database/sql scanned, with
+// []byte turned into strings.
+type SqlResult struct {
+	Columns   []string
+	Rows      [][]any
+	Truncated bool
+}
+
+type sqlToken struct {
+	text   string
+	quoted bool // a "quoted identifier"
+	ident  bool
+}
+
+// ValidateReadOnlySql checks that query is one SELECT that only reads SqlTables and only calls
+// sqlFunctions. It's deliberately strict: no comments, CTEs, dollar quoting, escape strings,
+// schema-qualified tables, or set-returning functions in FROM. Analysts can ask for more.
+func ValidateReadOnlySql(query string) error {
+	if len(strings.TrimSpace(query)) == 0 {
+		return propsError("Sql is required")
+	}
+	if len(query) > MaxSqlLen {
+		return propsError(fmt.Sprintf("Sql is longer than %d characters", MaxSqlLen))
+	}
+	tokens, err := lexSql(query)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 || !tokens[0].ident || tokens[0].quoted || strings.ToLower(tokens[0].text) != "select" {
+		return propsError("Sql must be a SELECT")
+	}
+
+	// inFrom[depth] is true while a FROM list is open at that paren depth
+	inFrom := []bool{false}
+	expectTable := false
+	for i, tok := range tokens {
+		word := ""
+		if tok.ident && !tok.quoted {
+			word = strings.ToLower(tok.text)
+		}
+		next := sqlToken{}
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+
+		if expectTable {
+			expectTable = false
+			switch {
+			case word == "lateral":
+				expectTable = true
+				continue
+			case tok.text == "(" && !tok.ident:
+				if !next.ident || next.quoted || strings.ToLower(next.text) != "select" {
+					return propsError("a parenthesized FROM item must be a subquery")
+				}
+			case tok.ident:
+				name := tok.text
+				if !tok.quoted {
+					name = word
+				}
+				if !SqlTables[name] {
+					return propsError(fmt.Sprintf("table %q is not one of %s", name, sqlTableList()))
+				}
+				if next.text == "." || next.text == "(" {
+					return propsError(fmt.Sprintf("table %q can't be schema-qualified or called", name))
+				}
+			default:
+				return propsError(fmt.Sprintf("expected a table name, got %q", tok.text))
+			}
+		}
+
+		switch {
+		case tok.ident && sqlBannedWords[word]:
+			return propsError(fmt.Sprintf("Sql can't use %s", strings.ToUpper(word)))
+		case tok.ident && next.text == "(" && !next.ident:
+			if tok.quoted || (!sqlFunctions[word] && !sqlParenWords[word]) {
+				return propsError(fmt.Sprintf("function %q is not allowed", tok.text))
+			}
+		}
+
+		switch {
+		case word == "from":
+			// FROM inside function arguments (EXTRACT, TRIM) isn't a table list; none of those are allowed
+			inFrom[len(inFrom)-1] = true
+			expectTable = true
+		case word == "join":
+			expectTable = true
+		case sqlFromEndWords[word]:
+			inFrom[len(inFrom)-1] = false
+		case tok.text == "(" && !tok.ident:
+			inFrom = append(inFrom, false)
+		case tok.text == ")" && !tok.ident:
+			if len(inFrom) == 1 {
+				return propsError("Sql has an unmatched )")
+			}
+			inFrom = inFrom[:len(inFrom)-1]
+		case tok.text == "," && !tok.ident && inFrom[len(inFrom)-1]:
+			expectTable = true
+		}
+	}
+	if expectTable {
+		return propsError("Sql ends where a table name was expected")
+	}
+	if len(inFrom) != 1 {
+		return propsError("Sql has an unmatched (")
+	}
+	return nil
+}
+
+// lexSql splits query into identifiers, quoted identifiers, literals, and punctuation. String
+// and number literals are returned as non-identifier tokens. It rejects anything it doesn't
+// need to understand, so a clever lexing trick fails here instead of reaching the database.
+func lexSql(query string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && i+1 < len(query) && query[i+1] == '-', c == '/' && i+1 < len(query) && query[i+1] == '*':
+			return nil, propsError("Sql can't have comments")
+		case c == ';':
+			return nil, propsError("Sql must be one statement without ;")
+		case c == '$' || c == '\\' || c == '&' || c == '#' || c == '?' || c == '@' || c == '`' || c == '[' || c == ']' || c == '{' || c == '}' || c == '^' || c == '~':
+			return nil, propsError(fmt.Sprintf("Sql can't use %q", c))
+		case c == '\'':
+			if i > 0 && isSqlIdentChar(query[i-1]) {
+				return nil, propsError("Sql can't use prefixed strings like E'...'")
+			}
+			end, err := sqlQuotedEnd(query, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{text: query[i:end]})
+			i = end
+		case c == '"':
+			end, err := sqlQuotedEnd(query, i, '"')
+			if err != nil {
+				return nil, err
+			}
+			name := strings.ReplaceAll(query[i+1:end-1], `""`, `"`)
+			tokens = append(tokens, sqlToken{text: name, ident: true, quoted: true})
+			i = end
+		case isSqlIdentChar(c) && !(c >= '0' && c <= '9'):
+			start := i
+			for i < len(query) && isSqlIdentChar(query[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{text: query[start:i], ident: true})
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(query) && ((query[i] >= '0' && query[i] <= '9') || query[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{text: query[start:i]})
+		case strings.IndexByte("(),.*=<>!+-/%:|", c) >= 0:
+			tokens = append(tokens, sqlToken{text: string(c)})
+			i++
+		default:
+			return nil, propsError(fmt.Sprintf("Sql can't use %q", c))
+		}
+	}
+	return tokens, nil
+}
+
+func isSqlIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// sqlQuotedEnd returns the index after the quote that closes the quoted text starting at start.
+// A doubled quote is an escaped quote.
+func sqlQuotedEnd(query string, start int, quote byte) (int, error) {
+	for i := start + 1; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1, nil
+	}
+	return 0, propsError("Sql has an unterminated quote")
+}
+
+func sqlTableList() string {
+	names := make([]string, 0, len(SqlTables))
+	for name := range SqlTables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
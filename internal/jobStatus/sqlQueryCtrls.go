@@ -0,0 +1,41 @@
+package jobStatus
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// SqlQueryPath is the admin route for analyst SQL.
+const SqlQueryPath = "/admin/sql"
+
+type SqlQueryCtrl struct {
+	uc *SqlQueryUC
+}
+
+func NewSqlQueryCtrl(uc *SqlQueryUC) *SqlQueryCtrl {
+	return &SqlQueryCtrl{uc: uc}
+}
+
+// ServeHTTP handles POST of a SqlQueryDto.
+func (ctrl *SqlQueryCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var qDto dto.SqlQueryDto
+	if err := json.NewDecoder(io.LimitReader(r.Body, 2*MaxSqlLen)).Decode(&qDto); err != nil {
+		writeError(w, r, common.NewCommonError(common.ErrcdJsonDecode, err))
+		return
+	}
+
+	clientIp := "unknown"
+	if ip, err := common.ClientIpOf(r); err == nil {
+		clientIp = ip.String()
+	}
+	result, err := ctrl.uc.Run(r.Context(), qDto, clientIp)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	common.WriteJson(w, http.StatusOK, result)
+}
@@ -0,0 +1,47 @@
+package jobStatus
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+type SqlQueryUC struct {
+	repo SqlRepo
+}
+
+func NewSqlQueryUC(repo SqlRepo) *SqlQueryUC {
+	return &SqlQueryUC{repo: repo}
+}
+
+// Run validates and runs an analyst query. Every query is logged, so there's a record of who
+// asked what (with the client address the controller adds).
+func (uc *SqlQueryUC) Run(ctx context.Context, qDto dto.SqlQueryDto, clientIp string) (dto.SqlResultDto, error) {
+	maxRows := DefaultSqlRows
+	if qDto.MaxRows != 0 {
+		if qDto.MaxRows < 1 || qDto.MaxRows > MaxSqlRows {
+			return dto.SqlResultDto{}, propsError(fmt.Sprintf("MaxRows must be 1 to %d", MaxSqlRows))
+		}
+		maxRows = qDto.MaxRows
+	}
+	timeout := DefaultSqlTimeout
+	if qDto.TimeoutSeconds != 0 {
+		timeout = time.Duration(qDto.TimeoutSeconds) * time.Second
+		if qDto.TimeoutSeconds < 1 || timeout > MaxSqlTimeout {
+			return dto.SqlResultDto{}, propsError(fmt.Sprintf("TimeoutSeconds must be 1 to %d", MaxSqlTimeout/time.Second))
+		}
+	}
+	if err := ValidateReadOnlySql(qDto.Sql); err != nil {
+		return dto.SqlResultDto{}, err
+	}
+
+	log.Printf("analyst sql from %s: %q", clientIp, qDto.Sql)
+	result, err := uc.repo.QueryReadOnly(ctx, qDto.Sql, maxRows, timeout)
+	if err != nil {
+		return dto.SqlResultDto{}, err
+	}
+	return dto.SqlResultDto{Columns: result.Columns, Rows: result.Rows, Truncated: result.Truncated}, nil
+}
@@ -0,0 +1,118 @@
+package jobStatus
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+func TestValidateReadOnlySql(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		sql     string
+		wantErr string // empty means the query is allowed
+	}{
+		{"simple select", `SELECT "JobId", count(*) FROM "JobStatus" GROUP BY "JobId"`, ""},
+		{"join and subquery", `SELECT s."JobId" FROM "JobStatus" s JOIN (SELECT "JobId" FROM "JobStatusDailyRollup") r ON r."JobId" = s."JobId"`, ""},
+		{"keywords in strings", `SELECT 'drop table; -- update' AS "note" FROM "JobStatus"`, ""},
+		{"window function", `SELECT row_number() OVER (PARTITION BY "JobId" ORDER BY "JobStatusTimestamp") FROM "JobStatus"`, ""},
+
+		{"empty", "  ", "Sql is required"},
+		{"not a select", `VALUES (1)`, "must be a SELECT"},
+
+		// multiple statements
+		{"second statement", `SELECT 1 FROM "JobStatus"; DELETE FROM "JobStatus"`, "one statement"},
+		{"trailing semicolon", `SELECT 1 FROM "JobStatus";`, "one statement"},
+		{"semicolon in a quoted identifier is fine", `SELECT 1 AS "a;b" FROM "JobStatus"`, ""},
+
+		// comments hiding keywords
+		{"line comment", "SELECT 1 FROM \"JobStatus\" -- ; DROP TABLE \"JobStatus\"", "comments"},
+		{"block comment", `SELECT 1 FROM "JobStatus" /* */ WHERE 1 = 1`, "comments"},
+		{"block comment splitting a word", `SELECT 1 FROM "JobStatus" WHERE 1 = 1 UNI/**/ON SELECT 1`, "comments"},
+		{"comment after a string", `SELECT 'x'--'` + "\n" + ` FROM "JobStatus"`, "comments"},
+
+		// dollar quoting
+		{"dollar quoted string", `SELECT $$x$$ FROM "JobStatus"`, `'$'`},
+		{"tagged dollar quote", `SELECT $q$ '; DELETE FROM "JobStatus"; $q$ FROM "JobStatus"`, `'$'`},
+		{"positional parameter", `SELECT 1 FROM "JobStatus" WHERE "JobId" = $1`, `'$'`},
+		{"escape string", `SELECT E'\x27' FROM "JobStatus"`, "prefixed strings"},
+
+		// row locks
+		{"for update", `SELECT * FROM "JobStatus" FOR UPDATE`, "FOR"},
+		{"for share in a subquery", `SELECT * FROM (SELECT * FROM "JobStatus" FOR SHARE) q`, "FOR"},
+		{"for no key update", `SELECT * FROM "JobStatus" FOR NO KEY UPDATE`, "FOR"},
+
+		// CTEs, including writable ones
+		{"cte", `WITH q AS (SELECT * FROM "JobStatus") SELECT * FROM q`, "must be a SELECT"},
+		{"writable cte", `WITH d AS (DELETE FROM "JobStatus" RETURNING *) SELECT * FROM d`, "must be a SELECT"},
+		{"cte in a subquery", `SELECT * FROM (WITH d AS (DELETE FROM "JobStatus" RETURNING *) SELECT * FROM d) q`, "must be a subquery"},
+
+		// SELECT INTO creates a table
+		{"select into", `SELECT * INTO "Copy" FROM "JobStatus"`, "INTO"},
+		{"select into temp", `SELECT * INTO TEMP "Copy" FROM "JobStatus"`, "INTO"},
+
+		// side-effect and admin functions
+		{"pg_sleep", `SELECT pg_sleep(60) FROM "JobStatus"`, `function "pg_sleep"`},
+		{"setval", `SELECT setval('seq', 1) FROM "JobStatus"`, `function "setval"`},
+		{"set_config", `SELECT set_config('statement_timeout', '0', false) FROM "JobStatus"`, `function "set_config"`},
+		{"file read", `SELECT pg_read_file('/etc/passwd') FROM "JobStatus"`, `function "pg_read_file"`},
+		{"dblink", `SELECT * FROM "JobStatus" WHERE "JobId" IN (SELECT dblink('host=x', 'DELETE'))`, `function "dblink"`},
+		{"quoted function name", `SELECT "count"(*) FROM "JobStatus"`, `function "count"`},
+		{"nextval in where", `SELECT 1 FROM "JobStatus" WHERE nextval('seq') > 0`, `function "nextval"`},
+		{"schema-qualified function", `SELECT pg_catalog.pg_sleep(1) FROM "JobStatus"`, `function "pg_sleep"`},
+
+		// tables outside the allowlist
+		{"other table", `SELECT * FROM "SavedView"`, "not one of"},
+		{"schema-qualified table", `SELECT * FROM "JobStatus"."x"`, "schema-qualified"},
+		{"catalog table", `SELECT * FROM pg_catalog.pg_authid`, "not one of"},
+		{"table after a comma", `SELECT * FROM "JobStatus", pg_user`, "not one of"},
+		{"set-returning function", `SELECT * FROM generate_series(1, 10)`, "not one of"},
+		{"lateral function", `SELECT * FROM "JobStatus", LATERAL pg_ls_dir('.')`, "not one of"},
+
+		{"unmatched paren", `SELECT (1 FROM "JobStatus"`, "unmatched ("},
+		{"unterminated string", `SELECT 'x FROM "JobStatus"`, "unterminated"},
+		{"too long", "SELECT " + strings.Repeat("1 + ", MaxSqlLen/4) + "1", "longer than"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateReadOnlySql(tc.sql)
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Errorf("got %v, want no error", err)
+			case tc.wantErr != "" && err == nil:
+				t.Errorf("got no error, want one containing %q", tc.wantErr)
+			case tc.wantErr != "" && !strings.Contains(err.Error(), tc.wantErr):
+				t.Errorf("got %v, want an error containing %q", err, tc.wantErr)
+			case err != nil && common.ErrorCode(err) != common.ErrcdDomainProps:
+				t.Errorf("got code %s, want %s", common.ErrorCode(err), common.ErrcdDomainProps)
+			}
+		})
+	}
+}
+
+func TestLexSql(t *testing.T) {
+	for _, tc := range []struct {
+		sql  string
+		want []sqlToken
+	}{
+		{`SELECT "Job""Id" FROM x`, []sqlToken{{text: "SELECT", ident: true}, {text: `Job"Id`, ident: true, quoted: true}, {text: "FROM", ident: true}, {text: "x", ident: true}}},
+		{`'it''s' 12.5 <> a_1`, []sqlToken{{text: `'it''s'`}, {text: "12.5"}, {text: "<"}, {text: ">"}, {text: "a_1", ident: true}}},
+		{"a\t(\r\nb)", []sqlToken{{text: "a", ident: true}, {text: "("}, {text: "b", ident: true}, {text: ")"}}},
+	} {
+		got, err := lexSql(tc.sql)
+		if err != nil {
+			t.Errorf("%q: %v", tc.sql, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: got %+v, want %+v", tc.sql, got, tc.want)
+		}
+	}
+
+	for _, sql := range []string{`a\b`, "a`b`", `a @> b`, `"unterminated`, "a\x00"} {
+		if _, err := lexSql(sql); err == nil {
+			t.Errorf("%q: got no error, want one", sql)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package jobStatus
+
+import (
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// StatusBoardPath is the route for wallboard summaries.
+const StatusBoardPath = "/status-board"
+
+type GetStatusBoardCtrl struct {
+	uc *StatusBoardUC
+}
+
+func NewGetStatusBoardCtrl(uc *StatusBoardUC) *GetStatusBoardCtrl {
+	return &GetStatusBoardCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameters view and optional busDt and asOf.
+func (ctrl *GetStatusBoardCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	result, err := ctrl.uc.Get(r.Context(), q.Get("view"), q.Get("busDt"), q.Get("asOf"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
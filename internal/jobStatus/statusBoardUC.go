@@ -0,0 +1,110 @@
+package jobStatus
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// StatusBoardUC builds wallboard summaries so a UI makes one call per view instead of one per job.
+type StatusBoardUC struct {
+	views SavedViewRepo
+	board BoardRepo
+}
+
+func NewStatusBoardUC(views SavedViewRepo, board BoardRepo) *StatusBoardUC {
+	return &StatusBoardUC{views: views, board: board}
+}
+
+// Get returns the current state of every job in the named view on businessDate (empty means
+// today, UTC). Jobs are in view order. A job whose latest status is for an application the
+// view doesn't list is left off; a job with no status is NOT_STARTED.
+// If asOf (RFC 3339) is set, the board is what it would have shown then, from the statuses
+// received by that time, and an empty businessDate means asOf's date.
+func (uc *StatusBoardUC) Get(ctx context.Context, viewName string, businessDate string, asOfTs string) (dto.StatusBoardDto, error) {
+	asOf, err := parseAsOf(asOfTs)
+	if err != nil {
+		return dto.StatusBoardDto{}, err
+	}
+	now := time.Now().UTC()
+	if !asOf.IsZero() {
+		now = asOf.UTC()
+	}
+	busDt := TruncateToDate(now)
+	if len(businessDate) > 0 {
+		if busDt, err = parseDateProp("BusinessDate", businessDate); err != nil {
+			return dto.StatusBoardDto{}, err
+		}
+	}
+
+	view, err := getSavedView(ctx, uc.views, viewName)
+	if err != nil {
+		return dto.StatusBoardDto{}, err
+	}
+	latest, err := uc.board.GetLatestByJobIds(ctx, view.JobIds, busDt, asOf)
+	if err != nil {
+		return dto.StatusBoardDto{}, err
+	}
+
+	byJob := make(map[JobIdType]JobStatus, len(latest))
+	for _, js := range filterApplications(latest, view.ApplicationIds) {
+		byJob[js.JobId] = js
+	}
+	inOtherApp := map[JobIdType]bool{}
+	for _, js := range latest {
+		if _, ok := byJob[js.JobId]; !ok {
+			inOtherApp[js.JobId] = true
+		}
+	}
+
+	board := dto.StatusBoardDto{
+		View:          view.Name,
+		BusinessDate:  busDt.Format(dto.DateFormat),
+		AsOfTimestamp: now.Format(time.RFC3339Nano),
+		Jobs:          []dto.StatusBoardJobDto{},
+	}
+	for _, jobId := range view.JobIds {
+		if inOtherApp[jobId] {
+			continue
+		}
+		js, ok := byJob[jobId]
+		if !ok {
+			board.Jobs = append(board.Jobs, dto.StatusBoardJobDto{JobId: string(jobId), State: dto.BoardStateNotStarted})
+			board.Counts.NotStarted++
+			continue
+		}
+
+		state := boardState(js.JobStatusCode)
+		switch state {
+		case dto.BoardStateRunning:
+			board.Counts.Running++
+		case dto.BoardStateSucceeded:
+			board.Counts.Succeeded++
+		case dto.BoardStateFailed:
+			board.Counts.Failed++
+		}
+		board.Jobs = append(board.Jobs, dto.StatusBoardJobDto{
+			JobId:              string(js.JobId),
+			State:              state,
+			ApplicationId:      js.ApplicationId,
+			JobStatusTimestamp: js.JobStatusTimestamp.Format(time.RFC3339Nano),
+			RunId:              string(js.RunId),
+			HostId:             string(js.HostId),
+			Links:              linksToDto(js.Links),
+		})
+	}
+	return board, nil
+}
+
+func boardState(code JobStatusCodeType) string {
+	switch code {
+	case JobStatus_START:
+		return dto.BoardStateRunning
+	case JobStatus_SUCCEED:
+		return dto.BoardStateSucceeded
+	case JobStatus_FAIL:
+		return dto.BoardStateFailed
+	}
+	return dto.BoardStateNotStarted
+}
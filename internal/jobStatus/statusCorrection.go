@@ -0,0 +1,71 @@
+package jobStatus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// MaxCorrectionReasonLen bounds why a status was corrected. It's a note, like a ticket number and
+// a sentence, not a report.
+const MaxCorrectionReasonLen = 1000
+
+// CorrectionIdType is the server-generated UUIDv7 for one correction, so a status's corrections
+// sort by when they were made.
+type CorrectionIdType string
+
+// StatusCorrection records an operator changing a stored status, like fixing a BusinessDate a job
+// got wrong, or deleting one that shouldn't have been posted. Before and After are the whole
+// status, so the audit trail shows exactly what changed; After is zero for a deletion.
+// CorrectedBy is the signed-in user (see common.UserOf).
+type StatusCorrection struct {
+	CorrectionId CorrectionIdType
+	StatusId     StatusIdType
+	Before       JobStatus
+	After        JobStatus
+	CorrectedBy  string
+	Reason       string
+	CorrectedTs  time.Time
+}
+
+// NewStatusCorrection validates its arguments and returns a correction of statusId with a new
+// CorrectionId, made now, or a CommonError with code ErrcdDomainProps. The repo fills in Before
+// and After.
+func NewStatusCorrection(statusId StatusIdType, correctedBy string, reason string) (StatusCorrection, error) {
+	if err := statusId.Validate(); err != nil {
+		return StatusCorrection{}, err
+	}
+	c, err := NewStatusDeletion(correctedBy, reason)
+	if err != nil {
+		return StatusCorrection{}, err
+	}
+	c.StatusId = statusId
+	return c, nil
+}
+
+// NewStatusDeletion is NewStatusCorrection for deleting a status found by its key, so the repo
+// fills in StatusId too.
+func NewStatusDeletion(correctedBy string, reason string) (StatusCorrection, error) {
+	reason = strings.TrimSpace(reason)
+	switch {
+	case correctedBy == "":
+		return StatusCorrection{}, propsError("CorrectedBy is required")
+	case reason == "":
+		return StatusCorrection{}, propsError("Reason is required")
+	case len(reason) > MaxCorrectionReasonLen:
+		return StatusCorrection{}, propsError(fmt.Sprintf("Reason is longer than %d characters", MaxCorrectionReasonLen))
+	}
+	return StatusCorrection{
+		CorrectionId: CorrectionIdType(common.NewUuidV7()),
+		CorrectedBy:  correctedBy,
+		Reason:       reason,
+		CorrectedTs:  time.Now().UTC().Truncate(time.Microsecond),
+	}, nil
+}
+
+// Deleted is true if the correction deleted the status.
+func (c StatusCorrection) Deleted() bool {
+	return c.After.StatusId == ""
+}
@@ -0,0 +1,104 @@
+package jobStatus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// StatusCorrectionsPath is the admin route for correcting stored statuses and reading their
+// audit trails.
+const StatusCorrectionsPath = "/admin/job-status-corrections"
+
+type CorrectJobStatusCtrl struct {
+	correct func(ctx context.Context, statusId string, jsDto dto.JobStatusDto, correctedBy string, reason string) (dto.StatusCorrectionDto, error)
+}
+
+// NewReplaceJobStatusCtrl handles PUT, which replaces every field a correction can change.
+func NewReplaceJobStatusCtrl(uc *StatusCorrectionUC) *CorrectJobStatusCtrl {
+	return &CorrectJobStatusCtrl{correct: uc.Replace}
+}
+
+// NewPatchJobStatusCtrl handles PATCH, which changes only the fields in the body.
+func NewPatchJobStatusCtrl(uc *StatusCorrectionUC) *CorrectJobStatusCtrl {
+	return &CorrectJobStatusCtrl{correct: uc.Patch}
+}
+
+// ServeHTTP takes a JobStatusDto with query parameters statusId and reason. The correction is
+// recorded as made by the user common.ResolveUser found, so requests that didn't come through
+// the authenticating proxy are 401.
+func (ctrl *CorrectJobStatusCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := common.UserOf(r)
+	if !ok {
+		writeError(w, r, common.NewCommonError(common.ErrcdUnauthorized, errNotSignedIn))
+		return
+	}
+
+	var jsDto dto.JobStatusDto
+	if err := json.NewDecoder(r.Body).Decode(&jsDto); err != nil {
+		writeError(w, r, common.NewCommonError(common.ErrcdJsonDecode, err))
+		return
+	}
+
+	q := r.URL.Query()
+	result, err := ctrl.correct(r.Context(), q.Get("statusId"), jsDto, user, q.Get("reason"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
+
+type ListStatusCorrectionsCtrl struct {
+	uc *StatusCorrectionUC
+}
+
+func NewListStatusCorrectionsCtrl(uc *StatusCorrectionUC) *ListStatusCorrectionsCtrl {
+	return &ListStatusCorrectionsCtrl{uc: uc}
+}
+
+// ServeHTTP handles GET with query parameter statusId.
+func (ctrl *ListStatusCorrectionsCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result, err := ctrl.uc.List(r.Context(), r.URL.Query().Get("statusId"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
+
+// StatusDeletePath is the admin route for deleting a status by its natural key.
+const StatusDeletePath = "/admin/job-statuses"
+
+type DeleteJobStatusCtrl struct {
+	uc *StatusCorrectionUC
+}
+
+func NewDeleteJobStatusCtrl(uc *StatusCorrectionUC) *DeleteJobStatusCtrl {
+	return &DeleteJobStatusCtrl{uc: uc}
+}
+
+// ServeHTTP handles DELETE with query parameters jobId, jobSt, busDt, runId, and reason, and
+// returns the correction recording the deletion. Like corrections, the deletion is recorded as
+// made by the signed-in user.
+func (ctrl *DeleteJobStatusCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := common.UserOf(r)
+	if !ok {
+		writeError(w, r, common.NewCommonError(common.ErrcdUnauthorized, errNotSignedIn))
+		return
+	}
+
+	q := r.URL.Query()
+	result, err := ctrl.uc.Delete(r.Context(), q.Get("jobId"), q.Get("jobSt"), q.Get("busDt"), q.Get("runId"), user, q.Get("reason"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	common.WriteJson(w, http.StatusOK, result)
+}
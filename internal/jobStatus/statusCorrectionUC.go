@@ -0,0 +1,177 @@
+package jobStatus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+type StatusCorrectionUC struct {
+	repo    CorrectionRepo
+	hasher  IntegrityHasher
+	aliases *JobAliasUC
+}
+
+// NewStatusCorrectionUC returns the use case. hasher must be the one used at ingestion, so
+// corrected statuses verify; if it's nil, corrected statuses are stored without a hash. If
+// aliases isn't nil, Delete resolves the JobId it's given, as adds do.
+func NewStatusCorrectionUC(repo CorrectionRepo, hasher IntegrityHasher, aliases *JobAliasUC) *StatusCorrectionUC {
+	return &StatusCorrectionUC{repo: repo, hasher: hasher, aliases: aliases}
+}
+
+// Replace corrects the status with statusId to jsDto, which is validated like a new status
+// except that RunId is required. StatusId, ReportedJobId, and ReceivedTimestamp are kept, and
+// JobId can't change; renaming a job is a job rename.
+func (uc *StatusCorrectionUC) Replace(ctx context.Context, statusId string, jsDto dto.JobStatusDto, correctedBy string, reason string) (dto.StatusCorrectionDto, error) {
+	return uc.correct(ctx, statusId, correctedBy, reason, func(dto.JobStatusDto) dto.JobStatusDto {
+		return jsDto
+	})
+}
+
+// Patch corrects only the fields set in patch and keeps the rest, then validates the result like
+// Replace. Links and Metadata, if set, replace all of the status's links or metadata; removing
+// every link or all metadata takes Replace.
+func (uc *StatusCorrectionUC) Patch(ctx context.Context, statusId string, patch dto.JobStatusDto, correctedBy string, reason string) (dto.StatusCorrectionDto, error) {
+	return uc.correct(ctx, statusId, correctedBy, reason, func(jsDto dto.JobStatusDto) dto.JobStatusDto {
+		set := func(to *string, from string) {
+			if from != "" {
+				*to = from
+			}
+		}
+		set(&jsDto.ApplicationId, patch.ApplicationId)
+		set(&jsDto.JobId, patch.JobId)
+		set(&jsDto.JobStatusCode, patch.JobStatusCode)
+		set(&jsDto.JobStatusTimestamp, patch.JobStatusTimestamp)
+		set(&jsDto.BusinessDate, patch.BusinessDate)
+		set(&jsDto.RunId, patch.RunId)
+		set(&jsDto.HostId, patch.HostId)
+		if patch.Links != nil {
+			jsDto.Links = patch.Links
+		}
+		if patch.Metadata != nil {
+			jsDto.Metadata = patch.Metadata
+		}
+		return jsDto
+	})
+}
+
+// Delete deletes the status with the natural key (jobId, jobSt, busDt, runId) and returns the
+// correction recording it. It's for statuses that shouldn't have been posted at all; a status
+// with a wrong field should be corrected instead, so its history stays.
+func (uc *StatusCorrectionUC) Delete(ctx context.Context, jobId string, jobSt string, busDt string, runId string, deletedBy string, reason string) (dto.StatusCorrectionDto, error) {
+	var problems common.FieldErrors
+	add := func(field string, problem string) {
+		if problem != "" {
+			problems = append(problems, common.FieldError{Field: field, Message: problem})
+		}
+	}
+	add("jobId", idProblem(jobId, MaxJobIdLen))
+	if !JobStatusCodeType(jobSt).IsValid() {
+		add("jobSt", fmt.Sprintf("%q is not START, SUCCEED, or FAIL", jobSt))
+	}
+	businessDate, err := ParseDate(busDt)
+	if err != nil {
+		add("busDt", fmt.Sprintf("%q is not a date, like 2023-06-15", busDt))
+	}
+	add("runId", idProblem(runId, MaxRunIdLen))
+	if len(problems) > 0 {
+		return dto.StatusCorrectionDto{}, common.NewCommonError(common.ErrcdDomainProps, problems)
+	}
+
+	c, err := NewStatusDeletion(deletedBy, reason)
+	if err != nil {
+		return dto.StatusCorrectionDto{}, err
+	}
+	key := StatusKey{JobId: JobIdType(jobId), JobStatusCode: JobStatusCodeType(jobSt), BusinessDate: TruncateToDate(businessDate), RunId: RunIdType(runId)}
+	if uc.aliases != nil {
+		if key.JobId, err = uc.aliases.Resolve(ctx, key.JobId); err != nil {
+			return dto.StatusCorrectionDto{}, err
+		}
+	}
+
+	if c, err = uc.repo.DeleteByKey(ctx, c, key); err != nil {
+		return dto.StatusCorrectionDto{}, err
+	}
+	return statusCorrectionToDto(c), nil
+}
+
+// List returns the status's corrections and its deletion, if it was deleted, oldest first.
+func (uc *StatusCorrectionUC) List(ctx context.Context, statusId string) ([]dto.StatusCorrectionDto, error) {
+	if err := StatusIdType(statusId).Validate(); err != nil {
+		return nil, err
+	}
+	cs, err := uc.repo.ListStatusCorrections(ctx, StatusIdType(statusId))
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]dto.StatusCorrectionDto, len(cs))
+	for i, c := range cs {
+		dtos[i] = statusCorrectionToDto(c)
+	}
+	return dtos, nil
+}
+
+// correct runs inside the repo's transaction, so merge sees the status as it's stored while the
+// correction is made, not as it was when the operator read it.
+func (uc *StatusCorrectionUC) correct(ctx context.Context, statusId string, correctedBy string, reason string, merge func(before dto.JobStatusDto) dto.JobStatusDto) (dto.StatusCorrectionDto, error) {
+	c, err := NewStatusCorrection(StatusIdType(statusId), correctedBy, reason)
+	if err != nil {
+		return dto.StatusCorrectionDto{}, err
+	}
+
+	c, err = uc.repo.Update(ctx, c, func(before JobStatus) (JobStatus, error) {
+		jsDto := merge(domainToDto(before))
+		problems := validateJobStatusDto(jsDto, false, time.Now())
+		if jsDto.JobId != string(before.JobId) {
+			problems = append(problems, common.FieldError{Field: "JobId", Message: "can't be corrected; rename the job instead"})
+		}
+		if len(problems) > 0 {
+			return JobStatus{}, common.NewCommonError(common.ErrcdDomainProps, problems)
+		}
+		// validated above, so these can't fail
+		jobStatusTimestamp, _ := time.Parse(time.RFC3339Nano, jsDto.JobStatusTimestamp)
+		businessDate, _ := ParseDate(jsDto.BusinessDate)
+
+		after := before
+		after.ApplicationId = jsDto.ApplicationId
+		after.JobStatusCode = JobStatusCodeType(jsDto.JobStatusCode)
+		after.JobStatusTimestamp = jobStatusTimestamp
+		after.BusinessDate = TruncateToDate(businessDate)
+		after.RunId = RunIdType(jsDto.RunId)
+		after.HostId = HostIdType(jsDto.HostId)
+		after.Links = linksDtoToDomain(jsDto.Links)
+		after.Metadata = Metadata(jsDto.Metadata).Clone()
+		if after.SameReport(before) {
+			return JobStatus{}, propsError("the correction doesn't change the status")
+		}
+
+		after.IntegrityHash = nil
+		if uc.hasher != nil {
+			after.IntegrityHash = uc.hasher.HashStatus(after)
+		}
+		return after, nil
+	})
+	if err != nil {
+		return dto.StatusCorrectionDto{}, err
+	}
+	return statusCorrectionToDto(c), nil
+}
+
+func statusCorrectionToDto(c StatusCorrection) dto.StatusCorrectionDto {
+	cDto := dto.StatusCorrectionDto{
+		CorrectionId:       string(c.CorrectionId),
+		StatusId:           string(c.StatusId),
+		CorrectedBy:        c.CorrectedBy,
+		Reason:             c.Reason,
+		CorrectedTimestamp: c.CorrectedTs.Format(dto.TimestampFormat),
+		Before:             domainToDto(c.Before),
+	}
+	if !c.Deleted() {
+		after := domainToDto(c.After)
+		cDto.After = &after
+	}
+	return cDto
+}
@@ -0,0 +1,77 @@
+package jobStatus
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// StreamJobStatusesUC is GetJobStatusesUC for results too large to build in memory.
+// It hands each DTO to the caller as the repo scans it.
+type StreamJobStatusesUC struct {
+	repo    StreamRepo
+	filters FilterRepo
+}
+
+// NewStreamJobStatusesUC returns the streaming use case. If filters is nil, streams by filters
+// alone are refused.
+func NewStreamJobStatusesUC(repo StreamRepo, filters FilterRepo) *StreamJobStatusesUC {
+	return &StreamJobStatusesUC{repo: repo, filters: filters}
+}
+
+// StreamByJobId calls fn for each status for a job. With params.AllowPartial, rows that can't
+// be read are skipped and reported in the returned error after the rest are streamed.
+func (uc *StreamJobStatusesUC) StreamByJobId(ctx context.Context, jobId string, params QueryParams, fn func(dto.JobStatusDto) error) error {
+	id, err := NewJobId(jobId)
+	if err != nil {
+		return err
+	}
+	opts, err := parseQueryOptions(params)
+	if err != nil {
+		return err
+	}
+
+	selected := opts.SelectedFields()
+	return uc.repo.ForEachByJobId(ctx, id, opts, func(js JobStatus) error {
+		return fn(domainToDtoFields(js, selected))
+	})
+}
+
+// StreamByJobIdBusinessDate calls fn for each status for a job on one business date.
+func (uc *StreamJobStatusesUC) StreamByJobIdBusinessDate(ctx context.Context, jobId string, businessDate string, params QueryParams, fn func(dto.JobStatusDto) error) error {
+	id, err := NewJobId(jobId)
+	if err != nil {
+		return err
+	}
+	busDt, err := parseDateProp("BusinessDate", businessDate)
+	if err != nil {
+		return err
+	}
+	opts, err := parseQueryOptions(params)
+	if err != nil {
+		return err
+	}
+
+	selected := opts.SelectedFields()
+	return uc.repo.ForEachByJobIdBusinessDate(ctx, id, busDt, opts, func(js JobStatus) error {
+		return fn(domainToDtoFields(js, selected))
+	})
+}
+
+// StreamByFilters calls fn for each status matching params.Filters; see GetJobStatusesUC.GetByFilters.
+func (uc *StreamJobStatusesUC) StreamByFilters(ctx context.Context, params QueryParams, fn func(dto.JobStatusDto) error) error {
+	if uc.filters == nil {
+		return common.NewCommonError(common.ErrcdDomainProps, errors.New("queries by filters are not available"))
+	}
+	opts, err := parseFilterQueryOptions(params)
+	if err != nil {
+		return err
+	}
+
+	selected := opts.SelectedFields()
+	return uc.filters.ForEachByFilters(ctx, opts, func(js JobStatus) error {
+		return fn(domainToDtoFields(js, selected))
+	})
+}
@@ -0,0 +1,242 @@
+// Package migrate runs long online migrations and backfills in small batches. Progress is
+// checkpointed after every batch, so a paused, failed, or interrupted migration resumes where
+// it stopped instead of starting over.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/tasks"
+	taskDto "github.com/jmjf/go-jst/public/tasks/dto"
+)
+
+// Migration does one migration a batch at a time. Batch processes up to limit items after
+// cursor ("" the first time) and returns the cursor to resume from, how many items it
+// processed, and whether the migration is finished. Batches must be safe to repeat, because
+// a crash after a batch and before its checkpoint repeats it.
+type Migration interface {
+	Name() string
+	Batch(ctx context.Context, cursor string, limit int) (next string, n int, done bool, err error)
+}
+
+// Checkpoint is a migration's saved progress.
+type Checkpoint struct {
+	Name      string
+	Cursor    string
+	ItemsDone int64
+	Done      bool
+	UpdatedTs time.Time
+}
+
+// CheckpointRepo stores checkpoints.
+type CheckpointRepo interface {
+	// GetCheckpoint returns ok false if the migration hasn't checkpointed yet.
+	GetCheckpoint(name string) (cp Checkpoint, ok bool, err error)
+	SaveCheckpoint(cp Checkpoint) error
+}
+
+// RunOptions tune one run. Zero values use defaults.
+type RunOptions struct {
+	BatchSize int // default 100
+	// ItemsPerSecond limits the rate by sleeping between batches; 0 means no limit.
+	ItemsPerSecond float64
+}
+
+// TaskKind is the tasks.Manager kind for migration runs.
+const TaskKind = "migration"
+
+// Runner starts, pauses, and reports on registered migrations. Runs are tasks on a tasks.Manager.
+type Runner struct {
+	repo  CheckpointRepo
+	tasks *tasks.Manager
+
+	mu         sync.Mutex
+	migrations map[string]Migration
+	running    map[string]run
+}
+
+type run struct {
+	taskId string
+	cancel context.CancelFunc
+}
+
+func NewRunner(repo CheckpointRepo, tm *tasks.Manager) *Runner {
+	return &Runner{repo: repo, tasks: tm, migrations: map[string]Migration{}, running: map[string]run{}}
+}
+
+// Register makes m available to Start. Call it before serving requests.
+func (r *Runner) Register(m Migration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.migrations[m.Name()] = m
+}
+
+// Start queues a run of the named migration from its checkpoint and returns the task.
+func (r *Runner) Start(name string, opts RunOptions) (taskDto.TaskDto, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.ItemsPerSecond < 0 {
+		return taskDto.TaskDto{}, propsError("ItemsPerSecond can't be negative")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.migrations[name]
+	if !ok {
+		return taskDto.TaskDto{}, propsError(fmt.Sprintf("unknown migration %q", name))
+	}
+	if _, ok := r.running[name]; ok {
+		return taskDto.TaskDto{}, propsError(fmt.Sprintf("migration %q is already running", name))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	task, err := r.tasks.Submit(TaskKind, func(taskCtx context.Context, progress func(int, int)) (any, error) {
+		defer r.finished(name)
+		// stop on either a pause or server shutdown; finished cancels ctx, which ends this goroutine
+		go func() {
+			select {
+			case <-taskCtx.Done():
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		return r.run(ctx, m, opts, progress)
+	})
+	if err != nil {
+		cancel()
+		return taskDto.TaskDto{}, err
+	}
+	r.running[name] = run{taskId: task.TaskId, cancel: cancel}
+	return task, nil
+}
+
+// Pause stops a running migration after its current batch. Start resumes it.
+func (r *Runner) Pause(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run, ok := r.running[name]
+	if !ok {
+		return propsError(fmt.Sprintf("migration %q isn't running", name))
+	}
+	run.cancel()
+	return nil
+}
+
+// Status describes a registered migration.
+type Status struct {
+	Name      string `json:"Name"`
+	Running   bool   `json:"Running"`
+	TaskId    string `json:"TaskId,omitempty"`
+	Cursor    string `json:"Cursor"`
+	ItemsDone int64  `json:"ItemsDone"`
+	Done      bool   `json:"Done"`
+	UpdatedTs string `json:"UpdatedTs,omitempty"`
+}
+
+// Statuses returns every registered migration with its checkpoint, sorted by name.
+func (r *Runner) Statuses() ([]Status, error) {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.migrations))
+	for name := range r.migrations {
+		names = append(names, name)
+	}
+	running := make(map[string]run, len(r.running))
+	for name, run := range r.running {
+		running[name] = run
+	}
+	r.mu.Unlock()
+	sort.Strings(names)
+
+	result := make([]Status, 0, len(names))
+	for _, name := range names {
+		st := Status{Name: name}
+		if run, ok := running[name]; ok {
+			st.Running = true
+			st.TaskId = run.taskId
+		}
+		cp, ok, err := r.repo.GetCheckpoint(name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			st.Cursor = cp.Cursor
+			st.ItemsDone = cp.ItemsDone
+			st.Done = cp.Done
+			st.UpdatedTs = cp.UpdatedTs.UTC().Format(time.RFC3339Nano)
+		}
+		result = append(result, st)
+	}
+	return result, nil
+}
+
+func (r *Runner) finished(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if run, ok := r.running[name]; ok {
+		run.cancel()
+		delete(r.running, name)
+	}
+}
+
+// run does batches until the migration is done or ctx is canceled. A pause isn't an error;
+// the task completes with the checkpoint so far.
+func (r *Runner) run(ctx context.Context, m Migration, opts RunOptions, progress func(int, int)) (any, error) {
+	cp, ok, err := r.repo.GetCheckpoint(m.Name())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		cp = Checkpoint{Name: m.Name()}
+	}
+
+	var doneThisRun int
+	for !cp.Done {
+		if ctx.Err() != nil {
+			break
+		}
+
+		started := time.Now()
+		next, n, done, err := m.Batch(ctx, cp.Cursor, opts.BatchSize)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				break
+			}
+			return nil, fmt.Errorf("batch after %q: %w", cp.Cursor, err)
+		}
+
+		cp.Cursor = next
+		cp.ItemsDone += int64(n)
+		cp.Done = done
+		cp.UpdatedTs = time.Now()
+		if err := r.repo.SaveCheckpoint(cp); err != nil {
+			return nil, fmt.Errorf("checkpoint at %q: %w", cp.Cursor, err)
+		}
+		doneThisRun += n
+		// the total is unknown, so progress reports items done this run
+		progress(doneThisRun, 0)
+
+		if opts.ItemsPerSecond > 0 && !cp.Done {
+			wait := time.Duration(float64(n)/opts.ItemsPerSecond*float64(time.Second)) - time.Since(started)
+			if wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+				case <-timer.C:
+				}
+			}
+		}
+	}
+	return Status{Name: cp.Name, Cursor: cp.Cursor, ItemsDone: cp.ItemsDone, Done: cp.Done, UpdatedTs: cp.UpdatedTs.UTC().Format(time.RFC3339Nano)}, nil
+}
+
+func propsError(msg string) error {
+	return common.NewCommonError(common.ErrcdDomainProps, errors.New(msg))
+}
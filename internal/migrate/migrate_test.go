@@ -0,0 +1,139 @@
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbmemory"
+	"github.com/jmjf/go-jst/internal/migrate"
+	"github.com/jmjf/go-jst/internal/tasks"
+	taskDto "github.com/jmjf/go-jst/public/tasks/dto"
+)
+
+// countMigration processes items 0 to total-1. Its cursor is the next item. It fails once when a
+// batch would start at failAt, and blocks a batch until release is closed if release isn't nil.
+type countMigration struct {
+	total   int
+	failAt  int
+	release chan struct{}
+
+	mu        sync.Mutex
+	failed    bool
+	processed []int
+}
+
+func (m *countMigration) Name() string { return "count" }
+
+func (m *countMigration) Batch(ctx context.Context, cursor string, limit int) (string, int, bool, error) {
+	if m.release != nil {
+		select {
+		case <-m.release:
+		case <-ctx.Done():
+			return cursor, 0, false, ctx.Err()
+		}
+	}
+	from := 0
+	if cursor != "" {
+		from, _ = strconv.Atoi(cursor)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if from == m.failAt && !m.failed {
+		m.failed = true
+		return cursor, 0, false, errors.New("lost the connection")
+	}
+	to := from + limit
+	if to > m.total {
+		to = m.total
+	}
+	for i := from; i < to; i++ {
+		m.processed = append(m.processed, i)
+	}
+	return strconv.Itoa(to), to - from, to == m.total, nil
+}
+
+func newRunner(t *testing.T, m migrate.Migration) (*migrate.Runner, *tasks.Manager) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	tm := tasks.NewManager(4, 10)
+	go tm.Run(ctx, 1)
+	runner := migrate.NewRunner(dbmemory.NewRepoMemory(), tm)
+	runner.Register(m)
+	return runner, tm
+}
+
+func waitForTask(t *testing.T, tm *tasks.Manager, taskId string) taskDto.TaskDto {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		task, _ := tm.Get(taskId)
+		if task.State == taskDto.StateCompleted || task.State == taskDto.StateFailed {
+			return task
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("task %s didn't finish", taskId)
+	return taskDto.TaskDto{}
+}
+
+func TestFailedRunResumesFromCheckpoint(t *testing.T) {
+	m := &countMigration{total: 10, failAt: 6}
+	runner, tm := newRunner(t, m)
+
+	task, err := runner.Start("count", migrate.RunOptions{BatchSize: 3})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if task = waitForTask(t, tm, task.TaskId); task.State != taskDto.StateFailed {
+		t.Fatalf("first run: got %s, want %s", task.State, taskDto.StateFailed)
+	}
+	statuses, err := runner.Statuses()
+	if err != nil || len(statuses) != 1 || statuses[0].Cursor != "6" || statuses[0].ItemsDone != 6 || statuses[0].Done {
+		t.Fatalf("after the failure: got %+v, %v; want checkpointed at 6", statuses, err)
+	}
+
+	task, err = runner.Start("count", migrate.RunOptions{BatchSize: 3})
+	if err != nil {
+		t.Fatalf("second Start: %v", err)
+	}
+	if task = waitForTask(t, tm, task.TaskId); task.State != taskDto.StateCompleted {
+		t.Fatalf("second run: got %s (%s), want %s", task.State, task.Error, taskDto.StateCompleted)
+	}
+	statuses, _ = runner.Statuses()
+	if statuses[0].ItemsDone != 10 || !statuses[0].Done {
+		t.Errorf("after the resume: got %+v, want 10 items done", statuses[0])
+	}
+	if len(m.processed) != 10 {
+		t.Errorf("processed %v, want each item once", m.processed)
+	}
+}
+
+func TestStartRefusesUnknownAndRunningMigrations(t *testing.T) {
+	m := &countMigration{total: 10, failAt: -1, release: make(chan struct{})}
+	runner, tm := newRunner(t, m)
+
+	if _, err := runner.Start("nope", migrate.RunOptions{}); common.ErrorCode(err) != common.ErrcdDomainProps {
+		t.Errorf("Start of an unknown migration: got %v, want %s", err, common.ErrcdDomainProps)
+	}
+	task, err := runner.Start("count", migrate.RunOptions{})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if _, err := runner.Start("count", migrate.RunOptions{}); common.ErrorCode(err) != common.ErrcdDomainProps {
+		t.Errorf("Start while running: got %v, want %s", err, common.ErrcdDomainProps)
+	}
+
+	if err := runner.Pause("count"); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	if task = waitForTask(t, tm, task.TaskId); task.State != taskDto.StateCompleted {
+		t.Errorf("paused run: got %s (%s), want %s", task.State, task.Error, taskDto.StateCompleted)
+	}
+}
@@ -0,0 +1,129 @@
+// Package region tracks whether this deployment is the active region or a passive standby, so
+// DR runbooks can promote a standby with an API call instead of editing config. The database's
+// own replication and promotion are outside go-jst; a region only says which side takes writes.
+package region
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/config"
+)
+
+type Role string
+
+const (
+	RoleActive  Role = "active"
+	RolePassive Role = "passive"
+)
+
+// ErrDatabaseNotPrimary is returned when promoting a region whose database is still a replica.
+var ErrDatabaseNotPrimary = errors.New("database is not accepting writes; promote it first or use force")
+
+// Database is what a region needs to know about its database.
+type Database interface {
+	Ping(ctx context.Context) error
+	// IsPrimary is true if the database accepts writes (it's not a read-only replica).
+	IsPrimary(ctx context.Context) (bool, error)
+}
+
+// State is a region's role as reported by the health and admin endpoints. ActiveUrl is where a
+// passive region sends writers, if it's known.
+type State struct {
+	Region    string    `json:"Region"`
+	Role      Role      `json:"Role"`
+	ActiveUrl string    `json:"ActiveUrl,omitempty"`
+	ChangedTs time.Time `json:"ChangedTs"`
+}
+
+// Region holds this deployment's role. Role changes last until the server restarts; set
+// GOJST_REGION_ROLE to keep them.
+type Region struct {
+	db Database
+
+	mu    sync.RWMutex
+	state State
+}
+
+func New(name string, role Role, activeUrl string, db Database) *Region {
+	return &Region{db: db, state: State{Region: name, Role: role, ActiveUrl: activeUrl, ChangedTs: time.Now().UTC()}}
+}
+
+// Config is a region's settings, before the region is created.
+type Config struct {
+	Name      string `env:"GOJST_REGION"`
+	Role      Role   `env:"GOJST_REGION_ROLE" validate:"oneof=active|passive"`
+	ActiveUrl string `env:"GOJST_ACTIVE_URL" validate:"url"`
+}
+
+// ConfigFromEnv reads the region's settings from environment variables and returns every
+// problem with them:
+//
+//	GOJST_REGION        this region's name, like "us-east"; it's only reported
+//	GOJST_REGION_ROLE   active (the default) or passive
+//	GOJST_ACTIVE_URL    the active region's base URL, where a passive region redirects writes
+func ConfigFromEnv(getenv func(string) string) (Config, error) {
+	cfg := Config{
+		Name:      getenv("GOJST_REGION"),
+		Role:      Role(strings.ToLower(strings.TrimSpace(getenv("GOJST_REGION_ROLE")))),
+		ActiveUrl: strings.TrimRight(getenv("GOJST_ACTIVE_URL"), "/"),
+	}
+	if cfg.Role == "" {
+		cfg.Role = RoleActive
+	}
+	if err := config.Validate(cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// NewFromConfig creates a region from ConfigFromEnv's settings.
+func NewFromConfig(cfg Config, db Database) *Region {
+	return New(cfg.Name, cfg.Role, cfg.ActiveUrl, db)
+}
+
+func ParseRole(s string) (Role, error) {
+	switch role := Role(strings.ToLower(strings.TrimSpace(s))); role {
+	case RoleActive, RolePassive:
+		return role, nil
+	}
+	return "", fmt.Errorf("role %q must be %s or %s", s, RoleActive, RolePassive)
+}
+
+func (rg *Region) State() State {
+	rg.mu.RLock()
+	defer rg.mu.RUnlock()
+	return rg.state
+}
+
+// IsActive is true if this region takes writes.
+func (rg *Region) IsActive() bool {
+	return rg.State().Role == RoleActive
+}
+
+// SetRole changes the region's role. Promoting to active checks that the database accepts
+// writes first, unless force is true. Demoting is always allowed, so a region can step down
+// while its database is unreachable.
+func (rg *Region) SetRole(ctx context.Context, role Role, force bool) (State, error) {
+	if role == RoleActive && !force {
+		primary, err := rg.db.IsPrimary(ctx)
+		if err != nil {
+			return rg.State(), err
+		}
+		if !primary {
+			return rg.State(), ErrDatabaseNotPrimary
+		}
+	}
+
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+	if rg.state.Role != role {
+		rg.state.Role = role
+		rg.state.ChangedTs = time.Now().UTC()
+	}
+	return rg.state, nil
+}
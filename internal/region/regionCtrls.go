@@ -0,0 +1,196 @@
+package region
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+const (
+	HealthPath = "/healthz"
+	ReadyPath  = "/readyz"
+	// Kubernetes probes. LivePath is HealthPath, and InstanceReadyPath is ReadyPath with role=any,
+	// so a passive region's pods stay in their Service for reads and admin calls.
+	StartupPath       = "/startup"
+	LivePath          = "/live"
+	InstanceReadyPath = "/ready"
+	// AdminPath is the admin route for the region's role.
+	AdminPath = "/admin/region"
+)
+
+// readyTimeout bounds the database ping in readiness checks, so a hung database fails the
+// check instead of hanging the load balancer's probe.
+const readyTimeout = 2 * time.Second
+
+// RoleHeader is set on every response from a passive region.
+const RoleHeader = "X-Gojst-Region-Role"
+
+// Readiness is the body of a readiness check.
+type Readiness struct {
+	State
+	Ready  bool   `json:"Ready"`
+	Reason string `json:"Reason,omitempty"`
+}
+
+type HealthCtrl struct {
+	region *Region
+}
+
+func NewHealthCtrl(rg *Region) *HealthCtrl {
+	return &HealthCtrl{region: rg}
+}
+
+// ServeHTTP handles GET. It's 200 with the region's state whenever the process is serving.
+func (ctrl *HealthCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	common.WriteJson(w, http.StatusOK, ctrl.region.State())
+}
+
+// ReadyGate is another condition for readiness, like a startup self-test. reason says why
+// it isn't ready.
+type ReadyGate interface {
+	Ready() (ready bool, reason string)
+}
+
+type ReadyCtrl struct {
+	region  *Region
+	gates   []ReadyGate
+	anyRole bool
+}
+
+func NewReadyCtrl(rg *Region, gates ...ReadyGate) *ReadyCtrl {
+	return &ReadyCtrl{region: rg, gates: gates}
+}
+
+// NewInstanceReadyCtrl returns a ReadyCtrl that's ready in either role, as if every request had
+// role=any.
+func NewInstanceReadyCtrl(rg *Region, gates ...ReadyGate) *ReadyCtrl {
+	return &ReadyCtrl{region: rg, gates: gates, anyRole: true}
+}
+
+// ServeHTTP handles GET. It's 200 if the database answers, every gate is ready, and the region is
+// active, and 503 otherwise, so a global load balancer sends traffic only to the active region.
+// With role=any, a passive region is ready too; use that for instance readiness inside the region.
+func (ctrl *ReadyCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result := Readiness{State: ctrl.region.State(), Ready: true}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+	if err := ctrl.region.db.Ping(ctx); err != nil {
+		result.Ready, result.Reason = false, "database: "+err.Error()
+	}
+	for _, gate := range ctrl.gates {
+		if !result.Ready {
+			break
+		}
+		result.Ready, result.Reason = gate.Ready()
+	}
+	if result.Ready && result.Role != RoleActive && !ctrl.anyRole && r.URL.Query().Get("role") != "any" {
+		result.Ready, result.Reason = false, "region is "+string(result.Role)
+	}
+
+	status := http.StatusOK
+	if !result.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	common.WriteJson(w, status, result)
+}
+
+// Startup is the body of a startup check.
+type Startup struct {
+	Started bool   `json:"Started"`
+	Reason  string `json:"Reason,omitempty"`
+}
+
+type StartupCtrl struct {
+	gates   []ReadyGate
+	started atomic.Bool
+}
+
+func NewStartupCtrl(gates ...ReadyGate) *StartupCtrl {
+	return &StartupCtrl{gates: gates}
+}
+
+// ServeHTTP handles GET. It's 503 until every gate has been ready at once, like a startup
+// self-test, and 200 from then on. Schema migrations run before the server listens, so they're
+// done by the time this answers. It doesn't check the database or the region, which can change
+// after startup; readiness covers them.
+func (ctrl *StartupCtrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !ctrl.started.Load() {
+		for _, gate := range ctrl.gates {
+			if ready, reason := gate.Ready(); !ready {
+				common.WriteJson(w, http.StatusServiceUnavailable, Startup{Reason: reason})
+				return
+			}
+		}
+		ctrl.started.Store(true)
+	}
+	common.WriteJson(w, http.StatusOK, Startup{Started: true})
+}
+
+// RejectWritesWhenPassive passes every request to next while the region is active. While it's
+// passive, reads (GET, HEAD, OPTIONS) and exempt paths still pass, and other requests are
+// redirected to the active region with 307 (so the method and body are kept) if GOJST_ACTIVE_URL
+// is set, or refused with 503 if it isn't.
+func (rg *Region) RejectWritesWhenPassive(next http.Handler, exemptPaths ...string) http.Handler {
+	exempt := map[string]bool{}
+	for _, p := range exemptPaths {
+		exempt[p] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := rg.State()
+		if state.Role == RoleActive {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set(RoleHeader, string(state.Role))
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if exempt[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if state.ActiveUrl != "" {
+			http.Redirect(w, r, state.ActiveUrl+r.URL.RequestURI(), http.StatusTemporaryRedirect)
+			return
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(30))
+		http.Error(w, "this region is passive and doesn't accept writes", http.StatusServiceUnavailable)
+	})
+}
+
+// RejectGrpcWritesWhenPassive is RejectWritesWhenPassive for unary gRPC calls. While the region
+// is passive, calls to writeMethods (full method names, like
+// "/gojst.jobstatus.v20230701.JobStatusService/AddJobStatus") fail with Unavailable. gRPC has
+// no redirect, so clients have to switch to the active region themselves; the role is in the
+// response header.
+func (rg *Region) RejectGrpcWritesWhenPassive(writeMethods ...string) grpc.UnaryServerInterceptor {
+	writes := map[string]bool{}
+	for _, m := range writeMethods {
+		writes[m] = true
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		state := rg.State()
+		if state.Role == RoleActive {
+			return handler(ctx, req)
+		}
+		grpc.SetHeader(ctx, metadata.Pairs(RoleHeader, string(state.Role)))
+		if !writes[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		return nil, status.Error(codes.Unavailable, "this region is passive and doesn't accept writes")
+	}
+}
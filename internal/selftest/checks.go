@@ -0,0 +1,58 @@
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Database is what the database and clock checks need from the repo.
+type Database interface {
+	// ProbeWrite writes a row to a scratch table, reads it back, and deletes it.
+	ProbeWrite(ctx context.Context) error
+	// Now is the database server's clock.
+	Now(ctx context.Context) (time.Time, error)
+}
+
+// DefaultMaxClockSkew is how far the server's clock can be from the database's. Statuses carry
+// the reporting job's timestamps, but ReceivedTimestamp, as-of queries, and quotas use this clock.
+const DefaultMaxClockSkew = 5 * time.Second
+
+// earliestSaneTime is before anything this server could have stored. A clock earlier than it
+// was never set (a container without RTC or NTP).
+var earliestSaneTime = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// DatabaseCheck probes writes to the database. If writable is false (a passive region reading
+// a replica), it's skipped.
+func DatabaseCheck(db Database, writable func() bool) Check {
+	return Check{Name: "database", Run: func(ctx context.Context) error {
+		if !writable() {
+			return Skip("region is passive")
+		}
+		return db.ProbeWrite(ctx)
+	}}
+}
+
+// ClockCheck fails if this server's clock isn't set or is more than maxSkew from the database's.
+func ClockCheck(db Database, maxSkew time.Duration) Check {
+	return Check{Name: "clock", Run: func(ctx context.Context) error {
+		before := time.Now()
+		if before.Before(earliestSaneTime) {
+			return fmt.Errorf("clock reads %s, which is before %s", before.UTC().Format(time.RFC3339), earliestSaneTime.Format(time.RFC3339))
+		}
+		dbNow, err := db.Now(ctx)
+		if err != nil {
+			return err
+		}
+		// compare to the middle of the round trip
+		rtt := time.Since(before)
+		skew := before.Add(rtt / 2).Sub(dbNow)
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > maxSkew+rtt/2 {
+			return fmt.Errorf("clock is %s from the database's (round trip %s, at most %s allowed)", skew.Round(time.Millisecond), rtt.Round(time.Millisecond), maxSkew)
+		}
+		return nil
+	}}
+}
@@ -0,0 +1,155 @@
+// Package selftest runs checks at startup that catch misconfiguration (a database user that
+// can't write, a bad delivery secret, a wrong clock) before traffic arrives. Results gate
+// readiness and are shown on the debug info route.
+package selftest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// checkTimeout bounds one check, so a hung dependency fails the suite instead of hanging it.
+const checkTimeout = 10 * time.Second
+
+// Check is one self-test. Run returns nil if it passed, or an error from Skip if it doesn't apply.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+type skipError struct {
+	reason string
+}
+
+func (e skipError) Error() string { return "skipped: " + e.reason }
+
+// Skip returns the error a check returns when it doesn't apply, like a write check in a
+// passive region. Skipped checks don't fail the suite.
+func Skip(reason string) error {
+	return skipError{reason: reason}
+}
+
+type Result struct {
+	Name       string `json:"Name"`
+	Passed     bool   `json:"Passed"`
+	Skipped    string `json:"Skipped,omitempty"`
+	Error      string `json:"Error,omitempty"`
+	DurationMs int64  `json:"DurationMs"`
+}
+
+// Report is the latest run of the suite. Passed is false until a run has finished.
+type Report struct {
+	Enabled   bool     `json:"Enabled"`
+	Runs      int      `json:"Runs"`
+	RunTs     string   `json:"RunTs,omitempty"`
+	Passed    bool     `json:"Passed"`
+	Results   []Result `json:"Results,omitempty"`
+	NextRunTs string   `json:"NextRunTs,omitempty"`
+}
+
+// Suite runs checks and remembers the latest report. A nil *Suite is a disabled suite: it's
+// always ready and reports Enabled false.
+type Suite struct {
+	checks []Check
+
+	mu     sync.Mutex
+	report Report
+}
+
+func NewSuite(checks ...Check) *Suite {
+	return &Suite{checks: checks, report: Report{Enabled: true}}
+}
+
+// Run runs every check in order and records the report.
+func (s *Suite) Run(ctx context.Context) Report {
+	results := make([]Result, len(s.checks))
+	passed := true
+	for i, c := range s.checks {
+		results[i] = runCheck(ctx, c)
+		passed = passed && results[i].Passed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report.Runs++
+	s.report.RunTs = time.Now().UTC().Format(time.RFC3339)
+	s.report.Passed = passed
+	s.report.Results = results
+	s.report.NextRunTs = ""
+	return s.report
+}
+
+func runCheck(ctx context.Context, c Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := common.CatchPanic("self-test "+c.Name, func() error { return c.Run(ctx) })
+	result := Result{Name: c.Name, Passed: true, DurationMs: time.Since(start).Milliseconds()}
+
+	var skip skipError
+	switch {
+	case errors.As(err, &skip):
+		result.Skipped = skip.reason
+	case err != nil:
+		result.Passed, result.Error = false, err.Error()
+	}
+	return result
+}
+
+// RunUntilPassed runs the suite, then again every retry until it passes or ctx is done, so a
+// dependency that's slow to start doesn't leave the instance unready until it restarts.
+func (s *Suite) RunUntilPassed(ctx context.Context, retry time.Duration) {
+	for {
+		if s.Run(ctx).Passed {
+			return
+		}
+
+		s.mu.Lock()
+		s.report.NextRunTs = time.Now().Add(retry).UTC().Format(time.RFC3339)
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+	}
+}
+
+// Report returns the latest report.
+func (s *Suite) Report() Report {
+	if s == nil {
+		return Report{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report := s.report
+	report.Results = append([]Result(nil), s.report.Results...)
+	return report
+}
+
+// Ready is false, with the reason, until a run passes.
+func (s *Suite) Ready() (bool, string) {
+	report := s.Report()
+	switch {
+	case !report.Enabled || report.Passed:
+		return true, ""
+	case report.Runs == 0:
+		return false, "self-test hasn't finished"
+	}
+
+	var failed []string
+	for _, r := range report.Results {
+		if !r.Passed {
+			failed = append(failed, r.Name)
+		}
+	}
+	return false, fmt.Sprintf("self-test failed: %s", strings.Join(failed, ", "))
+}
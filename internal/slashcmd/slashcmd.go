@@ -0,0 +1,160 @@
+// Package slashcmd answers chat slash commands (Slack semantics) about job statuses, so teams
+// can check on jobs without leaving chat. Mount it behind webhookauth.Require with a
+// SlackVerifier; this package doesn't check signatures itself.
+package slashcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// Path is where slash commands are posted.
+const Path = "/slash-commands"
+
+// maxLines keeps replies short enough to read in a chat window.
+const maxLines = 20
+
+const usage = "Usage:\n" +
+	"• `status <jobId> [YYYY-MM-DD]` lists a job's statuses for a business date (default today, UTC)\n" +
+	"• `board <view> [YYYY-MM-DD]` shows the state of each job in a saved view\n" +
+	"• `help` shows this"
+
+// ResponseDto is a slash command reply. ResponseType is "ephemeral" (only the caller sees it)
+// or "in_channel".
+type ResponseDto struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+type Ctrl struct {
+	getUC   *jobStatus.GetJobStatusesUC
+	boardUC *jobStatus.StatusBoardUC
+}
+
+// NewCtrl returns the slash command controller. If boardUC is nil, board commands are refused.
+func NewCtrl(getUC *jobStatus.GetJobStatusesUC, boardUC *jobStatus.StatusBoardUC) *Ctrl {
+	return &Ctrl{getUC: getUC, boardUC: boardUC}
+}
+
+// ServeHTTP handles POST of a form-encoded slash command. Slack shows non-200 responses as a
+// generic failure, so every answer, including errors, is a 200 with an ephemeral message.
+func (ctrl *Ctrl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		common.WriteError(w, r, common.NewCommonError(common.ErrcdDomainProps, fmt.Errorf("bad form: %w", err)))
+		return
+	}
+	text := r.PostForm.Get("text")
+	log.Printf("slash command %s %q from %s", r.PostForm.Get("command"), text, r.PostForm.Get("user_id"))
+
+	common.WriteJson(w, http.StatusOK, ResponseDto{ResponseType: "ephemeral", Text: ctrl.answer(r.Context(), text)})
+}
+
+func (ctrl *Ctrl) answer(ctx context.Context, text string) string {
+	args := strings.Fields(text)
+	if len(args) == 0 {
+		return usage
+	}
+	verb, args := strings.ToLower(args[0]), args[1:]
+
+	switch verb {
+	case "status", "board":
+		if len(args) < 1 || len(args) > 2 {
+			return usage
+		}
+		busDt := time.Now().UTC().Format(dto.DateFormat)
+		if len(args) == 2 {
+			busDt = args[1]
+		}
+		if verb == "status" {
+			return ctrl.status(ctx, args[0], busDt)
+		}
+		return ctrl.board(ctx, args[0], busDt)
+	case "slo":
+		return "SLO status isn't available yet."
+	case "help":
+		return usage
+	}
+	return fmt.Sprintf("I don't know `%s`.\n%s", verb, usage)
+}
+
+func (ctrl *Ctrl) status(ctx context.Context, jobId string, busDt string) string {
+	jss, err := ctrl.getUC.GetByJobIdBusinessDate(ctx, jobId, busDt, jobStatus.QueryParams{Sort: "JobStTs"})
+	if err != nil {
+		return errorText(err)
+	}
+	if len(jss) == 0 {
+		return fmt.Sprintf("No statuses for *%s* on %s.", jobId, busDt)
+	}
+
+	lines := []string{fmt.Sprintf("*%s* on %s:", jobId, busDt)}
+	for i, js := range jss {
+		if i == maxLines {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(jss)-maxLines))
+			break
+		}
+		line := fmt.Sprintf("• `%s` %s", js.JobStatusCode, js.JobStatusTimestamp)
+		if js.RunId != "" {
+			line += " run " + js.RunId
+		}
+		if js.HostId != "" {
+			line += " on " + js.HostId
+		}
+		for _, l := range js.Links {
+			line += fmt.Sprintf(" <%s|%s>", l.Url, l.Kind)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (ctrl *Ctrl) board(ctx context.Context, viewName string, busDt string) string {
+	if ctrl.boardUC == nil {
+		return "Status boards aren't available."
+	}
+	board, err := ctrl.boardUC.Get(ctx, viewName, busDt, "")
+	if err != nil {
+		return errorText(err)
+	}
+
+	c := board.Counts
+	lines := []string{fmt.Sprintf("*%s* on %s: %d failed, %d running, %d succeeded, %d not started",
+		board.View, board.BusinessDate, c.Failed, c.Running, c.Succeeded, c.NotStarted)}
+	for i, job := range board.Jobs {
+		if i == maxLines {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(board.Jobs)-maxLines))
+			break
+		}
+		line := fmt.Sprintf("• %s %s", boardEmoji[job.State], job.JobId)
+		if job.JobStatusTimestamp != "" {
+			line += " at " + job.JobStatusTimestamp
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+var boardEmoji = map[string]string{
+	dto.BoardStateNotStarted: ":white_circle:",
+	dto.BoardStateRunning:    ":large_blue_circle:",
+	dto.BoardStateSucceeded:  ":large_green_circle:",
+	dto.BoardStateFailed:     ":red_circle:",
+}
+
+// errorText shows the caller's mistakes and hides server failures, which are logged instead.
+func errorText(err error) string {
+	var ce *common.CommonError
+	if errors.As(err, &ce) && (ce.Code == common.ErrcdDomainProps || ce.Code == common.ErrcdNotFound) {
+		return "Sorry: " + ce.Err.Error()
+	}
+	log.Printf("slash command failed: %v", err)
+	return "Sorry, I couldn't answer that right now. Try again in a minute."
+}
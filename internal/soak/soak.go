@@ -0,0 +1,233 @@
+// Package soak generates sustained job status traffic against the API and then checks that
+// what's stored matches what was sent. It's for validating repo backends under load.
+package soak
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/public/jobStatus/client"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// ApplicationIdPrefix starts the ApplicationId of every soak status so soak data is easy to find and purge.
+const ApplicationIdPrefix = "soak-"
+
+// maxDiscrepancies limits how many discrepancies a Report lists (all are counted).
+const maxDiscrepancies = 100
+
+type Config struct {
+	Duration      time.Duration
+	Workers       int
+	RatePerSecond int // across all workers; 0 means as fast as possible
+	JobCount      int // statuses are spread over this many job ids
+	HostId        string
+}
+
+type Report struct {
+	SoakId         string
+	Sent           int
+	AddErrors      int
+	Stored         int
+	Missing        int
+	Unexpected     int
+	Mismatched     int
+	SentChecksum   string
+	StoredChecksum string
+	Discrepancies  []string
+	Elapsed        time.Duration
+}
+
+// OK reports whether everything sent was stored exactly once and unchanged.
+func (r Report) OK() bool {
+	return r.Missing == 0 && r.Unexpected == 0 && r.Mismatched == 0 && r.SentChecksum == r.StoredChecksum
+}
+
+func (r Report) String() string {
+	status := "OK"
+	if !r.OK() {
+		status = "DISCREPANCIES"
+	}
+	return fmt.Sprintf("soak %s %s: sent %d (add errors %d), stored %d, missing %d, unexpected %d, mismatched %d, checksums sent %s stored %s, elapsed %s",
+		r.SoakId, status, r.Sent, r.AddErrors, r.Stored, r.Missing, r.Unexpected, r.Mismatched,
+		short(r.SentChecksum), short(r.StoredChecksum), r.Elapsed.Round(time.Millisecond))
+}
+
+func short(checksum string) string {
+	if len(checksum) > 12 {
+		return checksum[:12]
+	}
+	return checksum
+}
+
+type sent struct {
+	mu       sync.Mutex
+	statuses map[string]dto.JobStatusDto // by key()
+	failed   map[string]dto.JobStatusDto // adds that returned errors
+}
+
+// Run sends traffic until cfg.Duration passes or ctx is done, then verifies it.
+func Run(ctx context.Context, c *client.Client, cfg Config) (Report, error) {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.JobCount < 1 {
+		cfg.JobCount = 10
+	}
+	if cfg.HostId == "" {
+		cfg.HostId = "soak"
+	}
+
+	start := time.Now()
+	soakId := start.UTC().Format("20060102T150405")
+	s := &sent{statuses: map[string]dto.JobStatusDto{}, failed: map[string]dto.JobStatusDto{}}
+
+	runCtx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	var tick <-chan time.Time
+	if cfg.RatePerSecond > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(cfg.RatePerSecond))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for seq := 0; ; seq++ {
+				if tick != nil {
+					select {
+					case <-runCtx.Done():
+						return
+					case <-tick:
+					}
+				} else if runCtx.Err() != nil {
+					return
+				}
+
+				jsDto := makeStatus(soakId, cfg, worker, seq)
+				_, err := c.AddJobStatus(jsDto)
+				s.mu.Lock()
+				if err != nil {
+					s.failed[key(jsDto)] = jsDto
+				} else {
+					s.statuses[key(jsDto)] = jsDto
+				}
+				s.mu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	report, err := verify(c, soakId, cfg, s)
+	report.Elapsed = time.Since(start)
+	return report, err
+}
+
+// makeStatus builds a unique status. Timestamps are truncated to microseconds because
+// that's what Postgres stores.
+func makeStatus(soakId string, cfg Config, worker int, seq int) dto.JobStatusDto {
+	codes := []string{"START", "SUCCEED", "FAIL"}
+	return dto.JobStatusDto{
+		ApplicationId:      ApplicationIdPrefix + soakId,
+		JobId:              fmt.Sprintf("%s%s-job-%d", ApplicationIdPrefix, soakId, (worker*7919+seq)%cfg.JobCount),
+		JobStatusCode:      codes[seq%len(codes)],
+		JobStatusTimestamp: time.Now().UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		BusinessDate:       time.Now().UTC().Format(dto.DateFormat),
+		RunId:              fmt.Sprintf("w%d-%d", worker, seq),
+		HostId:             cfg.HostId,
+	}
+}
+
+// key is the natural key the repo enforces.
+func key(d dto.JobStatusDto) string {
+	return strings.Join([]string{d.JobId, d.JobStatusCode, d.BusinessDate, d.RunId}, "|")
+}
+
+// canonical renders a DTO so that equivalent timestamps in different zones compare equal.
+func canonical(d dto.JobStatusDto) string {
+	if ts, err := time.Parse(time.RFC3339Nano, d.JobStatusTimestamp); err == nil {
+		d.JobStatusTimestamp = ts.UTC().Format(time.RFC3339Nano)
+	}
+	return strings.Join([]string{d.ApplicationId, d.JobId, d.JobStatusCode, d.JobStatusTimestamp, d.BusinessDate, d.RunId, d.HostId}, "|")
+}
+
+func checksum(ds map[string]dto.JobStatusDto) string {
+	lines := make([]string, 0, len(ds))
+	for _, d := range ds {
+		lines = append(lines, canonical(d))
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	for _, line := range lines {
+		h.Write([]byte(line))
+		h.Write([]byte("\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func verify(c *client.Client, soakId string, cfg Config, s *sent) (Report, error) {
+	report := Report{SoakId: soakId, Sent: len(s.statuses) + len(s.failed), AddErrors: len(s.failed)}
+	addDiscrepancy := func(format string, args ...any) {
+		if len(report.Discrepancies) < maxDiscrepancies {
+			report.Discrepancies = append(report.Discrepancies, fmt.Sprintf(format, args...))
+		}
+	}
+
+	stored := map[string]dto.JobStatusDto{}
+	for j := 0; j < cfg.JobCount; j++ {
+		jobId := fmt.Sprintf("%s%s-job-%d", ApplicationIdPrefix, soakId, j)
+		err := c.StreamByJobId(jobId, client.QueryOptions{}, func(d dto.JobStatusDto) error {
+			k := key(d)
+			if _, dup := stored[k]; dup {
+				report.Unexpected++
+				addDiscrepancy("duplicate stored row %s", k)
+			}
+			stored[k] = d
+			return nil
+		})
+		if err != nil {
+			return report, fmt.Errorf("reading %s: %w", jobId, err)
+		}
+	}
+	report.Stored = len(stored)
+
+	for k, want := range s.statuses {
+		got, ok := stored[k]
+		switch {
+		case !ok:
+			report.Missing++
+			addDiscrepancy("missing %s", k)
+		case canonical(got) != canonical(want):
+			report.Mismatched++
+			addDiscrepancy("mismatch %s: sent %s stored %s", k, canonical(want), canonical(got))
+		}
+	}
+	for k := range stored {
+		if _, ok := s.statuses[k]; ok {
+			continue
+		}
+		// a failed add can still commit (e.g., client timeout), so note it but don't count it as unexpected
+		if _, ok := s.failed[k]; ok {
+			addDiscrepancy("stored despite add error %s", k)
+			delete(stored, k)
+			continue
+		}
+		report.Unexpected++
+		addDiscrepancy("unexpected %s", k)
+	}
+
+	report.SentChecksum = checksum(s.statuses)
+	report.StoredChecksum = checksum(stored)
+	return report, nil
+}
@@ -0,0 +1,188 @@
+// Package supervisor runs the server's background loops (the nightly rollup, scheduled queries,
+// the metering flusher, task workers) and restarts them when they crash, so a loop doesn't die
+// silently and leave the server serving without it.
+package supervisor
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+)
+
+// Worker states.
+const (
+	StateRunning    = "running"
+	StateRestarting = "restarting"
+	StateFinished   = "finished"
+	StateFailed     = "failed"
+	StateStopped    = "stopped"
+)
+
+// Config controls restarts. Zero fields get the defaults.
+type Config struct {
+	// MaxRestarts is how many crashes in a row a worker gets before it's left failed (default 5).
+	MaxRestarts int
+	// BackoffMin is the wait before the first restart, doubled for each crash in a row up to
+	// BackoffMax (defaults 1s and 1m).
+	BackoffMin time.Duration
+	BackoffMax time.Duration
+	// StableAfter is how long a worker runs before its crash count goes back to 0 (default 5m).
+	StableAfter time.Duration
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.MaxRestarts <= 0 {
+		cfg.MaxRestarts = 5
+	}
+	if cfg.BackoffMin <= 0 {
+		cfg.BackoffMin = time.Second
+	}
+	if cfg.BackoffMax <= 0 {
+		cfg.BackoffMax = time.Minute
+	}
+	if cfg.BackoffMax < cfg.BackoffMin {
+		cfg.BackoffMax = cfg.BackoffMin
+	}
+	if cfg.StableAfter <= 0 {
+		cfg.StableAfter = 5 * time.Minute
+	}
+	return cfg
+}
+
+// WorkerStatus reports on one worker.
+type WorkerStatus struct {
+	Name      string `json:"Name"`
+	State     string `json:"State"`
+	Restarts  int    `json:"Restarts"`
+	LastError string `json:"LastError,omitempty"`
+	StartTs   string `json:"StartTs,omitempty"`
+}
+
+// RunFunc is a worker's loop. It should block until ctx is done and then return nil. Returning
+// nil earlier means its work is finished, like a self-test that passed. Returning an error or
+// panicking before ctx is done is a crash, and the worker is restarted.
+type RunFunc func(ctx context.Context) error
+
+type worker struct {
+	name   string
+	run    RunFunc
+	status WorkerStatus
+}
+
+// Supervisor is safe for concurrent use, but add every worker before calling Run.
+type Supervisor struct {
+	cfg     Config
+	mu      sync.Mutex
+	workers []*worker
+}
+
+func New(cfg Config) *Supervisor {
+	return &Supervisor{cfg: cfg.withDefaults()}
+}
+
+// Add registers a worker. Names should be unique; they're only used in logs and Status.
+func (s *Supervisor) Add(name string, run RunFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.workers = append(s.workers, &worker{name: name, run: run, status: WorkerStatus{Name: name}})
+}
+
+// Run starts every worker and blocks until all of them have stopped, finished, or failed. Cancel
+// ctx to stop them.
+func (s *Supervisor) Run(ctx context.Context) {
+	s.mu.Lock()
+	workers := append([]*worker(nil), s.workers...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			s.supervise(ctx, w)
+		}(w)
+	}
+	wg.Wait()
+}
+
+func (s *Supervisor) supervise(ctx context.Context, w *worker) {
+	crashes := 0
+	for {
+		start := time.Now()
+		s.setStatus(w, func(st *WorkerStatus) {
+			st.State = StateRunning
+			st.StartTs = start.UTC().Format(time.RFC3339)
+		})
+
+		// a panic in a goroutine the worker starts can't be caught here; it recovers those itself
+		err := common.CatchPanic("worker "+w.name, func() error { return w.run(ctx) })
+		switch {
+		case ctx.Err() != nil:
+			s.setStatus(w, func(st *WorkerStatus) { st.State = StateStopped })
+			return
+		case err == nil:
+			s.setStatus(w, func(st *WorkerStatus) { st.State = StateFinished })
+			return
+		}
+
+		if time.Since(start) >= s.cfg.StableAfter {
+			crashes = 0
+		}
+		crashes++
+		if crashes > s.cfg.MaxRestarts {
+			log.Printf("worker %s failed %d times in a row and won't be restarted: %v", w.name, crashes, err)
+			s.setStatus(w, func(st *WorkerStatus) { st.State, st.LastError = StateFailed, err.Error() })
+			return
+		}
+
+		backoff := s.backoff(crashes)
+		log.Printf("worker %s crashed, restarting in %s: %v", w.name, backoff, err)
+		s.setStatus(w, func(st *WorkerStatus) {
+			st.State, st.LastError = StateRestarting, err.Error()
+			st.Restarts++
+		})
+		select {
+		case <-ctx.Done():
+			s.setStatus(w, func(st *WorkerStatus) { st.State = StateStopped })
+			return
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// backoff is BackoffMin doubled for each crash in a row after the first, up to BackoffMax.
+func (s *Supervisor) backoff(crashes int) time.Duration {
+	d := s.cfg.BackoffMin
+	for i := 1; i < crashes && d < s.cfg.BackoffMax; i++ {
+		d *= 2
+	}
+	if d > s.cfg.BackoffMax {
+		d = s.cfg.BackoffMax
+	}
+	return d
+}
+
+func (s *Supervisor) setStatus(w *worker, fn func(*WorkerStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&w.status)
+}
+
+// Status returns every worker's status, ordered by name.
+func (s *Supervisor) Status() []WorkerStatus {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]WorkerStatus, len(s.workers))
+	for i, w := range s.workers {
+		result[i] = w.status
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
@@ -0,0 +1,203 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastConfig restarts almost at once so crash loops finish quickly.
+var fastConfig = Config{MaxRestarts: 3, BackoffMin: time.Millisecond, BackoffMax: time.Millisecond}
+
+// runSupervisor runs s in the background and returns a func that cancels it and waits for Run.
+func runSupervisor(t *testing.T, s *Supervisor) (stop func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+	return func() {
+		t.Helper()
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Run didn't return after ctx was canceled")
+		}
+	}
+}
+
+// waitForState waits for the only worker to reach state and returns its status.
+func waitForState(t *testing.T, s *Supervisor, state string) WorkerStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		st := s.Status()[0]
+		if st.State == state {
+			return st
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got state %s, want %s", st.State, state)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRestartsAfterCrash(t *testing.T) {
+	s := New(fastConfig)
+	var runs atomic.Int32
+	s.Add("rollup", func(ctx context.Context) error {
+		if runs.Add(1) <= 2 {
+			return errors.New("database went away")
+		}
+		<-ctx.Done()
+		return nil
+	})
+	stop := runSupervisor(t, s)
+
+	st := waitForState(t, s, StateRunning)
+	for st.Restarts < 2 {
+		st = waitForState(t, s, StateRunning)
+	}
+	stop()
+
+	st = s.Status()[0]
+	if st.State != StateStopped || st.Restarts != 2 || st.LastError != "database went away" {
+		t.Errorf("got %+v, want stopped after 2 restarts with the last error", st)
+	}
+	if runs.Load() != 3 {
+		t.Errorf("got %d runs, want 3", runs.Load())
+	}
+}
+
+func TestRestartsAfterPanic(t *testing.T) {
+	s := New(fastConfig)
+	var runs atomic.Int32
+	s.Add("metering", func(ctx context.Context) error {
+		if runs.Add(1) == 1 {
+			panic("nil map")
+		}
+		return nil
+	})
+	s.Run(context.Background())
+
+	st := s.Status()[0]
+	if st.State != StateFinished || st.Restarts != 1 || !strings.Contains(st.LastError, "nil map") {
+		t.Errorf("got %+v, want finished after 1 restart with the panic as the last error", st)
+	}
+}
+
+func TestFailsAfterMaxRestarts(t *testing.T) {
+	s := New(fastConfig)
+	var runs atomic.Int32
+	s.Add("tasks", func(ctx context.Context) error {
+		runs.Add(1)
+		return errors.New("bad config")
+	})
+	s.Run(context.Background())
+
+	st := s.Status()[0]
+	if st.State != StateFailed || st.Restarts != fastConfig.MaxRestarts || st.LastError != "bad config" {
+		t.Errorf("got %+v, want failed after %d restarts", st, fastConfig.MaxRestarts)
+	}
+	if int(runs.Load()) != fastConfig.MaxRestarts+1 {
+		t.Errorf("got %d runs, want %d", runs.Load(), fastConfig.MaxRestarts+1)
+	}
+}
+
+func TestStableRunResetsCrashes(t *testing.T) {
+	cfg := fastConfig
+	cfg.MaxRestarts = 1
+	cfg.StableAfter = 5 * time.Millisecond
+	s := New(cfg)
+	var runs atomic.Int32
+	s.Add("scheduler", func(ctx context.Context) error {
+		// each run is long enough to count as stable, so one restart is never used up
+		if runs.Add(1) <= 3 {
+			time.Sleep(cfg.StableAfter)
+			return errors.New("lost lock")
+		}
+		return nil
+	})
+	s.Run(context.Background())
+
+	if st := s.Status()[0]; st.State != StateFinished || st.Restarts != 3 {
+		t.Errorf("got %+v, want finished after 3 restarts", st)
+	}
+}
+
+func TestStopsDuringBackoff(t *testing.T) {
+	s := New(Config{BackoffMin: time.Hour})
+	s.Add("rollup", func(ctx context.Context) error { return errors.New("crash") })
+	stop := runSupervisor(t, s)
+
+	waitForState(t, s, StateRestarting)
+	stop()
+	if st := s.Status()[0]; st.State != StateStopped {
+		t.Errorf("got state %s, want %s", st.State, StateStopped)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	s := New(Config{BackoffMin: time.Second, BackoffMax: 10 * time.Second})
+	for _, tc := range []struct {
+		crashes int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{100, 10 * time.Second},
+	} {
+		if got := s.backoff(tc.crashes); got != tc.want {
+			t.Errorf("%d crashes: got %s, want %s", tc.crashes, got, tc.want)
+		}
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  Config
+		want Config
+	}{
+		{"zero", Config{}, Config{MaxRestarts: 5, BackoffMin: time.Second, BackoffMax: time.Minute, StableAfter: 5 * time.Minute}},
+		{"max below min", Config{BackoffMin: 2 * time.Minute}, Config{MaxRestarts: 5, BackoffMin: 2 * time.Minute, BackoffMax: 2 * time.Minute, StableAfter: 5 * time.Minute}},
+		{"set", Config{MaxRestarts: 1, BackoffMin: 1, BackoffMax: 2, StableAfter: 3}, Config{MaxRestarts: 1, BackoffMin: 1, BackoffMax: 2, StableAfter: 3}},
+	} {
+		if got := tc.cfg.withDefaults(); got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestStatusOrderedByName(t *testing.T) {
+	var nilSupervisor *Supervisor
+	if st := nilSupervisor.Status(); st != nil {
+		t.Errorf("nil Supervisor: got %v, want nil", st)
+	}
+
+	s := New(Config{})
+	s.Add("tasks", func(ctx context.Context) error { return nil })
+	s.Add("metering", func(ctx context.Context) error { return nil })
+	s.Add("rollup", func(ctx context.Context) error { return nil })
+	s.Run(context.Background())
+
+	var names []string
+	for _, st := range s.Status() {
+		if st.State != StateFinished {
+			t.Errorf("%s: got state %s, want %s", st.Name, st.State, StateFinished)
+		}
+		names = append(names, st.Name)
+	}
+	if strings.Join(names, ",") != "metering,rollup,tasks" {
+		t.Errorf("got %v, want metering, rollup, tasks", names)
+	}
+}
@@ -0,0 +1,76 @@
+// Package systemd tells systemd how the server is doing, for units with Type=notify: when it's
+// ready, when it's stopping, and that it's still alive if the unit has WatchdogSec. It speaks
+// the sd_notify protocol directly, so there's no libsystemd dependency. Outside systemd
+// (NOTIFY_SOCKET unset, including on Windows) every call does nothing.
+package systemd
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Notify sends state, like "READY=1", to systemd. sent is false if the process isn't running
+// under a unit that listens for notifications.
+func Notify(state string) (sent bool, err error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// a leading @ is an abstract socket, which Go names with a leading NUL
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Ready tells systemd the server is up, so units ordered after it can start.
+func Ready() (bool, error) {
+	return Notify("READY=1")
+}
+
+// Stopping tells systemd the server is shutting down on purpose.
+func Stopping() (bool, error) {
+	return Notify("STOPPING=1")
+}
+
+// WatchdogInterval returns how often systemd expects to hear the server is alive, or 0 if the
+// unit has no watchdog or it's meant for another process.
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// RunWatchdog blocks, telling systemd the server is alive at half of interval until ctx is done.
+// If healthy isn't nil, a ping is skipped while it returns false, so systemd restarts a server
+// that stays unhealthy for the whole interval.
+func RunWatchdog(ctx context.Context, interval time.Duration, healthy func() bool) {
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if healthy == nil || healthy() {
+				Notify("WATCHDOG=1")
+			}
+		}
+	}
+}
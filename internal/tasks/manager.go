@@ -0,0 +1,171 @@
+// Package tasks runs long admin actions in the background so their requests can return a
+// task id right away. Tasks live in memory: they're lost on restart and not shared between
+// instances. That's enough for a single API server; a shared work queue would replace it.
+package tasks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/public/tasks/dto"
+)
+
+// Func does a task's work. It should call progress as it goes and return early when ctx is done.
+type Func func(ctx context.Context, progress func(done int, total int)) (any, error)
+
+type task struct {
+	dto.TaskDto
+	fn Func
+}
+
+// Manager queues tasks and runs them on a fixed number of workers.
+type Manager struct {
+	mu          sync.Mutex
+	tasks       map[string]*task
+	finished    []string // ids in the order they finished, for pruning
+	queue       chan *task
+	maxFinished int
+}
+
+// NewManager returns a Manager that holds up to queueSize waiting tasks and remembers the
+// last maxFinished finished tasks. Call Run to start working.
+func NewManager(queueSize int, maxFinished int) *Manager {
+	return &Manager{
+		tasks:       make(map[string]*task),
+		queue:       make(chan *task, queueSize),
+		maxFinished: maxFinished,
+	}
+}
+
+// Run works tasks on workers goroutines until ctx is done. Running tasks see ctx canceled;
+// tasks still queued stay queued.
+func (m *Manager) Run(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case t := <-m.queue:
+					m.work(ctx, t)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Submit queues fn and returns the queued task. If the queue is full, it returns a
+// CommonError with code ErrcdBusy.
+func (m *Manager) Submit(kind string, fn Func) (dto.TaskDto, error) {
+	t := &task{
+		TaskDto: dto.TaskDto{
+			TaskId:    common.NewUuidV7(),
+			Kind:      kind,
+			State:     dto.StateQueued,
+			CreatedTs: formatTs(time.Now()),
+		},
+		fn: fn,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case m.queue <- t:
+	default:
+		return dto.TaskDto{}, common.NewCommonError(common.ErrcdBusy, errors.New("task queue is full; try again later"))
+	}
+	m.tasks[t.TaskId] = t
+	return t.TaskDto, nil
+}
+
+// Get returns a copy of the task with id taskId.
+func (m *Manager) Get(taskId string) (dto.TaskDto, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tasks[taskId]
+	if !ok {
+		return dto.TaskDto{}, false
+	}
+	return t.TaskDto, true
+}
+
+// List returns copies of every task the manager remembers, in no particular order.
+func (m *Manager) List() []dto.TaskDto {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]dto.TaskDto, 0, len(m.tasks))
+	for _, t := range m.tasks {
+		result = append(result, t.TaskDto)
+	}
+	return result
+}
+
+// Pending returns how many tasks are queued or running.
+func (m *Manager) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, t := range m.tasks {
+		if t.State == dto.StateQueued || t.State == dto.StateRunning {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *Manager) work(ctx context.Context, t *task) {
+	m.locked(func() {
+		t.State = dto.StateRunning
+		t.StartedTs = formatTs(time.Now())
+	})
+
+	progress := func(done int, total int) {
+		m.locked(func() {
+			t.ProgressDone = done
+			t.ProgressTotal = total
+		})
+	}
+	result, err := runFunc(ctx, t.fn, progress)
+
+	m.locked(func() {
+		t.FinishedTs = formatTs(time.Now())
+		if err != nil {
+			t.State = dto.StateFailed
+			t.Error = err.Error()
+		} else {
+			t.State = dto.StateCompleted
+			t.Result = result
+		}
+		m.finished = append(m.finished, t.TaskId)
+		for len(m.finished) > m.maxFinished {
+			delete(m.tasks, m.finished[0])
+			m.finished = m.finished[1:]
+		}
+	})
+}
+
+// runFunc turns a panic in fn into an error so one bad task doesn't take down the server.
+func runFunc(ctx context.Context, fn Func, progress func(int, int)) (result any, err error) {
+	err = common.CatchPanic("task", func() (err error) {
+		result, err = fn(ctx, progress)
+		return err
+	})
+	return result, err
+}
+
+func (m *Manager) locked(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn()
+}
+
+func formatTs(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
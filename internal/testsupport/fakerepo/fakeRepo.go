@@ -0,0 +1,133 @@
+// Package fakerepo has a test double for the repo ports on the query path. It's apart from
+// testsupport because it imports dbmemory, whose dependencies use testsupport in their own tests.
+package fakerepo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/jobStatus/dbmemory"
+)
+
+// Call is one call to a FakeRepo method. Args are the arguments after ctx, without callbacks.
+type Call struct {
+	Method string
+	Args   []any
+}
+
+// FakeRepo implements jobStatus.Repo, StreamRepo, and FilterRepo over the dbmemory.RepoMemory it
+// embeds, so statuses it's given are stored and queried like they are in the memory backend. The
+// other ports go straight to the RepoMemory.
+//
+// Fail makes one of the three ports' methods return an error instead of reaching the RepoMemory,
+// and Calls lists the calls made to them, failed or not, in order.
+type FakeRepo struct {
+	*dbmemory.RepoMemory
+
+	mu    sync.Mutex
+	errs  map[string]error
+	calls []Call
+}
+
+var (
+	_ jobStatus.Repo       = (*FakeRepo)(nil)
+	_ jobStatus.StreamRepo = (*FakeRepo)(nil)
+	_ jobStatus.FilterRepo = (*FakeRepo)(nil)
+	_ jobStatus.FullRepo   = (*FakeRepo)(nil)
+)
+
+// NewFakeRepo returns a FakeRepo over an empty RepoMemory.
+func NewFakeRepo() *FakeRepo {
+	return &FakeRepo{RepoMemory: dbmemory.NewRepoMemory(), errs: map[string]error{}}
+}
+
+// Fail makes method (like "Add") return err from now on. A nil err makes it succeed again.
+func (f *FakeRepo) Fail(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.errs, method)
+		return
+	}
+	f.errs[method] = err
+}
+
+// Calls returns the calls made so far, oldest first.
+func (f *FakeRepo) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// record adds a call and returns the error Fail set for method, if any.
+func (f *FakeRepo) record(method string, args ...any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Method: method, Args: args})
+	return f.errs[method]
+}
+
+func (f *FakeRepo) Add(ctx context.Context, js jobStatus.JobStatus) error {
+	if err := f.record("Add", js); err != nil {
+		return err
+	}
+	return f.RepoMemory.Add(ctx, js)
+}
+
+func (f *FakeRepo) AddIdempotent(ctx context.Context, js jobStatus.JobStatus) (jobStatus.JobStatus, bool, error) {
+	if err := f.record("AddIdempotent", js); err != nil {
+		return js, false, err
+	}
+	return f.RepoMemory.AddIdempotent(ctx, js)
+}
+
+func (f *FakeRepo) AddBatch(ctx context.Context, jss []jobStatus.JobStatus) error {
+	if err := f.record("AddBatch", jss); err != nil {
+		return err
+	}
+	return f.RepoMemory.AddBatch(ctx, jss)
+}
+
+func (f *FakeRepo) GetByJobId(ctx context.Context, jobId jobStatus.JobIdType, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	if err := f.record("GetByJobId", jobId, opts); err != nil {
+		return nil, err
+	}
+	return f.RepoMemory.GetByJobId(ctx, jobId, opts)
+}
+
+func (f *FakeRepo) GetByJobIdBusinessDate(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	if err := f.record("GetByJobIdBusinessDate", jobId, businessDate, opts); err != nil {
+		return nil, err
+	}
+	return f.RepoMemory.GetByJobIdBusinessDate(ctx, jobId, businessDate, opts)
+}
+
+func (f *FakeRepo) ForEachByJobId(ctx context.Context, jobId jobStatus.JobIdType, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	if err := f.record("ForEachByJobId", jobId, opts); err != nil {
+		return err
+	}
+	return f.RepoMemory.ForEachByJobId(ctx, jobId, opts, fn)
+}
+
+func (f *FakeRepo) ForEachByJobIdBusinessDate(ctx context.Context, jobId jobStatus.JobIdType, businessDate time.Time, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	if err := f.record("ForEachByJobIdBusinessDate", jobId, businessDate, opts); err != nil {
+		return err
+	}
+	return f.RepoMemory.ForEachByJobIdBusinessDate(ctx, jobId, businessDate, opts, fn)
+}
+
+func (f *FakeRepo) GetByFilters(ctx context.Context, opts jobStatus.QueryOptions) ([]jobStatus.JobStatus, error) {
+	if err := f.record("GetByFilters", opts); err != nil {
+		return nil, err
+	}
+	return f.RepoMemory.GetByFilters(ctx, opts)
+}
+
+func (f *FakeRepo) ForEachByFilters(ctx context.Context, opts jobStatus.QueryOptions, fn func(jobStatus.JobStatus) error) error {
+	if err := f.record("ForEachByFilters", opts); err != nil {
+		return err
+	}
+	return f.RepoMemory.ForEachByFilters(ctx, opts, fn)
+}
@@ -0,0 +1,51 @@
+package fakerepo_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/internal/common"
+	"github.com/jmjf/go-jst/internal/jobStatus"
+	"github.com/jmjf/go-jst/internal/testsupport/fakerepo"
+)
+
+func TestFakeRepoFailsAndRecordsCalls(t *testing.T) {
+	ctx := context.Background()
+	busDt := time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)
+	js, err := jobStatus.NewJobStatus("overdrafts", "od-calc", "SUCCEED", busDt.Add(25*time.Hour), busDt, "1", "batch01")
+	if err != nil {
+		t.Fatalf("NewJobStatus: %v", err)
+	}
+	repo := fakerepo.NewFakeRepo()
+
+	down := common.NewCommonError(common.ErrcdRepoConnection, errors.New("down"))
+	repo.Fail("Add", down)
+	if err := repo.Add(ctx, js); !errors.Is(err, down) {
+		t.Fatalf("failed Add: got %v, want %v", err, down)
+	}
+	repo.Fail("Add", nil)
+	if err := repo.Add(ctx, js); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	// a use case sees the stored status through the fake
+	uc := jobStatus.NewGetJobStatusesUC(repo, repo, repo)
+	jss, err := uc.GetByJobId(ctx, "od-calc", jobStatus.QueryParams{})
+	if err != nil {
+		t.Fatalf("GetByJobId: %v", err)
+	}
+	if len(jss) != 1 {
+		t.Errorf("got %d statuses, want 1", len(jss))
+	}
+
+	var methods []string
+	for _, c := range repo.Calls() {
+		methods = append(methods, c.Method)
+	}
+	if want := []string{"Add", "Add", "GetByJobId"}; !reflect.DeepEqual(methods, want) {
+		t.Errorf("got calls %v, want %v", methods, want)
+	}
+}
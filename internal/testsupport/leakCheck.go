@@ -0,0 +1,235 @@
+// Package testsupport has checks go-jst's tests share. For a repo without a database, use
+// dbmemory.RepoMemory.
+package testsupport
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TB is the part of testing.TB the leak checks use, so this package doesn't import testing.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Logf(format string, args ...any)
+}
+
+// LeakCheckMode comes from the GOJST_LEAKCHECK environment variable:
+//
+//	fail (default)  leaks fail the test; use this in CI
+//	warn            leaks are logged but don't fail
+//	off             no checks
+func LeakCheckMode() string {
+	switch mode := os.Getenv("GOJST_LEAKCHECK"); mode {
+	case "warn", "off":
+		return mode
+	}
+	return "fail"
+}
+
+// LeakOptions tune leak checks. The zero value waits up to 2 seconds for goroutines to finish.
+type LeakOptions struct {
+	Timeout time.Duration
+	// IgnoreFunctions are function names (like "net/http.(*persistConn).readLoop"); goroutines
+	// with any of them in their stack aren't reported.
+	IgnoreFunctions []string
+}
+
+// defaultIgnore are goroutines the runtime and test framework start on their own.
+var defaultIgnore = []string{
+	"testing.(*T).Run",
+	"testing.(*M).",
+	"testing.tRunner",
+	"testing.runTests",
+	"os/signal.signal_recv",
+	"os/signal.loop",
+	"runtime.ensureSigM",
+	"runtime.ReadTrace",
+}
+
+// CheckGoroutines records the running goroutines and returns a func that reports any new
+// goroutines still running when it's called. Goroutines get opts.Timeout to finish first.
+// Don't use it in parallel tests; their goroutines look like leaks.
+//
+//	defer testsupport.CheckGoroutines(t, testsupport.LeakOptions{})()
+func CheckGoroutines(t TB, opts LeakOptions) func() {
+	mode := LeakCheckMode()
+	if mode == "off" {
+		return func() {}
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 2 * time.Second
+	}
+	before := goroutineIds(currentGoroutines())
+
+	return func() {
+		t.Helper()
+		var leaked []goroutine
+		deadline := time.Now().Add(opts.Timeout)
+		for {
+			leaked = leaked[:0]
+			for _, g := range currentGoroutines() {
+				if !before[g.id] && !g.ignored(opts.IgnoreFunctions) {
+					leaked = append(leaked, g)
+				}
+			}
+			if len(leaked) == 0 || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		if len(leaked) > 0 {
+			var stacks strings.Builder
+			for _, g := range leaked {
+				stacks.WriteString(g.stack)
+				stacks.WriteString("\n\n")
+			}
+			report(t, mode, "%d leaked goroutine(s):\n%s", len(leaked), stacks.String())
+		}
+	}
+}
+
+// CheckDB returns a func that reports connections still in use on db when it's called.
+// Unclosed *sql.Rows and unfinished transactions hold a connection, so this catches a
+// missing defer rows.Close() in repo code.
+//
+//	defer testsupport.CheckDB(t, repo.DB)()
+func CheckDB(t TB, db *sql.DB) func() {
+	mode := LeakCheckMode()
+	if mode == "off" {
+		return func() {}
+	}
+
+	return func() {
+		t.Helper()
+		// closing rows returns the connection asynchronously in some drivers, so give it a moment
+		deadline := time.Now().Add(time.Second)
+		stats := db.Stats()
+		for stats.InUse > 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+			stats = db.Stats()
+		}
+		if stats.InUse > 0 {
+			report(t, mode, "%d database connection(s) still in use (unclosed rows or transactions?)", stats.InUse)
+		}
+	}
+}
+
+// TestingM is the part of *testing.M that VerifyTestMain uses.
+type TestingM interface {
+	Run() int
+}
+
+// VerifyTestMain runs the package's tests and fails the package if goroutines are still
+// running afterward. Use it from TestMain to check a whole package in one place:
+//
+//	func TestMain(m *testing.M) { os.Exit(testsupport.VerifyTestMain(m)) }
+func VerifyTestMain(m TestingM) int {
+	code := m.Run()
+	if code != 0 || LeakCheckMode() == "off" {
+		return code
+	}
+
+	var leaked []goroutine
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		leaked = leaked[:0]
+		for _, g := range currentGoroutines() {
+			if !g.ignored(nil) {
+				leaked = append(leaked, g)
+			}
+		}
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if len(leaked) == 0 {
+		return code
+	}
+	fmt.Fprintf(os.Stderr, "leakcheck: %d goroutine(s) still running after tests:\n", len(leaked))
+	for _, g := range leaked {
+		fmt.Fprintf(os.Stderr, "%s\n\n", g.stack)
+	}
+	if LeakCheckMode() == "fail" {
+		return 1
+	}
+	return code
+}
+
+func report(t TB, mode string, format string, args ...any) {
+	t.Helper()
+	if mode == "warn" {
+		t.Logf("leakcheck warning: "+format, args...)
+		return
+	}
+	t.Errorf("leakcheck: "+format, args...)
+}
+
+type goroutine struct {
+	id    int
+	stack string
+}
+
+func (g goroutine) ignored(extra []string) bool {
+	for _, fn := range defaultIgnore {
+		if strings.Contains(g.stack, fn) {
+			return true
+		}
+	}
+	for _, fn := range extra {
+		if strings.Contains(g.stack, fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// currentGoroutines returns every goroutine except the caller's.
+func currentGoroutines() []goroutine {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	var result []goroutine
+	blocks := bytes.Split(buf, []byte("\n\n"))
+	for i, block := range blocks {
+		// the first block is the goroutine calling runtime.Stack
+		if i == 0 {
+			continue
+		}
+		header, _, _ := strings.Cut(string(block), "\n")
+		fields := strings.Fields(header) // goroutine 18 [chan receive]:
+		if len(fields) < 2 || fields[0] != "goroutine" {
+			continue
+		}
+		id, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		result = append(result, goroutine{id: id, stack: string(block)})
+	}
+	return result
+}
+
+func goroutineIds(gs []goroutine) map[int]bool {
+	ids := make(map[int]bool, len(gs))
+	for _, g := range gs {
+		ids[g.id] = true
+	}
+	return ids
+}
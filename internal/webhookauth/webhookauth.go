@@ -0,0 +1,222 @@
+// Package webhookauth verifies inbound webhook deliveries (from CI systems and schedulers)
+// before their handlers run. Each endpoint gets its own Verifier and secret.
+package webhookauth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/public/webhook"
+)
+
+// MaxBodyBytes bounds how much of a delivery is read to verify it.
+const MaxBodyBytes = 1 << 20
+
+var (
+	ErrUnsigned = errors.New("delivery is not signed")
+	ErrBadSig   = errors.New("delivery signature doesn't match")
+	ErrReplayed = errors.New("delivery was already received")
+)
+
+// Verifier checks one delivery. body is the full request body.
+type Verifier interface {
+	Verify(r *http.Request, body []byte) error
+}
+
+// Require passes only verified deliveries to next, with the body restored so next can read it.
+// Failures get 401 without details; the reason is logged.
+func Require(v Verifier, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, MaxBodyBytes+1))
+		if err != nil {
+			http.Error(w, "could not read body", http.StatusBadRequest)
+			return
+		}
+		if len(body) > MaxBodyBytes {
+			http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err := v.Verify(r, body); err != nil {
+			log.Printf("%s %s webhook rejected: %v", r.Method, r.URL.Path, err)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// HmacVerifier checks go-jst's own scheme (public/webhook): a timestamped HMAC-SHA256, so
+// stale deliveries are rejected by time.
+type HmacVerifier struct {
+	Secret    []byte
+	Tolerance time.Duration // 0 means webhook.DefaultTolerance
+}
+
+func (v HmacVerifier) Verify(r *http.Request, body []byte) error {
+	tolerance := v.Tolerance
+	if tolerance == 0 {
+		tolerance = webhook.DefaultTolerance
+	}
+	switch err := webhook.Verify(r.Header.Get(webhook.SignatureHeader), body, v.Secret, time.Now(), tolerance); {
+	case errors.Is(err, webhook.ErrNoSignature):
+		return ErrUnsigned
+	case err != nil:
+		return err
+	}
+	return nil
+}
+
+// GitHubVerifier checks X-Hub-Signature-256. GitHub doesn't sign a timestamp, so replays are
+// caught by remembering X-GitHub-Delivery ids in Replays (see ReplayCache for deliveries without one).
+type GitHubVerifier struct {
+	Secret  []byte
+	Replays *ReplayCache
+}
+
+func (v GitHubVerifier) Verify(r *http.Request, body []byte) error {
+	sig, ok := strings.CutPrefix(r.Header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		return ErrUnsigned
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return ErrBadSig
+	}
+	h := hmac.New(sha256.New, v.Secret)
+	h.Write(body)
+	if !hmac.Equal(got, h.Sum(nil)) {
+		return ErrBadSig
+	}
+	return v.Replays.check(r.Header.Get("X-GitHub-Delivery"), body)
+}
+
+// GitLabVerifier checks X-Gitlab-Token, which GitLab sends as a plain shared secret. Replays
+// are caught with X-Gitlab-Event-UUID, which older GitLab versions don't send.
+type GitLabVerifier struct {
+	Token   string
+	Replays *ReplayCache
+}
+
+func (v GitLabVerifier) Verify(r *http.Request, body []byte) error {
+	token := r.Header.Get("X-Gitlab-Token")
+	if token == "" {
+		return ErrUnsigned
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(v.Token)) != 1 {
+		return ErrBadSig
+	}
+	return v.Replays.check(r.Header.Get("X-Gitlab-Event-UUID"), body)
+}
+
+// SlackVerifier checks Slack's v0 request signing (X-Slack-Signature over the timestamp and
+// body), so stale requests are rejected by X-Slack-Request-Timestamp.
+type SlackVerifier struct {
+	Secret    []byte
+	Tolerance time.Duration // 0 means webhook.DefaultTolerance
+}
+
+func (v SlackVerifier) Verify(r *http.Request, body []byte) error {
+	sig, ok := strings.CutPrefix(r.Header.Get("X-Slack-Signature"), "v0=")
+	tsHeader := r.Header.Get("X-Slack-Request-Timestamp")
+	if !ok || tsHeader == "" {
+		return ErrUnsigned
+	}
+	ts, err := strconv.ParseInt(tsHeader, 10, 64)
+	if err != nil {
+		return ErrBadSig
+	}
+	tolerance := v.Tolerance
+	if tolerance == 0 {
+		tolerance = webhook.DefaultTolerance
+	}
+	if age := time.Since(time.Unix(ts, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: timestamp %s is outside %s", ErrBadSig, tsHeader, tolerance)
+	}
+
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return ErrBadSig
+	}
+	h := hmac.New(sha256.New, v.Secret)
+	h.Write([]byte("v0:" + tsHeader + ":"))
+	h.Write(body)
+	if !hmac.Equal(got, h.Sum(nil)) {
+		return ErrBadSig
+	}
+	return nil
+}
+
+// ReplayCache remembers delivery ids for a window. A delivery without an id is remembered by
+// its body's SHA-256 instead, so leaving the id header off doesn't get a replay through; the
+// providers' payloads carry their own ids and times, so two real deliveries don't share a body.
+// A nil *ReplayCache doesn't check replays.
+type ReplayCache struct {
+	window time.Duration
+	mu     sync.Mutex
+	seen   map[string]time.Time
+}
+
+func NewReplayCache(window time.Duration) *ReplayCache {
+	return &ReplayCache{window: window, seen: map[string]time.Time{}}
+}
+
+func (rc *ReplayCache) check(deliveryId string, body []byte) error {
+	if rc == nil {
+		return nil
+	}
+	if deliveryId == "" {
+		sum := sha256.Sum256(body)
+		deliveryId = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	now := time.Now()
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for id, ts := range rc.seen {
+		if now.Sub(ts) > rc.window {
+			delete(rc.seen, id)
+		}
+	}
+	if _, ok := rc.seen[deliveryId]; ok {
+		return fmt.Errorf("%w: %s", ErrReplayed, deliveryId)
+	}
+	rc.seen[deliveryId] = now
+	return nil
+}
+
+// VerifierFromEnv builds the Verifier for endpoint name from GOJST_WEBHOOK_<NAME>_SCHEME
+// (hmac, github, gitlab, or slack) and GOJST_WEBHOOK_<NAME>_SECRET. There's no unsigned scheme;
+// an endpoint without settings is an error so it can't be mounted open by accident.
+func VerifierFromEnv(getenv func(string) string, name string) (Verifier, error) {
+	prefix := "GOJST_WEBHOOK_" + strings.ToUpper(name) + "_"
+	scheme := getenv(prefix + "SCHEME")
+	secret := getenv(prefix + "SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("%sSECRET is required", prefix)
+	}
+
+	switch scheme {
+	case "hmac":
+		return HmacVerifier{Secret: []byte(secret)}, nil
+	case "github":
+		return GitHubVerifier{Secret: []byte(secret), Replays: NewReplayCache(time.Hour)}, nil
+	case "gitlab":
+		return GitLabVerifier{Token: secret, Replays: NewReplayCache(time.Hour)}, nil
+	case "slack":
+		return SlackVerifier{Secret: []byte(secret)}, nil
+	}
+	return nil, fmt.Errorf("%sSCHEME %q must be hmac, github, gitlab, or slack", prefix, scheme)
+}
@@ -0,0 +1,214 @@
+package webhookauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmjf/go-jst/public/webhook"
+)
+
+var (
+	secret = []byte("s3cret")
+	body   = []byte(`{"JobId":"od-calc"}`)
+)
+
+func newRequest(headers map[string]string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/hooks/ci", nil)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	return r
+}
+
+func hexHmac(key []byte, parts ...string) string {
+	h := hmac.New(sha256.New, key)
+	for _, p := range parts {
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// checkErr fails the test unless err is want (nil for success), compared with errors.Is.
+func checkErr(t *testing.T, name string, err error, want error) {
+	t.Helper()
+	if (want == nil && err != nil) || (want != nil && !errors.Is(err, want)) {
+		t.Errorf("%s: got %v, want %v", name, err, want)
+	}
+}
+
+func TestHmacVerifier(t *testing.T) {
+	v := HmacVerifier{Secret: secret}
+	signed := func(b []byte, ts time.Time, key []byte) *http.Request {
+		return newRequest(map[string]string{webhook.SignatureHeader: webhook.Sign(b, ts, key)})
+	}
+
+	checkErr(t, "signed now", v.Verify(signed(body, time.Now(), secret), body), nil)
+	checkErr(t, "unsigned", v.Verify(newRequest(nil), body), ErrUnsigned)
+	if err := v.Verify(signed(body, time.Now(), []byte("other")), body); err == nil {
+		t.Error("wrong secret: got no error")
+	}
+	if err := v.Verify(signed(body, time.Now(), secret), []byte(`{"JobId":"other"}`)); err == nil {
+		t.Error("changed body: got no error")
+	}
+	if err := v.Verify(signed(body, time.Now().Add(-webhook.DefaultTolerance-time.Minute), secret), body); err == nil {
+		t.Error("stale: got no error")
+	}
+	short := HmacVerifier{Secret: secret, Tolerance: time.Second}
+	if err := short.Verify(signed(body, time.Now().Add(-time.Minute), secret), body); err == nil {
+		t.Error("older than Tolerance: got no error")
+	}
+}
+
+func TestGitHubVerifier(t *testing.T) {
+	v := GitHubVerifier{Secret: secret, Replays: NewReplayCache(time.Hour)}
+	delivery := func(id string, sig string) *http.Request {
+		return newRequest(map[string]string{"X-Hub-Signature-256": sig, "X-GitHub-Delivery": id})
+	}
+	good := "sha256=" + hexHmac(secret, string(body))
+
+	checkErr(t, "signed", v.Verify(delivery("d1", good), body), nil)
+	checkErr(t, "same delivery again", v.Verify(delivery("d1", good), body), ErrReplayed)
+	checkErr(t, "next delivery", v.Verify(delivery("d2", good), body), nil)
+	checkErr(t, "unsigned", v.Verify(delivery("d3", ""), body), ErrUnsigned)
+	checkErr(t, "sha1 only", v.Verify(delivery("d3", "sha1="+hexHmac(secret, string(body))), body), ErrUnsigned)
+	checkErr(t, "not hex", v.Verify(delivery("d3", "sha256=zz"), body), ErrBadSig)
+	checkErr(t, "wrong secret", v.Verify(delivery("d3", "sha256="+hexHmac([]byte("other"), string(body))), body), ErrBadSig)
+	checkErr(t, "changed body", v.Verify(delivery("d3", good), []byte(`{}`)), ErrBadSig)
+
+	// without a delivery id, the body is the id
+	other := []byte(`{"JobId":"other"}`)
+	otherSig := "sha256=" + hexHmac(secret, string(other))
+	checkErr(t, "no id", v.Verify(delivery("", otherSig), other), nil)
+	checkErr(t, "no id, same body again", v.Verify(delivery("", otherSig), other), ErrReplayed)
+}
+
+func TestGitLabVerifier(t *testing.T) {
+	v := GitLabVerifier{Token: "tok3n", Replays: NewReplayCache(time.Hour)}
+	delivery := func(uuid string, token string) *http.Request {
+		return newRequest(map[string]string{"X-Gitlab-Token": token, "X-Gitlab-Event-UUID": uuid})
+	}
+
+	checkErr(t, "token", v.Verify(delivery("u1", "tok3n"), body), nil)
+	checkErr(t, "same event again", v.Verify(delivery("u1", "tok3n"), body), ErrReplayed)
+	checkErr(t, "no token", v.Verify(delivery("u2", ""), body), ErrUnsigned)
+	checkErr(t, "wrong token", v.Verify(delivery("u2", "tok3n-"), body), ErrBadSig)
+	checkErr(t, "prefix of the token", v.Verify(delivery("u2", "tok"), body), ErrBadSig)
+
+	// older GitLab versions don't send X-Gitlab-Event-UUID
+	other := []byte(`{"object_kind":"pipeline","object_attributes":{"id":31}}`)
+	checkErr(t, "no uuid", v.Verify(delivery("", "tok3n"), other), nil)
+	checkErr(t, "no uuid, same body again", v.Verify(delivery("", "tok3n"), other), ErrReplayed)
+	checkErr(t, "no uuid, next body", v.Verify(delivery("", "tok3n"), body), nil)
+}
+
+func TestSlackVerifier(t *testing.T) {
+	v := SlackVerifier{Secret: secret}
+	signed := func(ts string, key []byte) *http.Request {
+		return newRequest(map[string]string{
+			"X-Slack-Request-Timestamp": ts,
+			"X-Slack-Signature":         "v0=" + hexHmac(key, "v0:"+ts+":", string(body)),
+		})
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	checkErr(t, "signed", v.Verify(signed(now, secret), body), nil)
+	checkErr(t, "unsigned", v.Verify(newRequest(map[string]string{"X-Slack-Request-Timestamp": now}), body), ErrUnsigned)
+	checkErr(t, "no timestamp", v.Verify(newRequest(map[string]string{"X-Slack-Signature": "v0=00"}), body), ErrUnsigned)
+	checkErr(t, "bad timestamp", v.Verify(signed("soon", secret), body), ErrBadSig)
+	checkErr(t, "stale", v.Verify(signed(strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10), secret), body), ErrBadSig)
+	checkErr(t, "future", v.Verify(signed(strconv.FormatInt(time.Now().Add(10*time.Minute).Unix(), 10), secret), body), ErrBadSig)
+	checkErr(t, "wrong secret", v.Verify(signed(now, []byte("other")), body), ErrBadSig)
+	checkErr(t, "changed body", v.Verify(signed(now, secret), []byte("text=status")), ErrBadSig)
+
+	// the timestamp is signed, so moving it forward breaks the signature
+	r := signed(strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10), secret)
+	r.Header.Set("X-Slack-Request-Timestamp", now)
+	checkErr(t, "moved timestamp", v.Verify(r, body), ErrBadSig)
+}
+
+func TestReplayCache(t *testing.T) {
+	var off *ReplayCache
+	checkErr(t, "nil cache", off.check("d1", body), nil)
+	checkErr(t, "nil cache again", off.check("d1", body), nil)
+
+	rc := NewReplayCache(20 * time.Millisecond)
+	checkErr(t, "first", rc.check("d1", body), nil)
+	checkErr(t, "replay", rc.check("d1", []byte("other body")), ErrReplayed)
+	checkErr(t, "other id, same body", rc.check("d2", body), nil)
+	checkErr(t, "no id", rc.check("", body), nil)
+	checkErr(t, "no id, same body", rc.check("", body), ErrReplayed)
+
+	time.Sleep(40 * time.Millisecond)
+	checkErr(t, "after the window", rc.check("d1", body), nil)
+	if len(rc.seen) != 1 {
+		t.Errorf("got %d ids remembered after the window, want 1", len(rc.seen))
+	}
+}
+
+func TestRequire(t *testing.T) {
+	h := Require(GitLabVerifier{Token: "tok3n"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Write(b)
+	}))
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		body  string
+		want  int
+	}{
+		{"verified", "tok3n", string(body), http.StatusOK},
+		{"unverified", "wrong", string(body), http.StatusUnauthorized},
+		{"too large", "tok3n", strings.Repeat("x", MaxBodyBytes+1), http.StatusRequestEntityTooLarge},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/hooks/ci", strings.NewReader(tc.body))
+		r.Header.Set("X-Gitlab-Token", tc.token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if rec.Code != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, rec.Code, tc.want)
+		}
+		if tc.want == http.StatusOK && rec.Body.String() != tc.body {
+			t.Errorf("%s: next read %q, want the body restored", tc.name, rec.Body.String())
+		}
+	}
+}
+
+func TestVerifierFromEnv(t *testing.T) {
+	env := func(scheme string, secret string) func(string) string {
+		return func(key string) string {
+			return map[string]string{"GOJST_WEBHOOK_CI_SCHEME": scheme, "GOJST_WEBHOOK_CI_SECRET": secret}[key]
+		}
+	}
+	for scheme, want := range map[string]Verifier{
+		"hmac":   HmacVerifier{},
+		"github": GitHubVerifier{},
+		"gitlab": GitLabVerifier{},
+		"slack":  SlackVerifier{},
+	} {
+		v, err := VerifierFromEnv(env(scheme, "s3cret"), "ci")
+		if err != nil {
+			t.Errorf("%s: %v", scheme, err)
+			continue
+		}
+		if got, wantType := fmt.Sprintf("%T", v), fmt.Sprintf("%T", want); got != wantType {
+			t.Errorf("%s: got %s, want %s", scheme, got, wantType)
+		}
+	}
+	if _, err := VerifierFromEnv(env("github", ""), "ci"); err == nil || !strings.Contains(err.Error(), "GOJST_WEBHOOK_CI_SECRET") {
+		t.Errorf("no secret: got %v", err)
+	}
+	if _, err := VerifierFromEnv(env("none", "s3cret"), "ci"); err == nil || !strings.Contains(err.Error(), "GOJST_WEBHOOK_CI_SCHEME") {
+		t.Errorf("unknown scheme: got %v", err)
+	}
+}
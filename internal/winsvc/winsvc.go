@@ -0,0 +1,19 @@
+// Package winsvc runs the server as a Windows service, so the service control manager can start
+// and stop it the way systemd does on Linux (see the systemd package). It reports StartPending
+// until the server is listening, then Running, and turns Stop and Shutdown requests into a stop
+// channel the server treats like SIGTERM, reporting StopPending while it drains. Outside the
+// service control manager (including on every other OS) IsService is false and the other
+// functions do nothing.
+package winsvc
+
+import "time"
+
+const (
+	// the service control manager is told a stop is still making progress this often, so a
+	// long drain isn't taken for a hung service
+	stopCheckpointInterval = 5 * time.Second
+
+	// how long the service control manager should wait for the next checkpoint; longer than
+	// stopCheckpointInterval so a late checkpoint isn't a failure
+	stopWaitHint = 2 * stopCheckpointInterval
+)
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package winsvc
+
+import "errors"
+
+// IsService is true if the service control manager started the process. It's never true here.
+func IsService() bool {
+	return false
+}
+
+// Run runs main as the named service. It's only possible on Windows.
+func Run(name string, main func()) error {
+	return errors.New("winsvc: Windows services need Windows")
+}
+
+// StopRequested is closed when the service control manager asks the service to stop. It's never
+// closed here.
+func StopRequested() <-chan struct{} {
+	return nil
+}
+
+// Ready tells the service control manager the server is up.
+func Ready() {}
+
+// Stopping tells the service control manager the server is shutting down.
+func Stopping() {}
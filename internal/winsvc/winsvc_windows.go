@@ -0,0 +1,126 @@
+//go:build windows
+
+package winsvc
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+var (
+	// inService is set while Run is running main, so Ready and Stopping know to report
+	inService atomic.Bool
+
+	// states carries Ready and Stopping to the handler; they never block the server
+	states = make(chan svc.State, 2)
+
+	stopRequested = make(chan struct{})
+	stopOnce      sync.Once
+)
+
+// IsService is true if the service control manager started the process. If it can't tell, it
+// says no, so the server runs as a console program and logs to stderr.
+func IsService() bool {
+	is, err := svc.IsWindowsService()
+	return err == nil && is
+}
+
+// Run runs main as the named service and returns when main returns. It must be called soon after
+// the process starts, before the service control manager gives up on it. The name is ignored for
+// services in their own process, which is how the server is installed.
+func Run(name string, main func()) error {
+	inService.Store(true)
+	defer inService.Store(false)
+	return svc.Run(name, handler{main: main})
+}
+
+// StopRequested is closed when the service control manager asks the service to stop, or the
+// system is shutting down.
+func StopRequested() <-chan struct{} {
+	return stopRequested
+}
+
+// Ready tells the service control manager the server is up, so services that depend on it can start.
+func Ready() {
+	report(svc.Running)
+}
+
+// Stopping tells the service control manager the server is shutting down, if a stop request
+// hasn't already.
+func Stopping() {
+	report(svc.StopPending)
+}
+
+func report(state svc.State) {
+	if !inService.Load() {
+		return
+	}
+	select {
+	case states <- state:
+	default:
+	}
+}
+
+type handler struct {
+	main func()
+}
+
+// Execute runs main and answers the service control manager until main returns.
+func (h handler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.main()
+	}()
+
+	// while stopping, a ticker sends checkpoints; checkpoints is nil (never ready) until then
+	var ticker *time.Ticker
+	var checkpoints <-chan time.Time
+	var checkpoint uint32
+	sendCheckpoint := func() {
+		checkpoint++
+		status <- svc.Status{State: svc.StopPending, CheckPoint: checkpoint, WaitHint: uint32(stopWaitHint / time.Millisecond)}
+	}
+	stopping := func() {
+		if ticker != nil {
+			return
+		}
+		ticker = time.NewTicker(stopCheckpointInterval)
+		checkpoints = ticker.C
+		sendCheckpoint()
+	}
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			// svc reports Stopped when Execute returns
+			return false, 0
+		case state := <-states:
+			switch {
+			case state == svc.StopPending:
+				stopping()
+			case ticker == nil:
+				// a server that's stopping isn't Running again
+				status <- svc.Status{State: state, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+			}
+		case <-checkpoints:
+			sendCheckpoint()
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				stopOnce.Do(func() { close(stopRequested) })
+				stopping()
+			}
+		}
+	}
+}
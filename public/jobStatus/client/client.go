@@ -0,0 +1,491 @@
+// Package client is a Go client for the job status HTTP API.
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+const (
+	jobStatusesPath       = "/job-statuses"
+	jobStatusBatchesPath  = "/job-status-batches"
+	jobStatusRollupsPath  = "/job-status-rollups"
+	jobReliabilityPath    = "/job-reliability"
+	jobFlakinessPath      = "/job-flakiness"
+	durationBaselinesPath = "/job-duration-baselines"
+	jobForecastPath       = "/job-forecast"
+	runCostsPath          = "/run-costs"
+	jobCostsPath          = "/job-costs"
+	runCommentsPath       = "/run-comments"
+	jobHeartbeatsPath     = "/job-heartbeats"
+	savedViewsPath        = "/saved-views"
+	statusBoardPath       = "/status-board"
+	jobBadgePath          = "/job-badge"
+	latestJobStatusesPath = "/job-statuses/latest"
+)
+
+// ApiError is returned when the server responds with a non-2xx status. Code, RequestId, and
+// FieldErrors are set if the server sent a dto.ErrorDto; act on Code rather than Message.
+type ApiError struct {
+	StatusCode  int
+	Message     string
+	Code        string
+	RequestId   string
+	FieldErrors []dto.FieldErrorDto
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("job status API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// QueryOptions are optional query parameters. Field names are DTO JSON names; sort terms
+// are a DTO field name optionally followed by :asc or :desc. Filters are keyed by a DTO field
+// name and operator, like {"JobSt[in]": "START,FAIL", "JobStTs[gte]": "2024-05-01T00:00:00Z"}.
+// AsOf is an RFC 3339 timestamp; if it's set, results are only the statuses the server had
+// received by then.
+type QueryOptions struct {
+	Fields  []string
+	Sort    []string
+	Filters map[string]string
+	AsOf    string
+}
+
+func (opts QueryOptions) addTo(q url.Values) {
+	if len(opts.Fields) > 0 {
+		q.Set("fields", strings.Join(opts.Fields, ","))
+	}
+	if len(opts.Sort) > 0 {
+		q.Set("sort", strings.Join(opts.Sort, ","))
+	}
+	for key, value := range opts.Filters {
+		q.Set(key, value)
+	}
+	if opts.AsOf != "" {
+		q.Set("asOf", opts.AsOf)
+	}
+}
+
+type Client struct {
+	baseUrl    string
+	httpClient *http.Client
+}
+
+// New returns a client for the API at baseUrl (like "http://localhost:9201").
+// If httpClient is nil, http.DefaultClient is used.
+func New(baseUrl string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseUrl: strings.TrimRight(baseUrl, "/"), httpClient: httpClient}
+}
+
+// AddJobStatus posts a job status and returns the stored status.
+func (c *Client) AddJobStatus(jsDto dto.JobStatusDto) (dto.JobStatusDto, error) {
+	var result dto.JobStatusDto
+	err := c.doJson(http.MethodPost, jobStatusesPath, nil, jsDto, &result)
+	return result, err
+}
+
+// AddJobStatusBatch adds many statuses in one call and returns them as stored, in the same
+// order. The server stores all of them or none.
+func (c *Client) AddJobStatusBatch(jsDtos []dto.JobStatusDto) ([]dto.JobStatusDto, error) {
+	var result []dto.JobStatusDto
+	err := c.doJson(http.MethodPost, jobStatusBatchesPath, nil, jsDtos, &result)
+	return result, err
+}
+
+// GetByJobId returns statuses for a job.
+func (c *Client) GetByJobId(jobId string, opts QueryOptions) ([]dto.JobStatusDto, error) {
+	q := url.Values{"jobId": {jobId}}
+	opts.addTo(q)
+
+	var result []dto.JobStatusDto
+	err := c.doJson(http.MethodGet, jobStatusesPath, q, nil, &result)
+	return result, err
+}
+
+// GetByJobIdBusinessDate returns statuses for a job on a business date (YYYY-MM-DD).
+func (c *Client) GetByJobIdBusinessDate(jobId string, businessDate string, opts QueryOptions) ([]dto.JobStatusDto, error) {
+	q := url.Values{"jobId": {jobId}, "busDt": {businessDate}}
+	opts.addTo(q)
+
+	var result []dto.JobStatusDto
+	err := c.doJson(http.MethodGet, jobStatusesPath, q, nil, &result)
+	return result, err
+}
+
+// GetPageByJobId returns one page of a job's statuses, skipping offset and returning at most
+// limit. Use the returned Page.NextOffset for the next page; it's nil after the last one.
+func (c *Client) GetPageByJobId(jobId string, opts QueryOptions, limit int, offset int) (dto.JobStatusPageDto, error) {
+	q := url.Values{"jobId": {jobId}, "limit": {strconv.Itoa(limit)}, "offset": {strconv.Itoa(offset)}}
+	opts.addTo(q)
+
+	var result dto.JobStatusPageDto
+	err := c.doJson(http.MethodGet, jobStatusesPath, q, nil, &result)
+	return result, err
+}
+
+// GetByView returns statuses for the jobs and applications in a saved view. An empty
+// businessDate returns all dates. Fields and Sort in opts override the view's.
+func (c *Client) GetByView(viewName string, businessDate string, opts QueryOptions) ([]dto.JobStatusDto, error) {
+	q := url.Values{"view": {viewName}}
+	if businessDate != "" {
+		q.Set("busDt", businessDate)
+	}
+	opts.addTo(q)
+
+	var result []dto.JobStatusDto
+	err := c.doJson(http.MethodGet, jobStatusesPath, q, nil, &result)
+	return result, err
+}
+
+// GetByFilters returns statuses matching opts.Filters, which have to narrow on JobId or BusDt,
+// like {"JobId[prefix]": "billing-"}.
+func (c *Client) GetByFilters(opts QueryOptions) ([]dto.JobStatusDto, error) {
+	q := url.Values{}
+	opts.addTo(q)
+
+	var result []dto.JobStatusDto
+	err := c.doJson(http.MethodGet, jobStatusesPath, q, nil, &result)
+	return result, err
+}
+
+// StreamByJobId calls fn for each status for a job as the server streams it.
+func (c *Client) StreamByJobId(jobId string, opts QueryOptions, fn func(dto.JobStatusDto) error) error {
+	q := url.Values{"jobId": {jobId}}
+	opts.addTo(q)
+	return c.stream(q, fn)
+}
+
+// StreamByJobIdBusinessDate calls fn for each status for a job on a business date as the server streams it.
+func (c *Client) StreamByJobIdBusinessDate(jobId string, businessDate string, opts QueryOptions, fn func(dto.JobStatusDto) error) error {
+	q := url.Values{"jobId": {jobId}, "busDt": {businessDate}}
+	opts.addTo(q)
+	return c.stream(q, fn)
+}
+
+// RunDailyRollup asks the server to recompute rollups for a business date range. It's an admin
+// route, so the Client's http.Client must send the admin bearer token.
+func (c *Client) RunDailyRollup(fromDate string, toDate string) (dto.RollupResultDto, error) {
+	q := url.Values{"fromDt": {fromDate}, "toDt": {toDate}}
+
+	var result dto.RollupResultDto
+	err := c.doJson(http.MethodPost, jobStatusRollupsPath, q, nil, &result)
+	return result, err
+}
+
+// GetDailyRollups returns rollups for a business date range. An empty applicationId returns all applications.
+func (c *Client) GetDailyRollups(applicationId string, fromDate string, toDate string) ([]dto.DailyRollupDto, error) {
+	q := url.Values{"fromDt": {fromDate}, "toDt": {toDate}}
+	if applicationId != "" {
+		q.Set("appId", applicationId)
+	}
+
+	var result []dto.DailyRollupDto
+	err := c.doJson(http.MethodGet, jobStatusRollupsPath, q, nil, &result)
+	return result, err
+}
+
+// GetJobReliability returns MTTR and MTBF for jobs over a business date range. An empty
+// applicationId or jobId returns all applications or jobs.
+func (c *Client) GetJobReliability(applicationId string, jobId string, fromDate string, toDate string) ([]dto.JobReliabilityDto, error) {
+	q := url.Values{"fromDt": {fromDate}, "toDt": {toDate}}
+	if applicationId != "" {
+		q.Set("appId", applicationId)
+	}
+	if jobId != "" {
+		q.Set("jobId", jobId)
+	}
+
+	var result []dto.JobReliabilityDto
+	err := c.doJson(http.MethodGet, jobReliabilityPath, q, nil, &result)
+	return result, err
+}
+
+// GetFlakiestJobs ranks an application's jobs by how often their outcome alternates between
+// SUCCEED and FAIL, most flaky first. Zero minRuns or limit uses the server's default.
+func (c *Client) GetFlakiestJobs(applicationId string, fromDate string, toDate string, minRuns int, limit int) ([]dto.JobFlakinessDto, error) {
+	q := url.Values{"appId": {applicationId}, "fromDt": {fromDate}, "toDt": {toDate}}
+	if minRuns != 0 {
+		q.Set("minRuns", strconv.Itoa(minRuns))
+	}
+	if limit != 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+
+	var result []dto.JobFlakinessDto
+	err := c.doJson(http.MethodGet, jobFlakinessPath, q, nil, &result)
+	return result, err
+}
+
+// GetDurationBaselines returns an application's duration baselines by season for a business date
+// range. With forDate, it returns the one baseline per job that applies to that date. jobId and
+// forDate are optional, and zero minRuns uses the server's default.
+func (c *Client) GetDurationBaselines(applicationId string, jobId string, fromDate string, toDate string, forDate string, minRuns int) ([]dto.DurationBaselineDto, error) {
+	q := url.Values{"appId": {applicationId}, "fromDt": {fromDate}, "toDt": {toDate}}
+	if jobId != "" {
+		q.Set("jobId", jobId)
+	}
+	if forDate != "" {
+		q.Set("forDt", forDate)
+	}
+	if minRuns != 0 {
+		q.Set("minRuns", strconv.Itoa(minRuns))
+	}
+
+	var result []dto.DurationBaselineDto
+	err := c.doJson(http.MethodGet, durationBaselinesPath, q, nil, &result)
+	return result, err
+}
+
+// GetJobForecast predicts when a job's run in progress on a business date will end. deadline and
+// asOf are optional RFC 3339 timestamps; without a deadline, the result has no MissProbability.
+func (c *Client) GetJobForecast(applicationId string, jobId string, businessDate string, deadline string, asOf string) (dto.JobForecastDto, error) {
+	q := url.Values{"appId": {applicationId}, "jobId": {jobId}, "busDt": {businessDate}}
+	if deadline != "" {
+		q.Set("deadline", deadline)
+	}
+	if asOf != "" {
+		q.Set("asOf", asOf)
+	}
+
+	var result dto.JobForecastDto
+	err := c.doJson(http.MethodGet, jobForecastPath, q, nil, &result)
+	return result, err
+}
+
+// RecordHeartbeat says a run is still going and returns its START status with HeartbeatTs set.
+// It fails with a 404 ApiError if the run hasn't posted a START.
+func (c *Client) RecordHeartbeat(hbDto dto.HeartbeatDto) (dto.JobStatusDto, error) {
+	var result dto.JobStatusDto
+	err := c.doJson(http.MethodPost, jobHeartbeatsPath, nil, hbDto, &result)
+	return result, err
+}
+
+// PutRunCost adds or replaces a run's cost and returns the stored cost.
+func (c *Client) PutRunCost(rcDto dto.RunCostDto) (dto.RunCostDto, error) {
+	var result dto.RunCostDto
+	err := c.doJson(http.MethodPut, runCostsPath, nil, rcDto, &result)
+	return result, err
+}
+
+// GetJobCosts returns an application's run costs totaled by job for a business date range.
+func (c *Client) GetJobCosts(applicationId string, fromDate string, toDate string) ([]dto.JobCostDto, error) {
+	var result []dto.JobCostDto
+	err := c.doJson(http.MethodGet, jobCostsPath, url.Values{"appId": {applicationId}, "fromDt": {fromDate}, "toDt": {toDate}}, nil, &result)
+	return result, err
+}
+
+// AddRunComment adds a comment to a run and returns the stored comment. The server takes the
+// author from its authenticating proxy, so this only works through one.
+func (c *Client) AddRunComment(rcDto dto.RunCommentDto) (dto.RunCommentDto, error) {
+	var result dto.RunCommentDto
+	err := c.doJson(http.MethodPost, runCommentsPath, nil, rcDto, &result)
+	return result, err
+}
+
+// ListRunComments returns the comments on a job's runs for a business date, oldest first. If
+// runId is empty, it returns comments on every run that day.
+func (c *Client) ListRunComments(jobId string, businessDate string, runId string) ([]dto.RunCommentDto, error) {
+	q := url.Values{"jobId": {jobId}, "busDt": {businessDate}}
+	if runId != "" {
+		q.Set("runId", runId)
+	}
+	var result []dto.RunCommentDto
+	err := c.doJson(http.MethodGet, runCommentsPath, q, nil, &result)
+	return result, err
+}
+
+// PutSavedView adds or replaces a saved view and returns the stored view.
+func (c *Client) PutSavedView(svDto dto.SavedViewDto) (dto.SavedViewDto, error) {
+	var result dto.SavedViewDto
+	err := c.doJson(http.MethodPut, savedViewsPath, nil, svDto, &result)
+	return result, err
+}
+
+// GetSavedView returns the named saved view.
+func (c *Client) GetSavedView(name string) (dto.SavedViewDto, error) {
+	var result dto.SavedViewDto
+	err := c.doJson(http.MethodGet, savedViewsPath, url.Values{"name": {name}}, nil, &result)
+	return result, err
+}
+
+// ListSavedViews returns team's saved views and all shared views.
+func (c *Client) ListSavedViews(team string) ([]dto.SavedViewDto, error) {
+	var result []dto.SavedViewDto
+	err := c.doJson(http.MethodGet, savedViewsPath, url.Values{"team": {team}}, nil, &result)
+	return result, err
+}
+
+// DeleteSavedView deletes a saved view owned by team.
+func (c *Client) DeleteSavedView(name string, team string) error {
+	req, err := c.newRequest(http.MethodDelete, savedViewsPath, url.Values{"name": {name}, "team": {team}}, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// GetStatusBoard returns the current state of each job in a saved view. An empty
+// businessDate means today (UTC) on the server.
+func (c *Client) GetStatusBoard(viewName string, businessDate string) (dto.StatusBoardDto, error) {
+	return c.GetStatusBoardAsOf(viewName, businessDate, "")
+}
+
+// GetStatusBoardAsOf returns the status board as it would have been at asOf, an RFC 3339
+// timestamp. An empty businessDate means asOf's date.
+func (c *Client) GetStatusBoardAsOf(viewName string, businessDate string, asOf string) (dto.StatusBoardDto, error) {
+	q := url.Values{"view": {viewName}}
+	if businessDate != "" {
+		q.Set("busDt", businessDate)
+	}
+	if asOf != "" {
+		q.Set("asOf", asOf)
+	}
+
+	var result dto.StatusBoardDto
+	err := c.doJson(http.MethodGet, statusBoardPath, q, nil, &result)
+	return result, err
+}
+
+// GetLatestByJobId returns the job's most recent status for each business date from fromDate
+// through toDate (YYYY-MM-DD), newest date first. Empty dates mean today (UTC) on the server, and
+// an empty toDate alone means fromDate.
+func (c *Client) GetLatestByJobId(jobId string, fromDate string, toDate string) ([]dto.JobStatusDto, error) {
+	q := url.Values{"jobId": {jobId}}
+	if fromDate != "" {
+		q.Set("fromDt", fromDate)
+	}
+	if toDate != "" {
+		q.Set("toDt", toDate)
+	}
+
+	var result []dto.JobStatusDto
+	err := c.doJson(http.MethodGet, latestJobStatusesPath, q, nil, &result)
+	return result, err
+}
+
+// GetJobBadge returns the SVG badge for a job's state on a business date (empty means today).
+func (c *Client) GetJobBadge(jobId string, businessDate string) ([]byte, error) {
+	q := url.Values{"jobId": {jobId}}
+	if businessDate != "" {
+		q.Set("busDt", businessDate)
+	}
+	req, err := c.newRequest(http.MethodGet, jobBadgePath, q, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return io.ReadAll(res.Body)
+}
+
+func (c *Client) newRequest(method string, path string, q url.Values, body any) (*http.Request, error) {
+	u := c.baseUrl + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, u, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		apiErr := &ApiError{StatusCode: res.StatusCode, Message: strings.TrimSpace(string(msg))}
+		var errDto dto.ErrorDto
+		if strings.HasPrefix(res.Header.Get("Content-Type"), "application/json") && json.Unmarshal(msg, &errDto) == nil {
+			apiErr.Message, apiErr.Code, apiErr.RequestId, apiErr.FieldErrors = errDto.Message, errDto.Code, errDto.RequestId, errDto.FieldErrors
+		}
+		return nil, apiErr
+	}
+	return res, nil
+}
+
+func (c *Client) doJson(method string, path string, q url.Values, body any, result any) error {
+	req, err := c.newRequest(method, path, q, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return json.NewDecoder(res.Body).Decode(result)
+}
+
+func (c *Client) stream(q url.Values, fn func(dto.JobStatusDto) error) error {
+	q.Set("stream", "ndjson")
+	req, err := c.newRequest(http.MethodGet, jobStatusesPath, q, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	res, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// the server ends a failed stream with an {"Error": "..."} line
+		var line struct {
+			dto.JobStatusDto
+			Error string
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return err
+		}
+		if line.Error != "" {
+			return fmt.Errorf("stream failed on server: %s", line.Error)
+		}
+		if err := fn(line.JobStatusDto); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
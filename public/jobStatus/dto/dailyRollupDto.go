@@ -0,0 +1,29 @@
+package dto
+
+// DailyRollupDto summarizes one application's job statuses for one business date.
+// Durations are in milliseconds and cover runs that have both a START and an end status.
+// Costs are from run costs reporters sent; FailedCostUsd is the part for runs that failed.
+type DailyRollupDto struct {
+	ApplicationId     string  `json:"AppId"`
+	BusinessDate      string  `json:"BusDt"`
+	StartCount        int64   `json:"StartCt"`
+	SucceedCount      int64   `json:"SucceedCt"`
+	FailCount         int64   `json:"FailCt"`
+	RunCount          int64   `json:"RunCt"`
+	CompletedRunCount int64   `json:"CompletedRunCt"`
+	TotalDurationMs   int64   `json:"TotalDurMs"`
+	MinDurationMs     int64   `json:"MinDurMs"`
+	MaxDurationMs     int64   `json:"MaxDurMs"`
+	AvgDurationMs     int64   `json:"AvgDurMs"`
+	ComputeHours      float64 `json:"ComputeHours"`
+	CostUsd           float64 `json:"CostUsd"`
+	FailedCostUsd     float64 `json:"FailedCostUsd"`
+	RolledUpTimestamp string  `json:"RolledUpTs"`
+}
+
+// RollupResultDto reports the outcome of an on-demand rollup.
+type RollupResultDto struct {
+	FromDate    string `json:"FromDt"`
+	ToDate      string `json:"ToDt"`
+	RowsWritten int64  `json:"RowsWritten"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "encoding/json"
+
+// EnvelopeMediaType is the Accept value that asks for responses wrapped in an EnvelopeDto.
+const EnvelopeMediaType = "application/vnd.gojst.envelope+json"
+
+// EnvelopeMetaDto is what the server says about a response. Page is set for paged queries
+// (limit=). Warnings are things that didn't stop the request, like a quota that's nearly used up
+// or a deprecated route.
+type EnvelopeMetaDto struct {
+	RequestId  string   `json:"RequestId,omitempty"`
+	ServerTime string   `json:"ServerTime"`
+	Page       *PageDto `json:"Page,omitempty"`
+	Warnings   []string `json:"Warnings,omitempty"`
+}
+
+// EnvelopeDto wraps a successful JSON response. Data is the response an unwrapped request
+// would get.
+type EnvelopeDto struct {
+	Data json.RawMessage `json:"Data"`
+	Meta EnvelopeMetaDto `json:"Meta"`
+}
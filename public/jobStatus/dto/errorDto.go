@@ -0,0 +1,20 @@
+package dto
+
+// ErrorDto is the body of an error response for clients whose Accept names application/json or
+// EnvelopeMediaType; others get plain text, and JSON:API clients get a JsonApiErrorsDto. Code is
+// the error's code, like "PropsError" or "DuplicateRowError", which with the HTTP status is what
+// clients should act on; Message is for people. Code is empty for a 500, which doesn't say what
+// failed. RequestId matches the X-Request-Id header and the server's log.
+type ErrorDto struct {
+	Code        string          `json:"Code,omitempty"`
+	Message     string          `json:"Message"`
+	RequestId   string          `json:"RequestId,omitempty"`
+	FieldErrors []FieldErrorDto `json:"FieldErrors,omitempty"`
+}
+
+// FieldErrorDto is a problem with one field of the request body. Field is the DTO's JSON name,
+// like "JobStTs".
+type FieldErrorDto struct {
+	Field   string `json:"Field"`
+	Message string `json:"Message"`
+}
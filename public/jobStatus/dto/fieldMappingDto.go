@@ -0,0 +1,11 @@
+package dto
+
+// FieldMappingDto is one application's mapping of a legacy field name to a JobStatusDto field,
+// and how many requests used it since the server started. LastUsed is empty if none have.
+type FieldMappingDto struct {
+	AppId    string `json:"AppId"`
+	From     string `json:"From"`
+	To       string `json:"To"`
+	Uses     int64  `json:"Uses"`
+	LastUsed string `json:"LastUsed,omitempty"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+// JobForecastDto predicts when a job's run in progress will end. MissProbability is the chance
+// it ends after DeadlineTs, from 0 to 1; without a deadline, both are omitted. Model names the
+// forecaster that made it: "baseline" for the built-in one.
+type JobForecastDto struct {
+	ApplicationId        string   `json:"AppId"`
+	JobId                string   `json:"JobId"`
+	BusinessDate         string   `json:"BusDt"`
+	RunId                string   `json:"RunId"`
+	StartedTimestamp     string   `json:"StartedTs"`
+	AsOfTimestamp        string   `json:"AsOfTs"`
+	DeadlineTimestamp    string   `json:"DeadlineTs,omitempty"`
+	Model                string   `json:"Model"`
+	ExpectedEndTimestamp string   `json:"ExpectedEndTs"`
+	MissProbability      *float64 `json:"MissProbability,omitempty"`
+}
+
+// ForecastRequestDto is what an external forecasting model receives: the run in progress and its
+// job's duration baselines. DeadlineTs is omitted if there isn't one.
+type ForecastRequestDto struct {
+	ApplicationId     string                `json:"AppId"`
+	JobId             string                `json:"JobId"`
+	BusinessDate      string                `json:"BusDt"`
+	RunId             string                `json:"RunId"`
+	StartedTimestamp  string                `json:"StartedTs"`
+	AsOfTimestamp     string                `json:"AsOfTs"`
+	DeadlineTimestamp string                `json:"DeadlineTs,omitempty"`
+	Baselines         []DurationBaselineDto `json:"Baselines"`
+}
+
+// ForecastResponseDto is what an external forecasting model returns. MissProbability is ignored
+// when the request had no deadline.
+type ForecastResponseDto struct {
+	Model                string  `json:"Model"`
+	ExpectedEndTimestamp string  `json:"ExpectedEndTs"`
+	MissProbability      float64 `json:"MissProbability"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+// IntegrityReportDto reports one application's integrity check for one business date.
+// Unhashed rows were stored before integrity hashing. Digest is a hex SHA-256 over the day's
+// row hashes (recomputed for unhashed rows) in StatusId order; record it to notice rows
+// deleted later.
+type IntegrityReportDto struct {
+	ApplicationId       string   `json:"AppId"`
+	BusinessDate        string   `json:"BusDt"`
+	RowCount            int      `json:"RowCt"`
+	VerifiedCount       int      `json:"VerifiedCt"`
+	UnhashedCount       int      `json:"UnhashedCt"`
+	MismatchedStatusIds []string `json:"MismatchedStatusIds,omitempty"`
+	Digest              string   `json:"Digest"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+// JobAliasDto says statuses sent with AliasJobId are stored under CanonicalJobId.
+type JobAliasDto struct {
+	AliasJobId       string `json:"AliasJobId"`
+	CanonicalJobId   string `json:"CanonicalJobId"`
+	CreatedTimestamp string `json:"CreatedTs"`
+}
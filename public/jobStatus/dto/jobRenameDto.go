@@ -0,0 +1,10 @@
+package dto
+
+// JobRenameResultDto reports what a job rename or merge changed.
+type JobRenameResultDto struct {
+	FromJobId      string `json:"FromJobId"`
+	ToJobId        string `json:"ToJobId"`
+	StatusesMoved  int64  `json:"StatusesMoved"`
+	ViewsUpdated   int64  `json:"ViewsUpdated"`
+	AliasesUpdated int64  `json:"AliasesUpdated"`
+}
@@ -0,0 +1,81 @@
+// Package dto defines the JSON shapes the job status API accepts and returns.
+// Field names are short because batch clients send many of these.
+package dto
+
+// Version identifies the DTO format this package describes.
+const Version = "20230701"
+
+// Formats for date and timestamp strings in DTOs.
+const (
+	DateFormat      = "2006-01-02"
+	TimestampFormat = "2006-01-02T15:04:05.999999999Z07:00" // time.RFC3339Nano
+)
+
+// JobStatusDto fields are all required on input except StatusId, which the server
+// assigns and ignores on input, and RunId, which the server generates if it's missing.
+// ReportedJobId is also server-set: if JobId was an alias, JobId is the canonical JobId and
+// ReportedJobId is the one the job sent. ReceivedTimestamp is when the server accepted the
+// status; rows stored before the server recorded it don't have one. Links and Metadata are
+// optional. HeartbeatTimestamp is server-set too: on a START status, it's when the run last sent
+// a HeartbeatDto.
+// Query results omit fields the client didn't request with the fields parameter.
+type JobStatusDto struct {
+	StatusId           string            `json:"StatusId,omitempty"`
+	ApplicationId      string            `json:"AppId,omitempty"`
+	JobId              string            `json:"JobId,omitempty"`
+	JobStatusCode      string            `json:"JobSt,omitempty"`
+	JobStatusTimestamp string            `json:"JobStTs,omitempty"`
+	BusinessDate       string            `json:"BusDt,omitempty"`
+	RunId              string            `json:"RunId,omitempty"`
+	HostId             string            `json:"HostId,omitempty"`
+	ReportedJobId      string            `json:"ReportedJobId,omitempty"`
+	ReceivedTimestamp  string            `json:"RecvTs,omitempty"`
+	Links              []LinkDto         `json:"Links,omitempty"`
+	Metadata           map[string]string `json:"Metadata,omitempty"`
+	HeartbeatTimestamp string            `json:"HeartbeatTs,omitempty"`
+}
+
+// HeartbeatDto says a run is still going. It names the run's START status; HeartbeatTs is
+// optional and defaults to when the server receives it.
+type HeartbeatDto struct {
+	JobId              string `json:"JobId"`
+	BusinessDate       string `json:"BusDt"`
+	RunId              string `json:"RunId"`
+	HeartbeatTimestamp string `json:"HeartbeatTs,omitempty"`
+}
+
+// LinkDto is a URL a job attaches to a status. Kind is "log", "artifact", or "ticket", and Url
+// is an absolute http or https URL.
+type LinkDto struct {
+	Kind string `json:"Kind"`
+	Url  string `json:"Url"`
+}
+
+// RowErrorDto describes a stored row the server couldn't read.
+type RowErrorDto struct {
+	Row   int    `json:"Row"`
+	Error string `json:"Error"`
+}
+
+// PageDto says where a page of query results (limit=) starts. NextOffset is set when the page is
+// full, so there may be more; if the last page is exactly full, the page after it is empty.
+type PageDto struct {
+	Limit      int  `json:"Limit"`
+	Offset     int  `json:"Offset"`
+	NextOffset *int `json:"NextOffset,omitempty"`
+}
+
+// JobStatusPageDto is the query response for one page of results. RowErrors is only set for
+// partial results (partial=true); skipped rows count toward the page.
+type JobStatusPageDto struct {
+	JobStatuses []JobStatusDto `json:"JobStatuses"`
+	Page        PageDto        `json:"Page"`
+	RowErrors   []RowErrorDto  `json:"RowErrors,omitempty"`
+}
+
+// PartialJobStatusesDto is the query response when the client asks for partial results
+// (partial=true). RowErrors lists rows that couldn't be read and aren't in JobStatuses.
+type PartialJobStatusesDto struct {
+	JobStatuses []JobStatusDto `json:"JobStatuses"`
+	RowErrors   []RowErrorDto  `json:"RowErrors"`
+}
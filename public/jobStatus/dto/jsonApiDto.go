@@ -0,0 +1,58 @@
+package dto
+
+// JsonApiMediaType is the Accept value that asks for JSON:API (https://jsonapi.org) documents
+// instead of plain DTOs.
+const JsonApiMediaType = "application/vnd.api+json"
+
+// JsonApiJobStatusType is the JSON:API resource type of a job status.
+const JsonApiJobStatusType = "jobStatuses"
+
+// JsonApiLinksDto holds JSON:API links. Only the links that apply are set. First, Prev, and Next
+// are pagination links for paged queries (limit=).
+type JsonApiLinksDto struct {
+	Self    string `json:"self,omitempty"`
+	Related string `json:"related,omitempty"`
+	First   string `json:"first,omitempty"`
+	Prev    string `json:"prev,omitempty"`
+	Next    string `json:"next,omitempty"`
+}
+
+// JsonApiRelationshipDto links a resource to related resources without including them.
+type JsonApiRelationshipDto struct {
+	Links JsonApiLinksDto `json:"links"`
+}
+
+// JsonApiJobStatusDto is one job status as a JSON:API resource. Id is the StatusId, so
+// Attributes doesn't repeat it. Relationships has "job", linking to all statuses for the
+// JobId, when JobId was selected.
+type JsonApiJobStatusDto struct {
+	Type          string                            `json:"type"`
+	Id            string                            `json:"id"`
+	Attributes    JobStatusDto                      `json:"attributes"`
+	Relationships map[string]JsonApiRelationshipDto `json:"relationships,omitempty"`
+}
+
+// JsonApiMetaDto is top-level meta. RowErrors is set for partial results (partial=true).
+type JsonApiMetaDto struct {
+	RowErrors []RowErrorDto `json:"rowErrors,omitempty"`
+}
+
+// JsonApiJobStatusesDto is a JSON:API document for a job status query.
+type JsonApiJobStatusesDto struct {
+	Data  []JsonApiJobStatusDto `json:"data"`
+	Links JsonApiLinksDto       `json:"links"`
+	Meta  *JsonApiMetaDto       `json:"meta,omitempty"`
+}
+
+// JsonApiErrorDto is one entry in a JSON:API error document. Status is the HTTP status as a
+// string, as JSON:API requires; Code is the CommonError code.
+type JsonApiErrorDto struct {
+	Status string `json:"status"`
+	Code   string `json:"code,omitempty"`
+	Detail string `json:"detail"`
+}
+
+// JsonApiErrorsDto is a JSON:API error document.
+type JsonApiErrorsDto struct {
+	Errors []JsonApiErrorDto `json:"errors"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+// MonthFormat is the format of month strings in metering DTOs.
+const MonthFormat = "2006-01"
+
+// ApplicationUsageDto is one application's usage for a month. ApiCalls is keyed by endpoint,
+// like "POST /job-statuses". RowsStored counts statuses by business date and comes from the
+// daily rollups, so dates that haven't been rolled up aren't included.
+type ApplicationUsageDto struct {
+	ApplicationId string           `json:"AppId"`
+	ApiCalls      map[string]int64 `json:"ApiCalls"`
+	ApiCallCount  int64            `json:"ApiCallCt"`
+	RowsStored    int64            `json:"RowsStored"`
+}
+
+// MeteringReportDto is the monthly metering report, sorted by application.
+type MeteringReportDto struct {
+	Month        string                `json:"Month"`
+	Applications []ApplicationUsageDto `json:"Applications"`
+}
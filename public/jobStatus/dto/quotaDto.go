@@ -0,0 +1,10 @@
+package dto
+
+// QuotaUsageDto reports an application's status count for one business date against its quota.
+// StatusLimit is 0 if the application has no quota.
+type QuotaUsageDto struct {
+	ApplicationId string `json:"AppId"`
+	BusinessDate  string `json:"BusDt"`
+	StatusCount   int64  `json:"StatusCt"`
+	StatusLimit   int64  `json:"StatusLimit"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+// JobReliabilityDto is one job's failures and recoveries over a business date range. A failure
+// is a run of FAILs; it's recovered by the next SUCCEED. MttrMs is the mean time from a
+// failure's first FAIL to its recovery, and MtbfMs the mean time between failure starts, both
+// 0 when there's nothing to average.
+type JobReliabilityDto struct {
+	ApplicationId string `json:"AppId"`
+	JobId         string `json:"JobId"`
+	FromDate      string `json:"FromDt"`
+	ToDate        string `json:"ToDt"`
+	TerminalCount int64  `json:"TerminalCt"`
+	FailureCount  int64  `json:"FailureCt"`
+	RecoveryCount int64  `json:"RecoveryCt"`
+	MttrMs        int64  `json:"MttrMs"`
+	MtbfMs        int64  `json:"MtbfMs"`
+}
+
+// JobFlakinessDto is one job's place in a flakiness ranking. Flakiness is TransitionCt divided by
+// TerminalCt less one: 0 if the job never changes between SUCCEED and FAIL, 1 if it changes
+// every run.
+type JobFlakinessDto struct {
+	Rank            int     `json:"Rank"`
+	ApplicationId   string  `json:"AppId"`
+	JobId           string  `json:"JobId"`
+	FromDate        string  `json:"FromDt"`
+	ToDate          string  `json:"ToDt"`
+	TerminalCount   int64   `json:"TerminalCt"`
+	TransitionCount int64   `json:"TransitionCt"`
+	FailureCount    int64   `json:"FailureCt"`
+	Flakiness       float64 `json:"Flakiness"`
+}
+
+// DurationBaselineDto is the duration of a job's successful runs in one season: a weekday
+// ("Mon" to "Sun"), "FirstBusinessDay" or "LastBusinessDay" of the month, or "All".
+type DurationBaselineDto struct {
+	ApplicationId string `json:"AppId"`
+	JobId         string `json:"JobId"`
+	Season        string `json:"Season"`
+	RunCount      int64  `json:"RunCt"`
+	MedianMs      int64  `json:"MedianMs"`
+	P90Ms         int64  `json:"P90Ms"`
+}
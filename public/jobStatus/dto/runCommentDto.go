@@ -0,0 +1,13 @@
+package dto
+
+// RunCommentDto is a comment on the run (JobId, BusDt, RunId). On POST, only those and Body are
+// read; the server sets CommentId, Author (the signed-in user), and CreatedTs.
+type RunCommentDto struct {
+	CommentId        string `json:"CommentId,omitempty"`
+	JobId            string `json:"JobId"`
+	BusinessDate     string `json:"BusDt"`
+	RunId            string `json:"RunId"`
+	Author           string `json:"Author,omitempty"`
+	Body             string `json:"Body"`
+	CreatedTimestamp string `json:"CreatedTs,omitempty"`
+}
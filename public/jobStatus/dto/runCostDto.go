@@ -0,0 +1,27 @@
+package dto
+
+// RunCostDto is what one run cost, as its reporter estimates it. The run is (JobId, BusDt, RunId).
+// CostUsd is in dollars. UpdatedTs is set by the server.
+type RunCostDto struct {
+	ApplicationId    string  `json:"AppId"`
+	JobId            string  `json:"JobId"`
+	BusinessDate     string  `json:"BusDt"`
+	RunId            string  `json:"RunId"`
+	ComputeHours     float64 `json:"ComputeHours"`
+	CostUsd          float64 `json:"CostUsd"`
+	UpdatedTimestamp string  `json:"UpdatedTs,omitempty"`
+}
+
+// JobCostDto totals one job's run costs over a business date range. FailedCostUsd is what runs
+// that ended in FAIL cost, an estimate of what failures cost.
+type JobCostDto struct {
+	ApplicationId  string  `json:"AppId"`
+	JobId          string  `json:"JobId"`
+	FromDate       string  `json:"FromDt"`
+	ToDate         string  `json:"ToDt"`
+	RunCount       int64   `json:"RunCt"`
+	ComputeHours   float64 `json:"ComputeHours"`
+	CostUsd        float64 `json:"CostUsd"`
+	FailedRunCount int64   `json:"FailedRunCt"`
+	FailedCostUsd  float64 `json:"FailedCostUsd"`
+}
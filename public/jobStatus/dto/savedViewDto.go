@@ -0,0 +1,15 @@
+package dto
+
+// SavedViewDto is a named set of job status filters and display options that a team can reuse
+// instead of rebuilding queries. Fields and Sort use the same syntax as the query parameters.
+// Shared views are listed for every team; others only for their own team.
+type SavedViewDto struct {
+	Name             string   `json:"Name"`
+	Team             string   `json:"Team"`
+	Shared           bool     `json:"Shared"`
+	ApplicationIds   []string `json:"AppIds,omitempty"`
+	JobIds           []string `json:"JobIds,omitempty"`
+	Fields           string   `json:"Fields,omitempty"`
+	Sort             string   `json:"Sort,omitempty"`
+	UpdatedTimestamp string   `json:"UpdatedTs,omitempty"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+// Delivery kinds for scheduled queries.
+const (
+	DeliveryWebhook = "webhook"
+)
+
+// ScheduledQueryDto is a query the server runs every day at RunAt (HH:MM, UTC) and delivers to
+// DeliveryTarget. With a View, it's the view's query; without one, Filters have to narrow on
+// JobId or BusDt like any query by filters. Filters use the query parameter syntax, like
+// {"JobSt[eq]": "FAIL"}. The business date is the run date plus BusDtOffset days (0 today,
+// -1 yesterday). LastRunDt is server-set.
+type ScheduledQueryDto struct {
+	Name               string            `json:"Name"`
+	Team               string            `json:"Team"`
+	View               string            `json:"View,omitempty"`
+	Filters            map[string]string `json:"Filters,omitempty"`
+	BusinessDateOffset int               `json:"BusDtOffset"`
+	RunAt              string            `json:"RunAt"`
+	DeliveryKind       string            `json:"DeliveryKind"`
+	DeliveryTarget     string            `json:"DeliveryTarget"`
+	LastRunDate        string            `json:"LastRunDt,omitempty"`
+	UpdatedTimestamp   string            `json:"UpdatedTs,omitempty"`
+}
+
+// ScheduledQueryResultDto is what a scheduled query delivers. RowErrors lists rows the server
+// couldn't read; they aren't in JobStatuses.
+type ScheduledQueryResultDto struct {
+	Name         string         `json:"Name"`
+	Team         string         `json:"Team"`
+	View         string         `json:"View,omitempty"`
+	BusinessDate string         `json:"BusDt"`
+	RunTimestamp string         `json:"RunTs"`
+	JobStatuses  []JobStatusDto `json:"JobStatuses"`
+	RowErrors    []RowErrorDto  `json:"RowErrors,omitempty"`
+}
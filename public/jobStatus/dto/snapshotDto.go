@@ -0,0 +1,28 @@
+package dto
+
+// SnapshotFormatVersion identifies the snapshot archive layout SnapshotManifestDto describes.
+const SnapshotFormatVersion = "1"
+
+// SnapshotManifestDto is manifest.json, the last entry in a snapshot archive. It lists the
+// other entries, which are JSON lines files, with their row counts, uncompressed sizes, and
+// hex SHA-256 sums.
+type SnapshotManifestDto struct {
+	FormatVersion string            `json:"FormatVersion"`
+	DtoVersion    string            `json:"DtoVersion"`
+	CreatedTs     string            `json:"CreatedTs"`
+	Files         []SnapshotFileDto `json:"Files"`
+}
+
+type SnapshotFileDto struct {
+	Name   string `json:"Name"`
+	Rows   int64  `json:"Rows"`
+	Bytes  int64  `json:"Bytes"`
+	Sha256 string `json:"Sha256"`
+}
+
+// SnapshotJobStatusDto is one line of job-statuses.jsonl. IntegrityHash is hex, and missing for
+// statuses stored before integrity hashing.
+type SnapshotJobStatusDto struct {
+	JobStatusDto
+	IntegrityHash string `json:"IntegrityHash,omitempty"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+// SqlQueryDto is an analyst's read-only SELECT. MaxRows and TimeoutSeconds default to the
+// server's defaults when zero and can't be raised past its maximums.
+type SqlQueryDto struct {
+	Sql            string `json:"Sql"`
+	MaxRows        int    `json:"MaxRows,omitempty"`
+	TimeoutSeconds int    `json:"TimeoutSeconds,omitempty"`
+}
+
+// SqlResultDto is a query's result. Values are JSON numbers, strings, booleans, or null;
+// timestamps are RFC 3339 strings. Truncated is true if there were more than MaxRows rows.
+type SqlResultDto struct {
+	Columns   []string `json:"Columns"`
+	Rows      [][]any  `json:"Rows"`
+	Truncated bool     `json:"Truncated"`
+}
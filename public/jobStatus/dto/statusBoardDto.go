@@ -0,0 +1,38 @@
+package dto
+
+// Job states on a status board, from each job's latest status for the business date.
+const (
+	BoardStateNotStarted = "NOT_STARTED"
+	BoardStateRunning    = "RUNNING"
+	BoardStateSucceeded  = "SUCCEEDED"
+	BoardStateFailed     = "FAILED"
+)
+
+// StatusBoardDto is everything a wallboard shows for one saved view on one business date.
+type StatusBoardDto struct {
+	View          string               `json:"View"`
+	BusinessDate  string               `json:"BusDt"`
+	AsOfTimestamp string               `json:"AsOfTs"`
+	Counts        StatusBoardCountsDto `json:"Counts"`
+	Jobs          []StatusBoardJobDto  `json:"Jobs"`
+}
+
+// StatusBoardCountsDto counts the board's jobs in each state.
+type StatusBoardCountsDto struct {
+	NotStarted int `json:"NotStarted"`
+	Running    int `json:"Running"`
+	Succeeded  int `json:"Succeeded"`
+	Failed     int `json:"Failed"`
+}
+
+// StatusBoardJobDto is one job's current state. Status fields are omitted for NOT_STARTED jobs.
+// Links are the latest status's links, so a failed job's log is one click away.
+type StatusBoardJobDto struct {
+	JobId              string    `json:"JobId"`
+	State              string    `json:"State"`
+	ApplicationId      string    `json:"AppId,omitempty"`
+	JobStatusTimestamp string    `json:"JobStTs,omitempty"`
+	RunId              string    `json:"RunId,omitempty"`
+	HostId             string    `json:"HostId,omitempty"`
+	Links              []LinkDto `json:"Links,omitempty"`
+}
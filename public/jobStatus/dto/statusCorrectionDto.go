@@ -0,0 +1,13 @@
+package dto
+
+// StatusCorrectionDto is one entry in a status's audit trail: who changed it, when, why, and the
+// whole status before and after. After is null if the status was deleted.
+type StatusCorrectionDto struct {
+	CorrectionId       string        `json:"CorrectionId"`
+	StatusId           string        `json:"StatusId"`
+	CorrectedBy        string        `json:"CorrectedBy"`
+	Reason             string        `json:"Reason"`
+	CorrectedTimestamp string        `json:"CorrectedTs"`
+	Before             JobStatusDto  `json:"Before"`
+	After              *JobStatusDto `json:"After"`
+}
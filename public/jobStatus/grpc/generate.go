@@ -0,0 +1,5 @@
+// Package jobstatusgrpc is the code generated from jobStatus.proto: the messages, and the client
+// and server for JobStatusService. Regenerate it with go generate after changing the proto.
+package jobstatusgrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative jobStatus.proto
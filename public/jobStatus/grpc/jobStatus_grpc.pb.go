@@ -0,0 +1,201 @@
+// JobStatusService mirrors the job status HTTP controllers for producers that would rather use
+// gRPC. Messages follow dto.JobStatusDto (version 20230701): dates and timestamps are strings in
+// dto.DateFormat and dto.TimestampFormat, so both APIs validate them the same way.
+//
+// Errors use gRPC codes in place of HTTP statuses: INVALID_ARGUMENT for 400, ALREADY_EXISTS for
+// 409, RESOURCE_EXHAUSTED for 429 (quotas), and UNAVAILABLE for 503.
+//
+// cmd/api serves it on GOJST_GRPC_ADDR. The generated code is checked in; see generate.go to
+// regenerate it after changing this file.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: jobStatus.proto
+
+package jobstatusgrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	JobStatusService_AddJobStatus_FullMethodName           = "/gojst.jobstatus.v20230701.JobStatusService/AddJobStatus"
+	JobStatusService_GetByJobId_FullMethodName             = "/gojst.jobstatus.v20230701.JobStatusService/GetByJobId"
+	JobStatusService_GetByJobIdBusinessDate_FullMethodName = "/gojst.jobstatus.v20230701.JobStatusService/GetByJobIdBusinessDate"
+)
+
+// JobStatusServiceClient is the client API for JobStatusService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type JobStatusServiceClient interface {
+	// AddJobStatus is POST /job-statuses. Adds are idempotent on the status's key (JobId, JobSt,
+	// BusDt, RunId): a retry gets the stored status with added false.
+	AddJobStatus(ctx context.Context, in *AddJobStatusRequest, opts ...grpc.CallOption) (*AddJobStatusResponse, error)
+	// GetByJobId is GET /job-statuses?jobId=.
+	GetByJobId(ctx context.Context, in *GetByJobIdRequest, opts ...grpc.CallOption) (*JobStatuses, error)
+	// GetByJobIdBusinessDate is GET /job-statuses?jobId=&busDt=.
+	GetByJobIdBusinessDate(ctx context.Context, in *GetByJobIdBusinessDateRequest, opts ...grpc.CallOption) (*JobStatuses, error)
+}
+
+type jobStatusServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewJobStatusServiceClient(cc grpc.ClientConnInterface) JobStatusServiceClient {
+	return &jobStatusServiceClient{cc}
+}
+
+func (c *jobStatusServiceClient) AddJobStatus(ctx context.Context, in *AddJobStatusRequest, opts ...grpc.CallOption) (*AddJobStatusResponse, error) {
+	out := new(AddJobStatusResponse)
+	err := c.cc.Invoke(ctx, JobStatusService_AddJobStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobStatusServiceClient) GetByJobId(ctx context.Context, in *GetByJobIdRequest, opts ...grpc.CallOption) (*JobStatuses, error) {
+	out := new(JobStatuses)
+	err := c.cc.Invoke(ctx, JobStatusService_GetByJobId_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobStatusServiceClient) GetByJobIdBusinessDate(ctx context.Context, in *GetByJobIdBusinessDateRequest, opts ...grpc.CallOption) (*JobStatuses, error) {
+	out := new(JobStatuses)
+	err := c.cc.Invoke(ctx, JobStatusService_GetByJobIdBusinessDate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// JobStatusServiceServer is the server API for JobStatusService service.
+// All implementations must embed UnimplementedJobStatusServiceServer
+// for forward compatibility
+type JobStatusServiceServer interface {
+	// AddJobStatus is POST /job-statuses. Adds are idempotent on the status's key (JobId, JobSt,
+	// BusDt, RunId): a retry gets the stored status with added false.
+	AddJobStatus(context.Context, *AddJobStatusRequest) (*AddJobStatusResponse, error)
+	// GetByJobId is GET /job-statuses?jobId=.
+	GetByJobId(context.Context, *GetByJobIdRequest) (*JobStatuses, error)
+	// GetByJobIdBusinessDate is GET /job-statuses?jobId=&busDt=.
+	GetByJobIdBusinessDate(context.Context, *GetByJobIdBusinessDateRequest) (*JobStatuses, error)
+	mustEmbedUnimplementedJobStatusServiceServer()
+}
+
+// UnimplementedJobStatusServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedJobStatusServiceServer struct {
+}
+
+func (UnimplementedJobStatusServiceServer) AddJobStatus(context.Context, *AddJobStatusRequest) (*AddJobStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddJobStatus not implemented")
+}
+func (UnimplementedJobStatusServiceServer) GetByJobId(context.Context, *GetByJobIdRequest) (*JobStatuses, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetByJobId not implemented")
+}
+func (UnimplementedJobStatusServiceServer) GetByJobIdBusinessDate(context.Context, *GetByJobIdBusinessDateRequest) (*JobStatuses, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetByJobIdBusinessDate not implemented")
+}
+func (UnimplementedJobStatusServiceServer) mustEmbedUnimplementedJobStatusServiceServer() {}
+
+// UnsafeJobStatusServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to JobStatusServiceServer will
+// result in compilation errors.
+type UnsafeJobStatusServiceServer interface {
+	mustEmbedUnimplementedJobStatusServiceServer()
+}
+
+func RegisterJobStatusServiceServer(s grpc.ServiceRegistrar, srv JobStatusServiceServer) {
+	s.RegisterService(&JobStatusService_ServiceDesc, srv)
+}
+
+func _JobStatusService_AddJobStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddJobStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobStatusServiceServer).AddJobStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobStatusService_AddJobStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobStatusServiceServer).AddJobStatus(ctx, req.(*AddJobStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobStatusService_GetByJobId_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByJobIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobStatusServiceServer).GetByJobId(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobStatusService_GetByJobId_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobStatusServiceServer).GetByJobId(ctx, req.(*GetByJobIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobStatusService_GetByJobIdBusinessDate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByJobIdBusinessDateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobStatusServiceServer).GetByJobIdBusinessDate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobStatusService_GetByJobIdBusinessDate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobStatusServiceServer).GetByJobIdBusinessDate(ctx, req.(*GetByJobIdBusinessDateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// JobStatusService_ServiceDesc is the grpc.ServiceDesc for JobStatusService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var JobStatusService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gojst.jobstatus.v20230701.JobStatusService",
+	HandlerType: (*JobStatusServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddJobStatus",
+			Handler:    _JobStatusService_AddJobStatus_Handler,
+		},
+		{
+			MethodName: "GetByJobId",
+			Handler:    _JobStatusService_GetByJobId_Handler,
+		},
+		{
+			MethodName: "GetByJobIdBusinessDate",
+			Handler:    _JobStatusService_GetByJobIdBusinessDate_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "jobStatus.proto",
+}
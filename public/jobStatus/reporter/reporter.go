@@ -0,0 +1,236 @@
+// Package reporter sends job statuses from the field without losing them to short outages. A
+// status the API can't take right now is spooled to a directory and resent, in the order it was
+// reported, until the API takes it.
+//
+// Resending is safe because a status's natural key (JobId, JobSt, BusDt, RunId) is its
+// idempotency key: if an earlier try got through, the API returns the status it stored. So every
+// status needs a RunId. Report makes one if it's missing; send it again with the run's later
+// statuses.
+package reporter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmjf/go-jst/public/jobStatus/client"
+	"github.com/jmjf/go-jst/public/jobStatus/dto"
+)
+
+// DefaultResendInterval is how often Run resends spooled statuses if Config doesn't say.
+const DefaultResendInterval = 30 * time.Second
+
+// RejectedDir is the subdirectory of the spool where statuses the API refused on resend are
+// moved, for someone to look at. They aren't sent again.
+const RejectedDir = "rejected"
+
+// ErrSpoolFull is returned when a status can't be sent and the spool already holds
+// Config.MaxSpooled statuses.
+var ErrSpoolFull = errors.New("reporter: spool is full")
+
+type Config struct {
+	// SpoolDir holds statuses waiting to be resent. It's created if it doesn't exist. Use a
+	// directory that survives restarts and isn't shared with another reporter.
+	SpoolDir string
+	// ResendInterval is how often Run resends. Zero means DefaultResendInterval.
+	ResendInterval time.Duration
+	// MaxSpooled bounds the statuses waiting, so a long outage can't fill the disk. Zero means
+	// no bound.
+	MaxSpooled int
+}
+
+// Reporter sends statuses through a client.Client and spools the ones it can't send. It's safe
+// for concurrent use.
+type Reporter struct {
+	client *client.Client
+	cfg    Config
+
+	// mu keeps spooled statuses in order: Report doesn't send around a resend in progress.
+	mu  sync.Mutex
+	seq uint64
+}
+
+// New returns a Reporter that sends with c. Give c an http.Client with a Timeout, or an
+// unreachable API holds Report as long as the network stack does. It returns an error if the
+// spool directory can't be created.
+func New(c *client.Client, cfg Config) (*Reporter, error) {
+	if cfg.SpoolDir == "" {
+		return nil, errors.New("reporter: SpoolDir is required")
+	}
+	if cfg.ResendInterval <= 0 {
+		cfg.ResendInterval = DefaultResendInterval
+	}
+	if err := os.MkdirAll(filepath.Join(cfg.SpoolDir, RejectedDir), 0o700); err != nil {
+		return nil, err
+	}
+	return &Reporter{client: c, cfg: cfg}, nil
+}
+
+// Report sends a status, or spools it if the API is unreachable or busy, and returns it with
+// its RunId. Statuses already waiting go first, so if any are, this one is spooled behind them
+// and Run sends it. An error means the status was neither sent nor spooled: the API refused
+// it (a *client.ApiError with a 4xx status), or the spool couldn't take it.
+func (rep *Reporter) Report(jsDto dto.JobStatusDto) (dto.JobStatusDto, error) {
+	if jsDto.RunId == "" {
+		jsDto.RunId = NewRunId()
+	}
+
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	waiting, err := rep.spooled()
+	if err != nil {
+		return jsDto, err
+	}
+	if len(waiting) == 0 {
+		_, err := rep.client.AddJobStatus(jsDto)
+		if err == nil || !isTemporary(err) {
+			return jsDto, err
+		}
+	}
+	if rep.cfg.MaxSpooled > 0 && len(waiting) >= rep.cfg.MaxSpooled {
+		return jsDto, ErrSpoolFull
+	}
+	return jsDto, rep.spool(jsDto)
+}
+
+// Run resends spooled statuses every ResendInterval until ctx is done.
+func (rep *Reporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(rep.cfg.ResendInterval)
+	defer ticker.Stop()
+	for {
+		rep.Resend()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Resend sends spooled statuses, oldest first, and returns how many went. It stops at the first
+// that the API can't take yet, so order is kept. A status the API refuses is moved to
+// RejectedDir and skipped.
+func (rep *Reporter) Resend() (int, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	waiting, err := rep.spooled()
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, name := range waiting {
+		path := filepath.Join(rep.cfg.SpoolDir, name)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return sent, err
+		}
+		var jsDto dto.JobStatusDto
+		if err := json.Unmarshal(b, &jsDto); err != nil {
+			if err := os.Rename(path, filepath.Join(rep.cfg.SpoolDir, RejectedDir, name)); err != nil {
+				return sent, err
+			}
+			continue
+		}
+
+		_, err = rep.client.AddJobStatus(jsDto)
+		switch {
+		case err == nil:
+			sent++
+			if err := os.Remove(path); err != nil {
+				return sent, err
+			}
+		case isTemporary(err):
+			return sent, err
+		default:
+			if err := os.Rename(path, filepath.Join(rep.cfg.SpoolDir, RejectedDir, name)); err != nil {
+				return sent, err
+			}
+		}
+	}
+	return sent, nil
+}
+
+// Pending returns how many statuses are waiting to be resent.
+func (rep *Reporter) Pending() (int, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	waiting, err := rep.spooled()
+	return len(waiting), err
+}
+
+// isTemporary is true for errors a later try may not get: no response, a server error, or
+// 408 or 429.
+func isTemporary(err error) bool {
+	var apiErr *client.ApiError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	return apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusRequestTimeout || apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// spool writes the status to a temporary file and renames it, so a crash can't leave half a
+// status to resend. Names sort in the order statuses were spooled.
+func (rep *Reporter) spool(jsDto dto.JobStatusDto) error {
+	b, err := json.Marshal(jsDto)
+	if err != nil {
+		return err
+	}
+	rep.seq++
+	name := fmt.Sprintf("%020d-%06d.json", time.Now().UnixNano(), rep.seq%1_000_000)
+
+	tmp, err := os.CreateTemp(rep.cfg.SpoolDir, ".spool-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(b)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(rep.cfg.SpoolDir, name))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// spooled returns the names of waiting statuses, oldest first.
+func (rep *Reporter) spooled() ([]string, error) {
+	entries, err := os.ReadDir(rep.cfg.SpoolDir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// NewRunId returns a random (v4) UUID to use as a RunId, for callers that need a run's RunId
+// before its first status is sent.
+func NewRunId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,26 @@
+// Package dto defines the JSON shapes of the admin task API.
+package dto
+
+// Task states.
+const (
+	StateQueued    = "queued"
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	StateFailed    = "failed"
+)
+
+// TaskDto reports a long running admin action. ProgressTotal is 0 if the task can't tell how
+// much work it has. Result is set when State is completed; Error when it's failed.
+// Timestamps are RFC 3339 in UTC; unset ones are omitted.
+type TaskDto struct {
+	TaskId        string `json:"TaskId"`
+	Kind          string `json:"Kind"`
+	State         string `json:"State"`
+	ProgressDone  int    `json:"ProgressDone"`
+	ProgressTotal int    `json:"ProgressTotal"`
+	Result        any    `json:"Result,omitempty"`
+	Error         string `json:"Error,omitempty"`
+	CreatedTs     string `json:"CreatedTs"`
+	StartedTs     string `json:"StartedTs,omitempty"`
+	FinishedTs    string `json:"FinishedTs,omitempty"`
+}